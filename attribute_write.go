@@ -31,6 +31,9 @@ const (
 //   - Scalars: int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64
 //   - Arrays: []int32, []float64, etc. (1D arrays only)
 //   - Strings: string (fixed-length, converted to byte array)
+//   - Attributes read from a file (*core.Attribute): written with their datatype,
+//     dataspace and raw value unchanged; variable-length and reference values
+//     must already point into the file being written
 //
 // Parameters:
 //   - name: Attribute name (ASCII, no null bytes)
@@ -231,7 +234,7 @@ func writeAttribute(fw *FileWriter, objectAddr uint64, name string, value interf
 		return writeDenseAttribute(fw, objectAddr, oh, name, value, sb)
 	}
 
-	if compactCount < MaxCompactAttributes {
+	if compactCount < fw.config.maxCompactAttributes() {
 		// Still compact → add compact attribute
 		return writeCompactAttribute(fw, objectAddr, oh, name, value, sb)
 	}
@@ -284,12 +287,17 @@ func writeCompactAttribute(fw *FileWriter, objectAddr uint64, oh *core.ObjectHea
 	}
 
 	// 4. Upsert logic: modify if exists, add if not exists
+	messages := append([]*core.HeaderMessage(nil), oh.Messages...)
 	err = upsertAttributeMessage(fw, objectAddr, oh, existingIndex, attrMsg, name, value, sb)
 	if err != nil {
 		return err
 	}
 
 	// 5. Write updated header back to disk
+	if err := reserveObjectHeader(fw, objectAddr, oh); err != nil {
+		oh.Messages = messages // Keep the header as it is on disk
+		return err
+	}
 	err = core.WriteObjectHeader(fw.writer, objectAddr, oh, sb)
 	if err != nil {
 		return fmt.Errorf("failed to write object header: %w", err)
@@ -298,6 +306,41 @@ func writeCompactAttribute(fw *FileWriter, objectAddr uint64, oh *core.ObjectHea
 	return nil
 }
 
+// reserveObjectHeader updates the allocator for the object header at addr
+// before it is rewritten as oh. Headers are rewritten in place: one that
+// grows takes the space that follows it, which must be freed space or past
+// the end of the file, and one that shrinks releases the space it no
+// longer uses.
+func reserveObjectHeader(fw *FileWriter, addr uint64, oh *core.ObjectHeader) error {
+	ohw := &core.ObjectHeaderWriter{Version: oh.Version, Flags: oh.Flags}
+	for _, msg := range oh.Messages {
+		ohw.Messages = append(ohw.Messages, core.MessageWriter{Type: msg.Type, Data: msg.Data})
+	}
+	return resizeObjectHeader(fw, addr, oh, ohw.Size())
+}
+
+// resizeObjectHeader updates the allocator for the object header at addr,
+// currently stored as oh, to take size bytes. See reserveObjectHeader.
+func resizeObjectHeader(fw *FileWriter, addr uint64, oh *core.ObjectHeader, size uint64) error {
+	regions, err := core.ObjectHeaderRegions(fw.writer.Reader(), addr, oh, fw.file.sb)
+	if err != nil {
+		return fmt.Errorf("failed to read object header size: %w", err)
+	}
+	current := regions[0].Size
+
+	switch {
+	case size > current:
+		if err := fw.writer.Allocator().Extend(addr+current, size-current); err != nil {
+			return fmt.Errorf("object header at 0x%x cannot grow: %w", addr, err)
+		}
+	case size < current:
+		if err := fw.writer.Free(addr+size, current-size); err != nil {
+			return fmt.Errorf("failed to free object header space: %w", err)
+		}
+	}
+	return nil
+}
+
 // upsertAttributeMessage handles the upsert logic for attribute messages in compact storage.
 // If attribute exists (existingIndex >= 0), it replaces the message data.
 // If attribute doesn't exist (existingIndex < 0), it adds a new message.
@@ -307,7 +350,9 @@ func upsertAttributeMessage(fw *FileWriter, objectAddr uint64, oh *core.ObjectHe
 ) error {
 	if existingIndex >= 0 {
 		// Attribute exists → Replace (upsert semantics)
-		oh.Messages[existingIndex].Data = attrMsg
+		msg := *oh.Messages[existingIndex]
+		msg.Data = attrMsg
+		oh.Messages[existingIndex] = &msg
 		return nil
 	}
 
@@ -356,7 +401,7 @@ func writeAttributeWithCachedHeader(fw *FileWriter, objectAddr uint64, oh *core.
 		}
 	}
 
-	if compactCount < MaxCompactAttributes {
+	if compactCount < fw.config.maxCompactAttributes() {
 		// Still compact → add compact attribute
 		return writeCompactAttribute(fw, objectAddr, oh, name, value, sb)
 	}
@@ -559,6 +604,9 @@ func deleteCompactAttributeFromHeader(fw *FileWriter, objectAddr uint64, oh *cor
 	oh.Messages = append(oh.Messages[:msgIndex], oh.Messages[msgIndex+1:]...)
 
 	// Write back object header to disk
+	if err := reserveObjectHeader(fw, objectAddr, oh); err != nil {
+		return err
+	}
 	err := core.WriteObjectHeader(fw.writer, objectAddr, oh, sb)
 	if err != nil {
 		return fmt.Errorf("failed to write object header after deletion: %w", err)
@@ -869,21 +917,13 @@ func transitionToDenseAttributes(fw *FileWriter, objectAddr uint64, oh *core.Obj
 		Data: tempAttrInfoMsg,
 	})
 
-	objectHeaderSize := ohWriter.Size()
-	objectHeaderEnd := objectAddr + objectHeaderSize
-
-	// 8. Update allocator to ensure dense storage allocated AFTER object header
-	allocator := fw.writer.Allocator()
-	if allocator.EndOfFile() < objectHeaderEnd {
-		bytesToAdvance := objectHeaderEnd - allocator.EndOfFile()
-		_, err = allocator.Allocate(bytesToAdvance)
-		if err != nil {
-			return fmt.Errorf("failed to advance allocator past object header: %w", err)
-		}
+	// 8. Update allocator so dense storage does not take the header's space
+	if err := resizeObjectHeader(fw, objectAddr, oh, ohWriter.Size()); err != nil {
+		return err
 	}
 
-	// 9. Write dense storage - allocator will place it AFTER object header
-	attrInfo, err := daw.WriteToFile(fw.writer, allocator, sb)
+	// 9. Write dense storage
+	attrInfo, err := daw.WriteToFile(fw.writer, fw.writer.Allocator(), sb)
 	if err != nil {
 		return fmt.Errorf("failed to write dense storage: %w", err)
 	}
@@ -920,6 +960,14 @@ func transitionToDenseAttributes(fw *FileWriter, objectAddr uint64, oh *core.Obj
 // inferDatatypeFromValue infers HDF5 datatype and dimensions from a Go value.
 // Returns datatype message, dataspace message, and error.
 func inferDatatypeFromValue(value interface{}) (*core.DatatypeMessage, *core.DataspaceMessage, error) {
+	// Attributes read from a file keep their datatype and dataspace.
+	if attr, ok := value.(*core.Attribute); ok {
+		if attr.Datatype == nil || attr.Dataspace == nil {
+			return nil, nil, fmt.Errorf("attribute %q has no datatype or dataspace", attr.Name)
+		}
+		return attr.Datatype, attr.Dataspace, nil
+	}
+
 	v := reflect.ValueOf(value)
 
 	// Handle scalar types
@@ -1134,6 +1182,10 @@ func inferSlice(v reflect.Value) (*core.DatatypeMessage, *core.DataspaceMessage,
 
 // encodeAttributeValue encodes a Go value to bytes for attribute storage.
 func encodeAttributeValue(value interface{}) ([]byte, error) {
+	if attr, ok := value.(*core.Attribute); ok {
+		return attr.Data, nil
+	}
+
 	v := reflect.ValueOf(value)
 
 	switch v.Kind() {
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/meko-christian/go-hdf5/internal/core"
 	"github.com/stretchr/testify/require"
 )
 
@@ -135,3 +136,36 @@ func TestDenseAttributes_Integration_UpsertSemantics(t *testing.T) {
 	}
 	t.Fatal("attr1 not found")
 }
+
+// TestDenseAttributes_Integration_MaxCompact tests the WithMaxCompactAttributes option.
+func TestDenseAttributes_Integration_MaxCompact(t *testing.T) {
+	testFile := filepath.Join(t.TempDir(), "dense_max_compact.h5")
+
+	fw, err := CreateForWrite(testFile, CreateTruncate, WithMaxCompactAttributes(2))
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{10})
+	require.NoError(t, err)
+	for i := 0; i < 4; i++ {
+		require.NoError(t, ds.WriteAttribute(fmt.Sprintf("attr%d", i), int32(i)))
+	}
+	require.NoError(t, fw.Close())
+
+	f, err := Open(testFile)
+	require.NoError(t, err)
+	defer f.Close()
+
+	dataset, err := f.OpenDataset("/data")
+	require.NoError(t, err)
+
+	// The third attribute moved all of them to dense storage.
+	header, err := core.ReadObjectHeader(f.r, dataset.Address(), f.sb)
+	require.NoError(t, err)
+	for _, msg := range header.Messages {
+		require.NotEqual(t, core.MsgAttribute, msg.Type, "attributes should be stored densely")
+	}
+
+	attrs, err := dataset.Attributes()
+	require.NoError(t, err)
+	require.Len(t, attrs, 4)
+}
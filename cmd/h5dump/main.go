@@ -0,0 +1,149 @@
+// Package main provides a command-line utility to display the contents of
+// HDF5 files in the Data Description Language of the h5dump tool.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+
+	hdf5 "github.com/meko-christian/go-hdf5"
+)
+
+// pathList collects the values of a flag given several times.
+type pathList []string
+
+func (l *pathList) String() string {
+	return strings.Join(*l, ",")
+}
+
+func (l *pathList) Set(s string) error {
+	*l = append(*l, s)
+	return nil
+}
+
+func main() {
+	var datasets, attributes, groups pathList
+
+	// Define command-line flags
+	headerOnly := flag.Bool("H", false, "Print the header only, no data")
+	properties := flag.Bool("p", false, "Print dataset storage layout, filters and fill values")
+	flag.Var(&datasets, "d", "Print the dataset at this path (may be repeated)")
+	flag.Var(&attributes, "a", "Print the attribute at this path (may be repeated)")
+	flag.Var(&groups, "g", "Print the group at this path (may be repeated)")
+	start := flag.String("s", "", "Start of the hyperslab printed for -d datasets, e.g. 0,2")
+	stride := flag.String("S", "", "Stride of the hyperslab (default 1 in every dimension)")
+	count := flag.String("c", "", "Number of blocks in the hyperslab (default: to the end)")
+	block := flag.String("k", "", "Block size of the hyperslab (default 1 in every dimension)")
+	flag.Parse()
+
+	args := flag.Args()
+	if len(args) != 1 {
+		fmt.Println("Usage: h5dump [flags] <file.h5>")
+		fmt.Println("Flags:")
+		flag.PrintDefaults()
+		return
+	}
+
+	f, err := hdf5.Open(args[0])
+	if err != nil {
+		log.Fatalf("Failed to open file: %v", err)
+	}
+	defer f.Close()
+
+	var opts []hdf5.DumpOption
+	if *headerOnly {
+		opts = append(opts, hdf5.DumpHeaderOnly())
+	}
+	if *properties {
+		opts = append(opts, hdf5.DumpProperties())
+	}
+	for _, path := range groups {
+		opts = append(opts, hdf5.DumpGroup(path))
+	}
+	for _, path := range datasets {
+		opts = append(opts, hdf5.DumpDataset(path))
+	}
+	for _, path := range attributes {
+		opts = append(opts, hdf5.DumpAttribute(path))
+	}
+
+	if *start != "" || *stride != "" || *count != "" || *block != "" {
+		if len(datasets) == 0 {
+			log.Fatalf("Hyperslab flags -s, -S, -c and -k require -d")
+		}
+		sel, err := selection(f, datasets[0], *start, *stride, *count, *block)
+		if err != nil {
+			log.Fatalf("Invalid hyperslab: %v", err)
+		}
+		opts = append(opts, hdf5.DumpSelection(sel))
+	}
+
+	if err := f.Dump(os.Stdout, opts...); err != nil {
+		log.Fatalf("Dump failed: %v", err)
+	}
+}
+
+// selection builds the hyperslab given by the -s, -S, -c and -k flags. Unset
+// starts default to 0 and unset counts to the end of the dataset.
+func selection(f *hdf5.File, path, start, stride, count, block string) (*hdf5.HyperslabSelection, error) {
+	ds, err := f.OpenDataset(path)
+	if err != nil {
+		return nil, err
+	}
+	shape, err := ds.Shape()
+	if err != nil {
+		return nil, err
+	}
+
+	sel := &hdf5.HyperslabSelection{}
+	if sel.Start, err = parseDims(start, len(shape), 0); err != nil {
+		return nil, fmt.Errorf("start: %w", err)
+	}
+	if sel.Stride, err = parseDims(stride, len(shape), 1); err != nil {
+		return nil, fmt.Errorf("stride: %w", err)
+	}
+	if sel.Block, err = parseDims(block, len(shape), 1); err != nil {
+		return nil, fmt.Errorf("block: %w", err)
+	}
+	if count == "" {
+		sel.Count = make([]uint64, len(shape))
+		for i, dim := range shape {
+			if sel.Start[i]+sel.Block[i] > dim || sel.Stride[i] == 0 {
+				return nil, fmt.Errorf("no complete block fits in dimension %d of size %d", i, dim)
+			}
+			sel.Count[i] = (dim-sel.Start[i]-sel.Block[i])/sel.Stride[i] + 1
+		}
+	} else if sel.Count, err = parseDims(count, len(shape), 1); err != nil {
+		return nil, fmt.Errorf("count: %w", err)
+	}
+	return sel, nil
+}
+
+// parseDims parses one value per dimension, separated by "," or "x". An empty
+// string gives def in every dimension.
+func parseDims(s string, rank int, def uint64) ([]uint64, error) {
+	dims := make([]uint64, rank)
+	if s == "" {
+		for i := range dims {
+			dims[i] = def
+		}
+		return dims, nil
+	}
+
+	fields := strings.FieldsFunc(s, func(r rune) bool { return r == 'x' || r == ',' })
+	if len(fields) != rank {
+		return nil, fmt.Errorf("got %d values for a dataset of rank %d", len(fields), rank)
+	}
+	for i, field := range fields {
+		n, err := strconv.ParseUint(strings.TrimSpace(field), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		dims[i] = n
+	}
+	return dims, nil
+}
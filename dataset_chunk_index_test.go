@@ -0,0 +1,165 @@
+package hdf5
+
+import (
+	"os"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+// Files written with the latest library format use layout message v4 and
+// pick the chunk index from the dataset's dimensions.
+func TestChunkIndex_LayoutV4(t *testing.T) {
+	tests := []struct {
+		file   string
+		path   string
+		shape  []uint64
+		chunks int
+		want   func(i int) float64
+	}{
+		// Fixed array, plain and filtered; the v1 B-tree twin holds the same data.
+		{"tdset_idx.h5", "/dset_fixed", []uint64{20, 10}, 8, func(i int) float64 { return float64(i % 10) }},
+		{"tdset_idx.h5", "/dset_filter", []uint64{20, 10}, 8, func(i int) float64 { return float64(i % 10) }},
+		{"tdset_idx.h5", "/dset_btree", []uint64{20, 10}, 8, func(i int) float64 { return float64(i % 10) }},
+		{"test_ld.h5", "/DSET_TWO", []uint64{4, 10}, 10, func(i int) float64 { return float64(i) }},
+		// Implicit index: chunks allocated back to back at creation.
+		{"test_ld.h5", "/DSET_ALLOC_EARLY", []uint64{10}, 5, func(i int) float64 { return float64(i) }},
+		// Extensible array; only the chunks of the first three columns were written.
+		{"h5fc_ext1_f.h5", "/DSET_EA", []uint64{4, 6}, 2, func(i int) float64 {
+			if i%6 >= 3 {
+				return 0
+			}
+			return float64(i)
+		}},
+		// Filtered fixed array whose partial edge chunks are stored unfiltered.
+		{"h5fc_edge_v3.h5", "/DSET_EDGE", []uint64{12, 6}, 6, func(int) float64 { return 100 }},
+		// Version 2 B-tree without allocated chunks.
+		{"h5fc_non_v3.h5", "/DSET_NDATA_BT2", nil, 0, func(int) float64 { return 0 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+tt.path, func(t *testing.T) {
+			f, err := Open("testdata/hdf5_official/" + tt.file)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ds, err := f.OpenDataset(tt.path)
+			require.NoError(t, err)
+
+			data, err := ds.Read()
+			require.NoError(t, err)
+			if tt.shape != nil {
+				shape, err := ds.Shape()
+				require.NoError(t, err)
+				require.Equal(t, tt.shape, shape)
+			}
+			for i, v := range data {
+				require.Equal(t, tt.want(i), v, "element %d", i)
+			}
+
+			it, err := ds.ChunkIterator()
+			require.NoError(t, err)
+			require.Equal(t, tt.chunks, it.Total())
+		})
+	}
+}
+
+func TestChunkIndex_ReadSlice(t *testing.T) {
+	f, err := Open("testdata/hdf5_official/tdset_idx.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	btree, err := f.OpenDataset("/dset_btree")
+	require.NoError(t, err)
+
+	for _, path := range []string{"/dset_fixed", "/dset_filter"} {
+		ds, err := f.OpenDataset(path)
+		require.NoError(t, err)
+
+		// Chunk [1 1] covers rows 5-9, columns 5-9.
+		slice, err := ds.ReadSlice([]uint64{5, 5}, []uint64{5, 5})
+		require.NoError(t, err, path)
+		require.Len(t, slice, 25)
+		for i, v := range slice.([]float64) {
+			require.Equal(t, float64(5+i%5), v, path)
+		}
+
+		// A selection spanning four chunks matches the v1 B-tree dataset.
+		got, err := ds.ReadSlice([]uint64{3, 4}, []uint64{4, 4})
+		require.NoError(t, err, path)
+		want, err := btree.ReadSlice([]uint64{3, 4}, []uint64{4, 4})
+		require.NoError(t, err)
+		require.Equal(t, want, got, path)
+	}
+}
+
+// chunk_index_v4.h5 is written by the C library (testdata/create_chunk_index.c),
+// so the single chunk and version 2 B-tree decoders are checked against files
+// this package did not encode.
+func TestChunkIndex_SingleChunkAndBTreeV2(t *testing.T) {
+	const file = "testdata/chunk_index_v4.h5"
+	if _, err := os.Stat(file); err != nil {
+		t.Skip("chunk_index_v4.h5 missing; build and run testdata/create_chunk_index.c")
+	}
+
+	tests := []struct {
+		path   string
+		index  core.ChunkIndexType
+		shape  []uint64
+		chunks int
+	}{
+		{"/single", core.ChunkIndexSingleChunk, []uint64{6, 8}, 1},
+		{"/single_filter", core.ChunkIndexSingleChunk, []uint64{6, 8}, 1},
+		// Records of type 10 (unfiltered) and 11 (filtered) in a tree with
+		// internal nodes.
+		{"/btree2", core.ChunkIndexBTreeV2, []uint64{40, 40}, 400},
+		{"/btree2_filter", core.ChunkIndexBTreeV2, []uint64{40, 40}, 400},
+	}
+
+	f, err := Open(file)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			ds, err := f.OpenDataset(tt.path)
+			require.NoError(t, err)
+
+			header, err := core.ReadObjectHeader(f.r, ds.address, f.sb)
+			require.NoError(t, err)
+			info, err := core.ReadDatasetInfo(header, f.sb)
+			require.NoError(t, err)
+			require.Equal(t, uint8(4), info.Layout.Version)
+			require.Equal(t, tt.index, info.Layout.ChunkIndex)
+			if tt.index == core.ChunkIndexBTreeV2 {
+				bt, err := core.OpenBTreeV2(f.r, info.Layout.DataAddress, f.sb)
+				require.NoError(t, err)
+				require.Positive(t, bt.Depth)
+				require.Equal(t, uint64(tt.chunks), bt.TotalRecords)
+			}
+
+			shape, err := ds.Shape()
+			require.NoError(t, err)
+			require.Equal(t, tt.shape, shape)
+
+			var data []int32
+			require.NoError(t, ds.ReadAs(&data))
+			for i, v := range data {
+				require.Equal(t, int32(i), v, "element %d", i)
+			}
+
+			it, err := ds.ChunkIterator()
+			require.NoError(t, err)
+			require.Equal(t, tt.chunks, it.Total())
+
+			// A block spanning several chunks.
+			slice, err := ds.ReadSlice([]uint64{1, 3}, []uint64{3, 4})
+			require.NoError(t, err)
+			for i, v := range slice.([]float64) {
+				row, col := 1+i/4, 3+i%4
+				require.Equal(t, float64(row*int(tt.shape[1])+col), v, "slice element %d", i)
+			}
+		})
+	}
+}
@@ -65,7 +65,7 @@ func (d *Dataset) ChunkIterator() (*ChunkIterator, error) {
 //	}
 func (d *Dataset) ChunkIteratorWithContext(ctx context.Context) (*ChunkIterator, error) {
 	// Read object header to get layout info.
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object header: %w", err)
 	}
@@ -105,7 +105,7 @@ func (d *Dataset) ChunkIteratorWithContext(ctx context.Context) (*ChunkIterator,
 		return nil, fmt.Errorf("failed to parse dataspace: %w", err)
 	}
 
-	// Get chunk coordinates from B-tree.
+	// Get chunk coordinates from the chunk index.
 	chunkCoords, err := d.collectChunkCoordinates(layout, dataspace)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect chunk coordinates: %w", err)
@@ -114,28 +114,16 @@ func (d *Dataset) ChunkIteratorWithContext(ctx context.Context) (*ChunkIterator,
 	return &ChunkIterator{
 		dataset:     d,
 		chunkCoords: chunkCoords,
-		chunkDims:   layout.ChunkSize,
+		chunkDims:   layout.ChunkSize[:len(dataspace.Dimensions)], // Drop the trailing element size dimension
 		datasetDims: dataspace.Dimensions,
 		current:     0,
 		ctx:         ctx,
 	}, nil
 }
 
-// collectChunkCoordinates retrieves all chunk coordinates from the B-tree.
+// collectChunkCoordinates retrieves all chunk coordinates from the chunk index.
 func (d *Dataset) collectChunkCoordinates(layout *core.DataLayoutMessage, dataspace *core.DataspaceMessage) ([][]uint64, error) {
-	// Parse B-tree to get all chunks.
-	btreeNode, err := core.ParseBTreeV1Node(
-		d.file.osFile,
-		layout.DataAddress,
-		d.file.sb.OffsetSize,
-		len(layout.ChunkSize),
-		layout.ChunkSize,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse chunk B-tree: %w", err)
-	}
-
-	allChunks, err := btreeNode.CollectAllChunks(d.file.osFile, d.file.sb.OffsetSize, layout.ChunkSize)
+	allChunks, err := core.CollectChunks(d.file.r, layout, dataspace, d.file.sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect chunks: %w", err)
 	}
@@ -0,0 +1,271 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFillValue_Corpus(t *testing.T) {
+	tests := []struct {
+		file      string
+		path      string
+		fill      []byte
+		allocTime AllocTime
+	}{
+		// Fill value message version 2, value 99.
+		{"fill18.h5", "/DS1", []byte{99, 0, 0, 0}, AllocTimeEarly},
+		// Old-style fill value message holding big-endian 4444.
+		{"fill_old.h5", "/dset2", []byte{0, 0, 0x11, 0x5C}, AllocTimeDefault},
+		// No fill value: zero bytes.
+		{"fill_old.h5", "/dset1", []byte{0, 0, 0, 0}, AllocTimeDefault},
+		{"h5repack_fill.h5", "/dset_fill", []byte{2, 0, 0, 0}, AllocTimeLate},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+tt.path, func(t *testing.T) {
+			f, err := Open("testdata/hdf5_official/" + tt.file)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ds, err := f.OpenDataset(tt.path)
+			require.NoError(t, err)
+
+			fill, err := ds.FillValue()
+			require.NoError(t, err)
+			require.Equal(t, tt.fill, fill)
+
+			meta, err := ds.Meta()
+			require.NoError(t, err)
+			require.Equal(t, FillTimeIfSet, meta.FillTime)
+			require.Equal(t, tt.allocTime, meta.AllocTime)
+		})
+	}
+}
+
+func TestFillValue_CorruptMessage(t *testing.T) {
+	// Fill value sizes larger than the message are rejected (CVE-2018-11206).
+	for _, file := range []string{"tCVE_2018_11206_fill_old.h5", "tCVE_2018_11206_fill_new.h5"} {
+		f, err := Open("testdata/hdf5_official/" + file)
+		require.NoError(t, err)
+
+		var checked bool
+		f.Walk(func(_ string, obj Object) {
+			ds, ok := obj.(*Dataset)
+			if !ok {
+				return
+			}
+			if _, err := ds.Meta(); err != nil {
+				require.Contains(t, err.Error(), "fill value")
+				checked = true
+			}
+		})
+		require.True(t, checked, file)
+		require.NoError(t, f.Close())
+	}
+}
+
+func TestFillValue_SparseChunked(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "sparse.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{5},
+		WithChunkDims([]uint64{5}),
+		WithMaxDims([]uint64{Unlimited}),
+		WithFillValue(int32(-1)))
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{0, 1, 2, 3, 4}))
+
+	// The chunks of the extended region are never written.
+	require.NoError(t, ds.Resize([]uint64{15}))
+
+	// Unwritten chunked datasets have no chunks at all.
+	_, err = fw.CreateDataset("/empty", Float64, []uint64{4, 6},
+		WithChunkDims([]uint64{2, 3}),
+		WithFillValue(1.5))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	sparse, err := f.OpenDataset("/data")
+	require.NoError(t, err)
+
+	fill, err := sparse.FillValue()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0xFF, 0xFF, 0xFF, 0xFF}, fill)
+
+	data, err := sparse.Read()
+	require.NoError(t, err)
+	require.Len(t, data, 15)
+	for i, v := range data {
+		want := float64(i)
+		if i >= 5 {
+			want = -1
+		}
+		require.Equal(t, want, v, "element %d", i)
+	}
+
+	slice, err := sparse.ReadSlice([]uint64{3}, []uint64{4})
+	require.NoError(t, err)
+	require.Equal(t, []float64{3, 4, -1, -1}, slice)
+
+	empty, err := f.OpenDataset("/empty")
+	require.NoError(t, err)
+
+	data, err = empty.Read()
+	require.NoError(t, err)
+	require.Len(t, data, 24)
+	for _, v := range data {
+		require.Equal(t, 1.5, v)
+	}
+
+	slice, err = empty.ReadSlice([]uint64{1, 2}, []uint64{2, 2})
+	require.NoError(t, err)
+	require.Equal(t, []float64{1.5, 1.5, 1.5, 1.5}, slice)
+}
+
+func TestFillValue_AllocTime(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "alloc.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	// Contiguous storage is allocated and filled at creation.
+	_, err = fw.CreateDataset("/early", Int16, []uint64{3},
+		WithFillValue(int16(7)))
+	require.NoError(t, err)
+
+	// Late allocation leaves the storage unallocated until written.
+	_, err = fw.CreateDataset("/late", Uint8, []uint64{4},
+		WithFillValue(uint8(3)),
+		WithAllocTime(AllocTimeLate))
+	require.NoError(t, err)
+
+	written, err := fw.CreateDataset("/late_written", Float32, []uint64{2},
+		WithAllocTime(AllocTimeLate))
+	require.NoError(t, err)
+	require.NoError(t, written.Write([]float32{1.25, 2.5}))
+
+	// Early chunk allocation writes every chunk at creation.
+	_, err = fw.CreateDataset("/chunked_early", Int64, []uint64{2, 3},
+		WithChunkDims([]uint64{2, 3}),
+		WithFillValue(int64(42)),
+		WithAllocTime(AllocTimeEarly))
+	require.NoError(t, err)
+
+	// Fill time "never" keeps the zero-filled storage.
+	_, err = fw.CreateDataset("/never", Int32, []uint64{2},
+		WithFillValue(int32(5)),
+		WithFillTime(FillTimeNever))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	tests := []struct {
+		path      string
+		want      []float64
+		allocTime AllocTime
+		fillTime  FillTime
+		chunks    int
+	}{
+		{"/early", []float64{7, 7, 7}, AllocTimeEarly, FillTimeIfSet, 0},
+		{"/late", []float64{3, 3, 3, 3}, AllocTimeLate, FillTimeIfSet, 0},
+		{"/late_written", []float64{1.25, 2.5}, AllocTimeLate, FillTimeIfSet, 0},
+		{"/chunked_early", []float64{42, 42, 42, 42, 42, 42}, AllocTimeEarly, FillTimeIfSet, 1},
+		{"/never", []float64{0, 0}, AllocTimeEarly, FillTimeNever, 0},
+	}
+	for _, tt := range tests {
+		ds, err := f.OpenDataset(tt.path)
+		require.NoError(t, err, tt.path)
+
+		data, err := ds.Read()
+		require.NoError(t, err, tt.path)
+		require.Equal(t, tt.want, data, tt.path)
+
+		meta, err := ds.Meta()
+		require.NoError(t, err, tt.path)
+		require.Equal(t, tt.allocTime, meta.AllocTime, tt.path)
+		require.Equal(t, tt.fillTime, meta.FillTime, tt.path)
+
+		if tt.chunks > 0 {
+			it, err := ds.ChunkIterator()
+			require.NoError(t, err, tt.path)
+			require.Equal(t, tt.chunks, it.Total(), tt.path)
+		}
+	}
+}
+
+func TestFillValue_Options(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "options.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	defer func() { _ = fw.Close() }()
+
+	// Datasets without fill options carry no fill value message.
+	_, err = fw.CreateDataset("/plain", Int32, []uint64{2})
+	require.NoError(t, err)
+
+	_, err = fw.CreateDataset("/name", String, []uint64{2},
+		WithStringSize(4), WithFillValue("n/a"))
+	require.NoError(t, err)
+
+	_, err = fw.CreateDataset("/vec", ArrayFloat32, []uint64{2},
+		WithArrayDims([]uint64{2}), WithFillValue([]float32{1, 2}))
+	require.NoError(t, err)
+
+	_, err = fw.CreateDataset("/raw", Float64, []uint64{2},
+		WithFillValue(binary.LittleEndian.AppendUint64(nil, math.Float64bits(math.NaN()))))
+	require.NoError(t, err)
+
+	// The fill value must match the element type.
+	_, err = fw.CreateDataset("/bad_type", Int32, []uint64{2}, WithFillValue(1.5))
+	require.ErrorContains(t, err, "invalid fill value")
+
+	_, err = fw.CreateDataset("/bad_size", Int32, []uint64{2}, WithFillValue([]byte{1, 2}))
+	require.ErrorContains(t, err, "invalid fill value")
+
+	_, err = fw.CreateDataset("/bad_vlen", VLenString, []uint64{2}, WithFillValue("x"))
+	require.ErrorContains(t, err, "variable-length")
+
+	_, err = fw.CreateDataset("/bad_time", Int32, []uint64{2}, WithFillTime(FillTime(9)))
+	require.ErrorContains(t, err, "invalid fill time")
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	tests := []struct {
+		path string
+		fill []byte
+	}{
+		{"/plain", []byte{0, 0, 0, 0}},
+		{"/name", []byte("n/a\x00")},
+		{"/vec", []byte{0, 0, 0x80, 0x3F, 0, 0, 0, 0x40}},
+		{"/raw", binary.LittleEndian.AppendUint64(nil, math.Float64bits(math.NaN()))},
+	}
+	for _, tt := range tests {
+		ds, err := f.OpenDataset(tt.path)
+		require.NoError(t, err, tt.path)
+
+		fill, err := ds.FillValue()
+		require.NoError(t, err, tt.path)
+		require.Equal(t, tt.fill, fill, tt.path)
+	}
+
+	strs, err := mustOpenDataset(t, f, "/name").ReadStrings()
+	require.NoError(t, err)
+	require.Equal(t, []string{"n/a", "n/a"}, strs)
+}
@@ -0,0 +1,72 @@
+package hdf5
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// Files written by HDF5 1.4/1.6 use data layout message versions 1 and 2.
+func TestLegacyLayout(t *testing.T) {
+	tests := []struct {
+		file   string
+		path   string
+		shape  []uint64
+		layout LayoutClass
+		want   func(i int) float64 // nil if reading must fail
+	}{
+		// Contiguous layout, version 2.
+		{"filespace_1_6.h5", "/dset", []uint64{100}, LayoutContiguous, func(i int) float64 { return float64(i) }},
+		// Chunked layout, version 1, plain and deflated.
+		{"btree_idx_1_6.h5", "/dset", []uint64{10}, LayoutChunked, func(i int) float64 { return float64(i) }},
+		{"btree_idx_1_6.h5", "/dset_filter", []uint64{10}, LayoutChunked, func(i int) float64 { return float64(i) }},
+		{"deflate.h5", "/Dataset1", []uint64{100, 200}, LayoutChunked, func(i int) float64 { return float64(i % 5) }},
+		// Chunked 3D big-endian doubles: out[i][j][k] = 83 + 5i + j + 2k.
+		{"binfp64.h5", "/fp/bin/64-bit", []uint64{5, 3, 4}, LayoutChunked, func(n int) float64 {
+			i, j, k := n/12, n/4%3, n%4
+			return float64(83 + 5*i + j + 2*k)
+		}},
+		// Contiguous layout, version 2, of 2^33 x (2^28+1) int32 elements
+		// without storage; the layout keeps the dimensions truncated to 32
+		// bits. Too large to read.
+		{"h5repack_layouto.h5", "/Dataset", []uint64{1 << 33, 1<<28 + 1}, LayoutContiguous, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file+tt.path, func(t *testing.T) {
+			f, err := Open("testdata/hdf5_official/" + tt.file)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ds, err := f.OpenDataset(tt.path)
+			require.NoError(t, err)
+
+			meta, err := ds.Meta()
+			require.NoError(t, err)
+			require.Equal(t, tt.shape, meta.Shape)
+			require.Equal(t, tt.layout, meta.Layout)
+
+			data, err := ds.Read()
+			if tt.want == nil {
+				require.ErrorContains(t, err, "too large")
+				return
+			}
+			require.NoError(t, err)
+			n := 1
+			for _, d := range tt.shape {
+				n *= int(d)
+			}
+			require.Len(t, data, n)
+			for i, v := range data {
+				require.Equal(t, tt.want(i), v, "element %d", i)
+			}
+		})
+	}
+}
+
+func TestLegacyLayout_InvalidCompound(t *testing.T) {
+	// The layout is valid, but the named compound datatype has no members,
+	// which the C library rejects when decoding it as well.
+	_, err := Open("testdata/hdf5_official/bad_compound.h5")
+	require.ErrorContains(t, err, "compound datatype has no members")
+}
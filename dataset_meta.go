@@ -0,0 +1,370 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// DatatypeClass identifies the HDF5 class of a dataset's element type.
+// Values match the class numbers used in the HDF5 file format.
+type DatatypeClass uint8
+
+// Datatype classes reported by DtypeInfo.
+const (
+	ClassInteger   DatatypeClass = 0  // Fixed-point (integers).
+	ClassFloat     DatatypeClass = 1  // Floating-point.
+	ClassTime      DatatypeClass = 2  // Time.
+	ClassString    DatatypeClass = 3  // Fixed-length string.
+	ClassBitfield  DatatypeClass = 4  // Bitfield.
+	ClassOpaque    DatatypeClass = 5  // Opaque.
+	ClassCompound  DatatypeClass = 6  // Compound.
+	ClassReference DatatypeClass = 7  // Reference.
+	ClassEnum      DatatypeClass = 8  // Enumerated.
+	ClassVarLen    DatatypeClass = 9  // Variable-length (sequences and strings).
+	ClassArray     DatatypeClass = 10 // Array.
+	ClassComplex   DatatypeClass = 11 // Complex (HDF5 2.0+).
+)
+
+// String returns the lower-case class name.
+func (c DatatypeClass) String() string {
+	switch c {
+	case ClassInteger:
+		return "integer"
+	case ClassFloat:
+		return "float"
+	case ClassTime:
+		return "time"
+	case ClassString:
+		return "string"
+	case ClassBitfield:
+		return "bitfield"
+	case ClassOpaque:
+		return "opaque"
+	case ClassCompound:
+		return "compound"
+	case ClassReference:
+		return "reference"
+	case ClassEnum:
+		return "enum"
+	case ClassVarLen:
+		return "vlen"
+	case ClassArray:
+		return "array"
+	case ClassComplex:
+		return "complex"
+	default:
+		return fmt.Sprintf("class_%d", uint8(c))
+	}
+}
+
+// DtypeInfo describes a dataset's element type.
+type DtypeInfo struct {
+	Class DatatypeClass
+	Size  uint32 // Size of one element in bytes.
+
+	// Signed is true for signed (two's complement) integers.
+	Signed bool
+
+	// ByteOrder is the stored byte order of integer, float, bitfield and time
+	// types; nil for all other classes.
+	ByteOrder binary.ByteOrder
+}
+
+// String returns a short description such as "int32", "float64" or "string(16)".
+func (t DtypeInfo) String() string {
+	switch t.Class {
+	case ClassInteger:
+		if t.Signed {
+			return fmt.Sprintf("int%d", t.Size*8)
+		}
+		return fmt.Sprintf("uint%d", t.Size*8)
+	case ClassFloat:
+		return fmt.Sprintf("float%d", t.Size*8)
+	default:
+		return fmt.Sprintf("%s(%d)", t.Class, t.Size)
+	}
+}
+
+// LayoutClass identifies how dataset storage is organized in the file.
+type LayoutClass uint8
+
+// Storage layouts reported by DatasetMeta.
+const (
+	LayoutCompact    LayoutClass = 0 // Data stored in the object header.
+	LayoutContiguous LayoutClass = 1 // Data stored as one block.
+	LayoutChunked    LayoutClass = 2 // Data stored in separately indexed chunks.
+	LayoutVirtual    LayoutClass = 3 // Data mapped from other datasets (VDS).
+)
+
+// String returns the lower-case layout name.
+func (l LayoutClass) String() string {
+	switch l {
+	case LayoutCompact:
+		return "compact"
+	case LayoutContiguous:
+		return "contiguous"
+	case LayoutChunked:
+		return "chunked"
+	case LayoutVirtual:
+		return "virtual"
+	default:
+		return fmt.Sprintf("layout_%d", uint8(l))
+	}
+}
+
+// FilterID identifies a filter in a dataset's filter pipeline.
+// Values are the registered HDF5 filter identifiers.
+type FilterID uint16
+
+// Well-known filter identifiers.
+const (
+	FilterGZIP        FilterID = 1     // Deflate (GZIP) compression.
+	FilterShuffle     FilterID = 2     // Byte shuffle.
+	FilterFletcher32  FilterID = 3     // Fletcher32 checksum.
+	FilterSZIP        FilterID = 4     // SZIP compression.
+	FilterNBit        FilterID = 5     // N-bit packing.
+	FilterScaleOffset FilterID = 6     // Scale-offset.
+	FilterBZIP2       FilterID = 307   // BZIP2 compression.
+	FilterLZF         FilterID = 32000 // LZF compression (PyTables/h5py).
+)
+
+// String returns the filter's common name.
+func (id FilterID) String() string {
+	return core.FilterID(id).String()
+}
+
+// FilterInfo describes one filter of a dataset's filter pipeline.
+type FilterInfo struct {
+	ID FilterID
+
+	// Name is the name stored in the file, or the common name for
+	// well-known filters stored without one.
+	Name string
+
+	// Optional is true if the filter may be skipped for chunks where it fails.
+	Optional bool
+
+	// ClientData holds the filter parameters (e.g. the GZIP level).
+	ClientData []uint32
+}
+
+// DatasetMeta is a structured description of a dataset, built from its
+// object header without reading any data.
+type DatasetMeta struct {
+	// Shape holds the current dimension sizes; empty for scalar datasets.
+	Shape []uint64
+
+	// MaxShape holds the maximum dimension sizes. Unlimited dimensions are
+	// reported as Unlimited. Equal to Shape for fixed-size datasets.
+	MaxShape []uint64
+
+	Dtype  DtypeInfo
+	Layout LayoutClass
+
+	// ChunkShape holds the chunk dimensions; nil unless Layout is LayoutChunked.
+	ChunkShape []uint64
+
+	// Filters lists the filter pipeline in application order; nil if unfiltered.
+	Filters []FilterInfo
+
+	// FillValue is the value of elements that were never written, encoded as
+	// one element in the file's byte order; nil if the fill value is undefined.
+	// Datasets without a fill value read unwritten elements as zero bytes.
+	FillValue []byte
+
+	FillTime  FillTime
+	AllocTime AllocTime
+}
+
+// Meta returns structured metadata about the dataset without reading its values.
+//
+// Example:
+//
+//	meta, err := ds.Meta()
+//	if err != nil {
+//	    return err
+//	}
+//	fmt.Println(meta.Shape, meta.Dtype, meta.Layout, meta.ChunkShape)
+func (d *Dataset) Meta() (*DatasetMeta, error) {
+	info, err := d.info()
+	if err != nil {
+		return nil, err
+	}
+	return newDatasetMeta(info), nil
+}
+
+// info reads the dataset's metadata messages without reading its values.
+func (d *Dataset) info() (*core.DatasetInfo, error) {
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
+	if err != nil {
+		return nil, err
+	}
+
+	info, err := core.ReadDatasetInfo(header, d.file.sb)
+	if err != nil {
+		return nil, err
+	}
+
+	// The extent of a virtual dataset follows its source datasets.
+	if info.Layout.IsVirtual() {
+		return core.ReadVirtualDatasetInfo(d.file.reader(), header, d.file.sb)
+	}
+	return info, nil
+}
+
+// Shape returns the current dimension sizes of the dataset.
+func (d *Dataset) Shape() ([]uint64, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return nil, err
+	}
+	return meta.Shape, nil
+}
+
+// MaxShape returns the maximum dimension sizes of the dataset.
+// Unlimited dimensions are reported as Unlimited.
+func (d *Dataset) MaxShape() ([]uint64, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return nil, err
+	}
+	return meta.MaxShape, nil
+}
+
+// Dtype returns a description of the dataset's element type.
+func (d *Dataset) Dtype() (DtypeInfo, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return DtypeInfo{}, err
+	}
+	return meta.Dtype, nil
+}
+
+// Layout returns the dataset's storage layout.
+func (d *Dataset) Layout() (LayoutClass, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return 0, err
+	}
+	return meta.Layout, nil
+}
+
+// ChunkShape returns the chunk dimensions, or nil if the dataset is not chunked.
+func (d *Dataset) ChunkShape() ([]uint64, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return nil, err
+	}
+	return meta.ChunkShape, nil
+}
+
+// Filters returns the dataset's filter pipeline, or nil if it has none.
+func (d *Dataset) Filters() ([]FilterInfo, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return nil, err
+	}
+	return meta.Filters, nil
+}
+
+// FillValue returns the value of dataset elements that were never written,
+// encoded as one element in the file's byte order, or nil if it is undefined.
+func (d *Dataset) FillValue() ([]byte, error) {
+	meta, err := d.Meta()
+	if err != nil {
+		return nil, err
+	}
+	return meta.FillValue, nil
+}
+
+// newDatasetMeta converts parsed header messages to the public description.
+// All slices are copies, so callers may modify them freely.
+func newDatasetMeta(info *core.DatasetInfo) *DatasetMeta {
+	meta := &DatasetMeta{
+		Shape:  append([]uint64{}, info.Dataspace.Dimensions...),
+		Dtype:  newDtypeInfo(info.Datatype),
+		Layout: LayoutClass(info.Layout.Class),
+	}
+
+	if len(info.Dataspace.MaxDims) > 0 {
+		meta.MaxShape = append([]uint64{}, info.Dataspace.MaxDims...)
+	} else {
+		meta.MaxShape = append([]uint64{}, meta.Shape...)
+	}
+
+	if info.Layout.IsChunked() {
+		// Layout v3 stores an extra trailing chunk dimension holding the element size.
+		chunk := info.Layout.ChunkSize
+		if len(chunk) > len(meta.Shape) {
+			chunk = chunk[:len(meta.Shape)]
+		}
+		meta.ChunkShape = append([]uint64{}, chunk...)
+	}
+
+	setFillMeta(meta, info)
+
+	if info.FilterPipeline != nil {
+		meta.Filters = make([]FilterInfo, 0, len(info.FilterPipeline.Filters))
+		for _, f := range info.FilterPipeline.Filters {
+			name := f.Name
+			if name == "" {
+				name = f.ID.String()
+			}
+			meta.Filters = append(meta.Filters, FilterInfo{
+				ID:         FilterID(f.ID),
+				Name:       name,
+				Optional:   f.Flags&0x01 != 0,
+				ClientData: append([]uint32(nil), f.ClientData...),
+			})
+		}
+	}
+
+	return meta
+}
+
+// setFillMeta fills in the fill value properties. Datasets without a fill value
+// message have the library default: all zero bytes, written if set.
+func setFillMeta(meta *DatasetMeta, info *core.DatasetInfo) {
+	fv := info.FillValue
+	if fv == nil {
+		meta.FillValue = make([]byte, info.Datatype.Size)
+		return
+	}
+
+	switch fv.FillTime {
+	case core.FillTimeAlloc:
+		meta.FillTime = FillTimeAlloc
+	case core.FillTimeNever:
+		meta.FillTime = FillTimeNever
+	default:
+		meta.FillTime = FillTimeIfSet
+	}
+	meta.AllocTime = AllocTime(fv.AllocTime)
+
+	switch {
+	case !fv.Defined:
+		// Undefined fill values are reported as nil.
+	case len(fv.Value) == 0:
+		meta.FillValue = make([]byte, info.Datatype.Size)
+	default:
+		meta.FillValue = append([]byte{}, fv.Value...)
+	}
+}
+
+// newDtypeInfo converts a parsed datatype message to the public description.
+func newDtypeInfo(dt *core.DatatypeMessage) DtypeInfo {
+	t := DtypeInfo{
+		Class:  DatatypeClass(dt.Class),
+		Size:   dt.Size,
+		Signed: dt.IsSigned(),
+	}
+
+	switch dt.Class {
+	case core.DatatypeFixed, core.DatatypeFloat, core.DatatypeBitfield, core.DatatypeTime:
+		t.ByteOrder = dt.GetByteOrder()
+	}
+
+	return t
+}
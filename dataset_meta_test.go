@@ -0,0 +1,105 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDatasetMeta_FilteredChunked(t *testing.T) {
+	f, err := Open("testdata/gzip_test.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	t.Run("gzip 2d", func(t *testing.T) {
+		meta, err := mustOpenDataset(t, f, "/compressed_2d").Meta()
+		require.NoError(t, err)
+
+		require.Equal(t, []uint64{20, 30}, meta.Shape)
+		require.Equal(t, []uint64{20, 30}, meta.MaxShape)
+		require.Equal(t, LayoutChunked, meta.Layout)
+		require.Equal(t, []uint64{5, 10}, meta.ChunkShape)
+		require.Equal(t, DtypeInfo{Class: ClassFloat, Size: 8, ByteOrder: binary.LittleEndian}, meta.Dtype)
+		require.Equal(t, "float64", meta.Dtype.String())
+
+		require.Len(t, meta.Filters, 1)
+		require.Equal(t, FilterGZIP, meta.Filters[0].ID)
+		require.Equal(t, []uint32{6}, meta.Filters[0].ClientData)
+	})
+
+	t.Run("shuffle then gzip", func(t *testing.T) {
+		filters, err := mustOpenDataset(t, f, "/shuffled_compressed").Filters()
+		require.NoError(t, err)
+		require.Len(t, filters, 2)
+		require.Equal(t, FilterShuffle, filters[0].ID)
+		require.Equal(t, []uint32{4}, filters[0].ClientData)
+		require.Equal(t, FilterGZIP, filters[1].ID)
+		require.Equal(t, []uint32{9}, filters[1].ClientData)
+	})
+
+	t.Run("contiguous", func(t *testing.T) {
+		ds := mustOpenDataset(t, f, "/uncompressed")
+
+		layout, err := ds.Layout()
+		require.NoError(t, err)
+		require.Equal(t, LayoutContiguous, layout)
+
+		chunks, err := ds.ChunkShape()
+		require.NoError(t, err)
+		require.Nil(t, chunks)
+
+		filters, err := ds.Filters()
+		require.NoError(t, err)
+		require.Nil(t, filters)
+
+		dtype, err := ds.Dtype()
+		require.NoError(t, err)
+		require.Equal(t, "int32", dtype.String())
+		require.True(t, dtype.Signed)
+	})
+}
+
+func TestDatasetMeta_WrittenDatasets(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "meta.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/growable", Uint16, []uint64{10, 4},
+		WithChunkDims([]uint64{5, 2}), WithMaxDims([]uint64{Unlimited, 4}))
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/names", String, []uint64{3}, WithStringSize(8))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	growable := mustOpenDataset(t, f, "/growable")
+
+	shape, err := growable.Shape()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{10, 4}, shape)
+
+	maxShape, err := growable.MaxShape()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{Unlimited, 4}, maxShape)
+
+	chunks, err := growable.ChunkShape()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{5, 2}, chunks)
+
+	dtype, err := growable.Dtype()
+	require.NoError(t, err)
+	require.Equal(t, "uint16", dtype.String())
+	require.False(t, dtype.Signed)
+
+	dtype, err = mustOpenDataset(t, f, "/names").Dtype()
+	require.NoError(t, err)
+	require.Equal(t, ClassString, dtype.Class)
+	require.Equal(t, uint32(8), dtype.Size)
+	require.Nil(t, dtype.ByteOrder)
+	require.Equal(t, "string(8)", dtype.String())
+}
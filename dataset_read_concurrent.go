@@ -0,0 +1,30 @@
+package hdf5
+
+import "runtime"
+
+// WithReadConcurrency reads and decodes the chunks of chunked datasets with
+// n goroutines, which speeds up reading compressed datasets on multi-core
+// machines. A value of 0 or less uses runtime.GOMAXPROCS. By default chunks
+// are read one by one.
+//
+// It applies to reading whole datasets (Read, ReadAs, ReadStructs and the
+// other reads) and hyperslabs (ReadSlice, ReadHyperslab). Files opened
+// with OpenReader need a reader that allows parallel ReadAt calls, as the
+// io.ReaderAt contract requires.
+//
+// Example:
+//
+//	f, err := hdf5.Open("compressed.h5", hdf5.WithReadConcurrency(0))
+func WithReadConcurrency(n int) OpenOption {
+	if n <= 0 {
+		n = runtime.GOMAXPROCS(0)
+	}
+	return func(cfg *openConfig) {
+		cfg.readConcurrency = n
+	}
+}
+
+// ChunkReadConcurrency implements core.ChunkReadConcurrency.
+func (r *sourceReader) ChunkReadConcurrency() int {
+	return r.file.config.readConcurrency
+}
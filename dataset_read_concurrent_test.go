@@ -0,0 +1,122 @@
+package hdf5
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// createConcurrencyTestFile writes a 100x100 gzip-compressed dataset of
+// 10x10 chunks, of which the last row of chunks is never written.
+func createConcurrencyTestFile(t *testing.T) (string, []float64) {
+	t.Helper()
+
+	filename := filepath.Join(t.TempDir(), "chunks.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/grid", Float64, []uint64{100, 100},
+		WithChunkDims([]uint64{10, 10}), WithGZIPCompression(6), WithFillValue(float64(-1)))
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteAttribute("units", "K"))
+
+	want := make([]float64, 100*100)
+	for i := range want {
+		want[i] = -1
+	}
+	for row := uint64(0); row < 9; row++ {
+		for col := uint64(0); col < 10; col++ {
+			chunk := make([]float64, 10*10)
+			for i := range chunk {
+				v := float64(row*1000 + col*100 + uint64(i))
+				chunk[i] = v
+				want[(row*10+uint64(i)/10)*100+col*10+uint64(i)%10] = v
+			}
+			require.NoError(t, ds.WriteChunk([]uint64{row, col}, chunk))
+		}
+	}
+	require.NoError(t, fw.Close())
+	return filename, want
+}
+
+func TestWithReadConcurrency(t *testing.T) {
+	filename, want := createConcurrencyTestFile(t)
+
+	for _, n := range []int{1, 4, 0} {
+		t.Run(fmt.Sprintf("n=%d", n), func(t *testing.T) {
+			f, err := Open(filename, WithReadConcurrency(n))
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+			ds, err := f.OpenDataset("/grid")
+			require.NoError(t, err)
+
+			got, err := ds.Read()
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			// A slice over written and unwritten chunks.
+			slice, err := ds.ReadSlice([]uint64{85, 5}, []uint64{15, 10})
+			require.NoError(t, err)
+			wantSlice, err := readSliceSequential(filename, []uint64{85, 5}, []uint64{15, 10})
+			require.NoError(t, err)
+			require.Equal(t, wantSlice, slice)
+		})
+	}
+}
+
+// readSliceSequential reads a slice of /grid without read concurrency.
+func readSliceSequential(filename string, start, count []uint64) (interface{}, error) {
+	f, err := Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	ds, err := f.OpenDataset("/grid")
+	if err != nil {
+		return nil, err
+	}
+	return ds.ReadSlice(start, count)
+}
+
+func TestFile_ConcurrentReads(t *testing.T) {
+	filename, want := createConcurrencyTestFile(t)
+
+	f, err := Open(filename, WithReadConcurrency(2))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 16)
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- func() error {
+				ds, err := f.OpenDataset("/grid")
+				if err != nil {
+					return err
+				}
+				got, err := ds.Read()
+				if err != nil {
+					return err
+				}
+				if len(got) != len(want) || got[len(got)-1] != want[len(want)-1] {
+					return fmt.Errorf("unexpected data")
+				}
+				if _, err := ds.ReadAttribute("units"); err != nil {
+					return err
+				}
+				f.Walk(func(string, Object) {})
+				_, err = f.Get("/grid")
+				return err
+			}()
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
@@ -1,9 +1,7 @@
 package hdf5
 
 import (
-	"encoding/binary"
 	"fmt"
-	"math"
 	"strings"
 
 	"github.com/meko-christian/go-hdf5/internal/core"
@@ -62,27 +60,15 @@ type HyperslabSelection struct {
 //   - error: Error if selection is invalid or reading fails
 func (d *Dataset) ReadSlice(start, count []uint64) (interface{}, error) {
 	// Read object header to get dataset metadata
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object header: %w", err)
 	}
 
 	// Extract dataspace to validate dimensions
-	var dataspaceMsg *core.HeaderMessage
-	for _, msg := range header.Messages {
-		if msg.Type == core.MsgDataspace {
-			dataspaceMsg = msg
-			break
-		}
-	}
-
-	if dataspaceMsg == nil {
-		return nil, fmt.Errorf("dataspace message not found in dataset")
-	}
-
-	dataspace, err := core.ParseDataspaceMessage(dataspaceMsg.Data)
+	dataspace, err := d.selectionDataspace(header)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse dataspace: %w", err)
+		return nil, err
 	}
 
 	// Validate dimensions match
@@ -140,17 +126,35 @@ func (d *Dataset) ReadSlice(start, count []uint64) (interface{}, error) {
 //   - error: Error if selection is invalid or reading fails
 func (d *Dataset) ReadHyperslab(selection *HyperslabSelection) (interface{}, error) {
 	// Read object header to get dataset metadata
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object header: %w", err)
 	}
 
 	// Extract dataspace to validate dimensions
-	var dataspaceMsg *core.HeaderMessage
+	dataspace, err := d.selectionDataspace(header)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate selection
+	if err := validateHyperslabSelection(selection, dataspace.Dimensions); err != nil {
+		return nil, fmt.Errorf("invalid selection: %w", err)
+	}
+
+	return d.readHyperslab(selection, header)
+}
+
+// selectionDataspace returns the dataspace selections are validated against.
+// For virtual datasets this is the extent determined by the source datasets.
+func (d *Dataset) selectionDataspace(header *core.ObjectHeader) (*core.DataspaceMessage, error) {
+	var dataspaceMsg, layoutMsg *core.HeaderMessage
 	for _, msg := range header.Messages {
-		if msg.Type == core.MsgDataspace {
+		switch msg.Type {
+		case core.MsgDataspace:
 			dataspaceMsg = msg
-			break
+		case core.MsgDataLayout:
+			layoutMsg = msg
 		}
 	}
 
@@ -158,17 +162,22 @@ func (d *Dataset) ReadHyperslab(selection *HyperslabSelection) (interface{}, err
 		return nil, fmt.Errorf("dataspace message not found in dataset")
 	}
 
+	if layoutMsg != nil {
+		layout, err := core.ParseDataLayoutMessage(layoutMsg.Data, d.file.sb)
+		if err == nil && layout.IsVirtual() {
+			info, err := core.ReadVirtualDatasetInfo(d.file.reader(), header, d.file.sb)
+			if err != nil {
+				return nil, err
+			}
+			return info.Dataspace, nil
+		}
+	}
+
 	dataspace, err := core.ParseDataspaceMessage(dataspaceMsg.Data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dataspace: %w", err)
 	}
-
-	// Validate selection
-	if err := validateHyperslabSelection(selection, dataspace.Dimensions); err != nil {
-		return nil, fmt.Errorf("invalid selection: %w", err)
-	}
-
-	return d.readHyperslab(selection, header)
+	return dataspace, nil
 }
 
 // validateHyperslabSelection validates a hyperslab selection against dataset dimensions.
@@ -286,6 +295,11 @@ func (d *Dataset) readHyperslab(selection *HyperslabSelection, header *core.Obje
 		return nil, err
 	}
 
+	// Virtual datasets are assembled from their source datasets
+	if parsedMsgs.layout.IsVirtual() {
+		return d.readHyperslabVirtual(selection, header)
+	}
+
 	// Dispatch to appropriate layout reader
 	return d.dispatchHyperslabReader(selection, parsedMsgs)
 }
@@ -296,6 +310,9 @@ type hyperslabMessages struct {
 	dataspace      *core.HeaderMessage
 	layout         *core.HeaderMessage
 	filterPipeline *core.HeaderMessage
+	fillValue      *core.HeaderMessage
+	fillValueOld   *core.HeaderMessage
+	external       bool // Data is stored in external files
 }
 
 // parsedHyperslabMessages holds parsed message structures.
@@ -304,6 +321,8 @@ type parsedHyperslabMessages struct {
 	dataspace      *core.DataspaceMessage
 	layout         *core.DataLayoutMessage
 	filterPipeline *core.FilterPipelineMessage
+	fillValue      *core.FillValueMessage
+	external       bool
 }
 
 // extractHyperslabMessages extracts required messages from object header.
@@ -320,6 +339,12 @@ func extractHyperslabMessages(header *core.ObjectHeader) (*hyperslabMessages, er
 			msgs.layout = msg
 		case core.MsgFilterPipeline:
 			msgs.filterPipeline = msg
+		case core.MsgFillValue:
+			msgs.fillValue = msg
+		case core.MsgFillValueOld:
+			msgs.fillValueOld = msg
+		case core.MsgExternalFiles:
+			msgs.external = true
 		}
 	}
 
@@ -339,7 +364,7 @@ func extractHyperslabMessages(header *core.ObjectHeader) (*hyperslabMessages, er
 
 // parseHyperslabMessages parses raw messages into structured types.
 func parseHyperslabMessages(msgs *hyperslabMessages, sb *core.Superblock) (*parsedHyperslabMessages, error) {
-	parsed := &parsedHyperslabMessages{}
+	parsed := &parsedHyperslabMessages{external: msgs.external}
 
 	var err error
 
@@ -366,6 +391,17 @@ func parseHyperslabMessages(msgs *hyperslabMessages, sb *core.Superblock) (*pars
 		}
 	}
 
+	// Parse fill value (optional, the new-style message takes precedence)
+	switch {
+	case msgs.fillValue != nil:
+		parsed.fillValue, err = core.ParseFillValueMessage(msgs.fillValue.Data)
+	case msgs.fillValueOld != nil:
+		parsed.fillValue, err = core.ParseFillValueOldMessage(msgs.fillValueOld.Data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fill value: %w", err)
+	}
+
 	return parsed, nil
 }
 
@@ -377,10 +413,14 @@ func (d *Dataset) dispatchHyperslabReader(
 	switch {
 	case msgs.layout.IsCompact():
 		return d.readHyperslabCompact(selection, msgs.datatype, msgs.dataspace, msgs.layout)
+	case msgs.layout.IsContiguous() && msgs.external:
+		return nil, fmt.Errorf("external data storage is not supported")
+	case msgs.layout.IsContiguous() && !msgs.layout.IsAllocated(d.file.sb):
+		return readHyperslabUnallocated(selection, msgs.datatype, msgs.dataspace, msgs.fillValue)
 	case msgs.layout.IsContiguous():
 		return d.readHyperslabContiguous(selection, msgs.datatype, msgs.dataspace, msgs.layout)
 	case msgs.layout.IsChunked():
-		return d.readHyperslabChunked(selection, msgs.datatype, msgs.dataspace, msgs.layout, msgs.filterPipeline)
+		return d.readHyperslabChunked(selection, msgs.datatype, msgs.dataspace, msgs.layout, msgs.filterPipeline, msgs.fillValue)
 	default:
 		return nil, fmt.Errorf("unsupported layout class: %d", msgs.layout.Class)
 	}
@@ -418,6 +458,23 @@ func (d *Dataset) readHyperslabCompact(
 	return extractHyperslabFromRawData(selection, datatype, dataspace, layout.CompactData)
 }
 
+// readHyperslabUnallocated reads hyperslab from a dataset whose storage was
+// never allocated. Every selected element is the fill value.
+func readHyperslabUnallocated(
+	selection *HyperslabSelection,
+	datatype *core.DatatypeMessage,
+	dataspace *core.DataspaceMessage,
+	fillValue *core.FillValueMessage,
+) (interface{}, error) {
+	elementSize := uint64(datatype.Size)
+	outputElements := calculateHyperslabOutputSize(selection)
+
+	outputData := make([]byte, outputElements*elementSize)
+	core.FillBuffer(outputData, fillValue.Pattern(elementSize))
+
+	return convertToFloat64(outputData, datatype, outputElements)
+}
+
 // readHyperslabContiguous reads hyperslab from contiguous layout dataset.
 // Contiguous layout stores data in one continuous block in the file.
 //
@@ -486,7 +543,7 @@ func (d *Dataset) readContiguousOptimized(
 		fileOffset := layout.DataAddress + startOffset
 
 		//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-		_, err := d.file.osFile.ReadAt(rawData, int64(fileOffset))
+		_, err := d.file.r.ReadAt(rawData, int64(fileOffset))
 		if err != nil {
 			return nil, fmt.Errorf("failed to read 1D contiguous data: %w", err)
 		}
@@ -506,7 +563,7 @@ func (d *Dataset) readContiguousOptimized(
 	fileOffset := layout.DataAddress + startByteOffset
 
 	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-	_, err := d.file.osFile.ReadAt(outputData, int64(fileOffset))
+	_, err := d.file.r.ReadAt(outputData, int64(fileOffset))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read contiguous data: %w", err)
 	}
@@ -562,7 +619,7 @@ func (d *Dataset) readContiguousRowByRow(
 	fileOffset := layout.DataAddress + startOffset
 
 	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-	_, err := d.file.osFile.ReadAt(rawData, int64(fileOffset))
+	_, err := d.file.r.ReadAt(rawData, int64(fileOffset))
 	if err != nil {
 		return nil, fmt.Errorf("failed to read bounding box: %w", err)
 	}
@@ -621,7 +678,7 @@ func (d *Dataset) readContiguous2DOptimized(
 
 					// Read single element
 					//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-					_, err := d.file.osFile.ReadAt(
+					_, err := d.file.r.ReadAt(
 						outputData[outputIdx*elementSize:(outputIdx+1)*elementSize],
 						int64(byteOffset),
 					)
@@ -639,7 +696,7 @@ func (d *Dataset) readContiguous2DOptimized(
 }
 
 // readHyperslabChunked reads hyperslab from chunked layout dataset.
-// Chunked layout stores data in separate chunks indexed by a B-tree.
+// Chunked layout stores data in separate chunks indexed by a B-tree or, in newer files, another chunk index.
 //
 // OPTIMIZED: Reads ONLY the chunks that overlap with the selection.
 // For a small selection in a large dataset, this dramatically reduces I/O.
@@ -649,6 +706,7 @@ func (d *Dataset) readHyperslabChunked(
 	dataspace *core.DataspaceMessage,
 	layout *core.DataLayoutMessage,
 	filterPipeline *core.FilterPipelineMessage,
+	fillValue *core.FillValueMessage,
 ) (interface{}, error) {
 	elementSize := uint64(datatype.Size)
 	dims := dataspace.Dimensions
@@ -668,59 +726,56 @@ func (d *Dataset) readHyperslabChunked(
 		return []float64{}, nil
 	}
 
-	// Parse B-tree to get chunk addresses
-	btreeNode, err := core.ParseBTreeV1Node(
-		d.file.osFile,
-		layout.DataAddress,
-		d.file.sb.OffsetSize,
-		len(chunkDims),
-		chunkDims,
-	)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse chunk B-tree: %w", err)
-	}
-
 	// Build chunk index (scaled coordinates -> file address)
-	chunkIndex := make(map[string]chunkIndexEntry)
-	allChunks, err := btreeNode.CollectAllChunks(d.file.osFile, d.file.sb.OffsetSize, chunkDims)
+	chunkIndex := make(map[string]core.ChunkEntry)
+	allChunks, err := core.CollectChunks(d.file.r, layout, dataspace, d.file.sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get chunk index: %w", err)
 	}
 
 	for _, chunk := range allChunks {
 		key := chunkCoordsToKey(chunk.Key.Scaled[:len(dims)])
-		chunkIndex[key] = chunkIndexEntry{
-			address: chunk.Address,
-			nbytes:  uint64(chunk.Key.Nbytes),
-		}
+		chunkIndex[key] = chunk
 	}
 
 	// Allocate output buffer
 	outputData := make([]byte, outputElements*elementSize)
 	outputIdx := uint64(0)
 
-	// Read each overlapping chunk and extract relevant data
-	for _, chunkCoord := range overlappingChunks {
-		err := d.extractFromChunk(
-			chunkCoord, chunkIndex, chunkDims, dims,
-			selection, datatype, filterPipeline,
-			outputData, &outputIdx,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to extract from chunk %v: %w", chunkCoord, err)
+	// Look up the stored overlapping chunks, which are read and decoded
+	// (possibly concurrently, see WithReadConcurrency) in order.
+	var stored []core.ChunkEntry
+	var storedAt []int // Index of each stored chunk in overlappingChunks
+	for i, chunkCoord := range overlappingChunks {
+		if chunk, ok := chunkIndex[chunkCoordsToKey(chunkCoord)]; ok {
+			stored = append(stored, chunk)
+			storedAt = append(storedAt, i)
 		}
 	}
 
+	// Extract relevant data from each overlapping chunk in order; chunks
+	// that were never written are filled.
+	next := 0
+	fillUpTo := func(end int) {
+		for ; next < end; next++ {
+			extractFromChunk(overlappingChunks[next], nil, chunkDims, dims, selection, datatype, fillValue, outputData, &outputIdx)
+		}
+	}
+	err = core.DecodeChunks(d.file.r, stored, filterPipeline, d.file.config.readConcurrency, func(i int, chunkData []byte) error {
+		fillUpTo(storedAt[i])
+		extractFromChunk(overlappingChunks[next], chunkData, chunkDims, dims, selection, datatype, fillValue, outputData, &outputIdx)
+		next++
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to extract from chunk: %w", err)
+	}
+	fillUpTo(len(overlappingChunks))
+
 	// Convert bytes to float64
 	return convertToFloat64(outputData, datatype, outputElements)
 }
 
-// chunkIndexEntry stores chunk location information.
-type chunkIndexEntry struct {
-	address uint64
-	nbytes  uint64
-}
-
 // findOverlappingChunks identifies all chunks that overlap with the hyperslab selection.
 // Returns chunk coordinates (scaled chunk indices, not element indices).
 func findOverlappingChunks(sel *HyperslabSelection, chunkDims, datasetDims []uint64) [][]uint64 {
@@ -803,44 +858,29 @@ func chunkCoordsToKey(coords []uint64) string {
 	return strings.Join(parts, ",")
 }
 
-// extractFromChunk reads a single chunk, decompresses if needed, and extracts relevant portion.
-func (d *Dataset) extractFromChunk(
+// extractFromChunk extracts the portion of a decoded chunk that intersects
+// with the selection. A nil chunkData stands for a chunk that was never
+// written, whose elements read as the fill value.
+func extractFromChunk(
 	chunkCoord []uint64,
-	chunkIndex map[string]chunkIndexEntry,
+	chunkData []byte,
 	chunkDims []uint64,
 	datasetDims []uint64,
 	selection *HyperslabSelection,
 	datatype *core.DatatypeMessage,
-	filterPipeline *core.FilterPipelineMessage,
+	fillValue *core.FillValueMessage,
 	outputData []byte,
 	outputIdx *uint64,
-) error {
-	// Look up chunk address
-	key := chunkCoordsToKey(chunkCoord)
-	chunkInfo, exists := chunkIndex[key]
-	if !exists {
-		// Chunk doesn't exist (sparse dataset) - skip
-		// In HDF5, missing chunks are filled with fill values (typically 0)
-		// For now, we leave output buffer as-is (initialized to 0)
-		return nil
-	}
-
+) {
 	elementSize := uint64(datatype.Size)
 
-	// Read chunk data (use nbytes from index)
-	chunkData := make([]byte, chunkInfo.nbytes)
-	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-	_, err := d.file.osFile.ReadAt(chunkData, int64(chunkInfo.address))
-	if err != nil {
-		return fmt.Errorf("failed to read chunk data: %w", err)
-	}
-
-	// Decompress if needed (using existing FilterPipelineMessage.ApplyFilters)
-	if filterPipeline != nil {
-		chunkData, err = filterPipeline.ApplyFilters(chunkData)
-		if err != nil {
-			return fmt.Errorf("failed to apply filters: %w", err)
+	if chunkData == nil {
+		chunkElements := uint64(1)
+		for _, dim := range chunkDims[:len(chunkCoord)] {
+			chunkElements *= dim
 		}
+		chunkData = make([]byte, chunkElements*elementSize)
+		core.FillBuffer(chunkData, fillValue.Pattern(elementSize))
 	}
 
 	// Extract portion of this chunk that intersects with selection
@@ -849,8 +889,6 @@ func (d *Dataset) extractFromChunk(
 		selection, elementSize,
 		outputData, outputIdx,
 	)
-
-	return nil
 }
 
 // extractChunkPortion extracts the portion of a chunk that intersects with the selection.
@@ -1080,80 +1118,17 @@ func calculateLinearOffset(coords, dims []uint64) uint64 {
 	return offset
 }
 
-// convertToFloat64 is a wrapper around core's private convertToFloat64 function.
-// This converts raw bytes to float64 array based on datatype.
-// For MVP, we only support float64 output (matching existing Read() method).
+// convertToFloat64 decodes raw element bytes into a float64 array.
+// Any numeric datatype supported by core.DecodeNumeric is accepted
+// (all integer widths, floats, fp8/bfloat16, enums).
 func convertToFloat64(rawData []byte, datatype *core.DatatypeMessage, numElements uint64) ([]float64, error) {
-	byteOrder := datatype.GetByteOrder()
-
-	switch {
-	case datatype.IsFloat64():
-		return convertBytesToFloat64Direct(rawData, byteOrder, numElements)
-	case datatype.IsFloat32():
-		return convertBytesToFloat32AsFloat64(rawData, byteOrder, numElements)
-	case datatype.IsInt32():
-		return convertBytesToInt32AsFloat64(rawData, byteOrder, numElements)
-	case datatype.IsInt64():
-		return convertBytesToInt64AsFloat64(rawData, byteOrder, numElements)
-	default:
+	if !datatype.IsNumeric() {
 		return nil, fmt.Errorf("unsupported datatype for conversion to float64")
 	}
-}
-
-// convertBytesToFloat64Direct converts IEEE 754 double precision bytes to float64.
-func convertBytesToFloat64Direct(rawData []byte, byteOrder binary.ByteOrder, numElements uint64) ([]float64, error) {
-	result := make([]float64, numElements)
-	for i := uint64(0); i < numElements; i++ {
-		offset := i * 8
-		if offset+8 > uint64(len(rawData)) {
-			return nil, fmt.Errorf("data truncated (float64)")
-		}
-		bits := byteOrder.Uint64(rawData[offset : offset+8])
-		result[i] = math.Float64frombits(bits)
-	}
-	return result, nil
-}
 
-// convertBytesToFloat32AsFloat64 converts IEEE 754 single precision bytes to float64.
-func convertBytesToFloat32AsFloat64(rawData []byte, byteOrder binary.ByteOrder, numElements uint64) ([]float64, error) {
 	result := make([]float64, numElements)
-	for i := uint64(0); i < numElements; i++ {
-		offset := i * 4
-		if offset+4 > uint64(len(rawData)) {
-			return nil, fmt.Errorf("data truncated (float32)")
-		}
-		bits := byteOrder.Uint32(rawData[offset : offset+4])
-		result[i] = float64(math.Float32frombits(bits))
-	}
-	return result, nil
-}
-
-// convertBytesToInt32AsFloat64 converts 32-bit signed integer bytes to float64.
-func convertBytesToInt32AsFloat64(rawData []byte, byteOrder binary.ByteOrder, numElements uint64) ([]float64, error) {
-	result := make([]float64, numElements)
-	for i := uint64(0); i < numElements; i++ {
-		offset := i * 4
-		if offset+4 > uint64(len(rawData)) {
-			return nil, fmt.Errorf("data truncated (int32)")
-		}
-		//nolint:gosec // G115: HDF5 binary format requires uint32 to int32 conversion
-		val := int32(byteOrder.Uint32(rawData[offset : offset+4]))
-		result[i] = float64(val)
-	}
-	return result, nil
-}
-
-// convertBytesToInt64AsFloat64 converts 64-bit signed integer bytes to float64.
-func convertBytesToInt64AsFloat64(rawData []byte, byteOrder binary.ByteOrder, numElements uint64) ([]float64, error) {
-	result := make([]float64, numElements)
-	for i := uint64(0); i < numElements; i++ {
-		offset := i * 8
-		if offset+8 > uint64(len(rawData)) {
-			return nil, fmt.Errorf("data truncated (int64)")
-		}
-		//nolint:gosec // G115: HDF5 binary format requires uint64 to int64 conversion
-		val := int64(byteOrder.Uint64(rawData[offset : offset+8]))
-		result[i] = float64(val)
+	if err := core.DecodeNumeric(rawData, datatype, result); err != nil {
+		return nil, err
 	}
 	return result, nil
 }
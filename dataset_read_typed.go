@@ -0,0 +1,142 @@
+package hdf5
+
+import (
+	"fmt"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// Numeric is the set of Go element types that numeric datasets can be read into
+// with ReadInto and Dataset.ReadAs.
+type Numeric = core.Numeric
+
+// ReadInto reads all elements of a numeric dataset directly into dst.
+//
+// Values are decoded from their stored representation (any integer width,
+// either byte order, float32/float64, float16, bfloat16, fp8 E4M3/E5M2 and
+// integer-based enums) straight into T without an intermediate float64,
+// so int64 and uint64 values keep their full precision.
+//
+// len(dst) must equal the number of elements in the dataset. For
+// multi-dimensional datasets, elements are stored in row-major order.
+//
+// Example:
+//
+//	counts := make([]uint64, 1000)
+//	if err := hdf5.ReadInto(ds, counts); err != nil {
+//	    return err
+//	}
+func ReadInto[T Numeric](d *Dataset, dst []T) error {
+	rawData, info, err := d.readRaw()
+	if err != nil {
+		return err
+	}
+
+	if !info.Datatype.IsNumeric() {
+		return fmt.Errorf("dataset %q is not numeric: %s", d.name, info.Datatype)
+	}
+
+	total := info.Dataspace.TotalElements()
+	if uint64(len(dst)) != total {
+		return fmt.Errorf("destination length %d does not match dataset element count %d", len(dst), total)
+	}
+
+	return core.DecodeNumeric(rawData, info.Datatype, dst)
+}
+
+// ReadAs reads all elements of a numeric dataset into dst.
+//
+// dst must be either a slice of a numeric type whose length equals the
+// number of dataset elements, or a pointer to such a slice, in which case
+// the slice is (re)allocated to the required length.
+//
+// Supported element types: int8, int16, int32, int64, uint8, uint16,
+// uint32, uint64, float32, float64.
+//
+// Example:
+//
+//	var values []int64
+//	if err := ds.ReadAs(&values); err != nil {
+//	    return err
+//	}
+func (d *Dataset) ReadAs(dst any) error {
+	switch v := dst.(type) {
+	case []int8:
+		return ReadInto(d, v)
+	case []int16:
+		return ReadInto(d, v)
+	case []int32:
+		return ReadInto(d, v)
+	case []int64:
+		return ReadInto(d, v)
+	case []uint8:
+		return ReadInto(d, v)
+	case []uint16:
+		return ReadInto(d, v)
+	case []uint32:
+		return ReadInto(d, v)
+	case []uint64:
+		return ReadInto(d, v)
+	case []float32:
+		return ReadInto(d, v)
+	case []float64:
+		return ReadInto(d, v)
+	case *[]int8:
+		return readAlloc(d, v)
+	case *[]int16:
+		return readAlloc(d, v)
+	case *[]int32:
+		return readAlloc(d, v)
+	case *[]int64:
+		return readAlloc(d, v)
+	case *[]uint8:
+		return readAlloc(d, v)
+	case *[]uint16:
+		return readAlloc(d, v)
+	case *[]uint32:
+		return readAlloc(d, v)
+	case *[]uint64:
+		return readAlloc(d, v)
+	case *[]float32:
+		return readAlloc(d, v)
+	case *[]float64:
+		return readAlloc(d, v)
+	default:
+		return fmt.Errorf("unsupported destination type %T (want a numeric slice or pointer to one)", dst)
+	}
+}
+
+// readAlloc sizes *dst to the dataset's element count and decodes into it.
+func readAlloc[T Numeric](d *Dataset, dst *[]T) error {
+	if dst == nil {
+		return fmt.Errorf("destination pointer is nil")
+	}
+
+	rawData, info, err := d.readRaw()
+	if err != nil {
+		return err
+	}
+
+	if !info.Datatype.IsNumeric() {
+		return fmt.Errorf("dataset %q is not numeric: %s", d.name, info.Datatype)
+	}
+
+	total := info.Dataspace.TotalElements()
+	if uint64(cap(*dst)) >= total {
+		*dst = (*dst)[:total]
+	} else {
+		*dst = make([]T, total)
+	}
+
+	return core.DecodeNumeric(rawData, info.Datatype, *dst)
+}
+
+// readRaw reads the dataset's object header and complete raw storage.
+func (d *Dataset) readRaw() ([]byte, *core.DatasetInfo, error) {
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return core.ReadDatasetRaw(d.file.reader(), header, d.file.sb)
+}
@@ -0,0 +1,207 @@
+package hdf5
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestReadInto_IntegerWidths verifies that every integer width round-trips
+// through ReadInto without passing through float64.
+func TestReadInto_IntegerWidths(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "typed_ints.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	writes := []struct {
+		name  string
+		dtype Datatype
+		data  interface{}
+	}{
+		{"/i8", Int8, []int8{math.MinInt8, -1, 0, math.MaxInt8}},
+		{"/i16", Int16, []int16{math.MinInt16, -1, 0, math.MaxInt16}},
+		{"/i32", Int32, []int32{math.MinInt32, -1, 0, math.MaxInt32}},
+		{"/i64", Int64, []int64{math.MinInt64, -1, 1<<53 + 1, math.MaxInt64}},
+		{"/u8", Uint8, []uint8{0, 1, 128, math.MaxUint8}},
+		{"/u16", Uint16, []uint16{0, 1, 1 << 15, math.MaxUint16}},
+		{"/u32", Uint32, []uint32{0, 1, 1 << 31, math.MaxUint32}},
+		{"/u64", Uint64, []uint64{0, 1, 1<<63 + 1, math.MaxUint64}},
+	}
+	for _, w := range writes {
+		ds, err := fw.CreateDataset(w.name, w.dtype, []uint64{4})
+		require.NoError(t, err)
+		require.NoError(t, ds.Write(w.data))
+	}
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	t.Run("int8", func(t *testing.T) {
+		got := make([]int8, 4)
+		require.NoError(t, ReadInto(mustOpenDataset(t, f, "/i8"), got))
+		require.Equal(t, []int8{math.MinInt8, -1, 0, math.MaxInt8}, got)
+	})
+	t.Run("int16", func(t *testing.T) {
+		got := make([]int16, 4)
+		require.NoError(t, ReadInto(mustOpenDataset(t, f, "/i16"), got))
+		require.Equal(t, []int16{math.MinInt16, -1, 0, math.MaxInt16}, got)
+	})
+	t.Run("int32", func(t *testing.T) {
+		got := make([]int32, 4)
+		require.NoError(t, ReadInto(mustOpenDataset(t, f, "/i32"), got))
+		require.Equal(t, []int32{math.MinInt32, -1, 0, math.MaxInt32}, got)
+	})
+	t.Run("int64 keeps precision", func(t *testing.T) {
+		got := make([]int64, 4)
+		require.NoError(t, ReadInto(mustOpenDataset(t, f, "/i64"), got))
+		require.Equal(t, []int64{math.MinInt64, -1, 1<<53 + 1, math.MaxInt64}, got)
+	})
+	t.Run("uint8", func(t *testing.T) {
+		var got []uint8
+		require.NoError(t, mustOpenDataset(t, f, "/u8").ReadAs(&got))
+		require.Equal(t, []uint8{0, 1, 128, math.MaxUint8}, got)
+	})
+	t.Run("uint16", func(t *testing.T) {
+		var got []uint16
+		require.NoError(t, mustOpenDataset(t, f, "/u16").ReadAs(&got))
+		require.Equal(t, []uint16{0, 1, 1 << 15, math.MaxUint16}, got)
+	})
+	t.Run("uint32", func(t *testing.T) {
+		got := make([]uint32, 4)
+		require.NoError(t, mustOpenDataset(t, f, "/u32").ReadAs(got))
+		require.Equal(t, []uint32{0, 1, 1 << 31, math.MaxUint32}, got)
+	})
+	t.Run("uint64 keeps precision", func(t *testing.T) {
+		var got []uint64
+		require.NoError(t, mustOpenDataset(t, f, "/u64").ReadAs(&got))
+		require.Equal(t, []uint64{0, 1, 1<<63 + 1, math.MaxUint64}, got)
+	})
+	t.Run("widening to float64", func(t *testing.T) {
+		got, err := mustOpenDataset(t, f, "/i16").Read()
+		require.NoError(t, err)
+		require.Equal(t, []float64{math.MinInt16, -1, 0, math.MaxInt16}, got)
+	})
+}
+
+func TestReadInto_Floats(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "typed_floats.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	f32 := []float32{1.5, -2.25, float32(math.Inf(1)), 3.4e38}
+	ds, err := fw.CreateDataset("/f32", Float32, []uint64{4})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(f32))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	got := make([]float32, 4)
+	require.NoError(t, ReadInto(mustOpenDataset(t, f, "/f32"), got))
+	require.Equal(t, f32, got)
+}
+
+func TestReadInto_Chunked(t *testing.T) {
+	t.Run("written chunked", func(t *testing.T) {
+		filename := filepath.Join(t.TempDir(), "typed_chunked.h5")
+
+		fw, err := CreateForWrite(filename, CreateTruncate)
+		require.NoError(t, err)
+
+		chunked := make([]int64, 100)
+		for i := range chunked {
+			chunked[i] = int64(i) * (1 << 40)
+		}
+		ds, err := fw.CreateDataset("/chunked", Int64, []uint64{100},
+			WithChunkDims([]uint64{30}))
+		require.NoError(t, err)
+		require.NoError(t, ds.Write(chunked))
+		require.NoError(t, fw.Close())
+
+		f, err := Open(filename)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		got := make([]int64, len(chunked))
+		require.NoError(t, ReadInto(mustOpenDataset(t, f, "/chunked"), got))
+		require.Equal(t, chunked, got)
+
+		var gotAs []int64
+		require.NoError(t, mustOpenDataset(t, f, "/chunked").ReadAs(&gotAs))
+		require.Equal(t, chunked, gotAs)
+	})
+
+	t.Run("3d chunks with partial edges", func(t *testing.T) {
+		f, err := Open("testdata/test_3d_chunked.h5")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		var got []int64
+		require.NoError(t, mustOpenDataset(t, f, "/data3d").ReadAs(&got))
+		require.Len(t, got, 8*6*4)
+		for i, v := range got {
+			require.Equal(t, int64(i), v)
+		}
+	})
+
+	t.Run("gzip compressed", func(t *testing.T) {
+		f, err := Open("testdata/gzip_test.h5")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		ds := mustOpenDataset(t, f, "/shuffled_compressed")
+		want, err := ds.Read()
+		require.NoError(t, err)
+
+		got := make([]float32, len(want))
+		require.NoError(t, ReadInto(ds, got))
+		for i := range want {
+			require.Equal(t, float32(want[i]), got[i])
+		}
+	})
+}
+
+func TestReadInto_Errors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "typed_errors.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/nums", Int32, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{1, 2, 3}))
+	ds, err = fw.CreateDataset("/strs", String, []uint64{2}, WithStringSize(4))
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]string{"ab", "cd"}))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	nums := mustOpenDataset(t, f, "/nums")
+
+	err = ReadInto(nums, make([]int32, 2))
+	require.ErrorContains(t, err, "does not match dataset element count")
+
+	err = nums.ReadAs([]string{})
+	require.ErrorContains(t, err, "unsupported destination type")
+
+	err = ReadInto(mustOpenDataset(t, f, "/strs"), make([]int32, 2))
+	require.ErrorContains(t, err, "not numeric")
+}
+
+// mustOpenDataset returns the dataset at path.
+func mustOpenDataset(t *testing.T, f *File, path string) *Dataset {
+	t.Helper()
+	ds, err := f.OpenDataset(path)
+	require.NoError(t, err)
+	return ds
+}
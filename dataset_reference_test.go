@@ -0,0 +1,501 @@
+package hdf5
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadReferences_ObjectRefs(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "tobjref.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/Dataset3")
+	require.NoError(t, err)
+	refs, err := ds.ReadReferences()
+	require.NoError(t, err)
+	require.Len(t, refs, 4)
+
+	want := []struct {
+		name string
+		obj  Object
+	}{
+		{"Dataset1", &Dataset{}},
+		{"Dataset2", &Dataset{}},
+		{"Group1", &Group{}},
+		{"Datatype1", &NamedDatatype{}},
+	}
+	for i, ref := range refs {
+		require.IsType(t, ObjectRef(0), ref)
+		obj, err := f.Dereference(ref)
+		require.NoError(t, err)
+		require.IsType(t, want[i].obj, obj)
+		require.Equal(t, want[i].name, obj.Name())
+	}
+
+	// Numeric datasets are not references.
+	ds, err = f.OpenDataset("/Group1/Dataset1")
+	require.NoError(t, err)
+	_, err = ds.ReadReferences()
+	require.ErrorContains(t, err, "is not a reference dataset")
+}
+
+func TestReadReferences_Attributes(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "h5repack_refs.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/Dset_REGREF")
+	require.NoError(t, err)
+
+	value, err := ds.ReadAttribute("Attr_OBJREF")
+	require.NoError(t, err)
+	objRefs, ok := value.([]ObjectRef)
+	require.True(t, ok, "got %T", value)
+	var names []string
+	for _, ref := range objRefs {
+		obj, err := f.Dereference(ref)
+		require.NoError(t, err)
+		names = append(names, obj.Name())
+	}
+	require.Equal(t, []string{"Dset1", "Group", "NamedDatatype"}, names)
+
+	// A scalar region reference attribute and a region reference dataset
+	// both point to Dset2.
+	value, err = ds.ReadAttribute("Attr_REGREF")
+	require.NoError(t, err)
+	regionRef, ok := value.(RegionRef)
+	require.True(t, ok, "got %T", value)
+	obj, err := f.Dereference(regionRef)
+	require.NoError(t, err)
+	require.Equal(t, "Dset2", obj.Name())
+
+	refs, err := ds.ReadReferences()
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	for _, ref := range refs {
+		require.IsType(t, RegionRef{}, ref)
+		obj, err := f.Dereference(ref)
+		require.NoError(t, err)
+		require.IsType(t, &Dataset{}, obj)
+		require.Equal(t, "Dset2", obj.Name())
+	}
+}
+
+func TestDereference_Written(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "refs.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	gw, err := fw.CreateGroup("/group")
+	require.NoError(t, err)
+	target, err := fw.CreateDataset("/group/values", Int32, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, target.Write([]int32{1, 2, 3}))
+
+	refs, err := fw.CreateDataset("/refs", ObjectReference, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, refs.Write([]uint64{target.address, gw.headerAddr, 0}))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/refs")
+	require.NoError(t, err)
+	got, err := ds.ReadReferences()
+	require.NoError(t, err)
+	require.Equal(t, []Reference{ObjectRef(target.address), ObjectRef(gw.headerAddr), ObjectRef(0)}, got)
+
+	obj, err := f.Dereference(got[0])
+	require.NoError(t, err)
+	values, ok := obj.(*Dataset)
+	require.True(t, ok, "got %T", obj)
+	data, err := values.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3}, data)
+
+	obj, err = f.Dereference(got[1])
+	require.NoError(t, err)
+	require.IsType(t, &Group{}, obj)
+	require.Equal(t, "group", obj.Name())
+
+	require.True(t, got[2].IsNull())
+	_, err = f.Dereference(got[2])
+	require.ErrorContains(t, err, "null reference")
+}
+
+func TestReadRegion_Attribute(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "tattrreg.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/Dataset1")
+	require.NoError(t, err)
+	value, err := ds.ReadAttribute("Attribute1")
+	require.NoError(t, err)
+	refs, ok := value.([]RegionRef)
+	require.True(t, ok, "got %T", value)
+	require.Len(t, refs, 4)
+	require.True(t, refs[2].IsNull())
+
+	// Dataset2 holds 3*i mod 256 at element i of a 10x10 array.
+	element := func(r, c uint64) float64 { return float64((3 * (10*r + c)) % 256) }
+
+	// Block (2,2)-(7,7).
+	target, sel, err := f.Region(refs[0])
+	require.NoError(t, err)
+	require.Equal(t, "Dataset2", target.Name())
+	require.Equal(t, []RegionBlock{{Start: []uint64{2, 2}, End: []uint64{7, 7}}}, sel.Blocks)
+
+	data, err := f.ReadRegion(refs[0])
+	require.NoError(t, err)
+	var want []float64
+	for r := uint64(2); r <= 7; r++ {
+		for c := uint64(2); c <= 7; c++ {
+			want = append(want, element(r, c))
+		}
+	}
+	require.Equal(t, want, data)
+
+	// Points, in selection order.
+	_, sel, err = f.Region(refs[1])
+	require.NoError(t, err)
+	points := [][]uint64{{6, 9}, {2, 2}, {8, 4}, {1, 6}, {2, 8}, {3, 2}, {0, 4}, {9, 0}, {7, 1}, {3, 3}}
+	require.Equal(t, points, sel.Points)
+
+	data, err = f.ReadRegion(refs[1])
+	require.NoError(t, err)
+	want = want[:0]
+	for _, p := range points {
+		want = append(want, element(p[0], p[1]))
+	}
+	require.Equal(t, want, data)
+
+	_, err = f.ReadRegion(refs[2])
+	require.ErrorContains(t, err, "null region reference")
+}
+
+func TestRegionRef_Written(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "regions.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	values := make([]int32, 24)
+	for i := range values {
+		values[i] = int32(i)
+	}
+	ds, err := fw.CreateDataset("/values", Int32, []uint64{4, 6})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(values))
+
+	// Rows 0 and 2, columns 1-2 and 4-5.
+	hyperslab := &HyperslabSelection{
+		Start:  []uint64{0, 1},
+		Count:  []uint64{2, 2},
+		Stride: []uint64{2, 3},
+		Block:  []uint64{1, 2},
+	}
+	slabRef, err := fw.NewRegionRef("/values", hyperslab)
+	require.NoError(t, err)
+	pointRef, err := fw.NewPointRegionRef("/values", [][]uint64{{3, 5}, {0, 0}, {1, 3}})
+	require.NoError(t, err)
+	objRef, err := fw.NewObjectRef("/values")
+	require.NoError(t, err)
+
+	regions, err := fw.CreateDataset("/regions", RegionReference, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, regions.Write([]RegionRef{slabRef, pointRef, {}}))
+	objects, err := fw.CreateDataset("/objects", ObjectReference, []uint64{1})
+	require.NoError(t, err)
+	require.NoError(t, objects.Write([]ObjectRef{objRef}))
+
+	// Invalid selections and value types.
+	_, err = fw.NewRegionRef("/values", &HyperslabSelection{Start: []uint64{3, 0}, Count: []uint64{2, 1}})
+	require.ErrorContains(t, err, "invalid selection")
+	_, err = fw.NewPointRegionRef("/values", [][]uint64{{4, 0}})
+	require.ErrorContains(t, err, "out of bounds in dimension 0")
+	_, err = fw.NewPointRegionRef("/values", [][]uint64{{1}})
+	require.ErrorContains(t, err, "has 1 coordinates")
+	_, err = fw.NewRegionRef("/", hyperslab)
+	require.ErrorContains(t, err, "is not a dataset")
+	require.ErrorContains(t, regions.Write([]uint64{1, 2, 3}), "require []RegionRef")
+	require.ErrorContains(t, objects.Write([]RegionRef{slabRef}), "require []ObjectRef")
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rds, err := f.OpenDataset("/regions")
+	require.NoError(t, err)
+	refs, err := rds.ReadReferences()
+	require.NoError(t, err)
+	require.Equal(t, []Reference{slabRef, pointRef, RegionRef{}}, refs)
+
+	target, sel, err := f.Region(slabRef)
+	require.NoError(t, err)
+	require.Equal(t, "values", target.Name())
+	require.Equal(t, hyperslab, sel.Hyperslab)
+
+	data, err := f.ReadRegion(slabRef)
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 4, 5, 13, 14, 16, 17}, data)
+
+	data, err = f.ReadRegion(pointRef)
+	require.NoError(t, err)
+	require.Equal(t, []float64{23, 0, 9}, data)
+
+	ods, err := f.OpenDataset("/objects")
+	require.NoError(t, err)
+	orefs, err := ods.ReadReferences()
+	require.NoError(t, err)
+	obj, err := f.Dereference(orefs[0])
+	require.NoError(t, err)
+	require.Equal(t, "values", obj.Name())
+}
+
+func TestReadReferences_Revised(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "trefer_obj.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	// An object reference and a region reference selecting all of
+	// Group1/Dataset1.
+	for _, name := range []string{"/Dataset3", "/Dataset5"} {
+		ds, err := f.OpenDataset(name)
+		require.NoError(t, err)
+		refs, err := ds.ReadReferences()
+		require.NoError(t, err)
+		require.Len(t, refs, 1)
+		require.IsType(t, Ref{}, refs[0])
+
+		obj, err := f.Dereference(refs[0])
+		require.NoError(t, err)
+		require.IsType(t, &Dataset{}, obj)
+		require.Equal(t, "Dataset1", obj.Name())
+	}
+
+	ds, err := f.OpenDataset("/Dataset5")
+	require.NoError(t, err)
+	refs, err := ds.ReadReferences()
+	require.NoError(t, err)
+	_, sel, err := f.Region(refs[0])
+	require.NoError(t, err)
+	require.True(t, sel.All)
+
+	// A scalar reference to a group.
+	g, err := Open(filepath.Join("testdata", "hdf5_official", "trefer_grp.h5"))
+	require.NoError(t, err)
+	defer func() { _ = g.Close() }()
+	ds, err = g.OpenDataset("/dset")
+	require.NoError(t, err)
+	refs, err = ds.ReadReferences()
+	require.NoError(t, err)
+	require.Len(t, refs, 1)
+	require.Equal(t, uint8(RefTypeObject), refs[0].(Ref).Type)
+	obj, err := g.Dereference(refs[0])
+	require.NoError(t, err)
+	require.IsType(t, &Group{}, obj)
+	require.Equal(t, "group", obj.Name())
+}
+
+func TestReadRegion_Revised(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "trefer_reg.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/Dataset1")
+	require.NoError(t, err)
+	refs, err := ds.ReadReferences()
+	require.NoError(t, err)
+	require.Len(t, refs, 4)
+
+	// Dataset2 holds 3*i mod 256 at element i of a 10x10 array.
+	element := func(r, c uint64) float64 { return float64((3 * (10*r + c)) % 256) }
+
+	target, sel, err := f.Region(refs[0])
+	require.NoError(t, err)
+	require.Equal(t, "Dataset2", target.Name())
+	require.Equal(t, &HyperslabSelection{
+		Start:  []uint64{2, 2},
+		Count:  []uint64{1, 1},
+		Stride: []uint64{1, 1},
+		Block:  []uint64{6, 6},
+	}, sel.Hyperslab)
+	data, err := f.ReadRegion(refs[0])
+	require.NoError(t, err)
+	require.Len(t, data, 36)
+	require.Equal(t, element(2, 2), data[0])
+	require.Equal(t, element(7, 7), data[35])
+
+	_, sel, err = f.Region(refs[1])
+	require.NoError(t, err)
+	points := [][]uint64{{6, 9}, {2, 2}, {8, 4}, {1, 6}, {2, 8}, {3, 2}, {0, 4}, {9, 0}, {7, 1}, {3, 3}}
+	require.Equal(t, points, sel.Points)
+	data, err = f.ReadRegion(refs[1])
+	require.NoError(t, err)
+	var want []float64
+	for _, p := range points {
+		want = append(want, element(p[0], p[1]))
+	}
+	require.Equal(t, want, data)
+
+	// Rows 1-2, 5-6 and 9 (blocks of 2 every 4 rows, unlimited count),
+	// columns 8-9.
+	_, sel, err = f.Region(refs[2])
+	require.NoError(t, err)
+	require.Equal(t, []uint64{core.SelectionUnlimited, 1}, sel.Hyperslab.Count)
+	data, err = f.ReadRegion(refs[2])
+	require.NoError(t, err)
+	want = want[:0]
+	for _, r := range []uint64{1, 2, 5, 6, 9} {
+		want = append(want, element(r, 8), element(r, 9))
+	}
+	require.Equal(t, want, data)
+
+	// A scalar null reference.
+	ds, err = f.OpenDataset("/DS_NA")
+	require.NoError(t, err)
+	refs, err = ds.ReadReferences()
+	require.NoError(t, err)
+	require.Equal(t, []Reference{Ref{}}, refs)
+	_, err = f.ReadRegion(refs[0])
+	require.ErrorContains(t, err, "null region reference")
+}
+
+func TestDereferenceAttribute(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "trefer_attr.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/Dataset3")
+	require.NoError(t, err)
+	refs, err := ds.ReadReferences()
+	require.NoError(t, err)
+	require.Len(t, refs, 4)
+
+	// The second reference names an attribute Dataset2 does not have.
+	owners := []string{"Dataset1", "Dataset2", "Group1", "Datatype1"}
+	names := []string{"Attr1", "Attr1", "Attr2", "Attr3"}
+	values := [][]uint32{{0, 3, 6, 9}, nil, {1, 4, 7, 10}, {2, 5, 8, 11}}
+	for i, ref := range refs {
+		obj, err := f.Dereference(ref)
+		require.NoError(t, err)
+		require.Equal(t, owners[i], obj.Name())
+
+		attr, err := f.DereferenceAttribute(ref)
+		if values[i] == nil {
+			require.ErrorContains(t, err, `attribute "Attr1" not found`)
+			continue
+		}
+		require.NoError(t, err)
+		require.Equal(t, names[i], attr.Name)
+		value, err := attr.ReadValue()
+		require.NoError(t, err)
+		require.Equal(t, values[i], value)
+	}
+
+	_, err = f.DereferenceAttribute(ObjectRef(1))
+	require.ErrorContains(t, err, "not an attribute reference")
+}
+
+func TestDereference_ExternalFile(t *testing.T) {
+	path := filepath.Join("testdata", "hdf5_official", "trefer_ext2.h5")
+	f, err := Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/Dataset3")
+	require.NoError(t, err)
+	refs, err := ds.ReadReferences()
+	require.NoError(t, err)
+	require.NotEmpty(t, refs)
+	for _, ref := range refs {
+		require.Equal(t, "trefer_ext1.h5", ref.(Ref).File)
+	}
+
+	attr, err := f.DereferenceAttribute(refs[0])
+	require.NoError(t, err)
+	require.Equal(t, "Attr1", attr.Name)
+
+	// A custom opener is used instead of the default search.
+	var opened []string
+	g, err := Open(path, WithFileOpener(func(name string) (*File, error) {
+		opened = append(opened, name)
+		return nil, errors.New("not available")
+	}))
+	require.NoError(t, err)
+	defer func() { _ = g.Close() }()
+	_, err = g.Dereference(refs[0])
+	require.ErrorContains(t, err, "not available")
+	require.Equal(t, []string{"trefer_ext1.h5"}, opened)
+}
+
+func TestRevisedRef_Written(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "revised.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate, WithRootAttribute("units", "counts"))
+	require.NoError(t, err)
+
+	values := make([]int32, 24)
+	for i := range values {
+		values[i] = int32(i)
+	}
+	ds, err := fw.CreateDataset("/values", Int32, []uint64{4, 6})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(values))
+
+	objRef, err := fw.CreateObjectRef("/values")
+	require.NoError(t, err)
+	slabRef, err := fw.CreateRegionRef("/values", &HyperslabSelection{Start: []uint64{1, 2}, Count: []uint64{2, 2}})
+	require.NoError(t, err)
+	pointRef, err := fw.CreatePointRegionRef("/values", [][]uint64{{3, 5}, {0, 1}})
+	require.NoError(t, err)
+	attrRef, err := fw.CreateAttributeRef("/", "units")
+	require.NoError(t, err)
+	external := objRef
+	external.File = "other.h5"
+
+	refs, err := fw.CreateDataset("/refs", RevisedReference, []uint64{6})
+	require.NoError(t, err)
+	require.NoError(t, refs.Write([]Ref{objRef, slabRef, pointRef, attrRef, external, {}}))
+	require.ErrorContains(t, refs.Write([]ObjectRef{1}), "require []Ref")
+	_, err = fw.CreateAttributeRef("/", "")
+	require.ErrorContains(t, err, "cannot be empty")
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rds, err := f.OpenDataset("/refs")
+	require.NoError(t, err)
+	got, err := rds.ReadReferences()
+	require.NoError(t, err)
+	require.Equal(t, []Reference{objRef, slabRef, pointRef, attrRef, external, Ref{}}, got)
+
+	obj, err := f.Dereference(got[0])
+	require.NoError(t, err)
+	require.Equal(t, "values", obj.Name())
+
+	data, err := f.ReadRegion(got[1])
+	require.NoError(t, err)
+	require.Equal(t, []float64{8, 9, 14, 15}, data)
+	data, err = f.ReadRegion(got[2])
+	require.NoError(t, err)
+	require.Equal(t, []float64{23, 1}, data)
+
+	attr, err := f.DereferenceAttribute(got[3])
+	require.NoError(t, err)
+	value, err := attr.ReadValue()
+	require.NoError(t, err)
+	require.Equal(t, "counts", value)
+
+	_, err = f.Dereference(got[4])
+	require.ErrorContains(t, err, `failed to open referenced file "other.h5"`)
+}
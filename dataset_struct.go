@@ -0,0 +1,762 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// Enum is implemented by integer types that are stored as HDF5 enumerations
+// in struct datasets. EnumValues is called on the zero value of the type.
+//
+// Example:
+//
+//	type State int8
+//
+//	func (State) EnumValues() ([]string, []int64) {
+//	    return []string{"OFF", "ON"}, []int64{0, 1}
+//	}
+type Enum interface {
+	EnumValues() (names []string, values []int64)
+}
+
+// enumInterface is the reflect type of Enum.
+var enumInterface = reflect.TypeFor[Enum]()
+
+// structBasicTypes maps Go numeric kinds to their HDF5 datatypes.
+var structBasicTypes = map[reflect.Kind]Datatype{
+	reflect.Int8:    Int8,
+	reflect.Int16:   Int16,
+	reflect.Int32:   Int32,
+	reflect.Int64:   Int64,
+	reflect.Int:     Int64,
+	reflect.Uint8:   Uint8,
+	reflect.Uint16:  Uint16,
+	reflect.Uint32:  Uint32,
+	reflect.Uint64:  Uint64,
+	reflect.Uint:    Uint64,
+	reflect.Float32: Float32,
+	reflect.Float64: Float64,
+}
+
+// WithPackedStructs lays out the members of datasets created with
+// CreateDatasetFromStruct without alignment padding, as numpy does by default.
+// Without it, members are aligned like the fields of the equivalent C struct.
+func WithPackedStructs() DatasetOption {
+	return func(cfg *datasetConfig) {
+		cfg.packedStructs = true
+	}
+}
+
+// CreateDatasetFromStruct creates a dataset whose compound datatype is derived
+// from the struct type T. Write its data with DatasetWriter.WriteStructs and
+// read it back with Dataset.ReadStructs.
+//
+// Members are named by `hdf5:"name"` struct tags, or by the Go field name if
+// the field has no tag. Fields tagged `hdf5:"-"` and unexported fields are
+// skipped. Field types map to HDF5 types as follows:
+//   - integers, floats: native little-endian integers and IEEE floats
+//     (int and uint are stored as 64-bit values)
+//   - bool: 8-bit enum {FALSE, TRUE}, as written by h5py
+//   - integer types implementing Enum: enums of the same width
+//   - string: variable-length string, or a fixed-length string of N bytes
+//     with `hdf5:"name,size=N"` (longer values are truncated)
+//   - arrays, including nested ones such as [2][3]float32: array types
+//   - structs: nested compound types
+//
+// Example:
+//
+//	type Particle struct {
+//	    ID       int32      `hdf5:"id"`
+//	    Name     string     `hdf5:"name,size=16"`
+//	    Position [3]float64 `hdf5:"position"`
+//	}
+//
+//	ds, _ := hdf5.CreateDatasetFromStruct[Particle](fw, "/particles", []uint64{2})
+//	ds.WriteStructs([]Particle{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}})
+func CreateDatasetFromStruct[T any](fw *FileWriter, name string, dims []uint64, opts ...DatasetOption) (*DatasetWriter, error) {
+	if err := validateDatasetName(name); err != nil {
+		return nil, err
+	}
+	if err := validateDimensions(dims); err != nil {
+		return nil, err
+	}
+
+	config := &datasetConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+
+	t := reflect.TypeFor[T]()
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("%s is not a struct type", t)
+	}
+	st, err := newStructType(t, 0, structConfig{packed: config.packedStructs, offsetSize: fw.file.sb.OffsetSize})
+	if err != nil {
+		return nil, err
+	}
+
+	dw, err := fw.createDataset(name, &parsedTypeHandler{msg: st.dt}, dims, config)
+	if err != nil {
+		return nil, err
+	}
+	dw.structType = st
+	return dw, nil
+}
+
+// WriteStructs writes a slice of structs to a dataset created with
+// CreateDatasetFromStruct for the same struct type. The slice length must
+// equal the number of dataset elements.
+func (dw *DatasetWriter) WriteStructs(data any) error {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return fmt.Errorf("expected a slice of structs, got %T", data)
+	}
+	st := dw.structType
+	if st == nil || st.goType != v.Type().Elem() {
+		return fmt.Errorf("dataset %s was not created for %s (use CreateDatasetFromStruct)", dw.name, v.Type().Elem())
+	}
+
+	total := calculateTotalElements(dw.dims)
+	if uint64(v.Len()) != total {
+		return fmt.Errorf("data length %d doesn't match dataset size %d", v.Len(), total)
+	}
+
+	size := int(st.dt.Size)
+	buf := make([]byte, v.Len()*size)
+	for i := 0; i < v.Len(); i++ {
+		if err := st.encode(dw.fileWriter, v.Index(i), buf[i*size:(i+1)*size]); err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	return dw.WriteRaw(buf)
+}
+
+// ReadStructs reads all elements of a compound dataset into dst, which must
+// be a pointer to a slice of structs. The slice is (re)allocated to the
+// number of dataset elements.
+//
+// Struct fields are matched to compound members by name, using the same
+// tags as CreateDatasetFromStruct, so the struct may list the members in any
+// order and skip members it does not need. Values are converted from their
+// stored representation: any integer width or byte order into Go integers
+// (failing on overflow), integers and floats into Go floats, fixed-length
+// and variable-length strings into strings, and arrays of the same number of
+// elements into Go arrays.
+//
+// Example:
+//
+//	var particles []Particle
+//	if err := ds.ReadStructs(&particles); err != nil {
+//	    return err
+//	}
+func (d *Dataset) ReadStructs(dst any) error {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Slice ||
+		v.Elem().Type().Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("destination must be a pointer to a slice of structs, got %T", dst)
+	}
+
+	rawData, info, err := d.readRaw()
+	if err != nil {
+		return err
+	}
+	if info.Datatype.Class != core.DatatypeCompound {
+		return fmt.Errorf("dataset %q is not compound: %s", d.name, info.Datatype)
+	}
+
+	total := info.Dataspace.TotalElements()
+	size := uint64(info.Datatype.Size)
+	if uint64(len(rawData)) < total*size {
+		return fmt.Errorf("data truncated: need %d bytes, have %d", total*size, len(rawData))
+	}
+
+	slice := reflect.MakeSlice(v.Elem().Type(), int(total), int(total)) //nolint:gosec // G115: bounded by the data read
+	sd := newStructDecoder(d.file)
+	for i := uint64(0); i < total; i++ {
+		if err := sd.decode(info.Datatype, rawData[i*size:(i+1)*size], slice.Index(int(i))); err != nil { //nolint:gosec // G115: i < total
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+	}
+	v.Elem().Set(slice)
+	return nil
+}
+
+// structKind says how a Go value is encoded.
+type structKind uint8
+
+const (
+	structInt structKind = iota
+	structUint
+	structFloat
+	structBool
+	structFixedString
+	structVLenString
+	structArray
+	structCompound
+)
+
+// structType maps a Go type to the HDF5 datatype used for it in struct
+// datasets.
+type structType struct {
+	goType reflect.Type
+	kind   structKind
+	dt     *core.DatatypeMessage
+	align  uint32
+	elem   *structType   // Element type of arrays
+	dims   []uint64      // Dimensions of arrays
+	fields []structField // Members of compounds
+}
+
+// structField is a struct field stored as a compound member.
+type structField struct {
+	index  []int
+	name   string
+	offset uint32
+	typ    *structType
+}
+
+// structConfig holds the file and dataset settings that affect the layout
+// of struct datasets.
+type structConfig struct {
+	packed     bool  // No alignment padding between members
+	offsetSize uint8 // Size of file offsets in heap IDs
+}
+
+// fieldTag is a struct field with its parsed `hdf5:"name,size=N"` tag.
+type fieldTag struct {
+	index []int
+	name  string
+	size  uint32
+}
+
+// structFields returns the fields of t that are stored as compound members.
+func structFields(t reflect.Type) ([]fieldTag, error) {
+	var fields []fieldTag
+	names := make(map[string]bool)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag, hasTag := f.Tag.Lookup("hdf5")
+		if !f.IsExported() || tag == "-" {
+			continue
+		}
+
+		ft := fieldTag{index: f.Index, name: f.Name}
+		if hasTag {
+			parts := strings.Split(tag, ",")
+			if parts[0] != "" {
+				ft.name = parts[0]
+			}
+			for _, opt := range parts[1:] {
+				value, ok := strings.CutPrefix(opt, "size=")
+				if !ok {
+					return nil, fmt.Errorf("field %s: unknown tag option %q", f.Name, opt)
+				}
+				size, err := strconv.ParseUint(value, 10, 32)
+				if err != nil || size == 0 {
+					return nil, fmt.Errorf("field %s: invalid string size %q", f.Name, value)
+				}
+				ft.size = uint32(size)
+			}
+		}
+
+		if names[ft.name] {
+			return nil, fmt.Errorf("field %s: duplicate member name %q", f.Name, ft.name)
+		}
+		names[ft.name] = true
+		fields = append(fields, ft)
+	}
+	return fields, nil
+}
+
+// newStructType derives the HDF5 datatype of t. size is the fixed string
+// size from the field tag (0 for variable-length strings).
+//
+//nolint:gocyclo,cyclop // One case per supported Go kind
+func newStructType(t reflect.Type, size uint32, cfg structConfig) (*structType, error) {
+	st := &structType{goType: t}
+
+	var err error
+	switch kind := t.Kind(); {
+	case t.Implements(enumInterface):
+		base, ok := structBasicTypes[kind]
+		if !ok || kind == reflect.Float32 || kind == reflect.Float64 {
+			return nil, fmt.Errorf("enum type %s must be an integer type", t)
+		}
+		names, values := reflect.Zero(t).Interface().(Enum).EnumValues()
+		st.kind = structInt
+		if kind >= reflect.Uint && kind <= reflect.Uint64 {
+			st.kind = structUint
+		}
+		st.dt, err = registryType(&enumTypeHandler{base}, &datasetConfig{enumNames: names, enumValues: values})
+
+	case kind == reflect.Bool:
+		st.kind = structBool
+		st.dt, err = registryType(&enumTypeHandler{Int8}, &datasetConfig{
+			enumNames:  []string{"FALSE", "TRUE"},
+			enumValues: []int64{0, 1},
+		})
+
+	case kind == reflect.String && size > 0:
+		st.kind = structFixedString
+		st.dt, err = registryType(datatypeRegistry[String], &datasetConfig{stringSize: size})
+		st.align = 1
+
+	case kind == reflect.String:
+		st.kind = structVLenString
+		st.dt, err = vlenStringType(cfg.offsetSize)
+		st.align = 8
+
+	case kind == reflect.Array:
+		return newStructArray(st, size, cfg)
+
+	case kind == reflect.Struct:
+		return newStructCompound(st, cfg)
+
+	default:
+		base, ok := structBasicTypes[kind]
+		if !ok {
+			return nil, fmt.Errorf("unsupported struct field type %s", t)
+		}
+		switch kind {
+		case reflect.Float32, reflect.Float64:
+			st.kind = structFloat
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			st.kind = structUint
+		default:
+			st.kind = structInt
+		}
+		st.dt, err = registryType(datatypeRegistry[base], &datasetConfig{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", t, err)
+	}
+	if st.align == 0 {
+		st.align = st.dt.Size
+	}
+	return st, nil
+}
+
+// newStructArray derives the array datatype of a (possibly nested) Go array.
+func newStructArray(st *structType, size uint32, cfg structConfig) (*structType, error) {
+	leaf := st.goType
+	count := uint64(1)
+	for leaf.Kind() == reflect.Array {
+		st.dims = append(st.dims, uint64(leaf.Len())) //nolint:gosec // G115: array lengths are non-negative
+		count *= uint64(leaf.Len())                   //nolint:gosec // G115: array lengths are non-negative
+		leaf = leaf.Elem()
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%s: arrays must not be empty", st.goType)
+	}
+
+	elem, err := newStructType(leaf, size, cfg)
+	if err != nil {
+		return nil, err
+	}
+	data, err := core.EncodeArrayDatatypeMessage(core.EncodeParsedDatatypeMessage(elem.dt), st.dims,
+		uint32(count)*elem.dt.Size) //nolint:gosec // G115: array sizes are limited by memory
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", st.goType, err)
+	}
+	if st.dt, err = core.ParseDatatypeMessage(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", st.goType, err)
+	}
+	st.kind = structArray
+	st.elem = elem
+	st.align = elem.align
+	return st, nil
+}
+
+// newStructCompound derives the compound datatype of a Go struct.
+func newStructCompound(st *structType, cfg structConfig) (*structType, error) {
+	tags, err := structFields(st.goType)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", st.goType, err)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("%s: struct has no exported fields", st.goType)
+	}
+
+	st.kind = structCompound
+	st.align = 1
+	offset := uint32(0)
+	defs := make([]core.CompoundFieldDef, len(tags))
+	for i, tag := range tags {
+		ft, err := newStructType(st.goType.FieldByIndex(tag.index).Type, tag.size, cfg)
+		if err != nil {
+			return nil, err
+		}
+		if !cfg.packed {
+			offset = alignUp(offset, ft.align)
+			st.align = max(st.align, ft.align)
+		}
+		st.fields = append(st.fields, structField{index: tag.index, name: tag.name, offset: offset, typ: ft})
+		defs[i] = core.CompoundFieldDef{Name: tag.name, Offset: offset, Type: ft.dt}
+		offset += ft.dt.Size
+	}
+	if !cfg.packed {
+		offset = alignUp(offset, st.align)
+	}
+
+	data, err := core.EncodeCompoundDatatypeV3(offset, defs)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", st.goType, err)
+	}
+	if st.dt, err = core.ParseDatatypeMessage(data); err != nil {
+		return nil, fmt.Errorf("%s: %w", st.goType, err)
+	}
+	return st, nil
+}
+
+// registryType encodes a registered datatype and parses it back, giving the
+// message with its properties for use inside compound and array types.
+func registryType(handler datatypeHandler, config *datasetConfig) (*core.DatatypeMessage, error) {
+	info, err := handler.GetInfo(config)
+	if err != nil {
+		return nil, err
+	}
+	data, err := handler.EncodeDatatypeMessage(info)
+	if err != nil {
+		return nil, err
+	}
+	return core.ParseDatatypeMessage(data)
+}
+
+// vlenStringType returns the datatype of variable-length ASCII strings as
+// the C library writes it: a sequence of unsigned bytes flagged as a string,
+// stored as its length and a global heap ID.
+func vlenStringType(offsetSize uint8) (*core.DatatypeMessage, error) {
+	base, err := registryType(datatypeRegistry[Uint8], &datasetConfig{})
+	if err != nil {
+		return nil, err
+	}
+	return &core.DatatypeMessage{
+		Class:         core.DatatypeVarLen,
+		Version:       1,
+		Size:          4 + uint32(offsetSize) + 4,
+		ClassBitField: 0x01, // String, null-terminated, ASCII
+		Properties:    core.EncodeParsedDatatypeMessage(base),
+	}, nil
+}
+
+// alignUp rounds offset up to a multiple of align.
+func alignUp(offset, align uint32) uint32 {
+	if align <= 1 {
+		return offset
+	}
+	return (offset + align - 1) / align * align
+}
+
+// encode writes the value v into buf, which holds one element of st.
+// Variable-length strings are written to the global heap of fw.
+func (st *structType) encode(fw *FileWriter, v reflect.Value, buf []byte) error {
+	switch st.kind {
+	case structInt:
+		putUint(buf, uint64(v.Int()), st.dt.Size) //nolint:gosec // G115: two's complement encoding
+	case structUint:
+		putUint(buf, v.Uint(), st.dt.Size)
+	case structBool:
+		if v.Bool() {
+			buf[0] = 1
+		}
+	case structFloat:
+		if st.dt.Size == 4 {
+			binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(v.Float())))
+		} else {
+			binary.LittleEndian.PutUint64(buf, math.Float64bits(v.Float()))
+		}
+	case structFixedString:
+		copy(buf[:st.dt.Size], v.String())
+	case structVLenString:
+		return encodeVLenString(fw, v.String(), buf)
+	case structArray:
+		size := int(st.elem.dt.Size)
+		i := 0
+		return arrayLeaves(v, len(st.dims), func(leaf reflect.Value) error {
+			err := st.elem.encode(fw, leaf, buf[i*size:(i+1)*size])
+			i++
+			return err
+		})
+	case structCompound:
+		for _, f := range st.fields {
+			if err := f.typ.encode(fw, v.FieldByIndex(f.index), buf[f.offset:]); err != nil {
+				return fmt.Errorf("%s: %w", f.name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// encodeVLenString writes s to the global heap and stores its length and
+// heap ID in buf. Empty strings are stored as null references.
+func encodeVLenString(fw *FileWriter, s string, buf []byte) error {
+	if s == "" {
+		return nil
+	}
+	heapID, err := fw.globalHeapWriter.WriteToGlobalHeap([]byte(s))
+	if err != nil {
+		return fmt.Errorf("write string to heap: %w", err)
+	}
+	offsetSize := int(fw.file.sb.OffsetSize)
+	binary.LittleEndian.PutUint32(buf, uint32(len(s))) //nolint:gosec // G115: heap objects are limited to 4 GiB
+	writeOffset(buf[4:], heapID.CollectionAddress, offsetSize)
+	binary.LittleEndian.PutUint32(buf[4+offsetSize:], uint32(heapID.ObjectIndex))
+	return nil
+}
+
+// putUint writes the low size bytes of v in little-endian order.
+func putUint(buf []byte, v uint64, size uint32) {
+	switch size {
+	case 1:
+		buf[0] = byte(v)
+	case 2:
+		binary.LittleEndian.PutUint16(buf, uint16(v)) //nolint:gosec // G115: truncation to the field width
+	case 4:
+		binary.LittleEndian.PutUint32(buf, uint32(v)) //nolint:gosec // G115: truncation to the field width
+	default:
+		binary.LittleEndian.PutUint64(buf, v)
+	}
+}
+
+// arrayLeaves calls fn with the elements of a Go array nested depth levels
+// deep, in row-major order.
+func arrayLeaves(v reflect.Value, depth int, fn func(reflect.Value) error) error {
+	if depth == 0 {
+		return fn(v)
+	}
+	for i := 0; i < v.Len(); i++ {
+		if err := arrayLeaves(v.Index(i), depth-1, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// structDecoder decodes compound data into Go structs. Parsed datatypes,
+// struct fields and global heap collections are cached across elements.
+type structDecoder struct {
+	f         *File
+	compounds map[*core.DatatypeMessage]*core.CompoundType
+	arrays    map[*core.DatatypeMessage]*arrayInfo
+	fields    map[reflect.Type][]fieldTag
+	heaps     map[uint64]*core.GlobalHeapCollection
+}
+
+// arrayInfo is a parsed array datatype.
+type arrayInfo struct {
+	count uint64
+	base  *core.DatatypeMessage
+}
+
+func newStructDecoder(f *File) *structDecoder {
+	return &structDecoder{
+		f:         f,
+		compounds: make(map[*core.DatatypeMessage]*core.CompoundType),
+		arrays:    make(map[*core.DatatypeMessage]*arrayInfo),
+		fields:    make(map[reflect.Type][]fieldTag),
+		heaps:     make(map[uint64]*core.GlobalHeapCollection),
+	}
+}
+
+// decode decodes one element of datatype dt from data into v.
+//
+//nolint:gocyclo,cyclop // One case per supported Go kind
+func (sd *structDecoder) decode(dt *core.DatatypeMessage, data []byte, v reflect.Value) error {
+	if uint64(len(data)) < uint64(dt.Size) {
+		return fmt.Errorf("data truncated: need %d bytes, have %d", dt.Size, len(data))
+	}
+	data = data[:dt.Size]
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Bool:
+		if dt.Class != core.DatatypeFixed && dt.Class != core.DatatypeEnum {
+			return fmt.Errorf("cannot decode %s into %s", dt, v.Type())
+		}
+		var n [1]int64
+		if err := core.DecodeNumeric(data, dt, n[:]); err != nil {
+			return err
+		}
+		if v.Kind() == reflect.Bool {
+			v.SetBool(n[0] != 0)
+			return nil
+		}
+		if v.OverflowInt(n[0]) {
+			return fmt.Errorf("value %d overflows %s", n[0], v.Type())
+		}
+		v.SetInt(n[0])
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if dt.Class != core.DatatypeFixed && dt.Class != core.DatatypeEnum {
+			return fmt.Errorf("cannot decode %s into %s", dt, v.Type())
+		}
+		var n [1]uint64
+		if err := core.DecodeNumeric(data, dt, n[:]); err != nil {
+			return err
+		}
+		if v.OverflowUint(n[0]) {
+			return fmt.Errorf("value %d overflows %s", n[0], v.Type())
+		}
+		v.SetUint(n[0])
+
+	case reflect.Float32, reflect.Float64:
+		if !dt.IsNumeric() {
+			return fmt.Errorf("cannot decode %s into %s", dt, v.Type())
+		}
+		var n [1]float64
+		if err := core.DecodeNumeric(data, dt, n[:]); err != nil {
+			return err
+		}
+		v.SetFloat(n[0])
+
+	case reflect.String:
+		switch {
+		case dt.IsFixedString():
+			v.SetString(fixedString(data, dt.GetStringPadding()))
+		case dt.IsVariableString():
+			s, err := sd.vlenString(data)
+			if err != nil {
+				return err
+			}
+			v.SetString(s)
+		default:
+			return fmt.Errorf("cannot decode %s into %s", dt, v.Type())
+		}
+
+	case reflect.Array:
+		return sd.decodeArray(dt, data, v)
+
+	case reflect.Struct:
+		return sd.decodeStruct(dt, data, v)
+
+	default:
+		return fmt.Errorf("unsupported struct field type %s", v.Type())
+	}
+	return nil
+}
+
+// decodeArray decodes an array datatype into a (possibly nested) Go array
+// with the same number of elements.
+func (sd *structDecoder) decodeArray(dt *core.DatatypeMessage, data []byte, v reflect.Value) error {
+	if dt.Class != core.DatatypeArray {
+		return fmt.Errorf("cannot decode %s into %s", dt, v.Type())
+	}
+	info, ok := sd.arrays[dt]
+	if !ok {
+		dims, base, err := arrayType(dt)
+		if err != nil {
+			return err
+		}
+		info = &arrayInfo{count: 1, base: base}
+		for _, dim := range dims {
+			info.count *= dim
+		}
+		sd.arrays[dt] = info
+	}
+
+	depth, count := 0, uint64(1)
+	for t := v.Type(); t.Kind() == reflect.Array; t = t.Elem() {
+		depth++
+		count *= uint64(t.Len()) //nolint:gosec // G115: array lengths are non-negative
+	}
+	if count != info.count {
+		return fmt.Errorf("cannot decode array of %d elements into %s", info.count, v.Type())
+	}
+
+	size := int(info.base.Size)
+	i := 0
+	return arrayLeaves(v, depth, func(leaf reflect.Value) error {
+		err := sd.decode(info.base, data[i*size:], leaf)
+		i++
+		return err
+	})
+}
+
+// decodeStruct decodes a compound datatype into a Go struct, matching
+// fields to members by name.
+func (sd *structDecoder) decodeStruct(dt *core.DatatypeMessage, data []byte, v reflect.Value) error {
+	if dt.Class != core.DatatypeCompound {
+		return fmt.Errorf("cannot decode %s into %s", dt, v.Type())
+	}
+	ct, ok := sd.compounds[dt]
+	if !ok {
+		var err error
+		if ct, err = core.ParseCompoundType(dt); err != nil {
+			return err
+		}
+		sd.compounds[dt] = ct
+	}
+	tags, ok := sd.fields[v.Type()]
+	if !ok {
+		var err error
+		if tags, err = structFields(v.Type()); err != nil {
+			return err
+		}
+		sd.fields[v.Type()] = tags
+	}
+
+	for _, tag := range tags {
+		member := findMember(ct, tag.name)
+		if member == nil {
+			return fmt.Errorf("%s: no member %q in compound type", v.Type(), tag.name)
+		}
+		if uint64(member.Offset)+uint64(member.Type.Size) > uint64(len(data)) {
+			return fmt.Errorf("%s: member extends past the compound size", tag.name)
+		}
+		if err := sd.decode(member.Type, data[member.Offset:], v.FieldByIndex(tag.index)); err != nil {
+			return fmt.Errorf("%s: %w", tag.name, err)
+		}
+	}
+	return nil
+}
+
+// findMember returns the member of ct named name, or nil.
+func findMember(ct *core.CompoundType, name string) *core.CompoundMember {
+	for i := range ct.Members {
+		if ct.Members[i].Name == name {
+			return &ct.Members[i]
+		}
+	}
+	return nil
+}
+
+// vlenString reads a variable-length string element: its length followed by
+// the global heap ID of its data. Null references give "".
+func (sd *structDecoder) vlenString(data []byte) (string, error) {
+	offsetSize := int(sd.f.sb.OffsetSize)
+	if len(data) < 8+offsetSize {
+		return "", fmt.Errorf("variable-length element too short: %d bytes", len(data))
+	}
+	length := binary.LittleEndian.Uint32(data)
+	ref, err := core.ParseGlobalHeapReference(data[4:], offsetSize)
+	if err != nil {
+		return "", err
+	}
+	if ref.HeapAddress == 0 {
+		return "", nil
+	}
+
+	collection, ok := sd.heaps[ref.HeapAddress]
+	if !ok {
+		collection, err = core.ReadGlobalHeapCollection(sd.f.reader(), ref.HeapAddress, offsetSize)
+		if err != nil {
+			return "", fmt.Errorf("failed to read global heap collection at 0x%X: %w", ref.HeapAddress, err)
+		}
+		sd.heaps[ref.HeapAddress] = collection
+	}
+	obj, err := collection.GetObject(ref.ObjectIndex)
+	if err != nil {
+		return "", err
+	}
+
+	s := obj.Data
+	if uint64(length) < uint64(len(s)) {
+		s = s[:length]
+	}
+	return strings.TrimRight(string(s), "\x00"), nil
+}
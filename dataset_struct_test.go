@@ -0,0 +1,308 @@
+package hdf5
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+type structState uint8
+
+func (structState) EnumValues() ([]string, []int64) {
+	return []string{"IDLE", "RUNNING", "DONE"}, []int64{0, 1, 2}
+}
+
+type structPoint struct {
+	X float32 `hdf5:"x"`
+	Y float32 `hdf5:"y"`
+}
+
+type structRecord struct {
+	ID       int64          `hdf5:"id"`
+	Small    int8           `hdf5:"small"`
+	Short    int16          `hdf5:"short"`
+	Count    uint32         `hdf5:"count"`
+	Ratio    float64        `hdf5:"ratio"`
+	Enabled  bool           `hdf5:"enabled"`
+	State    structState    `hdf5:"state"`
+	Code     string         `hdf5:"code,size=4"`
+	Label    string         `hdf5:"label"`
+	Origin   structPoint    `hdf5:"origin"`
+	Vector   [3]float64     `hdf5:"vector"`
+	Matrix   [2][2]int16    `hdf5:"matrix"`
+	Tags     [2]string      `hdf5:"tags"`
+	Path     [2]structPoint `hdf5:"path"`
+	Untagged uint16
+	Skipped  int    `hdf5:"-"`
+	internal string //nolint:unused // Unexported fields are not stored
+}
+
+func TestStructDataset_RoundTrip(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "structs.h5")
+	records := []structRecord{
+		{
+			ID: 1 << 40, Small: -5, Short: 300, Count: 7, Ratio: 0.25, Enabled: true, State: 1,
+			Code: "AB", Label: "first record", Origin: structPoint{1, 2},
+			Vector: [3]float64{1, 2, 3}, Matrix: [2][2]int16{{1, 2}, {3, 4}}, Tags: [2]string{"a", ""},
+			Path: [2]structPoint{{3, 4}, {5, 6}}, Untagged: 9, Skipped: 42,
+		},
+		{
+			ID: -1, State: 2, Code: "ABCDEFG", Label: "", Tags: [2]string{"", "second"},
+		},
+	}
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := CreateDatasetFromStruct[structRecord](fw, "/records", []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteStructs(records))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rds, err := f.OpenDataset("/records")
+	require.NoError(t, err)
+
+	var got []structRecord
+	require.NoError(t, rds.ReadStructs(&got))
+
+	// Skipped fields are not stored and fixed strings are truncated.
+	want := records
+	want[0].Skipped = 0
+	want[1].Code = "ABCD"
+	require.Equal(t, want, got)
+
+	// The bool and enum members are stored as enums.
+	info, err := rds.info()
+	require.NoError(t, err)
+	ct, err := core.ParseCompoundType(info.Datatype)
+	require.NoError(t, err)
+	require.Len(t, ct.Members, 15)
+	require.Equal(t, "Untagged", ct.Members[14].Name)
+	require.Equal(t, core.DatatypeEnum, findMember(ct, "enabled").Type.Class)
+	require.Equal(t, core.DatatypeEnum, findMember(ct, "state").Type.Class)
+	require.True(t, findMember(ct, "label").Type.IsVariableString())
+}
+
+func TestStructDataset_Layout(t *testing.T) {
+	type padded struct {
+		A int8  `hdf5:"a"`
+		B int32 `hdf5:"b"`
+		C int16 `hdf5:"c"`
+	}
+
+	tests := []struct {
+		name    string
+		opts    []DatasetOption
+		offsets []uint32
+		size    uint32
+	}{
+		{name: "aligned", offsets: []uint32{0, 4, 8}, size: 12},
+		{name: "packed", opts: []DatasetOption{WithPackedStructs()}, offsets: []uint32{0, 1, 5}, size: 7},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "layout.h5")
+			fw, err := CreateForWrite(filename, CreateTruncate)
+			require.NoError(t, err)
+			ds, err := CreateDatasetFromStruct[padded](fw, "/data", []uint64{2}, tt.opts...)
+			require.NoError(t, err)
+			require.NoError(t, ds.WriteStructs([]padded{{1, 2, 3}, {-1, -2, -3}}))
+			require.NoError(t, fw.Close())
+
+			f, err := Open(filename)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+			rds, err := f.OpenDataset("/data")
+			require.NoError(t, err)
+
+			info, err := rds.info()
+			require.NoError(t, err)
+			ct, err := core.ParseCompoundType(info.Datatype)
+			require.NoError(t, err)
+			require.Equal(t, tt.size, ct.Size)
+			for i, member := range ct.Members {
+				require.Equal(t, tt.offsets[i], member.Offset, member.Name)
+			}
+
+			var got []padded
+			require.NoError(t, rds.ReadStructs(&got))
+			require.Equal(t, []padded{{1, 2, 3}, {-1, -2, -3}}, got)
+		})
+	}
+}
+
+func TestStructDataset_Chunked(t *testing.T) {
+	type sample struct {
+		Time  float64 `hdf5:"time"`
+		Value int32   `hdf5:"value"`
+		Note  string  `hdf5:"note"`
+	}
+
+	filename := filepath.Join(t.TempDir(), "chunked.h5")
+	samples := make([]sample, 10)
+	for i := range samples {
+		samples[i] = sample{Time: float64(i) / 2, Value: int32(i * i), Note: string(rune('a' + i))}
+	}
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := CreateDatasetFromStruct[sample](fw, "/samples", []uint64{10},
+		WithChunkDims([]uint64{4}), WithGZIPCompression(6))
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteStructs(samples))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rds, err := f.OpenDataset("/samples")
+	require.NoError(t, err)
+
+	var got []sample
+	require.NoError(t, rds.ReadStructs(&got))
+	require.Equal(t, samples, got)
+
+	// ReadCompound decodes the variable-length strings as well.
+	values, err := rds.ReadCompound()
+	require.NoError(t, err)
+	require.Equal(t, "c", values[2]["note"])
+}
+
+func TestStructDataset_ReadTestdata(t *testing.T) {
+	t.Run("subset of members", func(t *testing.T) {
+		type measurement struct {
+			Name string `hdf5:"name"`
+			ID   int    `hdf5:"id"`
+		}
+
+		f, err := Open("testdata/compound_test.h5")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		ds, err := f.OpenDataset("/measurements")
+		require.NoError(t, err)
+
+		var got []measurement
+		require.NoError(t, ds.ReadStructs(&got))
+		require.Len(t, got, 5)
+		require.Equal(t, measurement{Name: "Sample A", ID: 1}, got[0])
+		require.Equal(t, measurement{Name: "Sample E", ID: 5}, got[4])
+	})
+
+	t.Run("big-endian", func(t *testing.T) {
+		type row struct {
+			A int32   `hdf5:"a_name"`
+			B float32 `hdf5:"b_name"`
+			C float64 `hdf5:"c_name"`
+		}
+
+		f, err := Open("testdata/hdf5_official/tcompound2.h5")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		ds, err := f.OpenDataset("/dset1")
+		require.NoError(t, err)
+
+		var got []row
+		require.NoError(t, ds.ReadStructs(&got))
+		require.Len(t, got, 6)
+		require.Equal(t, row{A: 4, B: 16, C: 0.2}, got[4])
+	})
+
+	t.Run("strings and arrays", func(t *testing.T) {
+		type strs struct {
+			VLen       string    `hdf5:"VLEN_STR1"`
+			Fixed      string    `hdf5:"FIXLEN_STR1"`
+			VLenArray  [3]string `hdf5:"VLEN_STR_ARRAY1"`
+			FixedArray [3]string `hdf5:"FIXLEN_STR_ARRAY1"`
+		}
+
+		f, err := Open("testdata/hdf5_official/h5diff_comp_vl_strs.h5")
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+		ds, err := f.OpenDataset("/group/Compound_dset1")
+		require.NoError(t, err)
+
+		var got []strs
+		require.NoError(t, ds.ReadStructs(&got))
+		require.Len(t, got, 1)
+		require.Equal(t, "Variable length string", got[0].VLen)
+		require.Equal(t, "Fixed length string", got[0].Fixed)
+		require.Equal(t, "1 - Variable length string Array", got[0].VLenArray[0])
+		require.Equal(t, "3 - Fixed length string Array", got[0].FixedArray[2])
+	})
+}
+
+func TestStructDataset_Errors(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "errors.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	_, err = CreateDatasetFromStruct[int32](fw, "/scalar", []uint64{1})
+	require.ErrorContains(t, err, "not a struct type")
+
+	type unsupported struct {
+		M map[string]int
+	}
+	_, err = CreateDatasetFromStruct[unsupported](fw, "/map", []uint64{1})
+	require.ErrorContains(t, err, "unsupported struct field type")
+
+	type badTag struct {
+		S string `hdf5:"s,size=x"`
+	}
+	_, err = CreateDatasetFromStruct[badTag](fw, "/tag", []uint64{1})
+	require.ErrorContains(t, err, "invalid string size")
+
+	type duplicate struct {
+		A int32 `hdf5:"v"`
+		B int32 `hdf5:"v"`
+	}
+	_, err = CreateDatasetFromStruct[duplicate](fw, "/dup", []uint64{1})
+	require.ErrorContains(t, err, "duplicate member name")
+
+	type small struct {
+		V int32 `hdf5:"v"`
+	}
+	ds, err := CreateDatasetFromStruct[small](fw, "/small", []uint64{2})
+	require.NoError(t, err)
+	require.ErrorContains(t, ds.WriteStructs([]small{{1}}), "doesn't match dataset size")
+	require.ErrorContains(t, ds.WriteStructs([]structPoint{{}, {}}), "was not created for")
+	require.NoError(t, ds.WriteStructs([]small{{1}, {1000}}))
+
+	ints, err := fw.CreateDataset("/ints", Int32, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ints.Write([]int32{1, 2}))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	rds, err := f.OpenDataset("/small")
+	require.NoError(t, err)
+
+	var wrong []small
+	require.ErrorContains(t, rds.ReadStructs(wrong), "pointer to a slice of structs")
+
+	var missing []struct {
+		W int32 `hdf5:"w"`
+	}
+	require.ErrorContains(t, rds.ReadStructs(&missing), `no member "w"`)
+
+	var overflow []struct {
+		V int8 `hdf5:"v"`
+	}
+	require.ErrorContains(t, rds.ReadStructs(&overflow), "overflows int8")
+
+	var strs []struct {
+		V string `hdf5:"v"`
+	}
+	require.ErrorContains(t, rds.ReadStructs(&strs), "cannot decode")
+
+	ids, err := f.OpenDataset("/ints")
+	require.NoError(t, err)
+	require.ErrorContains(t, ids.ReadStructs(&wrong), "not compound")
+}
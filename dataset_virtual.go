@@ -0,0 +1,126 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// maxVirtualNesting limits how deeply virtual datasets may use other virtual
+// datasets as sources.
+const maxVirtualNesting = 32
+
+// WithVirtualPrefix sets a directory to search for the source files of
+// virtual datasets, like H5Pset_virtual_prefix in the HDF5 library. A leading
+// "${ORIGIN}" stands for the directory of the file being opened.
+//
+// Source files named by relative paths are searched in the directories of the
+// HDF5_VDS_PREFIX environment variable (a list separated like PATH), then in
+// the prefix, then next to the virtual dataset's file and finally relative to
+// the working directory. Absolute paths are tried as given first; if that
+// fails, their base name is searched the same way. Elements mapped from
+// source files or datasets that are not found read as the fill value.
+//
+// Example:
+//
+//	// Source files live in a "raw" directory next to the VDS file
+//	f, err := hdf5.Open("vds.h5", hdf5.WithVirtualPrefix("${ORIGIN}/raw"))
+func WithVirtualPrefix(prefix string) OpenOption {
+	return func(cfg *openConfig) {
+		cfg.virtualPrefix = prefix
+	}
+}
+
+// sourceReader is the reader passed to the dataset readers of package core.
+// It reads the file, resolves the source datasets of virtual datasets and
+// sets the concurrency of chunk reads.
+type sourceReader struct {
+	file    *File
+	nesting int // Number of virtual datasets this read passed through.
+}
+
+// reader returns the reader for reading datasets of f.
+func (f *File) reader() io.ReaderAt {
+	return &sourceReader{file: f}
+}
+
+// ReadAt implements io.ReaderAt.
+func (r *sourceReader) ReadAt(p []byte, off int64) (int, error) {
+	return r.file.r.ReadAt(p, off)
+}
+
+// ResolveVirtualSource implements core.VirtualSourceResolver.
+func (r *sourceReader) ResolveVirtualSource(fileName, datasetName string) (*core.VirtualSource, error) {
+	if r.nesting >= maxVirtualNesting {
+		return nil, errors.New("virtual datasets nested too deeply")
+	}
+
+	src := r.file.virtualSourceFile(fileName)
+	if src == nil {
+		return nil, nil
+	}
+
+	ds, err := src.OpenDataset(datasetName)
+	if errors.Is(err, ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	header, err := core.ReadObjectHeader(src.r, ds.address, src.sb)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read object header: %w", err)
+	}
+
+	return &core.VirtualSource{
+		Reader:     &sourceReader{file: src, nesting: r.nesting + 1},
+		Header:     header,
+		Superblock: src.sb,
+	}, nil
+}
+
+// virtualSourceFile returns the file a virtual dataset mapping names as its
+// source file, opened with the options of f, or nil if it is not found.
+// Source files are opened once and closed with f.
+// Reference: H5Fint.c - H5F_prefix_open_file().
+func (f *File) virtualSourceFile(name string) *File {
+	if name == core.VirtualSameFile {
+		return f
+	}
+
+	f.filesMu.Lock()
+	defer f.filesMu.Unlock()
+
+	if src, ok := f.virtualFiles[name]; ok {
+		return src
+	}
+
+	var src *File
+	for _, path := range f.searchPaths(name, "HDF5_VDS_PREFIX", f.config.virtualPrefix) {
+		opened, err := open(path, f.config.linked())
+		if err == nil {
+			src = opened
+			break
+		}
+	}
+
+	if f.virtualFiles == nil {
+		f.virtualFiles = make(map[string]*File)
+	}
+	f.virtualFiles[name] = src
+	return src
+}
+
+// readHyperslabVirtual reads hyperslab from virtual layout dataset.
+// The data is assembled from the source datasets, then the selected region
+// is extracted.
+func (d *Dataset) readHyperslabVirtual(selection *HyperslabSelection, header *core.ObjectHeader) (interface{}, error) {
+	rawData, info, err := core.ReadDatasetRaw(d.file.reader(), header, d.file.sb)
+	if err != nil {
+		return nil, err
+	}
+	return extractHyperslabFromRawData(selection, info.Datatype, info.Dataspace, rawData)
+}
@@ -0,0 +1,165 @@
+package hdf5
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// The N_vds.h5 files of the HDF5 test suite map planes of the N_*.h5 source
+// datasets; every plane of a source holds a single value.
+func TestVirtualDataset(t *testing.T) {
+	tests := []struct {
+		file  string
+		path  string
+		shape []uint64
+		plane func(i int) []float64 // Expected planes, or nil to skip.
+	}{
+		// Printf-named sources 4_0.h5 .. 4_2.h5, stacked.
+		{"4_vds.h5", "/vds_dset", []uint64{9, 4, 4}, func(i int) []float64 {
+			return repeat(float64(10*(i/3+1)+i%3), 16)
+		}},
+		// Sources 5_a.h5 .. 5_c.h5, interleaved with stride 3.
+		{"5_vds.h5", "/vds_dset", []uint64{9, 4, 4}, func(i int) []float64 {
+			return repeat(float64(10*(i%3+1)+i/3), 16)
+		}},
+		// Unmapped columns read as the fill value -9.
+		{"3_1_vds.h5", "/vds_dset", []uint64{5, 25, 8}, func(i int) []float64 {
+			plane := repeat(-9, 200)
+			for _, rows := range [][3]int{{1, 3, 10}, {4, 8, 20}, {9, 11, 30}, {12, 16, 40}, {17, 19, 50}, {20, 24, 60}} {
+				for r := rows[0]; r < rows[1]; r++ {
+					copy(plane[r*8:], repeat(float64(rows[2]+i), 8))
+				}
+			}
+			return plane
+		}},
+		{"1_vds.h5", "/vds_dset", []uint64{5, 18, 8}, nil},
+		{"2_vds.h5", "/vds_dset", []uint64{6, 8, 14}, nil},
+		// Printf-named sources; f-1.h5 is missing, which ends the series.
+		{"vds-eiger.h5", "/VDS-Eiger", []uint64{5, 10, 10}, nil},
+		// Four unlimited sources of different lengths, interleaved.
+		{"vds-percival-unlim-maxmin.h5", "/VDS-Percival-unlim-maxmin", []uint64{32, 10, 10}, func(i int) []float64 {
+			lengths := []int{5, 6, 7, 8}
+			source, n := i%4, i/4
+			if n >= lengths[source] {
+				return repeat(0, 100)
+			}
+			if n < 4 {
+				return repeat(float64(source+1), 100)
+			}
+			return repeat(float64(10*(source+1)), 100)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			f, err := Open("testdata/hdf5_official/" + tt.file)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ds, err := f.OpenDataset(tt.path)
+			require.NoError(t, err)
+
+			meta, err := ds.Meta()
+			require.NoError(t, err)
+			require.Equal(t, LayoutVirtual, meta.Layout)
+			require.Equal(t, tt.shape, meta.Shape)
+
+			data, err := ds.Read()
+			require.NoError(t, err)
+			planeSize := int(tt.shape[1] * tt.shape[2])
+			require.Len(t, data, int(tt.shape[0])*planeSize)
+
+			if tt.plane != nil {
+				for i := 0; i < int(tt.shape[0]); i++ {
+					require.Equal(t, tt.plane(i), data[i*planeSize:(i+1)*planeSize], "plane %d", i)
+				}
+			}
+		})
+	}
+}
+
+func TestVirtualDataset_ReadSlice(t *testing.T) {
+	f, err := Open("testdata/hdf5_official/5_vds.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/vds_dset")
+	require.NoError(t, err)
+
+	data, err := ds.ReadSlice([]uint64{2, 1, 0}, []uint64{3, 1, 2})
+	require.NoError(t, err)
+	require.Equal(t, []float64{30, 30, 11, 11, 21, 21}, data)
+}
+
+func TestVirtualDataset_MissingSources(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"4_vds.h5", "4_0.h5", "4_1.h5"} {
+		copyTestdata(t, name, dir)
+	}
+
+	f, err := Open(filepath.Join(dir, "4_vds.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/vds_dset")
+	require.NoError(t, err)
+
+	// The printf series ends at the missing 4_2.h5.
+	meta, err := ds.Meta()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{6, 4, 4}, meta.Shape)
+
+	data, err := ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, repeat(21, 16), data[4*16:5*16])
+	require.Equal(t, repeat(22, 16), data[5*16:])
+}
+
+func TestWithVirtualPrefix(t *testing.T) {
+	dir := t.TempDir()
+	copyTestdata(t, "5_vds.h5", dir)
+	path := filepath.Join(dir, "5_vds.h5")
+
+	read := func(opts ...OpenOption) []float64 {
+		f, err := Open(path, opts...)
+		require.NoError(t, err)
+		defer func() { _ = f.Close() }()
+
+		ds, err := f.OpenDataset("/vds_dset")
+		require.NoError(t, err)
+		data, err := ds.Read()
+		require.NoError(t, err)
+		return data
+	}
+
+	// Without the sources the unlimited dimension has no extent.
+	require.Empty(t, read())
+
+	testdata, err := filepath.Abs("testdata/hdf5_official")
+	require.NoError(t, err)
+	data := read(WithVirtualPrefix(testdata))
+	require.Equal(t, repeat(10, 16), data[:16])
+	require.Equal(t, repeat(32, 16), data[8*16:])
+
+	// The same search through the environment variable.
+	t.Setenv("HDF5_VDS_PREFIX", testdata)
+	require.Equal(t, data, read())
+}
+
+func copyTestdata(t *testing.T, name, dir string) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata/hdf5_official", name))
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, name), data, 0o600))
+}
+
+func repeat(v float64, n int) []float64 {
+	s := make([]float64, n)
+	for i := range s {
+		s[i] = v
+	}
+	return s
+}
@@ -87,13 +87,20 @@ const (
 	// Reference datatypes - point to objects or dataset regions.
 
 	// ObjectReference represents reference to an object (group/dataset).
-	// Value type: ObjectRef (uint64 - 8-byte object address).
+	// Value type: ObjectRef (8-byte object address, see FileWriter.NewObjectRef).
 	ObjectReference Datatype = 300
 
 	// RegionReference represents reference to a dataset region.
-	// Value type: RegionRef ([12]byte - 8-byte object addr + 4-byte region info).
+	// Value type: RegionRef (12-byte global heap ID, see FileWriter.NewRegionRef).
 	RegionReference Datatype = 301
 
+	// RevisedReference represents the revised reference type of HDF5 1.12+
+	// (H5T_STD_REF): a reference to an object, a dataset region or an
+	// attribute, possibly in another file.
+	// Value type: Ref (see FileWriter.CreateObjectRef, CreateRegionRef and
+	// CreateAttributeRef).
+	RevisedReference Datatype = 302
+
 	// Opaque datatype - uninterpreted byte sequences with descriptive tag.
 	// Use with WithOpaqueTag option to specify tag and size.
 
@@ -142,6 +149,10 @@ const (
 // Use with WithMaxDims option to allow dimension to grow indefinitely.
 const Unlimited uint64 = 0xFFFFFFFFFFFFFFFF
 
+// undefinedAddress is the HDF5 undefined address (HADDR_UNDEF), stored for
+// storage that has not been allocated yet.
+const undefinedAddress uint64 = 0xFFFFFFFFFFFFFFFF
+
 // datatypeInfo contains metadata about a datatype.
 type datatypeInfo struct {
 	class         core.DatatypeClass
@@ -458,6 +469,27 @@ func (h *vlenTypeHandler) EncodeDatatypeMessage(_ *datatypeInfo) ([]byte, error)
 	return core.EncodeDatatypeMessage(msg)
 }
 
+// parsedTypeHandler handles a datatype read from a file, which is written
+// unchanged. Data of such datasets is written with WriteRaw.
+type parsedTypeHandler struct {
+	msg *core.DatatypeMessage
+}
+
+func (h *parsedTypeHandler) GetInfo(_ *datasetConfig) (*datatypeInfo, error) {
+	if h.msg.Size == 0 {
+		return nil, fmt.Errorf("datatype size cannot be 0")
+	}
+	return &datatypeInfo{
+		class:         h.msg.Class,
+		size:          h.msg.Size,
+		classBitField: h.msg.ClassBitField,
+	}, nil
+}
+
+func (h *parsedTypeHandler) EncodeDatatypeMessage(_ *datatypeInfo) ([]byte, error) {
+	return core.EncodeParsedDatatypeMessage(h.msg), nil
+}
+
 // datatypeRegistry is the global registry mapping Datatype constants to their handlers.
 // This follows the Go stdlib pattern (encoding/json, database/sql, net/http).
 var datatypeRegistry map[Datatype]datatypeHandler
@@ -465,11 +497,11 @@ var datatypeRegistry map[Datatype]datatypeHandler
 // init initializes the datatype registry with all supported types.
 func init() {
 	datatypeRegistry = map[Datatype]datatypeHandler{
-		// Basic integers (fixed-point)
-		Int8:   &basicTypeHandler{core.DatatypeFixed, 1, 0x00},
-		Int16:  &basicTypeHandler{core.DatatypeFixed, 2, 0x00},
-		Int32:  &basicTypeHandler{core.DatatypeFixed, 4, 0x00},
-		Int64:  &basicTypeHandler{core.DatatypeFixed, 8, 0x00},
+		// Basic integers (fixed-point); bit 3 of the class bit field marks signed types
+		Int8:   &basicTypeHandler{core.DatatypeFixed, 1, 0x08},
+		Int16:  &basicTypeHandler{core.DatatypeFixed, 2, 0x08},
+		Int32:  &basicTypeHandler{core.DatatypeFixed, 4, 0x08},
+		Int64:  &basicTypeHandler{core.DatatypeFixed, 8, 0x08},
 		Uint8:  &basicTypeHandler{core.DatatypeFixed, 1, 0x00},
 		Uint16: &basicTypeHandler{core.DatatypeFixed, 2, 0x00},
 		Uint32: &basicTypeHandler{core.DatatypeFixed, 4, 0x00},
@@ -505,8 +537,9 @@ func init() {
 		EnumUint64: &enumTypeHandler{Uint64},
 
 		// References
-		ObjectReference: &referenceTypeHandler{8, 0x00},
-		RegionReference: &referenceTypeHandler{12, 0x01},
+		ObjectReference:  &referenceTypeHandler{8, 0x00},
+		RegionReference:  &referenceTypeHandler{12, 0x01},
+		RevisedReference: &referenceTypeHandler{core.RefSize(8), core.RefDatatypeClassBitField()},
 
 		// Opaque
 		Opaque: &opaqueTypeHandler{},
@@ -538,6 +571,10 @@ type GroupMetadata struct {
 	heapAddr   uint64 // Local heap address (stores link names)
 	stNodeAddr uint64 // Symbol table node address (stores entries)
 	btreeAddr  uint64 // B-tree address (indexes symbol table)
+
+	// dense collects the links of a dense group until finishDenseGroup
+	// writes it; nil for symbol table groups.
+	dense *writer.DenseGroupWriter
 }
 
 // FileWriter represents an HDF5 file opened for writing.
@@ -559,9 +596,18 @@ type FileWriter struct {
 	// Example: "/mygroup" → {heapAddr, stNodeAddr, btreeAddr}
 	groups map[string]*GroupMetadata
 
+	// Storage of files created with CreateInMemory or opened with
+	// OpenInMemory (nil for other files)
+	memory *MemoryStorage
+
 	// Global heap writer for variable-length data (vlen strings, ragged arrays)
 	globalHeapWriter *globalHeapWriter
 
+	// File space settings read from the superblock extension, and the blocks
+	// of the free-space managers they reference (freed when rewritten)
+	fileSpaceInfo  *core.FileSpaceInfoMessage
+	staleFreeSpace []structures.FreeSpaceSection
+
 	// Rebalancing configurations (Phase 3)
 	// These are set via functional options: WithLazyRebalancing(), WithIncrementalRebalancing(), WithSmartRebalancing()
 	lazyRebalancingConfig        *structures.LazyRebalancingConfig
@@ -576,6 +622,10 @@ const (
 	// This format doesn't have checksums but works with all HDF5 tools.
 	SuperblockV0 = core.Version0
 
+	// SuperblockV1 (legacy format) - Version 0 plus the indexed storage K, which
+	// sets the fan-out of chunk B-trees. Use it with WithIndexedStorageK.
+	SuperblockV1 = core.Version1
+
 	// SuperblockV2 (modern format) - Default. Includes checksums for data integrity.
 	// This is the recommended format for new files. Supported by HDF5 1.10+.
 	SuperblockV2 = core.Version2
@@ -589,15 +639,36 @@ type WriteOption func(*FileWriteConfig)
 
 // FileWriteConfig holds configuration for file creation.
 type FileWriteConfig struct {
-	SuperblockVersion uint8                  // HDF5 superblock version (0, 2, or 3)
+	SuperblockVersion uint8                  // HDF5 superblock version (0, 1, 2, or 3)
 	BTreeRebalancing  bool                   // Enable B-tree rebalancing after deletions (default: true)
 	RootAttributes    map[string]interface{} // Attributes to add to root group during creation
+	SymbolInternalK   uint16                 // Group B-tree internal node K (0 = default)
+	SymbolLeafK       uint16                 // Group leaf node K (0 = default)
+	IndexedStorageK   uint16                 // Chunk B-tree K (0 = default)
+	PersistFreeSpace  bool                   // Keep free space in the file across sessions
+	MaxCompactAttrs   int                    // Attributes per object before dense storage (see WithMaxCompactAttributes)
+	BackingStore      string                 // File an in-memory file is saved to on Close (see WithBackingStore)
+
+	driver Driver // Driver the file is stored with (see WithWriteDriver)
+
+	persistFreeSpaceSet bool // PersistFreeSpace was set by WithFreeSpacePersistence
+	maxCompactAttrsSet  bool // MaxCompactAttrs was set by WithMaxCompactAttributes
 }
 
+// defaultWriterSymbolLeafK is the group leaf node K this writer uses unless
+// WithSymbolTableK sets another one. Groups are written as a single symbol
+// table node, so it allows 2K = 32 links per group instead of HDF5's 8.
+const defaultWriterSymbolLeafK = 16
+
+// maxBTreeK is the largest K whose 2K entries fit the 16-bit entry counts of
+// B-tree and symbol table nodes.
+const maxBTreeK = 32767
+
 // WithSuperblockVersion sets the HDF5 superblock version.
 //
 // Available versions:
 //   - SuperblockV0: Legacy format, maximum compatibility with older tools (h5dump, etc.)
+//   - SuperblockV1: Legacy format that also stores the indexed storage K
 //   - SuperblockV2: Modern format with checksums (default)
 //   - SuperblockV3: Latest format (not yet implemented for writing)
 //
@@ -613,6 +684,74 @@ func WithSuperblockVersion(version uint8) WriteOption {
 	}
 }
 
+// WithSymbolTableK sets the K values of group B-trees, like H5Pset_sym_k.
+//
+// Parameters:
+//   - internalK: Group B-tree nodes hold up to 2*internalK children (HDF5 default: 16)
+//   - leafK: Symbol table nodes hold up to 2*leafK links (default: 16, so 32 links per group)
+//
+// Only superblock versions 0 and 1 store these values.
+//
+// Example:
+//
+//	fw, err := hdf5.CreateForWrite("file.h5", hdf5.CreateTruncate,
+//	    hdf5.WithSuperblockVersion(hdf5.SuperblockV0),
+//	    hdf5.WithSymbolTableK(16, 64)) // Up to 128 links per group
+func WithSymbolTableK(internalK, leafK uint16) WriteOption {
+	return func(cfg *FileWriteConfig) {
+		cfg.SymbolInternalK = internalK
+		cfg.SymbolLeafK = leafK
+	}
+}
+
+// WithIndexedStorageK sets the K value of chunk B-trees, like H5Pset_istore_k.
+// Chunk B-tree nodes hold up to 2K chunks (HDF5 default K: 32).
+//
+// Only superblock version 1 stores this value.
+//
+// Example:
+//
+//	fw, err := hdf5.CreateForWrite("file.h5", hdf5.CreateTruncate,
+//	    hdf5.WithSuperblockVersion(hdf5.SuperblockV1),
+//	    hdf5.WithIndexedStorageK(64))
+func WithIndexedStorageK(k uint16) WriteOption {
+	return func(cfg *FileWriteConfig) {
+		cfg.IndexedStorageK = k
+	}
+}
+
+// btreeK returns the B-tree K values of a new file (group leaf node K, group
+// internal node K and indexed storage K), checking that its superblock
+// version can store the configured ones.
+func (cfg *FileWriteConfig) btreeK() (leafK, internalK, istoreK uint16, err error) {
+	leafK, internalK, istoreK = defaultWriterSymbolLeafK, core.DefaultSymbolInternalK, core.DefaultIndexedStorageK
+
+	if cfg.SymbolLeafK != 0 || cfg.SymbolInternalK != 0 {
+		if cfg.SuperblockVersion > core.Version1 {
+			return 0, 0, 0, fmt.Errorf("symbol table K values require superblock version 0 or 1, got %d",
+				cfg.SuperblockVersion)
+		}
+		leafK, internalK = cfg.SymbolLeafK, cfg.SymbolInternalK
+	}
+	if cfg.IndexedStorageK != 0 {
+		if cfg.SuperblockVersion != core.Version1 && cfg.IndexedStorageK != core.DefaultIndexedStorageK {
+			return 0, 0, 0, fmt.Errorf("indexed storage K requires superblock version 1, got %d",
+				cfg.SuperblockVersion)
+		}
+		istoreK = cfg.IndexedStorageK
+	}
+
+	for _, k := range []struct {
+		name  string
+		value uint16
+	}{{"symbol table leaf", leafK}, {"symbol table internal", internalK}, {"indexed storage", istoreK}} {
+		if k.value == 0 || k.value > maxBTreeK {
+			return 0, 0, 0, fmt.Errorf("%s K must be between 1 and %d, got %d", k.name, maxBTreeK, k.value)
+		}
+	}
+	return leafK, internalK, istoreK, nil
+}
+
 // WithBTreeRebalancing enables or disables B-tree rebalancing after deletions.
 //
 // When enabled (default):
@@ -683,6 +822,34 @@ func WithRootAttribute(name string, value interface{}) WriteOption {
 	}
 }
 
+// WithMaxCompactAttributes sets how many attributes an object stores in its
+// object header before they move to dense storage (fractal heap and B-tree),
+// like the max_compact value of H5Pset_attr_phase_change. 0 stores all
+// attributes densely.
+//
+// Default: MaxCompactAttributes (8).
+//
+// Example:
+//
+//	// Keep up to 32 attributes in object headers
+//	fw, err := hdf5.CreateForWrite("data.h5", hdf5.CreateTruncate,
+//	    hdf5.WithMaxCompactAttributes(32))
+func WithMaxCompactAttributes(n int) WriteOption {
+	return func(cfg *FileWriteConfig) {
+		cfg.MaxCompactAttrs = n
+		cfg.maxCompactAttrsSet = true
+	}
+}
+
+// maxCompactAttributes returns the number of attributes an object stores
+// compactly.
+func (cfg *FileWriteConfig) maxCompactAttributes() int {
+	if cfg == nil || !cfg.maxCompactAttrsSet {
+		return MaxCompactAttributes
+	}
+	return max(cfg.MaxCompactAttrs, 0)
+}
+
 // CreateForWrite creates a new HDF5 file for writing.
 // Unlike Create(), this keeps the file open in write mode.
 //
@@ -708,6 +875,52 @@ func WithRootAttribute(name string, value interface{}) WriteOption {
 //	fw, err := hdf5.CreateForWrite("data.h5", hdf5.CreateTruncate,
 //	    hdf5.WithSuperblockVersion(core.Version0))
 func CreateForWrite(filename string, mode CreateMode, opts ...interface{}) (*FileWriter, error) {
+	return createForWrite(filename, func(cfg *FileWriteConfig, sb *core.Superblock) (*writer.FileWriter, error) {
+		if err := cfg.checkNoBackingStore(); err != nil {
+			return nil, err
+		}
+		if cfg.driver != nil {
+			return newDriverWriter(cfg.driver, filename, mode, sb)
+		}
+		return initializeFileWriter(filename, mode, core.SuperblockSize(sb.Version))
+	}, opts)
+}
+
+// CreateWithStorage creates a new HDF5 file in storage, like CreateForWrite.
+// Any previous contents of storage are discarded. The FileWriter takes over
+// storage: it is closed when the FileWriter is closed or creation fails.
+//
+// Example:
+//
+//	// Create a file in memory
+//	storage := hdf5.NewMemoryStorage(nil)
+//	fw, err := hdf5.CreateWithStorage(storage)
+//	// ... write datasets, then fw.Close() ...
+//	image := storage.Bytes()
+func CreateWithStorage(storage Storage, opts ...interface{}) (*FileWriter, error) {
+	fw, err := createForWrite("", func(cfg *FileWriteConfig, sb *core.Superblock) (*writer.FileWriter, error) {
+		if err := cfg.checkNoBackingStore(); err != nil {
+			return nil, err
+		}
+		if err := cfg.checkNoDriver(); err != nil {
+			return nil, err
+		}
+		if err := storage.Truncate(0); err != nil {
+			return nil, fmt.Errorf("failed to truncate storage: %w", err)
+		}
+		return writer.NewStorageWriter(storage, core.SuperblockSize(sb.Version))
+	}, opts)
+	if err != nil {
+		_ = storage.Close()
+		return nil, err
+	}
+	return fw, nil
+}
+
+// createForWrite creates a new HDF5 file named filename (empty for custom
+// storage) with the low-level writer returned by newWriter for the
+// superblock sb.
+func createForWrite(filename string, newWriter func(cfg *FileWriteConfig, sb *core.Superblock) (*writer.FileWriter, error), opts []interface{}) (*FileWriter, error) {
 	// Apply default configuration
 	cfg := &FileWriteConfig{
 		SuperblockVersion: core.Version2, // Modern format by default
@@ -731,14 +944,34 @@ func CreateForWrite(filename string, mode CreateMode, opts ...interface{}) (*Fil
 		}
 	}
 
-	// Calculate superblock size based on version
-	superblockSize := uint64(48) // v2/v3
-	if cfg.SuperblockVersion == core.Version0 {
-		superblockSize = 96 // v0 is larger
+	if cfg.SuperblockVersion > core.Version3 {
+		return nil, fmt.Errorf("unsupported superblock version: %d", cfg.SuperblockVersion)
+	}
+	leafK, internalK, istoreK, err := cfg.btreeK()
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.checkFreeSpacePersistence(cfg.SuperblockVersion); err != nil {
+		return nil, err
 	}
 
-	// Map CreateMode to writer.CreateMode and create basic writer
-	fw, err := initializeFileWriter(filename, mode, superblockSize)
+	// Step 1: Superblock with configured version; root group addresses are
+	// filled in once the root group exists
+	sb := &core.Superblock{
+		Version:         cfg.SuperblockVersion, // Use configured version
+		OffsetSize:      8,
+		LengthSize:      8,
+		BaseAddress:     0,
+		Endianness:      binary.LittleEndian,
+		SuperExtension:  0,
+		DriverInfo:      0,
+		SymbolLeafK:     leafK,
+		SymbolInternalK: internalK,
+		IndexedStorageK: istoreK,
+	}
+
+	// Create basic writer
+	fw, err := newWriter(cfg, sb)
 	if err != nil {
 		return nil, err
 	}
@@ -751,31 +984,22 @@ func CreateForWrite(filename string, mode CreateMode, opts ...interface{}) (*Fil
 		}
 	}()
 
-	// Create root group with Symbol Table structure
-	rootInfo, err := createRootGroupStructure(fw, cfg.SuperblockVersion, cfg.RootAttributes)
+	// Step 2: Create root group with Symbol Table structure
+	rootInfo, err := createRootGroupStructure(fw, sb, cfg.RootAttributes, cfg.maxCompactAttributes())
 	if err != nil {
 		return nil, err
 	}
 
-	// Step 3: Create Superblock with configured version
-	sb := &core.Superblock{
-		Version:        cfg.SuperblockVersion, // Use configured version
-		OffsetSize:     8,
-		LengthSize:     8,
-		BaseAddress:    0,
-		RootGroup:      rootInfo.groupAddr,
-		Endianness:     binary.LittleEndian,
-		SuperExtension: 0,
-		DriverInfo:     0,
-		// V0-specific cached addresses (required for h5dump compatibility)
-		RootBTreeAddr: rootInfo.btreeAddr,
-		RootHeapAddr:  rootInfo.heapAddr,
-	}
+	// Step 3: Complete the superblock
+	sb.RootGroup = rootInfo.groupAddr
+	// V0/V1-specific cached addresses (required for h5dump compatibility)
+	sb.RootBTreeAddr = rootInfo.btreeAddr
+	sb.RootHeapAddr = rootInfo.heapAddr
 
 	// Calculate end-of-file address
 	var eofAddress uint64
-	if cfg.SuperblockVersion == core.Version0 {
-		// V0 uses fixed addresses - calculate from actual layout
+	if cfg.SuperblockVersion <= core.Version1 {
+		// V0/V1 use fixed addresses - calculate from actual layout
 		// EOF = last structure address + its size
 		eofAddress = rootInfo.heapAddr + rootInfo.heapSize
 	} else {
@@ -889,8 +1113,6 @@ func calculateTotalElements(dims []uint64) uint64 {
 //   - No compression
 //   - Dataset must be in root group (no nested groups yet)
 //   - Resizable datasets require chunked layout (use WithMaxDims with WithChunkDims)
-//
-//nolint:gocyclo,cyclop,gocognit,funlen // Complex by nature: dataset creation handles multiple layout types and options
 func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64, opts ...DatasetOption) (*DatasetWriter, error) {
 	// Validate inputs
 	if err := validateDatasetName(name); err != nil {
@@ -906,6 +1128,18 @@ func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64,
 		opt(config)
 	}
 
+	handler, ok := datatypeRegistry[dtype]
+	if !ok {
+		return nil, fmt.Errorf("invalid datatype: unsupported datatype: %d", dtype)
+	}
+
+	return fw.createDataset(name, handler, dims, config)
+}
+
+// createDataset creates a dataset of the datatype described by handler.
+//
+//nolint:gocyclo,cyclop,funlen // Complex by nature: dataset creation handles multiple layout types and options
+func (fw *FileWriter) createDataset(name string, handler datatypeHandler, dims []uint64, config *datasetConfig) (*DatasetWriter, error) {
 	// Validate maxDims if specified
 	if len(config.maxDims) > 0 {
 		if len(config.maxDims) != len(dims) {
@@ -929,11 +1163,11 @@ func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64,
 
 	// Check if chunked layout requested
 	if len(config.chunkDims) > 0 {
-		return fw.createChunkedDataset(name, dtype, dims, config)
+		return fw.createChunkedDataset(name, handler, dims, config)
 	}
 
 	// Get datatype info
-	dtInfo, err := getDatatypeInfo(dtype, config)
+	dtInfo, err := handler.GetInfo(config)
 	if err != nil {
 		return nil, fmt.Errorf("invalid datatype: %w", err)
 	}
@@ -942,14 +1176,39 @@ func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64,
 	totalElements := calculateTotalElements(dims)
 	dataSize := totalElements * uint64(dtInfo.size)
 
+	// For DatasetWriter, we need a simple DatatypeMessage for Write() operations
+	// Advanced types will use the base type for data encoding
+	var dsMsgForWriter *core.DatatypeMessage
+	if dtInfo.baseType != nil {
+		// For array/enum, use base type for data writing
+		dsMsgForWriter = &core.DatatypeMessage{
+			Class:   dtInfo.baseType.class,
+			Version: 1,
+			Size:    dtInfo.baseType.size,
+		}
+	} else {
+		// For simple types, use the datatype itself
+		dsMsgForWriter = &core.DatatypeMessage{
+			Class:         dtInfo.class,
+			Version:       1,
+			Size:          dtInfo.size,
+			ClassBitField: dtInfo.classBitField,
+		}
+	}
+
+	// Build fill value message (nil unless fill options were given)
+	fillMsg, err := newFillValueMessage(config, dsMsgForWriter, uint64(dtInfo.size), false)
+	if err != nil {
+		return nil, err
+	}
+
 	// Allocate space for dataset data
-	dataAddress, err := fw.writer.Allocate(dataSize)
+	dataAddress, err := fw.allocateContiguous(dataSize, uint64(dtInfo.size), fillMsg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to allocate space for data: %w", err)
+		return nil, err
 	}
 
 	// Encode datatype message using handler (simplified from complex switch)
-	handler := datatypeRegistry[dtype]
 	datatypeData, err := handler.EncodeDatatypeMessage(dtInfo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode datatype: %w", err)
@@ -984,6 +1243,11 @@ func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64,
 		},
 	}
 
+	// Add fill value message if present
+	if err := appendFillValueMessage(ohw, fillMsg); err != nil {
+		return nil, err
+	}
+
 	// Allocate space for object header
 	// We need to calculate size first
 	headerSize, err := calculateObjectHeaderSize(ohw)
@@ -991,7 +1255,7 @@ func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64,
 		return nil, fmt.Errorf("failed to calculate header size: %w", err)
 	}
 
-	headerAddress, err := fw.writer.Allocate(headerSize)
+	headerAddress, err := fw.writer.AllocateObjectHeader(headerSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate space for object header: %w", err)
 	}
@@ -1014,40 +1278,25 @@ func (fw *FileWriter) CreateDataset(name string, dtype Datatype, dims []uint64,
 	}
 
 	// Create DatasetWriter
-	// For DatasetWriter, we need a simple DatatypeMessage for Write() operations
-	// Advanced types will use the base type for data encoding
-	var dsMsgForWriter *core.DatatypeMessage
-	if dtInfo.baseType != nil {
-		// For array/enum, use base type for data writing
-		dsMsgForWriter = &core.DatatypeMessage{
-			Class:   dtInfo.baseType.class,
-			Version: 1,
-			Size:    dtInfo.baseType.size,
-		}
-	} else {
-		// For simple types, use the datatype itself
-		dsMsgForWriter = &core.DatatypeMessage{
-			Class:   dtInfo.class,
-			Version: 1,
-			Size:    dtInfo.size,
-		}
-	}
-
 	dsw := &DatasetWriter{
-		fileWriter:  fw,
-		name:        name,
-		address:     headerAddress,
-		dataAddress: dataAddress,
-		dataSize:    dataSize,
-		dtype:       dsMsgForWriter,
-		dims:        dims,
+		fileWriter:       fw,
+		name:             name,
+		address:          headerAddress,
+		dataAddress:      dataAddress,
+		dataSize:         dataSize,
+		dtype:            dsMsgForWriter,
+		elemSize:         uint64(dtInfo.size),
+		dims:             dims,
+		layoutClass:      core.LayoutContiguous,
+		layoutDataOffset: contiguousLayoutAddressOffset(headerAddress+objectHeaderPrefixSize(ohw), datatypeData, dataspaceData, dataAddress),
 	}
 
 	return dsw, nil
 }
 
 // CreateCompoundDataset creates a dataset with a compound (struct-like) datatype.
-// This is an advanced method for creating datasets with complex structured data.
+// This is an advanced method for creating datasets with complex structured data;
+// CreateDatasetFromStruct derives the datatype from a Go struct instead.
 //
 // Parameters:
 //   - name: Dataset path (e.g., "/data" or "/group/dataset")
@@ -1113,10 +1362,16 @@ func (fw *FileWriter) CreateCompoundDataset(name string, compoundType *core.Data
 	totalElements := calculateTotalElements(dims)
 	dataSize := totalElements * uint64(compoundType.Size)
 
+	// Build fill value message (compound fill values are given as raw bytes)
+	fillMsg, err := newFillValueMessage(config, compoundType, uint64(compoundType.Size), false)
+	if err != nil {
+		return nil, err
+	}
+
 	// Allocate space for dataset data
-	dataAddress, err := fw.writer.Allocate(dataSize)
+	dataAddress, err := fw.allocateContiguous(dataSize, uint64(compoundType.Size), fillMsg)
 	if err != nil {
-		return nil, fmt.Errorf("failed to allocate space for data: %w", err)
+		return nil, err
 	}
 
 	// Encode datatype message (compound type is already encoded in DatatypeMessage)
@@ -1155,6 +1410,11 @@ func (fw *FileWriter) CreateCompoundDataset(name string, compoundType *core.Data
 		},
 	}
 
+	// Add fill value message if present
+	if err := appendFillValueMessage(ohw, fillMsg); err != nil {
+		return nil, err
+	}
+
 	// Calculate object header size for pre-allocation
 	headerSize, err := calculateObjectHeaderSize(ohw)
 	if err != nil {
@@ -1162,7 +1422,7 @@ func (fw *FileWriter) CreateCompoundDataset(name string, compoundType *core.Data
 	}
 
 	// Allocate space for object header
-	headerAddress, err := fw.writer.Allocate(headerSize)
+	headerAddress, err := fw.writer.AllocateObjectHeader(headerSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate space for object header: %w", err)
 	}
@@ -1185,14 +1445,17 @@ func (fw *FileWriter) CreateCompoundDataset(name string, compoundType *core.Data
 
 	// Create DatasetWriter (for WriteRaw)
 	dsw := &DatasetWriter{
-		fileWriter:  fw,
-		name:        name,
-		address:     headerAddress,
-		dataAddress: dataAddress,
-		dataSize:    dataSize,
-		dtype:       compoundType,
-		dims:        dims,
-		isChunked:   false,
+		fileWriter:       fw,
+		name:             name,
+		address:          headerAddress,
+		dataAddress:      dataAddress,
+		dataSize:         dataSize,
+		dtype:            compoundType,
+		elemSize:         uint64(compoundType.Size),
+		dims:             dims,
+		layoutClass:      core.LayoutContiguous,
+		isChunked:        false,
+		layoutDataOffset: contiguousLayoutAddressOffset(headerAddress+objectHeaderPrefixSize(ohw), datatypeData, dataspaceData, dataAddress),
 	}
 
 	return dsw, nil
@@ -1205,22 +1468,20 @@ func calculateObjectHeaderSize(ohw *core.ObjectHeaderWriter) (uint64, error) {
 		return 0, fmt.Errorf("only object header version 2 supported")
 	}
 
-	// Calculate message data size
-	var messageDataSize uint64
+	// Signature (4) + Version (1) + Flags (1) + Chunk Size (1, 2 or 4) + Messages
+	return ohw.Size(), nil
+}
+
+// objectHeaderPrefixSize returns the size of the fields of a v2 object header
+// written by ohw that precede its first message. The chunk size field is 1, 2
+// or 4 bytes wide depending on the size of the messages.
+func objectHeaderPrefixSize(ohw *core.ObjectHeaderWriter) uint64 {
+	size := ohw.Size()
 	for _, msg := range ohw.Messages {
 		// Each message: Type (1) + Size (2) + Flags (1) + Data (variable)
-		messageDataSize += 1 + 2 + 1 + uint64(len(msg.Data))
+		size -= 1 + 2 + 1 + uint64(len(msg.Data))
 	}
-
-	// Validate chunk size fits in 1 byte (MVP limitation)
-	if messageDataSize > 255 {
-		return 0, fmt.Errorf("message data size %d exceeds 255 bytes (MVP limitation)", messageDataSize)
-	}
-
-	// Header: Signature (4) + Version (1) + Flags (1) + Chunk Size (1) + Messages
-	headerSize := 4 + 1 + 1 + 1 + messageDataSize
-
-	return headerSize, nil
+	return size
 }
 
 // DatasetWriter provides write access to a dataset.
@@ -1231,20 +1492,41 @@ type DatasetWriter struct {
 	dataAddress      uint64 // Data storage address (contiguous) or B-tree address (chunked)
 	dataSize         uint64 // Total data size in bytes
 	dtype            *core.DatatypeMessage
+	elemSize         uint64 // Element size in bytes (whole array for array datatypes)
 	dims             []uint64
 	maxDims          []uint64                 // Maximum dimensions (for resize support)
+	layoutClass      core.DataLayoutClass     // Storage layout of the dataset
 	isChunked        bool                     // True if using chunked layout
 	chunkCoordinator *writer.ChunkCoordinator // For chunked datasets
 	chunkDims        []uint64                 // Chunk dimensions
 	pipeline         *writer.FilterPipeline   // Filter pipeline for chunked datasets
+	chunkErr         error                    // Why a chunked dataset opened from the file cannot be written
+
+	// chunks holds the chunks written so far by chunkCoordsToKey of their
+	// scaled coordinate; chunkIndex is the chunk B-tree rooted at dataAddress.
+	chunks     map[string]*writtenChunk
+	chunkIndex *structures.ChunkBTree
+
+	// fillPattern is the fill value element for chunk areas never written
+	// (nil for zeros).
+	fillPattern []byte
 
 	// layoutBTreeOffset is the file offset where the B-tree address is stored
 	// in the layout message. Used to update the address after writing chunks.
 	layoutBTreeOffset uint64
 
+	// layoutDataOffset is the file offset where the contiguous data address is
+	// stored in the layout message while the storage is not yet allocated
+	// (AllocTimeLate); 0 once allocated.
+	layoutDataOffset uint64
+
 	// For RMW scenarios (files opened with OpenForWrite)
 	objectHeader  *core.ObjectHeader         // Full object header (for attribute operations)
 	denseAttrInfo *core.AttributeInfoMessage // Dense attribute storage info (nil if no dense storage)
+
+	// structType is the Go struct layout of datasets created with
+	// CreateDatasetFromStruct (nil otherwise).
+	structType *structType
 }
 
 // Write writes data to the dataset.
@@ -1278,26 +1560,7 @@ func (dw *DatasetWriter) Write(data interface{}) error {
 	}
 
 	// Convert data to bytes based on datatype
-	var buf []byte
-	var err error
-
-	switch dw.dtype.Class {
-	case core.DatatypeFixed:
-		buf, err = encodeFixedPointData(data, dw.dtype.Size, dw.dataSize)
-	case core.DatatypeFloat:
-		buf, err = encodeFloatData(data, dw.dtype.Size, dw.dataSize)
-	case core.DatatypeString:
-		buf, err = encodeStringData(data, dw.dtype.Size, dw.dataSize)
-	case core.DatatypeReference:
-		// References are fixed-size types (8 or 12 bytes)
-		buf, err = encodeFixedPointData(data, dw.dtype.Size, dw.dataSize)
-	case core.DatatypeOpaque:
-		// Opaque data is raw bytes
-		buf, err = encodeOpaqueData(data, dw.dataSize)
-	default:
-		return fmt.Errorf("unsupported datatype class for writing: %d", dw.dtype.Class)
-	}
-
+	buf, err := dw.encode(data, dw.dataSize)
 	if err != nil {
 		return fmt.Errorf("failed to encode data: %w", err)
 	}
@@ -1313,6 +1576,12 @@ func (dw *DatasetWriter) Write(data interface{}) error {
 	}
 
 	// Write data to file (contiguous layout)
+	if err := dw.requireContiguous(); err != nil {
+		return err
+	}
+	if err := dw.allocateLateStorage(); err != nil {
+		return err
+	}
 	if err := dw.fileWriter.writer.WriteAtAddress(buf, dw.dataAddress); err != nil {
 		return fmt.Errorf("failed to write data: %w", err)
 	}
@@ -1347,6 +1616,12 @@ func (dw *DatasetWriter) WriteRaw(data []byte) error {
 	}
 
 	// Write raw data to file (contiguous layout)
+	if err := dw.requireContiguous(); err != nil {
+		return err
+	}
+	if err := dw.allocateLateStorage(); err != nil {
+		return err
+	}
 	if err := dw.fileWriter.writer.WriteAtAddress(data, dw.dataAddress); err != nil {
 		return fmt.Errorf("failed to write raw data: %w", err)
 	}
@@ -1354,6 +1629,19 @@ func (dw *DatasetWriter) WriteRaw(data []byte) error {
 	return nil
 }
 
+// requireContiguous returns an error unless the dataset's data is stored
+// contiguously, the only layout besides chunked that writes support.
+func (dw *DatasetWriter) requireContiguous() error {
+	switch {
+	case dw.layoutClass == core.LayoutContiguous:
+		return nil
+	case dw.chunkErr != nil:
+		return dw.chunkErr
+	default:
+		return fmt.Errorf("writes are not supported for %s layout", LayoutClass(dw.layoutClass))
+	}
+}
+
 // writeVLen handles writing variable-length data (strings, ragged arrays).
 // Data is written to global heap, and heap IDs are stored in the dataset.
 //
@@ -1521,6 +1809,12 @@ func (dw *DatasetWriter) writeVLen(data interface{}) error {
 	}
 
 	// Contiguous layout - write directly
+	if err := dw.requireContiguous(); err != nil {
+		return err
+	}
+	if err := dw.allocateLateStorage(); err != nil {
+		return err
+	}
 	if err := dw.fileWriter.writer.WriteAtAddress(heapIDData, dw.dataAddress); err != nil {
 		return fmt.Errorf("write heap IDs: %w", err)
 	}
@@ -1546,6 +1840,9 @@ func (dw *DatasetWriter) writeVLen(data interface{}) error {
 //nolint:gocyclo,cyclop // Complex by nature: resize involves validation, header update, and state management
 func (dw *DatasetWriter) Resize(newDims []uint64) error {
 	// 1. Validate input.
+	if dw.chunkErr != nil {
+		return dw.chunkErr
+	}
 	if !dw.isChunked {
 		return fmt.Errorf("resize requires chunked layout")
 	}
@@ -1613,12 +1910,25 @@ func (dw *DatasetWriter) Resize(newDims []uint64) error {
 	dw.objectHeader.Messages[dataspaceIdx].Data = newDataspaceData
 
 	// 8. Write updated object header back to file.
+	if err := reserveObjectHeader(dw.fileWriter, dw.address, dw.objectHeader); err != nil {
+		return err
+	}
 	err = core.WriteObjectHeader(dw.fileWriter.writer, dw.address,
 		dw.objectHeader, dw.fileWriter.file.sb)
 	if err != nil {
 		return fmt.Errorf("write object header: %w", err)
 	}
 
+	// The rewritten header may hold the layout message elsewhere.
+	oh, err := core.ReadObjectHeader(dw.fileWriter.writer, dw.address, dw.fileWriter.file.sb)
+	if err != nil {
+		return fmt.Errorf("read object header: %w", err)
+	}
+	dw.objectHeader = oh
+	if dw.layoutBTreeOffset, err = layoutAddressOffset(oh); err != nil {
+		return err
+	}
+
 	// 9. Update internal state.
 	dw.dims = newDims
 
@@ -1634,12 +1944,106 @@ func (dw *DatasetWriter) Resize(newDims []uint64) error {
 	}
 	dw.chunkCoordinator = newCoordinator
 
+	// 12. Drop chunks that lie entirely outside the new extent and release
+	// their space.
+	pruned := false
+	for key, wc := range dw.chunks {
+		for i, c := range wc.coord {
+			if c*dw.chunkDims[i] >= newDims[i] {
+				if err := dw.fileWriter.writer.Free(wc.address, wc.capacity); err != nil {
+					return fmt.Errorf("free chunk %v: %w", wc.coord, err)
+				}
+				delete(dw.chunks, key)
+				pruned = true
+				break
+			}
+		}
+	}
+	if pruned {
+		if err := dw.rebuildChunkIndex(); err != nil {
+			return fmt.Errorf("update chunk index: %w", err)
+		}
+	}
+
 	// Note: For extending datasets, new chunks will be allocated and initialized
 	// with zeros on first write to those regions. This is standard HDF5 behavior.
 
 	return nil
 }
 
+// encode encodes data for writing expectedSize bytes of the dataset.
+// Revised references are encoded by the file writer, which stores the parts
+// that do not fit in an element in the global heap.
+func (dw *DatasetWriter) encode(data interface{}, expectedSize uint64) ([]byte, error) {
+	if refs, ok := data.([]Ref); ok && dw.dtype.IsRevisedReference() {
+		return dw.fileWriter.encodeRefs(refs, expectedSize)
+	}
+	return encodeData(data, dw.dtype, expectedSize)
+}
+
+// encodeData encodes a slice of values of the given datatype to bytes.
+func encodeData(data interface{}, dtype *core.DatatypeMessage, expectedSize uint64) ([]byte, error) {
+	switch dtype.Class {
+	case core.DatatypeFixed:
+		return encodeFixedPointData(data, dtype.Size, expectedSize)
+	case core.DatatypeFloat:
+		return encodeFloatData(data, dtype.Size, expectedSize)
+	case core.DatatypeString:
+		return encodeStringData(data, dtype.Size, expectedSize)
+	case core.DatatypeReference:
+		return encodeReferenceData(data, dtype, expectedSize)
+	case core.DatatypeOpaque:
+		// Opaque data is raw bytes
+		return encodeOpaqueData(data, expectedSize)
+	default:
+		return nil, fmt.Errorf("unsupported datatype class for writing: %d", dtype.Class)
+	}
+}
+
+// encodeReferenceData encodes object references ([]ObjectRef, or addresses
+// as []uint64) and region references ([]RegionRef). Revised references are
+// encoded by DatasetWriter.encode, which has access to the global heap.
+func encodeReferenceData(data interface{}, dtype *core.DatatypeMessage, expectedSize uint64) ([]byte, error) {
+	if dtype.IsRevisedReference() {
+		return nil, fmt.Errorf("revised reference datasets require []Ref, got %T", data)
+	}
+	isRegion := dtype.ReferenceType() == core.ReferenceTypeRegion
+
+	switch v := data.(type) {
+	case []ObjectRef:
+		if isRegion {
+			return nil, fmt.Errorf("region reference datasets require []RegionRef, got %T", data)
+		}
+		addrs := make([]uint64, len(v))
+		for i, ref := range v {
+			addrs[i] = uint64(ref)
+		}
+		return encodeFixedPointData(addrs, dtype.Size, expectedSize)
+
+	case []RegionRef:
+		if !isRegion {
+			return nil, fmt.Errorf("object reference datasets require []ObjectRef or []uint64, got %T", data)
+		}
+		actualSize := uint64(len(v)) * uint64(dtype.Size)
+		if actualSize != expectedSize {
+			return nil, fmt.Errorf("data size mismatch: expected %d bytes, got %d bytes", expectedSize, actualSize)
+		}
+		buf := make([]byte, expectedSize)
+		for i, ref := range v {
+			off := i * int(dtype.Size)
+			binary.LittleEndian.PutUint64(buf[off:], ref.HeapAddress)
+			binary.LittleEndian.PutUint32(buf[off+8:], ref.ObjectIndex)
+		}
+		return buf, nil
+
+	default:
+		if isRegion {
+			return nil, fmt.Errorf("region reference datasets require []RegionRef, got %T", data)
+		}
+		return encodeFixedPointData(data, dtype.Size, expectedSize)
+	}
+}
+
 // encodeFixedPointData encodes integer data to bytes.
 func encodeFixedPointData(data interface{}, elemSize uint32, expectedSize uint64) ([]byte, error) {
 	// Validate data size matches expected size
@@ -1882,6 +2286,10 @@ type datasetConfig struct {
 	pipeline      *writer.FilterPipeline // Filter pipeline for chunked datasets
 	enableShuffle bool                   // Add shuffle filter before compression
 	maxDims       []uint64               // Maximum dimensions (for resizable datasets)
+	fillValue     interface{}            // Value of unwritten elements (nil = not set)
+	fillTime      FillTime               // When the fill value is written
+	allocTime     AllocTime              // When storage is allocated
+	packedStructs bool                   // Struct datasets without alignment padding
 }
 
 // WithStringSize sets the fixed string size for String datasets.
@@ -2081,6 +2489,28 @@ func WithFletcher32() DatasetOption {
 	}
 }
 
+// WithLZFCompression enables LZF compression (filter 32000, as written by
+// h5py and PyTables). This option is only valid for chunked datasets
+// (requires WithChunkDims).
+//
+// LZF compresses less than GZIP but is several times faster in both
+// directions. Readers need the LZF filter, which h5py always includes.
+//
+// Example:
+//
+//	ds, _ := fw.CreateDataset("/data", hdf5.Float32, []uint64{1000},
+//	    hdf5.WithChunkDims([]uint64{100}),
+//	    hdf5.WithShuffle(),
+//	    hdf5.WithLZFCompression())
+func WithLZFCompression() DatasetOption {
+	return func(cfg *datasetConfig) {
+		if cfg.pipeline == nil {
+			cfg.pipeline = writer.NewFilterPipeline()
+		}
+		cfg.pipeline.AddFilter(writer.NewLZFFilter())
+	}
+}
+
 // OpenMode specifies how to open an existing HDF5 file.
 type OpenMode int
 
@@ -2139,9 +2569,16 @@ func OpenForWrite(filename string, mode OpenMode, opts ...WriteOption) (*FileWri
 	for _, opt := range opts {
 		opt(cfg)
 	}
+	if err := cfg.checkNoBackingStore(); err != nil {
+		return nil, err
+	}
 
 	// Step 1: Open existing HDF5 file for reading (to load structure)
-	f, err := Open(filename)
+	var openOpts []OpenOption
+	if cfg.driver != nil {
+		openOpts = append(openOpts, WithDriver(cfg.driver))
+	}
+	f, err := Open(filename, openOpts...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
@@ -2154,13 +2591,77 @@ func OpenForWrite(filename string, mode OpenMode, opts ...WriteOption) (*FileWri
 		writerMode = writer.ModeReadOnly // Read-only mode
 	}
 
-	// Determine initial offset from superblock
-	superblockSize := uint64(48) // v2/v3
-	if f.sb.Version == core.Version0 {
-		superblockSize = 96 // v0
+	return openForWrite(f, filename, mode, cfg, func(initialOffset uint64) (*writer.FileWriter, error) {
+		if cfg.driver != nil {
+			return openDriverWriter(cfg.driver, filename, mode, initialOffset)
+		}
+		return writer.OpenFileWriter(filename, writerMode, initialOffset)
+	})
+}
+
+// OpenWithStorage opens the HDF5 file held by storage for modification,
+// like OpenForWrite. The FileWriter takes over storage: it is closed when
+// the FileWriter is closed or opening fails.
+//
+// Example:
+//
+//	// Add a dataset to a file image in memory
+//	storage := hdf5.NewMemoryStorage(image)
+//	fw, err := hdf5.OpenWithStorage(storage, hdf5.OpenReadWrite)
+func OpenWithStorage(storage Storage, mode OpenMode, opts ...WriteOption) (*FileWriter, error) {
+	cfg := &FileWriteConfig{
+		SuperblockVersion: core.Version2, // Will be overridden by file's actual version
+		BTreeRebalancing:  true,          // C library default behavior
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	err := cfg.checkNoBackingStore()
+	if err == nil {
+		err = cfg.checkNoDriver()
+	}
+	if err != nil {
+		_ = storage.Close()
+		return nil, err
+	}
+	return openWithStorage(storage, mode, cfg)
+}
+
+// openWithStorage opens the HDF5 file held by storage with the settings cfg.
+// It closes storage on failure.
+func openWithStorage(storage Storage, mode OpenMode, cfg *FileWriteConfig) (*FileWriter, error) {
+	size, err := storage.Size()
+	if err != nil {
+		_ = storage.Close()
+		return nil, fmt.Errorf("failed to get storage size: %w", err)
+	}
+	f, err := OpenReader(storage, size)
+	if err != nil {
+		_ = storage.Close()
+		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	fw, err := writer.OpenFileWriter(filename, writerMode, superblockSize)
+	return openForWrite(f, "", mode, cfg, func(initialOffset uint64) (*writer.FileWriter, error) {
+		fw, err := writer.NewStorageWriter(storage, initialOffset)
+		if err != nil {
+			_ = storage.Close()
+		}
+		return fw, err
+	})
+}
+
+// openForWrite creates the FileWriter for the file f, opened for reading,
+// with the low-level writer returned by newWriter. It closes f on failure.
+func openForWrite(f *File, filename string, mode OpenMode, cfg *FileWriteConfig, newWriter func(initialOffset uint64) (*writer.FileWriter, error)) (*FileWriter, error) {
+	// Superblocks v2/v3 store no K values. Symbol tables in such files come
+	// from this writer (HDF5 writes link messages there), so their nodes have
+	// its capacity rather than HDF5's default.
+	if f.sb.Version >= core.Version2 {
+		f.sb.SymbolLeafK = defaultWriterSymbolLeafK
+	}
+
+	// Determine initial offset from superblock
+	fw, err := newWriter(core.SuperblockSize(f.sb.Version))
 	if err != nil {
 		_ = f.Close()
 		return nil, fmt.Errorf("failed to create writer: %w", err)
@@ -2168,8 +2669,8 @@ func OpenForWrite(filename string, mode OpenMode, opts ...WriteOption) (*FileWri
 
 	// Step 3: Extract root group information from existing file
 	rootGroupAddr := f.sb.RootGroup
-	rootBTreeAddr := f.sb.RootBTreeAddr // v0 only
-	rootHeapAddr := f.sb.RootHeapAddr   // v0 only
+	rootBTreeAddr := f.sb.RootBTreeAddr // v0/v1 only
+	rootHeapAddr := f.sb.RootHeapAddr   // v0/v1 only
 	rootStNodeAddr := uint64(0)         // Will need to extract if needed
 
 	// Step 4: Create FileWriter with loaded structures
@@ -2184,6 +2685,19 @@ func OpenForWrite(filename string, mode OpenMode, opts ...WriteOption) (*FileWri
 		rootStNodeAddr: rootStNodeAddr,
 	}
 
+	// Step 5: Reuse the free space persisted in the file
+	if mode == OpenReadWrite {
+		err := cfg.checkFreeSpacePersistence(f.sb.Version)
+		if err == nil {
+			err = fileWriter.loadFreeSpace()
+		}
+		if err != nil {
+			_ = fw.Close()
+			_ = f.Close()
+			return nil, err
+		}
+	}
+
 	return fileWriter, nil
 }
 
@@ -2193,6 +2707,9 @@ func OpenForWrite(filename string, mode OpenMode, opts ...WriteOption) (*FileWri
 // Supported operations:
 //   - WriteAttribute(): Add attributes to existing dense storage
 //   - Write(): Overwrite dataset data (for contiguous layout)
+//   - Write(), WriteSlice(), WriteChunk(), Resize(), Append(): Update chunked
+//     datasets indexed by a version 1 B-tree; existing chunks are read back
+//     through the dataset's filters
 //
 // Parameters:
 //   - path: Dataset path (e.g., "/temperature")
@@ -2211,18 +2728,10 @@ func OpenForWrite(filename string, mode OpenMode, opts ...WriteOption) (*FileWri
 //
 //nolint:gocognit,gocyclo,cyclop // Complex navigation logic with multiple object types and error paths
 func (fw *FileWriter) OpenDataset(path string) (*DatasetWriter, error) {
-	// Step 1: Navigate to dataset using file.Walk()
-	var foundDataset *Dataset
-	fw.file.Walk(func(p string, obj Object) {
-		if p == path {
-			if ds, ok := obj.(*Dataset); ok {
-				foundDataset = ds
-			}
-		}
-	})
-
-	if foundDataset == nil {
-		return nil, fmt.Errorf("dataset %q not found", path)
+	// Step 1: Resolve the dataset path link by link
+	foundDataset, err := fw.file.OpenDataset(path)
+	if err != nil {
+		return nil, fmt.Errorf("dataset %q not found: %w", path, err)
 	}
 
 	// Step 2: Read object header to extract dataset metadata
@@ -2281,11 +2790,24 @@ func (fw *FileWriter) OpenDataset(path string) (*DatasetWriter, error) {
 		dataAddress:   layoutMsg.DataAddress, // Data address from layout message
 		dataSize:      dataSize,
 		dtype:         datatypeMsg,
+		elemSize:      uint64(datatypeMsg.Size),
 		dims:          dataspaceMsg.Dimensions,
+		layoutClass:   layoutMsg.Class,
 		objectHeader:  oh,          // Store object header for attribute operations
 		denseAttrInfo: attrInfoMsg, // May be nil if no dense storage yet
 	}
 
+	// Step 6: Load the chunk index so chunks can be rewritten and added
+	if layoutMsg.IsChunked() {
+		info, err := core.ReadDatasetInfo(oh, fw.file.sb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read dataset metadata: %w", err)
+		}
+		if err := dsw.loadChunkState(oh, info); err != nil {
+			return nil, fmt.Errorf("dataset %q: %w", path, err)
+		}
+	}
+
 	return dsw, nil
 }
 
@@ -2315,6 +2837,16 @@ func (fw *FileWriter) Close() error {
 		}
 	}
 
+	// Store the free space last, once nothing else is allocated
+	if err := fw.persistFreeSpace(); err != nil {
+		return fmt.Errorf("failed to persist free space: %w", err)
+	}
+
+	// Complete files stored with a driver
+	if err := fw.writeDriverInfo(); err != nil {
+		return err
+	}
+
 	// Flush buffered writes
 	if err := fw.writer.Flush(); err != nil {
 		return fmt.Errorf("failed to flush: %w", err)
@@ -2326,7 +2858,7 @@ func (fw *FileWriter) Close() error {
 	}
 
 	fw.writer = nil
-	return nil
+	return fw.saveBackingStore()
 }
 
 // DisableRebalancing temporarily disables B-tree rebalancing.
@@ -2695,16 +3227,16 @@ type rootGroupInfo struct {
 // Returns information about the created root group structure.
 // createRootGroupStructure creates the root group structures.
 // Dispatches to version-specific implementation based on superblock version.
-func createRootGroupStructure(fw *writer.FileWriter, superblockVersion uint8, rootAttributes map[string]interface{}) (*rootGroupInfo, error) {
-	if superblockVersion == core.Version0 {
-		return createRootGroupStructureV0(fw, rootAttributes)
+func createRootGroupStructure(fw *writer.FileWriter, sb *core.Superblock, rootAttributes map[string]interface{}, maxCompact int) (*rootGroupInfo, error) {
+	if sb.Version <= core.Version1 {
+		return createRootGroupStructureV0(fw, sb, rootAttributes, maxCompact)
 	}
-	return createRootGroupStructureV2(fw, rootAttributes)
+	return createRootGroupStructureV2(fw, sb, rootAttributes, maxCompact)
 }
 
 // createRootGroupStructureV2 creates root group for modern format (v2/v3).
 // Order: Heap → B-tree → Object Header (v2 doesn't cache addresses in superblock).
-func createRootGroupStructureV2(fw *writer.FileWriter, rootAttributes map[string]interface{}) (*rootGroupInfo, error) {
+func createRootGroupStructureV2(fw *writer.FileWriter, sb *core.Superblock, rootAttributes map[string]interface{}, maxCompact int) (*rootGroupInfo, error) {
 	const offsetSize = 8
 	const lengthSize = 8
 
@@ -2716,13 +3248,13 @@ func createRootGroupStructureV2(fw *writer.FileWriter, rootAttributes map[string
 	}
 
 	// Create and write symbol table node
-	rootStNodeAddr, err := createSymbolTableNode(fw, offsetSize)
+	rootStNodeAddr, err := createSymbolTableNode(fw, sb)
 	if err != nil {
 		return nil, err
 	}
 
 	// Create and write B-tree
-	rootBTreeAddr, err := createBTreeNode(fw, rootStNodeAddr, offsetSize)
+	rootBTreeAddr, err := createBTreeNode(fw, rootStNodeAddr, sb)
 	if err != nil {
 		return nil, err
 	}
@@ -2733,7 +3265,7 @@ func createRootGroupStructureV2(fw *writer.FileWriter, rootAttributes map[string
 	}
 
 	// Create and write root group object header
-	rootGroupAddr, rootGroupSize, err := writeRootGroupHeader(fw, rootBTreeAddr, rootHeapAddr, offsetSize, lengthSize, rootAttributes)
+	rootGroupAddr, rootGroupSize, err := writeRootGroupHeader(fw, rootBTreeAddr, rootHeapAddr, offsetSize, lengthSize, rootAttributes, maxCompact)
 	if err != nil {
 		return nil, err
 	}
@@ -2748,12 +3280,12 @@ func createRootGroupStructureV2(fw *writer.FileWriter, rootAttributes map[string
 	}, nil
 }
 
-// createRootGroupStructureV0 creates root group for legacy format (v0).
+// createRootGroupStructureV0 creates root group for legacy format (v0/v1).
 // Order: Object Header → B-tree → Heap (as per C library H5Gobj.c)
 // This matches the reference implementation where:
 // 1. H5O_create() creates object header first
 // 2. H5G__stab_create_components() creates B-tree, then heap.
-func createRootGroupStructureV0(fw *writer.FileWriter, rootAttributes map[string]interface{}) (*rootGroupInfo, error) {
+func createRootGroupStructureV0(fw *writer.FileWriter, sb *core.Superblock, rootAttributes map[string]interface{}, maxCompact int) (*rootGroupInfo, error) {
 	const offsetSize = 8
 	const lengthSize = 8
 
@@ -2767,48 +3299,52 @@ func createRootGroupStructureV0(fw *writer.FileWriter, rootAttributes map[string
 	objHeaderSize := uint64(16 + 20 + 4)
 
 	// B-tree node size: signature(4) + node_type(1) + node_level(1) + entries_used(2) +
-	//                   left_sibling(8) + right_sibling(8) + key+child pairs
-	// For root with 1 child: 24 + (8+8)*2 = 56 bytes (minimum)
-	btreeSize := uint64(56)
+	//                   left_sibling(8) + right_sibling(8) + 2K+1 keys and 2K children
+	// HDF5 reads the full node, so it must not overlap the symbol table node
+	btreeSize := groupBTreeNodeSize(sb)
 
 	// Symbol table node size: signature(4) + version(1) + reserved(1) + num_symbols(2) +
-	//                          entries (40 bytes each, capacity 32)
-	stNodeSize := uint64(8 + 32*40)
+	//                          entries (40 bytes each, capacity 2K)
+	stNodeSize := symbolTableNodeSize(sb)
 
-	// Local heap size: minimum ~256 bytes
-	heapSize := uint64(256)
+	// Local heap size: header + 256 bytes of data
+	rootHeap := structures.NewLocalHeap(256)
+	heapSize := rootHeap.Size()
 
-	// Step 2: Calculate fixed addresses (no Allocate - we write at fixed offsets)
-	// Superblock v0: 0x00-0x5F (96 bytes)
-	rootGroupAddr := uint64(96)                    // 0x60 - immediately after superblock
+	// Step 2: Reserve the structures in one block right after the superblock
+	// (v0: 0x00-0x5F, 96 bytes; v1: 0x00-0x63, 100 bytes), so that later
+	// allocations do not overlap them
+	rootGroupAddr, err := fw.Allocate(objHeaderSize + btreeSize + stNodeSize + heapSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate root group: %w", err)
+	}
 	rootBTreeAddr := rootGroupAddr + objHeaderSize // After object header
 	rootStNodeAddr := rootBTreeAddr + btreeSize    // After B-tree
 	rootHeapAddr := rootStNodeAddr + stNodeSize    // After symbol table node
 
 	// Step 3: Write structures in ASCENDING ADDRESS ORDER
 	// CRITICAL: Sequential write order prevents sparse file holes on Windows!
-	// Order: Object Header (96) → B-tree (136) → SNOD (192) → Heap (1480)
+	// Order: Object Header → B-tree → SNOD → Heap
 
-	// 1. Write root group object header (offset 96)
+	// 1. Write root group object header (right after the superblock)
 	// V0 superblock requires Object Header v1 (not v2!)
 	const objectHeaderVersion = 1
-	actualObjHeaderSize, err := writeRootGroupHeaderAt(fw, rootGroupAddr, rootBTreeAddr, rootHeapAddr, offsetSize, lengthSize, objectHeaderVersion, rootAttributes)
+	actualObjHeaderSize, err := writeRootGroupHeaderAt(fw, rootGroupAddr, rootBTreeAddr, rootHeapAddr, offsetSize, lengthSize, objectHeaderVersion, rootAttributes, maxCompact)
 	if err != nil {
 		return nil, err
 	}
 
-	// 2. Write B-tree (offset 136, immediately after object header)
-	if err := writeBTreeNodeAt(fw, rootBTreeAddr, rootStNodeAddr, offsetSize); err != nil {
+	// 2. Write B-tree (immediately after object header)
+	if err := writeBTreeNodeAt(fw, rootBTreeAddr, rootStNodeAddr, sb); err != nil {
 		return nil, err
 	}
 
-	// 3. Write symbol table node (offset 192, after B-tree)
-	if err := writeSymbolTableNodeAt(fw, rootStNodeAddr, offsetSize); err != nil {
+	// 3. Write symbol table node (after B-tree)
+	if err := writeSymbolTableNodeAt(fw, rootStNodeAddr, sb); err != nil {
 		return nil, err
 	}
 
-	// 4. Write local heap (offset 1480, after symbol table node)
-	rootHeap := structures.NewLocalHeap(256)
+	// 4. Write local heap (after symbol table node)
 	if err := rootHeap.WriteTo(fw, rootHeapAddr); err != nil {
 		return nil, fmt.Errorf("failed to write root heap: %w", err)
 	}
@@ -2823,12 +3359,31 @@ func createRootGroupStructureV0(fw *writer.FileWriter, rootAttributes map[string
 	}, nil
 }
 
-// writeSymbolTableNodeAt writes a symbol table node at the specified address.
-func writeSymbolTableNodeAt(fw *writer.FileWriter, addr uint64, offsetSize int) error {
-	rootStNode := structures.NewSymbolTableNode(32) // Standard capacity (2*K where K=16)
+// symbolTableNodeSize returns the size of a symbol table node holding 2K
+// entries, K being the group leaf node K.
+func symbolTableNodeSize(sb *core.Superblock) uint64 {
+	// Format: 8-byte header + 2K * entrySize
+	// entrySize = 2*offsetSize + 4 + 4 + 16 = 2*8 + 24 = 40 bytes
+	entrySize := 2*uint64(sb.OffsetSize) + 4 + 4 + 16
+	return 8 + 2*uint64(sb.SymbolLeafK)*entrySize
+}
+
+// groupBTreeNodeSize returns the size of a group B-tree node with 2K+1 keys
+// and 2K children, K being the group internal node K.
+func groupBTreeNodeSize(sb *core.Superblock) uint64 {
+	// Header: 4 (sig) + 1 (type) + 1 (level) + 2 (entries) + 2*8 (siblings) = 24 bytes
+	// Keys: (2K+1) * offsetSize, children: 2K * offsetSize
+	k := uint64(sb.SymbolInternalK)
+	return 8 + 2*uint64(sb.OffsetSize) + (4*k+1)*uint64(sb.OffsetSize)
+}
+
+// writeSymbolTableNodeAt writes an empty symbol table node at the specified address.
+func writeSymbolTableNodeAt(fw *writer.FileWriter, addr uint64, sb *core.Superblock) error {
+	capacity := 2 * sb.SymbolLeafK
+	rootStNode := structures.NewSymbolTableNode(capacity)
 
 	// Write symbol table node (empty initially)
-	if err := rootStNode.WriteAt(fw, addr, uint8(offsetSize), 32, binary.LittleEndian); err != nil { //nolint:gosec // Safe: offsetSize validated to be 8
+	if err := rootStNode.WriteAt(fw, addr, sb.OffsetSize, capacity, sb.Endianness); err != nil {
 		return fmt.Errorf("failed to write symbol table node: %w", err)
 	}
 
@@ -2836,8 +3391,8 @@ func writeSymbolTableNodeAt(fw *writer.FileWriter, addr uint64, offsetSize int)
 }
 
 // writeBTreeNodeAt writes a B-tree node at the specified address.
-func writeBTreeNodeAt(fw *writer.FileWriter, addr, stNodeAddr uint64, offsetSize int) error {
-	rootBTree := structures.NewBTreeNodeV1(0, 16) // Type 0 = group symbol table, K=16
+func writeBTreeNodeAt(fw *writer.FileWriter, addr, stNodeAddr uint64, sb *core.Superblock) error {
+	rootBTree := structures.NewBTreeNodeV1(0, sb.SymbolInternalK) // Type 0 = group symbol table
 
 	// Add symbol table node address as child (with key 0 for empty group)
 	if err := rootBTree.AddKey(0, stNodeAddr); err != nil {
@@ -2845,7 +3400,7 @@ func writeBTreeNodeAt(fw *writer.FileWriter, addr, stNodeAddr uint64, offsetSize
 	}
 
 	// Write B-tree
-	if err := rootBTree.WriteAt(fw, addr, uint8(offsetSize), 16, binary.LittleEndian); err != nil { //nolint:gosec // Safe: offsetSize validated to be 8
+	if err := rootBTree.WriteAt(fw, addr, sb.OffsetSize, sb.SymbolInternalK, sb.Endianness); err != nil {
 		return fmt.Errorf("failed to write B-tree: %w", err)
 	}
 
@@ -2854,23 +3409,14 @@ func writeBTreeNodeAt(fw *writer.FileWriter, addr, stNodeAddr uint64, offsetSize
 
 // createSymbolTableNode creates and writes a symbol table node for a group.
 // Returns the address where the node was written.
-func createSymbolTableNode(fw *writer.FileWriter, offsetSize int) (uint64, error) {
-	rootStNode := structures.NewSymbolTableNode(32) // Standard capacity (2*K where K=16)
-
-	// Calculate symbol table node size
-	// Format: 8-byte header + 32 * entrySize
-	// entrySize = 2*offsetSize + 4 + 4 + 16 = 2*8 + 24 = 40 bytes
-	entrySize := 2*offsetSize + 4 + 4 + 16
-	stNodeSize := uint64(8 + 32*entrySize) //nolint:gosec // Safe: constant calculation always fits in uint64
-
-	rootStNodeAddr, err := fw.Allocate(stNodeSize)
+func createSymbolTableNode(fw *writer.FileWriter, sb *core.Superblock) (uint64, error) {
+	rootStNodeAddr, err := fw.Allocate(symbolTableNodeSize(sb))
 	if err != nil {
 		return 0, fmt.Errorf("failed to allocate root symbol table node: %w", err)
 	}
 
-	// Write symbol table node (empty initially)
-	if err := rootStNode.WriteAt(fw, rootStNodeAddr, uint8(offsetSize), 32, binary.LittleEndian); err != nil { //nolint:gosec // Safe: offsetSize validated to be 8
-		return 0, fmt.Errorf("failed to write root symbol table node: %w", err)
+	if err := writeSymbolTableNodeAt(fw, rootStNodeAddr, sb); err != nil {
+		return 0, err
 	}
 
 	return rootStNodeAddr, nil
@@ -2878,28 +3424,14 @@ func createSymbolTableNode(fw *writer.FileWriter, offsetSize int) (uint64, error
 
 // createBTreeNode creates and writes a B-tree node for a group.
 // Returns the address where the node was written.
-func createBTreeNode(fw *writer.FileWriter, stNodeAddr uint64, offsetSize int) (uint64, error) {
-	rootBTree := structures.NewBTreeNodeV1(0, 16) // Type 0 = group symbol table, K=16
-
-	// Add symbol table node address as child (with key 0 for empty group)
-	if err := rootBTree.AddKey(0, stNodeAddr); err != nil {
-		return 0, fmt.Errorf("failed to add root B-tree key: %w", err)
-	}
-
-	// Calculate B-tree size
-	// Header: 4 (sig) + 1 (type) + 1 (level) + 2 (entries) + 2*8 (siblings) = 24 bytes
-	// Keys: (2K+1) * offsetSize = 33 * 8 = 264 bytes
-	// Children: 2K * offsetSize = 32 * 8 = 256 bytes
-	btreeSize := uint64(24 + (2*16+1)*offsetSize + 2*16*offsetSize) //nolint:gosec // Safe: constant calculation always fits in uint64
-
-	rootBTreeAddr, err := fw.Allocate(btreeSize)
+func createBTreeNode(fw *writer.FileWriter, stNodeAddr uint64, sb *core.Superblock) (uint64, error) {
+	rootBTreeAddr, err := fw.Allocate(groupBTreeNodeSize(sb))
 	if err != nil {
 		return 0, fmt.Errorf("failed to allocate root B-tree: %w", err)
 	}
 
-	// Write B-tree
-	if err := rootBTree.WriteAt(fw, rootBTreeAddr, uint8(offsetSize), 16, binary.LittleEndian); err != nil { //nolint:gosec // Safe: offsetSize validated to be 8
-		return 0, fmt.Errorf("failed to write root B-tree: %w", err)
+	if err := writeBTreeNodeAt(fw, rootBTreeAddr, stNodeAddr, sb); err != nil {
+		return 0, err
 	}
 
 	return rootBTreeAddr, nil
@@ -2908,7 +3440,7 @@ func createBTreeNode(fw *writer.FileWriter, stNodeAddr uint64, offsetSize int) (
 // writeRootGroupHeaderAt writes the root group object header at the specified address.
 // Returns the actual size written.
 // The objectHeaderVersion parameter determines which object header format to use (1 or 2).
-func writeRootGroupHeaderAt(fw *writer.FileWriter, addr, btreeAddr, heapAddr uint64, offsetSize, lengthSize int, objectHeaderVersion uint8, rootAttributes map[string]interface{}) (uint64, error) {
+func writeRootGroupHeaderAt(fw *writer.FileWriter, addr, btreeAddr, heapAddr uint64, offsetSize, lengthSize int, objectHeaderVersion uint8, rootAttributes map[string]interface{}, maxCompact int) (uint64, error) {
 	stMsg := core.EncodeSymbolTableMessage(btreeAddr, heapAddr, offsetSize, lengthSize)
 
 	// Start with Symbol Table message
@@ -2916,8 +3448,8 @@ func writeRootGroupHeaderAt(fw *writer.FileWriter, addr, btreeAddr, heapAddr uin
 		{Type: core.MsgSymbolTable, Data: stMsg},
 	}
 
-	// Add attribute messages - use dense storage if >8 attributes
-	if len(rootAttributes) > MaxCompactAttributes {
+	// Add attribute messages - use dense storage beyond maxCompact attributes
+	if len(rootAttributes) > maxCompact {
 		// Dense storage: Use Fractal Heap + B-tree v2
 		denseWriter := writer.NewDenseAttributeWriter(addr)
 
@@ -3014,7 +3546,7 @@ func writeRootGroupHeaderAt(fw *writer.FileWriter, addr, btreeAddr, heapAddr uin
 // writeRootGroupHeader creates and writes the root group object header.
 // Returns the address where the header was written and its size.
 // Uses Object Header v2 (for superblock v2).
-func writeRootGroupHeader(fw *writer.FileWriter, btreeAddr, heapAddr uint64, offsetSize, lengthSize int, rootAttributes map[string]interface{}) (uint64, uint64, error) {
+func writeRootGroupHeader(fw *writer.FileWriter, btreeAddr, heapAddr uint64, offsetSize, lengthSize int, rootAttributes map[string]interface{}, maxCompact int) (uint64, uint64, error) {
 	stMsg := core.EncodeSymbolTableMessage(btreeAddr, heapAddr, offsetSize, lengthSize)
 
 	// Start with Symbol Table message
@@ -3022,8 +3554,8 @@ func writeRootGroupHeader(fw *writer.FileWriter, btreeAddr, heapAddr uint64, off
 		{Type: core.MsgSymbolTable, Data: stMsg},
 	}
 
-	// Add attribute messages - use dense storage if >8 attributes
-	if len(rootAttributes) > MaxCompactAttributes {
+	// Add attribute messages - use dense storage beyond maxCompact attributes
+	if len(rootAttributes) > maxCompact {
 		// Dense storage: Use Fractal Heap + B-tree v2
 		// Create a temporary root group address (will be updated after allocation)
 		tempRootAddr := uint64(48) // Placeholder, actual address determined after writing
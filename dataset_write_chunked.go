@@ -3,6 +3,8 @@ package hdf5
 import (
 	"encoding/binary"
 	"fmt"
+	"math"
+	"reflect"
 
 	"github.com/meko-christian/go-hdf5/internal/core"
 	"github.com/meko-christian/go-hdf5/internal/structures"
@@ -26,7 +28,7 @@ import (
 // - Single-level B-tree (no splits).
 //
 //nolint:gocognit,gocyclo,cyclop,funlen // Complex by nature: chunked dataset creation involves many steps
-func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []uint64, config *datasetConfig) (*DatasetWriter, error) {
+func (fw *FileWriter) createChunkedDataset(name string, handler datatypeHandler, dims []uint64, config *datasetConfig) (*DatasetWriter, error) {
 	// 1. Validate chunk dimensions
 	if len(config.chunkDims) != len(dims) {
 		return nil, fmt.Errorf("chunk dimensions (%d) must match dataset dimensions (%d)",
@@ -37,30 +39,54 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 		if chunkDim == 0 {
 			return nil, fmt.Errorf("chunk dimension %d cannot be zero", i)
 		}
-		if chunkDim > dims[i] {
+		// Resizable datasets may start smaller than a chunk; chunks only
+		// have to fit the maximum dimensions.
+		if len(config.maxDims) == len(dims) {
+			if config.maxDims[i] != Unlimited && chunkDim > config.maxDims[i] {
+				return nil, fmt.Errorf("chunk dimension %d (%d) cannot exceed maximum dimension (%d)",
+					i, chunkDim, config.maxDims[i])
+			}
+		} else if chunkDim > dims[i] {
 			return nil, fmt.Errorf("chunk dimension %d (%d) cannot exceed dataset dimension (%d)",
 				i, chunkDim, dims[i])
 		}
 	}
 
 	// 2. Get datatype info
-	dtInfo, err := getDatatypeInfo(dtype, config)
+	dtInfo, err := handler.GetInfo(config)
 	if err != nil {
 		return nil, fmt.Errorf("invalid datatype: %w", err)
 	}
 
+	// Writer datatype: array/enum data is encoded with its base type
+	var dsMsgForWriter *core.DatatypeMessage
+	if dtInfo.baseType != nil {
+		// For array/enum, use base type for data writing
+		dsMsgForWriter = &core.DatatypeMessage{
+			Class:   dtInfo.baseType.class,
+			Version: 1,
+			Size:    dtInfo.baseType.size,
+		}
+	} else {
+		// For simple types, use the datatype itself
+		dsMsgForWriter = &core.DatatypeMessage{
+			Class:   dtInfo.class,
+			Version: 1,
+			Size:    dtInfo.size,
+		}
+	}
+
 	// 3. Create chunk coordinator
 	chunkCoordinator, err := writer.NewChunkCoordinator(dims, config.chunkDims)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create chunk coordinator: %w", err)
 	}
 
-	// 4. B-tree address will be 0 initially (written during Write())
+	// 4. B-tree address is undefined until chunks are written (during Write())
 	// This is standard HDF5 practice for empty chunked datasets
-	btreeAddress := uint64(0)
+	btreeAddress := undefinedAddress
 
 	// 5. Encode datatype message
-	handler := datatypeRegistry[dtype]
 	datatypeData, err := handler.EncodeDatatypeMessage(dtInfo)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode datatype: %w", err)
@@ -73,12 +99,14 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 	}
 
 	// 7. Create chunked layout message
+	// The layout stores an extra trailing chunk dimension holding the element size.
+	layoutChunkDims := append(append([]uint64{}, config.chunkDims...), uint64(dtInfo.size))
 	layoutData, err := core.EncodeLayoutMessage(
 		core.LayoutChunked,
 		0,            // dataSize not used for chunked
-		btreeAddress, // B-tree address (0 for now)
+		btreeAddress, // B-tree address (undefined for now)
 		fw.file.sb,
-		config.chunkDims,
+		layoutChunkDims,
 	)
 	if err != nil {
 		return nil, fmt.Errorf("failed to encode chunked layout: %w", err)
@@ -99,7 +127,7 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 		}
 	}
 
-	// 9. Create object header with optional filter pipeline
+	// 9. Create object header with optional fill value and filter pipeline
 	ohw := &core.ObjectHeaderWriter{
 		Version: 2,
 		Flags:   0, // Minimal flags
@@ -110,6 +138,14 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 		},
 	}
 
+	fillMsg, err := newFillValueMessage(config, dsMsgForWriter, uint64(dtInfo.size), true)
+	if err != nil {
+		return nil, err
+	}
+	if err := appendFillValueMessage(ohw, fillMsg); err != nil {
+		return nil, err
+	}
+
 	// Add filter pipeline message if present
 	if config.pipeline != nil && !config.pipeline.IsEmpty() {
 		pipelineData, err := config.pipeline.EncodePipelineMessage()
@@ -130,7 +166,7 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 	}
 
 	// Allocate and write header
-	headerAddress, err := fw.writer.Allocate(headerSize)
+	headerAddress, err := fw.writer.AllocateObjectHeader(headerSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate header: %w", err)
 	}
@@ -149,7 +185,7 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 	//   - OHDR signature: 4 bytes
 	//   - Version: 1 byte
 	//   - Flags: 1 byte
-	//   - Chunk size: 1 byte (for flags bits 0-1 = 0)
+	//   - Chunk size: 1, 2 or 4 bytes (flags bits 0-1)
 	//   - Messages (each: type 1 + size 2 + flags 1 + data):
 	//     - Datatype: 4 + len(datatypeData)
 	//     - Dataspace: 4 + len(dataspaceData)
@@ -157,10 +193,7 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 	//     - Layout data: version(1) + class(1) + dimensionality(1) + btreeAddress(offsetSize)
 	// The B-tree address is at offset 3 within layout message data.
 	layoutBTreeOffset := headerAddress +
-		4 + // OHDR
-		1 + // version
-		1 + // flags
-		1 + // chunk size
+		objectHeaderPrefixSize(ohw) + // OHDR, version, flags, chunk size
 		4 + uint64(len(datatypeData)) + // datatype message
 		4 + uint64(len(dataspaceData)) + // dataspace message
 		4 + // layout message header
@@ -173,58 +206,56 @@ func (fw *FileWriter) createChunkedDataset(name string, dtype Datatype, dims []u
 	}
 
 	// 10. Create DatasetWriter
-	var dsMsgForWriter *core.DatatypeMessage
-	if dtInfo.baseType != nil {
-		// For array/enum, use base type for data writing
-		dsMsgForWriter = &core.DatatypeMessage{
-			Class:   dtInfo.baseType.class,
-			Version: 1,
-			Size:    dtInfo.baseType.size,
-		}
-	} else {
-		// For simple types, use the datatype itself
-		dsMsgForWriter = &core.DatatypeMessage{
-			Class:   dtInfo.class,
-			Version: 1,
-			Size:    dtInfo.size,
-		}
-	}
-
 	totalElements := calculateTotalElements(dims)
 	dataSize := totalElements * uint64(dtInfo.size)
 
-	return &DatasetWriter{
+	dsw := &DatasetWriter{
 		fileWriter:        fw,
 		name:              name,
 		address:           headerAddress,
 		dataAddress:       btreeAddress, // Will be updated on Write()
 		dataSize:          dataSize,
 		dtype:             dsMsgForWriter,
+		elemSize:          uint64(dtInfo.size),
 		dims:              dims,
 		maxDims:           config.maxDims, // Maximum dimensions for resize support
+		layoutClass:       core.LayoutChunked,
 		isChunked:         true,
 		chunkCoordinator:  chunkCoordinator,
 		chunkDims:         config.chunkDims,
 		pipeline:          config.pipeline, // Filter pipeline
 		layoutBTreeOffset: layoutBTreeOffset,
-	}, nil
+		chunks:            make(map[string]*writtenChunk),
+		fillPattern:       fillMsg.Pattern(uint64(dtInfo.size)),
+	}
+
+	// 11. Early allocation writes every chunk, filled, right away
+	if fillMsg != nil && fillMsg.AllocTime == core.AllocTimeEarly {
+		buf := make([]byte, dataSize)
+		if fillMsg.FillTime != core.FillTimeNever {
+			core.FillBuffer(buf, fillMsg.Pattern(uint64(dtInfo.size)))
+		}
+		if err := dsw.writeChunkedData(buf); err != nil {
+			return nil, fmt.Errorf("failed to allocate chunks: %w", err)
+		}
+	}
+
+	return dsw, nil
 }
 
-// writeChunkedData writes data to chunked dataset.
-//
-// Implementation steps:
-// 1. Extract chunks using ChunkCoordinator
-// 2. Write each chunk to file
-// 3. Build B-tree index
-// 4. Write B-tree to file
-// 5. Update object header with B-tree address
-//
-// For MVP (Phase 1):
-// - All chunks written at once (no partial writes)
-// - No compression
-// - Simple B-tree v1.
+// writtenChunk records a chunk stored in the file.
+type writtenChunk struct {
+	coord    []uint64 // Scaled chunk coordinate
+	address  uint64   // File address of the (filtered) chunk data
+	nbytes   uint32   // Stored size in bytes
+	capacity uint64   // Bytes allocated at address; later writes reuse the space if they fit
+}
+
+// writeChunkedData writes the whole dataset to a chunked dataset.
 //
-//nolint:gocognit,cyclop // Complex by nature: writing chunks + B-tree + updating layout requires multiple steps
+// Every chunk is rebuilt from buf: chunks lying fully inside the dataset are
+// never read back, and the parts of edge chunks beyond the dataset extent are
+// set to the fill value.
 func (dw *DatasetWriter) writeChunkedData(buf []byte) error {
 	if !dw.isChunked {
 		return fmt.Errorf("writeChunkedData called on non-chunked dataset")
@@ -234,59 +265,210 @@ func (dw *DatasetWriter) writeChunkedData(buf []byte) error {
 		return fmt.Errorf("data size mismatch: expected %d bytes, got %d", dw.dataSize, len(buf))
 	}
 
-	elemSize := dw.dtype.Size
+	all := &HyperslabSelection{Start: make([]uint64, len(dw.dims)), Count: dw.dims}
+	fillHyperslabDefaults(all, len(dw.dims))
+	return dw.writeChunkedSelection(all, buf)
+}
 
-	// 1. Create B-tree writer
-	dimensionality := len(dw.dims)
-	btreeWriter := structures.NewChunkBTreeWriter(dimensionality)
+// writeChunkedSelection writes the elements of a validated selection to the
+// chunks it overlaps and rewrites the chunk index. Chunks only partially
+// covered by the selection are read, unfiltered, updated and filtered again;
+// chunks that do not exist yet start out as the fill value.
+func (dw *DatasetWriter) writeChunkedSelection(sel *HyperslabSelection, buf []byte) error {
+	elemSize := dw.elemSize
+	axes := hyperslabAxes(sel)
+
+	chunkStrides := make([]uint64, len(dw.chunkDims))
+	chunkStrides[len(chunkStrides)-1] = 1
+	for i := len(chunkStrides) - 2; i >= 0; i-- {
+		chunkStrides[i] = chunkStrides[i+1] * dw.chunkDims[i+1]
+	}
 
-	// 2. Process each chunk
-	totalChunks := dw.chunkCoordinator.GetTotalChunks()
+	lo := make([]uint64, len(dw.dims))
+	hi := make([]uint64, len(dw.dims))
+	for _, coord := range findOverlappingChunks(sel, dw.chunkDims, dw.dims) {
+		for i := range coord {
+			lo[i] = coord[i] * dw.chunkDims[i]
+			hi[i] = min(lo[i]+dw.chunkDims[i], dw.dims[i])
+		}
 
-	for i := uint64(0); i < totalChunks; i++ {
-		// Get chunk coordinate
-		coord := dw.chunkCoordinator.GetChunkCoordinate(i)
+		region := newSlabRegion(axes, lo, hi)
+		if region.empty() {
+			continue // Selection skips this chunk (stride gap)
+		}
 
-		// Extract chunk data
-		chunkData := dw.chunkCoordinator.ExtractChunkData(buf, coord, elemSize)
+		chunk, err := dw.loadChunk(coord, region.covers(lo, hi))
+		if err != nil {
+			return err
+		}
 
-		// Apply filters to chunk (if pipeline configured)
-		if dw.pipeline != nil && !dw.pipeline.IsEmpty() {
-			filtered, err := dw.pipeline.Apply(chunkData)
-			if err != nil {
-				return fmt.Errorf("filter application failed for chunk %v: %w", coord, err)
+		region.forEachRun(func(bufIndex uint64, rel []uint64, n uint64) {
+			var off uint64
+			for i, r := range rel {
+				off += r * chunkStrides[i]
 			}
-			chunkData = filtered
+			copy(chunk[off*elemSize:(off+n)*elemSize], buf[bufIndex*elemSize:(bufIndex+n)*elemSize])
+		})
+
+		if err := dw.storeChunk(coord, chunk); err != nil {
+			return err
+		}
+	}
+
+	return dw.flushChunkIndex()
+}
+
+// loadChunk returns the unfiltered contents of the chunk at coord. Chunks
+// not written yet, and chunks about to be overwritten completely (fresh),
+// start out as the fill value.
+func (dw *DatasetWriter) loadChunk(coord []uint64, fresh bool) ([]byte, error) {
+	chunkBytes := dw.elemSize
+	for _, d := range dw.chunkDims {
+		chunkBytes *= d
+	}
+
+	wc, exists := dw.chunks[chunkCoordsToKey(coord)]
+	if fresh || !exists {
+		chunk := make([]byte, chunkBytes)
+		core.FillBuffer(chunk, dw.fillPattern)
+		return chunk, nil
+	}
+
+	stored := make([]byte, wc.nbytes)
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
+	if _, err := dw.fileWriter.writer.ReadAt(stored, int64(wc.address)); err != nil {
+		return nil, fmt.Errorf("failed to read chunk %v: %w", coord, err)
+	}
+
+	chunk := stored
+	if dw.pipeline != nil && !dw.pipeline.IsEmpty() {
+		var err error
+		chunk, err = dw.pipeline.Remove(stored)
+		if err != nil {
+			return nil, fmt.Errorf("filter removal failed for chunk %v: %w", coord, err)
+		}
+	}
+	if uint64(len(chunk)) != chunkBytes {
+		return nil, fmt.Errorf("chunk %v has %d bytes, expected %d", coord, len(chunk), chunkBytes)
+	}
+	return chunk, nil
+}
+
+// storeChunk filters and writes the chunk at coord, reusing the chunk's
+// previous space when the filtered data still fits.
+func (dw *DatasetWriter) storeChunk(coord []uint64, chunk []byte) error {
+	// Apply filters to chunk (if pipeline configured)
+	if dw.pipeline != nil && !dw.pipeline.IsEmpty() {
+		filtered, err := dw.pipeline.Apply(chunk)
+		if err != nil {
+			return fmt.Errorf("filter application failed for chunk %v: %w", coord, err)
 		}
+		chunk = filtered
+	}
+	if uint64(len(chunk)) > math.MaxUint32 {
+		return fmt.Errorf("chunk %v size %d exceeds uint32 maximum", coord, len(chunk))
+	}
 
+	key := chunkCoordsToKey(coord)
+	wc, exists := dw.chunks[key]
+	var oldAddress uint64
+	if exists {
+		oldAddress = wc.address
+	}
+	if !exists || uint64(len(chunk)) > wc.capacity {
 		// Allocate space for chunk (filtered size may differ from original)
-		chunkAddr, err := dw.fileWriter.writer.Allocate(uint64(len(chunkData)))
+		addr, err := dw.fileWriter.writer.AllocateRaw(uint64(len(chunk)))
 		if err != nil {
 			return fmt.Errorf("failed to allocate chunk %v: %w", coord, err)
 		}
+		// The chunk outgrew its previous space, which can be reused.
+		if exists {
+			if err := dw.fileWriter.writer.Free(oldAddress, wc.capacity); err != nil {
+				return fmt.Errorf("failed to free chunk %v: %w", coord, err)
+			}
+		}
+		wc = &writtenChunk{
+			coord:    append([]uint64{}, coord...),
+			address:  addr,
+			capacity: uint64(len(chunk)),
+		}
+		dw.chunks[key] = wc
+	}
+
+	if err := dw.fileWriter.writer.WriteAtAddress(chunk, wc.address); err != nil {
+		return fmt.Errorf("failed to write chunk %v: %w", coord, err)
+	}
 
-		// Write chunk data (filtered)
-		if err := dw.fileWriter.writer.WriteAtAddress(chunkData, chunkAddr); err != nil {
-			return fmt.Errorf("failed to write chunk %v: %w", coord, err)
+	// Only new, moved or resized chunks change the index.
+	nbytes := uint32(len(chunk))
+	if exists && wc.address == oldAddress && wc.nbytes == nbytes {
+		return nil
+	}
+	wc.nbytes = nbytes
+	return dw.indexChunk(wc)
+}
+
+// indexChunk inserts (or updates) the chunk at coord in the chunk B-tree.
+// The nodes are written by flushChunkIndex.
+func (dw *DatasetWriter) indexChunk(wc *writtenChunk) error {
+	if dw.chunkIndex == nil {
+		// Node size follows the file's indexed storage K, as HDF5 reads it.
+		k := int(dw.fileWriter.file.sb.IndexedStorageK)
+		if k == 0 {
+			k = structures.DefaultChunkBTreeK
 		}
+		dw.chunkIndex = structures.NewChunkBTree(len(dw.dims)+1, k)
+	}
+
+	// Keys hold chunk offsets in elements plus the trailing datatype dimension.
+	offset := make([]uint64, len(wc.coord)+1)
+	for i, c := range wc.coord {
+		offset[i] = c * dw.chunkDims[i]
+	}
+	if err := dw.chunkIndex.Insert(offset, wc.address, wc.nbytes); err != nil {
+		return fmt.Errorf("failed to add chunk %v to index: %w", wc.coord, err)
+	}
+	return nil
+}
 
-		// Add to B-tree index with chunk size
-		//nolint:gosec // G115: chunk size is validated and fits in uint32
-		if err := btreeWriter.AddChunkWithSize(coord, chunkAddr, uint32(len(chunkData))); err != nil {
-			return fmt.Errorf("failed to add chunk %v to index: %w", coord, err)
+// flushChunkIndex writes the chunk B-tree nodes changed since the last flush
+// and records the root address in the layout message if it moved.
+func (dw *DatasetWriter) flushChunkIndex() error {
+	if dw.chunkIndex == nil {
+		if dw.dataAddress == undefinedAddress {
+			return nil
 		}
+		// Every chunk was dropped; the dataset has no storage again.
+		return dw.setChunkIndexAddress(undefinedAddress)
 	}
 
-	// 3. Write B-tree
-	btreeAddr, err := btreeWriter.WriteToFile(dw.fileWriter.writer, dw.fileWriter.writer.Allocator())
-	if err != nil {
+	if err := dw.chunkIndex.Flush(dw.fileWriter.writer, dw.fileWriter.writer.Allocator()); err != nil {
 		return fmt.Errorf("failed to write B-tree: %w", err)
 	}
 
-	// 4. Store B-tree address
+	if root := dw.chunkIndex.RootAddress(); root != dw.dataAddress {
+		return dw.setChunkIndexAddress(root)
+	}
+	return nil
+}
+
+// rebuildChunkIndex builds a new chunk B-tree from dw.chunks, used after
+// chunks were dropped from the index.
+func (dw *DatasetWriter) rebuildChunkIndex() error {
+	dw.chunkIndex = nil
+	for _, wc := range dw.chunks {
+		if err := dw.indexChunk(wc); err != nil {
+			return err
+		}
+	}
+	return dw.flushChunkIndex()
+}
+
+// setChunkIndexAddress records a new chunk B-tree address.
+func (dw *DatasetWriter) setChunkIndexAddress(btreeAddr uint64) error {
 	dw.dataAddress = btreeAddr
 
-	// 5. Update the B-tree address in the layout message (in the object header).
+	// Update the B-tree address in the layout message (in the object header).
 	// This ensures the file can be read correctly after closing.
 	if dw.layoutBTreeOffset > 0 {
 		// Write B-tree address at the calculated offset.
@@ -304,7 +486,258 @@ func (dw *DatasetWriter) writeChunkedData(buf []byte) error {
 		if err := dw.fileWriter.writer.WriteAtAddress(addrBuf, dw.layoutBTreeOffset); err != nil {
 			return fmt.Errorf("failed to update B-tree address in layout message: %w", err)
 		}
+
+		// Keep a cached object header in step so Resize does not restore the old address.
+		if dw.objectHeader != nil {
+			for _, msg := range dw.objectHeader.Messages {
+				if msg.Type == core.MsgDataLayout && len(msg.Data) >= 3+len(addrBuf) {
+					copy(msg.Data[3:], addrBuf)
+				}
+			}
+		}
 	}
 
 	return nil
 }
+
+// loadChunkState sets up a chunked dataset opened from the file for writing:
+// its chunk dimensions, filters and fill value, and the chunks indexed by its
+// chunk B-tree. Datasets this writer cannot update keep isChunked unset and
+// record why in chunkErr, so they can still take attributes.
+func (dw *DatasetWriter) loadChunkState(oh *core.ObjectHeader, info *core.DatasetInfo) error {
+	layout := info.Layout
+	rank := len(dw.dims)
+	sb := dw.fileWriter.file.sb
+
+	if layout.Version > 3 || layout.ChunkIndex != core.ChunkIndexBTreeV1 {
+		dw.chunkErr = fmt.Errorf("writing chunked datasets is only supported with a version 1 B-tree chunk index")
+		return nil
+	}
+	if len(layout.ChunkSize) != rank+1 {
+		return fmt.Errorf("layout has %d chunk dimensions for a rank %d dataset", len(layout.ChunkSize), rank)
+	}
+
+	meta := newDatasetMeta(info)
+	config := &datasetConfig{}
+	if err := applyRepackFilters(config, meta.Filters); err != nil {
+		dw.chunkErr = err
+		return nil
+	}
+	if config.enableShuffle {
+		if meta.Filters[0].ID != FilterShuffle {
+			dw.chunkErr = fmt.Errorf("writing is only supported with the shuffle filter applied first")
+			return nil
+		}
+		config.pipeline.AddFilterAtStart(writer.NewShuffleFilter(uint32(dw.elemSize))) //nolint:gosec // G115: element sizes fit in uint32
+	}
+
+	addrOffset, err := layoutAddressOffset(oh)
+	if err != nil {
+		return err
+	}
+
+	chunks := make(map[string]*writtenChunk)
+	var index *structures.ChunkBTree
+	if layout.IsAllocated(sb) {
+		k := int(sb.IndexedStorageK)
+		if k == 0 {
+			k = structures.DefaultChunkBTreeK
+		}
+		index, err = structures.LoadChunkBTree(dw.fileWriter.writer.Reader(), layout.DataAddress, rank+1, k, sb.OffsetSize)
+		if err != nil {
+			return fmt.Errorf("failed to load chunk index: %w", err)
+		}
+
+		root, err := core.ParseBTreeV1Node(dw.fileWriter.writer.Reader(), layout.DataAddress, sb.OffsetSize,
+			rank+1, layout.ChunkSize)
+		if err != nil {
+			return fmt.Errorf("failed to read chunk index: %w", err)
+		}
+		entries, err := root.CollectAllChunks(dw.fileWriter.writer.Reader(), sb.OffsetSize, layout.ChunkSize)
+		if err != nil {
+			return fmt.Errorf("failed to read chunk index: %w", err)
+		}
+		for _, entry := range entries {
+			coord := entry.Key.Scaled[:rank]
+			if entry.Key.FilterMask != 0 {
+				dw.chunkErr = fmt.Errorf("writing is not supported for chunk %v stored with filters skipped", coord)
+				return nil
+			}
+			chunks[chunkCoordsToKey(coord)] = &writtenChunk{
+				coord:    coord,
+				address:  entry.Address,
+				nbytes:   entry.Key.Nbytes,
+				capacity: uint64(entry.Key.Nbytes),
+			}
+		}
+	}
+
+	coordinator, err := writer.NewChunkCoordinator(dw.dims, meta.ChunkShape)
+	if err != nil {
+		return fmt.Errorf("invalid chunk dimensions: %w", err)
+	}
+
+	if index == nil {
+		dw.dataAddress = undefinedAddress
+	}
+	dw.isChunked = true
+	dw.chunkDims = meta.ChunkShape
+	dw.chunkCoordinator = coordinator
+	dw.maxDims = info.Dataspace.MaxDims
+	dw.pipeline = config.pipeline
+	dw.fillPattern = info.FillValue.Pattern(dw.elemSize)
+	dw.layoutBTreeOffset = addrOffset
+	dw.chunks = chunks
+	dw.chunkIndex = index
+	return nil
+}
+
+// layoutAddressOffset returns the file offset of the chunk index address in
+// the (version 1 to 3) layout message of oh.
+func layoutAddressOffset(oh *core.ObjectHeader) (uint64, error) {
+	for _, msg := range oh.Messages {
+		if msg.Type != core.MsgDataLayout || len(msg.Data) == 0 {
+			continue
+		}
+
+		// Message header: type, size, flags (and creation order in v2
+		// headers tracking it); v1 headers pad it to 8 bytes.
+		dataOffset := uint64(8)
+		if oh.Version == 2 {
+			dataOffset = 4
+			if oh.Flags&0x04 != 0 {
+				dataOffset = 6
+			}
+		}
+
+		// Versions 1 and 2 store dimensionality, class and 5 reserved
+		// bytes; version 3 class and dimensionality only.
+		if msg.Data[0] < 3 {
+			return msg.Offset + dataOffset + 8, nil
+		}
+		return msg.Offset + dataOffset + 3, nil
+	}
+	return 0, fmt.Errorf("layout message not found in object header")
+}
+
+// WriteChunk writes the chunk at the given scaled chunk coordinate, for
+// example while streaming data into a dataset chunk by chunk. The chunk is
+// indexed by inserting it into the existing chunk B-tree.
+//
+// Parameters:
+//   - coords: Scaled chunk coordinate (element offset / chunk dimension)
+//   - data: The chunk's elements in row-major order; edge chunks hold only
+//     the elements inside the dataset (e.g. 10x5 for a 10x10 chunk that
+//     extends 5 elements past the last column)
+//
+// Example:
+//
+//	// 1000x100 dataset with 100x100 chunks: write rows 300-399
+//	err := ds.WriteChunk([]uint64{3, 0}, rows)
+func (dw *DatasetWriter) WriteChunk(coords []uint64, data interface{}) error {
+	if dw.chunkErr != nil {
+		return dw.chunkErr
+	}
+	if !dw.isChunked {
+		return fmt.Errorf("WriteChunk requires chunked layout")
+	}
+	if len(coords) != len(dw.dims) {
+		return fmt.Errorf("chunk coordinate dimensions (%d) != dataset dimensions (%d)",
+			len(coords), len(dw.dims))
+	}
+
+	start := make([]uint64, len(coords))
+	count := make([]uint64, len(coords))
+	for i, c := range coords {
+		start[i] = c * dw.chunkDims[i]
+		if c >= (dw.dims[i]+dw.chunkDims[i]-1)/dw.chunkDims[i] {
+			return fmt.Errorf("chunk %v is outside the dataset in dimension %d", coords, i)
+		}
+		count[i] = min(dw.chunkDims[i], dw.dims[i]-start[i])
+	}
+
+	return dw.WriteSlice(start, count, data)
+}
+
+// Append grows a dataset along its unlimited dimension and writes data to
+// the new part. Only the chunks holding appended elements are written; they
+// are inserted into the existing chunk B-tree.
+//
+// The dataset must have exactly one Unlimited maximum dimension. data holds a
+// whole number of slices across it (the product of the other dimensions),
+// flattened in row-major order.
+//
+// Example:
+//
+//	ds, _ := fw.CreateDataset("/samples", hdf5.Float64, []uint64{1, 3},
+//	    hdf5.WithChunkDims([]uint64{1024, 3}),
+//	    hdf5.WithMaxDims([]uint64{hdf5.Unlimited, 3}))
+//	ds.Write([]float64{0, 0, 0})
+//	ds.Append([]float64{1, 2, 3, 4, 5, 6}) // Now 3x3
+func (dw *DatasetWriter) Append(data interface{}) error {
+	if dw.chunkErr != nil {
+		return dw.chunkErr
+	}
+	unlimited := -1
+	for i, m := range dw.maxDims {
+		if m != Unlimited {
+			continue
+		}
+		if unlimited >= 0 {
+			return fmt.Errorf("append requires exactly one unlimited dimension, found %d and %d", unlimited, i)
+		}
+		unlimited = i
+	}
+	if !dw.isChunked || unlimited < 0 {
+		return fmt.Errorf("append requires a chunked dataset with an unlimited dimension")
+	}
+
+	n, err := sliceLength(data)
+	if err != nil {
+		return err
+	}
+	slice := uint64(1)
+	for i, d := range dw.dims {
+		if i != unlimited {
+			slice *= d
+		}
+	}
+	if slice == 0 || n%slice != 0 {
+		return fmt.Errorf("data length %d is not a multiple of %d elements per slice along dimension %d",
+			n, slice, unlimited)
+	}
+	if n == 0 {
+		return nil
+	}
+
+	// Encode before resizing so bad data does not grow the dataset.
+	buf, err := dw.encode(data, n*dw.elemSize)
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+
+	sel := &HyperslabSelection{
+		Start: make([]uint64, len(dw.dims)),
+		Count: append([]uint64{}, dw.dims...),
+	}
+	sel.Start[unlimited] = dw.dims[unlimited]
+	sel.Count[unlimited] = n / slice
+	fillHyperslabDefaults(sel, len(dw.dims))
+
+	newDims := append([]uint64{}, dw.dims...)
+	newDims[unlimited] += n / slice
+	if err := dw.Resize(newDims); err != nil {
+		return fmt.Errorf("failed to grow dataset: %w", err)
+	}
+
+	return dw.writeChunkedSelection(sel, buf)
+}
+
+// sliceLength returns the number of elements of a data slice.
+func sliceLength(data interface{}) (uint64, error) {
+	v := reflect.ValueOf(data)
+	if v.Kind() != reflect.Slice {
+		return 0, fmt.Errorf("data must be a slice, got %T", data)
+	}
+	return uint64(v.Len()), nil
+}
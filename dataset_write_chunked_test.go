@@ -179,6 +179,13 @@ func TestChunkedDataset_EdgeChunks(t *testing.T) {
 
 	err = fw.Close()
 	require.NoError(t, err)
+
+	// Edge chunks are stored full size and read back in place.
+	want := make([]float64, len(data))
+	for i, v := range data {
+		want[i] = float64(v)
+	}
+	require.Equal(t, want, readBack(t, filename, "/data"))
 }
 
 // TestChunkedDataset_SmallChunks tests many small chunks.
@@ -207,3 +214,143 @@ func TestChunkedDataset_SmallChunks(t *testing.T) {
 	err = fw.Close()
 	require.NoError(t, err)
 }
+
+func TestChunkedDataset_WriteChunk(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "write_chunk.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	// 5x5 with 2x3 chunks: a 3x2 chunk grid with edge chunks.
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{5, 5},
+		WithChunkDims([]uint64{2, 3}), WithFillValue(int32(-1)), WithGZIPCompression(6))
+	require.NoError(t, err)
+
+	require.NoError(t, ds.WriteChunk([]uint64{0, 0}, []int32{1, 2, 3, 4, 5, 6}))
+	require.NoError(t, ds.WriteChunk([]uint64{2, 1}, []int32{7, 8})) // 1x2 corner chunk
+	require.NoError(t, ds.WriteChunk([]uint64{1, 1}, []int32{9, 10, 11, 12}))
+
+	require.ErrorContains(t, ds.WriteChunk([]uint64{3, 0}, []int32{1, 2, 3}), "outside the dataset in dimension 0")
+	require.ErrorContains(t, ds.WriteChunk([]uint64{0}, []int32{1}), "chunk coordinate dimensions")
+	require.ErrorContains(t, ds.WriteChunk([]uint64{2, 1}, []int32{1, 2, 3}), "data size mismatch")
+	require.NoError(t, fw.Close())
+
+	require.Equal(t, []float64{
+		1, 2, 3, -1, -1,
+		4, 5, 6, -1, -1,
+		-1, -1, -1, 9, 10,
+		-1, -1, -1, 11, 12,
+		-1, -1, -1, 7, 8,
+	}, readBack(t, filename, "/data"))
+}
+
+func TestChunkedDataset_Append(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "append.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/samples", Float64, []uint64{1, 2},
+		WithChunkDims([]uint64{4, 2}), WithMaxDims([]uint64{Unlimited, 2}), WithGZIPCompression(1))
+	require.NoError(t, err)
+	want := []float64{0, 1}
+	require.NoError(t, ds.Write(want))
+
+	// 3 rows per append: appends straddle chunks, and 100 chunks need more
+	// than one B-tree node.
+	for i := 0; i < 133; i++ {
+		rows := make([]float64, 6)
+		for j := range rows {
+			rows[j] = float64(len(want) + j)
+		}
+		require.NoError(t, ds.Append(rows))
+		want = append(want, rows...)
+	}
+	require.NoError(t, ds.Append([]float64{}))
+	require.ErrorContains(t, ds.Append([]float64{1, 2, 3}), "not a multiple of 2 elements")
+	require.NoError(t, fw.Close())
+
+	require.Equal(t, want, readBack(t, filename, "/samples"))
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rd, err := f.OpenDataset("/samples")
+	require.NoError(t, err)
+	shape, err := rd.Shape()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{400, 2}, shape)
+}
+
+func TestChunkedDataset_AppendInvalid(t *testing.T) {
+	fw, err := CreateForWrite(filepath.Join(t.TempDir(), "append_invalid.h5"), CreateTruncate)
+	require.NoError(t, err)
+	defer func() { _ = fw.Close() }()
+
+	fixed, err := fw.CreateDataset("/fixed", Int32, []uint64{4}, WithChunkDims([]uint64{2}))
+	require.NoError(t, err)
+	require.ErrorContains(t, fixed.Append([]int32{1, 2}), "unlimited dimension")
+
+	both, err := fw.CreateDataset("/both", Int32, []uint64{2, 2},
+		WithChunkDims([]uint64{2, 2}), WithMaxDims([]uint64{Unlimited, Unlimited}))
+	require.NoError(t, err)
+	require.ErrorContains(t, both.Append([]int32{1, 2}), "exactly one unlimited dimension")
+}
+
+func TestChunkedDataset_AppendAfterReopen(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "append_reopen.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/samples", Int32, []uint64{2, 3},
+		WithChunkDims([]uint64{2, 3}), WithMaxDims([]uint64{Unlimited, 3}),
+		WithFillValue(int32(-1)), WithShuffle(), WithGZIPCompression(6))
+	require.NoError(t, err)
+	var want []int32
+	next := func(rows int) []int32 {
+		data := make([]int32, 3*rows)
+		for i := range data {
+			data[i] = int32(len(want) + i)
+		}
+		want = append(want, data...)
+		return data
+	}
+	require.NoError(t, ds.Write(next(2)))
+	// 70 chunks need more than one B-tree node.
+	for i := 0; i < 69; i++ {
+		require.NoError(t, ds.Append(next(2)))
+	}
+	require.NoError(t, fw.Close())
+
+	for session := 0; session < 2; session++ {
+		fw, err = OpenForWrite(filename, OpenReadWrite)
+		require.NoError(t, err)
+		ds, err = fw.OpenDataset("/samples")
+		require.NoError(t, err)
+
+		// Appends fill up the partial last chunk and add new ones.
+		for i := 0; i < 5; i++ {
+			require.NoError(t, ds.Append(next(3)))
+		}
+
+		// Existing chunks are read back, unfiltered, and rewritten.
+		require.NoError(t, ds.WriteChunk([]uint64{1, 0}, []int32{100, 101, 102, 103, 104, 105}))
+		copy(want[6:], []int32{100, 101, 102, 103, 104, 105})
+		require.NoError(t, ds.WriteSlice([]uint64{40, 1}, []uint64{1, 1}, []int32{200}))
+		want[40*3+1] = 200
+		require.NoError(t, fw.Close())
+
+		values := make([]float64, len(want))
+		for i, v := range want {
+			values[i] = float64(v)
+		}
+		require.Equal(t, values, readBack(t, filename, "/samples"))
+	}
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rd, err := f.OpenDataset("/samples")
+	require.NoError(t, err)
+	shape, err := rd.Shape()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{170, 3}, shape)
+}
@@ -0,0 +1,292 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// FillTime controls when the fill value is written into allocated storage.
+type FillTime uint8
+
+// Fill value write times.
+const (
+	FillTimeIfSet FillTime = iota // Write the fill value only if one was set (default).
+	FillTimeAlloc                 // Write the fill value (zero if not set) when storage is allocated.
+	FillTimeNever                 // Never write the fill value.
+)
+
+// AllocTime controls when storage space is allocated for a dataset.
+type AllocTime uint8
+
+// Storage allocation times.
+const (
+	AllocTimeDefault     AllocTime = iota // Early for contiguous, incremental for chunked datasets.
+	AllocTimeEarly                        // Allocate all storage when the dataset is created.
+	AllocTimeLate                         // Allocate storage when data is first written.
+	AllocTimeIncremental                  // Allocate chunks as they are written; late for contiguous datasets.
+)
+
+// WithFillValue sets the value of dataset elements that were never written.
+// Readers return the fill value for unallocated storage, such as chunks
+// beyond the written extent of a resized dataset.
+//
+// The value must have the dataset's element type: a scalar of the matching Go
+// type (e.g. int32 for Int32, string for String), a slice holding one array
+// element for Array datasets, or the raw encoded element as []byte.
+//
+// Example:
+//
+//	// Unwritten elements read back as -1
+//	ds, _ := fw.CreateDataset("/data", hdf5.Int32, []uint64{1000},
+//	    hdf5.WithChunkDims([]uint64{100}),
+//	    hdf5.WithFillValue(int32(-1)))
+func WithFillValue(v interface{}) DatasetOption {
+	return func(cfg *datasetConfig) {
+		cfg.fillValue = v
+	}
+}
+
+// WithFillTime sets when the fill value is written into allocated storage.
+// The default, FillTimeIfSet, writes it only if WithFillValue was given.
+//
+// Example:
+//
+//	// Allocate and zero-fill all storage at creation
+//	ds, _ := fw.CreateDataset("/data", hdf5.Float64, []uint64{1000},
+//	    hdf5.WithFillTime(hdf5.FillTimeAlloc))
+func WithFillTime(t FillTime) DatasetOption {
+	return func(cfg *datasetConfig) {
+		cfg.fillTime = t
+	}
+}
+
+// WithAllocTime sets when storage space is allocated for the dataset.
+//
+// Contiguous datasets allocate their storage at creation by default; with
+// AllocTimeLate the storage is allocated by the first Write. Chunked datasets
+// allocate chunks as they are written by default; with AllocTimeEarly every
+// chunk is allocated and filled at creation.
+//
+// Example:
+//
+//	// Allocate the data block on first write
+//	ds, _ := fw.CreateDataset("/data", hdf5.Int64, []uint64{1000},
+//	    hdf5.WithAllocTime(hdf5.AllocTimeLate))
+func WithAllocTime(t AllocTime) DatasetOption {
+	return func(cfg *datasetConfig) {
+		cfg.allocTime = t
+	}
+}
+
+// hasFillProperties reports whether any fill value option was given.
+func (cfg *datasetConfig) hasFillProperties() bool {
+	return cfg.fillValue != nil || cfg.fillTime != FillTimeIfSet || cfg.allocTime != AllocTimeDefault
+}
+
+// newFillValueMessage builds the fill value message for a dataset created
+// with cfg, or returns nil if no fill value option was given.
+//
+// dtype is the type used to encode written data (the base type for array
+// and enum datatypes), elemSize the size of one dataset element.
+//
+// Reference: H5Dint.c - H5D__init_type(), H5Pdcpl.c - H5P_fill_value_defined().
+func newFillValueMessage(cfg *datasetConfig, dtype *core.DatatypeMessage, elemSize uint64, chunked bool) (*core.FillValueMessage, error) {
+	if !cfg.hasFillProperties() {
+		return nil, nil
+	}
+
+	msg := &core.FillValueMessage{Version: 3, Defined: true}
+
+	switch cfg.fillTime {
+	case FillTimeIfSet:
+		msg.FillTime = core.FillTimeIfSet
+	case FillTimeAlloc:
+		msg.FillTime = core.FillTimeAlloc
+	case FillTimeNever:
+		msg.FillTime = core.FillTimeNever
+	default:
+		return nil, fmt.Errorf("invalid fill time: %d", cfg.fillTime)
+	}
+
+	// The message records the allocation time actually used.
+	switch cfg.allocTime {
+	case AllocTimeDefault:
+		msg.AllocTime = core.AllocTimeEarly
+		if chunked {
+			msg.AllocTime = core.AllocTimeIncremental
+		}
+	case AllocTimeEarly:
+		msg.AllocTime = core.AllocTimeEarly
+	case AllocTimeLate:
+		msg.AllocTime = core.AllocTimeLate
+	case AllocTimeIncremental:
+		msg.AllocTime = core.AllocTimeLate
+		if chunked {
+			msg.AllocTime = core.AllocTimeIncremental
+		}
+	default:
+		return nil, fmt.Errorf("invalid allocation time: %d", cfg.allocTime)
+	}
+
+	if cfg.fillValue != nil {
+		value, err := encodeFillValue(cfg.fillValue, dtype, elemSize)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fill value: %w", err)
+		}
+		msg.Value = value
+	}
+
+	return msg, nil
+}
+
+// encodeFillValue encodes a fill value as one dataset element.
+func encodeFillValue(v interface{}, dtype *core.DatatypeMessage, elemSize uint64) ([]byte, error) {
+	if dtype.Class == core.DatatypeVarLen {
+		return nil, fmt.Errorf("fill values are not supported for variable-length datasets")
+	}
+
+	// Raw element bytes are stored as given.
+	if raw, ok := v.([]byte); ok {
+		if uint64(len(raw)) != elemSize {
+			return nil, fmt.Errorf("size mismatch: expected %d bytes, got %d bytes", elemSize, len(raw))
+		}
+		return raw, nil
+	}
+
+	// Scalars are encoded as a one-element slice.
+	switch x := v.(type) {
+	case int8:
+		v = []int8{x}
+	case uint8:
+		v = []uint8{x}
+	case int16:
+		v = []int16{x}
+	case uint16:
+		v = []uint16{x}
+	case int32:
+		v = []int32{x}
+	case uint32:
+		v = []uint32{x}
+	case int64:
+		v = []int64{x}
+	case uint64:
+		v = []uint64{x}
+	case float32:
+		v = []float32{x}
+	case float64:
+		v = []float64{x}
+	case string:
+		v = []string{x}
+	}
+
+	return encodeData(v, dtype, elemSize)
+}
+
+// fillStorage returns the content of newly allocated storage of size bytes,
+// or nil if the fill time says allocated storage is left unwritten.
+func fillStorage(msg *core.FillValueMessage, size, elemSize uint64) []byte {
+	if msg == nil || msg.FillTime == core.FillTimeNever {
+		return nil
+	}
+	if msg.FillTime == core.FillTimeIfSet && len(msg.Value) == 0 {
+		return nil
+	}
+
+	buf := make([]byte, size)
+	core.FillBuffer(buf, msg.Pattern(elemSize))
+	return buf
+}
+
+// appendFillValueMessage adds the fill value message to a dataset object
+// header. A nil message adds nothing.
+func appendFillValueMessage(ohw *core.ObjectHeaderWriter, msg *core.FillValueMessage) error {
+	if msg == nil {
+		return nil
+	}
+
+	fillData, err := core.EncodeFillValueMessage(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode fill value: %w", err)
+	}
+
+	ohw.Messages = append(ohw.Messages, core.MessageWriter{
+		Type: core.MsgFillValue,
+		Data: fillData,
+	})
+	return nil
+}
+
+// allocateContiguous allocates the storage of a contiguous dataset and writes
+// the fill value into it as the fill time requires. With late allocation no
+// storage is allocated and the undefined address is returned.
+func (fw *FileWriter) allocateContiguous(dataSize, elemSize uint64, fillMsg *core.FillValueMessage) (uint64, error) {
+	if fillMsg != nil && fillMsg.AllocTime == core.AllocTimeLate {
+		return undefinedAddress, nil
+	}
+
+	dataAddress, err := fw.writer.AllocateRaw(dataSize)
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate space for data: %w", err)
+	}
+
+	if buf := fillStorage(fillMsg, dataSize, elemSize); buf != nil {
+		if err := fw.writer.WriteAtAddress(buf, dataAddress); err != nil {
+			return 0, fmt.Errorf("failed to write fill value: %w", err)
+		}
+	}
+
+	return dataAddress, nil
+}
+
+// contiguousLayoutAddressOffset returns the file offset of the data address
+// in the contiguous layout message of a dataset object header written by
+// CreateDataset, or 0 if the storage is already allocated. messagesAddress is
+// the address of the first message of the header.
+//
+// The layout message is the third message of the header, after the datatype
+// and dataspace messages (see createChunkedDataset for the header layout).
+// The address follows the version and class bytes of the layout data.
+func contiguousLayoutAddressOffset(messagesAddress uint64, datatypeData, dataspaceData []byte, dataAddress uint64) uint64 {
+	if dataAddress != undefinedAddress {
+		return 0
+	}
+
+	return messagesAddress +
+		4 + uint64(len(datatypeData)) + // datatype message
+		4 + uint64(len(dataspaceData)) + // dataspace message
+		4 + // layout message header
+		2 // offset to data address within layout data (version + class)
+}
+
+// allocateLateStorage allocates the storage of a contiguous dataset created
+// with late allocation and records its address in the layout message.
+func (dw *DatasetWriter) allocateLateStorage() error {
+	if dw.layoutDataOffset == 0 {
+		return nil
+	}
+
+	dataAddress, err := dw.fileWriter.writer.AllocateRaw(dw.dataSize)
+	if err != nil {
+		return fmt.Errorf("failed to allocate space for data: %w", err)
+	}
+
+	offsetSize := dw.fileWriter.file.sb.OffsetSize
+	addrBuf := make([]byte, offsetSize)
+	switch offsetSize {
+	case 8:
+		binary.LittleEndian.PutUint64(addrBuf, dataAddress)
+	case 4:
+		binary.LittleEndian.PutUint32(addrBuf, uint32(dataAddress)) //nolint:gosec // G115: Safe - address validated
+	default:
+		return fmt.Errorf("unsupported offset size: %d", offsetSize)
+	}
+	if err := dw.fileWriter.writer.WriteAtAddress(addrBuf, dw.layoutDataOffset); err != nil {
+		return fmt.Errorf("failed to update data address in layout message: %w", err)
+	}
+
+	dw.dataAddress = dataAddress
+	dw.layoutDataOffset = 0
+	return nil
+}
@@ -2,6 +2,7 @@ package hdf5
 
 import (
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -129,6 +130,42 @@ func TestChunkedDatasetWithFletcher32(t *testing.T) {
 	t.Logf("File size with Fletcher32: %d bytes", info.Size())
 }
 
+func TestChunkedDatasetWithLZF(t *testing.T) {
+	tmpFile := filepath.Join(t.TempDir(), "test_lzf.h5")
+
+	file, err := CreateForWrite(tmpFile, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := file.CreateDataset("/data", Int32, []uint64{1000},
+		WithChunkDims([]uint64{250}),
+		WithShuffle(),
+		WithLZFCompression())
+	require.NoError(t, err)
+
+	data := make([]int32, 1000)
+	for i := range data {
+		data[i] = int32(i % 50)
+	}
+	require.NoError(t, ds.Write(data))
+	require.NoError(t, file.Close())
+
+	f, err := Open(tmpFile)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	dset, err := f.OpenDataset("/data")
+	require.NoError(t, err)
+	filters, err := dset.Filters()
+	require.NoError(t, err)
+	require.Len(t, filters, 2)
+	require.Equal(t, FilterShuffle, filters[0].ID)
+	require.Equal(t, FilterLZF, filters[1].ID)
+
+	var got []int32
+	require.NoError(t, dset.ReadAs(&got))
+	require.Equal(t, data, got)
+}
+
 func TestChunkedDatasetWithAllFilters(t *testing.T) {
 	tmpFile := "test_all_filters.h5"
 	defer os.Remove(tmpFile)
@@ -648,3 +685,35 @@ func TestChunkedDatasetMixedValues(t *testing.T) {
 
 	t.Logf("Mixed values compression: %.2f:1", compressionRatio)
 }
+
+// TestChunkedDatasetLegacyFilterFormat reads a file written by earlier
+// versions of this library, which stored deflate data as gzip streams and
+// version 2 filter pipeline messages in the version 1 layout.
+func TestChunkedDatasetLegacyFilterFormat(t *testing.T) {
+	f, err := Open("testdata/legacy_filters.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	want := make([]float64, 100)
+	for i := range want {
+		want[i] = float64(i)
+	}
+	for _, path := range []string{"/gz", "/sgz"} { // gzip; shuffle, gzip and fletcher32
+		ds, err := f.OpenDataset(path)
+		require.NoError(t, err)
+		data, err := ds.Read()
+		require.NoError(t, err, path)
+		require.Equal(t, want, data, path)
+	}
+
+	ds, err := f.OpenDataset("/sgz")
+	require.NoError(t, err)
+	meta, err := ds.Meta()
+	require.NoError(t, err)
+	require.Len(t, meta.Filters, 3)
+	require.Equal(t, FilterShuffle, meta.Filters[0].ID)
+	require.Equal(t, []uint32{4}, meta.Filters[0].ClientData)
+	require.Equal(t, FilterGZIP, meta.Filters[1].ID)
+	require.Equal(t, []uint32{6}, meta.Filters[1].ClientData)
+	require.Equal(t, FilterFletcher32, meta.Filters[2].ID)
+}
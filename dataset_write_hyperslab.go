@@ -0,0 +1,243 @@
+package hdf5
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// WriteSlice writes a rectangular block of the dataset using simple start/count
+// parameters. It is the write counterpart of Dataset.ReadSlice and lets large
+// datasets be written piece by piece instead of holding all data in memory.
+//
+// Parameters:
+//   - start: Starting coordinates in each dimension (0-based)
+//   - count: Number of elements to write in each dimension
+//   - data: count[0]*count[1]*... values, flattened in row-major order
+//
+// Elements outside the block keep their current values.
+//
+// Example (2D dataset):
+//
+//	// Write rows 100-149 of a 1000x200 dataset
+//	err := ds.WriteSlice([]uint64{100, 0}, []uint64{50, 200}, rows)
+func (dw *DatasetWriter) WriteSlice(start, count []uint64, data interface{}) error {
+	return dw.WriteHyperslab(&HyperslabSelection{Start: start, Count: count}, data)
+}
+
+// WriteHyperslab writes data to a hyperslab selection including stride and
+// block. It is the write counterpart of Dataset.ReadHyperslab.
+//
+// Parameters:
+//   - selection: The hyperslab selection specification
+//   - data: One value per selected element, in row-major order of the
+//     selection (Count[i]*Block[i] elements along dimension i)
+//
+// For contiguous datasets the selected elements are written in place. For
+// chunked datasets every chunk the selection touches is read, unfiltered,
+// updated, filtered again and written back; chunks not written before start
+// out as the fill value.
+//
+// Example (write every 2nd element of row 0):
+//
+//	sel := &HyperslabSelection{
+//	    Start:  []uint64{0, 0},
+//	    Count:  []uint64{1, 50},
+//	    Stride: []uint64{1, 2},
+//	}
+//	err := ds.WriteHyperslab(sel, values)
+func (dw *DatasetWriter) WriteHyperslab(selection *HyperslabSelection, data interface{}) error {
+	if dw.dtype.Class == core.DatatypeVarLen {
+		return fmt.Errorf("partial writes are not supported for variable-length data")
+	}
+
+	if err := validateHyperslabSelection(selection, dw.dims); err != nil {
+		return fmt.Errorf("invalid selection: %w", err)
+	}
+	for i := range selection.Count {
+		if selection.Count[i] > 1 && selection.Stride[i] < selection.Block[i] {
+			return fmt.Errorf("invalid selection: blocks overlap in dimension %d (stride %d < block %d)",
+				i, selection.Stride[i], selection.Block[i])
+		}
+	}
+
+	size := calculateHyperslabOutputSize(selection) * dw.elemSize
+	buf, err := dw.encode(data, size)
+	if err != nil {
+		return fmt.Errorf("failed to encode data: %w", err)
+	}
+	if uint64(len(buf)) != size {
+		return fmt.Errorf("data size mismatch: expected %d bytes, got %d bytes", size, len(buf))
+	}
+
+	switch {
+	case dw.isChunked:
+		return dw.writeChunkedSelection(selection, buf)
+	case dw.layoutClass == core.LayoutContiguous:
+		return dw.writeContiguousSelection(selection, buf)
+	case dw.chunkErr != nil:
+		return dw.chunkErr
+	default:
+		return fmt.Errorf("partial writes are not supported for %s layout", LayoutClass(dw.layoutClass))
+	}
+}
+
+// writeContiguousSelection writes the elements of a validated selection to
+// contiguous storage, one file write per run of adjacent elements.
+func (dw *DatasetWriter) writeContiguousSelection(sel *HyperslabSelection, buf []byte) error {
+	if err := dw.allocateLateStorage(); err != nil {
+		return err
+	}
+
+	strides := make([]uint64, len(dw.dims))
+	strides[len(strides)-1] = 1
+	for i := len(strides) - 2; i >= 0; i-- {
+		strides[i] = strides[i+1] * dw.dims[i+1]
+	}
+
+	elemSize := dw.elemSize
+	region := newSlabRegion(hyperslabAxes(sel), make([]uint64, len(dw.dims)), dw.dims)
+
+	var err error
+	region.forEachRun(func(bufIndex uint64, rel []uint64, n uint64) {
+		if err != nil {
+			return
+		}
+		var off uint64
+		for i, r := range rel {
+			off += r * strides[i]
+		}
+		run := buf[bufIndex*elemSize : (bufIndex+n)*elemSize]
+		if werr := dw.fileWriter.writer.WriteAtAddress(run, dw.dataAddress+off*elemSize); werr != nil {
+			err = fmt.Errorf("failed to write data: %w", werr)
+		}
+	})
+	return err
+}
+
+// slabAxis describes the coordinates a hyperslab selects along one dimension.
+// Position p (0 <= p < size) of the selection's data maps to coordinate
+// start + (p/block)*stride + p%block; coordinates grow with p because blocks
+// do not overlap.
+type slabAxis struct {
+	start, stride, block, size uint64
+}
+
+// hyperslabAxes returns the axes of a validated selection.
+func hyperslabAxes(sel *HyperslabSelection) []slabAxis {
+	axes := make([]slabAxis, len(sel.Start))
+	for i := range axes {
+		axes[i] = slabAxis{
+			start:  sel.Start[i],
+			stride: sel.Stride[i],
+			block:  sel.Block[i],
+			size:   sel.Count[i] * sel.Block[i],
+		}
+	}
+	return axes
+}
+
+func (a slabAxis) coord(p uint64) uint64 {
+	return a.start + (p/a.block)*a.stride + p%a.block
+}
+
+// span returns the positions [first, last) whose coordinates lie in [lo, hi).
+func (a slabAxis) span(lo, hi uint64) (first, last uint64) {
+	n := int(a.size) //nolint:gosec // G115: selection size is validated against the dataset extent
+	first = uint64(sort.Search(n, func(p int) bool { return a.coord(uint64(p)) >= lo }))
+	last = uint64(sort.Search(n, func(p int) bool { return a.coord(uint64(p)) >= hi }))
+	return first, last
+}
+
+// slabRegion is the part of a hyperslab selection inside the box [lo, hi).
+type slabRegion struct {
+	axes        []slabAxis
+	lo          []uint64
+	first, last []uint64 // Selected positions per dimension
+}
+
+func newSlabRegion(axes []slabAxis, lo, hi []uint64) *slabRegion {
+	r := &slabRegion{
+		axes:  axes,
+		lo:    lo,
+		first: make([]uint64, len(axes)),
+		last:  make([]uint64, len(axes)),
+	}
+	for i, a := range axes {
+		r.first[i], r.last[i] = a.span(lo[i], hi[i])
+	}
+	return r
+}
+
+// empty reports whether no selected element lies inside the box.
+func (r *slabRegion) empty() bool {
+	for i := range r.axes {
+		if r.first[i] == r.last[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// covers reports whether every element of the box [lo, hi) is selected.
+func (r *slabRegion) covers(lo, hi []uint64) bool {
+	for i := range r.axes {
+		if r.last[i]-r.first[i] != hi[i]-lo[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// forEachRun visits the selected elements inside the box in row-major order,
+// grouped into runs adjacent along the last dimension. For each run fn gets
+// the index of its first element in the selection's data, the coordinates of
+// that element relative to lo, and the run length.
+func (r *slabRegion) forEachRun(fn func(bufIndex uint64, rel []uint64, n uint64)) {
+	if r.empty() {
+		return
+	}
+
+	ndims := len(r.axes)
+	bufStrides := make([]uint64, ndims)
+	bufStrides[ndims-1] = 1
+	for i := ndims - 2; i >= 0; i-- {
+		bufStrides[i] = bufStrides[i+1] * r.axes[i+1].size
+	}
+
+	pos := append([]uint64{}, r.first...)
+	rel := make([]uint64, ndims)
+	inner := r.axes[ndims-1]
+	for {
+		var base uint64
+		for i := 0; i < ndims-1; i++ {
+			base += pos[i] * bufStrides[i]
+			rel[i] = r.axes[i].coord(pos[i]) - r.lo[i]
+		}
+
+		// Split the innermost span into runs of consecutive coordinates.
+		for p := r.first[ndims-1]; p < r.last[ndims-1]; {
+			n := uint64(1)
+			for p+n < r.last[ndims-1] && inner.coord(p+n) == inner.coord(p)+n {
+				n++
+			}
+			rel[ndims-1] = inner.coord(p) - r.lo[ndims-1]
+			fn(base+p, rel, n)
+			p += n
+		}
+
+		// Advance the outer positions like an odometer.
+		d := ndims - 2
+		for ; d >= 0; d-- {
+			pos[d]++
+			if pos[d] < r.last[d] {
+				break
+			}
+			pos[d] = r.first[d]
+		}
+		if d < 0 {
+			return
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package hdf5
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readBack reads dataset path of a file as float64 values.
+func readBack(t *testing.T, filename, path string) []float64 {
+	t.Helper()
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset(path)
+	require.NoError(t, err)
+	data, err := ds.Read()
+	require.NoError(t, err)
+	return data
+}
+
+func TestWriteSlice_Contiguous(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "slice.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{3, 4})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(make([]int32, 12)))
+
+	require.NoError(t, ds.WriteSlice([]uint64{1, 1}, []uint64{2, 2}, []int32{1, 2, 3, 4}))
+	require.NoError(t, ds.WriteSlice([]uint64{0, 3}, []uint64{1, 1}, []int32{9}))
+	require.NoError(t, fw.Close())
+
+	require.Equal(t, []float64{
+		0, 0, 0, 9,
+		0, 1, 2, 0,
+		0, 3, 4, 0,
+	}, readBack(t, filename, "/data"))
+}
+
+func TestWriteHyperslab_ContiguousStrided(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "strided.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	// Late allocation: the storage is allocated by the first partial write.
+	ds, err := fw.CreateDataset("/data", Float64, []uint64{4, 6}, WithFillValue(float64(-1)))
+	require.NoError(t, err)
+
+	// 2x2 blocks of 1x2 elements: rows 0 and 2, columns 1-2 and 4-5.
+	err = ds.WriteHyperslab(&HyperslabSelection{
+		Start:  []uint64{0, 1},
+		Count:  []uint64{2, 2},
+		Stride: []uint64{2, 3},
+		Block:  []uint64{1, 2},
+	}, []float64{1, 2, 3, 4, 5, 6, 7, 8})
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	require.Equal(t, []float64{
+		-1, 1, 2, -1, 3, 4,
+		-1, -1, -1, -1, -1, -1,
+		-1, 5, 6, -1, 7, 8,
+		-1, -1, -1, -1, -1, -1,
+	}, readBack(t, filename, "/data"))
+}
+
+func TestWriteSlice_Chunked(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []DatasetOption
+	}{
+		{"plain", nil},
+		{"gzip", []DatasetOption{WithGZIPCompression(6)}},
+		{"shuffle gzip", []DatasetOption{WithShuffle(), WithGZIPCompression(6)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filename := filepath.Join(t.TempDir(), "chunked.h5")
+			fw, err := CreateForWrite(filename, CreateTruncate)
+			require.NoError(t, err)
+
+			// 5x7 with 2x3 chunks: edge chunks in both dimensions.
+			opts := append([]DatasetOption{WithChunkDims([]uint64{2, 3}), WithFillValue(int32(-1))}, tt.opts...)
+			ds, err := fw.CreateDataset("/data", Int32, []uint64{5, 7}, opts...)
+			require.NoError(t, err)
+
+			// Rows 1-3, columns 2-6: partially covers six chunks.
+			block := make([]int32, 15)
+			for i := range block {
+				block[i] = int32(i + 1)
+			}
+			require.NoError(t, ds.WriteSlice([]uint64{1, 2}, []uint64{3, 5}, block))
+
+			// Overwrite part of the block again (read-modify-write of existing chunks).
+			require.NoError(t, ds.WriteSlice([]uint64{2, 1}, []uint64{1, 3}, []int32{100, 101, 102}))
+			require.NoError(t, fw.Close())
+
+			require.Equal(t, []float64{
+				-1, -1, -1, -1, -1, -1, -1,
+				-1, -1, 1, 2, 3, 4, 5,
+				-1, 100, 101, 102, 8, 9, 10,
+				-1, -1, 11, 12, 13, 14, 15,
+				-1, -1, -1, -1, -1, -1, -1,
+			}, readBack(t, filename, "/data"))
+		})
+	}
+}
+
+func TestWriteSlice_ChunkedAfterWrite(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "rmw.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{10}, WithChunkDims([]uint64{3}), WithGZIPCompression(1))
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}))
+
+	err = ds.WriteHyperslab(&HyperslabSelection{
+		Start:  []uint64{1},
+		Count:  []uint64{3},
+		Stride: []uint64{4},
+	}, []int32{-1, -5, -9})
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	require.Equal(t, []float64{0, -1, 2, 3, 4, -5, 6, 7, 8, -9}, readBack(t, filename, "/data"))
+}
+
+func TestWriteSlice_Append(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "append.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{2, 2},
+		WithChunkDims([]uint64{2, 2}), WithMaxDims([]uint64{Unlimited, 2}))
+	require.NoError(t, err)
+
+	// Grow the dataset two rows at a time, writing only the new rows.
+	for i := uint64(0); i < 4; i++ {
+		if i > 0 {
+			require.NoError(t, ds.Resize([]uint64{2 * (i + 1), 2}))
+		}
+		v := int32(i * 10)
+		require.NoError(t, ds.WriteSlice([]uint64{2 * i, 0}, []uint64{2, 2}, []int32{v, v + 1, v + 2, v + 3}))
+	}
+	require.NoError(t, fw.Close())
+
+	require.Equal(t, []float64{
+		0, 1, 2, 3,
+		10, 11, 12, 13,
+		20, 21, 22, 23,
+		30, 31, 32, 33,
+	}, readBack(t, filename, "/data"))
+}
+
+func TestWriteHyperslab_Invalid(t *testing.T) {
+	fw, err := CreateForWrite(filepath.Join(t.TempDir(), "invalid.h5"), CreateTruncate)
+	require.NoError(t, err)
+	defer func() { _ = fw.Close() }()
+
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{4, 4})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		sel  *HyperslabSelection
+		data interface{}
+		want string
+	}{
+		{
+			name: "out of bounds",
+			sel:  &HyperslabSelection{Start: []uint64{3, 0}, Count: []uint64{2, 1}},
+			data: []int32{1, 2},
+			want: "exceeds dataset bounds",
+		},
+		{
+			name: "rank mismatch",
+			sel:  &HyperslabSelection{Start: []uint64{0}, Count: []uint64{1}},
+			data: []int32{1},
+			want: "start dimensions (1) != dataset dimensions (2)",
+		},
+		{
+			name: "size mismatch",
+			sel:  &HyperslabSelection{Start: []uint64{0, 0}, Count: []uint64{2, 2}},
+			data: []int32{1, 2, 3},
+			want: "data size mismatch",
+		},
+		{
+			name: "overlapping blocks",
+			sel:  &HyperslabSelection{Start: []uint64{0, 0}, Count: []uint64{1, 2}, Stride: []uint64{1, 1}, Block: []uint64{1, 2}},
+			data: []int32{1, 2, 3, 4},
+			want: "blocks overlap in dimension 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.ErrorContains(t, ds.WriteHyperslab(tt.sel, tt.data), tt.want)
+		})
+	}
+}
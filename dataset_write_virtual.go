@@ -0,0 +1,306 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// VirtualMapping maps a region of a source dataset into a virtual dataset.
+//
+// The elements SourceSelection selects in dataset SourceDataset of file
+// SourceFile appear at the elements VirtualSelection selects in the virtual
+// dataset; both selections are iterated in row-major order. A nil
+// SourceSelection selects the whole source dataset. SourceFile is resolved
+// relative to the virtual dataset's file when reading (see
+// WithVirtualPrefix); "." names the virtual dataset's own file.
+//
+// One dimension of each selection may have an Unlimited count (or block):
+//   - With both selections unlimited, the virtual dataset grows with the
+//     source dataset.
+//   - With only the virtual selection unlimited, the source names are
+//     printf-style: "%b" is replaced by the block number, so source n fills
+//     block n of the virtual selection. Reading stops at the first missing
+//     source. Use "%%" for a literal "%".
+type VirtualMapping struct {
+	VirtualSelection *HyperslabSelection
+	SourceFile       string
+	SourceDataset    string
+	SourceSelection  *HyperslabSelection
+}
+
+// CreateVirtualDataset creates a virtual dataset (VDS) whose elements are
+// read from other datasets, without copying any data. Elements not covered
+// by a mapping, or whose source is missing, read as the fill value.
+//
+// Supported options are WithMaxDims, which dimensions mapped by unlimited
+// selections require, and the fill value options. Source datasets must have
+// the class and size of dtype when the virtual dataset is read.
+//
+// Example:
+//
+//	// Stack /data of run-0.h5, run-1.h5, ... (each 100x64) along rows.
+//	err := fw.CreateVirtualDataset("/all_runs", hdf5.Float32, []uint64{0, 64},
+//	    []hdf5.VirtualMapping{{
+//	        VirtualSelection: &hdf5.HyperslabSelection{
+//	            Start:  []uint64{0, 0},
+//	            Count:  []uint64{hdf5.Unlimited, 1},
+//	            Stride: []uint64{100, 1},
+//	            Block:  []uint64{100, 64},
+//	        },
+//	        SourceFile:    "run-%b.h5",
+//	        SourceDataset: "/data",
+//	    }},
+//	    hdf5.WithMaxDims([]uint64{hdf5.Unlimited, 64}))
+//
+// Reference: H5Pdcpl.c - H5Pset_virtual(), H5Dvirtual.c.
+func (fw *FileWriter) CreateVirtualDataset(name string, dtype Datatype, dims []uint64, mappings []VirtualMapping, opts ...DatasetOption) error {
+	if err := validateDatasetName(name); err != nil {
+		return err
+	}
+	if len(dims) == 0 {
+		return fmt.Errorf("dimensions cannot be empty (use []uint64{1} for scalar)")
+	}
+
+	config := &datasetConfig{}
+	for _, opt := range opts {
+		opt(config)
+	}
+	if len(config.chunkDims) > 0 || config.pipeline != nil {
+		return errors.New("virtual datasets cannot be chunked or filtered")
+	}
+
+	maxDims := config.maxDims
+	if len(maxDims) == 0 {
+		maxDims = dims
+	}
+	if len(maxDims) != len(dims) {
+		return fmt.Errorf("maxDims length (%d) must match dims length (%d)", len(maxDims), len(dims))
+	}
+	for i, dim := range dims {
+		if maxDims[i] != Unlimited && maxDims[i] < dim {
+			return fmt.Errorf("maxDims[%d] (%d) must be >= dims[%d] (%d)", i, maxDims[i], i, dim)
+		}
+		if dim == 0 && maxDims[i] != Unlimited {
+			return fmt.Errorf("dimension %d cannot be 0", i)
+		}
+	}
+
+	coreMappings := make([]core.VirtualMapping, len(mappings))
+	for i := range mappings {
+		m, err := encodeVirtualMapping(&mappings[i], maxDims)
+		if err != nil {
+			return fmt.Errorf("mapping %d: %w", i, err)
+		}
+		coreMappings[i] = *m
+	}
+
+	dtInfo, err := getDatatypeInfo(dtype, config)
+	if err != nil {
+		return fmt.Errorf("invalid datatype: %w", err)
+	}
+	fillType := &core.DatatypeMessage{Class: dtInfo.class, Version: 1, Size: dtInfo.size}
+	if dtInfo.baseType != nil {
+		fillType = &core.DatatypeMessage{Class: dtInfo.baseType.class, Version: 1, Size: dtInfo.baseType.size}
+	}
+
+	// Virtual datasets have no storage of their own; like chunked
+	// datasets, they default to incremental allocation.
+	fillMsg, err := newFillValueMessage(config, fillType, uint64(dtInfo.size), true)
+	if err != nil {
+		return err
+	}
+
+	// The mapping list is stored in the global heap.
+	mappingData, err := core.EncodeVirtualMappings(coreMappings, fw.file.sb)
+	if err != nil {
+		return fmt.Errorf("failed to encode virtual mappings: %w", err)
+	}
+	heapID, err := fw.globalHeapWriter.WriteToGlobalHeap(mappingData)
+	if err != nil {
+		return fmt.Errorf("failed to write virtual mappings: %w", err)
+	}
+
+	datatypeData, err := datatypeRegistry[dtype].EncodeDatatypeMessage(dtInfo)
+	if err != nil {
+		return fmt.Errorf("failed to encode datatype: %w", err)
+	}
+	dataspaceData, err := core.EncodeDataspaceMessage(dims, config.maxDims)
+	if err != nil {
+		return fmt.Errorf("failed to encode dataspace: %w", err)
+	}
+	layoutData := core.EncodeVirtualLayoutMessage(heapID.CollectionAddress, uint32(heapID.ObjectIndex), fw.file.sb)
+
+	ohw := &core.ObjectHeaderWriter{
+		Version: 2,
+		Flags:   0,
+		Messages: []core.MessageWriter{
+			{Type: core.MsgDatatype, Data: datatypeData},
+			{Type: core.MsgDataspace, Data: dataspaceData},
+			{Type: core.MsgDataLayout, Data: layoutData},
+		},
+	}
+	if err := appendFillValueMessage(ohw, fillMsg); err != nil {
+		return err
+	}
+
+	headerSize, err := calculateObjectHeaderSize(ohw)
+	if err != nil {
+		return fmt.Errorf("failed to calculate header size: %w", err)
+	}
+	headerAddress, err := fw.writer.AllocateObjectHeader(headerSize)
+	if err != nil {
+		return fmt.Errorf("failed to allocate space for object header: %w", err)
+	}
+	writtenSize, err := ohw.WriteTo(fw.writer, headerAddress)
+	if err != nil {
+		return fmt.Errorf("failed to write object header: %w", err)
+	}
+	if writtenSize != headerSize {
+		return fmt.Errorf("header size mismatch: expected %d, wrote %d", headerSize, writtenSize)
+	}
+
+	parent, datasetName := parsePath(name)
+	if err := fw.linkToParent(parent, datasetName, headerAddress); err != nil {
+		return fmt.Errorf("failed to link dataset to parent: %w", err)
+	}
+	return nil
+}
+
+// encodeVirtualMapping checks a mapping against the maximum dimensions of
+// the virtual dataset and converts it to its stored form.
+// Reference: H5Pdcpl.c - H5Pset_virtual(), H5Dvirtual.c - H5D_virtual_check_mapping_pre/post().
+func encodeVirtualMapping(m *VirtualMapping, maxDims []uint64) (*core.VirtualMapping, error) {
+	if m.VirtualSelection == nil {
+		return nil, errors.New("virtual selection is required")
+	}
+	if m.SourceFile == "" || m.SourceDataset == "" {
+		return nil, errors.New("source file and dataset names are required")
+	}
+
+	vsel, err := virtualHyperslab(m.VirtualSelection)
+	if err != nil {
+		return nil, fmt.Errorf("virtual selection: %w", err)
+	}
+	if vsel.Rank != len(maxDims) {
+		return nil, fmt.Errorf("virtual selection rank %d does not match dataset rank %d", vsel.Rank, len(maxDims))
+	}
+	vdim := vsel.UnlimitedDim()
+	for i, bound := range vsel.Bounds() {
+		switch {
+		case i == vdim && maxDims[i] != Unlimited:
+			return nil, fmt.Errorf("unlimited virtual selection in dimension %d requires an unlimited maximum dimension", i)
+		case i != vdim && maxDims[i] != Unlimited && bound > maxDims[i]:
+			return nil, fmt.Errorf("virtual selection exceeds maximum dimension %d (%d > %d)", i, bound, maxDims[i])
+		}
+	}
+
+	ssel := &core.Selection{Type: core.SelectionAll}
+	if m.SourceSelection != nil {
+		if ssel, err = virtualHyperslab(m.SourceSelection); err != nil {
+			return nil, fmt.Errorf("source selection: %w", err)
+		}
+	}
+	sdim := ssel.UnlimitedDim()
+
+	// Unlimited mappings must match the number of elements outside the
+	// unlimited dimension; a printf-style mapping maps one block.
+	printf := vdim >= 0 && sdim < 0
+	switch {
+	case vdim < 0 && sdim >= 0:
+		return nil, errors.New("unlimited source selection requires an unlimited virtual selection")
+	case printf && !core.VirtualNameHasBlock(m.SourceFile) && !core.VirtualNameHasBlock(m.SourceDataset):
+		return nil, errors.New(`unlimited virtual selection with a bounded source selection requires "%b" in the source names`)
+	case printf && vsel.Regular[vdim].Count != core.SelectionUnlimited:
+		return nil, errors.New("printf-style mapping requires an unlimited count in the virtual selection")
+	}
+	if ssel.Type != core.SelectionAll {
+		vn, vok := virtualSelectionSize(vsel, vdim, printf)
+		sn, sok := virtualSelectionSize(ssel, sdim, false)
+		if vok && sok && vn != sn {
+			return nil, fmt.Errorf("virtual selection has %d elements, source selection %d", vn, sn)
+		}
+	}
+
+	return &core.VirtualMapping{
+		SourceFile:       m.SourceFile,
+		SourceDataset:    m.SourceDataset,
+		SourceSelection:  ssel,
+		VirtualSelection: vsel,
+	}, nil
+}
+
+// virtualHyperslab converts a hyperslab selection of a virtual dataset
+// mapping. At most one dimension may have an Unlimited count or block.
+func virtualHyperslab(sel *HyperslabSelection) (*core.Selection, error) {
+	rank := len(sel.Start)
+	if rank == 0 {
+		return nil, errors.New("selection cannot be empty")
+	}
+	if err := validateSelectionDimensions(sel, rank); err != nil {
+		return nil, err
+	}
+
+	result := &core.Selection{Type: core.SelectionHyperslabs, Rank: rank, Regular: make([]core.HyperslabDim, rank)}
+	unlimited := -1
+	for i := range sel.Start {
+		dim := core.HyperslabDim{Start: sel.Start[i], Stride: 1, Count: sel.Count[i], Block: 1}
+		if sel.Stride != nil {
+			dim.Stride = sel.Stride[i]
+		}
+		if sel.Block != nil {
+			dim.Block = sel.Block[i]
+		}
+
+		if dim.Count == 0 || dim.Block == 0 {
+			return nil, fmt.Errorf("count and block must be > 0 in dimension %d", i)
+		}
+		if dim.Count == Unlimited || dim.Block == Unlimited {
+			if unlimited >= 0 || (dim.Count == Unlimited && dim.Block == Unlimited) {
+				return nil, errors.New("only one count or block may be unlimited")
+			}
+			unlimited = i
+		}
+		if dim.Count == 1 && dim.Stride == 0 {
+			dim.Stride = 1
+		}
+		if dim.Count > 1 && dim.Stride < dim.Block {
+			return nil, fmt.Errorf("blocks overlap in dimension %d (stride %d < block %d)", i, dim.Stride, dim.Block)
+		}
+		if dim.Count != Unlimited && dim.Block != Unlimited {
+			hi, lo := bits.Mul64(dim.Count-1, dim.Stride)
+			if hi != 0 || lo+dim.Block < lo || dim.Start+lo+dim.Block < dim.Start {
+				return nil, fmt.Errorf("selection overflows in dimension %d", i)
+			}
+		}
+		result.Regular[i] = dim
+	}
+	return result, nil
+}
+
+// virtualSelectionSize returns the number of elements a regular selection
+// selects outside its unlimited dimension udim (-1 if none); for a block of a
+// printf-style mapping the unlimited dimension counts one block. It reports
+// false if the count is not known.
+func virtualSelectionSize(sel *core.Selection, udim int, block bool) (uint64, bool) {
+	n := uint64(1)
+	for i, dim := range sel.Regular {
+		count := dim.Count
+		if i == udim {
+			if !block {
+				continue
+			}
+			count = 1
+		}
+		for _, f := range []uint64{count, dim.Block} {
+			hi, lo := bits.Mul64(n, f)
+			if hi != 0 {
+				return 0, false
+			}
+			n = lo
+		}
+	}
+	return n, true
+}
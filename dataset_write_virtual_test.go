@@ -0,0 +1,256 @@
+package hdf5
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeVirtualSource writes an int32 dataset of the given shape holding
+// first, first+1, ... to a new file in dir.
+func writeVirtualSource(t *testing.T, dir, file, path string, dims []uint64, first int32, opts ...DatasetOption) {
+	t.Helper()
+	fw, err := CreateForWrite(filepath.Join(dir, file), CreateTruncate)
+	require.NoError(t, err)
+
+	ds, err := fw.CreateDataset(path, Int32, dims, opts...)
+	require.NoError(t, err)
+	data := make([]int32, calculateTotalElements(dims))
+	for i := range data {
+		data[i] = first + int32(i)
+	}
+	require.NoError(t, ds.Write(data))
+	require.NoError(t, fw.Close())
+}
+
+// readVirtual reads dataset path of a file and returns its shape and data.
+func readVirtual(t *testing.T, filename, path string) ([]uint64, []float64) {
+	t.Helper()
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset(path)
+	require.NoError(t, err)
+	meta, err := ds.Meta()
+	require.NoError(t, err)
+	require.Equal(t, LayoutVirtual, meta.Layout)
+
+	data, err := ds.Read()
+	require.NoError(t, err)
+	return meta.Shape, data
+}
+
+func TestCreateVirtualDataset(t *testing.T) {
+	dir := t.TempDir()
+	writeVirtualSource(t, dir, "a.h5", "/data", []uint64{2, 3}, 0)
+	writeVirtualSource(t, dir, "b.h5", "/data", []uint64{2, 3}, 100)
+
+	filename := filepath.Join(dir, "vds.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	src, err := fw.CreateDataset("/local", Int32, []uint64{6})
+	require.NoError(t, err)
+	require.NoError(t, src.Write([]int32{-10, -20, -30, -40, -50, -60}))
+
+	rows := func(start uint64) *HyperslabSelection {
+		return &HyperslabSelection{Start: []uint64{start, 0}, Count: []uint64{2, 3}}
+	}
+	err = fw.CreateVirtualDataset("/vds", Int32, []uint64{6, 3}, []VirtualMapping{
+		{VirtualSelection: rows(0), SourceFile: "a.h5", SourceDataset: "/data"},
+		// Only the last row of b.h5.
+		{
+			VirtualSelection: &HyperslabSelection{Start: []uint64{2, 0}, Count: []uint64{1, 3}},
+			SourceFile:       "b.h5",
+			SourceDataset:    "/data",
+			SourceSelection:  &HyperslabSelection{Start: []uint64{1, 0}, Count: []uint64{1, 3}},
+		},
+		// Every second element of /local into column 1.
+		{
+			VirtualSelection: &HyperslabSelection{Start: []uint64{3, 1}, Count: []uint64{3, 1}},
+			SourceFile:       ".",
+			SourceDataset:    "/local",
+			SourceSelection:  &HyperslabSelection{Start: []uint64{0}, Count: []uint64{3}, Stride: []uint64{2}},
+		},
+	}, WithFillValue(int32(-1)))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	shape, data := readVirtual(t, filename, "/vds")
+	require.Equal(t, []uint64{6, 3}, shape)
+	require.Equal(t, []float64{
+		0, 1, 2,
+		3, 4, 5,
+		103, 104, 105,
+		-1, -10, -1,
+		-1, -30, -1,
+		-1, -50, -1,
+	}, data)
+}
+
+func TestCreateVirtualDataset_Printf(t *testing.T) {
+	dir := t.TempDir()
+	writeVirtualSource(t, dir, "run-0.h5", "/data", []uint64{2, 3}, 0)
+	writeVirtualSource(t, dir, "run-1.h5", "/data", []uint64{2, 3}, 10)
+	writeVirtualSource(t, dir, "run-2.h5", "/data", []uint64{2, 3}, 20)
+
+	filename := filepath.Join(dir, "vds.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	// Rows of consecutive runs, leaving a gap row between runs.
+	err = fw.CreateVirtualDataset("/runs", Int32, []uint64{0, 3}, []VirtualMapping{{
+		VirtualSelection: &HyperslabSelection{
+			Start:  []uint64{0, 0},
+			Count:  []uint64{Unlimited, 1},
+			Stride: []uint64{3, 1},
+			Block:  []uint64{2, 3},
+		},
+		SourceFile:    "run-%b.h5",
+		SourceDataset: "/data",
+	}}, WithMaxDims([]uint64{Unlimited, 3}))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	shape, data := readVirtual(t, filename, "/runs")
+	require.Equal(t, []uint64{8, 3}, shape)
+	require.Equal(t, []float64{
+		0, 1, 2, 3, 4, 5, 0, 0, 0,
+		10, 11, 12, 13, 14, 15, 0, 0, 0,
+		20, 21, 22, 23, 24, 25,
+	}, data)
+}
+
+func TestCreateVirtualDataset_Unlimited(t *testing.T) {
+	dir := t.TempDir()
+	writeVirtualSource(t, dir, "src.h5", "/data", []uint64{3, 2}, 1,
+		WithChunkDims([]uint64{3, 2}), WithMaxDims([]uint64{Unlimited, 2}))
+
+	filename := filepath.Join(dir, "vds.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	// The virtual dataset grows with the source, offset by one row.
+	err = fw.CreateVirtualDataset("/vds", Int32, []uint64{1, 2}, []VirtualMapping{{
+		VirtualSelection: &HyperslabSelection{Start: []uint64{1, 0}, Count: []uint64{1, 1}, Block: []uint64{Unlimited, 2}},
+		SourceFile:       "src.h5",
+		SourceDataset:    "/data",
+		SourceSelection:  &HyperslabSelection{Start: []uint64{0, 0}, Count: []uint64{1, 1}, Block: []uint64{Unlimited, 2}},
+	}}, WithMaxDims([]uint64{Unlimited, 2}))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	shape, data := readVirtual(t, filename, "/vds")
+	require.Equal(t, []uint64{4, 2}, shape)
+	require.Equal(t, []float64{0, 0, 1, 2, 3, 4, 5, 6}, data)
+}
+
+func TestCreateVirtualDataset_Invalid(t *testing.T) {
+	slab := func(start, count []uint64) *HyperslabSelection {
+		return &HyperslabSelection{Start: start, Count: count}
+	}
+	unlimitedRows := &HyperslabSelection{Start: []uint64{0, 0}, Count: []uint64{Unlimited, 1}, Stride: []uint64{2, 1}, Block: []uint64{2, 4}}
+
+	tests := []struct {
+		name    string
+		dims    []uint64
+		opts    []DatasetOption
+		mapping VirtualMapping
+		want    string
+	}{
+		{
+			name:    "missing virtual selection",
+			mapping: VirtualMapping{SourceFile: "a.h5", SourceDataset: "/d"},
+			want:    "virtual selection is required",
+		},
+		{
+			name:    "missing source dataset",
+			mapping: VirtualMapping{VirtualSelection: slab([]uint64{0, 0}, []uint64{1, 1}), SourceFile: "a.h5"},
+			want:    "names are required",
+		},
+		{
+			name:    "rank mismatch",
+			mapping: VirtualMapping{VirtualSelection: slab([]uint64{0}, []uint64{1}), SourceFile: "a.h5", SourceDataset: "/d"},
+			want:    "does not match dataset rank",
+		},
+		{
+			name:    "out of bounds",
+			mapping: VirtualMapping{VirtualSelection: slab([]uint64{3, 0}, []uint64{2, 4}), SourceFile: "a.h5", SourceDataset: "/d"},
+			want:    "exceeds maximum dimension 0",
+		},
+		{
+			name: "element count mismatch",
+			mapping: VirtualMapping{
+				VirtualSelection: slab([]uint64{0, 0}, []uint64{2, 4}),
+				SourceFile:       "a.h5", SourceDataset: "/d",
+				SourceSelection: slab([]uint64{0}, []uint64{7}),
+			},
+			want: "virtual selection has 8 elements, source selection 7",
+		},
+		{
+			name: "overlapping blocks",
+			mapping: VirtualMapping{
+				VirtualSelection: &HyperslabSelection{Start: []uint64{0, 0}, Count: []uint64{2, 1}, Block: []uint64{2, 4}},
+				SourceFile:       "a.h5", SourceDataset: "/d",
+			},
+			want: "blocks overlap",
+		},
+		{
+			name:    "unlimited without unlimited max dims",
+			mapping: VirtualMapping{VirtualSelection: unlimitedRows, SourceFile: "a-%b.h5", SourceDataset: "/d"},
+			want:    "requires an unlimited maximum dimension",
+		},
+		{
+			name:    "unlimited without printf",
+			opts:    []DatasetOption{WithMaxDims([]uint64{Unlimited, 4})},
+			mapping: VirtualMapping{VirtualSelection: unlimitedRows, SourceFile: "a.h5", SourceDataset: "/d"},
+			want:    `requires "%b"`,
+		},
+		{
+			name:    "bad format specifier",
+			opts:    []DatasetOption{WithMaxDims([]uint64{Unlimited, 4})},
+			mapping: VirtualMapping{VirtualSelection: unlimitedRows, SourceFile: "a-%b-%d.h5", SourceDataset: "/d"},
+			want:    "invalid format specifier",
+		},
+		{
+			name: "unlimited source only",
+			mapping: VirtualMapping{
+				VirtualSelection: slab([]uint64{0, 0}, []uint64{4, 4}),
+				SourceFile:       "a.h5", SourceDataset: "/d",
+				SourceSelection: &HyperslabSelection{Start: []uint64{0}, Count: []uint64{Unlimited}},
+			},
+			want: "requires an unlimited virtual selection",
+		},
+		{
+			name: "chunked",
+			opts: []DatasetOption{WithChunkDims([]uint64{2, 2})},
+			want: "cannot be chunked",
+		},
+		{
+			name: "zero fixed dimension",
+			dims: []uint64{0, 4},
+			want: "dimension 0 cannot be 0",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fw, err := CreateForWrite(filepath.Join(t.TempDir(), "vds.h5"), CreateTruncate)
+			require.NoError(t, err)
+			defer func() { _ = fw.Close() }()
+
+			dims := tt.dims
+			if dims == nil {
+				dims = []uint64{4, 4}
+			}
+			var mappings []VirtualMapping
+			if tt.mapping.VirtualSelection != nil || tt.mapping.SourceFile != "" {
+				mappings = append(mappings, tt.mapping)
+			}
+			err = fw.CreateVirtualDataset("/vds", Int32, dims, mappings, tt.opts...)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,685 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/writer"
+)
+
+// Driver maps the address space of an HDF5 file onto files on disk, like the
+// file drivers of the HDF5 library. Files are stored in a single file by
+// default; use WithDriver to read and WithWriteDriver to write files stored
+// otherwise.
+//
+// Drivers:
+//   - FamilyDriver: the file is split into members of a fixed size
+//   - SplitDriver: metadata and raw data are stored in separate files
+//   - MultiDriver: each kind of data is stored in its own file
+type Driver interface {
+	// open opens the storage of the file name with the flags of os.OpenFile.
+	open(name string, flag int) (driverStorage, error)
+}
+
+// driverStorage is the Storage of a file opened by a Driver.
+type driverStorage interface {
+	Storage
+
+	// rawSpace returns the first address and the end of the raw data space,
+	// if raw data does not share the address space of metadata.
+	rawSpace() (base, end uint64, ok bool)
+
+	// driverInfo returns the driver information block of the file, whose
+	// metadata and raw data end at eoa and rawEOA.
+	driverInfo(eoa, rawEOA uint64) *core.DriverInfo
+}
+
+// WithDriver opens a file stored with the driver d, with the name given to
+// d (see FamilyDriver and SplitDriver). Files named by links, references
+// and virtual datasets are opened with the default driver.
+//
+// Example:
+//
+//	// Read data00000.h5, data00001.h5, ...
+//	f, err := hdf5.Open("data%05d.h5", hdf5.WithDriver(hdf5.FamilyDriver(0)))
+func WithDriver(d Driver) OpenOption {
+	return func(cfg *openConfig) {
+		cfg.driver = d
+	}
+}
+
+// WithWriteDriver creates (CreateForWrite) or modifies (OpenForWrite) a
+// file stored with the driver d. Superblocks version 0 and 1 record the
+// driver in a driver information block, as the HDF5 library does.
+//
+// Example:
+//
+//	// Write metadata to data-m.h5 and raw data to data-r.h5
+//	fw, err := hdf5.CreateForWrite("data", hdf5.CreateTruncate,
+//	    hdf5.WithSuperblockVersion(hdf5.SuperblockV0),
+//	    hdf5.WithWriteDriver(hdf5.SplitDriver()))
+func WithWriteDriver(d Driver) WriteOption {
+	return func(cfg *FileWriteConfig) {
+		cfg.driver = d
+	}
+}
+
+// linked returns the settings for opening files named by links, references
+// and virtual datasets of a file opened with cfg.
+func (cfg openConfig) linked() openConfig {
+	cfg.driver = nil
+	return cfg
+}
+
+// checkNoDriver reports an error if cfg sets a driver, which only stores
+// files on disk.
+func (cfg *FileWriteConfig) checkNoDriver() error {
+	if cfg.driver != nil {
+		return errors.New("file drivers store files on disk and cannot be used with custom storage")
+	}
+	return nil
+}
+
+// openDriverFile opens the file name stored with cfg.driver for reading.
+func openDriverFile(name string, cfg openConfig) (*File, error) {
+	storage, err := cfg.driver.open(name, os.O_RDONLY)
+	if err != nil {
+		return nil, fmt.Errorf("file open failed: %w", err)
+	}
+	size, err := storage.Size()
+	if err != nil {
+		_ = storage.Close()
+		return nil, fmt.Errorf("file open failed: %w", err)
+	}
+	if _, ok := storage.(*multiStorage); ok {
+		// Members are spread over the address space, which has no single end.
+		size = -1
+	}
+	return openReader(storage, storage, size, name, cfg)
+}
+
+// newDriverWriter creates the low-level writer of a new file name stored
+// with the driver d. For superblocks version 0 and 1 it reserves the driver
+// information block after the superblock and records it in sb.
+func newDriverWriter(d Driver, name string, mode CreateMode, sb *core.Superblock) (*writer.FileWriter, error) {
+	flag := os.O_RDWR | os.O_CREATE
+	switch mode {
+	case CreateTruncate:
+		flag |= os.O_TRUNC
+	case CreateExclusive:
+		flag |= os.O_EXCL
+	default:
+		return nil, fmt.Errorf("invalid create mode: %d", mode)
+	}
+
+	storage, err := d.open(name, flag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create file: %w", err)
+	}
+
+	offset := core.SuperblockSize(sb.Version)
+	var info *core.DriverInfo
+	if sb.Version <= core.Version1 {
+		info = storage.driverInfo(0, 0)
+		sb.DriverInfo = offset
+		offset += info.Size()
+	}
+
+	fw, err := writer.NewStorageWriter(storage, offset)
+	if err != nil {
+		_ = storage.Close()
+		return nil, fmt.Errorf("failed to create writer: %w", err)
+	}
+	if base, end, ok := storage.rawSpace(); ok {
+		fw.SeparateRawData(base, end)
+	}
+	if info != nil {
+		if err := fw.WriteAtAddress(info.Encode(), sb.DriverInfo); err != nil {
+			_ = fw.Close()
+			return nil, fmt.Errorf("failed to write driver info: %w", err)
+		}
+	}
+	return fw, nil
+}
+
+// openDriverWriter opens the low-level writer of the existing file name
+// stored with the driver d.
+func openDriverWriter(d Driver, name string, mode OpenMode, initialOffset uint64) (*writer.FileWriter, error) {
+	flag := os.O_RDONLY
+	if mode == OpenReadWrite {
+		flag = os.O_RDWR
+	}
+
+	storage, err := d.open(name, flag)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	fw, err := writer.NewStorageWriter(storage, initialOffset)
+	if err != nil {
+		_ = storage.Close()
+		return nil, err
+	}
+	if base, end, ok := storage.rawSpace(); ok {
+		fw.SeparateRawData(base, end)
+	}
+	return fw, nil
+}
+
+// writeDriverInfo completes a file stored with a driver: it updates the
+// driver information block with the end of the file and rewrites the
+// superblock, whose end of file address is not otherwise kept current.
+func (fw *FileWriter) writeDriverInfo() error {
+	storage, ok := fw.writer.Storage().(driverStorage)
+	if !ok {
+		return nil
+	}
+
+	sb := fw.file.sb
+	eoa := fw.writer.EndOfFile()
+	if err := fw.extendFile(eoa); err != nil {
+		return err
+	}
+	if sb.Version > core.Version1 || sb.DriverInfo == 0 || sb.DriverInfo == undefinedAddress {
+		return nil
+	}
+
+	info := storage.driverInfo(eoa, fw.writer.RawEndOfFile())
+	if err := fw.writer.WriteAtAddress(info.Encode(), sb.DriverInfo); err != nil {
+		return fmt.Errorf("failed to write driver info: %w", err)
+	}
+	if err := sb.WriteTo(fw.writer, eoa); err != nil {
+		return fmt.Errorf("failed to write superblock: %w", err)
+	}
+	return nil
+}
+
+// FamilyDriver returns the driver of files split into members of
+// memberSize bytes (H5FD_FAMILY). The name of a family is a printf pattern
+// with an integer verb, such as "data%05d.h5", which gives the name of each
+// member from its index.
+//
+// When reading, a memberSize of 0 uses the size recorded in the file, or
+// the size of the first member for files without driver information.
+func FamilyDriver(memberSize int64) Driver {
+	return familyDriver{memberSize: memberSize}
+}
+
+// familyDriver implements FamilyDriver.
+type familyDriver struct {
+	memberSize int64
+}
+
+func (d familyDriver) open(pattern string, flag int) (driverStorage, error) {
+	// Sprintf marks missing or extra verbs with "%!".
+	if first := fmt.Sprintf(pattern, 0); strings.Contains(first, "%!") || first == fmt.Sprintf(pattern, 1) {
+		return nil, fmt.Errorf("family name %q has no integer verb for the member index", pattern)
+	}
+	if d.memberSize < 0 || (d.memberSize == 0 && flag&os.O_CREATE != 0) {
+		return nil, fmt.Errorf("invalid family member size: %d", d.memberSize)
+	}
+
+	s := &familyStorage{pattern: pattern, memberSize: d.memberSize, flag: flag}
+	//nolint:gosec // G304: User-provided filename for HDF5 family member
+	first, err := os.OpenFile(s.memberName(0), flag, 0o666)
+	if err != nil {
+		return nil, err
+	}
+	s.members = append(s.members, first)
+
+	if flag&os.O_CREATE == 0 {
+		if err := s.checkMemberSize(); err != nil {
+			_ = s.Close()
+			return nil, err
+		}
+	}
+
+	// Open the other members, which a new file truncates (as the HDF5
+	// library does) or must not have.
+	if flag&os.O_EXCL == 0 {
+		for i := 1; ; i++ {
+			//nolint:gosec // G304: User-provided filename for HDF5 family member
+			f, err := os.OpenFile(s.memberName(i), flag&^os.O_CREATE, 0o666)
+			if errors.Is(err, os.ErrNotExist) {
+				break
+			}
+			if err != nil {
+				_ = s.Close()
+				return nil, err
+			}
+			s.members = append(s.members, f)
+		}
+	}
+	return s, nil
+}
+
+// familyStorage maps the addresses of a file onto the members of a family.
+type familyStorage struct {
+	pattern    string
+	memberSize int64
+	flag       int // Flags new members are created with.
+	members    []*os.File
+}
+
+// memberName returns the file name of member i.
+func (s *familyStorage) memberName(i int) string {
+	return fmt.Sprintf(s.pattern, i)
+}
+
+// checkMemberSize sets the member size of an existing family from its
+// driver information or first member when it is not known, and checks it
+// otherwise.
+func (s *familyStorage) checkMemberSize() error {
+	first := s.members[0]
+	stored := int64(0)
+	if sb, err := core.ReadSuperblock(first); err == nil {
+		info, err := core.ReadDriverInfo(first, sb)
+		if err != nil {
+			return err
+		}
+		if info != nil {
+			size, err := info.FamilyMemberSize()
+			if err != nil {
+				return err
+			}
+			stored = int64(size) //nolint:gosec // G115: member sizes fit in int64
+		}
+	}
+
+	if s.memberSize == 0 {
+		s.memberSize = stored
+		if s.memberSize == 0 {
+			stat, err := first.Stat()
+			if err != nil {
+				return err
+			}
+			s.memberSize = stat.Size()
+		}
+		if s.memberSize <= 0 {
+			return errors.New("family member size unknown: first member is empty")
+		}
+		return nil
+	}
+
+	if stored != 0 && stored != s.memberSize {
+		return fmt.Errorf("family member size %d does not match %d stored in the file", s.memberSize, stored)
+	}
+	return nil
+}
+
+// member returns member i, creating the members up to it as needed.
+func (s *familyStorage) member(i int) (*os.File, error) {
+	for len(s.members) <= i {
+		//nolint:gosec // G304: User-provided filename for HDF5 family member
+		f, err := os.OpenFile(s.memberName(len(s.members)), s.flag|os.O_CREATE, 0o666)
+		if err != nil {
+			return nil, err
+		}
+		s.members = append(s.members, f)
+	}
+	return s.members[i], nil
+}
+
+// ReadAt implements io.ReaderAt. Unwritten parts of members read as zeros.
+func (s *familyStorage) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		i := int(off / s.memberSize)
+		within := off % s.memberSize
+		chunk := p[n:min(len(p), n+int(s.memberSize-within))]
+		if i >= len(s.members) {
+			return n, io.EOF
+		}
+
+		m, err := s.members[i].ReadAt(chunk, within)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return n + m, err
+		}
+		if m < len(chunk) {
+			if i == len(s.members)-1 {
+				return n + m, io.EOF
+			}
+			clear(chunk[m:])
+		}
+		n += len(chunk)
+		off += int64(len(chunk))
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt, creating members as needed.
+func (s *familyStorage) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	n := 0
+	for n < len(p) {
+		within := off % s.memberSize
+		chunk := p[n:min(len(p), n+int(s.memberSize-within))]
+		f, err := s.member(int(off / s.memberSize))
+		if err != nil {
+			return n, err
+		}
+		m, err := f.WriteAt(chunk, within)
+		n += m
+		if err != nil {
+			return n, err
+		}
+		off += int64(m)
+	}
+	return n, nil
+}
+
+// Size implements Storage: the end of the last non-empty member.
+func (s *familyStorage) Size() (int64, error) {
+	for i := len(s.members) - 1; i >= 0; i-- {
+		stat, err := s.members[i].Stat()
+		if err != nil {
+			return 0, err
+		}
+		if stat.Size() > 0 || i == 0 {
+			return int64(i)*s.memberSize + stat.Size(), nil
+		}
+	}
+	return 0, nil
+}
+
+// Truncate implements Storage. Members before the one holding the end are
+// extended to the member size, and members after it are emptied.
+func (s *familyStorage) Truncate(size int64) error {
+	if size < 0 {
+		return errors.New("negative size")
+	}
+	last := 0
+	if size > 0 {
+		last = int((size - 1) / s.memberSize)
+	}
+	if _, err := s.member(last); err != nil {
+		return err
+	}
+
+	for i, f := range s.members {
+		var want int64
+		switch {
+		case i < last:
+			stat, err := f.Stat()
+			if err != nil {
+				return err
+			}
+			if stat.Size() >= s.memberSize {
+				continue
+			}
+			want = s.memberSize
+		case i == last:
+			want = size - int64(i)*s.memberSize
+		}
+		if err := f.Truncate(want); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Sync implements Storage.
+func (s *familyStorage) Sync() error {
+	for _, f := range s.members {
+		if err := f.Sync(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close implements Storage.
+func (s *familyStorage) Close() error {
+	var err error
+	for _, f := range s.members {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	s.members = nil
+	return err
+}
+
+func (s *familyStorage) rawSpace() (uint64, uint64, bool) {
+	return 0, 0, false
+}
+
+func (s *familyStorage) driverInfo(uint64, uint64) *core.DriverInfo {
+	return core.NewFamilyDriverInfo(uint64(s.memberSize)) //nolint:gosec // G115: member size is positive
+}
+
+// splitRawAddress is the first address of raw data in files of the split
+// driver (HADDR_MAX / 2 in the HDF5 library).
+const splitRawAddress = 0x7FFFFFFFFFFFFFFF
+
+// SplitDriver returns the driver of files whose metadata and raw data are
+// stored separately (H5FD_SPLIT), in the files name-m.h5 and name-r.h5 for
+// the name given to Open or CreateForWrite.
+func SplitDriver() Driver {
+	info := &core.MultiDriverInfo{
+		Members: []core.MultiMember{
+			{Type: core.MemSuper, Address: 0, Name: "%s-m.h5"},
+			{Type: core.MemDraw, Address: splitRawAddress, Name: "%s-r.h5"},
+		},
+	}
+	// Like H5Pset_fapl_split, global heaps (variable-length data) go with
+	// the raw data.
+	for mt := core.MemSuper; mt <= core.MemOHdr; mt++ {
+		info.Map[mt] = core.MemSuper
+	}
+	info.Map[core.MemDraw] = core.MemDraw
+	info.Map[core.MemGHeap] = core.MemDraw
+	return multiDriver{super: "%s-m.h5", layout: info}
+}
+
+// MultiDriver returns the driver of existing files that store each kind of
+// data in its own file (H5FD_MULTI), such as name-s.h5 for the superblock
+// and name-r.h5 for raw data. The files are found from the driver
+// information of name-s.h5. New metadata is added to name-s.h5.
+func MultiDriver() Driver {
+	return multiDriver{super: "%s-s.h5"}
+}
+
+// multiDriver implements SplitDriver and MultiDriver.
+type multiDriver struct {
+	super  string                // Name template of the member holding the superblock.
+	layout *core.MultiDriverInfo // Layout of new files (nil if they are not supported).
+}
+
+func (d multiDriver) open(name string, flag int) (driverStorage, error) {
+	superName := strings.ReplaceAll(d.super, "%s", name)
+	//nolint:gosec // G304: User-provided filename for HDF5 multi member
+	super, err := os.OpenFile(superName, flag, 0o666)
+	if err != nil {
+		return nil, err
+	}
+
+	layout := d.layout
+	if flag&os.O_CREATE == 0 {
+		if sb, err := core.ReadSuperblock(super); err == nil {
+			info, err := core.ReadDriverInfo(super, sb)
+			if err != nil {
+				_ = super.Close()
+				return nil, err
+			}
+			if info != nil {
+				if layout, err = info.MultiDriverInfo(); err != nil {
+					_ = super.Close()
+					return nil, err
+				}
+			}
+		}
+	}
+	if layout == nil {
+		_ = super.Close()
+		if flag&os.O_CREATE != 0 {
+			return nil, errors.New("the multi driver cannot create files")
+		}
+		return nil, fmt.Errorf("%s has no multi driver information", superName)
+	}
+
+	s := &multiStorage{layout: layout, files: make([]*os.File, len(layout.Members))}
+	for i, m := range layout.Members {
+		memberName := strings.ReplaceAll(m.Name, "%s", name)
+		if memberName == superName {
+			s.files[i] = super
+			continue
+		}
+		//nolint:gosec // G304: User-provided filename for HDF5 multi member
+		f, err := os.OpenFile(memberName, flag, 0o666)
+		if errors.Is(err, os.ErrNotExist) && m.EOA == 0 {
+			continue // Unused members need not exist.
+		}
+		if err != nil {
+			_ = super.Close()
+			_ = s.Close()
+			return nil, err
+		}
+		s.files[i] = f
+	}
+	if s.files[s.memberOf(0)] != super {
+		_ = super.Close()
+		_ = s.Close()
+		return nil, fmt.Errorf("%s does not hold the start of the file", superName)
+	}
+
+	return s, nil
+}
+
+// multiStorage maps the addresses of a file onto the members of the multi
+// driver. Each member holds the addresses from its own up to those of the
+// next member. Size and Truncate apply to the member at address 0, which
+// holds the superblock and metadata.
+type multiStorage struct {
+	layout *core.MultiDriverInfo
+	files  []*os.File // Files of the members (nil for missing unused members).
+}
+
+// memberOf returns the member holding addr.
+func (s *multiStorage) memberOf(addr uint64) int {
+	found := 0
+	for i, m := range s.layout.Members {
+		if m.Address <= addr && m.Address >= s.layout.Members[found].Address {
+			found = i
+		}
+	}
+	return found
+}
+
+// locate returns the file and offset of the address off. Addresses past
+// the range of int64 (raw data of split files) arrive as negative offsets.
+func (s *multiStorage) locate(off int64) (*os.File, int64, error) {
+	addr := uint64(off) //nolint:gosec // G115: Negative offsets are addresses above 2^63
+	i := s.memberOf(addr)
+	f := s.files[i]
+	if f == nil {
+		return nil, 0, fmt.Errorf("address %d is in missing member %q", addr, s.layout.Members[i].Name)
+	}
+	return f, int64(addr - s.layout.Members[i].Address), nil //nolint:gosec // G115: member offsets fit in int64
+}
+
+// ReadAt implements io.ReaderAt.
+func (s *multiStorage) ReadAt(p []byte, off int64) (int, error) {
+	f, within, err := s.locate(off)
+	if err != nil {
+		return 0, err
+	}
+	return f.ReadAt(p, within)
+}
+
+// WriteAt implements io.WriterAt.
+func (s *multiStorage) WriteAt(p []byte, off int64) (int, error) {
+	f, within, err := s.locate(off)
+	if err != nil {
+		return 0, err
+	}
+	return f.WriteAt(p, within)
+}
+
+// Size implements Storage.
+func (s *multiStorage) Size() (int64, error) {
+	stat, err := s.files[s.memberOf(0)].Stat()
+	if err != nil {
+		return 0, err
+	}
+	return stat.Size(), nil
+}
+
+// Truncate implements Storage.
+func (s *multiStorage) Truncate(size int64) error {
+	return s.files[s.memberOf(0)].Truncate(size)
+}
+
+// Sync implements Storage.
+func (s *multiStorage) Sync() error {
+	for _, f := range s.files {
+		if f != nil {
+			if err := f.Sync(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Close implements Storage.
+func (s *multiStorage) Close() error {
+	var err error
+	for _, f := range s.files {
+		if f != nil {
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	s.files = nil
+	return err
+}
+
+// rawMember returns the member holding raw data.
+func (s *multiStorage) rawMember() int {
+	for i, m := range s.layout.Members {
+		if m.Type == s.layout.Map[core.MemDraw] {
+			return i
+		}
+	}
+	return s.memberOf(0)
+}
+
+func (s *multiStorage) rawSpace() (uint64, uint64, bool) {
+	i := s.rawMember()
+	m := s.layout.Members[i]
+	if m.Address == 0 || s.files[i] == nil {
+		return 0, 0, false
+	}
+	stat, err := s.files[i].Stat()
+	if err != nil {
+		return m.Address, m.Address + m.EOA, true
+	}
+	//nolint:gosec // G115: File sizes are positive
+	return m.Address, m.Address + max(m.EOA, uint64(stat.Size())), true
+}
+
+func (s *multiStorage) driverInfo(eoa, rawEOA uint64) *core.DriverInfo {
+	info := *s.layout
+	info.Members = append([]core.MultiMember(nil), s.layout.Members...)
+	info.Members[s.memberOf(0)].EOA = eoa
+	if raw := s.rawMember(); info.Members[raw].Address != 0 {
+		info.Members[raw].EOA = rawEOA - info.Members[raw].Address
+	}
+	return core.NewMultiDriverInfo(&info)
+}
+
+// Ensure the driver storages implement driverStorage.
+var (
+	_ driverStorage = (*familyStorage)(nil)
+	_ driverStorage = (*multiStorage)(nil)
+)
@@ -0,0 +1,259 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+// readDriverDataset opens the file name with the driver d and reads the
+// dataset path.
+func readDriverDataset(t *testing.T, name string, d Driver, path string) []float64 {
+	t.Helper()
+
+	f, err := Open(name, WithDriver(d))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	ds, err := f.OpenDataset(path)
+	require.NoError(t, err)
+	data, err := ds.Read()
+	require.NoError(t, err)
+	return data
+}
+
+func TestFamilyDriver_Read(t *testing.T) {
+	tests := []struct {
+		name   string
+		driver Driver
+		path   string
+		length int
+	}{
+		{"family_file%05d.h5", FamilyDriver(0), "/dataset", 4096},
+		{"family_file%05d.h5", FamilyDriver(1024), "/dataset", 4096},
+		{"family_v16-%06d.h5", FamilyDriver(0), "/dataset", 4096}, // No driver info
+		{"tfamily%05d.h5", FamilyDriver(0), "/dset1", 150},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := readDriverDataset(t, filepath.Join("testdata/hdf5_official", tt.name), tt.driver, tt.path)
+			require.Len(t, data, tt.length)
+			require.Equal(t, []float64{0, 1, 2, 3}, data[:4])
+		})
+	}
+
+	// The member size must match the one recorded in the file.
+	_, err := Open("testdata/hdf5_official/family_file%05d.h5", WithDriver(FamilyDriver(512)))
+	require.ErrorContains(t, err, "does not match")
+}
+
+func TestSplitDriver_Read(t *testing.T) {
+	data := readDriverDataset(t, "testdata/hdf5_official/tsplit_file", SplitDriver(), "/dset1")
+	require.Len(t, data, 150)
+	require.Equal(t, []float64{0, 1, 2, 3}, data[:4])
+}
+
+func TestMultiDriver_Read(t *testing.T) {
+	// Six members, with object headers stored above 2^63.
+	data := readDriverDataset(t, "testdata/hdf5_official/tmulti", MultiDriver(), "/dset1")
+	require.Len(t, data, 150)
+	require.Equal(t, []float64{0, 1, 2, 3}, data[:4])
+
+	// Split layout with other member names.
+	data = readDriverDataset(t, "testdata/hdf5_official/multi_file_v16", MultiDriver(), "/dset1")
+	require.Len(t, data, 16384)
+	require.Equal(t, []float64{0, 1, 2, 3}, data[:4])
+}
+
+// writeDriverFile creates the file name with the driver d and writes the
+// dataset /data, then adds an attribute to it with OpenForWrite.
+func writeDriverFile(t *testing.T, name string, d Driver, sbVersion uint8, data []float64) {
+	t.Helper()
+
+	fw, err := CreateForWrite(name, CreateTruncate, WithSuperblockVersion(sbVersion), WithWriteDriver(d))
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/data", Float64, []uint64{uint64(len(data))})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(data))
+	require.NoError(t, ds.WriteAttribute("units", "m"))
+	require.NoError(t, fw.Close())
+
+	fw, err = OpenForWrite(name, OpenReadWrite, WithWriteDriver(d))
+	require.NoError(t, err)
+	ds, err = fw.OpenDataset("/data")
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteAttribute("scale", float64(0.5)))
+	require.NoError(t, fw.Close())
+}
+
+// checkDriverFile checks a file written by writeDriverFile.
+func checkDriverFile(t *testing.T, name string, d Driver, data []float64) {
+	t.Helper()
+
+	f, err := Open(name, WithDriver(d))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	ds, err := f.OpenDataset("/data")
+	require.NoError(t, err)
+	got, err := ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, data, got)
+	scale, err := ds.ReadAttribute("scale")
+	require.NoError(t, err)
+	require.Equal(t, 0.5, scale)
+}
+
+func testDriverData() []float64 {
+	data := make([]float64, 2000)
+	for i := range data {
+		data[i] = float64(i)
+	}
+	return data
+}
+
+func TestFamilyDriver_Write(t *testing.T) {
+	data := testDriverData()
+	for _, version := range []uint8{SuperblockV0, SuperblockV2} {
+		dir := t.TempDir()
+		name := filepath.Join(dir, "family%03d.h5")
+		writeDriverFile(t, name, FamilyDriver(2048), version, data)
+
+		members, err := filepath.Glob(filepath.Join(dir, "family*.h5"))
+		require.NoError(t, err)
+		require.Greater(t, len(members), 8)
+		for _, m := range members[:len(members)-1] {
+			stat, err := os.Stat(m)
+			require.NoError(t, err)
+			require.Equal(t, int64(2048), stat.Size(), m)
+		}
+
+		// The member size is found from the driver info or first member.
+		checkDriverFile(t, name, FamilyDriver(0), data)
+		checkDriverFile(t, name, FamilyDriver(2048), data)
+	}
+}
+
+func TestSplitDriver_Write(t *testing.T) {
+	data := testDriverData()
+	for _, version := range []uint8{SuperblockV0, SuperblockV2} {
+		name := filepath.Join(t.TempDir(), "split")
+		writeDriverFile(t, name, SplitDriver(), version, data)
+		checkDriverFile(t, name, SplitDriver(), data)
+
+		// Raw data is stored on its own in name-r.h5.
+		raw, err := os.ReadFile(name + "-r.h5")
+		require.NoError(t, err)
+		require.Len(t, raw, 8*len(data))
+		for i, v := range data {
+			require.Equal(t, v, math.Float64frombits(binary.LittleEndian.Uint64(raw[8*i:])))
+		}
+
+		if version == SuperblockV0 {
+			// The driver information records the split layout.
+			meta, err := os.Open(name + "-m.h5")
+			require.NoError(t, err)
+			sb, err := core.ReadSuperblock(meta)
+			require.NoError(t, err)
+			di, err := core.ReadDriverInfo(meta, sb)
+			require.NoError(t, err)
+			require.NoError(t, meta.Close())
+			require.NotNil(t, di)
+			info, err := di.MultiDriverInfo()
+			require.NoError(t, err)
+			require.Len(t, info.Members, 2)
+			require.Equal(t, "%s-r.h5", info.Members[1].Name)
+			require.Equal(t, uint64(len(raw)), info.Members[1].EOA)
+			stat, err := os.Stat(name + "-m.h5")
+			require.NoError(t, err)
+			require.Equal(t, uint64(stat.Size()), info.Members[0].EOA)
+		}
+	}
+}
+
+func TestSplitDriver_MemberMap(t *testing.T) {
+	// tsplit_file-m.h5 was written by the C library with H5Pset_fapl_split.
+	meta, err := os.Open("testdata/hdf5_official/tsplit_file-m.h5")
+	require.NoError(t, err)
+	defer func() { _ = meta.Close() }()
+	sb, err := core.ReadSuperblock(meta)
+	require.NoError(t, err)
+	di, err := core.ReadDriverInfo(meta, sb)
+	require.NoError(t, err)
+	require.NotNil(t, di)
+	want, err := di.MultiDriverInfo()
+	require.NoError(t, err)
+
+	got := SplitDriver().(multiDriver).layout
+	require.Len(t, got.Members, len(want.Members))
+	for i, m := range want.Members {
+		require.Equal(t, m.Type, got.Members[i].Type)
+		require.Equal(t, m.Address, got.Members[i].Address)
+		require.Equal(t, m.Name, got.Members[i].Name)
+	}
+
+	// The file predates the library versions that store global heaps with
+	// the raw data; every other type maps the same way.
+	require.Equal(t, core.MemSuper, want.Map[core.MemGHeap])
+	require.Equal(t, core.MemDraw, got.Map[core.MemGHeap])
+	for mt := core.MemSuper; mt <= core.MemOHdr; mt++ {
+		if mt != core.MemGHeap {
+			require.Equal(t, want.Map[mt], got.Map[mt], "memory type %d", mt)
+		}
+	}
+}
+
+func TestSplitDriver_VarLenData(t *testing.T) {
+	type record struct {
+		ID    int32  `hdf5:"id"`
+		Label string `hdf5:"label"`
+	}
+	records := []record{{1, "alpha"}, {2, "beta"}}
+
+	name := filepath.Join(t.TempDir(), "split")
+	fw, err := CreateForWrite(name, CreateTruncate, WithWriteDriver(SplitDriver()))
+	require.NoError(t, err)
+	ds, err := CreateDatasetFromStruct[record](fw, "/records", []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteStructs(records))
+	require.NoError(t, fw.Close())
+
+	// The global heap holding the strings is stored with the raw data.
+	raw, err := os.ReadFile(name + "-r.h5")
+	require.NoError(t, err)
+	require.Contains(t, string(raw), "GCOL")
+	metadata, err := os.ReadFile(name + "-m.h5")
+	require.NoError(t, err)
+	require.NotContains(t, string(metadata), "GCOL")
+
+	f, err := Open(name, WithDriver(SplitDriver()))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rds, err := f.OpenDataset("/records")
+	require.NoError(t, err)
+	var got []record
+	require.NoError(t, rds.ReadStructs(&got))
+	require.Equal(t, records, got)
+}
+
+func TestDriver_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := CreateForWrite(filepath.Join(dir, "family.h5"), CreateTruncate, WithWriteDriver(FamilyDriver(1024)))
+	require.ErrorContains(t, err, "no integer verb")
+	_, err = CreateForWrite(filepath.Join(dir, "family%d.h5"), CreateTruncate, WithWriteDriver(FamilyDriver(0)))
+	require.ErrorContains(t, err, "invalid family member size")
+	_, err = CreateForWrite(filepath.Join(dir, "multi"), CreateTruncate, WithWriteDriver(MultiDriver()))
+	require.ErrorContains(t, err, "cannot create files")
+	_, err = Open(filepath.Join(dir, "missing%d.h5"), WithDriver(FamilyDriver(0)))
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	// Drivers only store files on disk.
+	_, err = CreateInMemory(WithWriteDriver(SplitDriver()))
+	require.ErrorContains(t, err, "cannot be used with custom storage")
+	_, err = CreateWithStorage(NewMemoryStorage(nil), WithWriteDriver(SplitDriver()))
+	require.ErrorContains(t, err, "cannot be used with custom storage")
+}
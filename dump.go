@@ -0,0 +1,727 @@
+package hdf5
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// dumpIndent is one level of indentation in Dump output.
+const dumpIndent = "   "
+
+// dumpWidth is the column after which data lines are wrapped.
+const dumpWidth = 80
+
+// DumpOption configures Dump.
+type DumpOption func(*dumpConfig)
+
+// dumpConfig holds the settings of a Dump call.
+type dumpConfig struct {
+	headerOnly bool                // Omit dataset and attribute values
+	properties bool                // Print storage layout, filters and fill values
+	objects    []dumpObject        // Objects to print; the whole file if empty
+	selection  *HyperslabSelection // Subset printed for the datasets in objects
+}
+
+// dumpObject is an object selected by DumpDataset, DumpAttribute or DumpGroup.
+type dumpObject struct {
+	keyword string // "DATASET", "ATTRIBUTE" or "GROUP"
+	path    string
+}
+
+// DumpHeaderOnly omits the values of datasets and attributes (h5dump -H).
+func DumpHeaderOnly() DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.headerOnly = true
+	}
+}
+
+// DumpProperties prints the storage layout, filters, fill value and
+// allocation time of datasets (h5dump -p).
+func DumpProperties() DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.properties = true
+	}
+}
+
+// DumpDataset prints only the dataset at path (h5dump -d). The option may be
+// given several times; objects are printed in the order given.
+func DumpDataset(path string) DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.objects = append(cfg.objects, dumpObject{keyword: "DATASET", path: path})
+	}
+}
+
+// DumpAttribute prints only the attribute at path, the path of its object
+// followed by the attribute name (h5dump -a).
+func DumpAttribute(path string) DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.objects = append(cfg.objects, dumpObject{keyword: "ATTRIBUTE", path: path})
+	}
+}
+
+// DumpGroup prints only the group at path and its members (h5dump -g).
+func DumpGroup(path string) DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.objects = append(cfg.objects, dumpObject{keyword: "GROUP", path: path})
+	}
+}
+
+// DumpSelection limits the values printed for datasets selected with
+// DumpDataset to a hyperslab (h5dump -s, -S, -c and -k). Nil Stride and
+// Block default to 1 in every dimension.
+func DumpSelection(sel *HyperslabSelection) DumpOption {
+	return func(cfg *dumpConfig) {
+		cfg.selection = sel
+	}
+}
+
+// Dump writes a description of the file to w in the Data Description Language
+// of the h5dump tool: the group hierarchy with the datatype, dataspace and
+// attributes of every object, and the values of datasets and attributes.
+//
+// Objects reached through more than one hard link are described once and
+// printed as a HARDLINK to the first path afterwards. Soft and external links
+// are printed, not followed. Like h5dump, Dump continues past objects it
+// cannot read, printing what it can; their errors are returned together once
+// the output is complete.
+//
+// Example:
+//
+//	// Like "h5dump -H -d /data file.h5".
+//	err := f.Dump(os.Stdout, hdf5.DumpHeaderOnly(), hdf5.DumpDataset("/data"))
+func (f *File) Dump(w io.Writer, opts ...DumpOption) error {
+	cfg := &dumpConfig{}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	d := &dumper{
+		f:     f,
+		cfg:   cfg,
+		w:     bufio.NewWriter(w),
+		seen:  make(map[uint64]string),
+		heaps: make(map[uint64]*core.GlobalHeapCollection),
+	}
+	err := d.run()
+	if flushErr := d.w.Flush(); flushErr != nil {
+		return flushErr
+	}
+	return err
+}
+
+// dumpTarget is the path and DDL keyword of an object, for printing
+// references to it.
+type dumpTarget struct {
+	keyword string
+	path    string
+}
+
+// dumper holds the state of a Dump call.
+type dumper struct {
+	f   *File
+	cfg *dumpConfig
+	w   *bufio.Writer
+
+	seen    map[uint64]string                     // Path each object header was first printed at
+	targets map[uint64]dumpTarget                 // Objects by header address; built on first use
+	heaps   map[uint64]*core.GlobalHeapCollection // Global heap collections read so far
+}
+
+// run prints the whole file, or the objects selected in the configuration.
+func (d *dumper) run() error {
+	d.line(0, "HDF5 %s {", quote(d.f.filename))
+
+	var errs []error
+	if len(d.cfg.objects) == 0 {
+		d.seen[d.f.sb.RootGroup] = "/"
+		errs = append(errs, d.group(d.f.root, "/", "/", 0))
+	}
+
+	for _, obj := range d.cfg.objects {
+		switch obj.keyword {
+		case "DATASET":
+			errs = append(errs, d.selectedDataset(obj.path))
+		case "ATTRIBUTE":
+			errs = append(errs, d.selectedAttribute(obj.path))
+		case "GROUP":
+			errs = append(errs, d.selectedGroup(obj.path))
+		}
+	}
+
+	d.line(0, "}")
+	return errors.Join(errs...)
+}
+
+// selectedDataset prints the dataset at path, limited to the configured
+// selection.
+func (d *dumper) selectedDataset(path string) error {
+	ds, err := d.f.OpenDataset(path)
+	if err != nil {
+		return err
+	}
+	return d.dataset(ds, path, path, 0, d.cfg.selection)
+}
+
+// selectedGroup prints the group at path.
+func (d *dumper) selectedGroup(path string) error {
+	g, err := d.f.OpenGroup(path)
+	if err != nil {
+		return err
+	}
+	if g.address != 0 {
+		d.seen[g.address] = path
+	}
+	return d.group(g, path, path, 0)
+}
+
+// selectedAttribute prints the attribute at path.
+func (d *dumper) selectedAttribute(path string) error {
+	i := strings.LastIndex(path, "/")
+	if i < 0 || i == len(path)-1 {
+		return fmt.Errorf("%s: attribute path must be an object path followed by the attribute name", path)
+	}
+	objPath, name := path[:i], path[i+1:]
+	if objPath == "" {
+		objPath = "/"
+	}
+
+	obj, err := d.f.Get(objPath)
+	if err != nil {
+		return err
+	}
+	attrs, err := objectAttributes(obj)
+	if err != nil {
+		return fmt.Errorf("%s: %w", objPath, err)
+	}
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return d.attribute(attr, path, 0)
+		}
+	}
+	return fmt.Errorf("%s: attribute %q not found", objPath, name)
+}
+
+// group prints the group g, named name in the output and found at path.
+func (d *dumper) group(g *Group, name, path string, level int) error {
+	d.line(level, "GROUP %s {", quote(name))
+	defer d.line(level, "}")
+	errs := []error{d.attributes(g, path, level+1)}
+
+	links, err := g.Links()
+	if err != nil {
+		return errors.Join(append(errs, fmt.Errorf("%s: %w", path, err))...)
+	}
+	sort.Slice(links, func(i, j int) bool { return links[i].Name < links[j].Name })
+	children := make(map[string]Object, len(g.children))
+	for _, child := range g.Children() {
+		children[child.Name()] = child
+	}
+
+	for _, l := range links {
+		childPath := strings.TrimSuffix(path, "/") + "/" + l.Name
+		switch l.Type {
+		case LinkHard:
+			child, ok := children[l.Name]
+			if !ok {
+				errs = append(errs, fmt.Errorf("%s: object at 0x%x could not be read", childPath, l.Address))
+				continue
+			}
+			errs = append(errs, d.object(child, l.Name, childPath, l.Address, level+1))
+		case LinkSoft:
+			d.line(level+1, "SOFTLINK %s {", quote(l.Name))
+			d.line(level+2, "LINKTARGET %s", quote(l.Target))
+			d.line(level+1, "}")
+		case LinkExternal:
+			d.line(level+1, "EXTERNAL_LINK %s {", quote(l.Name))
+			d.line(level+2, "TARGETFILE %s", quote(l.File))
+			d.line(level+2, "TARGETPATH %s", quote(l.Target))
+			d.line(level+1, "}")
+		default:
+			d.line(level+1, "USERDEFINED_LINK %s {", quote(l.Name))
+			d.line(level+2, "LINKCLASS %d", l.Type)
+			d.line(level+1, "}")
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// object prints the object obj at address, reached through a hard link
+// named name. Objects printed before are printed as a HARDLINK.
+func (d *dumper) object(obj Object, name, path string, address uint64, level int) error {
+	keyword := objectKeyword(obj)
+	if first, ok := d.seen[address]; ok && address != 0 {
+		d.line(level, "%s %s {", keyword, quote(name))
+		d.line(level+1, "HARDLINK %s", quote(first))
+		d.line(level, "}")
+		return nil
+	}
+	d.seen[address] = path
+
+	switch obj := obj.(type) {
+	case *Group:
+		return d.group(obj, name, path, level)
+	case *Dataset:
+		return d.dataset(obj, name, path, level, nil)
+	case *NamedDatatype:
+		dt, err := d.datatype(obj.datatype, level)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		d.line(level, "DATATYPE %s %s", quote(name), dt)
+		return nil
+	}
+	return fmt.Errorf("%s: unsupported object type %T", path, obj)
+}
+
+// dataset prints the dataset ds. If sel is not nil, only the selected
+// values are printed.
+func (d *dumper) dataset(ds *Dataset, name, path string, level int, sel *HyperslabSelection) error {
+	info, err := ds.info()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	d.line(level, "DATASET %s {", quote(name))
+	defer d.line(level, "}")
+
+	// Named datatypes are referred to by path.
+	if t, ok := d.target(info.DatatypeAddress); ok && info.DatatypeAddress != 0 {
+		d.line(level+1, "DATATYPE  %s", quote(t.path))
+	} else {
+		dt, err := d.datatype(info.Datatype, level+1)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		d.line(level+1, "DATATYPE  %s", dt)
+	}
+	d.line(level+1, "DATASPACE  %s", dataspaceString(info.Dataspace))
+
+	if d.cfg.properties {
+		if err := d.properties(info, level+1); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	var errs []error
+	if !d.cfg.headerOnly {
+		if err := d.datasetData(ds, info, sel, level+1); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", path, err))
+		}
+	}
+	return errors.Join(append(errs, d.attributes(ds, path, level+1))...)
+}
+
+// datasetData prints the values of ds, or those selected by sel.
+func (d *dumper) datasetData(ds *Dataset, info *core.DatasetInfo, sel *HyperslabSelection, level int) error {
+	raw, _, err := ds.readRaw()
+	if err != nil {
+		return err
+	}
+	if sel == nil {
+		return d.data(raw, info.Datatype, info.Dataspace, nil, level)
+	}
+
+	if info.Dataspace.Type != core.DataspaceSimple {
+		return fmt.Errorf("cannot select a subset of a dataset without dimensions")
+	}
+	sel = &HyperslabSelection{Start: sel.Start, Count: sel.Count, Stride: sel.Stride, Block: sel.Block}
+	if err := validateHyperslabSelection(sel, info.Dataspace.Dimensions); err != nil {
+		return err
+	}
+	selection := &core.Selection{Type: core.SelectionHyperslabs, Rank: len(sel.Start)}
+	for i := range sel.Start {
+		selection.Regular = append(selection.Regular, core.HyperslabDim{
+			Start: sel.Start[i], Stride: sel.Stride[i], Count: sel.Count[i], Block: sel.Block[i],
+		})
+	}
+
+	d.line(level, "SUBSET {")
+	defer d.line(level, "}")
+	d.line(level+1, "START %s;", dimsString(sel.Start))
+	d.line(level+1, "STRIDE %s;", dimsString(sel.Stride))
+	d.line(level+1, "COUNT %s;", dimsString(sel.Count))
+	d.line(level+1, "BLOCK %s;", dimsString(sel.Block))
+	return d.data(raw, info.Datatype, info.Dataspace, selection, level+1)
+}
+
+// properties prints the storage layout, filters, fill value and allocation
+// time of a dataset.
+func (d *dumper) properties(info *core.DatasetInfo, level int) error {
+	layout := info.Layout
+	d.line(level, "STORAGE_LAYOUT {")
+	switch layout.Class {
+	case core.LayoutCompact:
+		d.line(level+1, "COMPACT")
+		d.line(level+1, "SIZE %d", len(layout.CompactData))
+	case core.LayoutContiguous:
+		d.line(level+1, "CONTIGUOUS")
+		d.line(level+1, "SIZE %d", layout.DataSize)
+		d.line(level+1, "OFFSET %d", layout.DataAddress)
+	case core.LayoutChunked:
+		// Layout v3 stores an extra trailing chunk dimension holding the element size.
+		chunk := layout.ChunkSize
+		if len(chunk) > len(info.Dataspace.Dimensions) {
+			chunk = chunk[:len(info.Dataspace.Dimensions)]
+		}
+		d.line(level+1, "CHUNKED %s", dimsString(chunk))
+	case core.LayoutVirtual:
+		d.line(level+1, "VIRTUAL")
+	}
+	d.line(level, "}")
+
+	d.line(level, "FILTERS {")
+	if info.FilterPipeline == nil || len(info.FilterPipeline.Filters) == 0 {
+		d.line(level+1, "NONE")
+	} else {
+		for _, f := range info.FilterPipeline.Filters {
+			d.filter(f, level+1)
+		}
+	}
+	d.line(level, "}")
+
+	fillTime, value := "H5D_FILL_TIME_IFSET", "H5D_FILL_VALUE_DEFAULT"
+	allocTime := core.AllocTimeDefault
+	if fv := info.FillValue; fv != nil {
+		switch fv.FillTime {
+		case core.FillTimeAlloc:
+			fillTime = "H5D_FILL_TIME_ALLOC"
+		case core.FillTimeNever:
+			fillTime = "H5D_FILL_TIME_NEVER"
+		}
+		switch {
+		case !fv.Defined:
+			value = "H5D_FILL_VALUE_UNDEFINED"
+		case len(fv.Value) > 0:
+			var err error
+			if value, err = d.value(info.Datatype, fv.Value); err != nil {
+				return err
+			}
+		}
+		allocTime = fv.AllocTime
+	}
+	d.line(level, "FILLVALUE {")
+	d.line(level+1, "FILL_TIME %s", fillTime)
+	d.line(level+1, "VALUE  %s", value)
+	d.line(level, "}")
+
+	// The default allocation time depends on the layout.
+	if allocTime == core.AllocTimeDefault {
+		switch layout.Class {
+		case core.LayoutCompact:
+			allocTime = core.AllocTimeEarly
+		case core.LayoutContiguous:
+			allocTime = core.AllocTimeLate
+		default:
+			allocTime = core.AllocTimeIncremental
+		}
+	}
+	d.line(level, "ALLOCATION_TIME {")
+	switch allocTime {
+	case core.AllocTimeEarly:
+		d.line(level+1, "H5D_ALLOC_TIME_EARLY")
+	case core.AllocTimeLate:
+		d.line(level+1, "H5D_ALLOC_TIME_LATE")
+	default:
+		d.line(level+1, "H5D_ALLOC_TIME_INCR")
+	}
+	d.line(level, "}")
+	return nil
+}
+
+// filter prints one filter of a dataset's filter pipeline.
+func (d *dumper) filter(f core.Filter, level int) {
+	switch f.ID {
+	case core.FilterDeflate:
+		var gzipLevel uint32
+		if len(f.ClientData) > 0 {
+			gzipLevel = f.ClientData[0]
+		}
+		d.line(level, "COMPRESSION DEFLATE { LEVEL %d }", gzipLevel)
+	case core.FilterShuffle:
+		d.line(level, "PREPROCESSING SHUFFLE")
+	case core.FilterFletcher:
+		d.line(level, "CHECKSUM FLETCHER32")
+	case core.FilterNBit:
+		d.line(level, "COMPRESSION NBIT")
+	default:
+		name := f.Name
+		if name == "" {
+			name = f.ID.String()
+		}
+		d.line(level, "USER_DEFINED_FILTER {")
+		d.line(level+1, "FILTER_ID %d", f.ID)
+		d.line(level+1, "COMMENT %s", name)
+		if len(f.ClientData) > 0 {
+			params := make([]string, len(f.ClientData))
+			for i, v := range f.ClientData {
+				params[i] = fmt.Sprint(v)
+			}
+			d.line(level+1, "PARAMS { %s }", strings.Join(params, " "))
+		}
+		d.line(level, "}")
+	}
+}
+
+// attributes prints the attributes of obj, sorted by name.
+func (d *dumper) attributes(obj Object, path string, level int) error {
+	attrs, err := objectAttributes(obj)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	sort.Slice(attrs, func(i, j int) bool { return attrs[i].Name < attrs[j].Name })
+	var errs []error
+	for _, attr := range attrs {
+		if err := d.attribute(attr, attr.Name, level); err != nil {
+			errs = append(errs, fmt.Errorf("%s: attribute %q: %w", path, attr.Name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// attribute prints attr under the given name.
+func (d *dumper) attribute(attr *core.Attribute, name string, level int) error {
+	d.line(level, "ATTRIBUTE %s {", quote(name))
+	defer d.line(level, "}")
+	dt, err := d.datatype(attr.Datatype, level+1)
+	if err != nil {
+		return err
+	}
+	d.line(level+1, "DATATYPE  %s", dt)
+	d.line(level+1, "DATASPACE  %s", dataspaceString(attr.Dataspace))
+	if d.cfg.headerOnly {
+		return nil
+	}
+	return d.data(attr.Data, attr.Datatype, attr.Dataspace, nil, level+1)
+}
+
+// data prints a DATA block with the elements of raw selected by sel, or all
+// elements if sel is nil. Each run of elements along the last dimension
+// starts a new line, prefixed with the coordinates of its first element.
+func (d *dumper) data(raw []byte, dt *core.DatatypeMessage, space *core.DataspaceMessage, sel *core.Selection, level int) error {
+	d.line(level, "DATA {")
+	defer d.line(level, "}")
+	if space.Type == core.DataspaceNull {
+		return nil
+	}
+
+	// Scalars print as a one-element array.
+	dims := space.Dimensions
+	if space.Type == core.DataspaceScalar || len(dims) == 0 {
+		dims = []uint64{1}
+	}
+	if sel == nil {
+		sel = &core.Selection{Type: core.SelectionAll}
+	}
+	runs, err := sel.Runs(dims)
+	if err != nil {
+		return err
+	}
+	var total uint64
+	for _, run := range runs {
+		total += run.Length
+	}
+
+	size := uint64(dt.Size)
+	indent := strings.Repeat(dumpIndent, level)
+	var line strings.Builder
+	flush := func() {
+		if line.Len() > 0 {
+			d.w.WriteString(indent + line.String() + "\n")
+			line.Reset()
+		}
+	}
+
+	defer flush()
+
+	var printed uint64
+	for _, run := range runs {
+		coords := append([]uint64{}, run.Coords...)
+		for k := uint64(0); k < run.Length; k++ {
+			offset := linearIndex(coords, dims) * size
+			if offset+size > uint64(len(raw)) {
+				return fmt.Errorf("data truncated: need %d bytes, have %d", offset+size, len(raw))
+			}
+			v, err := d.value(dt, raw[offset:offset+size])
+			if err != nil {
+				return err
+			}
+
+			if k == 0 || len(indent)+line.Len()+len(v)+2 > dumpWidth {
+				flush()
+				line.WriteString(coordsString(coords) + ": ")
+			} else {
+				line.WriteString(" ")
+			}
+			line.WriteString(v)
+			if printed++; printed < total {
+				line.WriteString(",")
+			}
+			coords[len(coords)-1]++
+		}
+	}
+	return nil
+}
+
+// target returns the path and keyword of the object at address, for
+// printing references to it.
+func (d *dumper) target(address uint64) (dumpTarget, bool) {
+	if d.targets == nil {
+		d.targets = make(map[uint64]dumpTarget)
+		d.f.Walk(func(path string, obj Object) {
+			address := objectAddress(obj)
+			if _, ok := d.targets[address]; ok || address == 0 {
+				return
+			}
+			if path != "/" {
+				path = strings.TrimSuffix(path, "/")
+			}
+			d.targets[address] = dumpTarget{keyword: objectKeyword(obj), path: path}
+		})
+		d.targets[d.f.sb.RootGroup] = dumpTarget{keyword: "GROUP", path: "/"}
+	}
+	t, ok := d.targets[address]
+	return t, ok
+}
+
+// heapObject returns the data of object index in the global heap collection
+// at address.
+func (d *dumper) heapObject(address uint64, index uint32) ([]byte, error) {
+	collection, ok := d.heaps[address]
+	if !ok {
+		var err error
+		collection, err = core.ReadGlobalHeapCollection(d.f.reader(), address, int(d.f.sb.OffsetSize))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read global heap collection at 0x%X: %w", address, err)
+		}
+		d.heaps[address] = collection
+	}
+	obj, err := collection.GetObject(index)
+	if err != nil {
+		return nil, err
+	}
+	return obj.Data, nil
+}
+
+// line writes one line of output, indented by level.
+func (d *dumper) line(level int, format string, args ...any) {
+	d.w.WriteString(strings.Repeat(dumpIndent, level))
+	fmt.Fprintf(d.w, format, args...)
+	d.w.WriteString("\n")
+}
+
+// objectAttributes returns the attributes of a group, dataset or named datatype.
+func objectAttributes(obj Object) ([]*core.Attribute, error) {
+	switch obj := obj.(type) {
+	case *Group:
+		return obj.Attributes()
+	case *Dataset:
+		return obj.Attributes()
+	case *NamedDatatype:
+		return obj.Attributes()
+	}
+	return nil, fmt.Errorf("unsupported object type %T", obj)
+}
+
+// objectAddress returns the object header address of obj.
+func objectAddress(obj Object) uint64 {
+	switch obj := obj.(type) {
+	case *Group:
+		return obj.address
+	case *Dataset:
+		return obj.address
+	case *NamedDatatype:
+		return obj.address
+	}
+	return 0
+}
+
+// objectKeyword returns the DDL keyword of obj.
+func objectKeyword(obj Object) string {
+	switch obj.(type) {
+	case *Group:
+		return "GROUP"
+	case *NamedDatatype:
+		return "DATATYPE"
+	}
+	return "DATASET"
+}
+
+// dataspaceString formats a dataspace, e.g. "SIMPLE { ( 2, 3 ) / ( 2, H5S_UNLIMITED ) }".
+func dataspaceString(space *core.DataspaceMessage) string {
+	switch space.Type {
+	case core.DataspaceScalar:
+		return "SCALAR"
+	case core.DataspaceNull:
+		return "NULL"
+	}
+	maxDims := space.MaxDims
+	if len(maxDims) == 0 {
+		maxDims = space.Dimensions
+	}
+	return fmt.Sprintf("SIMPLE { %s / %s }", dimsString(space.Dimensions), dimsString(maxDims))
+}
+
+// dimsString formats dimension sizes as "( 2, 3 )".
+func dimsString(dims []uint64) string {
+	parts := make([]string, len(dims))
+	for i, dim := range dims {
+		if dim == Unlimited {
+			parts[i] = "H5S_UNLIMITED"
+		} else {
+			parts[i] = fmt.Sprint(dim)
+		}
+	}
+	return "( " + strings.Join(parts, ", ") + " )"
+}
+
+// coordsString formats element coordinates as "(1,0)".
+func coordsString(coords []uint64) string {
+	parts := make([]string, len(coords))
+	for i, c := range coords {
+		parts[i] = fmt.Sprint(c)
+	}
+	return "(" + strings.Join(parts, ",") + ")"
+}
+
+// linearIndex returns the row-major index of coords in an array of size dims.
+func linearIndex(coords, dims []uint64) uint64 {
+	var index uint64
+	for i, c := range coords {
+		index = index*dims[i] + c
+	}
+	return index
+}
+
+// quote quotes s for output; see escape.
+func quote(s string) string {
+	return `"` + escape(s) + `"`
+}
+
+// escape escapes quotes, backslashes and control characters in s.
+func escape(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			b.WriteByte('\\')
+			b.WriteRune(r)
+		case r == '\n':
+			b.WriteString(`\n`)
+		case r == '\r':
+			b.WriteString(`\r`)
+		case r == '\t':
+			b.WriteString(`\t`)
+		case r < 0x20 || r == 0x7f:
+			fmt.Fprintf(&b, `\%03o`, r)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,507 @@
+package hdf5
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// datatype formats dt as in h5dump output. Types spanning several lines are
+// indented for a DATATYPE line at level.
+func (d *dumper) datatype(dt *core.DatatypeMessage, level int) (string, error) {
+	switch dt.Class {
+	case core.DatatypeFixed:
+		sign := "U"
+		if dt.IsSigned() {
+			sign = "I"
+		}
+		return fmt.Sprintf("H5T_STD_%s%d%s", sign, dt.Size*8, byteOrderSuffix(dt)), nil
+
+	case core.DatatypeFloat:
+		return fmt.Sprintf("H5T_IEEE_F%d%s", dt.Size*8, byteOrderSuffix(dt)), nil
+
+	case core.DatatypeBitfield:
+		return fmt.Sprintf("H5T_STD_B%d%s", dt.Size*8, byteOrderSuffix(dt)), nil
+
+	case core.DatatypeTime:
+		return "H5T_TIME", nil
+
+	case core.DatatypeString:
+		return stringType(fmt.Sprint(dt.Size), dt.GetStringPadding(), uint8(dt.ClassBitField>>4&0x0F), level), nil //nolint:gosec // G115: masked to 4 bits
+
+	case core.DatatypeVarLen:
+		if dt.IsVariableString() {
+			//nolint:gosec // G115: masked to 4 bits
+			return stringType("H5T_VARIABLE", uint8(dt.ClassBitField>>4&0x0F), uint8(dt.ClassBitField>>8&0x0F), level), nil
+		}
+		base, err := core.ParseDatatypeMessage(dt.Properties)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse variable-length base type: %w", err)
+		}
+		s, err := d.datatype(base, level)
+		if err != nil {
+			return "", err
+		}
+		return "H5T_VLEN { " + s + " }", nil
+
+	case core.DatatypeCompound:
+		compound, err := core.ParseCompoundType(dt)
+		if err != nil {
+			return "", fmt.Errorf("unsupported compound datatype: %w", err)
+		}
+		lines := make([]string, len(compound.Members))
+		for i, m := range compound.Members {
+			s, err := d.datatype(m.Type, level+1)
+			if err != nil {
+				return "", err
+			}
+			lines[i] = fmt.Sprintf("%s %s;", s, quote(m.Name))
+		}
+		return typeBlock("H5T_COMPOUND", lines, level), nil
+
+	case core.DatatypeEnum:
+		base, names, values, err := enumType(dt)
+		if err != nil {
+			return "", err
+		}
+		s, err := d.datatype(base, level+1)
+		if err != nil {
+			return "", err
+		}
+		lines := []string{s + ";"}
+		for i, name := range names {
+			v, err := d.value(base, values[i])
+			if err != nil {
+				return "", err
+			}
+			lines = append(lines, fmt.Sprintf("%s %s;", quote(name), v))
+		}
+		return typeBlock("H5T_ENUM", lines, level), nil
+
+	case core.DatatypeArray:
+		dims, base, err := arrayType(dt)
+		if err != nil {
+			return "", err
+		}
+		s, err := d.datatype(base, level)
+		if err != nil {
+			return "", err
+		}
+		var b strings.Builder
+		b.WriteString("H5T_ARRAY { ")
+		for _, dim := range dims {
+			fmt.Fprintf(&b, "[%d]", dim)
+		}
+		return b.String() + " " + s + " }", nil
+
+	case core.DatatypeReference:
+		switch dt.ReferenceType() {
+		case core.ReferenceTypeObject:
+			return "H5T_REFERENCE { H5T_STD_REF_OBJECT }", nil
+		case core.ReferenceTypeRegion:
+			return "H5T_REFERENCE { H5T_STD_REF_DSETREG }", nil
+		}
+		return "H5T_REFERENCE { H5T_STD_REF }", nil
+
+	case core.DatatypeOpaque:
+		tag := string(bytes.TrimRight(dt.Properties, "\x00"))
+		return typeBlock("H5T_OPAQUE", []string{fmt.Sprintf("OPAQUE_TAG %s;", quote(tag))}, level), nil
+
+	case core.DatatypeComplex:
+		base, err := core.ParseDatatypeMessage(dt.Properties)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse complex base type: %w", err)
+		}
+		s, err := d.datatype(base, level)
+		if err != nil {
+			return "", err
+		}
+		return "H5T_COMPLEX { " + s + " }", nil
+	}
+
+	return "", fmt.Errorf("unsupported datatype class: %d", dt.Class)
+}
+
+// value formats one element of datatype dt stored in elem.
+func (d *dumper) value(dt *core.DatatypeMessage, elem []byte) (string, error) {
+	switch dt.Class {
+	case core.DatatypeFixed:
+		if dt.IsSigned() {
+			v := make([]int64, 1)
+			if err := core.DecodeNumeric(elem, dt, v); err != nil {
+				return "", err
+			}
+			return strconv.FormatInt(v[0], 10), nil
+		}
+		v := make([]uint64, 1)
+		if err := core.DecodeNumeric(elem, dt, v); err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(v[0], 10), nil
+
+	case core.DatatypeFloat:
+		v := make([]float64, 1)
+		if err := core.DecodeNumeric(elem, dt, v); err != nil {
+			return "", err
+		}
+		bitSize := 64
+		if dt.Size <= 4 {
+			bitSize = 32
+		}
+		return strconv.FormatFloat(v[0], 'g', -1, bitSize), nil
+
+	case core.DatatypeBitfield:
+		b := append([]byte{}, elem...)
+		if dt.GetByteOrder() == binary.LittleEndian {
+			for i, j := 0, len(b)-1; i < j; i, j = i+1, j-1 {
+				b[i], b[j] = b[j], b[i]
+			}
+		}
+		return fmt.Sprintf("0x%x", b), nil
+
+	case core.DatatypeString:
+		return quote(fixedString(elem, dt.GetStringPadding())), nil
+
+	case core.DatatypeVarLen:
+		return d.vlenValue(dt, elem)
+
+	case core.DatatypeCompound:
+		compound, err := core.ParseCompoundType(dt)
+		if err != nil {
+			return "", fmt.Errorf("unsupported compound datatype: %w", err)
+		}
+		parts := make([]string, len(compound.Members))
+		for i, m := range compound.Members {
+			end := uint64(m.Offset) + uint64(m.Type.Size)
+			if end > uint64(len(elem)) {
+				return "", fmt.Errorf("compound member %q exceeds element size", m.Name)
+			}
+			if parts[i], err = d.value(m.Type, elem[m.Offset:end]); err != nil {
+				return "", err
+			}
+		}
+		return "{ " + strings.Join(parts, ", ") + " }", nil
+
+	case core.DatatypeEnum:
+		base, names, values, err := enumType(dt)
+		if err != nil {
+			return "", err
+		}
+		for i, v := range values {
+			if bytes.Equal(v, elem) {
+				return escape(names[i]), nil
+			}
+		}
+		return d.value(base, elem)
+
+	case core.DatatypeArray:
+		_, base, err := arrayType(dt)
+		if err != nil {
+			return "", err
+		}
+		parts, err := d.values(base, elem)
+		if err != nil {
+			return "", err
+		}
+		return "[ " + strings.Join(parts, ", ") + " ]", nil
+
+	case core.DatatypeReference:
+		return d.referenceValue(dt, elem)
+
+	case core.DatatypeComplex:
+		base, err := core.ParseDatatypeMessage(dt.Properties)
+		if err != nil {
+			return "", fmt.Errorf("failed to parse complex base type: %w", err)
+		}
+		parts, err := d.values(base, elem)
+		if err != nil {
+			return "", err
+		}
+		if len(parts) != 2 {
+			return "", fmt.Errorf("complex element has %d parts", len(parts))
+		}
+		return parts[0] + "+" + parts[1] + "i", nil
+	}
+
+	// Opaque and time values print as hexadecimal bytes.
+	parts := make([]string, len(elem))
+	for i, c := range elem {
+		parts[i] = fmt.Sprintf("%02x", c)
+	}
+	return strings.Join(parts, ":"), nil
+}
+
+// values formats the consecutive elements of datatype dt stored in data.
+func (d *dumper) values(dt *core.DatatypeMessage, data []byte) ([]string, error) {
+	size := int(dt.Size)
+	if size == 0 {
+		return nil, errors.New("datatype has size 0")
+	}
+	parts := make([]string, len(data)/size)
+	for i := range parts {
+		var err error
+		if parts[i], err = d.value(dt, data[i*size:(i+1)*size]); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+// vlenValue formats a variable-length string or sequence. Elements hold the
+// number of base elements followed by the global heap ID of the data.
+func (d *dumper) vlenValue(dt *core.DatatypeMessage, elem []byte) (string, error) {
+	offsetSize := int(d.f.sb.OffsetSize)
+	if len(elem) < 8+offsetSize {
+		return "", fmt.Errorf("variable-length element too short: %d bytes", len(elem))
+	}
+	length := binary.LittleEndian.Uint32(elem)
+	heapID, err := core.ParseGlobalHeapReference(elem[4:], offsetSize)
+	if err != nil {
+		return "", err
+	}
+
+	var data []byte
+	if heapID.HeapAddress != 0 {
+		if data, err = d.heapObject(heapID.HeapAddress, heapID.ObjectIndex); err != nil {
+			return "", err
+		}
+	}
+
+	if dt.IsVariableString() {
+		if heapID.HeapAddress == 0 {
+			return "NULL", nil
+		}
+		if uint64(length) < uint64(len(data)) {
+			data = data[:length]
+		}
+		return quote(string(bytes.TrimRight(data, "\x00"))), nil
+	}
+
+	base, err := core.ParseDatatypeMessage(dt.Properties)
+	if err != nil {
+		return "", fmt.Errorf("failed to parse variable-length base type: %w", err)
+	}
+	if need := uint64(length) * uint64(base.Size); need <= uint64(len(data)) {
+		data = data[:need]
+	}
+	parts, err := d.values(base, data)
+	if err != nil {
+		return "", err
+	}
+	return "(" + strings.Join(parts, ", ") + ")", nil
+}
+
+// referenceValue formats an object, region or attribute reference as the
+// keyword and path of the referenced object.
+func (d *dumper) referenceValue(dt *core.DatatypeMessage, elem []byte) (string, error) {
+	offsetSize := int(d.f.sb.OffsetSize)
+	refs, err := core.DecodeReferences(d.f.reader(), elem, dt, 1, offsetSize)
+	if err != nil {
+		return "", err
+	}
+
+	switch ref := refs[0].(type) {
+	case core.ObjectRef:
+		if ref.IsNull() {
+			return "NULL", nil
+		}
+		return d.objectRefString(uint64(ref)), nil
+
+	case core.RegionRef:
+		if ref.IsNull() {
+			return "NULL", nil
+		}
+		address, sel, err := core.ReadRegionReference(d.f.reader(), ref, offsetSize)
+		if err != nil {
+			return "", err
+		}
+		return d.regionRefString(address, sel)
+
+	case core.Ref:
+		if ref.IsNull() {
+			return "NULL", nil
+		}
+		if ref.File != "" {
+			return fmt.Sprintf("EXTERNAL %s %d", quote(ref.File), ref.Address), nil
+		}
+		switch ref.Type {
+		case core.ReferenceTypeRegion2:
+			return d.regionRefString(ref.Address, ref.Selection)
+		case core.ReferenceTypeAttribute:
+			t, ok := d.target(ref.Address)
+			if !ok {
+				return fmt.Sprintf("ATTRIBUTE %d %s", ref.Address, ref.Attribute), nil
+			}
+			return "ATTRIBUTE " + strings.TrimSuffix(t.path, "/") + "/" + ref.Attribute, nil
+		}
+		return d.objectRefString(ref.Address), nil
+	}
+	return "", fmt.Errorf("unsupported reference %T", refs[0])
+}
+
+// objectRefString formats a reference to the object at address.
+func (d *dumper) objectRefString(address uint64) string {
+	t, ok := d.target(address)
+	if !ok {
+		return fmt.Sprintf("UNKNOWN_OBJECT %d", address)
+	}
+	return fmt.Sprintf("%s %d %s", t.keyword, address, t.path)
+}
+
+// regionRefString formats a reference to the region sel of the dataset at
+// address, listing selected blocks as "(start)-(end)" and points as "(x,y)".
+func (d *dumper) regionRefString(address uint64, sel *core.Selection) (string, error) {
+	path := fmt.Sprint(address)
+	if t, ok := d.target(address); ok {
+		path = t.path
+	}
+
+	var parts []string
+	switch sel.Type {
+	case core.SelectionAll:
+		parts = append(parts, "ALL")
+	case core.SelectionPoints:
+		for _, p := range sel.Points {
+			parts = append(parts, coordsString(p))
+		}
+	case core.SelectionHyperslabs:
+		blocks, err := selectionBlocks(sel)
+		if err != nil {
+			return "", err
+		}
+		for _, b := range blocks {
+			parts = append(parts, coordsString(b.Start)+"-"+coordsString(b.End))
+		}
+	}
+	return fmt.Sprintf("DATASET %s {%s}", path, strings.Join(parts, ", ")), nil
+}
+
+// selectionBlocks returns the blocks of a hyperslab selection in row-major
+// order, expanding regular selections.
+func selectionBlocks(sel *core.Selection) ([]core.SelectionBlock, error) {
+	if sel.Regular == nil {
+		return sel.Blocks, nil
+	}
+
+	blocks := []core.SelectionBlock{{}}
+	for i, dim := range sel.Regular {
+		if dim.Count == core.SelectionUnlimited || dim.Block == core.SelectionUnlimited {
+			return nil, fmt.Errorf("unlimited selection in dimension %d", i)
+		}
+		next := make([]core.SelectionBlock, 0, uint64(len(blocks))*dim.Count)
+		for _, b := range blocks {
+			for j := uint64(0); j < dim.Count; j++ {
+				start := dim.Start + j*dim.Stride
+				next = append(next, core.SelectionBlock{
+					Start: append(append([]uint64{}, b.Start...), start),
+					End:   append(append([]uint64{}, b.End...), start+dim.Block-1),
+				})
+			}
+		}
+		blocks = next
+	}
+	return blocks, nil
+}
+
+// enumType parses the base type, member names and member values of an enum
+// datatype. All names precede the values; names are padded to a multiple of
+// 8 bytes before version 3.
+func enumType(dt *core.DatatypeMessage) (*core.DatatypeMessage, []string, [][]byte, error) {
+	base, err := core.ParseDatatypeMessage(dt.Properties)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed to parse enum base type: %w", err)
+	}
+
+	props := dt.Properties
+	pos := base.GetEncodedSize()
+	count := int(dt.ClassBitField & 0xFFFF)
+	names := make([]string, count)
+	for i := range names {
+		if pos > len(props) {
+			return nil, nil, nil, errors.New("enum datatype too short")
+		}
+		end := bytes.IndexByte(props[pos:], 0)
+		if end < 0 {
+			return nil, nil, nil, errors.New("enum member name not terminated")
+		}
+		names[i] = string(props[pos : pos+end])
+		pos += end + 1
+		if dt.Version < 3 {
+			pos += (8 - (end+1)%8) % 8
+		}
+	}
+
+	size := int(dt.Size)
+	if pos+count*size > len(props) {
+		return nil, nil, nil, errors.New("enum datatype too short")
+	}
+	values := make([][]byte, count)
+	for i := range values {
+		values[i] = props[pos+i*size : pos+(i+1)*size]
+	}
+	return base, names, values, nil
+}
+
+// stringType formats a string datatype of the given size, padding and
+// character set.
+func stringType(size string, padding, charset uint8, level int) string {
+	pad := "H5T_STR_NULLTERM"
+	switch padding {
+	case 1:
+		pad = "H5T_STR_NULLPAD"
+	case 2:
+		pad = "H5T_STR_SPACEPAD"
+	}
+	cset := "H5T_CSET_ASCII"
+	if charset == 1 {
+		cset = "H5T_CSET_UTF8"
+	}
+	return typeBlock("H5T_STRING", []string{
+		"STRSIZE " + size + ";",
+		"STRPAD " + pad + ";",
+		"CSET " + cset + ";",
+		"CTYPE H5T_C_S1;",
+	}, level)
+}
+
+// typeBlock formats a datatype with one property per line, for a DATATYPE
+// line at level.
+func typeBlock(keyword string, lines []string, level int) string {
+	indent := strings.Repeat(dumpIndent, level)
+	var b strings.Builder
+	b.WriteString(keyword + " {\n")
+	for _, line := range lines {
+		b.WriteString(indent + dumpIndent + line + "\n")
+	}
+	b.WriteString(indent + "}")
+	return b.String()
+}
+
+// byteOrderSuffix returns "LE" or "BE" for the byte order of a numeric type.
+func byteOrderSuffix(dt *core.DatatypeMessage) string {
+	if dt.GetByteOrder() == binary.BigEndian {
+		return "BE"
+	}
+	return "LE"
+}
+
+// fixedString returns the value of a fixed-length string with the given
+// padding: null-terminated (0), null-padded (1) or space-padded (2).
+func fixedString(b []byte, padding uint8) string {
+	switch padding {
+	case 0:
+		if i := bytes.IndexByte(b, 0); i >= 0 {
+			b = b[:i]
+		}
+	case 1:
+		b = bytes.TrimRight(b, "\x00")
+	case 2:
+		b = bytes.TrimRight(b, " ")
+	}
+	return string(b)
+}
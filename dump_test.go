@@ -0,0 +1,279 @@
+package hdf5
+
+import (
+	"bytes"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// writeDumpSource writes a small file with groups, datasets, attributes and
+// links for the Dump tests.
+func writeDumpSource(t *testing.T, filename string) {
+	t.Helper()
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	grp, err := fw.CreateGroup("/grp")
+	require.NoError(t, err)
+	require.NoError(t, grp.WriteAttribute("count", int32(7)))
+	chunked, err := fw.CreateDataset("/grp/chunked", Int32, []uint64{8}, WithChunkDims([]uint64{4}), WithGZIPCompression(6))
+	require.NoError(t, err)
+	require.NoError(t, chunked.Write([]int32{1, 2, 3, 4, 5, 6, 7, 8}))
+
+	values, err := fw.CreateDataset("/values", Float64, []uint64{2, 3})
+	require.NoError(t, err)
+	require.NoError(t, fw.CreateHardLink("/grp/alias", "/values"))
+	require.NoError(t, values.WriteAttribute("units", "m"))
+	require.NoError(t, values.Write([]float64{1, 2.5, 3, 4, 5, 6}))
+
+	state, err := fw.CreateDataset("/state", EnumInt8, []uint64{3}, WithEnumValues([]string{"OFF", "ON"}, []int64{0, 1}))
+	require.NoError(t, err)
+	require.NoError(t, state.Write([]int8{1, 0, 1}))
+
+	require.NoError(t, fw.CreateSoftLink("/soft", "/grp/chunked"))
+	require.NoError(t, fw.CreateExternalLink("/ext", "other.h5", "/data"))
+	require.NoError(t, fw.Close())
+}
+
+// dump opens filename and returns its Dump output.
+func dump(t *testing.T, filename string, opts ...DumpOption) string {
+	t.Helper()
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var buf bytes.Buffer
+	require.NoError(t, f.Dump(&buf, opts...))
+	return buf.String()
+}
+
+func TestDump_File(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dump.h5")
+	writeDumpSource(t, filename)
+
+	want := `HDF5 "` + filename + `" {
+GROUP "/" {
+   EXTERNAL_LINK "ext" {
+      TARGETFILE "other.h5"
+      TARGETPATH "/data"
+   }
+   GROUP "grp" {
+      ATTRIBUTE "count" {
+         DATATYPE  H5T_STD_I32LE
+         DATASPACE  SIMPLE { ( 1 ) / ( 1 ) }
+         DATA {
+         (0): 7
+         }
+      }
+      DATASET "alias" {
+         DATATYPE  H5T_IEEE_F64LE
+         DATASPACE  SIMPLE { ( 2, 3 ) / ( 2, 3 ) }
+         DATA {
+         (0,0): 1, 2.5, 3,
+         (1,0): 4, 5, 6
+         }
+         ATTRIBUTE "units" {
+            DATATYPE  H5T_STRING {
+               STRSIZE 2;
+               STRPAD H5T_STR_NULLTERM;
+               CSET H5T_CSET_ASCII;
+               CTYPE H5T_C_S1;
+            }
+            DATASPACE  SIMPLE { ( 1 ) / ( 1 ) }
+            DATA {
+            (0): "m"
+            }
+         }
+      }
+      DATASET "chunked" {
+         DATATYPE  H5T_STD_I32LE
+         DATASPACE  SIMPLE { ( 8 ) / ( 8 ) }
+         DATA {
+         (0): 1, 2, 3, 4, 5, 6, 7, 8
+         }
+      }
+   }
+   SOFTLINK "soft" {
+      LINKTARGET "/grp/chunked"
+   }
+   DATASET "state" {
+      DATATYPE  H5T_ENUM {
+         H5T_STD_I8LE;
+         "OFF" 0;
+         "ON" 1;
+      }
+      DATASPACE  SIMPLE { ( 3 ) / ( 3 ) }
+      DATA {
+      (0): ON, OFF, ON
+      }
+   }
+   DATASET "values" {
+      HARDLINK "/grp/alias"
+   }
+}
+}
+`
+	require.Equal(t, want, dump(t, filename))
+}
+
+func TestDump_HeaderOnlyProperties(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dump.h5")
+	writeDumpSource(t, filename)
+
+	out := dump(t, filename, DumpHeaderOnly(), DumpProperties(), DumpDataset("/grp/chunked"))
+	require.NotContains(t, out, "DATA {")
+	for _, line := range []string{
+		`DATASET "/grp/chunked" {`,
+		"      CHUNKED ( 4 )",
+		"      COMPRESSION DEFLATE { LEVEL 6 }",
+		"      FILL_TIME H5D_FILL_TIME_IFSET",
+		"      H5D_ALLOC_TIME_INCR",
+	} {
+		require.Contains(t, out, line+"\n")
+	}
+	require.NotContains(t, out, `"values"`)
+}
+
+func TestDump_Selection(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dump.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/grid", Int32, []uint64{4, 4})
+	require.NoError(t, err)
+	data := make([]int32, 16)
+	for i := range data {
+		data[i] = int32(i)
+	}
+	require.NoError(t, ds.Write(data))
+	require.NoError(t, fw.Close())
+
+	out := dump(t, filename, DumpDataset("/grid"), DumpSelection(&HyperslabSelection{
+		Start:  []uint64{1, 0},
+		Count:  []uint64{2, 2},
+		Block:  []uint64{1, 1},
+		Stride: []uint64{2, 2},
+	}))
+	require.Contains(t, out, `   SUBSET {
+      START ( 1, 0 );
+      STRIDE ( 2, 2 );
+      COUNT ( 2, 2 );
+      BLOCK ( 1, 1 );
+      DATA {
+      (1,0): 4,
+      (1,2): 6,
+      (3,0): 12,
+      (3,2): 14
+      }
+   }
+`)
+
+	// Selections outside the dataset are reported.
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	err = f.Dump(&bytes.Buffer{}, DumpDataset("/grid"), DumpSelection(&HyperslabSelection{
+		Start: []uint64{3, 3},
+		Count: []uint64{2, 1},
+	}))
+	require.Error(t, err)
+}
+
+func TestDump_Attribute(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dump.h5")
+	writeDumpSource(t, filename)
+
+	out := dump(t, filename, DumpAttribute("/grp/count"))
+	require.Equal(t, `HDF5 "`+filename+`" {
+ATTRIBUTE "/grp/count" {
+   DATATYPE  H5T_STD_I32LE
+   DATASPACE  SIMPLE { ( 1 ) / ( 1 ) }
+   DATA {
+   (0): 7
+   }
+}
+}
+`, out)
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.Error(t, f.Dump(&bytes.Buffer{}, DumpAttribute("/grp/missing")))
+	require.Error(t, f.Dump(&bytes.Buffer{}, DumpDataset("/missing")))
+}
+
+func TestDump_Testdata(t *testing.T) {
+	tests := []struct {
+		file  string
+		lines []string
+	}{
+		{
+			// Object and region references.
+			file: "hdf5_official/tattrreg.h5",
+			lines: []string{
+				"      DATASPACE  NULL",
+				"         DATATYPE  H5T_REFERENCE { H5T_STD_REF_DSETREG }",
+				"         (0): DATASET /Dataset2 {(2,2)-(7,7)},",
+				"         (2): NULL, NULL",
+			},
+		},
+		{
+			file: "hdf5_official/h5repack_refs.h5",
+			lines: []string{
+				"      (0): DATASET 800 /Dset1, GROUP 1400 /Group, DATATYPE 2104 /NamedDatatype",
+			},
+		},
+		{
+			// Committed enum datatype with escaped member names.
+			file: "hdf5_official/tenum.h5",
+			lines: []string{
+				`   DATATYPE "enum normal" H5T_ENUM {`,
+				`      "GREEN\ngreen" 1;`,
+				`      DATATYPE  "/enum normal"`,
+				`      (0): RED, GREEN\ngreen, BLUE blue, GREEN\ngreen, WHITE \"white\",`,
+			},
+		},
+		{
+			file: "hdf5_official/tvldtypes1.h5",
+			lines: []string{
+				"      DATATYPE  H5T_VLEN { H5T_STD_I32LE }",
+				"      (0): (0), (10, 11), (20, 21, 22), (30, 31, 32, 33)",
+			},
+		},
+		{
+			file: "hdf5_official/tvlstr.h5",
+			lines: []string{
+				"         STRSIZE H5T_VARIABLE;",
+				`      (2): "", NULL`,
+			},
+		},
+		{
+			file: "hdf5_official/tarray1.h5",
+			lines: []string{
+				"      DATATYPE  H5T_ARRAY { [4] H5T_STD_I32LE }",
+				"      (0): [ 0, 1, 2, 3 ], [ 10, 11, 12, 13 ], [ 20, 21, 22, 23 ],",
+			},
+		},
+		{
+			file: "reference_traverse.h5",
+			lines: []string{
+				`            HARDLINK "/group1/dset1"`,
+				`   GROUP "group2" {`,
+				`      HARDLINK "/group1/group3"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			out := dump(t, filepath.Join("testdata", tt.file))
+			for _, line := range tt.lines {
+				require.Contains(t, strings.Split(out, "\n"), line)
+			}
+		})
+	}
+}
@@ -4,71 +4,155 @@
 package hdf5
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 
 	"github.com/meko-christian/go-hdf5/internal/core"
 	"github.com/meko-christian/go-hdf5/internal/utils"
 )
 
 // File represents an open HDF5 file with its metadata and root group.
+//
+// A File is safe for concurrent use by multiple goroutines: groups,
+// datasets and attributes can be looked up and read in parallel. Close
+// must not be called while other calls are in progress.
 type File struct {
-	osFile        *os.File
-	sb            *core.Superblock
-	root          *Group
+	r        io.ReaderAt // Contents of the file.
+	closer   io.Closer   // Closes r (nil if the caller owns it).
+	filename string      // Name of the file (empty if opened from a reader).
+	sb       *core.Superblock
+	root     *Group
+	config   openConfig
+
+	// loadMu serializes loading objects after Open, which tracks visited
+	// B-trees in visitedBTrees.
+	loadMu        sync.Mutex
 	visitedBTrees map[uint64]bool // Track visited B-tree addresses to prevent cycles
+
+	// filesMu guards the files opened on first use.
+	filesMu      sync.Mutex
+	virtualFiles map[string]*File // Source files of virtual datasets (nil if not found)
+	refFiles     map[string]*File // Files opened for revised references into other files
+	linkFiles    map[string]*File // Files opened for external links
+}
+
+// OpenOption configures how Open reads a file.
+type OpenOption func(*openConfig)
+
+// openConfig holds the settings given to Open.
+type openConfig struct {
+	virtualPrefix   string     // Search path for source files of virtual datasets.
+	fileOpener      FileOpener // Opens files named by references (nil for the default).
+	followLinks     bool       // Follow soft and external links in lookups and Walk.
+	externalPrefix  string     // Search path for the files of external links.
+	driver          Driver     // Driver the file is stored with (nil for a single file).
+	readConcurrency int        // Goroutines reading chunks (see WithReadConcurrency).
 }
 
 // Open opens an HDF5 file for reading and returns a File handle.
 // The file must be a valid HDF5 file with a supported format version.
-func Open(filename string) (*File, error) {
+func Open(filename string, opts ...OpenOption) (*File, error) {
+	var cfg openConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return open(filename, cfg)
+}
+
+// OpenReader opens an HDF5 file of size bytes read from r. It reads HDF5
+// files embedded in archives, memory-mapped regions or other storage that
+// provides random access. r must stay valid until the file is closed, and
+// closing the file does not close r.
+//
+// The file has no name, so the source files of virtual datasets and the
+// files of external links and references are searched relative to the
+// current directory (see WithVirtualPrefix and WithExternalLinkPrefix).
+//
+// Example:
+//
+//	// Read a file stored in a zip archive without extracting it.
+//	f, err := hdf5.OpenReader(zipEntryReader, int64(entry.UncompressedSize64))
+func OpenReader(r io.ReaderAt, size int64, opts ...OpenOption) (*File, error) {
+	var cfg openConfig
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return openReader(r, nil, size, "", cfg)
+}
+
+// OpenBytes opens the HDF5 file image b. See OpenReader.
+func OpenBytes(b []byte, opts ...OpenOption) (*File, error) {
+	return OpenReader(bytes.NewReader(b), int64(len(b)), opts...)
+}
+
+// open opens filename with the settings cfg.
+func open(filename string, cfg openConfig) (*File, error) {
+	if cfg.driver != nil {
+		return openDriverFile(filename, cfg)
+	}
+
 	//nolint:gosec // G304: User-provided filename is intentional for HDF5 file library
 	f, err := os.Open(filename)
 	if err != nil {
 		return nil, utils.WrapError("file open failed", err)
 	}
 
-	// Verify HDF5 signature before reading superblock.
-	if !isHDF5File(f) {
-		_ = f.Close()
-		return nil, errors.New("not an HDF5 file")
-	}
-
 	// Get file size for address validation.
 	fi, err := f.Stat()
 	if err != nil {
 		_ = f.Close()
 		return nil, utils.WrapError("file stat failed", err)
 	}
-	fileSize := fi.Size()
 
-	sb, err := core.ReadSuperblock(f)
+	return openReader(f, f, fi.Size(), filename, cfg)
+}
+
+// openReader opens the file of size bytes read from r with the settings
+// cfg. closer is closed when the file is closed or fails to open.
+func openReader(r io.ReaderAt, closer io.Closer, size int64, filename string, cfg openConfig) (*File, error) {
+	fail := func(err error) (*File, error) {
+		if closer != nil {
+			_ = closer.Close()
+		}
+		return nil, err
+	}
+
+	// Verify HDF5 signature before reading superblock.
+	if !isHDF5File(r) {
+		return fail(errors.New("not an HDF5 file"))
+	}
+
+	sb, err := core.ReadSuperblock(r)
 	if err != nil {
-		_ = f.Close()
-		return nil, utils.WrapError("superblock read failed", err)
+		return fail(utils.WrapError("superblock read failed", err))
 	}
 
 	file := &File{
-		osFile:        f,
+		r:             r,
+		closer:        closer,
+		filename:      filename,
 		sb:            sb,
 		visitedBTrees: make(map[uint64]bool),
+		config:        cfg,
 	}
 
-	// Validate root group address.
+	// Validate root group address (unless the size is unknown).
 	//nolint:gosec // G115: File size is always positive, safe to convert int64 to uint64
-	if sb.RootGroup >= uint64(fileSize) {
-		_ = f.Close()
-		return nil, fmt.Errorf("root group address %d beyond file size %d",
-			sb.RootGroup, fileSize)
+	if size >= 0 && sb.RootGroup >= uint64(size) {
+		return fail(fmt.Errorf("root group address %d beyond file size %d",
+			sb.RootGroup, size))
 	}
 
 	// For all versions, sb.RootGroup now contains the correct object header address.
 	file.root, err = loadGroup(file, sb.RootGroup)
 	if err != nil {
-		_ = f.Close()
-		return nil, utils.WrapError("root group load failed", err)
+		return fail(utils.WrapError("root group load failed", err))
 	}
 
 	// Ensure root group always has name "/" (may be empty from object header)
@@ -91,11 +175,43 @@ func isHDF5File(r utils.ReaderAt) bool {
 // Close closes the HDF5 file and releases associated resources.
 // It is safe to call Close multiple times.
 func (f *File) Close() error {
-	if f.osFile == nil {
+	if f.r == nil {
 		return nil // Already closed.
 	}
-	err := f.osFile.Close()
-	f.osFile = nil // Prevent double close.
+	var err error
+	if f.closer != nil {
+		err = f.closer.Close()
+	}
+	f.r, f.closer = nil, nil // Prevent double close.
+
+	f.filesMu.Lock()
+	defer f.filesMu.Unlock()
+
+	for _, src := range f.virtualFiles {
+		if src != nil {
+			if closeErr := src.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	f.virtualFiles = nil
+
+	// Files returned by a custom opener belong to the caller.
+	if f.config.fileOpener == nil {
+		for _, file := range f.refFiles {
+			if closeErr := file.Close(); err == nil {
+				err = closeErr
+			}
+		}
+	}
+	f.refFiles = nil
+
+	for _, file := range f.linkFiles {
+		if closeErr := file.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	f.linkFiles = nil
 	return err
 }
 
@@ -106,22 +222,11 @@ func (f *File) Root() *Group {
 
 // Walk traverses the entire file structure, calling fn for each object.
 // Objects are visited in depth-first order starting from the root group.
+// Soft and external links are visited after the children of their group if
+// the file was opened WithFollowLinks.
 func (f *File) Walk(fn func(path string, obj Object)) {
-	walkGroup(f.root, "/", fn)
-}
-
-func walkGroup(g *Group, currentPath string, fn func(string, Object)) {
-	fn(currentPath, g)
-
-	for _, child := range g.Children() {
-		childPath := currentPath + child.Name()
-
-		if childGroup, ok := child.(*Group); ok {
-			walkGroup(childGroup, childPath+"/", fn)
-		} else {
-			fn(childPath, child)
-		}
-	}
+	w := &walker{fn: fn, follow: f.config.followLinks, ancestors: make(map[walkKey]bool)}
+	w.walkGroup(f.root, "/")
 }
 
 // SuperblockVersion returns the HDF5 superblock format version (0, 2, or 3).
@@ -136,7 +241,39 @@ func (f *File) Superblock() *core.Superblock {
 
 // Reader returns the underlying file reader for low-level access.
 func (f *File) Reader() io.ReaderAt {
-	return f.osFile
+	return f.r
+}
+
+// searchPaths returns the paths tried, in order, to open the file name named
+// by another file: the directories of the environment variable envVar, the
+// prefix, the directory of f, and finally name as given. Absolute names are
+// tried as given first; their base name is then searched the same way. A
+// leading "${ORIGIN}" in a directory stands for the directory of f.
+func (f *File) searchPaths(name, envVar, prefix string) []string {
+	var paths []string
+	if filepath.IsAbs(name) {
+		paths = append(paths, name)
+		name = filepath.Base(name)
+	}
+
+	origin := filepath.Dir(f.filename)
+	expand := func(prefix string) string {
+		if rest, ok := strings.CutPrefix(prefix, "${ORIGIN}"); ok {
+			return origin + rest
+		}
+		return prefix
+	}
+
+	for _, dir := range filepath.SplitList(os.Getenv(envVar)) {
+		if dir != "" {
+			paths = append(paths, filepath.Join(expand(dir), name))
+		}
+	}
+	if prefix != "" {
+		paths = append(paths, filepath.Join(expand(prefix), name))
+	}
+
+	return append(paths, filepath.Join(origin, name), name)
 }
 
 // readSignature reads 4 bytes at address and returns string.
@@ -0,0 +1,107 @@
+package hdf5
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateInMemory(t *testing.T) {
+	fw, err := CreateInMemory(WithSuperblockVersion(SuperblockV0))
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/values", Float64, []uint64{4}, WithChunkDims([]uint64{2}), WithGZIPCompression(6))
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{1, 2, 3, 4}))
+
+	// The file is incomplete until the writer is closed.
+	_, err = fw.Bytes()
+	require.ErrorContains(t, err, "still open")
+	require.NoError(t, fw.Close())
+
+	image, err := fw.Bytes()
+	require.NoError(t, err)
+	var buf bytes.Buffer
+	n, err := fw.WriteTo(&buf)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(image)), n)
+	require.Equal(t, image, buf.Bytes())
+
+	f, err := OpenBytes(image)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.Equal(t, uint8(0), f.SuperblockVersion())
+	rds, err := f.OpenDataset("/values")
+	require.NoError(t, err)
+	got, err := rds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4}, got)
+}
+
+func TestInMemory_BackingStore(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "backing.h5")
+
+	fw, err := CreateInMemory(WithBackingStore(filename))
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{1, 2, 3}))
+
+	// Nothing is written to disk before Close.
+	_, err = os.Stat(filename)
+	require.ErrorIs(t, err, os.ErrNotExist)
+	require.NoError(t, fw.Close())
+
+	// Load the saved file into memory, modify it and save it again.
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	fw, err = OpenInMemory(data, OpenReadWrite, WithBackingStore(filename))
+	require.NoError(t, err)
+	ds, err = fw.OpenDataset("/data")
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteAttribute("scale", float64(0.5)))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rds, err := f.OpenDataset("/data")
+	require.NoError(t, err)
+	got, err := rds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3}, got)
+	scale, err := rds.ReadAttribute("scale")
+	require.NoError(t, err)
+	require.Equal(t, 0.5, scale)
+
+	image, err := fw.Bytes()
+	require.NoError(t, err)
+	saved, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	require.Equal(t, image, saved)
+}
+
+func TestInMemory_Errors(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "disk.h5")
+
+	// Backing stores are only for in-memory files.
+	_, err := CreateForWrite(filename, CreateTruncate, WithBackingStore(filepath.Join(dir, "other.h5")))
+	require.ErrorContains(t, err, "requires an in-memory file")
+	_, err = os.Stat(filename)
+	require.ErrorIs(t, err, os.ErrNotExist)
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+	_, err = fw.Bytes()
+	require.ErrorContains(t, err, "not in memory")
+
+	_, err = OpenForWrite(filename, OpenReadWrite, WithBackingStore(filename))
+	require.ErrorContains(t, err, "requires an in-memory file")
+
+	_, err = OpenInMemory([]byte("not an HDF5 file"), OpenReadWrite)
+	require.Error(t, err)
+}
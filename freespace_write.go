@@ -0,0 +1,331 @@
+package hdf5
+
+import (
+	"fmt"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/structures"
+)
+
+// WithFreeSpacePersistence keeps the free space of the file across sessions,
+// like H5Pset_file_space_strategy(fcpl, H5F_FSPACE_STRATEGY_FSM_AGGR, true, 1).
+//
+// Space released while a file is open (by Unlink, DeleteDataset, Resize, ...)
+// is always reused by later allocations of the same session. With
+// persistence, Close also stores the remaining free space in a free-space
+// manager referenced from the superblock extension, and OpenForWrite hands it
+// to the allocator again, so later sessions of this library and of the HDF5
+// library reuse it too.
+//
+// Files that already persist free space, such as files created by the HDF5
+// library with persistent free-space managers, keep doing so unless this
+// option disables it.
+//
+// Requires superblock version 2 or later.
+//
+// Example:
+//
+//	fw, err := hdf5.CreateForWrite("data.h5", hdf5.CreateTruncate,
+//	    hdf5.WithFreeSpacePersistence(true))
+func WithFreeSpacePersistence(enable bool) WriteOption {
+	return func(cfg *FileWriteConfig) {
+		cfg.PersistFreeSpace = enable
+		cfg.persistFreeSpaceSet = true
+	}
+}
+
+// checkFreeSpacePersistence checks that a file with the given superblock
+// version can persist free space if that was requested.
+func (cfg *FileWriteConfig) checkFreeSpacePersistence(sbVersion uint8) error {
+	if cfg.PersistFreeSpace && sbVersion < core.Version2 {
+		return fmt.Errorf("free-space persistence requires superblock version 2 or later, got %d", sbVersion)
+	}
+	return nil
+}
+
+// hasSuperExtension reports whether the superblock has an extension. Files
+// created by this writer store 0 for none.
+func hasSuperExtension(sb *core.Superblock) bool {
+	return sb.SuperExtension != 0 && sb.SuperExtension != ^uint64(0)
+}
+
+// loadFreeSpace reads the File Space Info message of the superblock extension
+// and hands the sections of its persistent free-space managers to the
+// allocator.
+//
+// The message is ignored when it is marked as modified by a writer that did
+// not know it, as the HDF5 library does (H5F__super_read): its managers may
+// not match the file any more.
+func (fw *FileWriter) loadFreeSpace() error {
+	sb := fw.file.sb
+	if !hasSuperExtension(sb) {
+		return nil
+	}
+
+	ext, err := core.ReadObjectHeader(fw.writer.Reader(), sb.SuperExtension, sb)
+	if err != nil {
+		return fmt.Errorf("failed to read superblock extension: %w", err)
+	}
+
+	var infoMsg *core.HeaderMessage
+	for _, msg := range ext.Messages {
+		if msg.Type == core.MsgFileSpaceInfo {
+			infoMsg = msg
+			break
+		}
+	}
+	if infoMsg == nil || infoMsg.Flags&core.MsgFlagWasUnknown != 0 {
+		return nil
+	}
+
+	info, err := core.ParseFileSpaceInfoMessage(infoMsg.Data, sb)
+	if err != nil {
+		return fmt.Errorf("failed to parse file space info: %w", err)
+	}
+	fw.fileSpaceInfo = info
+
+	if info.Strategy != core.FileSpaceStrategyFSMAggr {
+		// Paged aggregation places objects within file space pages, which
+		// this writer does not do. Its managers are left as they are.
+		if fw.config.persistFreeSpaceSet {
+			return fmt.Errorf("free-space persistence is not supported for file space strategy %d", info.Strategy)
+		}
+		return nil
+	}
+	if !info.Persist {
+		return nil
+	}
+	if !fw.config.persistFreeSpaceSet {
+		fw.config.PersistFreeSpace = true
+	}
+
+	for _, addr := range info.ManagerAddresses {
+		if addr == ^uint64(0) {
+			continue
+		}
+		fsm, err := structures.ReadFreeSpaceManager(fw.writer.Reader(), addr, sb)
+		if err != nil {
+			return fmt.Errorf("failed to read free-space manager at %d: %w", addr, err)
+		}
+		for _, section := range fsm.Sections {
+			if err := fw.writer.Free(section.Offset, section.Size); err != nil {
+				return fmt.Errorf("failed to free section %v: %w", section, err)
+			}
+		}
+		fw.staleFreeSpace = append(fw.staleFreeSpace,
+			structures.FreeSpaceSection{Offset: addr, Size: structures.FreeSpaceHeaderSize(sb)})
+		if fsm.SectionsAddress != ^uint64(0) && fsm.SectionsSize > 0 {
+			fw.staleFreeSpace = append(fw.staleFreeSpace,
+				structures.FreeSpaceSection{Offset: fsm.SectionsAddress, Size: fsm.SectionsSize})
+		}
+	}
+
+	return nil
+}
+
+// persistFreeSpace stores the free space of the allocator in a free-space
+// manager and updates the File Space Info message of the superblock extension.
+// It does nothing unless the file persists free space or did so before.
+//
+// The manager is placed at the end of the file, after everything it
+// describes, and the superblock is rewritten with the new end of file.
+func (fw *FileWriter) persistFreeSpace() error {
+	wasPersisting := fw.fileSpaceInfo != nil && fw.fileSpaceInfo.Persist
+	if !fw.config.PersistFreeSpace && !wasPersisting {
+		return nil
+	}
+	if fw.fileSpaceInfo != nil && fw.fileSpaceInfo.Strategy != core.FileSpaceStrategyFSMAggr {
+		return nil
+	}
+
+	sb := fw.file.sb
+	info := core.NewFileSpaceInfoMessage(core.FileSpaceStrategyFSMAggr, fw.config.PersistFreeSpace)
+	if fw.fileSpaceInfo != nil {
+		info.Threshold = fw.fileSpaceInfo.Threshold
+		info.PageSize = fw.fileSpaceInfo.PageSize
+		info.PageEndMetaThreshold = fw.fileSpaceInfo.PageEndMetaThreshold
+	}
+
+	// The previous managers are rewritten, so their space is free as well.
+	for _, block := range fw.staleFreeSpace {
+		if err := fw.writer.Free(block.Offset, block.Size); err != nil {
+			return fmt.Errorf("failed to free free-space manager block: %w", err)
+		}
+	}
+	fw.staleFreeSpace = nil
+
+	// Allocate the superblock extension first: the manager must not list
+	// space that is used afterwards.
+	slot, err := fw.prepareFileSpaceInfo(core.FileSpaceInfoMessageSize(info.Persist, sb))
+	if err != nil {
+		return err
+	}
+
+	if info.Persist {
+		if err := fw.writeFreeSpaceManager(info); err != nil {
+			return err
+		}
+	}
+
+	data, err := core.EncodeFileSpaceInfoMessage(info, sb)
+	if err != nil {
+		return fmt.Errorf("failed to encode file space info: %w", err)
+	}
+	if err := slot.write(fw, data); err != nil {
+		return fmt.Errorf("failed to write file space info: %w", err)
+	}
+
+	eof := fw.writer.EndOfFile()
+	if err := fw.extendFile(eof); err != nil {
+		return err
+	}
+	if err := sb.WriteTo(fw.writer, eof); err != nil {
+		return fmt.Errorf("failed to write superblock: %w", err)
+	}
+
+	return nil
+}
+
+// writeFreeSpaceManager writes the free blocks of the allocator to a new
+// free-space manager at the end of the file and records it in info. Nothing
+// is written when no space is free.
+func (fw *FileWriter) writeFreeSpaceManager(info *core.FileSpaceInfoMessage) error {
+	sb := fw.file.sb
+	allocator := fw.writer.Allocator()
+
+	free := allocator.FreeBlocks()
+	if len(free) == 0 {
+		return nil
+	}
+	sections := make([]structures.FreeSpaceSection, len(free))
+	for i, block := range free {
+		sections[i] = structures.FreeSpaceSection{Offset: block.Offset, Size: block.Size}
+	}
+
+	eoa := allocator.EndOfFile()
+	headerAddr, err := allocator.AllocateAtEnd(structures.FreeSpaceHeaderSize(sb))
+	if err != nil {
+		return fmt.Errorf("failed to allocate free-space manager: %w", err)
+	}
+	sectionsAddr, err := allocator.AllocateAtEnd(structures.FreeSpaceSectionsSize(sections, sb))
+	if err != nil {
+		return fmt.Errorf("failed to allocate free-space sections: %w", err)
+	}
+	if err := structures.WriteFreeSpaceManager(fw.writer, headerAddr, sectionsAddr, sections, sb); err != nil {
+		return err
+	}
+
+	// The file space of all memory types is managed together, which the
+	// HDF5 library stores in the superblock manager (H5FD_MEM_SUPER).
+	info.EOAPreFSMAlloc = eoa
+	info.ManagerAddresses[0] = headerAddr
+	return nil
+}
+
+// fileSpaceInfoSlot is where persistFreeSpace writes the File Space Info
+// message: over an existing message of the same size, or as the last message
+// of a new superblock extension.
+type fileSpaceInfoSlot struct {
+	msgAddr uint64 // Header address of the message to overwrite (version 1 extension)
+
+	ext     *core.ObjectHeaderWriter // New extension, nil when overwriting
+	extAddr uint64
+}
+
+// fileSpaceInfoFlags are the message flags the HDF5 library uses for the
+// File Space Info message.
+const fileSpaceInfoFlags = core.MsgFlagDontShare | core.MsgFlagMarkIfUnknown
+
+// prepareFileSpaceInfo finds or allocates the place of a File Space Info
+// message of the given size.
+//
+// A message of the same size in a version 1 extension is overwritten in
+// place. Otherwise a new version 1 extension holding the other messages of
+// the current one is allocated, and the space of the current one is freed.
+func (fw *FileWriter) prepareFileSpaceInfo(size int) (*fileSpaceInfoSlot, error) {
+	sb := fw.file.sb
+	var messages []core.MessageWriter
+	var old []core.HeaderRegion
+
+	if hasSuperExtension(sb) {
+		ext, err := core.ReadObjectHeader(fw.writer.Reader(), sb.SuperExtension, sb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read superblock extension: %w", err)
+		}
+		old, err = core.ObjectHeaderRegions(fw.writer.Reader(), sb.SuperExtension, ext, sb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read superblock extension: %w", err)
+		}
+		for _, msg := range ext.Messages {
+			switch {
+			case msg.Type == core.MsgFileSpaceInfo && ext.Version == 1 && len(msg.Data) == size:
+				return &fileSpaceInfoSlot{msgAddr: msg.Offset}, nil
+			case msg.Type == core.MsgFileSpaceInfo, msg.Type == core.MsgNil:
+			default:
+				messages = append(messages, core.MessageWriter{Type: msg.Type, Flags: msg.Flags, Data: msg.Data})
+			}
+		}
+	}
+
+	ext := &core.ObjectHeaderWriter{
+		Version:  1,
+		RefCount: 1,
+		Messages: append(messages, core.MessageWriter{
+			Type:  core.MsgFileSpaceInfo,
+			Flags: fileSpaceInfoFlags,
+			Data:  make([]byte, size),
+		}),
+	}
+	addr, err := fw.writer.Allocate(ext.Size())
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate superblock extension: %w", err)
+	}
+
+	// The superblock only points to the new extension once it is written,
+	// so the old one is freed after the new one is allocated.
+	for _, region := range old {
+		if err := fw.writer.Free(region.Address, region.Size); err != nil {
+			return nil, fmt.Errorf("failed to free superblock extension: %w", err)
+		}
+	}
+
+	return &fileSpaceInfoSlot{ext: ext, extAddr: addr}, nil
+}
+
+// write stores the encoded message in the slot.
+func (s *fileSpaceInfoSlot) write(fw *FileWriter, data []byte) error {
+	if s.ext == nil {
+		// Version 1 message header: type (2), size (2), flags (1), reserved (3).
+		// Rewriting the flags clears a stale "was unknown" mark.
+		if err := fw.writer.WriteAtAddress([]byte{fileSpaceInfoFlags}, s.msgAddr+4); err != nil {
+			return err
+		}
+		return fw.writer.WriteAtAddress(data, s.msgAddr+8)
+	}
+
+	s.ext.Messages[len(s.ext.Messages)-1].Data = data
+	if _, err := s.ext.WriteTo(fw.writer, s.extAddr); err != nil {
+		return err
+	}
+	fw.file.sb.SuperExtension = s.extAddr
+	return nil
+}
+
+// extendFile makes the file at least eof bytes long, so the end of file
+// stored in the superblock does not point past allocated but unwritten
+// space, which the HDF5 library reports as a truncated file.
+func (fw *FileWriter) extendFile(eof uint64) error {
+	storage := fw.writer.Storage()
+	size, err := storage.Size()
+	if err != nil {
+		return err
+	}
+	//nolint:gosec // G115: HDF5 addresses fit in int64
+	if size < int64(eof) {
+		//nolint:gosec // G115: HDF5 addresses fit in int64
+		if err := storage.Truncate(int64(eof)); err != nil {
+			return fmt.Errorf("failed to extend file: %w", err)
+		}
+	}
+	return nil
+}
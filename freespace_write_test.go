@@ -0,0 +1,284 @@
+package hdf5
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/structures"
+	"github.com/meko-christian/go-hdf5/internal/writer"
+	"github.com/stretchr/testify/require"
+)
+
+// readFileSpaceInfo returns the File Space Info message of a file's
+// superblock extension.
+func readFileSpaceInfo(t *testing.T, filename string) (*core.FileSpaceInfoMessage, *core.HeaderMessage) {
+	t.Helper()
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	require.True(t, hasSuperExtension(f.sb))
+	ext, err := core.ReadObjectHeader(f.r, f.sb.SuperExtension, f.sb)
+	require.NoError(t, err)
+	for _, msg := range ext.Messages {
+		if msg.Type == core.MsgFileSpaceInfo {
+			info, err := core.ParseFileSpaceInfoMessage(msg.Data, f.sb)
+			require.NoError(t, err)
+			return info, msg
+		}
+	}
+	t.Fatal("no file space info message")
+	return nil, nil
+}
+
+func TestFreeSpacePersistence(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "persist.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate, WithFreeSpacePersistence(true))
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/scratch", Float64, []uint64{512})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write(make([]float64, 512)))
+	ds, err = fw.CreateDataset("/kept", Int32, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{1, 2, 3}))
+	require.NoError(t, fw.Unlink("/scratch"))
+	require.NoError(t, fw.Close())
+
+	// The free space is recorded in the file.
+	info, msg := readFileSpaceInfo(t, filename)
+	require.True(t, info.Persist)
+	require.Equal(t, core.FileSpaceStrategyFSMAggr, info.Strategy)
+	require.Equal(t, fileSpaceInfoFlags, msg.Flags)
+	require.Equal(t, info.EOAPreFSMAlloc, info.ManagerAddresses[0])
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	fsm, err := structures.ReadFreeSpaceManager(f.r, info.ManagerAddresses[0], f.sb)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// A later session reuses it.
+	fw, err = OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	free := fw.writer.Allocator().FreeBlocks()
+	require.Len(t, free, len(fsm.Sections))
+	for _, section := range fsm.Sections {
+		require.Contains(t, free, writer.AllocatedBlock{Offset: section.Offset, Size: section.Size})
+	}
+
+	addr, err := fw.writer.Allocate(1024)
+	require.NoError(t, err)
+	require.Less(t, addr, info.EOAPreFSMAlloc)
+	require.NoError(t, fw.Close())
+
+	f, err = Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	kept, err := f.OpenDataset("/kept")
+	require.NoError(t, err)
+	data, err := kept.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3}, data)
+}
+
+func TestFreeSpacePersistence_Disable(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "disable.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate, WithFreeSpacePersistence(true))
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/a", Float64, []uint64{16})
+	require.NoError(t, err)
+	require.NoError(t, fw.Unlink("/a"))
+	require.NoError(t, fw.Close())
+
+	fw, err = OpenForWrite(filename, OpenReadWrite, WithFreeSpacePersistence(false))
+	require.NoError(t, err)
+	require.NotEmpty(t, fw.writer.Allocator().FreeBlocks())
+	require.NoError(t, fw.Close())
+
+	info, _ := readFileSpaceInfo(t, filename)
+	require.False(t, info.Persist)
+
+	fw, err = OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	require.Empty(t, fw.writer.Allocator().FreeBlocks())
+	require.NoError(t, fw.Close())
+}
+
+func TestFreeSpacePersistence_SuperblockVersion(t *testing.T) {
+	_, err := CreateForWrite(filepath.Join(t.TempDir(), "v0.h5"), CreateTruncate,
+		WithSuperblockVersion(SuperblockV0), WithFreeSpacePersistence(true))
+	require.ErrorContains(t, err, "requires superblock version 2")
+}
+
+func TestFreeSpacePersistence_LibraryFile(t *testing.T) {
+	// Written by the HDF5 library with a persistent free-space manager
+	// holding one section.
+	const name = "h5clear_fsm_persist_equal.h5"
+	dir := t.TempDir()
+	copyTestdata(t, name, dir)
+	filename := filepath.Join(dir, name)
+
+	fw, err := OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	require.True(t, fw.config.PersistFreeSpace)
+	require.Equal(t, []writer.AllocatedBlock{{Offset: 0x5e0, Size: 0x220}}, fw.writer.Allocator().FreeBlocks())
+
+	// New allocations take the free section.
+	addr, err := fw.writer.Allocate(0x100)
+	require.NoError(t, err)
+	require.Equal(t, uint64(0x5e0), addr)
+	require.NoError(t, fw.writer.Free(addr, 0x100))
+
+	require.NoError(t, fw.Unlink("/dset"))
+	require.NoError(t, fw.Close())
+
+	info, _ := readFileSpaceInfo(t, filename)
+	require.True(t, info.Persist)
+	require.Equal(t, uint64(1), info.Threshold)
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	ok, err := f.Exists("/dset")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// The old section has merged with the space of the dataset.
+	fsm, err := structures.ReadFreeSpaceManager(f.r, info.ManagerAddresses[0], f.sb)
+	require.NoError(t, err)
+	require.NotEmpty(t, fsm.Sections)
+	var merged bool
+	for _, section := range fsm.Sections {
+		merged = merged || section.Offset == 0x5e0 && section.Size > 0x220
+	}
+	require.True(t, merged, "sections: %v", fsm.Sections)
+}
+
+func TestFreeSpaceReuse_ObjectHeaders(t *testing.T) {
+	// Object headers grow in place as attributes are added, so they must
+	// not be put into the space freed by Unlink.
+	filename := filepath.Join(t.TempDir(), "reuse.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/g")
+	require.NoError(t, err)
+	for _, name := range []string{"d1", "d2", "d3"} {
+		ds, err := fw.CreateDataset("/g/"+name, Int32, []uint64{4})
+		require.NoError(t, err)
+		require.NoError(t, ds.Write([]int32{1, 2, 3, 4}))
+		require.NoError(t, ds.WriteAttribute("units", "m"))
+	}
+	require.NoError(t, fw.Unlink("/g/d1"))
+	for _, name := range []string{"n1", "n2", "n3", "n4"} {
+		ds, err := fw.CreateDataset("/g/"+name, Int32, []uint64{4})
+		require.NoError(t, err)
+		require.NoError(t, ds.Write([]int32{5, 6, 7, 8}))
+		require.NoError(t, ds.WriteAttribute("units", "s"))
+	}
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	for _, tt := range []struct {
+		name  string
+		data  []float64
+		units string
+	}{
+		{"d2", []float64{1, 2, 3, 4}, "m"},
+		{"d3", []float64{1, 2, 3, 4}, "m"},
+		{"n1", []float64{5, 6, 7, 8}, "s"},
+		{"n2", []float64{5, 6, 7, 8}, "s"},
+		{"n3", []float64{5, 6, 7, 8}, "s"},
+		{"n4", []float64{5, 6, 7, 8}, "s"},
+	} {
+		ds, err := f.OpenDataset("/g/" + tt.name)
+		require.NoError(t, err, tt.name)
+		data, err := ds.Read()
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.data, data, tt.name)
+		units, err := ds.ReadAttribute("units")
+		require.NoError(t, err, tt.name)
+		require.Equal(t, tt.units, units, tt.name)
+	}
+}
+
+func TestFreeSpaceReuse_AttributeModification(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "attrs.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{4})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{1, 2, 3, 4}))
+	require.NoError(t, ds.WriteAttribute("note", "a rather long description"))
+	require.Empty(t, fw.writer.Allocator().FreeBlocks())
+
+	// A smaller value shrinks the header and frees its end, which the
+	// header takes back when it grows again.
+	require.NoError(t, ds.WriteAttribute("note", "short"))
+	free := fw.writer.Allocator().FreeBlocks()
+	require.Len(t, free, 1)
+	require.Equal(t, uint64(len("a rather long description")-len("short")), free[0].Size)
+	require.NoError(t, ds.WriteAttribute("note", "A RATHER LONG DESCRIPTION"))
+	require.Empty(t, fw.writer.Allocator().FreeBlocks())
+
+	// So does deleting an attribute.
+	require.NoError(t, ds.WriteAttribute("units", "m"))
+	require.NoError(t, ds.DeleteAttribute("units"))
+	require.Len(t, fw.writer.Allocator().FreeBlocks(), 1)
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rds, err := f.OpenDataset("/data")
+	require.NoError(t, err)
+	note, err := rds.ReadAttribute("note")
+	require.NoError(t, err)
+	require.Equal(t, "A RATHER LONG DESCRIPTION", note)
+	_, err = rds.ReadAttribute("units")
+	require.Error(t, err)
+}
+
+func TestFreeSpacePersistence_SuperblockExtension(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "ext.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate, WithFreeSpacePersistence(true))
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/a", Float64, []uint64{16})
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	// Dropping persistence writes a new, smaller File Space Info message,
+	// which takes a new superblock extension.
+	fw, err = OpenForWrite(filename, OpenReadWrite, WithFreeSpacePersistence(false))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+	f, err := Open(filename)
+	require.NoError(t, err)
+	ext := f.sb.SuperExtension
+	require.NoError(t, f.Close())
+
+	// Persisting again replaces it, and its space is recorded as free.
+	fw, err = OpenForWrite(filename, OpenReadWrite, WithFreeSpacePersistence(true))
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	info, _ := readFileSpaceInfo(t, filename)
+	f, err = Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.NotEqual(t, ext, f.sb.SuperExtension)
+	fsm, err := structures.ReadFreeSpaceManager(f.r, info.ManagerAddresses[0], f.sb)
+	require.NoError(t, err)
+	var freed bool
+	for _, section := range fsm.Sections {
+		freed = freed || section.Offset <= ext && ext < section.Offset+section.Size
+	}
+	require.True(t, freed, "extension at %d, sections: %v", ext, fsm.Sections)
+}
@@ -102,8 +102,9 @@ func (ghw *globalHeapWriter) createNewHeap(minObjectSize uint64) error {
 		collectionSize = ((neededSize + 4095) / 4096) * 4096
 	}
 
-	// Allocate space in file
-	heapAddr, err := ghw.fileWriter.writer.Allocate(collectionSize)
+	// Allocate space in file. Global heaps hold variable-length data and
+	// are stored with the raw data, in the raw data file of split files.
+	heapAddr, err := ghw.fileWriter.writer.AllocateRaw(collectionSize)
 	if err != nil {
 		return fmt.Errorf("allocate heap space: %w", err)
 	}
@@ -46,6 +46,15 @@ func (n *NamedDatatype) Datatype() *core.DatatypeMessage {
 	return n.datatype
 }
 
+// Attributes returns all attributes attached to this named datatype.
+func (n *NamedDatatype) Attributes() ([]*core.Attribute, error) {
+	header, err := core.ReadObjectHeader(n.file.r, n.address, n.file.sb)
+	if err != nil {
+		return nil, err
+	}
+	return header.Attributes, nil
+}
+
 // Name returns the dataset's name.
 func (d *Dataset) Name() string {
 	return d.name
@@ -58,7 +67,7 @@ func (d *Dataset) Address() uint64 {
 
 // Attributes returns all attributes attached to this dataset.
 func (d *Dataset) Attributes() ([]*core.Attribute, error) {
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, err
 	}
@@ -97,17 +106,18 @@ func (d *Dataset) ReadAttribute(name string) (interface{}, error) {
 }
 
 // Read reads the dataset values and returns them as float64 array.
-// Currently supports float64, float32, int32, int64 datatypes.
-// All values are converted to float64 for convenience.
+// All numeric datatypes are supported and converted to float64 for convenience.
+// Use ReadInto or ReadAs to read into the native element type without
+// losing precision (e.g. for large int64/uint64 values).
 func (d *Dataset) Read() ([]float64, error) {
 	// Read object header for this dataset.
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, err
 	}
 
 	// Use the dataset reader to get values.
-	return core.ReadDatasetFloat64(d.file.osFile, header, d.file.sb)
+	return core.ReadDatasetFloat64(d.file.reader(), header, d.file.sb)
 }
 
 // ReadStrings reads string dataset values and returns them as string array.
@@ -115,32 +125,33 @@ func (d *Dataset) Read() ([]float64, error) {
 // Variable-length strings are not yet supported.
 func (d *Dataset) ReadStrings() ([]string, error) {
 	// Read object header for this dataset.
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, err
 	}
 
 	// Use the string dataset reader.
-	return core.ReadDatasetStrings(d.file.osFile, header, d.file.sb)
+	return core.ReadDatasetStrings(d.file.reader(), header, d.file.sb)
 }
 
 // ReadCompound reads compound dataset values and returns them as array of maps.
 // Each map represents one compound structure instance with field names as keys.
 // Supports nested compound types, numeric types, and fixed-length strings.
+// ReadStructs decodes compound data into Go structs instead.
 func (d *Dataset) ReadCompound() ([]core.CompoundValue, error) {
 	// Read object header for this dataset.
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return nil, err
 	}
 
 	// Use the compound dataset reader.
-	return core.ReadDatasetCompound(d.file.osFile, header, d.file.sb)
+	return core.ReadDatasetCompound(d.file.reader(), header, d.file.sb)
 }
 
 // Info returns metadata about the dataset without reading actual values.
 func (d *Dataset) Info() (string, error) {
-	header, err := core.ReadObjectHeader(d.file.osFile, d.address, d.file.sb)
+	header, err := core.ReadObjectHeader(d.file.r, d.address, d.file.sb)
 	if err != nil {
 		return "", err
 	}
@@ -159,6 +170,8 @@ type Group struct {
 	name        string
 	address     uint64 // Address of object header (0 if traditional/SNOD format)
 	children    []Object
+	links       []LinkInfo            // All links, in storage order; see Links.
+	linkInfo    *core.LinkInfoMessage // Link Info message of new-style groups (nil otherwise)
 	symbolTable *structures.SymbolTable
 	localHeap   *structures.LocalHeap
 }
@@ -183,7 +196,7 @@ func (g *Group) Attributes() ([]*core.Attribute, error) {
 	}
 
 	// Read object header to get attributes.
-	header, err := core.ReadObjectHeader(g.file.osFile, g.address, g.file.sb)
+	header, err := core.ReadObjectHeader(g.file.r, g.address, g.file.sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read object header: %w", err)
 	}
@@ -220,7 +233,7 @@ func (g *Group) ReadAttribute(name string) (interface{}, error) {
 // cannot.
 func (g *Group) readDenseAttributes(header *core.ObjectHeader) ([]*core.Attribute, error) {
 	sb := g.file.sb
-	r := g.file.osFile
+	r := g.file.r
 
 	// Find AttributeInfo message in the header.
 	var attrInfo *core.AttributeInfoMessage
@@ -288,7 +301,7 @@ func loadGroup(file *File, address uint64) (*Group, error) {
 	}
 
 	// Check signature to determine group format.
-	sig := readSignature(file.osFile, address)
+	sig := readSignature(file.r, address)
 
 	// SNOD always means traditional format.
 	if sig == SignatureSNOD {
@@ -301,7 +314,7 @@ func loadGroup(file *File, address uint64) (*Group, error) {
 }
 
 func loadModernGroup(file *File, address uint64) (*Group, error) {
-	r := file.osFile
+	r := file.r
 	sb := file.sb
 
 	header, err := core.ReadObjectHeader(r, address, sb)
@@ -316,10 +329,10 @@ func loadModernGroup(file *File, address uint64) (*Group, error) {
 	}
 
 	// Load children only for groups.
-	// Note: For v0 files, the root group may have ObjectTypeUnknown because
+	// Note: For v0/v1 files, the root group may have ObjectTypeUnknown because
 	// it has no messages (symbol table info is cached in superblock).
 	isGroup := header.Type == core.ObjectTypeGroup ||
-		(header.Type == core.ObjectTypeUnknown && sb.Version == core.Version0)
+		(header.Type == core.ObjectTypeUnknown && sb.Version <= core.Version1)
 	if isGroup {
 		// First, try to parse Link messages (modern format).
 		hasLinkMessages := false
@@ -334,42 +347,43 @@ func loadModernGroup(file *File, address uint64) (*Group, error) {
 				}
 
 				// Process based on link type.
-				if linkMsg.IsHardLink() {
-					// Load the object that this link points to.
-					child, err := loadObject(file, linkMsg.ObjectAddress, linkMsg.Name)
-					if err != nil {
-						// Log warning but continue with other links.
-						// Some links might point to objects we don't support yet.
-						continue
-					}
-					group.children = append(group.children, child)
-				} else if linkMsg.IsSoftLink() {
-					// Soft link support deferred to v0.11.0-beta.
-					// Soft links are symbolic links within HDF5 file pointing to paths.
-					// Current implementation focuses on hard links (direct object references).
-					// Target version: v0.11.0-beta (write support phase)
+				if !linkMsg.IsHardLink() {
+					// Soft and external links are followed on lookup only.
+					group.addLink(linkMsg)
 					continue
 				}
+
+				// Load the object that this link points to.
+				child, err := loadObject(file, linkMsg.ObjectAddress, linkMsg.Name)
+				if err != nil {
+					// Log warning but continue with other links.
+					// Some links might point to objects we don't support yet.
+					group.addLink(linkMsg)
+					continue
+				}
+				group.addChild(linkFromMessage(linkMsg), child)
 			}
 		}
 
-		// If no inline link messages, check for dense link storage (LinkInfo → fractal heap + B-tree v2).
-		if !hasLinkMessages {
-			for _, msg := range header.Messages {
-				if msg.Type == core.MsgLinkInfo {
-					linkInfo, err := core.ParseLinkInfoMessage(msg.Data, sb)
-					if err != nil {
-						return nil, utils.WrapError("link info parse failed", err)
-					}
-					if linkInfo.HasFractalHeap() && linkInfo.HasNameBTree() {
-						if err := loadDenseGroupChildren(file, group, linkInfo, sb); err != nil {
-							return nil, utils.WrapError("dense group load failed", err)
-						}
-						hasLinkMessages = true
-						break
-					}
+		// The Link Info message tells whether creation order is tracked and
+		// where dense link storage lives.
+		for _, msg := range header.Messages {
+			if msg.Type == core.MsgLinkInfo {
+				group.linkInfo, err = core.ParseLinkInfoMessage(msg.Data, sb)
+				if err != nil {
+					return nil, utils.WrapError("link info parse failed", err)
 				}
+				break
+			}
+		}
+
+		// If no inline link messages, check for dense link storage (LinkInfo → fractal heap + B-tree v2).
+		if linkInfo := group.linkInfo; !hasLinkMessages && linkInfo != nil &&
+			linkInfo.HasFractalHeap() && linkInfo.HasNameBTree() {
+			if err := loadDenseGroupChildren(file, group, linkInfo, sb); err != nil {
+				return nil, utils.WrapError("dense group load failed", err)
 			}
+			hasLinkMessages = true
 		}
 
 		// Fallback to symbol table if no link messages found (older format).
@@ -393,11 +407,11 @@ func loadModernGroup(file *File, address uint64) (*Group, error) {
 				}
 			}
 
-			// For v0 superblocks: if no symbol table message found in object header,
+			// For v0/v1 superblocks: if no symbol table message found in object header,
 			// use cached B-tree and Heap addresses from superblock.
 			// This is ONLY valid for the ROOT GROUP - superblock cached addresses point to root's symbol table.
 			// For nested groups, symbol table addresses come from parent SNOD entry (CacheType=1).
-			if group.symbolTable == nil && sb.Version == core.Version0 && address == sb.RootGroup {
+			if group.symbolTable == nil && sb.Version <= core.Version1 && address == sb.RootGroup {
 				// Check if superblock has cached addresses
 				if sb.RootBTreeAddr != 0 && sb.RootHeapAddr != 0 {
 					group.symbolTable = &structures.SymbolTable{
@@ -421,7 +435,7 @@ func loadModernGroup(file *File, address uint64) (*Group, error) {
 
 func loadTraditionalGroup(file *File, address uint64) (*Group, error) {
 	// Parse the Symbol Table Node (SNOD).
-	node, err := structures.ParseSymbolTableNode(file.osFile, address, file.sb)
+	node, err := structures.ParseSymbolTableNode(file.r, address, file.sb)
 	if err != nil {
 		return nil, utils.WrapError("symbol table node parse failed", err)
 	}
@@ -439,13 +453,13 @@ func loadTraditionalGroup(file *File, address uint64) (*Group, error) {
 	var heap *structures.LocalHeap
 
 	// Read root object header to get heap address.
-	rootHeader, err := core.ReadObjectHeader(file.osFile, file.sb.RootGroup, file.sb)
+	rootHeader, err := core.ReadObjectHeader(file.r, file.sb.RootGroup, file.sb)
 	if err == nil {
 		// Find symbol table message.
 		for _, msg := range rootHeader.Messages {
 			if msg.Type == core.MsgSymbolTable && len(msg.Data) >= 16 {
 				heapAddr := file.sb.Endianness.Uint64(msg.Data[8:16])
-				heap, err = structures.LoadLocalHeap(file.osFile, heapAddr, file.sb)
+				heap, err = structures.LoadLocalHeap(file.r, heapAddr, file.sb)
 				if err != nil {
 					return nil, utils.WrapError("local heap load failed", err)
 				}
@@ -467,23 +481,24 @@ func loadTraditionalGroup(file *File, address uint64) (*Group, error) {
 
 	// Load children from SNOD entries.
 	for _, entry := range node.Entries {
-		// Skip soft links - they have CacheType=2 and ObjectAddress=HADDR_UNDEF.
-		// Following C library behavior: soft links are not resolved during file open.
-		if entry.IsSoftLink() {
-			continue
-		}
-
 		linkName, err := heap.GetString(entry.LinkNameOffset)
 		if err != nil {
 			return nil, utils.WrapError("link name read failed", err)
 		}
 
+		// Soft links have CacheType=2 and ObjectAddress=HADDR_UNDEF.
+		// Following C library behavior: soft links are not resolved during file open.
+		if entry.IsSoftLink() {
+			group.addSoftLink(heap, linkName, entry.CachedSoftLinkOffset)
+			continue
+		}
+
 		child, err := loadObject(file, entry.ObjectAddress, linkName)
 		if err != nil {
 			return nil, utils.WrapError("child load failed", err)
 		}
 
-		group.children = append(group.children, child)
+		group.addChild(hardLink(linkName, entry.ObjectAddress), child)
 	}
 
 	return group, nil
@@ -493,7 +508,7 @@ func loadTraditionalGroup(file *File, address uint64) (*Group, error) {
 // This is used by groups that store links in a fractal heap indexed by a B-tree v2,
 // rather than inline Link messages or old-style symbol tables.
 func loadDenseGroupChildren(file *File, group *Group, linkInfo *core.LinkInfoMessage, sb *core.Superblock) error {
-	r := file.osFile
+	r := file.r
 
 	// Open fractal heap for reading link data.
 	fh, err := structures.OpenFractalHeap(r, linkInfo.FractalHeapAddress,
@@ -503,14 +518,14 @@ func loadDenseGroupChildren(file *File, group *Group, linkInfo *core.LinkInfoMes
 		return fmt.Errorf("open fractal heap: %w", err)
 	}
 
-	// Load B-tree v2 to get all link name records.
-	bt := structures.NewWritableBTreeV2(0)
-	if err := bt.LoadFromFile(r, linkInfo.NameBTreeAddress, sb); err != nil {
+	// Read all link name records from the B-tree v2 (any depth).
+	records, err := structures.ReadLinkNameRecords(r, linkInfo.NameBTreeAddress, sb)
+	if err != nil {
 		return fmt.Errorf("load B-tree v2: %w", err)
 	}
 
 	// Iterate all records and load each linked object.
-	for _, rec := range bt.GetRecords() {
+	for _, rec := range records {
 		// Read the link message data from the fractal heap.
 		// Use spec-compliant read: official HDF5 files encode heap offsets
 		// from the start of the direct block (including header).
@@ -525,13 +540,16 @@ func loadDenseGroupChildren(file *File, group *Group, linkInfo *core.LinkInfoMes
 			continue
 		}
 
-		if linkMsg.IsHardLink() {
-			child, err := loadObject(file, linkMsg.ObjectAddress, linkMsg.Name)
-			if err != nil {
-				continue
-			}
-			group.children = append(group.children, child)
+		if !linkMsg.IsHardLink() {
+			group.addLink(linkMsg)
+			continue
 		}
+		child, err := loadObject(file, linkMsg.ObjectAddress, linkMsg.Name)
+		if err != nil {
+			group.addLink(linkMsg)
+			continue
+		}
+		group.addChild(linkFromMessage(linkMsg), child)
 	}
 
 	return nil
@@ -551,22 +569,22 @@ func (g *Group) loadChildren() error {
 	}
 	g.file.visitedBTrees[btreeAddr] = true
 
-	heap, err := structures.LoadLocalHeap(g.file.osFile, g.symbolTable.HeapAddress, g.file.sb)
+	heap, err := structures.LoadLocalHeap(g.file.r, g.symbolTable.HeapAddress, g.file.sb)
 	if err != nil {
 		return utils.WrapError("local heap load failed", err)
 	}
 
 	// Detect B-tree format by reading signature.
-	btreeSig := readSignature(g.file.osFile, btreeAddr)
+	btreeSig := readSignature(g.file.r, btreeAddr)
 
 	var entries []structures.BTreeEntry
 	switch btreeSig {
 	case "TREE":
 		// v1 B-tree format (used in v0 files and some v1 files).
-		entries, err = structures.ReadGroupBTreeEntries(g.file.osFile, btreeAddr, g.file.sb)
+		entries, err = structures.ReadGroupBTreeEntries(g.file.r, btreeAddr, g.file.sb)
 	case "BTRE":
 		// Modern B-tree format.
-		entries, err = structures.ReadBTreeEntries(g.file.osFile, btreeAddr, g.file.sb)
+		entries, err = structures.ReadBTreeEntries(g.file.r, btreeAddr, g.file.sb)
 	default:
 		return fmt.Errorf("unknown B-tree signature: %q at address 0x%X", btreeSig, btreeAddr)
 	}
@@ -576,37 +594,40 @@ func (g *Group) loadChildren() error {
 	}
 
 	for _, entry := range entries {
-		// Skip soft links - they are symbolic links stored in old symbol table format.
 		// Soft links have CacheType=2 and ObjectAddress=HADDR_UNDEF (0xFFFFFFFFFFFFFFFF).
 		// The target path is stored in local heap at CachedSoftLinkOffset.
 		// Like the C library, we don't resolve soft links during file open - only on explicit access.
 		if entry.IsSoftLink() {
+			if linkName, err := heap.GetString(entry.LinkNameOffset); err == nil {
+				g.addSoftLink(heap, linkName, entry.CachedSoftLinkOffset)
+			}
 			continue
 		}
 
 		// Check if this is an unnamed SNOD (offset 0 AND object is SNOD) - means we should inline its children.
 		// Note: offset 0 alone is NOT sufficient - it's a valid offset for the first string in the heap!
 		// We must verify the object at the address is actually a SNOD, not a regular object with name at offset 0.
-		sig := readSignature(g.file.osFile, entry.ObjectAddress)
+		sig := readSignature(g.file.r, entry.ObjectAddress)
 		if entry.LinkNameOffset == 0 && sig == SignatureSNOD {
 			// This is an unnamed SNOD container - load its children directly.
-			node, err := structures.ParseSymbolTableNode(g.file.osFile, entry.ObjectAddress, g.file.sb)
+			node, err := structures.ParseSymbolTableNode(g.file.r, entry.ObjectAddress, g.file.sb)
 			if err != nil {
 				return utils.WrapError("SNOD parse failed", err)
 			}
 
 			// Add each entry from the SNOD to this group.
 			for _, snodEntry := range node.Entries {
-				// Skip soft links in SNOD entries (same as above).
-				if snodEntry.IsSoftLink() {
-					continue
-				}
-
 				childName, err := heap.GetString(snodEntry.LinkNameOffset)
 				if err != nil {
 					return utils.WrapError("SNOD child name read failed", err)
 				}
 
+				// Soft links in SNOD entries (same as above).
+				if snodEntry.IsSoftLink() {
+					g.addSoftLink(heap, childName, snodEntry.CachedSoftLinkOffset)
+					continue
+				}
+
 				// For nested groups with CacheType=1, pass cached symbol table addresses.
 				var child Object
 				if snodEntry.CacheType == structures.CacheTypeSymbolTable && snodEntry.CachedBTreeAddr != 0 {
@@ -619,7 +640,7 @@ func (g *Group) loadChildren() error {
 					return utils.WrapError("SNOD child load failed", err)
 				}
 
-				g.children = append(g.children, child)
+				g.addChild(hardLink(childName, snodEntry.ObjectAddress), child)
 			}
 			continue
 		}
@@ -642,7 +663,7 @@ func (g *Group) loadChildren() error {
 			return utils.WrapError("child load failed", err)
 		}
 
-		g.children = append(g.children, child)
+		g.addChild(hardLink(linkName, entry.ObjectAddress), child)
 	}
 
 	return nil
@@ -650,13 +671,13 @@ func (g *Group) loadChildren() error {
 
 func loadObject(file *File, address uint64, name string) (Object, error) {
 	// Check signature first - SNOD means traditional group format.
-	sig := readSignature(file.osFile, address)
+	sig := readSignature(file.r, address)
 	if sig == SignatureSNOD {
 		// SNOD is a symbol table node - it might be:
 		// 1. A true group with multiple children.
 		// 2. A redirect node with single entry (v0 files).
 
-		node, err := structures.ParseSymbolTableNode(file.osFile, address, file.sb)
+		node, err := structures.ParseSymbolTableNode(file.r, address, file.sb)
 		if err != nil {
 			return nil, err
 		}
@@ -664,7 +685,7 @@ func loadObject(file *File, address uint64, name string) (Object, error) {
 		// If SNOD has single entry, it's likely a redirect - load the target directly.
 		if len(node.Entries) == 1 {
 			// Get heap from root to read the name.
-			rootHeader, err := core.ReadObjectHeader(file.osFile, file.sb.RootGroup, file.sb)
+			rootHeader, err := core.ReadObjectHeader(file.r, file.sb.RootGroup, file.sb)
 			if err != nil {
 				return nil, err
 			}
@@ -673,7 +694,7 @@ func loadObject(file *File, address uint64, name string) (Object, error) {
 			for _, msg := range rootHeader.Messages {
 				if msg.Type == core.MsgSymbolTable && len(msg.Data) >= 16 {
 					heapAddr := file.sb.Endianness.Uint64(msg.Data[8:16])
-					heap, err = structures.LoadLocalHeap(file.osFile, heapAddr, file.sb)
+					heap, err = structures.LoadLocalHeap(file.r, heapAddr, file.sb)
 					if err != nil {
 						return nil, err
 					}
@@ -704,13 +725,16 @@ func loadObject(file *File, address uint64, name string) (Object, error) {
 	}
 
 	// Try reading object header (works for both v1 and v2).
-	header, err := core.ReadObjectHeader(file.osFile, address, file.sb)
+	header, err := core.ReadObjectHeader(file.r, address, file.sb)
 	if err != nil {
 		return nil, err
 	}
 
 	switch header.Type {
 	case core.ObjectTypeGroup:
+		if link := linkObject(header, name, file.sb); link != nil {
+			return &linkStub{link: *link}, nil
+		}
 		group, err := loadGroup(file, address)
 		if err != nil {
 			return nil, err
@@ -747,9 +771,9 @@ func loadObject(file *File, address uint64, name string) (Object, error) {
 			datatype: datatype,
 		}, nil
 	case core.ObjectTypeUnknown:
-		// For v0 files, groups may have no messages and thus ObjectTypeUnknown.
+		// For v0/v1 files, groups may have no messages and thus ObjectTypeUnknown.
 		// Try loading as a group first.
-		if file.sb.Version == core.Version0 {
+		if file.sb.Version <= core.Version1 {
 			group, err := loadGroup(file, address)
 			if err == nil {
 				if name != "" {
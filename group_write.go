@@ -52,6 +52,8 @@ type GroupWriter struct {
 //   - Scalars: int8, int16, int32, int64, uint8, uint16, uint32, uint64, float32, float64
 //   - Arrays: []int32, []float64, etc. (1D arrays only)
 //   - Strings: string (fixed-length, converted to byte array)
+//   - Attributes read from a file (*core.Attribute): written with their datatype,
+//     dataspace and raw value unchanged (not for variable-length or reference data)
 //
 // Parameters:
 //   - name: Attribute name (ASCII, no null bytes)
@@ -111,7 +113,7 @@ func validateGroupPath(path string) error {
 // createGroupStructures creates and writes the local heap, symbol table node, and B-tree for a group.
 // Returns (heapAddr, stNodeAddr, btreeAddr, error).
 func (fw *FileWriter) createGroupStructures() (uint64, uint64, uint64, error) {
-	offsetSize := int(fw.file.sb.OffsetSize)
+	sb := fw.file.sb
 
 	// Create local heap
 	heap := structures.NewLocalHeap(256)
@@ -120,33 +122,24 @@ func (fw *FileWriter) createGroupStructures() (uint64, uint64, uint64, error) {
 		return 0, 0, 0, fmt.Errorf("failed to allocate heap: %w", err)
 	}
 
-	// Create symbol table node
-	stNode := structures.NewSymbolTableNode(32)
-	entrySize := 2*offsetSize + 4 + 4 + 16
-	stNodeSize := uint64(8 + 32*entrySize) //nolint:gosec // Safe: small constant calculation
-	stNodeAddr, err := fw.writer.Allocate(stNodeSize)
+	// Create symbol table node (2K entries, K = group leaf node K)
+	stNodeAddr, err := fw.writer.Allocate(symbolTableNodeSize(sb))
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to allocate symbol table node: %w", err)
 	}
 
-	if err := stNode.WriteAt(fw.writer, stNodeAddr, uint8(offsetSize), 32, fw.file.sb.Endianness); err != nil { //nolint:gosec // Safe: offsetSize is 8
-		return 0, 0, 0, fmt.Errorf("failed to write symbol table node: %w", err)
+	if err := writeSymbolTableNodeAt(fw.writer, stNodeAddr, sb); err != nil {
+		return 0, 0, 0, err
 	}
 
-	// Create B-tree
-	btree := structures.NewBTreeNodeV1(0, 16)
-	if err := btree.AddKey(0, stNodeAddr); err != nil {
-		return 0, 0, 0, fmt.Errorf("failed to add B-tree key: %w", err)
-	}
-
-	btreeSize := uint64(24 + (2*16+1)*offsetSize + 2*16*offsetSize) //nolint:gosec // Safe: small constant calculation
-	btreeAddr, err := fw.writer.Allocate(btreeSize)
+	// Create B-tree (2K children, K = group internal node K)
+	btreeAddr, err := fw.writer.Allocate(groupBTreeNodeSize(sb))
 	if err != nil {
 		return 0, 0, 0, fmt.Errorf("failed to allocate B-tree: %w", err)
 	}
 
-	if err := btree.WriteAt(fw.writer, btreeAddr, uint8(offsetSize), 16, fw.file.sb.Endianness); err != nil { //nolint:gosec // Safe: offsetSize is 8
-		return 0, 0, 0, fmt.Errorf("failed to write B-tree: %w", err)
+	if err := writeBTreeNodeAt(fw.writer, btreeAddr, stNodeAddr, sb); err != nil {
+		return 0, 0, 0, err
 	}
 
 	// Write heap
@@ -257,7 +250,7 @@ func (fw *FileWriter) CreateGroup(path string) (*GroupWriter, error) {
 	messageDataSize := 1 + 2 + 1 + uint64(len(stMsg))
 	headerSize := 7 + messageDataSize
 
-	headerAddr, err := fw.writer.Allocate(headerSize)
+	headerAddr, err := fw.writer.AllocateObjectHeader(headerSize)
 	if err != nil {
 		return nil, fmt.Errorf("failed to allocate object header: %w", err)
 	}
@@ -310,7 +303,9 @@ func parsePath(path string) (parent, name string) {
 }
 
 // linkToParent links a child object to its parent group.
-// Links the child by adding an entry to the parent's symbol table.
+// Links the child by adding an entry to the parent's symbol table. Entries
+// are kept sorted by name and the B-tree keys are updated, so that readers
+// can search the group by name (H5G__node_found).
 //
 // Parameters:
 //   - parentPath: Path to parent group ("" or "/" for root)
@@ -321,19 +316,41 @@ func parsePath(path string) (parent, name string) {
 //   - error: If linking fails
 func (fw *FileWriter) linkToParent(parentPath, childName string, childAddr uint64) error {
 	// Get parent group metadata
-	var heapAddr, stNodeAddr uint64
+	var heapAddr, btreeAddr uint64
 	if parentPath == "" || parentPath == "/" {
 		// Root group - use root metadata
 		heapAddr = fw.rootHeapAddr
-		stNodeAddr = fw.rootStNodeAddr
+		btreeAddr = fw.rootBTreeAddr
 	} else {
 		// Non-root group - look up metadata
 		meta, exists := fw.groups[parentPath]
 		if !exists {
 			return fmt.Errorf("parent group %q not found (create it first)", parentPath)
 		}
+		if meta.dense != nil {
+			return meta.dense.AddLink(childName, childAddr)
+		}
 		heapAddr = meta.heapAddr
-		stNodeAddr = meta.stNodeAddr
+		btreeAddr = meta.btreeAddr
+	}
+
+	entry := structures.SymbolTableEntry{
+		ObjectAddress: childAddr,
+		CacheType:     0, // No cache (MVP)
+		Reserved:      0,
+	}
+	return fw.insertSymbolTableEntry(heapAddr, btreeAddr, childName, entry, "")
+}
+
+// insertSymbolTableEntry adds entry, named childName, to the symbol table
+// made of the local heap at heapAddr and the B-tree at btreeAddr. Full symbol
+// table nodes are split. For soft link entries, softTarget is stored in the
+// local heap too.
+func (fw *FileWriter) insertSymbolTableEntry(heapAddr, btreeAddr uint64, childName string,
+	entry structures.SymbolTableEntry, softTarget string,
+) error {
+	if btreeAddr == 0 {
+		return fmt.Errorf("group has no symbol table B-tree")
 	}
 
 	// Step 1: Read existing local heap
@@ -342,43 +359,70 @@ func (fw *FileWriter) linkToParent(parentPath, childName string, childAddr uint6
 		return fmt.Errorf("read local heap: %w", err)
 	}
 
-	// Step 2: Add child name to heap
-	nameOffset, err := heap.AddString(childName)
-	if err != nil {
-		return fmt.Errorf("add string to heap: %w", err)
+	// Step 2: Add child name (and soft link value) to heap, growing it first
+	// if they do not fit
+	needed := uint64(len(childName)) + 1
+	if entry.CacheType == structures.CacheTypeSoftLink {
+		needed += uint64(len(softTarget)) + 1
 	}
-
-	// Step 3: Read existing symbol table node
-	stNode, err := fw.readSymbolTableNode(stNodeAddr)
-	if err != nil {
-		return fmt.Errorf("read symbol table node: %w", err)
+	var oldAddr, oldSize uint64
+	if heap.Available() < needed {
+		if oldAddr, oldSize, err = fw.growLocalHeap(heap, needed); err != nil {
+			return fmt.Errorf("grow local heap: %w", err)
+		}
 	}
-
-	// Step 4: Add entry to symbol table
-	entry := structures.SymbolTableEntry{
-		LinkNameOffset: nameOffset,
-		ObjectAddress:  childAddr,
-		CacheType:      0, // No cache (MVP)
-		Reserved:       0,
+	entry.LinkNameOffset, err = heap.AddString(childName)
+	if err != nil {
+		return fmt.Errorf("add string to heap: %w", err)
 	}
-	if err := stNode.AddEntry(entry); err != nil {
-		return fmt.Errorf("add entry to symbol table: %w", err)
+	if entry.CacheType == structures.CacheTypeSoftLink {
+		valueOffset, err := heap.AddString(softTarget)
+		if err != nil {
+			return fmt.Errorf("add string to heap: %w", err)
+		}
+		entry.CachedSoftLinkOffset = uint32(valueOffset) //nolint:gosec // Local heap offsets fit in 32 bits
 	}
 
-	// Step 5: Write updated heap
+	// Step 3: Write updated heap, before any entry refers to the name
 	if err := heap.WriteTo(fw.writer, heapAddr); err != nil {
 		return fmt.Errorf("write heap: %w", err)
 	}
-
-	// Step 6: Write updated symbol table node
-	offsetSize := fw.file.sb.OffsetSize
-	if err := stNode.WriteAt(fw.writer, stNodeAddr, offsetSize, 32, fw.file.sb.Endianness); err != nil {
-		return fmt.Errorf("write symbol table: %w", err)
+	if oldSize > 0 {
+		if err := fw.writer.Free(oldAddr, oldSize); err != nil {
+			return fmt.Errorf("free local heap data: %w", err)
+		}
 	}
 
+	// Step 4: Insert entry into the symbol table node covering its name
+	err = structures.InsertGroupSymbolTableEntry(fw.writer, fw.writer, fw.writer.Allocator(), btreeAddr,
+		heap, childName, entry, fw.file.sb)
+	if err != nil {
+		return fmt.Errorf("add entry to symbol table: %w", err)
+	}
 	return nil
 }
 
+// growLocalHeap doubles the data segment of heap until needed more bytes fit,
+// as the HDF5 library does. The data segment grows in place if the space
+// after it is free and moves otherwise; the old data segment is then
+// returned, to be freed once the heap header points to the new one.
+func (fw *FileWriter) growLocalHeap(heap *structures.LocalHeap, needed uint64) (oldAddr, oldSize uint64, err error) {
+	used := heap.DataSegmentSize - heap.Available()
+	size := max(heap.DataSegmentSize, 16)
+	for size-used < needed {
+		size *= 2
+	}
+
+	addr := heap.DataSegmentAddress
+	if fw.writer.Allocator().Extend(addr+heap.DataSegmentSize, size-heap.DataSegmentSize) != nil {
+		oldAddr, oldSize = addr, heap.DataSegmentSize
+		if addr, err = fw.writer.Allocate(size); err != nil {
+			return 0, 0, err
+		}
+	}
+	return oldAddr, oldSize, heap.Resize(addr, size)
+}
+
 // readLocalHeap reads a local heap from the file at the specified address.
 // This is used to modify the heap by adding new strings for linking.
 //
@@ -407,20 +451,6 @@ func (fw *FileWriter) readLocalHeap(addr uint64) (*structures.LocalHeap, error)
 	return heap, nil
 }
 
-// readSymbolTableNode reads a symbol table node from the file at the specified address.
-// This is used to modify the node by adding new entries for linking.
-//
-// Parameters:
-//   - addr: Address of the symbol table node in the file
-//
-// Returns:
-//   - *structures.SymbolTableNode: The node structure (writable)
-//   - error: If read fails
-func (fw *FileWriter) readSymbolTableNode(addr uint64) (*structures.SymbolTableNode, error) {
-	// Use the existing ParseSymbolTableNode function from structures package
-	return structures.ParseSymbolTableNode(fw.writer, addr, fw.file.sb)
-}
-
 // CreateDenseGroup creates new dense group (HDF5 1.8+ format).
 //
 // Dense groups are more efficient for large numbers of links (>8).
@@ -488,6 +518,48 @@ func (fw *FileWriter) CreateDenseGroup(name string, links map[string]string) err
 	return nil
 }
 
+// beginDenseGroup starts the dense group at path. Links added to it (by
+// linkToParent) are collected until finishDenseGroup writes the group, which
+// cannot be changed afterwards.
+func (fw *FileWriter) beginDenseGroup(path string) error {
+	if err := validateGroupPath(path); err != nil {
+		return err
+	}
+	parent, _ := parsePath(path)
+	if parent != "" && parent != "/" {
+		if _, exists := fw.groups[parent]; !exists {
+			return fmt.Errorf("parent group %q does not exist (create it first)", parent)
+		}
+	}
+	if _, exists := fw.groups[path]; exists {
+		return fmt.Errorf("group %q already exists", path)
+	}
+
+	fw.groups[path] = &GroupMetadata{dense: writer.NewDenseGroupWriter(path)}
+	return nil
+}
+
+// finishDenseGroup writes the dense group started by beginDenseGroup, links
+// it to its parent and returns the address of its object header.
+func (fw *FileWriter) finishDenseGroup(path string) (uint64, error) {
+	meta, exists := fw.groups[path]
+	if !exists || meta.dense == nil {
+		return 0, fmt.Errorf("dense group %q was not started", path)
+	}
+	delete(fw.groups, path)
+
+	ohAddr, err := meta.dense.WriteToFile(fw.writer, fw.writer.Allocator(), fw.file.sb)
+	if err != nil {
+		return 0, fmt.Errorf("failed to write dense group: %w", err)
+	}
+
+	parent, name := parsePath(path)
+	if err := fw.linkToParent(parent, name, ohAddr); err != nil {
+		return 0, fmt.Errorf("failed to link to parent: %w", err)
+	}
+	return ohAddr, nil
+}
+
 // resolveObjectAddress resolves object path to file address.
 //
 // This is a helper for link creation - looks up the target object's
@@ -513,10 +585,10 @@ func (fw *FileWriter) resolveObjectAddress(path string) (uint64, error) {
 	parent, name := parsePath(path)
 
 	// Get parent group metadata
-	var stNodeAddr, heapAddr uint64
+	var btreeAddr, heapAddr uint64
 	if parent == "" || parent == "/" {
 		// Root group
-		stNodeAddr = fw.rootStNodeAddr
+		btreeAddr = fw.rootBTreeAddr
 		heapAddr = fw.rootHeapAddr
 	} else {
 		// Non-root group - look up metadata
@@ -524,32 +596,24 @@ func (fw *FileWriter) resolveObjectAddress(path string) (uint64, error) {
 		if !exists {
 			return 0, fmt.Errorf("parent group %q not found", parent)
 		}
-		stNodeAddr = meta.stNodeAddr
+		if meta.dense != nil {
+			return 0, fmt.Errorf("parent group %q is not written yet", parent)
+		}
+		btreeAddr = meta.btreeAddr
 		heapAddr = meta.heapAddr
 	}
 
-	// Read parent group's symbol table to find the object
-	stNode, err := fw.readSymbolTableNode(stNodeAddr)
+	// Search the parent group's symbol table for the object
+	heap, err := structures.LoadLocalHeap(fw.writer, heapAddr, fw.file.sb)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read symbol table: %w", err)
+		return 0, fmt.Errorf("failed to read local heap: %w", err)
 	}
-
-	heap, err := fw.readLocalHeap(heapAddr)
+	entry, err := structures.FindGroupBTreeEntry(fw.writer, btreeAddr, heap, name, fw.file.sb)
 	if err != nil {
-		return 0, fmt.Errorf("failed to read local heap: %w", err)
+		return 0, fmt.Errorf("failed to read symbol table: %w", err)
 	}
-
-	// Search for object in symbol table
-	for _, entry := range stNode.Entries {
-		// Get link name from heap
-		linkName, err := heap.GetString(entry.LinkNameOffset)
-		if err != nil {
-			continue
-		}
-
-		if linkName == name {
-			return entry.ObjectAddress, nil
-		}
+	if entry != nil {
+		return entry.ObjectAddress, nil
 	}
 
 	return 0, fmt.Errorf("object not found: %s", path)
@@ -1,7 +1,9 @@
 package hdf5
 
 import (
+	"fmt"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -107,6 +109,38 @@ func TestCreateGroup_Multiple(t *testing.T) {
 	require.NotNil(t, root)
 }
 
+func TestCreateGroup_LocalHeapGrows(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "heap_grows.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate, WithSuperblockVersion(SuperblockV0))
+	require.NoError(t, err)
+
+	// Far more name bytes than the 256 the group's local heap starts with.
+	var groupPaths []string
+	for i := 0; i < 40; i++ {
+		name := fmt.Sprintf("/group_with_a_rather_long_name_%02d", i)
+		_, err := fw.CreateGroup(name)
+		require.NoError(t, err)
+		require.NoError(t, fw.CreateSoftLink(name+"_link", name))
+		groupPaths = append(groupPaths, name+"/")
+	}
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var names []string
+	f.Walk(func(path string, _ Object) {
+		names = append(names, path)
+	})
+	for _, name := range groupPaths {
+		require.Contains(t, names, name)
+	}
+	links, err := f.Root().Links()
+	require.NoError(t, err)
+	require.Len(t, links, 2*len(groupPaths))
+}
+
 func TestParsePath(t *testing.T) {
 	tests := []struct {
 		name       string
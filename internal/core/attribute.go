@@ -445,6 +445,39 @@ func (a *Attribute) ReadValue() (interface{}, error) {
 			return values[0], nil
 		}
 		return values, nil
+
+	case DatatypeReference:
+		// Object references (ObjectRef), region references (RegionRef) or
+		// revised references (Ref).
+		switch a.Datatype.ReferenceType() {
+		case ReferenceTypeObject:
+			values, err := DecodeObjectReferences(a.Data, a.Datatype, totalElements)
+			if err != nil {
+				return nil, err
+			}
+			if isScalar {
+				return values[0], nil
+			}
+			return values, nil
+		case ReferenceTypeRegion:
+			values, err := DecodeRegionReferences(a.Data, a.Datatype, totalElements)
+			if err != nil {
+				return nil, err
+			}
+			if isScalar {
+				return values[0], nil
+			}
+			return values, nil
+		case ReferenceTypeObject2, ReferenceTypeRegion2, ReferenceTypeAttribute:
+			values, err := DecodeRefs(a.reader, a.Data, a.Datatype, totalElements, a.offsetSize)
+			if err != nil {
+				return nil, err
+			}
+			if isScalar {
+				return values[0], nil
+			}
+			return values, nil
+		}
 	}
 
 	return nil, fmt.Errorf("unsupported datatype class %d or size %d", a.Datatype.Class, a.Datatype.Size)
@@ -244,9 +244,19 @@ type ChunkEntry struct {
 // CollectAllChunks recursively collects all chunks from B-tree.
 // This handles both leaf and non-leaf nodes.
 func (node *BTreeV1Node) CollectAllChunks(r io.ReaderAt, offsetSize uint8, chunkDims []uint64) ([]ChunkEntry, error) {
+	return node.collectAllChunks(r, offsetSize, chunkDims, 0)
+}
+
+// collectAllChunks is CollectAllChunks rejecting nodes with more than
+// maxEntries children (2K of the file's indexed storage K; 0 for no limit).
+func (node *BTreeV1Node) collectAllChunks(r io.ReaderAt, offsetSize uint8, chunkDims []uint64, maxEntries int) ([]ChunkEntry, error) {
 	ndims := len(chunkDims)
 	var chunks []ChunkEntry
 
+	if maxEntries > 0 && int(node.EntriesUsed) > maxEntries {
+		return nil, fmt.Errorf("b-tree node has %d entries, more than 2K = %d", node.EntriesUsed, maxEntries)
+	}
+
 	// If this is a leaf node (level 0), children point to actual chunks.
 	if node.NodeLevel == 0 {
 		for i := 0; i < int(node.EntriesUsed); i++ {
@@ -270,7 +280,7 @@ func (node *BTreeV1Node) CollectAllChunks(r io.ReaderAt, offsetSize uint8, chunk
 		}
 
 		// Recursively collect chunks from child.
-		childChunks, err := childNode.CollectAllChunks(r, offsetSize, chunkDims)
+		childChunks, err := childNode.collectAllChunks(r, offsetSize, chunkDims, maxEntries)
 		if err != nil {
 			return nil, fmt.Errorf("failed to collect chunks from child at 0x%x: %w", childAddr, err)
 		}
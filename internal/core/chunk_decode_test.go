@@ -0,0 +1,53 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeChunks(t *testing.T) {
+	// 20 chunks of 4 bytes each, every byte holding its chunk number.
+	var file []byte
+	var chunks []ChunkEntry
+	for i := 0; i < 20; i++ {
+		chunks = append(chunks, ChunkEntry{Key: ChunkKey{Nbytes: 4}, Address: uint64(len(file))})
+		file = append(file, bytes.Repeat([]byte{byte(i)}, 4)...)
+	}
+	r := bytes.NewReader(file)
+
+	for _, workers := range []int{1, 3} {
+		var order []int
+		err := DecodeChunks(r, chunks, nil, workers, func(i int, data []byte) error {
+			order = append(order, i)
+			require.Equal(t, bytes.Repeat([]byte{byte(i)}, 4), data)
+			return nil
+		})
+		require.NoError(t, err)
+		require.Len(t, order, len(chunks))
+		for i, got := range order {
+			require.Equal(t, i, got, "chunks are passed to fn in order")
+		}
+
+		// Errors of fn stop decoding.
+		stop := errors.New("stop")
+		calls := 0
+		err = DecodeChunks(r, chunks, nil, workers, func(i int, _ []byte) error {
+			calls++
+			if i == 5 {
+				return stop
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, 6, calls)
+
+		// So do read errors.
+		bad := append([]ChunkEntry(nil), chunks...)
+		bad[7].Address = uint64(len(file))
+		err = DecodeChunks(r, bad, nil, workers, func(int, []byte) error { return nil })
+		require.ErrorContains(t, err, "failed to read chunk")
+	}
+}
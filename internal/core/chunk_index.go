@@ -0,0 +1,372 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/meko-christian/go-hdf5/internal/utils"
+)
+
+// unlimitedDim is the maximum dimension value marking an unlimited dimension.
+const unlimitedDim = ^uint64(0)
+
+// CollectChunks returns all allocated chunks of a chunked dataset, reading
+// whichever chunk index its layout message uses. Chunk keys carry scaled
+// chunk coordinates followed by a zero for the element-size dimension, as in
+// the v1 B-tree index.
+//
+// Partial edge chunks of datasets created with the "don't filter partial
+// edge chunks" flag get a filter mask with all filters disabled.
+func CollectChunks(r io.ReaderAt, layout *DataLayoutMessage, dataspace *DataspaceMessage, sb *Superblock) ([]ChunkEntry, error) {
+	if !layout.IsChunked() {
+		return nil, errors.New("layout is not chunked")
+	}
+	if len(layout.ChunkSize) < len(dataspace.Dimensions) {
+		return nil, fmt.Errorf("chunk rank %d does not match dataspace rank %d",
+			len(layout.ChunkSize)-1, len(dataspace.Dimensions))
+	}
+
+	// Storage is not allocated until data is written.
+	if isUndefinedAddress(layout.DataAddress, sb.OffsetSize) {
+		return nil, nil
+	}
+
+	grid, err := newChunkGrid(layout, dataspace)
+	if err != nil {
+		return nil, err
+	}
+
+	var chunks []ChunkEntry
+	switch layout.ChunkIndex {
+	case ChunkIndexBTreeV1:
+		var btree *BTreeV1Node
+		btree, err = ParseBTreeV1Node(r, layout.DataAddress, sb.OffsetSize, len(layout.ChunkSize), layout.ChunkSize)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse B-tree: %w", err)
+		}
+		chunks, err = btree.collectAllChunks(r, sb.OffsetSize, layout.ChunkSize, 2*int(sb.IndexedStorageK))
+	case ChunkIndexSingleChunk:
+		chunks, err = singleChunk(layout, grid)
+	case ChunkIndexImplicit:
+		chunks = implicitChunks(layout, grid)
+	case ChunkIndexFixedArray:
+		chunks, err = readFixedArrayChunks(r, layout.DataAddress, grid, sb)
+	case ChunkIndexExtensibleArray:
+		chunks, err = readExtensibleArrayChunks(r, layout.DataAddress, grid, sb)
+	case ChunkIndexBTreeV2:
+		chunks, err = readBTreeV2Chunks(r, layout.DataAddress, grid, sb)
+	default:
+		return nil, fmt.Errorf("unsupported chunk index type: %d", layout.ChunkIndex)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read chunk index: %w", err)
+	}
+
+	if layout.ChunkFlags&ChunkFlagDontFilterPartialEdgeChunks != 0 {
+		for i := range chunks {
+			if grid.isPartialChunk(chunks[i].Key.Scaled) {
+				chunks[i].Key.FilterMask = math.MaxUint32
+			}
+		}
+	}
+
+	return chunks, nil
+}
+
+// chunkGrid describes how a dataspace is divided into chunks.
+// Reference: H5Dchunk.c - H5D__chunk_set_info_real.
+type chunkGrid struct {
+	dims          []uint64 // Current dataset dimensions.
+	chunkDims     []uint64 // Chunk dimensions, without the element-size dimension.
+	chunks        []uint64 // Chunks per dimension for the current dimensions.
+	maxChunks     []uint64 // Chunks per dimension for the maximum dimensions.
+	maxDownChunks []uint64 // Linear index strides over maxChunks.
+	chunkBytes    uint64   // Size of an unfiltered chunk in bytes.
+	unlimitedDim  int      // Index of the unlimited dimension, or -1.
+}
+
+func newChunkGrid(layout *DataLayoutMessage, dataspace *DataspaceMessage) (*chunkGrid, error) {
+	ndims := len(dataspace.Dimensions)
+	g := &chunkGrid{
+		dims:          dataspace.Dimensions,
+		chunkDims:     layout.ChunkSize[:ndims],
+		chunks:        make([]uint64, ndims),
+		maxChunks:     make([]uint64, ndims),
+		maxDownChunks: make([]uint64, ndims),
+		unlimitedDim:  -1,
+	}
+
+	g.chunkBytes = 1
+	for _, d := range layout.ChunkSize {
+		if d == 0 {
+			return nil, errors.New("chunk dimension is zero")
+		}
+		var err error
+		g.chunkBytes, err = utils.SafeMultiply(g.chunkBytes, d)
+		if err != nil {
+			return nil, fmt.Errorf("chunk size overflow: %w", err)
+		}
+	}
+
+	for i, dim := range g.dims {
+		maxDim := dim
+		if i < len(dataspace.MaxDims) {
+			maxDim = dataspace.MaxDims[i]
+		}
+		g.chunks[i] = ceilDiv(dim, g.chunkDims[i])
+		if maxDim == unlimitedDim {
+			g.maxChunks[i] = unlimitedDim
+			g.unlimitedDim = i
+		} else {
+			g.maxChunks[i] = ceilDiv(maxDim, g.chunkDims[i])
+		}
+	}
+	g.maxDownChunks = downChunks(g.maxChunks)
+
+	return g, nil
+}
+
+// ceilDiv returns a/b rounded up.
+func ceilDiv(a, b uint64) uint64 {
+	return (a + b - 1) / b
+}
+
+// downChunks returns the strides of a row-major linear index over counts
+// (H5VM_array_down). The first count never takes part, so it may be
+// unlimited.
+func downChunks(counts []uint64) []uint64 {
+	down := make([]uint64, len(counts))
+	acc := uint64(1)
+	for i := len(counts) - 1; i >= 0; i-- {
+		down[i] = acc
+		acc *= counts[i]
+	}
+	return down
+}
+
+// scaledFromIndex converts a linear chunk index to scaled coordinates using
+// the given strides. The result has a trailing zero for the element-size
+// dimension.
+func scaledFromIndex(index uint64, down []uint64) []uint64 {
+	scaled := make([]uint64, len(down)+1)
+	for i, d := range down {
+		scaled[i] = index / d
+		index %= d
+	}
+	return scaled
+}
+
+// indexFromScaled converts scaled coordinates to a linear chunk index
+// (H5VM_array_offset_pre).
+func indexFromScaled(scaled, down []uint64) uint64 {
+	var index uint64
+	for i, d := range down {
+		index += scaled[i] * d
+	}
+	return index
+}
+
+// inExtent reports whether a chunk lies within the current dimensions.
+func (g *chunkGrid) inExtent(scaled []uint64) bool {
+	for i, n := range g.chunks {
+		if scaled[i] >= n {
+			return false
+		}
+	}
+	return true
+}
+
+// isPartialChunk reports whether a chunk extends past the current dimensions.
+func (g *chunkGrid) isPartialChunk(scaled []uint64) bool {
+	for i, dim := range g.dims {
+		if (scaled[i]+1)*g.chunkDims[i] > dim {
+			return true
+		}
+	}
+	return false
+}
+
+// forEachChunk visits the scaled coordinates of all chunks in the current
+// extent in row-major order.
+func (g *chunkGrid) forEachChunk(fn func(scaled []uint64)) {
+	ndims := len(g.chunks)
+	for _, n := range g.chunks {
+		if n == 0 {
+			return
+		}
+	}
+	scaled := make([]uint64, ndims+1)
+	for {
+		fn(append([]uint64(nil), scaled...))
+
+		i := ndims - 1
+		for ; i >= 0; i-- {
+			scaled[i]++
+			if scaled[i] < g.chunks[i] {
+				break
+			}
+			scaled[i] = 0
+		}
+		if i < 0 {
+			return
+		}
+	}
+}
+
+// newChunkEntry builds a chunk entry, checking that the stored size fits the
+// 32-bit size field of chunk keys.
+func newChunkEntry(scaled []uint64, address, nbytes uint64, filterMask uint32) (ChunkEntry, error) {
+	if nbytes > math.MaxUint32 {
+		return ChunkEntry{}, fmt.Errorf("chunk at 0x%X too large: %d bytes", address, nbytes)
+	}
+	return ChunkEntry{
+		Key: ChunkKey{
+			Scaled:     scaled,
+			Nbytes:     uint32(nbytes),
+			FilterMask: filterMask,
+		},
+		Address: address,
+	}, nil
+}
+
+// singleChunk returns the only chunk of a single chunk index, whose address
+// is the layout's index address.
+// Reference: H5Dsingle.c.
+func singleChunk(layout *DataLayoutMessage, grid *chunkGrid) ([]ChunkEntry, error) {
+	nbytes := grid.chunkBytes
+	var filterMask uint32
+	if layout.ChunkFlags&ChunkFlagSingleIndexWithFilter != 0 {
+		nbytes = layout.ChunkIndexParams.FilteredChunkSize
+		filterMask = layout.ChunkIndexParams.FilterMask
+	}
+	entry, err := newChunkEntry(make([]uint64, len(grid.dims)+1), layout.DataAddress, nbytes, filterMask)
+	if err != nil {
+		return nil, err
+	}
+	return []ChunkEntry{entry}, nil
+}
+
+// implicitChunks returns the chunks of an implicit index. All chunks of the
+// maximum extent are allocated back to back, so a chunk's address follows
+// from its linear index.
+// Reference: H5Dnone.c - H5D__none_idx_get_addr.
+func implicitChunks(layout *DataLayoutMessage, grid *chunkGrid) []ChunkEntry {
+	var chunks []ChunkEntry
+	grid.forEachChunk(func(scaled []uint64) {
+		index := indexFromScaled(scaled, grid.maxDownChunks)
+		chunks = append(chunks, ChunkEntry{
+			Key: ChunkKey{
+				Scaled: scaled,
+				// Implicit indexes are only used without filters, and chunk sizes
+				// are limited to 4 GiB by the library.
+				Nbytes: uint32(grid.chunkBytes), //nolint:gosec // G115: see above
+			},
+			Address: layout.DataAddress + index*grid.chunkBytes,
+		})
+	})
+	return chunks
+}
+
+// arrayChunkElement decodes a fixed or extensible array element. Unfiltered
+// elements hold the chunk address only; filtered elements add the stored
+// chunk size and the filter mask. ok is false for unallocated chunks.
+// Reference: H5Dfarray.c - H5D__farray_filt_decode.
+func arrayChunkElement(element []byte, filtered bool, grid *chunkGrid, sb *Superblock) (address, nbytes uint64, filterMask uint32, ok bool) {
+	offsetSize := int(sb.OffsetSize)
+	address = readUint64(element, offsetSize, sb.Endianness)
+	if isUndefinedAddress(address, sb.OffsetSize) {
+		return 0, 0, 0, false
+	}
+	if !filtered {
+		return address, grid.chunkBytes, 0, true
+	}
+	sizeLen := len(element) - offsetSize - 4
+	nbytes = readVarUint(element[offsetSize : offsetSize+sizeLen])
+	filterMask = binary.LittleEndian.Uint32(element[offsetSize+sizeLen:])
+	return address, nbytes, filterMask, true
+}
+
+// arrayElementFiltered reports whether fixed or extensible array elements of
+// the given client class describe filtered chunks, validating the element
+// size against the superblock.
+func arrayElementFiltered(clientID, elementSize uint8, sb *Superblock) (bool, error) {
+	switch clientID {
+	case 0:
+		if int(elementSize) != int(sb.OffsetSize) {
+			return false, fmt.Errorf("invalid chunk element size %d", elementSize)
+		}
+		return false, nil
+	case 1:
+		sizeLen := int(elementSize) - int(sb.OffsetSize) - 4
+		if sizeLen < 1 || sizeLen > 8 {
+			return false, fmt.Errorf("invalid filtered chunk element size %d", elementSize)
+		}
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported array client class: %d", clientID)
+	}
+}
+
+// readBTreeV2Chunks reads a version 2 B-tree chunk index. Records hold the
+// chunk address, for filtered chunks the stored size and filter mask, and
+// the scaled coordinates as 8-byte values.
+// Reference: H5Dbtree2.c - H5D__bt2_unfilt_decode, H5D__bt2_filt_decode.
+func readBTreeV2Chunks(r io.ReaderAt, address uint64, grid *chunkGrid, sb *Superblock) ([]ChunkEntry, error) {
+	bt, err := OpenBTreeV2(r, address, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	ndims := len(grid.dims)
+	offsetSize := int(sb.OffsetSize)
+	fixedSize := offsetSize + 8*ndims
+	switch bt.Type {
+	case BTreeV2TypeChunk:
+		if int(bt.RecordSize) != fixedSize {
+			return nil, fmt.Errorf("invalid chunk record size %d", bt.RecordSize)
+		}
+	case BTreeV2TypeChunkFiltered:
+		if sizeLen := int(bt.RecordSize) - fixedSize - 4; sizeLen < 1 || sizeLen > 8 {
+			return nil, fmt.Errorf("invalid filtered chunk record size %d", bt.RecordSize)
+		}
+	default:
+		return nil, fmt.Errorf("B-tree type %d is not a chunk index", bt.Type)
+	}
+
+	var chunks []ChunkEntry
+	err = bt.ForEach(func(rec []byte) error {
+		addr := readUint64(rec, offsetSize, sb.Endianness)
+		nbytes := grid.chunkBytes
+		var filterMask uint32
+		p := offsetSize
+		if bt.Type == BTreeV2TypeChunkFiltered {
+			sizeLen := len(rec) - fixedSize - 4
+			nbytes = readVarUint(rec[p : p+sizeLen])
+			filterMask = binary.LittleEndian.Uint32(rec[p+sizeLen:])
+			p += sizeLen + 4
+		}
+
+		scaled := make([]uint64, ndims+1)
+		for i := 0; i < ndims; i++ {
+			scaled[i] = binary.LittleEndian.Uint64(rec[p:])
+			p += 8
+		}
+		if isUndefinedAddress(addr, sb.OffsetSize) || !grid.inExtent(scaled) {
+			return nil
+		}
+
+		entry, err := newChunkEntry(scaled, addr, nbytes, filterMask)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollectChunks_SingleChunk(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+	dataspace := &DataspaceMessage{Dimensions: []uint64{4, 5}}
+
+	layout := &DataLayoutMessage{
+		Version:     4,
+		Class:       LayoutChunked,
+		DataAddress: 0x800,
+		ChunkSize:   []uint64{4, 5, 8},
+		ChunkIndex:  ChunkIndexSingleChunk,
+	}
+	chunks, err := CollectChunks(nil, layout, dataspace, sb)
+	require.NoError(t, err)
+	require.Equal(t, []ChunkEntry{{
+		Key:     ChunkKey{Scaled: []uint64{0, 0, 0}, Nbytes: 160},
+		Address: 0x800,
+	}}, chunks)
+
+	// Filtered chunks take their size and mask from the layout message.
+	layout.ChunkFlags = ChunkFlagSingleIndexWithFilter
+	layout.ChunkIndexParams = ChunkIndexParams{FilteredChunkSize: 42, FilterMask: 1}
+	chunks, err = CollectChunks(nil, layout, dataspace, sb)
+	require.NoError(t, err)
+	require.Len(t, chunks, 1)
+	require.Equal(t, uint32(42), chunks[0].Key.Nbytes)
+	require.Equal(t, uint32(1), chunks[0].Key.FilterMask)
+
+	// Unallocated storage has no chunks.
+	layout.DataAddress = math.MaxUint64
+	chunks, err = CollectChunks(nil, layout, dataspace, sb)
+	require.NoError(t, err)
+	require.Empty(t, chunks)
+}
+
+func TestCollectChunks_Implicit(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+
+	// 5x6 dataset of 2x4 chunks that may grow to 8x8: chunk addresses follow
+	// the linear index over the 4x2 maximum chunk grid.
+	dataspace := &DataspaceMessage{Dimensions: []uint64{5, 6}, MaxDims: []uint64{8, 8}}
+	layout := &DataLayoutMessage{
+		Version:     4,
+		Class:       LayoutChunked,
+		DataAddress: 0x1000,
+		ChunkSize:   []uint64{2, 4, 4},
+		ChunkFlags:  ChunkFlagDontFilterPartialEdgeChunks,
+		ChunkIndex:  ChunkIndexImplicit,
+	}
+
+	chunks, err := CollectChunks(nil, layout, dataspace, sb)
+	require.NoError(t, err)
+	require.Len(t, chunks, 6)
+
+	for _, chunk := range chunks {
+		scaled := chunk.Key.Scaled
+		require.Len(t, scaled, 3)
+		require.Equal(t, uint32(32), chunk.Key.Nbytes)
+		require.Equal(t, 0x1000+(scaled[0]*2+scaled[1])*32, chunk.Address, "chunk %v", scaled)
+
+		// Chunks in the last row or column extend past the dataset.
+		partial := scaled[0] == 2 || scaled[1] == 1
+		if partial {
+			require.Equal(t, uint32(math.MaxUint32), chunk.Key.FilterMask, "chunk %v", scaled)
+		} else {
+			require.Zero(t, chunk.Key.FilterMask, "chunk %v", scaled)
+		}
+	}
+}
+
+func TestChunkIndexCoordinates(t *testing.T) {
+	down := downChunks([]uint64{unlimitedDim, 3, 4})
+	require.Equal(t, []uint64{12, 4, 1}, down)
+
+	for index := uint64(0); index < 36; index++ {
+		scaled := scaledFromIndex(index, down)
+		require.Len(t, scaled, 4)
+		require.Zero(t, scaled[3])
+		require.Equal(t, index, indexFromScaled(scaled, down))
+	}
+	require.Equal(t, []uint64{2, 1, 3, 0}, scaledFromIndex(2*12+1*4+3, down))
+}
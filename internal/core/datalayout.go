@@ -4,6 +4,8 @@ import (
 	"encoding/binary"
 	"errors"
 	"fmt"
+
+	"github.com/meko-christian/go-hdf5/internal/utils"
 )
 
 // DataLayoutClass represents the storage layout type.
@@ -19,6 +21,46 @@ const (
 	layoutUnknown = "unknown" // String representation for unknown layout class.
 )
 
+// ChunkIndexType identifies the structure that indexes the chunks of a
+// chunked dataset. Layout messages before version 4 always use a v1 B-tree.
+// Reference: H5Dpublic.h - H5D_chunk_index_t.
+type ChunkIndexType uint8
+
+// Chunk index types.
+const (
+	ChunkIndexBTreeV1         ChunkIndexType = 0 // Version 1 B-tree (layout v1-v3).
+	ChunkIndexSingleChunk     ChunkIndexType = 1 // Dataset consists of exactly one chunk.
+	ChunkIndexImplicit        ChunkIndexType = 2 // Chunks allocated contiguously, no index.
+	ChunkIndexFixedArray      ChunkIndexType = 3 // Fixed array, for datasets without unlimited dimensions.
+	ChunkIndexExtensibleArray ChunkIndexType = 4 // Extensible array, for one unlimited dimension.
+	ChunkIndexBTreeV2         ChunkIndexType = 5 // Version 2 B-tree, for several unlimited dimensions.
+)
+
+// Layout v4 chunked flags.
+const (
+	// ChunkFlagDontFilterPartialEdgeChunks marks datasets whose partial edge
+	// chunks are stored without applying the filter pipeline.
+	ChunkFlagDontFilterPartialEdgeChunks uint8 = 0x01
+	// ChunkFlagSingleIndexWithFilter marks a single chunk index whose chunk
+	// is filtered; the layout then stores the filtered size and filter mask.
+	ChunkFlagSingleIndexWithFilter uint8 = 0x02
+)
+
+// ChunkIndexParams holds the index-specific fields of a version 4 chunked
+// layout message. Only the fields of the layout's index type are set.
+type ChunkIndexParams struct {
+	FilteredChunkSize uint64 // Single chunk: stored size of the filtered chunk.
+	FilterMask        uint32 // Single chunk: filters skipped for the chunk.
+	PageBits          uint8  // Fixed/extensible array: log2 of elements per data block page.
+	MaxBits           uint8  // Extensible array: log2 of the maximum number of elements.
+	IndexElements     uint8  // Extensible array: elements stored in the index block.
+	MinPointers       uint8  // Extensible array: minimum data block pointers per super block.
+	MinElements       uint8  // Extensible array: minimum elements per data block.
+	NodeSize          uint32 // B-tree v2: node size in bytes.
+	SplitPercent      uint8  // B-tree v2: node split threshold.
+	MergePercent      uint8  // B-tree v2: node merge threshold.
+}
+
 // DataLayoutMessage represents HDF5 data layout message.
 type DataLayoutMessage struct {
 	Version      uint8
@@ -28,6 +70,15 @@ type DataLayoutMessage struct {
 	CompactData  []byte   // Data itself (for compact layout).
 	ChunkSize    []uint64 // Chunk dimensions (for chunked layout) - uint64 for HDF5 2.0.0+ support.
 	ChunkKeySize uint8    // Size of chunk keys in bytes: 4 (uint32) or 8 (uint64).
+
+	// Version 4 chunked layout properties.
+	ChunkFlags       uint8          // ChunkFlag* bits.
+	ChunkIndex       ChunkIndexType // Chunk index type (ChunkIndexBTreeV1 before version 4).
+	ChunkIndexParams ChunkIndexParams
+
+	// Virtual layout: the mapping list is a global heap object in the
+	// collection at DataAddress.
+	HeapIndex uint32
 }
 
 // ParseDataLayoutMessage parses a data layout message from header message data.
@@ -38,8 +89,9 @@ func ParseDataLayoutMessage(data []byte, sb *Superblock) (*DataLayoutMessage, er
 
 	version := data[0]
 
-	// Version 3 and 4 are most common (HDF5 1.8+).
-	if version < 3 || version > 4 {
+	// Version 3 and 4 are most common (HDF5 1.8+); versions 1 and 2 come
+	// from HDF5 1.4/1.6-era files.
+	if version < 1 || version > 4 {
 		return nil, fmt.Errorf("unsupported data layout version: %d", version)
 	}
 
@@ -49,6 +101,8 @@ func ParseDataLayoutMessage(data []byte, sb *Superblock) (*DataLayoutMessage, er
 	}
 
 	switch version {
+	case 1, 2:
+		return parseLayoutV1(data, sb, msg)
 	case 3:
 		return parseLayoutV3(data, sb, msg)
 	case 4:
@@ -71,6 +125,80 @@ func determineChunkKeySize(superblockVersion uint8) uint8 {
 	return 4
 }
 
+// parseLayoutV1 parses HDF5 Data Layout Message versions 1 and 2, which share
+// one encoding: dimensionality, class, five reserved bytes, the storage
+// address (absent for compact layouts) and 32-bit dimension sizes, followed
+// for compact layouts by the data size and the data itself.
+//
+// Contiguous and chunked layouts store one dimension more than the dataspace
+// rank, the element size. Contiguous layouts carry no storage size; it is the
+// product of all dimensions.
+// Reference: H5Olayout.c - H5O__layout_decode (version < 3).
+func parseLayoutV1(data []byte, sb *Superblock, msg *DataLayoutMessage) (*DataLayoutMessage, error) {
+	if len(data) < 8 {
+		return nil, fmt.Errorf("layout v%d message too short", msg.Version)
+	}
+
+	dimensionality := int(data[1])
+	msg.Class = DataLayoutClass(data[2])
+	offset := 8 // Version, dimensionality, class and 5 reserved bytes.
+
+	switch msg.Class {
+	case LayoutCompact, LayoutContiguous, LayoutChunked:
+	default:
+		return nil, fmt.Errorf("unsupported layout class: %d", msg.Class)
+	}
+
+	if msg.Class != LayoutCompact {
+		if offset+int(sb.OffsetSize) > len(data) {
+			return nil, errors.New("layout address truncated")
+		}
+		msg.DataAddress = readUint64(data[offset:], int(sb.OffsetSize), sb.Endianness)
+		offset += int(sb.OffsetSize)
+	}
+
+	if offset+4*dimensionality > len(data) {
+		return nil, errors.New("layout dimensions truncated")
+	}
+	dims := make([]uint64, dimensionality)
+	for i := range dims {
+		dims[i] = uint64(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		offset += 4
+	}
+
+	switch msg.Class {
+	case LayoutCompact:
+		if offset+4 > len(data) {
+			return nil, errors.New("compact layout size truncated")
+		}
+		size := uint64(binary.LittleEndian.Uint32(data[offset : offset+4]))
+		offset += 4
+		if uint64(offset)+size > uint64(len(data)) {
+			return nil, errors.New("compact layout data truncated")
+		}
+		msg.CompactData = data[offset : offset+int(size)]
+		msg.DataSize = size
+
+	case LayoutContiguous:
+		msg.DataSize = 1
+		for _, d := range dims {
+			var err error
+			msg.DataSize, err = utils.SafeMultiply(msg.DataSize, d)
+			if err != nil {
+				return nil, fmt.Errorf("contiguous layout size overflow: %w", err)
+			}
+		}
+
+	case LayoutChunked:
+		if dimensionality < 2 {
+			return nil, fmt.Errorf("invalid chunked layout dimensionality: %d", dimensionality)
+		}
+		msg.ChunkSize = dims
+	}
+
+	return msg, nil
+}
+
 // parseLayoutV3 parses HDF5 Data Layout Message version 3.
 // Cognitive complexity is high due to handling 3 distinct layout types
 // (Compact, Contiguous, Chunked) with different binary formats and
@@ -163,10 +291,100 @@ func parseLayoutV3(data []byte, sb *Superblock, msg *DataLayoutMessage) (*DataLa
 	return msg, nil
 }
 
+// parseLayoutV4 parses HDF5 Data Layout Message version 4.
+// Compact and contiguous layouts are encoded as in version 3. Chunked layouts
+// add flags, variable-width chunk dimensions and the chunk index type with
+// its parameters, followed by the index address. Virtual layouts (new in
+// version 4) store the global heap ID of the mapping list.
+// Reference: H5Olayout.c - H5O__layout_decode.
 func parseLayoutV4(data []byte, sb *Superblock, msg *DataLayoutMessage) (*DataLayoutMessage, error) {
-	// Version 4 is similar to v3 but with some differences.
-	// For now, delegate to v3 parser (they're very similar for contiguous layout).
-	return parseLayoutV3(data, sb, msg)
+	if len(data) < 2 {
+		return nil, errors.New("layout v4 message too short")
+	}
+	switch DataLayoutClass(data[1]) {
+	case LayoutChunked:
+	case LayoutVirtual:
+		msg.Class = LayoutVirtual
+		if len(data) < 2+int(sb.OffsetSize)+4 {
+			return nil, errors.New("virtual layout message too short")
+		}
+		msg.DataAddress = readUint64(data[2:], int(sb.OffsetSize), sb.Endianness)
+		msg.HeapIndex = binary.LittleEndian.Uint32(data[2+int(sb.OffsetSize):])
+		return msg, nil
+	default:
+		return parseLayoutV3(data, sb, msg)
+	}
+
+	msg.Class = LayoutChunked
+	if len(data) < 5 {
+		return nil, errors.New("chunked layout message too short")
+	}
+	msg.ChunkFlags = data[2]
+	dimensionality := int(data[3])
+	encodedSize := int(data[4])
+	if encodedSize < 1 || encodedSize > 8 {
+		return nil, fmt.Errorf("invalid chunk dimension encoded size: %d", encodedSize)
+	}
+	offset := 5
+
+	if offset+dimensionality*encodedSize+1 > len(data) {
+		return nil, errors.New("chunked layout dimensions truncated")
+	}
+	msg.ChunkSize = make([]uint64, dimensionality)
+	for i := range msg.ChunkSize {
+		msg.ChunkSize[i] = readUint64(data[offset:], encodedSize, binary.LittleEndian)
+		offset += encodedSize
+	}
+
+	msg.ChunkIndex = ChunkIndexType(data[offset])
+	offset++
+
+	params := &msg.ChunkIndexParams
+	var paramSize int
+	switch msg.ChunkIndex {
+	case ChunkIndexSingleChunk:
+		if msg.ChunkFlags&ChunkFlagSingleIndexWithFilter != 0 {
+			paramSize = int(sb.LengthSize) + 4
+		}
+	case ChunkIndexImplicit:
+	case ChunkIndexFixedArray:
+		paramSize = 1
+	case ChunkIndexExtensibleArray:
+		paramSize = 5
+	case ChunkIndexBTreeV2:
+		paramSize = 6
+	default:
+		return nil, fmt.Errorf("unsupported chunk index type: %d", msg.ChunkIndex)
+	}
+	if offset+paramSize+int(sb.OffsetSize) > len(data) {
+		return nil, errors.New("chunk index information truncated")
+	}
+
+	p := data[offset : offset+paramSize]
+	switch msg.ChunkIndex {
+	case ChunkIndexSingleChunk:
+		if paramSize > 0 {
+			params.FilteredChunkSize = readUint64(p, int(sb.LengthSize), sb.Endianness)
+			params.FilterMask = binary.LittleEndian.Uint32(p[sb.LengthSize:])
+		}
+	case ChunkIndexFixedArray:
+		params.PageBits = p[0]
+	case ChunkIndexExtensibleArray:
+		params.MaxBits = p[0]
+		params.IndexElements = p[1]
+		params.MinPointers = p[2]
+		params.MinElements = p[3]
+		params.PageBits = p[4]
+	case ChunkIndexBTreeV2:
+		params.NodeSize = binary.LittleEndian.Uint32(p[0:4])
+		params.SplitPercent = p[4]
+		params.MergePercent = p[5]
+	}
+	offset += paramSize
+
+	msg.DataAddress = readUint64(data[offset:], int(sb.OffsetSize), sb.Endianness)
+
+	return msg, nil
 }
 
 // Helper function to read variable-sized unsigned integers.
@@ -207,6 +425,20 @@ func (dl *DataLayoutMessage) IsChunked() bool {
 	return dl.Class == LayoutChunked
 }
 
+// IsVirtual returns true if layout is virtual (data mapped from other datasets).
+func (dl *DataLayoutMessage) IsVirtual() bool {
+	return dl.Class == LayoutVirtual
+}
+
+// IsAllocated returns true unless the layout's storage (contiguous data or
+// chunk index) was never allocated and its address is undefined.
+func (dl *DataLayoutMessage) IsAllocated(sb *Superblock) bool {
+	if dl.IsCompact() {
+		return true
+	}
+	return !isUndefinedAddress(dl.DataAddress, sb.OffsetSize)
+}
+
 // String returns human-readable layout description.
 func (dl *DataLayoutMessage) String() string {
 	switch dl.Class {
@@ -75,3 +75,75 @@ func TestParseDataLayoutMessage_InvalidVersion(t *testing.T) {
 	_, err := ParseDataLayoutMessage(data, sb)
 	require.Error(t, err)
 }
+
+// TestParseDataLayoutMessage_V1V2 tests the layout encoding of versions 1 and 2.
+func TestParseDataLayoutMessage_V1V2(t *testing.T) {
+	sb := &Superblock{
+		OffsetSize: 8,
+		LengthSize: 8,
+		Endianness: binary.LittleEndian,
+	}
+	reserved := []byte{0, 0, 0, 0, 0}
+	address := []byte{0x00, 0x08, 0, 0, 0, 0, 0, 0}
+
+	t.Run("contiguous", func(t *testing.T) {
+		// 8x8 dataset of 4-byte elements: rank + 1 dimensions.
+		data := append([]byte{2, 3, byte(LayoutContiguous)}, reserved...)
+		data = append(data, address...)
+		data = append(data, 8, 0, 0, 0, 8, 0, 0, 0, 4, 0, 0, 0)
+
+		layout, err := ParseDataLayoutMessage(data, sb)
+		require.NoError(t, err)
+		require.Equal(t, uint8(2), layout.Version)
+		require.True(t, layout.IsContiguous())
+		require.Equal(t, uint64(0x800), layout.DataAddress)
+		require.Equal(t, uint64(256), layout.DataSize)
+	})
+
+	t.Run("chunked", func(t *testing.T) {
+		data := append([]byte{1, 3, byte(LayoutChunked)}, reserved...)
+		data = append(data, address...)
+		data = append(data, 50, 0, 0, 0, 50, 0, 0, 0, 4, 0, 0, 0)
+		data = append(data, 0, 0, 0, 0) // Alignment padding.
+
+		layout, err := ParseDataLayoutMessage(data, sb)
+		require.NoError(t, err)
+		require.Equal(t, uint8(1), layout.Version)
+		require.True(t, layout.IsChunked())
+		require.Equal(t, ChunkIndexBTreeV1, layout.ChunkIndex)
+		require.Equal(t, uint64(0x800), layout.DataAddress)
+		require.Equal(t, []uint64{50, 50, 4}, layout.ChunkSize)
+	})
+
+	t.Run("compact", func(t *testing.T) {
+		// No address; the data size and data follow the dimensions.
+		data := append([]byte{1, 2, byte(LayoutCompact)}, reserved...)
+		data = append(data, 3, 0, 0, 0, 2, 0, 0, 0)
+		data = append(data, 6, 0, 0, 0, 1, 2, 3, 4, 5, 6)
+
+		layout, err := ParseDataLayoutMessage(data, sb)
+		require.NoError(t, err)
+		require.True(t, layout.IsCompact())
+		require.Equal(t, []byte{1, 2, 3, 4, 5, 6}, layout.CompactData)
+		require.Equal(t, uint64(6), layout.DataSize)
+
+		_, err = ParseDataLayoutMessage(data[:len(data)-1], sb)
+		require.ErrorContains(t, err, "compact layout data truncated")
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		_, err := ParseDataLayoutMessage([]byte{1, 2, byte(LayoutContiguous)}, sb)
+		require.ErrorContains(t, err, "too short")
+
+		data := append([]byte{1, 2, byte(LayoutContiguous)}, reserved...)
+		_, err = ParseDataLayoutMessage(append(data, address[:4]...), sb)
+		require.ErrorContains(t, err, "address truncated")
+
+		_, err = ParseDataLayoutMessage(append(data, address...), sb)
+		require.ErrorContains(t, err, "dimensions truncated")
+
+		data = append([]byte{1, 2, byte(LayoutVirtual)}, reserved...)
+		_, err = ParseDataLayoutMessage(data, sb)
+		require.ErrorContains(t, err, "unsupported layout class")
+	})
+}
@@ -111,7 +111,7 @@ func TestReadUint64(t *testing.T) {
 	}
 }
 
-// TestParseLayoutV4 tests version 4 layout parsing (delegates to v3).
+// TestParseLayoutV4 tests version 4 contiguous layout parsing (same encoding as v3).
 func TestParseLayoutV4(t *testing.T) {
 	sb := &Superblock{
 		OffsetSize: 8,
@@ -134,3 +134,110 @@ func TestParseLayoutV4(t *testing.T) {
 	require.Equal(t, uint64(0x1000), msg.DataAddress)
 	require.Equal(t, uint64(0x4000), msg.DataSize)
 }
+
+// TestParseLayoutV4_Chunked tests version 4 chunked layouts for each chunk index type.
+func TestParseLayoutV4_Chunked(t *testing.T) {
+	sb := &Superblock{
+		OffsetSize: 8,
+		LengthSize: 8,
+		Endianness: binary.LittleEndian,
+	}
+
+	// version, class, flags, dimensionality 3, encoded size 2, dims {10, 20, 8}.
+	prefix := func(flags uint8) []byte {
+		return []byte{4, byte(LayoutChunked), flags, 3, 2, 10, 0, 20, 0, 8, 0}
+	}
+	address := []byte{0x00, 0x20, 0, 0, 0, 0, 0, 0}
+
+	tests := []struct {
+		name  string
+		flags uint8
+		index ChunkIndexType
+		info  []byte
+		want  ChunkIndexParams
+	}{
+		{
+			name:  "single chunk",
+			index: ChunkIndexSingleChunk,
+		},
+		{
+			name:  "filtered single chunk",
+			flags: ChunkFlagSingleIndexWithFilter,
+			index: ChunkIndexSingleChunk,
+			info:  []byte{0x90, 0x01, 0, 0, 0, 0, 0, 0, 0x02, 0, 0, 0},
+			want:  ChunkIndexParams{FilteredChunkSize: 400, FilterMask: 2},
+		},
+		{
+			name:  "implicit",
+			index: ChunkIndexImplicit,
+		},
+		{
+			name:  "fixed array",
+			flags: ChunkFlagDontFilterPartialEdgeChunks,
+			index: ChunkIndexFixedArray,
+			info:  []byte{10},
+			want:  ChunkIndexParams{PageBits: 10},
+		},
+		{
+			name:  "extensible array",
+			index: ChunkIndexExtensibleArray,
+			info:  []byte{32, 4, 4, 16, 10},
+			want:  ChunkIndexParams{MaxBits: 32, IndexElements: 4, MinPointers: 4, MinElements: 16, PageBits: 10},
+		},
+		{
+			name:  "version 2 B-tree",
+			index: ChunkIndexBTreeV2,
+			info:  []byte{0x00, 0x02, 0, 0, 100, 40},
+			want:  ChunkIndexParams{NodeSize: 512, SplitPercent: 100, MergePercent: 40},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data := append(prefix(tt.flags), byte(tt.index))
+			data = append(data, tt.info...)
+			data = append(data, address...)
+
+			msg, err := ParseDataLayoutMessage(data, sb)
+			require.NoError(t, err)
+			require.Equal(t, LayoutChunked, msg.Class)
+			require.Equal(t, []uint64{10, 20, 8}, msg.ChunkSize)
+			require.Equal(t, tt.flags, msg.ChunkFlags)
+			require.Equal(t, tt.index, msg.ChunkIndex)
+			require.Equal(t, tt.want, msg.ChunkIndexParams)
+			require.Equal(t, uint64(0x2000), msg.DataAddress)
+
+			// Every truncation is rejected.
+			for n := 2; n < len(data); n++ {
+				_, err := ParseDataLayoutMessage(data[:n], sb)
+				require.Error(t, err, "length %d", n)
+			}
+		})
+	}
+
+	t.Run("v1 B-tree index not allowed", func(t *testing.T) {
+		data := append(prefix(0), byte(ChunkIndexBTreeV1))
+		data = append(data, address...)
+		_, err := ParseDataLayoutMessage(data, sb)
+		require.ErrorContains(t, err, "unsupported chunk index type")
+	})
+}
+
+// TestParseLayoutV4_Virtual tests the version 4 virtual layout.
+func TestParseLayoutV4_Virtual(t *testing.T) {
+	sb := &Superblock{
+		OffsetSize: 8,
+		LengthSize: 8,
+		Endianness: binary.LittleEndian,
+	}
+
+	data := []byte{4, byte(LayoutVirtual), 0x00, 0x30, 0, 0, 0, 0, 0, 0, 7, 0, 0, 0}
+	msg, err := ParseDataLayoutMessage(data, sb)
+	require.NoError(t, err)
+	require.True(t, msg.IsVirtual())
+	require.Equal(t, uint64(0x3000), msg.DataAddress)
+	require.Equal(t, uint32(7), msg.HeapIndex)
+
+	_, err = ParseDataLayoutMessage(data[:10], sb)
+	require.ErrorContains(t, err, "virtual layout message too short")
+}
@@ -4,16 +4,91 @@ import (
 	"errors"
 	"fmt"
 	"io"
-	"math"
 
 	"github.com/meko-christian/go-hdf5/internal/utils"
 )
 
 // ReadDatasetFloat64 reads a dataset and returns values as float64 array.
 // This is the main entry point for reading numerical datasets.
+// Every numeric datatype understood by DecodeNumeric is accepted.
 func ReadDatasetFloat64(r io.ReaderAt, header *ObjectHeader, sb *Superblock) ([]float64, error) {
-	// 1. Extract required messages from object header.
+	rawData, info, err := ReadDatasetRaw(r, header, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	totalElements := info.Dataspace.TotalElements()
+	if totalElements == 0 {
+		return []float64{}, nil
+	}
+
+	return convertToFloat64(rawData, info.Datatype, totalElements)
+}
+
+// ReadDatasetRaw reads the complete dataset storage and returns the raw element
+// bytes (in file byte order) together with the parsed dataset metadata.
+// Compact, contiguous and chunked layouts are supported; chunked data is
+// passed through the filter pipeline. Virtual datasets are supported if r
+// implements VirtualSourceResolver.
+func ReadDatasetRaw(r io.ReaderAt, header *ObjectHeader, sb *Superblock) ([]byte, *DatasetInfo, error) {
+	msgs, err := loadDatasetMessages(r, header, sb)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	info := msgs.info()
+
+	if msgs.dataspace.TotalElements() == 0 {
+		return []byte{}, info, nil
+	}
+
+	rawData, err := readDatasetRawData(r, msgs, sb)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rawData, info, nil
+}
+
+// datasetMessages holds the parsed header messages needed to read dataset storage.
+type datasetMessages struct {
+	datatype        *DatatypeMessage
+	datatypeAddress uint64 // Named datatype address, if datatype is committed.
+	dataspace       *DataspaceMessage
+	layout          *DataLayoutMessage
+	filterPipeline  *FilterPipelineMessage
+	fillValue       *FillValueMessage
+	external        bool            // Data is stored in external files.
+	virtual         *virtualDataset // Resolved mappings of a virtual dataset.
+}
+
+// loadDatasetMessages parses the dataset header messages like
+// parseDatasetMessages. For virtual datasets it also opens the source
+// datasets and updates the dataspace to the extent they determine.
+func loadDatasetMessages(r io.ReaderAt, header *ObjectHeader, sb *Superblock) (*datasetMessages, error) {
+	msgs, err := parseDatasetMessages(header, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	if msgs.layout.IsVirtual() {
+		msgs.virtual, err = openVirtualDataset(r, msgs, sb)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open virtual dataset: %w", err)
+		}
+		msgs.dataspace.Dimensions = msgs.virtual.dims
+	}
+
+	return msgs, nil
+}
+
+// parseDatasetMessages extracts and parses the datatype, dataspace, layout and
+// (optional) filter pipeline and fill value messages from a dataset object
+// header.
+func parseDatasetMessages(header *ObjectHeader, sb *Superblock) (*datasetMessages, error) {
+	msgs := &datasetMessages{}
 	var datatypeMsg, dataspaceMsg, layoutMsg, filterPipelineMsg *HeaderMessage
+	var fillValueMsg, fillValueOldMsg *HeaderMessage
 
 	for _, msg := range header.Messages {
 		switch msg.Type {
@@ -25,6 +100,12 @@ func ReadDatasetFloat64(r io.ReaderAt, header *ObjectHeader, sb *Superblock) ([]
 			layoutMsg = msg
 		case MsgFilterPipeline:
 			filterPipelineMsg = msg
+		case MsgFillValue:
+			fillValueMsg = msg
+		case MsgFillValueOld:
+			fillValueOldMsg = msg
+		case MsgExternalFiles:
+			msgs.external = true
 		}
 	}
 
@@ -39,182 +120,171 @@ func ReadDatasetFloat64(r io.ReaderAt, header *ObjectHeader, sb *Superblock) ([]
 		return nil, errors.New("data layout message not found")
 	}
 
-	// 2. Parse datatype.
-	datatype, err := ParseDatatypeMessage(datatypeMsg.Data)
+	var err error
+
+	datatypeData := datatypeMsg.Data
+	if datatypeMsg.Committed != nil {
+		datatypeData = datatypeMsg.Committed
+		msgs.datatypeAddress = datatypeMsg.CommittedAddress
+	}
+	msgs.datatype, err = ParseDatatypeMessage(datatypeData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse datatype: %w", err)
 	}
 
-	// 3. Parse dataspace.
-	dataspace, err := ParseDataspaceMessage(dataspaceMsg.Data)
+	msgs.dataspace, err = ParseDataspaceMessage(dataspaceMsg.Data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse dataspace: %w", err)
 	}
 
-	// 4. Parse layout.
-	layout, err := ParseDataLayoutMessage(layoutMsg.Data, sb)
+	msgs.layout, err = ParseDataLayoutMessage(layoutMsg.Data, sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse layout: %w", err)
 	}
 
-	// 5. Parse filter pipeline (optional, for compression).
-	var filterPipeline *FilterPipelineMessage
+	// Filter pipeline is optional (only present for filtered chunked data).
 	if filterPipelineMsg != nil {
-		filterPipeline, err = ParseFilterPipelineMessage(filterPipelineMsg.Data)
+		msgs.filterPipeline, err = ParseFilterPipelineMessage(filterPipelineMsg.Data)
 		if err != nil {
 			return nil, fmt.Errorf("failed to parse filter pipeline: %w", err)
 		}
 	}
 
-	// 6. Calculate total number of elements.
-	totalElements := dataspace.TotalElements()
-	if totalElements == 0 {
-		return []float64{}, nil
+	// Fill value is optional; the old-style message is only consulted when
+	// the dataset has no new-style one (H5D__open_oid).
+	switch {
+	case fillValueMsg != nil:
+		msgs.fillValue, err = ParseFillValueMessage(fillValueMsg.Data)
+	case fillValueOldMsg != nil:
+		msgs.fillValue, err = ParseFillValueOldMessage(fillValueOldMsg.Data)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse fill value: %w", err)
 	}
 
-	// 6. Read data based on layout type.
-	var rawData []byte
+	return msgs, nil
+}
+
+// readDatasetRawData reads all raw element bytes according to the storage layout.
+func readDatasetRawData(r io.ReaderAt, msgs *datasetMessages, sb *Superblock) ([]byte, error) {
+	layout := msgs.layout
+	totalElements := msgs.dataspace.TotalElements()
 
 	switch {
 	case layout.IsCompact():
 		// Data is stored directly in the layout message.
-		rawData = layout.CompactData
+		return layout.CompactData, nil
 
 	case layout.IsContiguous():
 		// Data is stored contiguously at specific address.
-		dataSize := totalElements * uint64(datatype.Size)
-		rawData = make([]byte, dataSize)
+		dataSize, err := utils.SafeMultiply(totalElements, uint64(msgs.datatype.Size))
+		if err != nil {
+			return nil, fmt.Errorf("dataset size overflow: %w", err)
+		}
+		if err := utils.ValidateBufferSize(dataSize, utils.MaxDatasetSize, "dataset"); err != nil {
+			return nil, fmt.Errorf("dataset too large: %w", err)
+		}
+		// Allocated storage must hold every element.
+		if layout.IsAllocated(sb) && layout.DataSize < dataSize {
+			return nil, fmt.Errorf("contiguous storage of %d bytes is smaller than the dataset's %d bytes",
+				layout.DataSize, dataSize)
+		}
+		rawData := make([]byte, dataSize)
+
+		// Storage that was never allocated reads as the fill value.
+		// External storage has no address in the file.
+		if !layout.IsAllocated(sb) {
+			if msgs.external {
+				return nil, errors.New("external data storage is not supported")
+			}
+			FillBuffer(rawData, msgs.fillValue.Pattern(uint64(msgs.datatype.Size)))
+			return rawData, nil
+		}
 
 		//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-		_, err := r.ReadAt(rawData, int64(layout.DataAddress))
-		if err != nil {
+		if _, err := r.ReadAt(rawData, int64(layout.DataAddress)); err != nil {
 			return nil, fmt.Errorf("failed to read contiguous data: %w", err)
 		}
+		return rawData, nil
 
 	case layout.IsChunked():
 		// Data is stored in chunks indexed by B-tree.
-		rawData, err = readChunkedData(r, layout, dataspace, datatype, sb, filterPipeline)
+		rawData, err := readChunkedData(r, layout, msgs.dataspace, msgs.datatype, sb, msgs.filterPipeline, msgs.fillValue)
 		if err != nil {
 			return nil, fmt.Errorf("failed to read chunked data: %w", err)
 		}
+		return rawData, nil
+
+	case layout.IsVirtual():
+		// Data is assembled from the source datasets.
+		rawData, err := msgs.virtual.read(msgs.datatype, msgs.fillValue)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read virtual data: %w", err)
+		}
+		return rawData, nil
 
 	default:
 		return nil, fmt.Errorf("unsupported layout class: %d", layout.Class)
 	}
-
-	// 7. Convert raw bytes to float64 based on datatype.
-	return convertToFloat64(rawData, datatype, totalElements)
 }
 
 // convertToFloat64 converts raw bytes to float64 array based on datatype.
 func convertToFloat64(rawData []byte, datatype *DatatypeMessage, numElements uint64) ([]float64, error) {
-	result := make([]float64, numElements)
-	byteOrder := datatype.GetByteOrder()
-
-	switch {
-	case datatype.IsFloat64():
-		// IEEE 754 double precision (64-bit).
-		for i := uint64(0); i < numElements; i++ {
-			offset := i * 8
-			if offset+8 > uint64(len(rawData)) {
-				return nil, errors.New("data truncated (float64)")
-			}
-
-			bits := byteOrder.Uint64(rawData[offset : offset+8])
-			result[i] = math.Float64frombits(bits)
-		}
-
-	case datatype.IsFloat32():
-		// IEEE 754 single precision (32-bit).
-		for i := uint64(0); i < numElements; i++ {
-			offset := i * 4
-			if offset+4 > uint64(len(rawData)) {
-				return nil, errors.New("data truncated (float32)")
-			}
-
-			bits := byteOrder.Uint32(rawData[offset : offset+4])
-			result[i] = float64(math.Float32frombits(bits))
-		}
-
-	case datatype.IsInt32():
-		// 32-bit signed integer.
-		for i := uint64(0); i < numElements; i++ {
-			offset := i * 4
-			if offset+4 > uint64(len(rawData)) {
-				return nil, errors.New("data truncated (int32)")
-			}
-
-			//nolint:gosec // G115: HDF5 binary format requires uint32 to int32 conversion
-			val := int32(byteOrder.Uint32(rawData[offset : offset+4]))
-			result[i] = float64(val)
-		}
-
-	case datatype.IsInt64():
-		// 64-bit signed integer.
-		for i := uint64(0); i < numElements; i++ {
-			offset := i * 8
-			if offset+8 > uint64(len(rawData)) {
-				return nil, errors.New("data truncated (int64)")
-			}
-
-			//nolint:gosec // G115: HDF5 binary format requires uint64 to int64 conversion
-			val := int64(byteOrder.Uint64(rawData[offset : offset+8]))
-			result[i] = float64(val)
-		}
-
-	default:
+	if !datatype.IsNumeric() {
 		return nil, fmt.Errorf("unsupported datatype for conversion to float64: %s", datatype)
 	}
 
+	result := make([]float64, numElements)
+	if err := DecodeNumeric(rawData, datatype, result); err != nil {
+		return nil, err
+	}
 	return result, nil
 }
 
 // ReadDatasetInfo returns dataset metadata without reading actual data.
+// The dataspace of virtual datasets is the stored one; see
+// ReadVirtualDatasetInfo.
 func ReadDatasetInfo(header *ObjectHeader, sb *Superblock) (*DatasetInfo, error) {
-	var datatypeMsg, dataspaceMsg, layoutMsg *HeaderMessage
-
-	for _, msg := range header.Messages {
-		switch msg.Type {
-		case MsgDatatype:
-			datatypeMsg = msg
-		case MsgDataspace:
-			dataspaceMsg = msg
-		case MsgDataLayout:
-			layoutMsg = msg
-		}
-	}
-
-	if datatypeMsg == nil || dataspaceMsg == nil || layoutMsg == nil {
-		return nil, errors.New("missing required messages")
-	}
-
-	datatype, err := ParseDatatypeMessage(datatypeMsg.Data)
+	msgs, err := parseDatasetMessages(header, sb)
 	if err != nil {
 		return nil, err
 	}
 
-	dataspace, err := ParseDataspaceMessage(dataspaceMsg.Data)
-	if err != nil {
-		return nil, err
-	}
+	return msgs.info(), nil
+}
 
-	layout, err := ParseDataLayoutMessage(layoutMsg.Data, sb)
+// ReadVirtualDatasetInfo returns dataset metadata like ReadDatasetInfo, with
+// the dataspace of virtual datasets updated to the extent determined by
+// their source datasets, which r must be able to resolve.
+func ReadVirtualDatasetInfo(r io.ReaderAt, header *ObjectHeader, sb *Superblock) (*DatasetInfo, error) {
+	msgs, err := loadDatasetMessages(r, header, sb)
 	if err != nil {
 		return nil, err
 	}
 
+	return msgs.info(), nil
+}
+
+// info returns the dataset metadata held by the messages.
+func (msgs *datasetMessages) info() *DatasetInfo {
 	return &DatasetInfo{
-		Datatype:  datatype,
-		Dataspace: dataspace,
-		Layout:    layout,
-	}, nil
+		Datatype:        msgs.datatype,
+		DatatypeAddress: msgs.datatypeAddress,
+		Dataspace:       msgs.dataspace,
+		Layout:          msgs.layout,
+		FilterPipeline:  msgs.filterPipeline,
+		FillValue:       msgs.fillValue,
+	}
 }
 
 // DatasetInfo holds metadata about a dataset.
 type DatasetInfo struct {
-	Datatype  *DatatypeMessage
-	Dataspace *DataspaceMessage
-	Layout    *DataLayoutMessage
+	Datatype        *DatatypeMessage
+	DatatypeAddress uint64 // Object header address of the named datatype, if Datatype is committed.
+	Dataspace       *DataspaceMessage
+	Layout          *DataLayoutMessage
+	FilterPipeline  *FilterPipelineMessage // nil if the dataset has no filters.
+	FillValue       *FillValueMessage      // nil if the dataset has no fill value message.
 }
 
 // String returns human-readable dataset info.
@@ -227,17 +297,9 @@ func (di *DatasetInfo) String() string {
 	)
 }
 
-// readChunkedData reads data from chunked layout.
-func readChunkedData(r io.ReaderAt, layout *DataLayoutMessage, dataspace *DataspaceMessage, datatype *DatatypeMessage, sb *Superblock, filterPipeline *FilterPipelineMessage) ([]byte, error) {
-	// Parse B-tree to get chunk index.
-	// Note: chunk dimensions may include an extra dimension for datatype size.
-	// (HDF5 stores "fastest-varying dimension" as bytes, see H5Dbtree.c comments).
-	ndims := len(layout.ChunkSize)
-	btree, err := ParseBTreeV1Node(r, layout.DataAddress, sb.OffsetSize, ndims, layout.ChunkSize)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse B-tree: %w", err)
-	}
-
+// readChunkedData reads data from chunked layout. Elements of chunks that were
+// never written are set to the fill value.
+func readChunkedData(r io.ReaderAt, layout *DataLayoutMessage, dataspace *DataspaceMessage, datatype *DatatypeMessage, sb *Superblock, filterPipeline *FilterPipelineMessage, fillValue *FillValueMessage) ([]byte, error) {
 	// Calculate total data size.
 	totalElements := dataspace.TotalElements()
 	elementSize := uint64(datatype.Size)
@@ -249,44 +311,25 @@ func readChunkedData(r io.ReaderAt, layout *DataLayoutMessage, dataspace *Datasp
 	}
 
 	// Validate total size is within reasonable limits.
-	if err := utils.ValidateBufferSize(totalBytes, utils.MaxChunkSize*1024, "dataset"); err != nil {
+	if err := utils.ValidateBufferSize(totalBytes, utils.MaxDatasetSize, "dataset"); err != nil {
 		return nil, fmt.Errorf("dataset too large: %w", err)
 	}
 
-	// Allocate output buffer.
+	// Allocate output buffer, pre-filled for chunks missing from the index.
 	rawData := make([]byte, totalBytes)
+	FillBuffer(rawData, fillValue.Pattern(elementSize))
 
-	// Collect all chunks from B-tree (handles both leaf and non-leaf nodes).
-	chunks, err := btree.CollectAllChunks(r, sb.OffsetSize, layout.ChunkSize)
+	// Collect all chunks from the chunk index.
+	// Note: chunk dimensions include an extra dimension for datatype size.
+	// (HDF5 stores "fastest-varying dimension" as bytes, see H5Dbtree.c comments).
+	chunks, err := CollectChunks(r, layout, dataspace, sb)
 	if err != nil {
 		return nil, fmt.Errorf("failed to collect chunks: %w", err)
 	}
 
 	// Read each chunk and copy to correct position.
-	for _, chunk := range chunks {
-		chunkKey := chunk.Key
-		chunkAddr := chunk.Address
-
-		// CVE-2025-7067 fix: Validate chunk size before allocation to prevent buffer overflow.
-		if err := utils.ValidateBufferSize(uint64(chunkKey.Nbytes), utils.MaxChunkSize, "chunk data"); err != nil {
-			return nil, fmt.Errorf("invalid chunk size at 0x%x: %w", chunkAddr, err)
-		}
-
-		// Read chunk data.
-		chunkData := make([]byte, chunkKey.Nbytes)
-		//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-		_, err := r.ReadAt(chunkData, int64(chunkAddr))
-		if err != nil {
-			return nil, fmt.Errorf("failed to read chunk at 0x%x: %w", chunkAddr, err)
-		}
-
-		// Apply filters (decompression, etc) if present.
-		if filterPipeline != nil {
-			chunkData, err = filterPipeline.ApplyFilters(chunkData)
-			if err != nil {
-				return nil, fmt.Errorf("failed to apply filters to chunk at 0x%x: %w", chunkAddr, err)
-			}
-		}
+	err = DecodeChunks(r, chunks, filterPipeline, chunkReadConcurrency(r), func(i int, chunkData []byte) error {
+		chunkKey := chunks[i].Key
 
 		// Calculate where this chunk goes in the output array.
 		// For N-dimensional dataset, chunk [i0, i1, ...] maps to elements:
@@ -298,10 +341,13 @@ func readChunkedData(r io.ReaderAt, layout *DataLayoutMessage, dataspace *Datasp
 		actualChunkDims := layout.ChunkSize[:len(dataDims)]
 		actualChunkCoords := chunkKey.Scaled[:len(dataDims)]
 
-		err = copyChunkToArray(chunkData, rawData, actualChunkCoords, actualChunkDims, dataDims, elementSize)
-		if err != nil {
-			return nil, fmt.Errorf("failed to copy chunk %v: %w", actualChunkCoords, err)
+		if err := copyChunkToArray(chunkData, rawData, actualChunkCoords, actualChunkDims, dataDims, elementSize); err != nil {
+			return fmt.Errorf("failed to copy chunk %v: %w", actualChunkCoords, err)
 		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return rawData, nil
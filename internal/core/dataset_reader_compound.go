@@ -14,103 +14,35 @@ type CompoundValue map[string]interface{}
 
 // ReadDatasetCompound reads a dataset with compound datatype and returns array of compound values.
 func ReadDatasetCompound(r io.ReaderAt, header *ObjectHeader, sb *Superblock) ([]CompoundValue, error) {
-	// 1. Extract required messages.
-	var datatypeMsg, dataspaceMsg, layoutMsg, filterPipelineMsg *HeaderMessage
-
-	for _, msg := range header.Messages {
-		switch msg.Type {
-		case MsgDatatype:
-			datatypeMsg = msg
-		case MsgDataspace:
-			dataspaceMsg = msg
-		case MsgDataLayout:
-			layoutMsg = msg
-		case MsgFilterPipeline:
-			filterPipelineMsg = msg
-		}
-	}
-
-	// Validate.
-	if datatypeMsg == nil {
-		return nil, errors.New("datatype message not found")
-	}
-	if dataspaceMsg == nil {
-		return nil, errors.New("dataspace message not found")
-	}
-	if layoutMsg == nil {
-		return nil, errors.New("data layout message not found")
-	}
-
-	// 2. Parse datatype.
-	datatype, err := ParseDatatypeMessage(datatypeMsg.Data)
+	// 1. Extract and parse required messages.
+	msgs, err := loadDatasetMessages(r, header, sb)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse datatype: %w", err)
+		return nil, err
 	}
 
-	if !datatype.IsCompound() {
+	if !msgs.datatype.IsCompound() {
 		return nil, errors.New("not a compound datatype")
 	}
 
-	// Parse compound structure.
-	compoundType, err := ParseCompoundType(datatype)
+	// 2. Parse compound structure.
+	compoundType, err := ParseCompoundType(msgs.datatype)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse compound type: %w", err)
 	}
 
-	// 3. Parse dataspace.
-	dataspace, err := ParseDataspaceMessage(dataspaceMsg.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse dataspace: %w", err)
-	}
-
-	// 4. Parse layout.
-	layout, err := ParseDataLayoutMessage(layoutMsg.Data, sb)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse layout: %w", err)
-	}
-
-	// 5. Parse filter pipeline (optional).
-	var filterPipeline *FilterPipelineMessage
-	if filterPipelineMsg != nil {
-		filterPipeline, err = ParseFilterPipelineMessage(filterPipelineMsg.Data)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse filter pipeline: %w", err)
-		}
-	}
-
-	// 6. Calculate total number of elements.
-	totalElements := dataspace.TotalElements()
+	// 3. Calculate total number of elements.
+	totalElements := msgs.dataspace.TotalElements()
 	if totalElements == 0 {
 		return []CompoundValue{}, nil
 	}
 
-	// 7. Read raw data based on layout.
-	var rawData []byte
-
-	switch {
-	case layout.IsCompact():
-		rawData = layout.CompactData
-
-	case layout.IsContiguous():
-		dataSize := totalElements * uint64(datatype.Size)
-		rawData = make([]byte, dataSize)
-		//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-		_, err := r.ReadAt(rawData, int64(layout.DataAddress))
-		if err != nil {
-			return nil, fmt.Errorf("failed to read contiguous data: %w", err)
-		}
-
-	case layout.IsChunked():
-		rawData, err = readChunkedData(r, layout, dataspace, datatype, sb, filterPipeline)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read chunked data: %w", err)
-		}
-
-	default:
-		return nil, fmt.Errorf("unsupported layout class: %d", layout.Class)
+	// 4. Read raw data based on layout.
+	rawData, err := readDatasetRawData(r, msgs, sb)
+	if err != nil {
+		return nil, err
 	}
 
-	// 8. Convert raw bytes to compound values.
+	// 5. Convert raw bytes to compound values.
 	return parseCompoundData(rawData, compoundType, totalElements, r, sb)
 }
 
@@ -248,11 +180,16 @@ func extractString(data []byte, paddingType uint8) string {
 }
 
 // readVariableString reads a variable-length string from the Global Heap.
-// The data contains a Global Heap reference: heap_address (offset_size bytes) + object_index (4 bytes).
+// The data contains the string length (4 bytes) followed by a Global Heap
+// reference: heap_address (offset_size bytes) + object_index (4 bytes).
 func readVariableString(r io.ReaderAt, data []byte, sb *Superblock) (string, error) {
+	if len(data) < 4 {
+		return "", errors.New("insufficient data for variable-length string")
+	}
+
 	// Parse the global heap reference.
 	offsetSize := int(sb.OffsetSize)
-	ref, err := ParseGlobalHeapReference(data, offsetSize)
+	ref, err := ParseGlobalHeapReference(data[4:], offsetSize)
 	if err != nil {
 		return "", fmt.Errorf("failed to parse global heap reference: %w", err)
 	}
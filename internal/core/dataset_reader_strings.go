@@ -12,104 +12,31 @@ import (
 // ReadDatasetStrings reads a string dataset and returns values as string array.
 // Supports both fixed-length and variable-length strings.
 func ReadDatasetStrings(r io.ReaderAt, header *ObjectHeader, sb *Superblock) ([]string, error) {
-	// 1. Extract required messages from object header.
-	var datatypeMsg, dataspaceMsg, layoutMsg *HeaderMessage
-
-	for _, msg := range header.Messages {
-		switch msg.Type {
-		case MsgDatatype:
-			datatypeMsg = msg
-		case MsgDataspace:
-			dataspaceMsg = msg
-		case MsgDataLayout:
-			layoutMsg = msg
-		}
-	}
-
-	// Validate we have all required messages.
-	if datatypeMsg == nil {
-		return nil, errors.New("datatype message not found")
-	}
-	if dataspaceMsg == nil {
-		return nil, errors.New("dataspace message not found")
-	}
-	if layoutMsg == nil {
-		return nil, errors.New("data layout message not found")
-	}
-
-	// 2. Parse datatype.
-	datatype, err := ParseDatatypeMessage(datatypeMsg.Data)
+	// 1. Extract and parse required messages.
+	msgs, err := loadDatasetMessages(r, header, sb)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse datatype: %w", err)
+		return nil, err
 	}
 
-	// Verify it's a string type.
-	if !datatype.IsString() {
-		return nil, fmt.Errorf("datatype is not string: %s", datatype)
-	}
-
-	// 3. Parse dataspace.
-	dataspace, err := ParseDataspaceMessage(dataspaceMsg.Data)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse dataspace: %w", err)
-	}
-
-	// 4. Parse layout.
-	layout, err := ParseDataLayoutMessage(layoutMsg.Data, sb)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse layout: %w", err)
+	// 2. Verify it's a string type.
+	if !msgs.datatype.IsString() {
+		return nil, fmt.Errorf("datatype is not string: %s", msgs.datatype)
 	}
 
-	// 5. Calculate total number of elements.
-	totalElements := dataspace.TotalElements()
+	// 3. Calculate total number of elements.
+	totalElements := msgs.dataspace.TotalElements()
 	if totalElements == 0 {
 		return []string{}, nil
 	}
 
-	// 6. Read data based on layout type.
-	var rawData []byte
-
-	switch {
-	case layout.IsCompact():
-		// Data is stored directly in the layout message.
-		rawData = layout.CompactData
-
-	case layout.IsContiguous():
-		// Data is stored contiguously at specific address.
-		dataSize := totalElements * uint64(datatype.Size)
-		rawData = make([]byte, dataSize)
-
-		//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-		_, err := r.ReadAt(rawData, int64(layout.DataAddress))
-		if err != nil {
-			return nil, fmt.Errorf("failed to read contiguous data: %w", err)
-		}
-
-	case layout.IsChunked():
-		// Data is stored in chunks indexed by B-tree.
-		// Extract filter pipeline if present.
-		var filterPipeline *FilterPipelineMessage
-		for _, msg := range header.Messages {
-			if msg.Type == MsgFilterPipeline {
-				filterPipeline, err = ParseFilterPipelineMessage(msg.Data)
-				if err != nil {
-					return nil, fmt.Errorf("failed to parse filter pipeline: %w", err)
-				}
-				break
-			}
-		}
-
-		rawData, err = readChunkedData(r, layout, dataspace, datatype, sb, filterPipeline)
-		if err != nil {
-			return nil, fmt.Errorf("failed to read chunked data: %w", err)
-		}
-
-	default:
-		return nil, fmt.Errorf("unsupported layout class: %d", layout.Class)
+	// 4. Read data based on layout type.
+	rawData, err := readDatasetRawData(r, msgs, sb)
+	if err != nil {
+		return nil, err
 	}
 
-	// 7. Convert raw bytes to string array based on string type.
-	return convertToStrings(rawData, datatype, totalElements)
+	// 5. Convert raw bytes to string array based on string type.
+	return convertToStrings(rawData, msgs.datatype, totalElements)
 }
 
 // convertToStrings converts raw bytes to string array based on string datatype.
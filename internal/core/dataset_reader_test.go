@@ -1,7 +1,9 @@
 package core
 
 import (
+	"bytes"
 	"encoding/binary"
+	"strings"
 	"testing"
 )
 
@@ -358,3 +360,43 @@ func buildDataLayoutMessage(t *testing.T, class DataLayoutClass, address uint64)
 	data[17] = byte((size >> 56) & 0xFF)
 	return data
 }
+
+func TestReadDatasetRawData_ContiguousSize(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+	r := bytes.NewReader(make([]byte, 256))
+
+	tests := []struct {
+		name    string
+		dims    []uint64
+		size    uint64 // Storage size in the layout message.
+		wantErr string
+	}{
+		{"fits storage", []uint64{4}, 32, ""},
+		{"larger than storage", []uint64{8}, 32, "smaller than the dataset"},
+		{"too large to read", []uint64{1 << 40}, 1 << 43, "too large"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msgs := &datasetMessages{
+				datatype:  &DatatypeMessage{Class: DatatypeFloat, Size: 8},
+				dataspace: &DataspaceMessage{Type: DataspaceSimple, Dimensions: tt.dims},
+				layout:    &DataLayoutMessage{Version: 3, Class: LayoutContiguous, DataAddress: 64, DataSize: tt.size},
+			}
+
+			data, err := readDatasetRawData(r, msgs, sb)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if uint64(len(data)) != tt.size {
+					t.Errorf("read %d bytes, want %d", len(data), tt.size)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -50,6 +50,12 @@ func ParseDataspaceMessage(data []byte) (*DataspaceMessage, error) {
 		Version: version,
 	}
 
+	// Version 2 stores the type; null dataspaces have no elements.
+	if version == 2 && len(data) >= 4 && DataspaceType(data[3]) == DataspaceNull {
+		ds.Type = DataspaceNull
+		return ds, nil
+	}
+
 	// Determine dataspace type based on dimensionality.
 	if dimensionality == 0 {
 		// Scalar dataspace.
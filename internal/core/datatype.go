@@ -94,6 +94,60 @@ func calculateCompoundPropsLen(properties []byte, version uint8) (int, error) {
 	return offset, nil
 }
 
+// calculateDerivedPropsLen calculates the properties size of an array, enum,
+// opaque or variable-length datatype.
+func calculateDerivedPropsLen(properties []byte, class DatatypeClass, version uint8, classBitField, size uint32) (int, error) {
+	var offset int
+	switch class {
+	case DatatypeOpaque:
+		// The class bit field holds the length of the padded tag.
+		return int(classBitField & 0xFF), nil
+	case DatatypeArray:
+		if len(properties) < 1 {
+			return 0, errors.New("array properties too short")
+		}
+		// Version 3 drops the reserved bytes and permutation indices.
+		rank := int(properties[0])
+		offset = 4 + 8*rank
+		if version >= 3 {
+			offset = 1 + 4*rank
+		}
+	}
+	if offset+8 > len(properties) {
+		return 0, errors.New("base type truncated")
+	}
+	base, err := ParseDatatypeMessage(properties[offset:])
+	if err != nil {
+		return 0, err
+	}
+	offset += 8 + len(base.Properties)
+
+	if class == DatatypeEnum {
+		// Member names, padded to 8 bytes before version 3, then the values.
+		members := int(classBitField & 0xFFFF)
+		for i := 0; i < members; i++ {
+			end := offset
+			for end < len(properties) && properties[end] != 0 {
+				end++
+			}
+			if end >= len(properties) {
+				return 0, fmt.Errorf("enum member %d: name not null-terminated", i)
+			}
+			nameLen := end + 1 - offset
+			if version < 3 {
+				nameLen = (nameLen + 7) / 8 * 8
+			}
+			offset += nameLen
+		}
+		offset += members * int(size)
+	}
+
+	if offset > len(properties) {
+		return 0, errors.New("properties truncated")
+	}
+	return offset, nil
+}
+
 // ParseDatatypeMessage parses a datatype message from header message data.
 func ParseDatatypeMessage(data []byte) (*DatatypeMessage, error) {
 	if len(data) < 8 {
@@ -124,11 +178,16 @@ func ParseDatatypeMessage(data []byte) (*DatatypeMessage, error) {
 		propsLen = 4
 	case DatatypeTime:
 		propsLen = 2
-	case DatatypeString:
-		// String properties are variable, but typically minimal
-		// For now, take all remaining (safe for top-level parsing)
-		propsLen = len(data) - 8
+	case DatatypeString, DatatypeReference:
+		// Padding, character set and reference type are in the class bit field.
+		propsLen = 0
 	case DatatypeCompound:
+		// Versions 1 and 2 keep the number of members in the class bit
+		// field; like the C library (H5O__dtype_decode_helper), reject
+		// compound types without members.
+		if version < 3 && classBitField&0xFFFF == 0 {
+			return nil, errors.New("compound datatype has no members")
+		}
 		// Compound types: properties are variable length and self-describing
 		// For inline parsing (nested compounds), we must calculate the exact size
 		// by walking through the member definitions
@@ -139,10 +198,16 @@ func ParseDatatypeMessage(data []byte) (*DatatypeMessage, error) {
 		} else {
 			propsLen = calculatedLen
 		}
-	case DatatypeArray, DatatypeEnum, DatatypeReference, DatatypeOpaque, DatatypeVarLen:
-		// Complex types: properties are variable length
-		// For inline parsing, take all remaining
-		propsLen = len(data) - 8
+	case DatatypeArray, DatatypeEnum, DatatypeOpaque, DatatypeVarLen:
+		// Properties end with or hold a base type; for inline parsing
+		// (e.g., compound members) their exact size is needed.
+		calculatedLen, err := calculateDerivedPropsLen(data[8:], class, version, classBitField, size)
+		if err != nil {
+			// Fallback: take all remaining
+			propsLen = len(data) - 8
+		} else {
+			propsLen = calculatedLen
+		}
 	default:
 		// Unknown type: take all remaining
 		propsLen = len(data) - 8
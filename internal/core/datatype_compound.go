@@ -48,7 +48,13 @@ func ParseCompoundType(dt *DatatypeMessage) (*CompoundType, error) {
 		// For version 1, number of members is in ClassBitField bits 0-15.
 		//nolint:gosec // G115: HDF5 binary format bitfield extraction
 		numMembers := uint16(dt.ClassBitField & 0xFFFF)
-		return parseCompoundV1(compound, dt.Properties, numMembers)
+		return parseCompoundV1(compound, dt.Properties, numMembers, true)
+	case 2:
+		// Version 2 drops the array info of version 1 (array members use
+		// the array datatype instead).
+		//nolint:gosec // G115: HDF5 binary format bitfield extraction
+		numMembers := uint16(dt.ClassBitField & 0xFFFF)
+		return parseCompoundV1(compound, dt.Properties, numMembers, false)
 	case 3:
 		return parseCompoundV3(compound, dt.Properties)
 	default:
@@ -56,18 +62,18 @@ func ParseCompoundType(dt *DatatypeMessage) (*CompoundType, error) {
 	}
 }
 
-// parseCompoundV1 parses version 1 compound datatype properties.
+// parseCompoundV1 parses version 1 and 2 compound datatype properties.
 // Format per member (H5Odtype.c:360-481):
 //  1. Name (null-terminated, padded to 8-byte boundary).
 //  2. Offset (uint32, 4 bytes).
-//  3. Array info (28 bytes total):
+//  3. Array info (28 bytes total, version 1 only):
 //     - Dimensionality (1 byte).
 //     - Reserved (3 bytes).
 //     - Dimension permutation (4 bytes).
 //     - Reserved (4 bytes).
 //     - Dimension sizes (4 × uint32 = 16 bytes).
 //  4. Member datatype (recursive, NO padding between members).
-func parseCompoundV1(compound *CompoundType, properties []byte, numMembers uint16) (*CompoundType, error) {
+func parseCompoundV1(compound *CompoundType, properties []byte, numMembers uint16, arrayInfo bool) (*CompoundType, error) {
 	offset := 0
 
 	for i := uint16(0); i < numMembers; i++ {
@@ -98,11 +104,13 @@ func parseCompoundV1(compound *CompoundType, properties []byte, numMembers uint1
 		offset += 4
 
 		// 3. Array info (always 28 bytes for version 1, even for scalar members).
-		if offset+28 > len(properties) {
-			return nil, fmt.Errorf("member %d: array info truncated", i)
+		if arrayInfo {
+			if offset+28 > len(properties) {
+				return nil, fmt.Errorf("member %d: array info truncated", i)
+			}
+			// Skip array info (we don't support array members yet).
+			offset += 28
 		}
-		// Skip array info (we don't support array members yet).
-		offset += 28
 
 		// 4. Member datatype (recursive parse).
 		if offset+8 > len(properties) {
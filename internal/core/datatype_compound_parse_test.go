@@ -36,7 +36,7 @@ func TestParseCompoundType(t *testing.T) {
 			name: "unsupported version",
 			dt: &DatatypeMessage{
 				Class:      DatatypeCompound,
-				Version:    2,
+				Version:    4,
 				Properties: []byte{0x00, 0x00},
 			},
 			wantErr:     true,
@@ -106,6 +106,39 @@ func TestParseCompoundType_Version1(t *testing.T) {
 	require.Equal(t, uint32(4), got.Members[0].Type.Size)
 }
 
+// TestParseCompoundType_Version2 tests version 2 compound parsing, which has
+// no array info after the member offset.
+func TestParseCompoundType_Version2(t *testing.T) {
+	properties := make([]byte, 0, 100)
+
+	// Members "a" (int32 at offset 0) and "b" (int32 at offset 4)
+	for i, name := range []string{"a", "b"} {
+		properties = append(properties, []byte(name+"\x00\x00\x00\x00\x00\x00\x00")...)
+		properties = binary.LittleEndian.AppendUint32(properties, uint32(4*i))
+
+		dtBuf := make([]byte, 12)
+		binary.LittleEndian.PutUint32(dtBuf[0:4], uint32(DatatypeFixed)|(1<<4))
+		binary.LittleEndian.PutUint32(dtBuf[4:8], 4)
+		dtBuf[10] = 32 // precision
+		properties = append(properties, dtBuf...)
+	}
+
+	dt := &DatatypeMessage{
+		Class:         DatatypeCompound,
+		Version:       2,
+		Size:          8,
+		ClassBitField: 2,
+		Properties:    properties,
+	}
+
+	got, err := ParseCompoundType(dt)
+	require.NoError(t, err)
+	require.Len(t, got.Members, 2)
+	require.Equal(t, "b", got.Members[1].Name)
+	require.Equal(t, uint32(4), got.Members[1].Offset)
+	require.Equal(t, uint32(4), got.Members[1].Type.Size)
+}
+
 // TestParseCompoundType_Version3 tests version 3 compound parsing.
 func TestParseCompoundType_Version3(t *testing.T) {
 	// Version 3 format: num members(4) + [name + offset(4) + datatype(8+)]*
@@ -0,0 +1,225 @@
+package core
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Numeric is the set of Go element types that numeric HDF5 data can be
+// decoded into directly.
+type Numeric interface {
+	~int8 | ~int16 | ~int32 | ~int64 |
+		~uint8 | ~uint16 | ~uint32 | ~uint64 |
+		~float32 | ~float64
+}
+
+// numericKind classifies how a single stored element must be decoded.
+type numericKind uint8
+
+const (
+	kindSigned numericKind = iota
+	kindUnsigned
+	kindIEEEFloat
+	kindFloat16
+	kindBFloat16
+	kindFP8E4M3
+	kindFP8E5M2
+)
+
+// IsSigned reports whether a fixed-point datatype stores signed (two's complement) values.
+// Bit 3 of the class bit field carries the sign flag (H5Odtype.c).
+func (dt *DatatypeMessage) IsSigned() bool {
+	return dt.Class == DatatypeFixed && dt.ClassBitField&0x08 != 0
+}
+
+// IsNumeric reports whether the datatype can be decoded by DecodeNumeric.
+// This covers integers, bitfields, floats (including fp8, float16 and bfloat16)
+// and enums, which are decoded through their integer base type.
+func (dt *DatatypeMessage) IsNumeric() bool {
+	_, _, err := numericLayout(dt)
+	return err == nil
+}
+
+// numericLayout returns the decoding kind and the datatype whose byte order
+// and size apply to the stored element (the base type for enums).
+func numericLayout(dt *DatatypeMessage) (numericKind, *DatatypeMessage, error) {
+	switch dt.Class {
+	case DatatypeFixed:
+		if !validNumericSize(dt.Size) {
+			return 0, nil, fmt.Errorf("unsupported integer size: %d", dt.Size)
+		}
+		if dt.IsSigned() {
+			return kindSigned, dt, nil
+		}
+		return kindUnsigned, dt, nil
+
+	case DatatypeBitfield:
+		if !validNumericSize(dt.Size) {
+			return 0, nil, fmt.Errorf("unsupported bitfield size: %d", dt.Size)
+		}
+		return kindUnsigned, dt, nil
+
+	case DatatypeFloat:
+		return floatLayout(dt)
+
+	case DatatypeEnum:
+		// Enum properties start with the base type, followed by names and values.
+		base, err := ParseDatatypeMessage(dt.Properties)
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to parse enum base type: %w", err)
+		}
+		kind, _, err := numericLayout(base)
+		if err != nil {
+			return 0, nil, err
+		}
+		return kind, base, nil
+
+	default:
+		return 0, nil, fmt.Errorf("datatype is not numeric: %s", dt)
+	}
+}
+
+// floatLayout selects the float decoding based on size and, for the small
+// formats, the exponent width recorded in the float properties.
+func floatLayout(dt *DatatypeMessage) (numericKind, *DatatypeMessage, error) {
+	switch dt.Size {
+	case 4, 8:
+		return kindIEEEFloat, dt, nil
+	case 2:
+		// Properties byte 5 holds the exponent size: 8 bits for bfloat16, 5 for IEEE half.
+		if len(dt.Properties) > 5 && dt.Properties[5] == 8 {
+			return kindBFloat16, dt, nil
+		}
+		return kindFloat16, dt, nil
+	case 1:
+		if len(dt.Properties) > 5 && dt.Properties[5] == 5 {
+			return kindFP8E5M2, dt, nil
+		}
+		return kindFP8E4M3, dt, nil
+	default:
+		return 0, nil, fmt.Errorf("unsupported float size: %d", dt.Size)
+	}
+}
+
+func validNumericSize(size uint32) bool {
+	return size == 1 || size == 2 || size == 4 || size == 8
+}
+
+// DecodeNumeric decodes len(dst) elements of the given numeric datatype from
+// rawData into dst. Values are decoded in their stored precision and converted
+// straight to T, so integers never pass through float64.
+//
+// Conversion between stored and destination types follows Go conversion rules
+// (for example, storing an int64 dataset into []int8 truncates).
+func DecodeNumeric[T Numeric](rawData []byte, datatype *DatatypeMessage, dst []T) error {
+	kind, base, err := numericLayout(datatype)
+	if err != nil {
+		return err
+	}
+
+	size := uint64(base.Size)
+	order := base.GetByteOrder()
+	if uint64(len(rawData)) < uint64(len(dst))*size {
+		return fmt.Errorf("data truncated (%s): need %d bytes, have %d",
+			kindName(kind, size), uint64(len(dst))*size, len(rawData))
+	}
+
+	for i := range dst {
+		elem := rawData[uint64(i)*size : uint64(i+1)*size]
+
+		switch kind {
+		case kindSigned:
+			dst[i] = T(decodeSigned(elem, order))
+		case kindUnsigned:
+			dst[i] = T(decodeUnsigned(elem, order))
+		case kindIEEEFloat:
+			if size == 4 {
+				dst[i] = T(math.Float32frombits(order.Uint32(elem)))
+			} else {
+				dst[i] = T(math.Float64frombits(order.Uint64(elem)))
+			}
+		case kindFloat16:
+			dst[i] = T(float16ToFloat32(order.Uint16(elem)))
+		case kindBFloat16:
+			dst[i] = T(BFloat16(order.Uint16(elem)).ToFloat32())
+		case kindFP8E4M3:
+			dst[i] = T(FP8E4M3(elem[0]).ToFloat32())
+		case kindFP8E5M2:
+			dst[i] = T(FP8E5M2(elem[0]).ToFloat32())
+		}
+	}
+
+	return nil
+}
+
+// decodeUnsigned reads a 1, 2, 4 or 8 byte unsigned integer.
+func decodeUnsigned(elem []byte, order binary.ByteOrder) uint64 {
+	switch len(elem) {
+	case 1:
+		return uint64(elem[0])
+	case 2:
+		return uint64(order.Uint16(elem))
+	case 4:
+		return uint64(order.Uint32(elem))
+	default:
+		return order.Uint64(elem)
+	}
+}
+
+// decodeSigned reads a 1, 2, 4 or 8 byte two's complement integer.
+//
+//nolint:gosec // G115: HDF5 binary format requires unsigned to signed reinterpretation
+func decodeSigned(elem []byte, order binary.ByteOrder) int64 {
+	switch len(elem) {
+	case 1:
+		return int64(int8(elem[0]))
+	case 2:
+		return int64(int16(order.Uint16(elem)))
+	case 4:
+		return int64(int32(order.Uint32(elem)))
+	default:
+		return int64(order.Uint64(elem))
+	}
+}
+
+// float16ToFloat32 converts an IEEE 754 half precision value to float32.
+func float16ToFloat32(h uint16) float32 {
+	sign := uint32(h>>15) << 31
+	exp := uint32(h>>10) & 0x1F
+	mant := uint32(h) & 0x3FF
+
+	switch {
+	case exp == 0 && mant == 0:
+		return math.Float32frombits(sign)
+	case exp == 0:
+		// Subnormal: value = mant * 2^-24.
+		v := float32(mant) / (1 << 24)
+		if sign != 0 {
+			return -v
+		}
+		return v
+	case exp == 0x1F:
+		return math.Float32frombits(sign | 0x7F800000 | mant<<13)
+	default:
+		return math.Float32frombits(sign | (exp+112)<<23 | mant<<13)
+	}
+}
+
+// kindName returns a short label used in error messages.
+func kindName(kind numericKind, size uint64) string {
+	switch kind {
+	case kindSigned:
+		return fmt.Sprintf("int%d", size*8)
+	case kindUnsigned:
+		return fmt.Sprintf("uint%d", size*8)
+	case kindIEEEFloat:
+		return fmt.Sprintf("float%d", size*8)
+	case kindFloat16:
+		return "float16"
+	case kindBFloat16:
+		return "bfloat16"
+	default:
+		return "fp8"
+	}
+}
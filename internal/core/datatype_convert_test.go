@@ -0,0 +1,87 @@
+package core
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeNumeric_Integers(t *testing.T) {
+	t.Run("signed big-endian int16", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFixed, Size: 2, ClassBitField: 0x09}
+		raw := []byte{0xFF, 0xFE, 0x00, 0x07}
+		got := make([]int32, 2)
+		require.NoError(t, DecodeNumeric(raw, dt, got))
+		require.Equal(t, []int32{-2, 7}, got)
+	})
+
+	t.Run("unsigned uint64 beyond float64 precision", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFixed, Size: 8}
+		raw := make([]byte, 8)
+		binary.LittleEndian.PutUint64(raw, math.MaxUint64-1)
+		got := make([]uint64, 1)
+		require.NoError(t, DecodeNumeric(raw, dt, got))
+		require.Equal(t, uint64(math.MaxUint64-1), got[0])
+	})
+
+	t.Run("enum decodes through base type", func(t *testing.T) {
+		base := []byte{0x10, 0x08, 0x00, 0x00, 0x01, 0x00, 0x00, 0x00, 0, 0, 8, 0}
+		dt := &DatatypeMessage{Class: DatatypeEnum, Size: 1, Properties: base}
+		got := make([]int8, 2)
+		require.NoError(t, DecodeNumeric([]byte{0xFF, 0x02}, dt, got))
+		require.Equal(t, []int8{-1, 2}, got)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFixed, Size: 4}
+		err := DecodeNumeric([]byte{1, 2, 3}, dt, make([]int32, 1))
+		require.ErrorContains(t, err, "data truncated")
+	})
+
+	t.Run("not numeric", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeString, Size: 4}
+		require.False(t, dt.IsNumeric())
+		require.Error(t, DecodeNumeric([]byte{1, 2, 3, 4}, dt, make([]int32, 1)))
+	})
+}
+
+func TestDecodeNumeric_SmallFloats(t *testing.T) {
+	// Float properties: offset(2) precision(2) exp loc(1) exp size(1) mant loc(1) mant size(1) bias(4).
+	floatProps := func(precision, expLoc, expSize, mantSize uint8) []byte {
+		return []byte{0, 0, precision, 0, expLoc, expSize, 0, mantSize, 0, 0, 0, 0}
+	}
+
+	t.Run("float16", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFloat, Size: 2, Properties: floatProps(16, 10, 5, 10)}
+		raw := []byte{0x00, 0x3C, 0x00, 0xC0, 0x01, 0x00} // 1.0, -2.0, smallest subnormal
+		got := make([]float32, 3)
+		require.NoError(t, DecodeNumeric(raw, dt, got))
+		require.Equal(t, []float32{1, -2, float32(math.Ldexp(1, -24))}, got)
+	})
+
+	t.Run("bfloat16", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFloat, Size: 2, Properties: floatProps(16, 7, 8, 7)}
+		raw := append(Float32ToBFloat16(1.5).Encode(), Float32ToBFloat16(-3).Encode()...)
+		got := make([]float64, 2)
+		require.NoError(t, DecodeNumeric(raw, dt, got))
+		require.Equal(t, []float64{1.5, -3}, got)
+	})
+
+	t.Run("fp8 e4m3", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFloat, Size: 1, Properties: floatProps(8, 3, 4, 3)}
+		raw := []byte{byte(Float32ToFP8E4M3(2)), byte(Float32ToFP8E4M3(-0.5))}
+		got := make([]float32, 2)
+		require.NoError(t, DecodeNumeric(raw, dt, got))
+		require.Equal(t, []float32{2, -0.5}, got)
+	})
+
+	t.Run("fp8 e5m2", func(t *testing.T) {
+		dt := &DatatypeMessage{Class: DatatypeFloat, Size: 1, Properties: floatProps(8, 2, 5, 2)}
+		raw := []byte{byte(Float32ToFP8E5M2(4)), byte(Float32ToFP8E5M2(-1))}
+		got := make([]float32, 2)
+		require.NoError(t, DecodeNumeric(raw, dt, got))
+		require.Equal(t, []float32{4, -1}, got)
+	})
+}
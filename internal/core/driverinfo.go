@@ -0,0 +1,216 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Driver identifiers of the driver information block.
+const (
+	DriverFamily = "NCSAfami" // Family driver (H5FD_FAMILY)
+	DriverMulti  = "NCSAmult" // Multi and split drivers (H5FD_MULTI, H5FD_SPLIT)
+)
+
+// driverInfoHeaderSize is the size of the driver information block before
+// the driver information: version (1), reserved (3), size (4) and
+// identifier (8).
+const driverInfoHeaderSize = 16
+
+// Memory types of the multi driver (H5FD_mem_t in the HDF5 library). The
+// driver information maps each type except MemDefault to a member.
+const (
+	MemDefault uint8 = iota // Default (maps to the type itself)
+	MemSuper                // Superblock
+	MemBTree                // B-tree nodes
+	MemDraw                 // Raw data
+	MemGHeap                // Global heaps
+	MemLHeap                // Local heaps
+	MemOHdr                 // Object headers
+	memNTypes
+)
+
+// DriverInfo represents the driver information block of superblocks version
+// 0 and 1. It records how the file driver that wrote the file laid out its
+// address space.
+//
+// Format:
+//   - Version (1 byte, 0)
+//   - Reserved (3 bytes)
+//   - Driver information size (4 bytes)
+//   - Driver identification (8 ASCII bytes, e.g. "NCSAfami")
+//   - Driver information (variable size)
+//
+// Reference: HDF5 Format Spec Section II.B (Driver Information Block).
+type DriverInfo struct {
+	Driver string // Driver identification (DriverFamily, DriverMulti)
+	Data   []byte // Driver information, encoded by the driver
+}
+
+// ReadDriverInfo reads the driver information block of sb. It returns nil
+// if the superblock has none.
+func ReadDriverInfo(r io.ReaderAt, sb *Superblock) (*DriverInfo, error) {
+	if sb.Version > Version1 || sb.DriverInfo == 0 || sb.DriverInfo == ^uint64(0) {
+		return nil, nil
+	}
+
+	//nolint:gosec // G115: HDF5 addresses fit in int64
+	addr := int64(sb.BaseAddress + sb.DriverInfo)
+	header := make([]byte, driverInfoHeaderSize)
+	if _, err := r.ReadAt(header, addr); err != nil {
+		return nil, fmt.Errorf("failed to read driver info block: %w", err)
+	}
+	if header[0] != 0 {
+		return nil, fmt.Errorf("unsupported driver info block version: %d", header[0])
+	}
+
+	size := binary.LittleEndian.Uint32(header[4:8])
+	if size > 1<<20 {
+		return nil, fmt.Errorf("driver info size %d too large", size)
+	}
+	data := make([]byte, size)
+	if _, err := r.ReadAt(data, addr+driverInfoHeaderSize); err != nil {
+		return nil, fmt.Errorf("failed to read driver info: %w", err)
+	}
+
+	return &DriverInfo{Driver: string(header[8:16]), Data: data}, nil
+}
+
+// Size returns the encoded size of the driver information block.
+func (di *DriverInfo) Size() uint64 {
+	return driverInfoHeaderSize + uint64(len(di.Data))
+}
+
+// Encode encodes the driver information block.
+func (di *DriverInfo) Encode() []byte {
+	buf := make([]byte, di.Size())
+	//nolint:gosec // G115: driver information is small
+	binary.LittleEndian.PutUint32(buf[4:8], uint32(len(di.Data)))
+	copy(buf[8:16], di.Driver)
+	copy(buf[driverInfoHeaderSize:], di.Data)
+	return buf
+}
+
+// FamilyMemberSize returns the member size stored in the driver information
+// of the family driver (H5FD__family_sb_decode).
+func (di *DriverInfo) FamilyMemberSize() (uint64, error) {
+	if di.Driver != DriverFamily {
+		return 0, fmt.Errorf("driver %q is not the family driver", di.Driver)
+	}
+	if len(di.Data) < 8 {
+		return 0, errors.New("family driver info too short")
+	}
+	return binary.LittleEndian.Uint64(di.Data), nil
+}
+
+// NewFamilyDriverInfo returns the driver information of the family driver
+// with members of memberSize bytes.
+func NewFamilyDriverInfo(memberSize uint64) *DriverInfo {
+	data := make([]byte, 8)
+	binary.LittleEndian.PutUint64(data, memberSize)
+	return &DriverInfo{Driver: DriverFamily, Data: data}
+}
+
+// MultiMember describes a member file of the multi driver.
+type MultiMember struct {
+	Type    uint8  // Memory type the member is stored under (Mem* value)
+	Address uint64 // First address of the member in the file's address space
+	EOA     uint64 // End of allocated space in the member, relative to Address
+	Name    string // File name template, "%s" standing for the file name
+}
+
+// MultiDriverInfo is the driver information of the multi driver, which the
+// split driver uses with one member for metadata and one for raw data.
+type MultiDriverInfo struct {
+	// Map maps each memory type to the type of the member holding it,
+	// indexed by memory type (index MemDefault is unused).
+	Map [memNTypes]uint8
+
+	// Members lists the members in order of their memory type.
+	Members []MultiMember
+}
+
+// MultiDriverInfo decodes the driver information of the multi driver
+// (H5FD_multi_sb_decode):
+//   - Member map (1 byte per memory type from MemSuper, padded to 8 bytes)
+//   - Address and end of allocated space of each member (8 bytes each)
+//   - Name template of each member (null-terminated, padded to 8 bytes)
+func (di *DriverInfo) MultiDriverInfo() (*MultiDriverInfo, error) {
+	if di.Driver != DriverMulti {
+		return nil, fmt.Errorf("driver %q is not the multi driver", di.Driver)
+	}
+	data := di.Data
+	if len(data) < 8 {
+		return nil, errors.New("multi driver info too short")
+	}
+
+	info := &MultiDriverInfo{}
+	for mt := MemSuper; mt < memNTypes; mt++ {
+		mmt := data[mt-1]
+		if mmt == MemDefault {
+			mmt = mt
+		}
+		if mmt >= memNTypes {
+			return nil, fmt.Errorf("invalid multi driver member type %d", mmt)
+		}
+		info.Map[mt] = mmt
+	}
+
+	p := data[8:]
+	for mt := MemSuper; mt < memNTypes; mt++ {
+		if info.Map[mt] != mt {
+			continue
+		}
+		if len(p) < 16 {
+			return nil, errors.New("multi driver info too short")
+		}
+		info.Members = append(info.Members, MultiMember{
+			Type:    mt,
+			Address: binary.LittleEndian.Uint64(p[0:8]),
+			EOA:     binary.LittleEndian.Uint64(p[8:16]),
+		})
+		p = p[16:]
+	}
+
+	for i := range info.Members {
+		end := bytes.IndexByte(p, 0)
+		if end < 0 {
+			return nil, errors.New("multi driver member name not terminated")
+		}
+		info.Members[i].Name = string(p[:end])
+		p = p[min(alignUp8(end+1), len(p)):]
+	}
+
+	return info, nil
+}
+
+// NewMultiDriverInfo returns the driver information of the multi driver
+// (H5FD_multi_sb_encode) for info. Each member must be stored under its own
+// type in info.Map.
+func NewMultiDriverInfo(info *MultiDriverInfo) *DriverInfo {
+	var buf bytes.Buffer
+	mapping := make([]byte, 8)
+	for mt := MemSuper; mt < memNTypes; mt++ {
+		mapping[mt-1] = info.Map[mt]
+	}
+	buf.Write(mapping)
+
+	for _, m := range info.Members {
+		_ = binary.Write(&buf, binary.LittleEndian, m.Address)
+		_ = binary.Write(&buf, binary.LittleEndian, m.EOA)
+	}
+	for _, m := range info.Members {
+		name := make([]byte, alignUp8(len(m.Name)+1))
+		copy(name, m.Name)
+		buf.Write(name)
+	}
+
+	return &DriverInfo{Driver: DriverMulti, Data: buf.Bytes()}
+}
+
+// alignUp8 rounds n up to a multiple of 8.
+func alignUp8(n int) int {
+	return (n + 7) &^ 7
+}
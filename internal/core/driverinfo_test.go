@@ -0,0 +1,80 @@
+package core
+
+import (
+	"bytes"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// readTestDriverInfo reads the driver information block of a test file.
+func readTestDriverInfo(t *testing.T, filename string) *DriverInfo {
+	t.Helper()
+
+	data, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	r := bytes.NewReader(data)
+	sb, err := ReadSuperblock(r)
+	require.NoError(t, err)
+	di, err := ReadDriverInfo(r, sb)
+	require.NoError(t, err)
+	require.NotNil(t, di)
+	return di
+}
+
+func TestReadDriverInfo_Family(t *testing.T) {
+	di := readTestDriverInfo(t, "../../testdata/hdf5_official/family_file00000.h5")
+	require.Equal(t, DriverFamily, di.Driver)
+	size, err := di.FamilyMemberSize()
+	require.NoError(t, err)
+	require.Equal(t, uint64(1024), size)
+
+	require.Equal(t, di.Encode(), NewFamilyDriverInfo(1024).Encode())
+
+	_, err = di.MultiDriverInfo()
+	require.Error(t, err)
+}
+
+func TestReadDriverInfo_Split(t *testing.T) {
+	di := readTestDriverInfo(t, "../../testdata/hdf5_official/tsplit_file-m.h5")
+	info, err := di.MultiDriverInfo()
+	require.NoError(t, err)
+
+	require.Equal(t, [memNTypes]uint8{0, MemSuper, MemSuper, MemDraw, MemSuper, MemSuper, MemSuper}, info.Map)
+	require.Equal(t, []MultiMember{
+		{Type: MemSuper, Address: 0, EOA: 2048, Name: "%s-m.h5"},
+		{Type: MemDraw, Address: 0x7FFFFFFFFFFFFFFF, EOA: 2048, Name: "%s-r.h5"},
+	}, info.Members)
+
+	// Encoding gives back the block.
+	require.Equal(t, di.Encode(), NewMultiDriverInfo(info).Encode())
+}
+
+func TestReadDriverInfo_Multi(t *testing.T) {
+	di := readTestDriverInfo(t, "../../testdata/hdf5_official/tmulti-s.h5")
+	info, err := di.MultiDriverInfo()
+	require.NoError(t, err)
+	require.Len(t, info.Members, 6)
+	require.Equal(t, "%s-s.h5", info.Members[0].Name)
+	require.Equal(t, uint64(0), info.Members[0].Address)
+	for i := 1; i < len(info.Members); i++ {
+		require.Greater(t, info.Members[i].Address, info.Members[i-1].Address)
+	}
+}
+
+func TestReadDriverInfo_None(t *testing.T) {
+	for _, addr := range []uint64{0, ^uint64(0)} {
+		di, err := ReadDriverInfo(bytes.NewReader(nil), &Superblock{DriverInfo: addr})
+		require.NoError(t, err)
+		require.Nil(t, di)
+	}
+
+	// Superblocks version 2 keep driver information in the extension.
+	di, err := ReadDriverInfo(bytes.NewReader(nil), &Superblock{Version: Version2, DriverInfo: 96})
+	require.NoError(t, err)
+	require.Nil(t, di)
+
+	_, err = (&DriverInfo{Driver: DriverMulti, Data: []byte{1, 1, 3, 1, 1, 1, 0, 0, 0}}).MultiDriverInfo()
+	require.Error(t, err)
+}
@@ -0,0 +1,366 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+)
+
+// Extensible array signatures.
+const (
+	extensibleArrayHeaderSignature     = "EAHD"
+	extensibleArrayIndexBlockSignature = "EAIB"
+	extensibleArraySuperBlockSignature = "EASB"
+	extensibleArrayDataBlockSignature  = "EADB"
+)
+
+// extensibleArrayHeader is the header of an extensible array, the chunk index
+// used for datasets with a single unlimited dimension.
+// Reference: H5EAcache.c - H5EA__cache_hdr_deserialize.
+type extensibleArrayHeader struct {
+	clientID          uint8  // 0 = unfiltered chunks, 1 = filtered chunks.
+	elementSize       uint8  // Size of one encoded element.
+	maxElementsBits   uint8  // log2 of the maximum number of elements.
+	indexElements     uint8  // Elements stored directly in the index block.
+	dataBlockMinElems uint8  // Minimum elements per data block.
+	superBlockMinPtrs uint8  // Minimum data block pointers per super block.
+	pageBits          uint8  // log2 of elements per data block page.
+	maxIndexSet       uint64 // One more than the highest element index set.
+	indexBlockAddress uint64
+
+	superBlocks []extensibleArraySuperBlockInfo
+}
+
+// extensibleArraySuperBlockInfo describes the data blocks of one super block
+// (H5EA_sblk_info_t).
+type extensibleArraySuperBlockInfo struct {
+	numDataBlocks  uint64 // Data blocks in the super block.
+	dataBlockElems uint64 // Elements per data block.
+	startIndex     uint64 // Index of the first element, after the index block elements.
+	startDataBlock uint64 // Index of the first data block.
+}
+
+// readExtensibleArrayHeader reads an extensible array header: signature
+// "EAHD", version, client ID, the six creation parameters, six length-sized
+// statistics, the index block address and checksum.
+func readExtensibleArrayHeader(r io.ReaderAt, address uint64, sb *Superblock) (*extensibleArrayHeader, error) {
+	lengthSize := int(sb.LengthSize)
+	size := 4 + 1 + 1 + 6 + 6*lengthSize + int(sb.OffsetSize) + 4
+	buf := make([]byte, size)
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
+	if _, err := r.ReadAt(buf, int64(address)); err != nil {
+		return nil, fmt.Errorf("failed to read extensible array header at 0x%X: %w", address, err)
+	}
+	if string(buf[0:4]) != extensibleArrayHeaderSignature {
+		return nil, fmt.Errorf("invalid extensible array header signature at 0x%X: %q", address, buf[0:4])
+	}
+	if buf[4] != 0 {
+		return nil, fmt.Errorf("unsupported extensible array version: %d", buf[4])
+	}
+	if err := verifyChecksum(buf, address, "extensible array header"); err != nil {
+		return nil, err
+	}
+
+	h := &extensibleArrayHeader{
+		clientID:          buf[5],
+		elementSize:       buf[6],
+		maxElementsBits:   buf[7],
+		indexElements:     buf[8],
+		dataBlockMinElems: buf[9],
+		superBlockMinPtrs: buf[10],
+		pageBits:          buf[11],
+	}
+	// Skip the super block and data block counts and sizes; only the
+	// highest index set is needed to bound iteration.
+	offset := 12 + 4*lengthSize
+	h.maxIndexSet = readUint64(buf[offset:], lengthSize, sb.Endianness)
+	offset += 2 * lengthSize
+	h.indexBlockAddress = readUint64(buf[offset:], int(sb.OffsetSize), sb.Endianness)
+
+	if err := h.init(); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// init validates the creation parameters and computes the super block table
+// (H5EA__hdr_init).
+func (h *extensibleArrayHeader) init() error {
+	if h.elementSize == 0 {
+		return errors.New("invalid extensible array element size: 0")
+	}
+	if h.maxElementsBits == 0 || h.maxElementsBits > 64 || h.pageBits >= 64 {
+		return fmt.Errorf("invalid extensible array parameters: max bits %d, page bits %d", h.maxElementsBits, h.pageBits)
+	}
+	if !isPowerOfTwo(uint64(h.dataBlockMinElems)) || !isPowerOfTwo(uint64(h.superBlockMinPtrs)) {
+		return fmt.Errorf("invalid extensible array parameters: data block minimum %d, super block minimum %d",
+			h.dataBlockMinElems, h.superBlockMinPtrs)
+	}
+	minElemsBits := log2(uint64(h.dataBlockMinElems))
+	if minElemsBits > int(h.maxElementsBits) {
+		return fmt.Errorf("invalid extensible array parameters: data block minimum %d exceeds maximum", h.dataBlockMinElems)
+	}
+
+	numSuperBlocks := 1 + int(h.maxElementsBits) - minElemsBits
+	h.superBlocks = make([]extensibleArraySuperBlockInfo, numSuperBlocks)
+	var startIndex, startDataBlock uint64
+	for u := range h.superBlocks {
+		info := extensibleArraySuperBlockInfo{
+			numDataBlocks:  uint64(1) << (u / 2),
+			dataBlockElems: (uint64(1) << ((u + 1) / 2)) * uint64(h.dataBlockMinElems),
+			startIndex:     startIndex,
+			startDataBlock: startDataBlock,
+		}
+		h.superBlocks[u] = info
+		startIndex += info.numDataBlocks * info.dataBlockElems
+		startDataBlock += info.numDataBlocks
+	}
+	return nil
+}
+
+// blockOffsetSize returns the size of the block offset field of super and
+// data blocks.
+func (h *extensibleArrayHeader) blockOffsetSize() uint64 {
+	return (uint64(h.maxElementsBits) + 7) / 8
+}
+
+// forEach visits every element of the array below the highest index set.
+// Elements of unallocated blocks and uninitialized pages are skipped.
+//
+// Index block format (H5EAcache.c - H5EA__cache_iblock_deserialize):
+// signature "EAIB", version, client ID, header address, the index block
+// elements, the addresses of the data blocks of the first super blocks, the
+// addresses of the remaining super blocks and a checksum.
+func (h *extensibleArrayHeader) forEach(r io.ReaderAt, sb *Superblock, fn func(index uint64, element []byte) error) error {
+	if h.maxIndexSet == 0 || isUndefinedAddress(h.indexBlockAddress, sb.OffsetSize) {
+		return nil
+	}
+
+	offsetSize := uint64(sb.OffsetSize)
+	elementSize := uint64(h.elementSize)
+	indexSuperBlocks := 2 * log2(uint64(h.superBlockMinPtrs))
+	if indexSuperBlocks > len(h.superBlocks) {
+		indexSuperBlocks = len(h.superBlocks)
+	}
+	numDataBlockAddrs := 2 * (uint64(h.superBlockMinPtrs) - 1)
+	numSuperBlockAddrs := uint64(len(h.superBlocks) - indexSuperBlocks)
+
+	prefixSize := uint64(4+1+1) + offsetSize
+	size := prefixSize + uint64(h.indexElements)*elementSize + (numDataBlockAddrs+numSuperBlockAddrs)*offsetSize + 4
+	block, err := readArrayBlock(r, h.indexBlockAddress, size, extensibleArrayIndexBlockSignature, "extensible array index block")
+	if err != nil {
+		return err
+	}
+
+	p := prefixSize
+	for i := uint64(0); i < uint64(h.indexElements) && i < h.maxIndexSet; i++ {
+		if err := fn(i, block[p+i*elementSize:p+(i+1)*elementSize]); err != nil {
+			return err
+		}
+	}
+	p += uint64(h.indexElements) * elementSize
+	dataBlockAddrs := block[p : p+numDataBlockAddrs*offsetSize]
+	p += numDataBlockAddrs * offsetSize
+	superBlockAddrs := block[p : p+numSuperBlockAddrs*offsetSize]
+
+	for u, info := range h.superBlocks {
+		base := uint64(h.indexElements) + info.startIndex
+		if base >= h.maxIndexSet {
+			break
+		}
+
+		if u < indexSuperBlocks {
+			for j := uint64(0); j < info.numDataBlocks; j++ {
+				k := (info.startDataBlock + j) * offsetSize
+				addr := readUint64(dataBlockAddrs[k:], int(offsetSize), sb.Endianness)
+				if err := h.forEachInDataBlock(r, sb, addr, info, base+j*info.dataBlockElems, nil, fn); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		k := uint64(u-indexSuperBlocks) * offsetSize
+		addr := readUint64(superBlockAddrs[k:], int(offsetSize), sb.Endianness)
+		if err := h.forEachInSuperBlock(r, sb, addr, info, base, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// pagesPerDataBlock returns the number of pages of the data blocks described
+// by info, or zero if they are not paged.
+func (h *extensibleArrayHeader) pagesPerDataBlock(info extensibleArraySuperBlockInfo) uint64 {
+	pageElements := uint64(1) << h.pageBits
+	if info.dataBlockElems <= pageElements {
+		return 0
+	}
+	return info.dataBlockElems / pageElements
+}
+
+// forEachInSuperBlock visits the elements of the data blocks of a super block.
+//
+// Super block format (H5EAcache.c - H5EA__cache_sblock_deserialize):
+// signature "EASB", version, client ID, header address, block offset, the
+// page-initialized bitmaps of paged data blocks, the data block addresses
+// and a checksum.
+func (h *extensibleArrayHeader) forEachInSuperBlock(r io.ReaderAt, sb *Superblock, address uint64, info extensibleArraySuperBlockInfo, base uint64, fn func(uint64, []byte) error) error {
+	if isUndefinedAddress(address, sb.OffsetSize) {
+		return nil
+	}
+
+	offsetSize := uint64(sb.OffsetSize)
+	var bitmapSize uint64
+	if pages := h.pagesPerDataBlock(info); pages > 0 {
+		bitmapSize = (pages + 7) / 8
+	}
+
+	prefixSize := uint64(4+1+1) + offsetSize + h.blockOffsetSize()
+	size := prefixSize + info.numDataBlocks*(bitmapSize+offsetSize) + 4
+	block, err := readArrayBlock(r, address, size, extensibleArraySuperBlockSignature, "extensible array super block")
+	if err != nil {
+		return err
+	}
+
+	bitmaps := block[prefixSize : prefixSize+info.numDataBlocks*bitmapSize]
+	addrs := block[prefixSize+info.numDataBlocks*bitmapSize:]
+	for j := uint64(0); j < info.numDataBlocks; j++ {
+		start := base + j*info.dataBlockElems
+		if start >= h.maxIndexSet {
+			break
+		}
+		var bitmap []byte
+		if bitmapSize > 0 {
+			bitmap = bitmaps[j*bitmapSize : (j+1)*bitmapSize]
+		}
+		addr := readUint64(addrs[j*offsetSize:], int(offsetSize), sb.Endianness)
+		if err := h.forEachInDataBlock(r, sb, addr, info, start, bitmap, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// forEachInDataBlock visits the elements of a data block whose first element
+// has index base. For paged data blocks, pageInit selects the pages to read;
+// nil means all pages.
+//
+// Data block format (H5EAcache.c - H5EA__cache_dblock_deserialize):
+// signature "EADB", version, client ID, header address, block offset, the
+// elements unless the block is paged, and a checksum. Pages follow the
+// block, each holding its elements and a checksum.
+func (h *extensibleArrayHeader) forEachInDataBlock(r io.ReaderAt, sb *Superblock, address uint64, info extensibleArraySuperBlockInfo, base uint64, pageInit []byte, fn func(uint64, []byte) error) error {
+	if isUndefinedAddress(address, sb.OffsetSize) {
+		return nil
+	}
+
+	elementSize := uint64(h.elementSize)
+	numPages := h.pagesPerDataBlock(info)
+	prefixSize := uint64(4+1+1) + uint64(sb.OffsetSize) + h.blockOffsetSize()
+
+	if numPages == 0 {
+		size := prefixSize + info.dataBlockElems*elementSize + 4
+		block, err := readArrayBlock(r, address, size, extensibleArrayDataBlockSignature, "extensible array data block")
+		if err != nil {
+			return err
+		}
+		elements := block[prefixSize:]
+		for i := uint64(0); i < info.dataBlockElems && base+i < h.maxIndexSet; i++ {
+			if err := fn(base+i, elements[i*elementSize:(i+1)*elementSize]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if _, err := readArrayBlock(r, address, prefixSize+4, extensibleArrayDataBlockSignature, "extensible array data block"); err != nil {
+		return err
+	}
+	pageElements := uint64(1) << h.pageBits
+	pageSize := pageElements*elementSize + 4
+	for page := uint64(0); page < numPages; page++ {
+		start := base + page*pageElements
+		if start >= h.maxIndexSet {
+			break
+		}
+		if pageInit != nil && !bitIsSet(pageInit, page) {
+			continue
+		}
+		data, err := readArrayPage(r, address+prefixSize+4+page*pageSize, pageSize)
+		if err != nil {
+			return err
+		}
+		for i := uint64(0); i < pageElements && start+i < h.maxIndexSet; i++ {
+			if err := fn(start+i, data[i*elementSize:(i+1)*elementSize]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// isPowerOfTwo reports whether v is a non-zero power of two.
+func isPowerOfTwo(v uint64) bool {
+	return v != 0 && v&(v-1) == 0
+}
+
+// log2 returns the base 2 logarithm of a power of two (H5VM_log2_of2).
+func log2(v uint64) int {
+	return bits.TrailingZeros64(v)
+}
+
+// readExtensibleArrayChunks reads an extensible array chunk index. Element
+// indexes are linear chunk indexes over the maximum dimensions with the
+// unlimited dimension moved to the front, so that growing the dataset only
+// appends elements.
+// Reference: H5Dearray.c - H5D__earray_idx_get_addr.
+func readExtensibleArrayChunks(r io.ReaderAt, address uint64, grid *chunkGrid, sb *Superblock) ([]ChunkEntry, error) {
+	if grid.unlimitedDim < 0 {
+		return nil, errors.New("extensible array index requires an unlimited dimension")
+	}
+	h, err := readExtensibleArrayHeader(r, address, sb)
+	if err != nil {
+		return nil, err
+	}
+	filtered, err := arrayElementFiltered(h.clientID, h.elementSize, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	// Swizzle the unlimited dimension to the front (H5VM_swizzle_coords).
+	ndims := len(grid.dims)
+	unlim := grid.unlimitedDim
+	swizzled := make([]uint64, 0, ndims)
+	swizzled = append(swizzled, grid.maxChunks[unlim])
+	swizzled = append(swizzled, grid.maxChunks[:unlim]...)
+	swizzled = append(swizzled, grid.maxChunks[unlim+1:]...)
+	down := downChunks(swizzled)
+
+	var chunks []ChunkEntry
+	err = h.forEach(r, sb, func(index uint64, element []byte) error {
+		addr, nbytes, filterMask, ok := arrayChunkElement(element, filtered, grid, sb)
+		if !ok {
+			return nil
+		}
+		coords := scaledFromIndex(index, down)
+		scaled := make([]uint64, ndims+1)
+		scaled[unlim] = coords[0]
+		copy(scaled[:unlim], coords[1:unlim+1])
+		copy(scaled[unlim+1:ndims], coords[unlim+1:ndims])
+		if !grid.inExtent(scaled) {
+			return nil
+		}
+		entry, err := newChunkEntry(scaled, addr, nbytes, filterMask)
+		if err != nil {
+			return err
+		}
+		chunks = append(chunks, entry)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return chunks, nil
+}
@@ -0,0 +1,143 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFillValueMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want *FillValueMessage
+	}{
+		{
+			name: "v1 with value",
+			data: []byte{1, 2, 2, 1, 4, 0, 0, 0, 0x63, 0, 0, 0},
+			want: &FillValueMessage{Version: 1, AllocTime: AllocTimeLate, FillTime: FillTimeIfSet, Defined: true, Value: []byte{0x63, 0, 0, 0}},
+		},
+		{
+			name: "v1 size always present",
+			data: []byte{1, 1, 0, 0, 0, 0, 0, 0},
+			want: &FillValueMessage{Version: 1, AllocTime: AllocTimeEarly, FillTime: FillTimeAlloc},
+		},
+		{
+			name: "v2 defined",
+			data: []byte{2, 3, 1, 1, 2, 0, 0, 0, 0xFF, 0x7F},
+			want: &FillValueMessage{Version: 2, AllocTime: AllocTimeIncremental, FillTime: FillTimeNever, Defined: true, Value: []byte{0xFF, 0x7F}},
+		},
+		{
+			name: "v2 default value",
+			data: []byte{2, 2, 2, 1, 0, 0, 0, 0},
+			want: &FillValueMessage{Version: 2, AllocTime: AllocTimeLate, FillTime: FillTimeIfSet, Defined: true},
+		},
+		{
+			name: "v2 undefined",
+			data: []byte{2, 2, 2, 0},
+			want: &FillValueMessage{Version: 2, AllocTime: AllocTimeLate, FillTime: FillTimeIfSet},
+		},
+		{
+			name: "v3 with value",
+			data: []byte{3, 0x01 | 0x02<<2 | 0x20, 1, 0, 0, 0, 0x2A},
+			want: &FillValueMessage{Version: 3, AllocTime: AllocTimeEarly, FillTime: FillTimeIfSet, Defined: true, Value: []byte{0x2A}},
+		},
+		{
+			name: "v3 default value",
+			data: []byte{3, 0x03},
+			want: &FillValueMessage{Version: 3, AllocTime: AllocTimeIncremental, FillTime: FillTimeAlloc, Defined: true},
+		},
+		{
+			name: "v3 undefined",
+			data: []byte{3, 0x02 | 0x10},
+			want: &FillValueMessage{Version: 3, AllocTime: AllocTimeLate, FillTime: FillTimeAlloc},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseFillValueMessage(tt.data)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseFillValueMessage_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"empty", []byte{}, "too short"},
+		{"v2 truncated", []byte{2, 2, 2}, "too short"},
+		{"bad version", []byte{4, 0}, "unsupported fill value message version"},
+		{"reserved flags", []byte{3, 0x40}, "invalid fill value message flags"},
+		{"undefined and set", []byte{3, 0x30, 0, 0, 0, 0}, "both undefined and set"},
+		{"size truncated", []byte{3, 0x20, 1, 0}, "size truncated"},
+		{"size exceeds message", []byte{1, 2, 2, 1, 8, 0, 0, 0, 1, 2}, "exceeds message size"},
+		{"bad fill time", []byte{2, 2, 3, 0}, "invalid fill value write time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := ParseFillValueMessage(tt.data)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestParseFillValueOldMessage(t *testing.T) {
+	msg, err := ParseFillValueOldMessage([]byte{2, 0, 0, 0, 0x5C, 0x11})
+	require.NoError(t, err)
+	require.Equal(t, &FillValueMessage{FillTime: FillTimeIfSet, Defined: true, Value: []byte{0x5C, 0x11}}, msg)
+
+	_, err = ParseFillValueOldMessage([]byte{0x00, 0x00, 0x80, 0x00})
+	require.ErrorContains(t, err, "exceeds message size")
+}
+
+func TestEncodeFillValueMessage(t *testing.T) {
+	for _, msg := range []*FillValueMessage{
+		{Version: 3, AllocTime: AllocTimeLate, FillTime: FillTimeIfSet, Defined: true, Value: []byte{1, 2, 3, 4}},
+		{Version: 3, AllocTime: AllocTimeIncremental, FillTime: FillTimeNever, Defined: true},
+		{Version: 3, AllocTime: AllocTimeEarly, FillTime: FillTimeAlloc},
+	} {
+		data, err := EncodeFillValueMessage(msg)
+		require.NoError(t, err)
+
+		got, err := ParseFillValueMessage(data)
+		require.NoError(t, err)
+		require.Equal(t, msg, got)
+	}
+
+	data, err := EncodeFillValueMessage(&FillValueMessage{AllocTime: AllocTimeLate, FillTime: FillTimeIfSet, Defined: true, Value: []byte{7}})
+	require.NoError(t, err)
+	require.Equal(t, []byte{3, 0x02 | 0x02<<2 | 0x20, 1, 0, 0, 0, 7}, data)
+
+	_, err = EncodeFillValueMessage(&FillValueMessage{Value: []byte{1}})
+	require.ErrorContains(t, err, "undefined fill value")
+	_, err = EncodeFillValueMessage(&FillValueMessage{FillTime: 3})
+	require.ErrorContains(t, err, "invalid fill time")
+}
+
+func TestFillValuePattern(t *testing.T) {
+	var missing *FillValueMessage
+	require.Nil(t, missing.Pattern(4))
+
+	msg := &FillValueMessage{FillTime: FillTimeIfSet, Defined: true, Value: []byte{1, 0}}
+	require.Equal(t, []byte{1, 0}, msg.Pattern(2))
+	require.Nil(t, msg.Pattern(4), "size mismatch")
+
+	msg.FillTime = FillTimeNever
+	require.Nil(t, msg.Pattern(2))
+
+	msg = &FillValueMessage{FillTime: FillTimeAlloc, Defined: true, Value: []byte{0, 0}}
+	require.Nil(t, msg.Pattern(2), "zero value")
+
+	buf := make([]byte, 7)
+	FillBuffer(buf, []byte{1, 2, 3})
+	require.Equal(t, []byte{1, 2, 3, 1, 2, 3, 1}, buf)
+
+	FillBuffer(buf, nil)
+	require.Equal(t, []byte{1, 2, 3, 1, 2, 3, 1}, buf)
+}
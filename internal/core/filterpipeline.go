@@ -1,7 +1,9 @@
 package core
 
 import (
+	"bytes"
 	"compress/bzip2"
+	"compress/gzip"
 	"compress/zlib"
 	"encoding/binary"
 	"errors"
@@ -63,6 +65,16 @@ func ParseFilterPipelineMessage(data []byte) (*FilterPipelineMessage, error) {
 
 	offset := 2
 
+	// Earlier versions of this library wrote version 2 messages in the
+	// version 1 layout, without the null terminator of filter names and
+	// the padding of client data. Version 2 has no reserved bytes and no
+	// filter with ID 0, so these messages start with six zero bytes.
+	legacy := version == 2 && numFilters > 0 && len(data) >= 8 &&
+		binary.LittleEndian.Uint32(data[2:6]) == 0 && binary.LittleEndian.Uint16(data[6:8]) == 0
+	if legacy {
+		version = 1
+	}
+
 	// Version 1 has 6 bytes reserved after num filters.
 	if version == 1 {
 		offset += 6
@@ -137,7 +149,7 @@ func ParseFilterPipelineMessage(data []byte) (*FilterPipelineMessage, error) {
 			}
 
 			// Version 1: client data is padded to 8-byte boundary.
-			if version == 1 {
+			if version == 1 && !legacy {
 				if dataSize%8 != 0 {
 					offset += 8 - (dataSize % 8)
 				}
@@ -152,6 +164,13 @@ func ParseFilterPipelineMessage(data []byte) (*FilterPipelineMessage, error) {
 
 // ApplyFilters applies filter pipeline to decompress/decode chunk data.
 func (fp *FilterPipelineMessage) ApplyFilters(data []byte) ([]byte, error) {
+	return fp.ApplyFiltersWithMask(data, 0)
+}
+
+// ApplyFiltersWithMask is like ApplyFilters but skips the filters whose bit
+// is set in filterMask, as recorded in the chunk index for chunks stored
+// without some or all filters of the pipeline.
+func (fp *FilterPipelineMessage) ApplyFiltersWithMask(data []byte, filterMask uint32) ([]byte, error) {
 	if fp == nil || len(fp.Filters) == 0 {
 		return data, nil
 	}
@@ -164,6 +183,10 @@ func (fp *FilterPipelineMessage) ApplyFilters(data []byte) ([]byte, error) {
 	for i := len(fp.Filters) - 1; i >= 0; i-- {
 		filter := fp.Filters[i]
 
+		if i < 32 && filterMask&(1<<uint(i)) != 0 {
+			continue
+		}
+
 		// Skip optional filters if they fail.
 		isOptional := (filter.Flags & 0x0001) != 0
 
@@ -234,6 +257,12 @@ func applyFilter(filter Filter, data []byte) ([]byte, error) {
 // applyDeflate decompresses GZIP/deflate compressed data.
 // HDF5 uses raw deflate (zlib), not gzip format.
 func applyDeflate(data []byte) ([]byte, error) {
+	// Earlier versions of this library wrote gzip streams, which start
+	// with a magic number that is not a valid zlib header.
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		return applyGzip(data)
+	}
+
 	reader, err := zlib.NewReader(io.NopCloser(io.NewSectionReader(
 		&bytesReaderAt{data}, 0, int64(len(data)))))
 	if err != nil {
@@ -250,6 +279,22 @@ func applyDeflate(data []byte) ([]byte, error) {
 	return decompressed, nil
 }
 
+// applyGzip decompresses deflate data stored in the gzip format.
+func applyGzip(data []byte) ([]byte, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("gzip reader creation failed: %w", err)
+	}
+	defer func() { _ = reader.Close() }()
+
+	decompressed, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("gzip decompression failed: %w", err)
+	}
+
+	return decompressed, nil
+}
+
 // applyShuffle reverses shuffle filter.
 // Shuffle reorders bytes to improve compression.
 func applyShuffle(data []byte, clientData []uint32) ([]byte, error) {
@@ -443,6 +488,11 @@ func filterName(id FilterID) string {
 	}
 }
 
+// String returns the filter's common name.
+func (id FilterID) String() string {
+	return filterName(id)
+}
+
 // bytesReaderAt wraps []byte to implement io.ReaderAt.
 type bytesReaderAt struct {
 	data []byte
@@ -215,3 +215,36 @@ func TestParseFilterPipelineMessage_Version1WithName(t *testing.T) {
 	require.Equal(t, uint16(10), filter.NameLength)
 	require.Equal(t, []uint32{999}, filter.ClientData)
 }
+
+func TestParseFilterPipelineMessage_LegacyVersion2(t *testing.T) {
+	// Message as written by earlier versions of this library: version 2
+	// with the version 1 layout, names without null terminator and
+	// unpadded client data.
+	data := make([]byte, 8+2*(8+8+4))
+	data[0] = 2 // version 2
+	data[1] = 2 // 2 filters
+	offset := 8
+	for _, f := range []struct {
+		id   FilterID
+		name string
+		cd   uint32
+	}{{FilterShuffle, "shuffle", 4}, {FilterDeflate, "deflate", 6}} {
+		binary.LittleEndian.PutUint16(data[offset:], uint16(f.id))
+		binary.LittleEndian.PutUint16(data[offset+2:], uint16(len(f.name)))
+		binary.LittleEndian.PutUint16(data[offset+6:], 1) // 1 client data
+		copy(data[offset+8:], f.name)
+		binary.LittleEndian.PutUint32(data[offset+16:], f.cd)
+		offset += 8 + 8 + 4
+	}
+
+	got, err := ParseFilterPipelineMessage(data)
+	require.NoError(t, err)
+	require.Equal(t, uint8(2), got.Version)
+	require.Len(t, got.Filters, 2)
+	require.Equal(t, FilterShuffle, got.Filters[0].ID)
+	require.Equal(t, "shuffle", got.Filters[0].Name)
+	require.Equal(t, []uint32{4}, got.Filters[0].ClientData)
+	require.Equal(t, FilterDeflate, got.Filters[1].ID)
+	require.Equal(t, "deflate", got.Filters[1].Name)
+	require.Equal(t, []uint32{6}, got.Filters[1].ClientData)
+}
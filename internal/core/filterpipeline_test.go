@@ -2,6 +2,7 @@ package core
 
 import (
 	"bytes"
+	"compress/gzip"
 	"compress/zlib"
 	"testing"
 
@@ -34,6 +35,12 @@ func TestApplyDeflate(t *testing.T) {
 			want:    bytes.Repeat([]byte("test"), 1000),
 			wantErr: false,
 		},
+		{
+			name:    "gzip data written by earlier versions",
+			input:   gzipCompress(t, []byte("hello world")),
+			want:    []byte("hello world"),
+			wantErr: false,
+		},
 		{
 			name:    "invalid compressed data",
 			input:   []byte{0x00, 0x01, 0x02, 0x03},
@@ -329,6 +336,32 @@ func TestFilterPipelineApplyFilters(t *testing.T) {
 	}
 }
 
+// TestFilterPipelineApplyFiltersWithMask tests skipping masked filters.
+func TestFilterPipelineApplyFiltersWithMask(t *testing.T) {
+	pipeline := &FilterPipelineMessage{
+		Filters: []Filter{
+			{ID: FilterShuffle, ClientData: []uint32{2}},
+			{ID: FilterDeflate},
+		},
+	}
+	raw := []byte{0x01, 0x02, 0xAA, 0xBB}
+
+	// Deflate (bit 1) skipped: only unshuffle.
+	got, err := pipeline.ApplyFiltersWithMask(raw, 0x2)
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x01, 0xAA, 0x02, 0xBB}, got)
+
+	// Shuffle (bit 0) skipped: only inflate.
+	got, err = pipeline.ApplyFiltersWithMask(zlibCompress(t, raw), 0x1)
+	require.NoError(t, err)
+	require.Equal(t, raw, got)
+
+	// All filters skipped, as for unfiltered partial edge chunks.
+	got, err = pipeline.ApplyFiltersWithMask(raw, 0xFFFFFFFF)
+	require.NoError(t, err)
+	require.Equal(t, raw, got)
+}
+
 // TestApplySZIP tests SZIP decompression error handling.
 func TestApplySZIP(t *testing.T) {
 	tests := []struct {
@@ -381,3 +414,14 @@ func zlibCompress(t *testing.T, data []byte) []byte {
 	require.NoError(t, err)
 	return buf.Bytes()
 }
+
+func gzipCompress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err := w.Write(data)
+	require.NoError(t, err)
+	err = w.Close()
+	require.NoError(t, err)
+	return buf.Bytes()
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFileSpaceInfoMessage(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+	undef := ^uint64(0)
+
+	t.Run("persisting free space", func(t *testing.T) {
+		// Message from fsm_aggr_persist.h5 (HDF5 1.10.0 development layout).
+		data := make([]byte, 125)
+		data[2] = 1                                         // Persist
+		binary.LittleEndian.PutUint64(data[3:], 1)          // Threshold
+		binary.LittleEndian.PutUint64(data[11:], 4096)      // Page size
+		binary.LittleEndian.PutUint16(data[19:], 10)        // Page end threshold
+		binary.LittleEndian.PutUint64(data[21:], 0x990)     // EOA before FSM
+		binary.LittleEndian.PutUint64(data[29:], 0x990)     // Superblock manager
+		for offset := 37; offset < len(data); offset += 8 { // Other managers
+			binary.LittleEndian.PutUint64(data[offset:], undef)
+		}
+
+		msg, err := ParseFileSpaceInfoMessage(data, sb)
+		require.NoError(t, err)
+		require.Equal(t, FileSpaceStrategyFSMAggr, msg.Strategy)
+		require.True(t, msg.Persist)
+		require.Equal(t, uint64(1), msg.Threshold)
+		require.Equal(t, uint64(4096), msg.PageSize)
+		require.Equal(t, uint16(10), msg.PageEndMetaThreshold)
+		require.Equal(t, uint64(0x990), msg.EOAPreFSMAlloc)
+		require.Equal(t, uint64(0x990), msg.ManagerAddresses[0])
+		require.Equal(t, undef, msg.ManagerAddresses[1])
+
+		encoded, err := EncodeFileSpaceInfoMessage(msg, sb)
+		require.NoError(t, err)
+		data[0] = 1 // Written as version 1
+		require.Equal(t, data, encoded)
+	})
+
+	t.Run("round trip without persistence", func(t *testing.T) {
+		msg := NewFileSpaceInfoMessage(FileSpaceStrategyPage, false)
+		msg.PageSize = 8192
+
+		encoded, err := EncodeFileSpaceInfoMessage(msg, sb)
+		require.NoError(t, err)
+		require.Len(t, encoded, FileSpaceInfoMessageSize(false, sb))
+		require.Len(t, encoded, 29)
+
+		parsed, err := ParseFileSpaceInfoMessage(encoded, sb)
+		require.NoError(t, err)
+		require.Equal(t, msg, parsed)
+	})
+
+	t.Run("version 0", func(t *testing.T) {
+		data := make([]byte, 2+8+6*8)
+		data[1] = fileSpaceV0AllPersist
+		binary.LittleEndian.PutUint64(data[2:], 1)
+		binary.LittleEndian.PutUint64(data[10:], 0x800)
+		for offset := 18; offset < len(data); offset += 8 {
+			binary.LittleEndian.PutUint64(data[offset:], undef)
+		}
+
+		msg, err := ParseFileSpaceInfoMessage(data, sb)
+		require.NoError(t, err)
+		require.Equal(t, FileSpaceStrategyFSMAggr, msg.Strategy)
+		require.True(t, msg.Persist)
+		require.Equal(t, uint64(0x800), msg.ManagerAddresses[0])
+		require.Equal(t, undef, msg.ManagerAddresses[11])
+
+		msg, err = ParseFileSpaceInfoMessage([]byte{0, fileSpaceV0VFD, 1, 0, 0, 0, 0, 0, 0, 0}, sb)
+		require.NoError(t, err)
+		require.Equal(t, FileSpaceStrategyNone, msg.Strategy)
+		require.False(t, msg.Persist)
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		_, err := ParseFileSpaceInfoMessage([]byte{2, 0}, sb)
+		require.ErrorContains(t, err, "unsupported file space info version")
+		_, err = ParseFileSpaceInfoMessage([]byte{1, 0, 1}, sb)
+		require.ErrorContains(t, err, "truncated")
+		_, err = ParseFileSpaceInfoMessage(make([]byte, 29), sb)
+		require.NoError(t, err)
+	})
+}
@@ -66,7 +66,7 @@ func (lim *LinkInfoMessage) HasNameBTree() bool {
 
 // HasCreationOrderBTree returns true if creation order B-tree address is set.
 func (lim *LinkInfoMessage) HasCreationOrderBTree() bool {
-	return lim.CreationOrderBTreeAddress != 0
+	return lim.CreationOrderBTreeAddress != 0 && lim.CreationOrderBTreeAddress != ^uint64(0)
 }
 
 // ParseLinkInfoMessage parses Link Info message from header message data.
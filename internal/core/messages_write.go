@@ -148,6 +148,26 @@ func encodeChunkedLayout(chunkDims []uint64, btreeAddress uint64, sb *Superblock
 	return buf, nil
 }
 
+// EncodeVirtualLayoutMessage encodes a virtual Data Layout message. Virtual
+// layouts exist only in version 4; the mapping list is the global heap
+// object heapIndex of the collection at heapAddress.
+//
+// Format (version 4, virtual):
+//   - Version: 1 byte (4)
+//   - Class: 1 byte (3 for virtual)
+//   - Global Heap Collection Address: offsetSize bytes
+//   - Global Heap Object Index: 4 bytes
+//
+// Reference: H5Olayout.c - H5O__layout_encode() for virtual case.
+func EncodeVirtualLayoutMessage(heapAddress uint64, heapIndex uint32, sb *Superblock) []byte {
+	buf := make([]byte, 2+int(sb.OffsetSize)+4)
+	buf[0] = 4
+	buf[1] = byte(LayoutVirtual)
+	writeUint64(buf[2:], heapAddress, int(sb.OffsetSize), sb.Endianness)
+	sb.Endianness.PutUint32(buf[2+int(sb.OffsetSize):], heapIndex)
+	return buf
+}
+
 // EncodeDatatypeMessage encodes a Datatype message.
 // Supports primitive types: int8-64, uint8-64, float32, float64, and fixed-length strings.
 //
@@ -202,6 +222,19 @@ func EncodeDatatypeMessage(dt *DatatypeMessage) ([]byte, error) {
 	}
 }
 
+// EncodeParsedDatatypeMessage encodes a datatype message as ParseDatatypeMessage
+// returned it: the header fields followed by the unchanged properties. Unlike
+// EncodeDatatypeMessage it writes every class and version, which makes it
+// suitable for copying datatypes read from a file.
+func EncodeParsedDatatypeMessage(dt *DatatypeMessage) []byte {
+	buf := make([]byte, 8+len(dt.Properties))
+	classAndVersion := uint32(dt.Class)&0x0F | uint32(dt.Version&0x0F)<<4 | (dt.ClassBitField&0x00FFFFFF)<<8
+	binary.LittleEndian.PutUint32(buf[0:4], classAndVersion)
+	binary.LittleEndian.PutUint32(buf[4:8], dt.Size)
+	copy(buf[8:], dt.Properties)
+	return buf
+}
+
 // encodeDatatypeNumeric encodes numeric datatypes (fixed-point and floating-point).
 func encodeDatatypeNumeric(dt *DatatypeMessage) ([]byte, error) {
 	// Version 1 for basic numeric types
@@ -466,6 +499,54 @@ func EncodeDataspaceMessage(dims, maxDims []uint64) ([]byte, error) {
 	return buf, nil
 }
 
+// EncodeFillValueMessage encodes a Fill Value message (type 5).
+//
+// Parameters:
+//   - fv: Fill value properties; Value holds one encoded element or is empty
+//     for the library default (all zero bytes)
+//
+// Returns:
+//   - Encoded message bytes
+//   - Error if encoding fails
+//
+// Format (version 3):
+//   - Version: 1 byte (3)
+//   - Flags: 1 byte (bits 0-1 allocation time, bits 2-3 fill time,
+//     bit 4 value undefined, bit 5 value present)
+//   - Size: 4 bytes (if value present)
+//   - Value: size bytes (if value present)
+//
+// Reference: HDF5 spec III.E (Fill Value Message)
+// C Reference: H5Ofill.c - H5O__fill_new_encode()..
+func EncodeFillValueMessage(fv *FillValueMessage) ([]byte, error) {
+	if fv.AllocTime > AllocTimeIncremental {
+		return nil, fmt.Errorf("invalid allocation time: %d", fv.AllocTime)
+	}
+	if fv.FillTime > FillTimeIfSet {
+		return nil, fmt.Errorf("invalid fill time: %d", fv.FillTime)
+	}
+	if !fv.Defined && len(fv.Value) > 0 {
+		return nil, errors.New("undefined fill value cannot have a value")
+	}
+
+	flags := uint8(fv.AllocTime) | uint8(fv.FillTime)<<fillFlagFillTimeShift
+	if !fv.Defined {
+		flags |= fillFlagUndefined
+	}
+	if len(fv.Value) == 0 {
+		return []byte{3, flags}, nil
+	}
+
+	flags |= fillFlagHaveValue
+	buf := make([]byte, 2+4+len(fv.Value))
+	buf[0] = 3
+	buf[1] = flags
+	binary.LittleEndian.PutUint32(buf[2:6], uint32(len(fv.Value))) //nolint:gosec // Safe: fill value is one element
+	copy(buf[6:], fv.Value)
+
+	return buf, nil
+}
+
 // EncodeSymbolTableMessage encodes a Symbol Table Message.
 // This message is used in group object headers to point to the symbol table structure.
 //
@@ -630,14 +711,20 @@ func writeUint64(buf []byte, value uint64, size int, endianness binary.ByteOrder
 	}
 }
 
-// encodeDatatypeReference encodes reference datatypes (object/region references).
+// encodeDatatypeReference encodes reference datatypes (object/region references,
+// or the revised references of HDF5 1.12+).
 // Reference types are fixed-size types with no additional properties.
 func encodeDatatypeReference(dt *DatatypeMessage) ([]byte, error) {
-	// Version 1 for reference types
+	// Version 1 for object and region references, version 4 for revised references
 	version := uint8(1)
 
 	// Validate size
-	if dt.Size != 8 && dt.Size != 12 {
+	if dt.IsRevisedReference() {
+		version = 4
+		if dt.Size != RefSize(4) && dt.Size != RefSize(8) {
+			return nil, fmt.Errorf("invalid revised reference datatype size: %d (must be %d or %d)", dt.Size, RefSize(4), RefSize(8))
+		}
+	} else if dt.Size != 8 && dt.Size != 12 {
 		return nil, fmt.Errorf("invalid reference datatype size: %d (must be 8 for object ref or 12 for region ref)", dt.Size)
 	}
 
@@ -646,7 +733,7 @@ func encodeDatatypeReference(dt *DatatypeMessage) ([]byte, error) {
 	buf := make([]byte, 8)
 
 	// Pack class, version, and class bit field into bytes 0-3
-	// ClassBitField encodes reference type: 0=object, 1=region
+	// ClassBitField encodes reference type: 0=object, 1=region, 2-4=revised
 	classAndVersion := uint32(dt.Class) | (uint32(version) << 4) | (dt.ClassBitField << 8)
 	binary.LittleEndian.PutUint32(buf[0:4], classAndVersion)
 
@@ -789,9 +876,8 @@ func EncodeArrayDatatypeMessage(baseType []byte, dims []uint64, arraySize uint32
 //   - Bytes 0-3: Class (4 bits) | Version (4 bits) | NumMembers (16 bits, in classBitField)
 //   - Bytes 4-7: Size (base type size)
 //   - Following: Base type message
-//   - Following: For each member:
-//   - Name (null-terminated, padded to multiple of 8)
-//   - Value (size bytes)
+//   - Following: Member names, each null-terminated (not padded in version 3)
+//   - Following: Member values, size bytes each, in the order of the names
 //
 // Reference: HDF5 spec III.C (Datatype Message - Enum class).
 // C Reference: H5Odtype.c - H5O__dtype_encode_helper() for H5T_ENUM.
@@ -805,67 +891,28 @@ func EncodeEnumDatatypeMessage(baseType []byte, names []string, values []byte, e
 	if len(baseType) == 0 {
 		return nil, fmt.Errorf("base type cannot be empty")
 	}
+	valuesSize := len(names) * int(enumSize)
+	if len(values) < valuesSize {
+		return nil, fmt.Errorf("not enough value bytes for %d members: have %d, need %d", len(names), len(values), valuesSize)
+	}
 
 	nmembs := uint16(len(names)) //nolint:gosec // Safe: validated above
 	version := uint8(3)
 
-	// Calculate total message size
-	headerSize := 8
-	baseTypeSize := len(baseType)
-
-	// Calculate names + values size
-	namesValuesSize := 0
-	for i, name := range names {
-		// Name: null-terminated, padded to multiple of 8
-		nameLen := len(name) + 1 // include null terminator
-		paddedNameLen := ((nameLen + 7) / 8) * 8
-		namesValuesSize += paddedNameLen
-
-		// Value: enumSize bytes
-		valueOffset := i * int(enumSize)
-		if valueOffset+int(enumSize) > len(values) {
-			return nil, fmt.Errorf("not enough value bytes for member %d", i)
-		}
-		namesValuesSize += int(enumSize)
-	}
-
-	messageSize := headerSize + baseTypeSize + namesValuesSize
-	buf := make([]byte, messageSize)
-	offset := 0
+	buf := make([]byte, 8, 8+len(baseType)+valuesSize+len(names)*8)
 
 	// Pack class, version, nmembs
 	// ClassBitField stores nmembs (lower 16 bits)
 	classAndVersion := uint32(DatatypeEnum) | (uint32(version) << 4) | (uint32(nmembs) << 8)
-	binary.LittleEndian.PutUint32(buf[offset:offset+4], classAndVersion)
-	offset += 4
-
-	// Size
-	binary.LittleEndian.PutUint32(buf[offset:offset+4], enumSize)
-	offset += 4
-
-	// Base type message
-	copy(buf[offset:], baseType)
-	offset += len(baseType)
-
-	// Names and values
-	for i, name := range names {
-		// Name (null-terminated, padded to multiple of 8)
-		nameLen := len(name) + 1
-		paddedNameLen := ((nameLen + 7) / 8) * 8
-
-		copy(buf[offset:], name)
-		offset += len(name)
-		buf[offset] = 0 // null terminator
-		offset++
-
-		// Padding (zeros)
-		offset += paddedNameLen - nameLen
+	binary.LittleEndian.PutUint32(buf[0:4], classAndVersion)
+	binary.LittleEndian.PutUint32(buf[4:8], enumSize)
 
-		// Value
-		valueOffset := i * int(enumSize)
-		copy(buf[offset:], values[valueOffset:valueOffset+int(enumSize)])
-		offset += int(enumSize)
+	buf = append(buf, baseType...)
+	for _, name := range names {
+		buf = append(buf, name...)
+		buf = append(buf, 0)
 	}
+	buf = append(buf, values[:valuesSize]...)
 
 	return buf, nil
 }
@@ -69,3 +69,17 @@ func TestEncodeEnumDatatypeMessage(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, data)
 }
+
+// TestEncodeParsedDatatypeMessage tests that parsed datatypes encode unchanged.
+func TestEncodeParsedDatatypeMessage(t *testing.T) {
+	base, err := EncodeDatatypeMessage(&DatatypeMessage{Class: DatatypeFixed, Version: 1, Size: 2, ClassBitField: 0x08})
+	require.NoError(t, err)
+	enum, err := EncodeEnumDatatypeMessage(base, []string{"OFF", "ON"}, []byte{0, 0, 1, 0}, 2)
+	require.NoError(t, err)
+
+	for _, data := range [][]byte{base, enum} {
+		dt, err := ParseDatatypeMessage(data)
+		require.NoError(t, err)
+		require.Equal(t, data, EncodeParsedDatatypeMessage(dt))
+	}
+}
@@ -38,8 +38,14 @@ type ObjectHeader struct {
 // HeaderMessage represents a single message within an object header.
 type HeaderMessage struct {
 	Type   MessageType
-	Offset uint64
+	Offset uint64 // Address of the message header
+	Flags  uint8  // Message flags (MsgFlag* bits)
 	Data   []byte
+
+	// For shared datatype messages, Committed holds the datatype message of
+	// the named datatype at CommittedAddress that Data refers to.
+	Committed        []byte
+	CommittedAddress uint64
 }
 
 // MessageType identifies the type of message in an object header.
@@ -51,8 +57,8 @@ const (
 	MsgDataspace      MessageType = 1
 	MsgLinkInfo       MessageType = 2
 	MsgDatatype       MessageType = 3
-	MsgFillValueOld   MessageType = 4
-	MsgFillValue      MessageType = 5  // Alias for FillValueOld
+	MsgFillValueOld   MessageType = 4  // Old-style fill value (size + value)
+	MsgFillValue      MessageType = 5  // Fill value with allocation and write times
 	MsgDataLayout     MessageType = 8  // Corrected: Data Layout is 0x0008
 	MsgFilterPipeline MessageType = 11 // Filter Pipeline (compression, etc)
 	MsgAttribute      MessageType = 12
@@ -61,17 +67,40 @@ const (
 	MsgContinuation   MessageType = 16 // Object header continuation (0x0010)
 	MsgSymbolTable    MessageType = 17
 	MsgLinkMessage    MessageType = 6
+	MsgExternalFiles  MessageType = 7  // External data files (contiguous storage outside the file)
 	MsgRefCount       MessageType = 22 // Reference Count (0x0016) - for hard links (v2 only)
+	MsgFileSpaceInfo  MessageType = 23 // File Space Info (0x0017) - superblock extension only
+)
+
+// Header message flags.
+const (
+	MsgFlagConstant      uint8 = 0x01 // Message data is constant
+	MsgFlagShared        uint8 = 0x02 // Message is stored in the shared message heap
+	MsgFlagDontShare     uint8 = 0x04 // Message must not be shared
+	MsgFlagFailIfUnknown uint8 = 0x08 // Fail to open for writing if the type is unknown
+	MsgFlagMarkIfUnknown uint8 = 0x10 // Set MsgFlagWasUnknown if a writer does not know the type
+	MsgFlagWasUnknown    uint8 = 0x20 // A writer that did not know the type modified the object
 )
 
 // ReadObjectHeader reads and parses an HDF5 object header from the specified address.
 // It supports both version 1 and version 2 object header formats.
 func ReadObjectHeader(r io.ReaderAt, address uint64, sb *Superblock) (*ObjectHeader, error) {
-	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
-	offset := int64(address)
-	if offset < 0 {
-		return nil, fmt.Errorf("negative offset: %d", offset)
+	header, err := readObjectHeader(r, address, sb)
+	if err != nil {
+		return nil, err
 	}
+	resolveCommittedDatatypes(r, header, sb)
+	return header, nil
+}
+
+// readObjectHeader reads an object header like ReadObjectHeader, without
+// resolving shared datatype messages.
+func readObjectHeader(r io.ReaderAt, address uint64, sb *Superblock) (*ObjectHeader, error) {
+	// Addresses past the range of int64 (object headers in members of the
+	// multi driver) become negative offsets, which only the reader of such
+	// files accepts.
+	//nolint:gosec // G115: HDF5 addresses are passed to io.ReaderAt unchanged
+	offset := int64(address)
 
 	prefix := utils.GetBuffer(8)
 	defer utils.ReleaseBuffer(prefix)
@@ -274,7 +303,6 @@ func parseV2Header(r io.ReaderAt, headerAddr uint64, flags uint8, _ *Superblock,
 			msgSize = binary.LittleEndian.Uint16(headerBuf[1:3])
 		}
 		msgFlags := headerBuf[3]
-		_ = msgFlags // Unused for now
 		// Creation index at headerBuf[4:6] if tracked - not currently used
 		utils.ReleaseBuffer(headerBuf)
 
@@ -297,6 +325,7 @@ func parseV2Header(r io.ReaderAt, headerAddr uint64, flags uint8, _ *Superblock,
 		messages = append(messages, &HeaderMessage{
 			Type:   msgType,
 			Offset: current,
+			Flags:  msgFlags,
 			Data:   data,
 		})
 
@@ -342,3 +371,55 @@ func (oh *ObjectHeader) DecrementReferenceCount() uint32 {
 func (oh *ObjectHeader) GetReferenceCount() uint32 {
 	return oh.ReferenceCount
 }
+
+// HeaderRegion is a contiguous region of the file holding part of an object
+// header.
+type HeaderRegion struct {
+	Address uint64
+	Size    uint64
+}
+
+// ObjectHeaderRegions returns the regions occupied by the object header oh,
+// read from address: the first chunk, prefix included, followed by the
+// continuation blocks its messages point to. These are the regions released
+// when the object is deleted (H5O__delete_oh).
+func ObjectHeaderRegions(r io.ReaderAt, address uint64, oh *ObjectHeader, sb *Superblock) ([]HeaderRegion, error) {
+	prefix := make([]byte, 16)
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
+	if _, err := r.ReadAt(prefix, int64(address)); err != nil {
+		return nil, utils.WrapError("object header read failed", err)
+	}
+
+	var size uint64
+	switch oh.Version {
+	case 1:
+		// 16-byte prefix followed by the message data.
+		size = 16 + uint64(sb.Endianness.Uint32(prefix[8:12]))
+	case 2:
+		// Signature, version and flags, optional fields, then the chunk
+		// size. Only the chunk itself is counted.
+		flags := prefix[5]
+		pos := 6
+		if flags&0x20 != 0 {
+			pos += 16
+		}
+		if flags&0x10 != 0 {
+			pos += 4
+		}
+		sizeBytes := 1 << (flags & 0x03)
+		field := make([]byte, 8)
+		//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
+		if _, err := r.ReadAt(field[:sizeBytes], int64(address)+int64(pos)); err != nil {
+			return nil, utils.WrapError("chunk size read failed", err)
+		}
+		size = uint64(pos+sizeBytes) + binary.LittleEndian.Uint64(field) //nolint:gosec // G115: small prefix size
+	default:
+		return nil, fmt.Errorf("unsupported object header version: %d", oh.Version)
+	}
+
+	regions := []HeaderRegion{{Address: address, Size: size}}
+	for _, cont := range findContinuations(oh.Messages, sb) {
+		regions = append(regions, HeaderRegion(cont))
+	}
+	return regions, nil
+}
@@ -217,7 +217,7 @@ func parseV1MessagesInBlock(r io.ReaderAt, start, end uint64, maxMessages uint16
 
 		msgType := MessageType(sb.Endianness.Uint16(msgHeaderBuf[0:2]))
 		msgSize := sb.Endianness.Uint16(msgHeaderBuf[2:4])
-		// msgFlags := msgHeaderBuf[4]  // Unused for now.
+		msgFlags := msgHeaderBuf[4]
 		utils.ReleaseBuffer(msgHeaderBuf)
 
 		if msgSize == 0 {
@@ -258,6 +258,7 @@ func parseV1MessagesInBlock(r io.ReaderAt, start, end uint64, maxMessages uint16
 		messages = append(messages, &HeaderMessage{
 			Type:   msgType,
 			Offset: current,
+			Flags:  msgFlags,
 			Data:   data,
 		})
 
@@ -19,8 +19,9 @@ type ObjectHeaderWriter struct {
 
 // MessageWriter represents a message that can be written to an object header.
 type MessageWriter struct {
-	Type MessageType
-	Data []byte
+	Type  MessageType
+	Flags uint8 // Message flags (MsgFlag* bits)
+	Data  []byte
 }
 
 // NewMinimalRootGroupHeader creates a minimal object header v2 for an empty root group.
@@ -95,14 +96,8 @@ func (ohw *ObjectHeaderWriter) Size() uint64 {
 //   - Message headers (8 bytes each)
 //   - Message data (variable, 8-byte aligned)
 //
-// IMPORTANT: The "Object Header Size" field in v1 includes ONLY:
-//   - The 16-byte header
-//   - All message headers (8 bytes each)
-//   - It does NOT include message data!
-//
-// This function returns the TOTAL size (header + message headers + message data)
-// for allocation purposes, but writeToV1() calculates the "Object Header Size"
-// field separately.
+// The "Object Header Size" field written by writeToV1() is this size minus
+// the 16-byte header: the messages, including their headers and padding.
 func (ohw *ObjectHeaderWriter) sizeV1() uint64 {
 	headerSize := uint64(16) // V1 header is always 16 bytes
 
@@ -190,7 +185,7 @@ func (ohw *ObjectHeaderWriter) WriteTo(w io.WriterAt, address uint64) (uint64, e
 //   - Reserved (1 byte) = 0
 //   - Number of Messages (2 bytes, little-endian)
 //   - Object Reference Count (4 bytes, little-endian)
-//   - Object Header Size (4 bytes, little-endian) - total size including header
+//   - Object Header Size (4 bytes, little-endian) - size of the messages after the header
 //   - Padding to 8-byte alignment (4 bytes of zeros)
 //   - Messages (each 8-byte aligned):
 //   - Type (2 bytes, little-endian)
@@ -203,10 +198,9 @@ func (ohw *ObjectHeaderWriter) writeToV1(w io.WriterAt, address uint64) (uint64,
 	totalSize := ohw.sizeV1()
 	buf := make([]byte, totalSize)
 
-	// Calculate "Object Header Size" field value
-	// This field includes ONLY: 16-byte header + message headers (8 bytes each)
-	// It does NOT include message data!
-	objectHeaderSize := uint32(16 + (len(ohw.Messages) * 8)) //nolint:gosec // G115: Safe - message count limited by HDF5 spec
+	// Calculate "Object Header Size" field value: the size of the messages
+	// (headers, data and padding) following the 16-byte header
+	objectHeaderSize := uint32(totalSize - 16) //nolint:gosec // G115: Safe - message sizes limited by HDF5 spec
 
 	offset := 0
 
@@ -225,8 +219,8 @@ func (ohw *ObjectHeaderWriter) writeToV1(w io.WriterAt, address uint64) (uint64,
 	binary.LittleEndian.PutUint32(buf[offset:offset+4], ohw.RefCount)
 	offset += 4
 
-	// Object header size (4 bytes) - header + message headers ONLY (no message data!)
-	// For 1 message: 16 (header) + 8 (message header) = 24 bytes
+	// Object header size (4 bytes) - messages following the header
+	// For 1 message with 16 bytes of data: 8 (message header) + 16 = 24 bytes
 	binary.LittleEndian.PutUint32(buf[offset:offset+4], objectHeaderSize)
 	offset += 4
 
@@ -245,7 +239,7 @@ func (ohw *ObjectHeaderWriter) writeToV1(w io.WriterAt, address uint64) (uint64,
 		offset += 2
 
 		// Message flags (1 byte)
-		buf[offset] = 0 // For MVP: no flags
+		buf[offset] = msg.Flags
 		offset++
 
 		// Reserved (3 bytes) - already zero from make()
@@ -355,8 +349,7 @@ func (ohw *ObjectHeaderWriter) writeToV2(w io.WriterAt, address uint64) (uint64,
 		offset += 2
 
 		// Message flags (1 byte)
-		// For MVP: flags = 0 (not shared, not constant, not shareable)
-		buf[offset] = 0
+		buf[offset] = msg.Flags
 		offset++
 
 		// Message data
@@ -480,8 +473,9 @@ func WriteObjectHeader(w io.WriterAt, addr uint64, oh *ObjectHeader, sb *Superbl
 	// Convert messages
 	for i, msg := range oh.Messages {
 		ohw.Messages[i] = MessageWriter{
-			Type: msg.Type,
-			Data: msg.Data,
+			Type:  msg.Type,
+			Flags: msg.Flags,
+			Data:  msg.Data,
 		}
 	}
 
@@ -0,0 +1,495 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/meko-christian/go-hdf5/internal/utils"
+)
+
+// Reference types stored in bits 0-3 of a reference datatype's class bit field.
+// Reference: H5Rpublic.h - H5R_type_t.
+const (
+	ReferenceTypeObject    = 0 // H5R_OBJECT: address of an object header.
+	ReferenceTypeRegion    = 1 // H5R_DATASET_REGION: global heap ID of a dataset address and selection.
+	ReferenceTypeObject2   = 2 // H5R_OBJECT2: revised (1.12) object reference.
+	ReferenceTypeRegion2   = 3 // H5R_DATASET_REGION2: revised (1.12) dataset region reference.
+	ReferenceTypeAttribute = 4 // H5R_ATTR: revised (1.12) attribute reference.
+)
+
+// refFlagExternal marks a revised reference that names another file.
+// Reference: H5Rint.c - H5R_IS_EXTERNAL.
+const refFlagExternal = 0x1
+
+// refEncodingVersion is the encoding version stored in bits 4-7 of a revised
+// reference datatype's class bit field.
+const refEncodingVersion = 1
+
+// Reference is a reference value read from a reference-typed dataset or
+// attribute: an ObjectRef, a RegionRef or a Ref.
+type Reference interface {
+	// IsNull reports whether the reference is unset and points to nothing.
+	IsNull() bool
+
+	isReference()
+}
+
+// ObjectRef is an object reference (H5R_OBJECT): the address of the
+// referenced object's header.
+type ObjectRef uint64
+
+// IsNull reports whether the reference is unset (address 0).
+func (r ObjectRef) IsNull() bool {
+	return r == 0
+}
+
+func (ObjectRef) isReference() {}
+
+// RegionRef is a dataset region reference (H5R_DATASET_REGION). The global
+// heap object it identifies holds the address of the referenced dataset
+// followed by the serialized selection.
+type RegionRef struct {
+	HeapAddress uint64 // Address of the global heap collection.
+	ObjectIndex uint32 // Index of the object within the collection.
+}
+
+// IsNull reports whether the reference is unset (heap address 0).
+func (r RegionRef) IsNull() bool {
+	return r.HeapAddress == 0
+}
+
+func (RegionRef) isReference() {}
+
+// Ref is a revised reference (H5R_REF, HDF5 1.12+) to an object, a dataset
+// region or an attribute, in the same file or in the file named by File.
+type Ref struct {
+	Type      uint8      // ReferenceTypeObject2, ReferenceTypeRegion2 or ReferenceTypeAttribute; 0 if null.
+	File      string     // Name of the file holding the object; empty for the referencing file.
+	Address   uint64     // Object header address of the referenced object.
+	Selection *Selection // Selected elements, for ReferenceTypeRegion2.
+	Attribute string     // Attribute name, for ReferenceTypeAttribute.
+}
+
+// IsNull reports whether the reference is unset (type 0).
+func (r Ref) IsNull() bool {
+	return r.Type == 0
+}
+
+func (Ref) isReference() {}
+
+// ReferenceType returns the reference type (ReferenceTypeObject or
+// ReferenceTypeRegion, or ReferenceTypeObject2 and up for revised references)
+// of a reference datatype.
+func (dt *DatatypeMessage) ReferenceType() uint8 {
+	return uint8(dt.ClassBitField & 0x0F) //nolint:gosec // G115: masked to 4 bits
+}
+
+// DecodeObjectReferences decodes count object references of datatype dt.
+// Each element is an address of dt.Size (4 or 8) bytes.
+func DecodeObjectReferences(data []byte, dt *DatatypeMessage, count uint64) ([]ObjectRef, error) {
+	if dt.Class != DatatypeReference || dt.ReferenceType() != ReferenceTypeObject {
+		return nil, fmt.Errorf("datatype is not an object reference: %s", dt)
+	}
+	size := uint64(dt.Size)
+	if size != 4 && size != 8 {
+		return nil, fmt.Errorf("unsupported object reference size: %d", size)
+	}
+	if err := checkReferenceData(data, size, count); err != nil {
+		return nil, err
+	}
+
+	refs := make([]ObjectRef, count)
+	for i := range refs {
+		refs[i] = ObjectRef(readAddress(data[uint64(i)*size:], int(size)))
+	}
+	return refs, nil
+}
+
+// DecodeRegionReferences decodes count region references of datatype dt.
+// Each element is a global heap ID: a heap address of dt.Size-4 (4 or 8)
+// bytes and a 4-byte object index.
+func DecodeRegionReferences(data []byte, dt *DatatypeMessage, count uint64) ([]RegionRef, error) {
+	if dt.Class != DatatypeReference || dt.ReferenceType() != ReferenceTypeRegion {
+		return nil, fmt.Errorf("datatype is not a region reference: %s", dt)
+	}
+	size := uint64(dt.Size)
+	if size != 8 && size != 12 {
+		return nil, fmt.Errorf("unsupported region reference size: %d", size)
+	}
+	if err := checkReferenceData(data, size, count); err != nil {
+		return nil, err
+	}
+
+	refs := make([]RegionRef, count)
+	for i := range refs {
+		heapID, err := ParseGlobalHeapReference(data[uint64(i)*size:], int(size)-4)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse region reference %d: %w", i, err)
+		}
+		refs[i] = RegionRef{HeapAddress: heapID.HeapAddress, ObjectIndex: heapID.ObjectIndex}
+	}
+	return refs, nil
+}
+
+// DecodeReferences decodes count references of datatype dt into ObjectRef,
+// RegionRef or Ref values. Revised references that do not fit in an element
+// are read from the global heap through r.
+func DecodeReferences(r io.ReaderAt, data []byte, dt *DatatypeMessage, count uint64, offsetSize int) ([]Reference, error) {
+	if dt.Class != DatatypeReference {
+		return nil, fmt.Errorf("datatype is not a reference: %s", dt)
+	}
+
+	refs := make([]Reference, 0, count)
+	switch dt.ReferenceType() {
+	case ReferenceTypeObject:
+		objRefs, err := DecodeObjectReferences(data, dt, count)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range objRefs {
+			refs = append(refs, ref)
+		}
+	case ReferenceTypeRegion:
+		regionRefs, err := DecodeRegionReferences(data, dt, count)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range regionRefs {
+			refs = append(refs, ref)
+		}
+	case ReferenceTypeObject2, ReferenceTypeRegion2, ReferenceTypeAttribute:
+		revised, err := DecodeRefs(r, data, dt, count, offsetSize)
+		if err != nil {
+			return nil, err
+		}
+		for _, ref := range revised {
+			refs = append(refs, ref)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported reference type: %d", dt.ReferenceType())
+	}
+	return refs, nil
+}
+
+// ReadRegionReference reads the global heap object of a region reference and
+// returns the address of the referenced dataset and the selected region.
+//
+// Format: dataset object header address (offsetSize bytes) followed by the
+// serialized selection (see ParseSelection).
+//
+// Reference: H5Rint.c - H5R__get_region(), H5R__encode_heap().
+func ReadRegionReference(r io.ReaderAt, ref RegionRef, offsetSize int) (uint64, *Selection, error) {
+	if ref.IsNull() {
+		return 0, nil, fmt.Errorf("null region reference")
+	}
+
+	collection, err := ReadGlobalHeapCollection(r, ref.HeapAddress, offsetSize)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read global heap collection at 0x%X: %w", ref.HeapAddress, err)
+	}
+	obj, err := collection.GetObject(ref.ObjectIndex)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get region reference object: %w", err)
+	}
+	if len(obj.Data) < offsetSize {
+		return 0, nil, fmt.Errorf("region reference object too short: %d bytes", len(obj.Data))
+	}
+
+	sel, _, err := ParseSelection(obj.Data[offsetSize:])
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to parse region selection: %w", err)
+	}
+	return readAddress(obj.Data, offsetSize), sel, nil
+}
+
+// EncodeRegionReference encodes the global heap object of a region reference
+// to the dataset at address, in the format read by ReadRegionReference.
+func EncodeRegionReference(address uint64, sel *Selection, offsetSize int) ([]byte, error) {
+	if offsetSize != 4 && offsetSize != 8 {
+		return nil, fmt.Errorf("invalid offset size: %d (must be 4 or 8)", offsetSize)
+	}
+	selData, err := EncodeSelection(sel)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode region selection: %w", err)
+	}
+
+	buf := make([]byte, offsetSize, offsetSize+len(selData))
+	if offsetSize == 4 {
+		binary.LittleEndian.PutUint32(buf, uint32(address)) //nolint:gosec // G115: 4-byte offsets hold 32-bit addresses
+	} else {
+		binary.LittleEndian.PutUint64(buf, address)
+	}
+	return append(buf, selData...), nil
+}
+
+// IsRevisedReference reports whether dt is the revised (H5R_REF) reference
+// datatype introduced in HDF5 1.12.
+func (dt *DatatypeMessage) IsRevisedReference() bool {
+	if dt.Class != DatatypeReference {
+		return false
+	}
+	switch dt.ReferenceType() {
+	case ReferenceTypeObject2, ReferenceTypeRegion2, ReferenceTypeAttribute:
+		return true
+	}
+	return false
+}
+
+// RefSize returns the size of a revised reference element in a file with
+// the given offset size: type, flags, blob size and global heap ID.
+func RefSize(offsetSize int) uint32 {
+	return uint32(2 + 4 + offsetSize + 4) //nolint:gosec // G115: offset size is 4 or 8
+}
+
+// RefDatatypeClassBitField returns the class bit field of the revised
+// reference datatype.
+func RefDatatypeClassBitField() uint32 {
+	return ReferenceTypeObject2 | refEncodingVersion<<4
+}
+
+// DecodeRefs decodes count revised references of datatype dt.
+//
+// Each element starts with the reference type and flags. Object references
+// to the same file store their address token inline; all other references
+// store the blob size and the global heap ID of the encoded reference, which
+// is read through r.
+//
+// Reference: H5Tref.c - H5T__ref_disk_read(), H5Rint.c - H5R__decode().
+func DecodeRefs(r io.ReaderAt, data []byte, dt *DatatypeMessage, count uint64, offsetSize int) ([]Ref, error) {
+	if !dt.IsRevisedReference() {
+		return nil, fmt.Errorf("datatype is not a revised reference: %s", dt)
+	}
+	if offsetSize != 4 && offsetSize != 8 {
+		return nil, fmt.Errorf("invalid offset size: %d (must be 4 or 8)", offsetSize)
+	}
+	size := uint64(dt.Size)
+	if size < uint64(RefSize(offsetSize)) {
+		return nil, fmt.Errorf("unsupported revised reference size: %d", size)
+	}
+	if err := checkReferenceData(data, size, count); err != nil {
+		return nil, err
+	}
+
+	collections := make(map[uint64]*GlobalHeapCollection)
+	refs := make([]Ref, count)
+	for i := range refs {
+		ref, err := decodeRef(r, data[uint64(i)*size:uint64(i+1)*size], offsetSize, collections)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode reference %d: %w", i, err)
+		}
+		refs[i] = ref
+	}
+	return refs, nil
+}
+
+// decodeRef decodes one revised reference element. Heap collections read
+// for earlier elements are reused from collections.
+func decodeRef(r io.ReaderAt, elem []byte, offsetSize int, collections map[uint64]*GlobalHeapCollection) (Ref, error) {
+	refType, flags := elem[0], elem[1]
+	switch refType {
+	case 0:
+		return Ref{}, nil
+	case ReferenceTypeObject2, ReferenceTypeRegion2, ReferenceTypeAttribute:
+	default:
+		return Ref{}, fmt.Errorf("unsupported reference type: %d", refType)
+	}
+
+	if refType == ReferenceTypeObject2 && flags&refFlagExternal == 0 {
+		ref := Ref{Type: refType}
+		if _, err := decodeRefToken(elem[2:], &ref); err != nil {
+			return Ref{}, err
+		}
+		return ref, nil
+	}
+
+	if r == nil {
+		return Ref{}, errors.New("no reader for global heap reference")
+	}
+	blobSize := binary.LittleEndian.Uint32(elem[2:6])
+	heapID, err := ParseGlobalHeapReference(elem[6:], offsetSize)
+	if err != nil {
+		return Ref{}, err
+	}
+	collection, ok := collections[heapID.HeapAddress]
+	if !ok {
+		collection, err = ReadGlobalHeapCollection(r, heapID.HeapAddress, offsetSize)
+		if err != nil {
+			return Ref{}, fmt.Errorf("failed to read global heap collection at 0x%X: %w", heapID.HeapAddress, err)
+		}
+		collections[heapID.HeapAddress] = collection
+	}
+	obj, err := collection.GetObject(heapID.ObjectIndex)
+	if err != nil {
+		return Ref{}, fmt.Errorf("failed to get reference object: %w", err)
+	}
+	if uint64(blobSize) > uint64(len(obj.Data)) {
+		return Ref{}, fmt.Errorf("reference object too short: need %d bytes, have %d", blobSize, len(obj.Data))
+	}
+	return decodeRefBlob(refType, flags, obj.Data[:blobSize])
+}
+
+// decodeRefBlob decodes an encoded revised reference without its type and
+// flags header: the object token, the file name of an external reference,
+// then the selection of a region reference or the name of an attribute.
+//
+// Reference: H5Rint.c - H5R__decode(), H5R__decode_region().
+func decodeRefBlob(refType, flags uint8, blob []byte) (Ref, error) {
+	ref := Ref{Type: refType}
+	pos, err := decodeRefToken(blob, &ref)
+	if err != nil {
+		return Ref{}, err
+	}
+
+	if flags&refFlagExternal != 0 {
+		name, n, err := decodeRefString(blob[pos:])
+		if err != nil {
+			return Ref{}, fmt.Errorf("failed to decode file name: %w", err)
+		}
+		ref.File = name
+		pos += n
+	}
+
+	switch refType {
+	case ReferenceTypeRegion2:
+		if len(blob)-pos < 8 {
+			return Ref{}, errors.New("region reference truncated")
+		}
+		rank := binary.LittleEndian.Uint32(blob[pos+4 : pos+8])
+		sel, _, err := ParseSelection(blob[pos+8:])
+		if err != nil {
+			return Ref{}, fmt.Errorf("failed to parse region selection: %w", err)
+		}
+		if sel.Rank == 0 {
+			if rank > maxSelectionRank {
+				return Ref{}, fmt.Errorf("invalid selection rank: %d", rank)
+			}
+			sel.Rank = int(rank)
+		}
+		ref.Selection = sel
+	case ReferenceTypeAttribute:
+		name, _, err := decodeRefString(blob[pos:])
+		if err != nil {
+			return Ref{}, fmt.Errorf("failed to decode attribute name: %w", err)
+		}
+		ref.Attribute = name
+	}
+	return ref, nil
+}
+
+// decodeRefToken decodes the object token (a size byte followed by the
+// object address) into ref and returns the number of bytes read.
+func decodeRefToken(data []byte, ref *Ref) (int, error) {
+	if len(data) < 1 {
+		return 0, errors.New("reference token truncated")
+	}
+	size := int(data[0])
+	if size == 0 || size > 8 || len(data) < 1+size {
+		return 0, fmt.Errorf("invalid reference token size: %d", size)
+	}
+	ref.Address = readAddress(data[1:], size)
+	return 1 + size, nil
+}
+
+// decodeRefString decodes a string stored as a 2-byte length followed by the
+// characters, returning the string and the number of bytes read.
+func decodeRefString(data []byte) (string, int, error) {
+	if len(data) < 2 {
+		return "", 0, errors.New("string truncated")
+	}
+	n := int(binary.LittleEndian.Uint16(data[0:2]))
+	if len(data) < 2+n {
+		return "", 0, errors.New("string truncated")
+	}
+	return string(data[2 : 2+n]), 2 + n, nil
+}
+
+// EncodeRef encodes a revised reference element of RefSize(offsetSize)
+// bytes. Object references to the same file are stored inline; for all
+// others putBlob stores the encoded reference in the global heap and returns
+// its heap ID.
+//
+// Reference: H5Tref.c - H5T__ref_disk_write(), H5Rint.c - H5R__encode().
+func EncodeRef(ref Ref, offsetSize int, putBlob func([]byte) (uint64, uint32, error)) ([]byte, error) {
+	if offsetSize != 4 && offsetSize != 8 {
+		return nil, fmt.Errorf("invalid offset size: %d (must be 4 or 8)", offsetSize)
+	}
+	elem := make([]byte, RefSize(offsetSize))
+	if ref.IsNull() {
+		return elem, nil
+	}
+
+	var flags uint8
+	if ref.File != "" {
+		flags |= refFlagExternal
+	}
+	elem[0], elem[1] = ref.Type, flags
+
+	blob := []byte{byte(offsetSize)}
+	blob = binary.LittleEndian.AppendUint64(blob, ref.Address)[:1+offsetSize]
+	if ref.Type == ReferenceTypeObject2 && ref.File == "" {
+		copy(elem[2:], blob)
+		return elem, nil
+	}
+
+	var err error
+	if ref.File != "" {
+		if blob, err = appendRefString(blob, ref.File); err != nil {
+			return nil, fmt.Errorf("invalid file name: %w", err)
+		}
+	}
+	switch ref.Type {
+	case ReferenceTypeObject2:
+	case ReferenceTypeRegion2:
+		if ref.Selection == nil {
+			return nil, errors.New("region reference has no selection")
+		}
+		selData, err := EncodeSelection(ref.Selection)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode region selection: %w", err)
+		}
+		blob = binary.LittleEndian.AppendUint32(blob, uint32(4+len(selData)))     //nolint:gosec // G115: selections are far below 4 GiB
+		blob = binary.LittleEndian.AppendUint32(blob, uint32(ref.Selection.Rank)) //nolint:gosec // G115: rank is at most 32
+		blob = append(blob, selData...)
+	case ReferenceTypeAttribute:
+		if ref.Attribute == "" {
+			return nil, errors.New("attribute reference has no attribute name")
+		}
+		if blob, err = appendRefString(blob, ref.Attribute); err != nil {
+			return nil, fmt.Errorf("invalid attribute name: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported reference type: %d", ref.Type)
+	}
+
+	addr, index, err := putBlob(blob)
+	if err != nil {
+		return nil, err
+	}
+	binary.LittleEndian.PutUint32(elem[2:6], uint32(len(blob))) //nolint:gosec // G115: blob size bounded by string lengths
+	writeAddress(elem[6:], addr, offsetSize, binary.LittleEndian)
+	binary.LittleEndian.PutUint32(elem[6+offsetSize:], index)
+	return elem, nil
+}
+
+// appendRefString appends s with a 2-byte length prefix.
+func appendRefString(buf []byte, s string) ([]byte, error) {
+	if len(s) > 0xFFFF {
+		return nil, fmt.Errorf("too long: %d bytes", len(s))
+	}
+	buf = binary.LittleEndian.AppendUint16(buf, uint16(len(s))) //nolint:gosec // G115: checked above
+	return append(buf, s...), nil
+}
+
+// checkReferenceData verifies that data holds count references of size bytes.
+func checkReferenceData(data []byte, size, count uint64) error {
+	totalBytes, err := utils.SafeMultiply(count, size)
+	if err != nil {
+		return fmt.Errorf("reference data size overflow: %w", err)
+	}
+	if totalBytes > uint64(len(data)) {
+		return fmt.Errorf("reference data size mismatch: need %d bytes, have %d", totalBytes, len(data))
+	}
+	return nil
+}
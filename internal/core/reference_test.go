@@ -0,0 +1,62 @@
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecodeReferences(t *testing.T) {
+	objType := &DatatypeMessage{Class: DatatypeReference, Size: 8, ClassBitField: ReferenceTypeObject}
+	data := make([]byte, 16)
+	binary.LittleEndian.PutUint64(data[0:], 0x320)
+	refs, err := DecodeReferences(nil, data, objType, 2, 8)
+	require.NoError(t, err)
+	require.Equal(t, []Reference{ObjectRef(0x320), ObjectRef(0)}, refs)
+	require.False(t, refs[0].IsNull())
+	require.True(t, refs[1].IsNull())
+
+	regionType := &DatatypeMessage{Class: DatatypeReference, Size: 12, ClassBitField: ReferenceTypeRegion}
+	data = make([]byte, 12)
+	binary.LittleEndian.PutUint64(data[0:], 0x1860)
+	binary.LittleEndian.PutUint32(data[8:], 3)
+	refs, err = DecodeReferences(nil, data, regionType, 1, 8)
+	require.NoError(t, err)
+	require.Equal(t, []Reference{RegionRef{HeapAddress: 0x1860, ObjectIndex: 3}}, refs)
+
+	_, err = DecodeReferences(nil, data, objType, 2, 8)
+	require.ErrorContains(t, err, "size mismatch")
+	_, err = DecodeObjectReferences(data, regionType, 1)
+	require.ErrorContains(t, err, "not an object reference")
+	_, err = DecodeReferences(nil, data, &DatatypeMessage{Class: DatatypeReference, Size: 8, ClassBitField: 5}, 1, 8)
+	require.ErrorContains(t, err, "unsupported reference type: 5")
+	_, err = DecodeReferences(nil, data, &DatatypeMessage{Class: DatatypeFixed, Size: 4}, 1, 8)
+	require.ErrorContains(t, err, "not a reference")
+}
+
+func TestEncodeRef_Inline(t *testing.T) {
+	refType := &DatatypeMessage{Class: DatatypeReference, Size: RefSize(8), ClassBitField: RefDatatypeClassBitField()}
+	require.True(t, refType.IsRevisedReference())
+
+	noHeap := func([]byte) (uint64, uint32, error) {
+		t.Fatal("inline reference written to the heap")
+		return 0, 0, nil
+	}
+	obj, err := EncodeRef(Ref{Type: ReferenceTypeObject2, Address: 0x728}, 8, noHeap)
+	require.NoError(t, err)
+	require.Equal(t, []byte{2, 0, 8, 0x28, 7, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0}, obj)
+	null, err := EncodeRef(Ref{}, 8, noHeap)
+	require.NoError(t, err)
+
+	refs, err := DecodeReferences(nil, append(obj, null...), refType, 2, 8)
+	require.NoError(t, err)
+	require.Equal(t, []Reference{Ref{Type: ReferenceTypeObject2, Address: 0x728}, Ref{}}, refs)
+	require.True(t, refs[1].IsNull())
+
+	// Attribute references live in the global heap.
+	_, err = DecodeReferences(nil, []byte{4, 0, 10, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 1, 0, 0, 0}, refType, 1, 8)
+	require.ErrorContains(t, err, "no reader")
+	_, err = EncodeRef(Ref{Type: ReferenceTypeAttribute, Address: 0x728}, 8, noHeap)
+	require.ErrorContains(t, err, "no attribute name")
+}
@@ -0,0 +1,657 @@
+package core
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// SelectionType identifies the kind of a dataspace selection.
+// Reference: H5Spublic.h - H5S_sel_type.
+type SelectionType uint32
+
+// Selection types.
+const (
+	SelectionNone       SelectionType = 0 // Nothing selected.
+	SelectionPoints     SelectionType = 1 // List of element coordinates.
+	SelectionHyperslabs SelectionType = 2 // Regular hyperslab or list of blocks.
+	SelectionAll        SelectionType = 3 // Entire dataspace.
+)
+
+// SelectionUnlimited is the count or block of a regular hyperslab dimension
+// that extends to the end of the (unlimited) dimension (H5S_UNLIMITED).
+const SelectionUnlimited = ^uint64(0)
+
+// maxSelectionRank is the largest rank of a dataspace (H5S_MAX_RANK).
+const maxSelectionRank = 32
+
+// hyperslabFlagRegular marks hyperslab selections stored as start, stride,
+// count and block per dimension (H5S_HYPER_REGULAR).
+const hyperslabFlagRegular = 0x01
+
+// HyperslabDim describes a regular hyperslab selection in one dimension:
+// Count blocks of Block elements each, Stride elements apart, from Start.
+type HyperslabDim struct {
+	Start  uint64
+	Stride uint64
+	Count  uint64 // SelectionUnlimited for an unlimited number of blocks.
+	Block  uint64 // SelectionUnlimited for a block reaching the end of the dimension.
+}
+
+// SelectionBlock is one block of an irregular hyperslab selection, given by
+// its first and last (inclusive) coordinates.
+type SelectionBlock struct {
+	Start []uint64
+	End   []uint64
+}
+
+// Selection is a serialized dataspace selection, as stored in virtual
+// dataset mappings and dataset region references.
+//
+// Hyperslab selections are either regular (Regular holds one entry per
+// dimension) or a list of blocks. The rank of "all" and "none" selections is
+// not serialized and taken from the dataspace they are applied to.
+type Selection struct {
+	Type    SelectionType
+	Rank    int
+	Regular []HyperslabDim
+	Blocks  []SelectionBlock
+	Points  [][]uint64 // Point coordinates, in selection order.
+}
+
+// SelectionRun is a run of selected elements that are consecutive along the
+// fastest-changing dimension, starting at Coords.
+type SelectionRun struct {
+	Coords []uint64
+	Length uint64
+}
+
+// ParseSelection decodes a serialized dataspace selection and returns it
+// together with the number of bytes consumed.
+//
+// Format: selection type and version (4 bytes each), followed by a
+// type- and version-specific encoding. Versions 1 and 2 use fixed-size
+// fields; version 3 (HDF5 1.12+) stores coordinates in 2, 4 or 8 bytes.
+//
+// Reference: H5Sall.c, H5Snone.c, H5Spoint.c, H5Shyper.c - *_deserialize().
+func ParseSelection(data []byte) (*Selection, int, error) {
+	if len(data) < 8 {
+		return nil, 0, errors.New("selection too short")
+	}
+
+	sel := &Selection{Type: SelectionType(binary.LittleEndian.Uint32(data[0:4]))}
+	version := binary.LittleEndian.Uint32(data[4:8])
+	d := &selectionDecoder{data: data, offset: 8}
+
+	switch sel.Type {
+	case SelectionNone, SelectionAll:
+		if version != 1 {
+			return nil, 0, fmt.Errorf("unsupported selection version %d for type %d", version, sel.Type)
+		}
+		d.skip(8) // Reserved and length.
+
+	case SelectionPoints:
+		if err := d.decodePoints(sel, version); err != nil {
+			return nil, 0, err
+		}
+
+	case SelectionHyperslabs:
+		if err := d.decodeHyperslab(sel, version); err != nil {
+			return nil, 0, err
+		}
+
+	default:
+		return nil, 0, fmt.Errorf("unsupported selection type: %d", sel.Type)
+	}
+
+	if d.err != nil {
+		return nil, 0, d.err
+	}
+	return sel, d.offset, nil
+}
+
+// selectionDecoder reads the fields of a serialized selection. The first
+// read past the end of the data sets err; later reads return zero.
+type selectionDecoder struct {
+	data   []byte
+	offset int
+	err    error
+}
+
+func (d *selectionDecoder) skip(n int) {
+	if d.err == nil && d.offset+n > len(d.data) {
+		d.err = errors.New("selection truncated")
+	}
+	d.offset += n
+}
+
+func (d *selectionDecoder) uint(size int) uint64 {
+	if d.err != nil {
+		return 0
+	}
+	if d.offset+size > len(d.data) {
+		d.err = errors.New("selection truncated")
+		return 0
+	}
+	v := readUint64(d.data[d.offset:], size, binary.LittleEndian)
+	d.offset += size
+	return v
+}
+
+// unlimited reads a count or block, which holds SelectionUnlimited as all
+// ones in the encoded size.
+func (d *selectionDecoder) unlimited(size int) uint64 {
+	v := d.uint(size)
+	if size < 8 && v == 1<<(8*size)-1 {
+		return SelectionUnlimited
+	}
+	return v
+}
+
+// encodedSize reads the coordinate size of a version 3 selection.
+func (d *selectionDecoder) encodedSize() int {
+	size := int(d.uint(1))
+	if d.err == nil && size != 2 && size != 4 && size != 8 {
+		d.err = fmt.Errorf("invalid selection encoding size: %d", size)
+	}
+	return size
+}
+
+// rank reads the selection rank.
+func (d *selectionDecoder) rank() int {
+	rank := d.uint(4)
+	if d.err == nil && (rank == 0 || rank > maxSelectionRank) {
+		d.err = fmt.Errorf("invalid selection rank: %d", rank)
+	}
+	return int(rank)
+}
+
+// count reads a number of points or blocks, each needing at least
+// perItem bytes, and checks it against the remaining data.
+func (d *selectionDecoder) count(size, perItem int) uint64 {
+	n := d.uint(size)
+	if d.err == nil && n > uint64(len(d.data)-d.offset)/uint64(perItem) {
+		d.err = fmt.Errorf("selection count %d exceeds data size", n)
+	}
+	return n
+}
+
+func (d *selectionDecoder) coords(rank, size int) []uint64 {
+	coords := make([]uint64, rank)
+	for i := range coords {
+		coords[i] = d.uint(size)
+	}
+	return coords
+}
+
+// decodePoints decodes a point selection (versions 1 and 2).
+func (d *selectionDecoder) decodePoints(sel *Selection, version uint32) error {
+	size := 4
+	switch version {
+	case 1:
+		d.skip(8) // Reserved and length.
+	case 2:
+		size = d.encodedSize()
+	default:
+		return fmt.Errorf("unsupported point selection version: %d", version)
+	}
+
+	sel.Rank = d.rank()
+	n := d.count(size, sel.Rank*size)
+	if d.err != nil {
+		return d.err
+	}
+
+	sel.Points = make([][]uint64, n)
+	for i := range sel.Points {
+		sel.Points[i] = d.coords(sel.Rank, size)
+	}
+	return d.err
+}
+
+// decodeHyperslab decodes a hyperslab selection (versions 1-3).
+func (d *selectionDecoder) decodeHyperslab(sel *Selection, version uint32) error {
+	size := 4
+	var flags uint64
+	switch version {
+	case 1:
+		d.skip(8) // Reserved and length.
+	case 2:
+		flags = d.uint(1)
+		d.skip(4) // Length.
+		size = 8
+	case 3:
+		flags = d.uint(1)
+		size = d.encodedSize()
+	default:
+		return fmt.Errorf("unsupported hyperslab selection version: %d", version)
+	}
+
+	sel.Rank = d.rank()
+	if d.err != nil {
+		return d.err
+	}
+
+	if flags&hyperslabFlagRegular != 0 {
+		sel.Regular = make([]HyperslabDim, sel.Rank)
+		for i := range sel.Regular {
+			sel.Regular[i] = HyperslabDim{
+				Start:  d.uint(size),
+				Stride: d.uint(size),
+				Count:  d.unlimited(size),
+				Block:  d.unlimited(size),
+			}
+		}
+		return d.err
+	}
+	if version == 2 {
+		return errors.New("hyperslab selection version 2 must be regular")
+	}
+
+	countSize := size
+	if version == 1 {
+		countSize = 4
+	}
+	n := d.count(countSize, 2*sel.Rank*size)
+	if d.err != nil {
+		return d.err
+	}
+
+	sel.Blocks = make([]SelectionBlock, n)
+	for i := range sel.Blocks {
+		sel.Blocks[i] = SelectionBlock{
+			Start: d.coords(sel.Rank, size),
+			End:   d.coords(sel.Rank, size),
+		}
+	}
+	return d.err
+}
+
+// EncodeSelection serializes a selection in the format read by
+// ParseSelection, using the oldest version that can hold it: version 1 for
+// "all", "none" and selections whose coordinates fit in 32 bits, version 2
+// for regular hyperslabs and points with larger coordinates, and version 3
+// for lists of blocks with larger coordinates.
+//
+// Reference: H5Sall.c, H5Snone.c, H5Spoint.c, H5Shyper.c - *_serialize().
+func EncodeSelection(sel *Selection) ([]byte, error) {
+	e := &selectionEncoder{}
+	e.uint(4, uint64(sel.Type))
+
+	switch sel.Type {
+	case SelectionNone, SelectionAll:
+		e.uint(4, 1) // Version.
+		e.uint(4, 0) // Reserved.
+		e.uint(4, 0) // Length.
+
+	case SelectionPoints:
+		if err := checkSelectionCoords(sel.Rank, sel.Points...); err != nil {
+			return nil, err
+		}
+		if fitsUint32(sel.Points...) {
+			e.uint(4, 1) // Version.
+			e.uint(4, 0) // Reserved.
+			e.uint(4, uint64(8+4*sel.Rank*len(sel.Points)))
+			e.uint(4, uint64(sel.Rank))
+			e.uint(4, uint64(len(sel.Points)))
+			e.coords(4, sel.Points...)
+		} else {
+			e.uint(4, 2) // Version.
+			e.uint(1, 8) // Encoded size.
+			e.uint(4, uint64(sel.Rank))
+			e.uint(8, uint64(len(sel.Points)))
+			e.coords(8, sel.Points...)
+		}
+
+	case SelectionHyperslabs:
+		if sel.Regular != nil {
+			if len(sel.Regular) != sel.Rank {
+				return nil, fmt.Errorf("hyperslab selection has %d dimensions, want rank %d", len(sel.Regular), sel.Rank)
+			}
+			if err := checkSelectionCoords(sel.Rank); err != nil {
+				return nil, err
+			}
+			e.uint(4, 2) // Version.
+			e.uint(1, hyperslabFlagRegular)
+			e.uint(4, uint64(4+32*sel.Rank))
+			e.uint(4, uint64(sel.Rank))
+			for _, dim := range sel.Regular {
+				e.uint(8, dim.Start)
+				e.uint(8, dim.Stride)
+				e.uint(8, dim.Count)
+				e.uint(8, dim.Block)
+			}
+			break
+		}
+
+		corners := make([][]uint64, 0, 2*len(sel.Blocks))
+		for _, b := range sel.Blocks {
+			corners = append(corners, b.Start, b.End)
+		}
+		if err := checkSelectionCoords(sel.Rank, corners...); err != nil {
+			return nil, err
+		}
+		if fitsUint32(corners...) {
+			e.uint(4, 1) // Version.
+			e.uint(4, 0) // Reserved.
+			e.uint(4, uint64(8+8*sel.Rank*len(sel.Blocks)))
+			e.uint(4, uint64(sel.Rank))
+			e.uint(4, uint64(len(sel.Blocks)))
+			e.coords(4, corners...)
+		} else {
+			e.uint(4, 3) // Version.
+			e.uint(1, 0) // Flags.
+			e.uint(1, 8) // Encoded size.
+			e.uint(4, uint64(sel.Rank))
+			e.uint(8, uint64(len(sel.Blocks)))
+			e.coords(8, corners...)
+		}
+
+	default:
+		return nil, fmt.Errorf("unsupported selection type: %d", sel.Type)
+	}
+
+	return e.buf, nil
+}
+
+// selectionEncoder appends the little-endian fields of a serialized
+// selection.
+type selectionEncoder struct {
+	buf []byte
+}
+
+func (e *selectionEncoder) uint(size int, v uint64) {
+	var tmp [8]byte
+	binary.LittleEndian.PutUint64(tmp[:], v)
+	e.buf = append(e.buf, tmp[:size]...)
+}
+
+func (e *selectionEncoder) coords(size int, coords ...[]uint64) {
+	for _, c := range coords {
+		for _, v := range c {
+			e.uint(size, v)
+		}
+	}
+}
+
+// checkSelectionCoords checks the rank of a selection and that each of
+// coords has rank entries.
+func checkSelectionCoords(rank int, coords ...[]uint64) error {
+	if rank == 0 || rank > maxSelectionRank {
+		return fmt.Errorf("invalid selection rank: %d", rank)
+	}
+	for _, c := range coords {
+		if len(c) != rank {
+			return fmt.Errorf("selection coordinates %v do not match rank %d", c, rank)
+		}
+	}
+	return nil
+}
+
+// fitsUint32 reports whether all coordinates fit in 32 bits.
+func fitsUint32(coords ...[]uint64) bool {
+	for _, c := range coords {
+		for _, v := range c {
+			if v > math.MaxUint32 {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// UnlimitedDim returns the dimension in which a regular hyperslab selection
+// has an unlimited count or block, or -1 if the selection is bounded.
+func (s *Selection) UnlimitedDim() int {
+	for i, dim := range s.Regular {
+		if dim.Count == SelectionUnlimited || dim.Block == SelectionUnlimited {
+			return i
+		}
+	}
+	return -1
+}
+
+// Bounds returns, per dimension, one past the largest selected coordinate.
+// In unlimited dimensions the bound is the start of the selection. The rank
+// of "all" and "none" selections is unknown, so they have no bounds.
+func (s *Selection) Bounds() []uint64 {
+	switch {
+	case s.Regular != nil:
+		bounds := make([]uint64, len(s.Regular))
+		for i, dim := range s.Regular {
+			switch {
+			case dim.Count == SelectionUnlimited || dim.Block == SelectionUnlimited:
+				bounds[i] = dim.Start
+			default:
+				bounds[i] = dim.Start + (dim.Count-1)*dim.Stride + dim.Block
+			}
+		}
+		return bounds
+
+	case s.Type == SelectionHyperslabs:
+		bounds := make([]uint64, s.Rank)
+		for _, b := range s.Blocks {
+			for i, end := range b.End {
+				bounds[i] = max(bounds[i], end+1)
+			}
+		}
+		return bounds
+
+	case s.Type == SelectionPoints:
+		bounds := make([]uint64, s.Rank)
+		for _, p := range s.Points {
+			for i, c := range p {
+				bounds[i] = max(bounds[i], c+1)
+			}
+		}
+		return bounds
+	}
+	return nil
+}
+
+// Runs returns the selected elements as runs along the last dimension, in
+// the order the selection is iterated: row-major for hyperslabs and "all",
+// the stored order for points.
+//
+// dims is the extent of the dataspace the selection is applied to. It sizes
+// "all" selections and ends unlimited counts and blocks; runs of bounded
+// selections are not clipped to it.
+//
+// Reference: H5Shyper.c - H5S__hyper_iter_get_seq_list().
+func (s *Selection) Runs(dims []uint64) ([]SelectionRun, error) {
+	switch s.Type {
+	case SelectionNone:
+		return nil, nil
+
+	case SelectionAll:
+		return regularRuns(allIntervals(dims))
+
+	case SelectionPoints:
+		if s.Rank != len(dims) {
+			return nil, fmt.Errorf("point selection rank %d does not match dataspace rank %d", s.Rank, len(dims))
+		}
+		runs := make([]SelectionRun, len(s.Points))
+		for i, p := range s.Points {
+			runs[i] = SelectionRun{Coords: p, Length: 1}
+		}
+		return runs, nil
+
+	case SelectionHyperslabs:
+		if s.Rank != len(dims) {
+			return nil, fmt.Errorf("hyperslab selection rank %d does not match dataspace rank %d", s.Rank, len(dims))
+		}
+		if s.Regular != nil {
+			intervals := make([][]interval, len(s.Regular))
+			for i, dim := range s.Regular {
+				var err error
+				if intervals[i], err = dim.intervals(dims[i]); err != nil {
+					return nil, err
+				}
+			}
+			return regularRuns(intervals)
+		}
+		return blockRuns(s.Blocks)
+	}
+
+	return nil, fmt.Errorf("unsupported selection type: %d", s.Type)
+}
+
+// NumElements returns the number of selected elements; see Runs for dims.
+func (s *Selection) NumElements(dims []uint64) (uint64, error) {
+	runs, err := s.Runs(dims)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	for _, run := range runs {
+		n += run.Length
+	}
+	return n, nil
+}
+
+// interval is a half-open coordinate range [lo, hi).
+type interval struct {
+	lo, hi uint64
+}
+
+// maxSelectionBlocks limits the number of blocks a regular hyperslab
+// selection may expand to in one dimension, and maxSelectionRuns the number
+// of runs any selection may expand to.
+const (
+	maxSelectionBlocks = 1 << 24
+	maxSelectionRuns   = 1 << 26
+)
+
+// intervals returns the sorted, merged coordinate ranges selected in this
+// dimension. Unlimited counts and blocks end at extent.
+func (dim HyperslabDim) intervals(extent uint64) ([]interval, error) {
+	if dim.Block == SelectionUnlimited {
+		if dim.Start >= extent {
+			return nil, nil
+		}
+		return []interval{{dim.Start, extent}}, nil
+	}
+
+	count := dim.Count
+	if count == SelectionUnlimited {
+		count = 0
+		if dim.Start < extent && dim.Stride > 0 {
+			count = (extent - dim.Start + dim.Stride - 1) / dim.Stride
+		}
+	}
+	if count > maxSelectionBlocks {
+		return nil, fmt.Errorf("hyperslab selection has too many blocks: %d", count)
+	}
+
+	var result []interval
+	for i := uint64(0); i < count; i++ {
+		lo := dim.Start + i*dim.Stride
+		hi := lo + dim.Block
+		if dim.Count == SelectionUnlimited {
+			hi = min(hi, extent)
+		}
+		if n := len(result); n > 0 && lo <= result[n-1].hi {
+			// Blocks overlap when the stride is smaller than the block.
+			result[n-1].hi = max(result[n-1].hi, hi)
+			continue
+		}
+		result = append(result, interval{lo, hi})
+	}
+	return result, nil
+}
+
+// allIntervals returns one interval covering each dimension of dims.
+func allIntervals(dims []uint64) [][]interval {
+	intervals := make([][]interval, len(dims))
+	for i, d := range dims {
+		if d > 0 {
+			intervals[i] = []interval{{0, d}}
+		}
+	}
+	return intervals
+}
+
+// regularRuns returns the row-major runs of the product of the per-dimension
+// intervals.
+func regularRuns(intervals [][]interval) ([]SelectionRun, error) {
+	rank := len(intervals)
+	if rank == 0 {
+		// Scalar dataspace: a single element.
+		return []SelectionRun{{Coords: []uint64{}, Length: 1}}, nil
+	}
+
+	// One run per interval of the last dimension and selected coordinate
+	// of the other dimensions.
+	numRuns := uint64(len(intervals[rank-1]))
+	for _, iv := range intervals[:rank-1] {
+		var n uint64
+		for _, r := range iv {
+			n += r.hi - r.lo
+		}
+		if n != 0 && numRuns > maxSelectionRuns/n {
+			return nil, errors.New("selection has too many runs")
+		}
+		numRuns *= n
+	}
+	if numRuns == 0 {
+		return nil, nil
+	}
+
+	runs := make([]SelectionRun, 0, numRuns)
+	coords := make([]uint64, rank)
+	var walk func(dim int)
+	walk = func(dim int) {
+		if dim == rank-1 {
+			for _, iv := range intervals[dim] {
+				c := append([]uint64(nil), coords...)
+				c[dim] = iv.lo
+				runs = append(runs, SelectionRun{Coords: c, Length: iv.hi - iv.lo})
+			}
+			return
+		}
+		for _, iv := range intervals[dim] {
+			for x := iv.lo; x < iv.hi; x++ {
+				coords[dim] = x
+				walk(dim + 1)
+			}
+		}
+	}
+	walk(0)
+	return runs, nil
+}
+
+// blockRuns returns the runs of a list of hyperslab blocks in row-major
+// order.
+func blockRuns(blocks []SelectionBlock) ([]SelectionRun, error) {
+	var runs []SelectionRun
+	for _, b := range blocks {
+		intervals := make([][]interval, len(b.Start))
+		for i := range b.Start {
+			if b.End[i] < b.Start[i] {
+				return nil, fmt.Errorf("invalid hyperslab block: start %v, end %v", b.Start, b.End)
+			}
+			intervals[i] = []interval{{b.Start[i], b.End[i] + 1}}
+		}
+		r, err := regularRuns(intervals)
+		if err != nil {
+			return nil, err
+		}
+		if len(runs)+len(r) > maxSelectionRuns {
+			return nil, errors.New("selection has too many runs")
+		}
+		runs = append(runs, r...)
+	}
+
+	sort.Slice(runs, func(i, j int) bool {
+		a, b := runs[i].Coords, runs[j].Coords
+		for k := range a {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		return false
+	})
+	return runs, nil
+}
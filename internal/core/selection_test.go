@@ -0,0 +1,299 @@
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// selectionBytes encodes values as little-endian integers of the given size.
+func selectionBytes(size int, values ...uint64) []byte {
+	buf := make([]byte, 0, size*len(values))
+	for _, v := range values {
+		switch size {
+		case 1:
+			buf = append(buf, byte(v))
+		case 2:
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(v))
+		case 4:
+			buf = binary.LittleEndian.AppendUint32(buf, uint32(v))
+		default:
+			buf = binary.LittleEndian.AppendUint64(buf, v)
+		}
+	}
+	return buf
+}
+
+func concatBytes(parts ...[]byte) []byte {
+	var buf []byte
+	for _, p := range parts {
+		buf = append(buf, p...)
+	}
+	return buf
+}
+
+func TestParseSelection(t *testing.T) {
+	u := SelectionUnlimited
+	tests := []struct {
+		name string
+		data []byte
+		want *Selection
+	}{
+		{
+			name: "all",
+			data: selectionBytes(4, 3, 1, 0, 0),
+			want: &Selection{Type: SelectionAll},
+		},
+		{
+			name: "none",
+			data: selectionBytes(4, 0, 1, 0, 0),
+			want: &Selection{Type: SelectionNone},
+		},
+		{
+			name: "points v1",
+			data: selectionBytes(4, 1, 1, 0, 24, 2, 2, 1, 2, 3, 4),
+			want: &Selection{Type: SelectionPoints, Rank: 2, Points: [][]uint64{{1, 2}, {3, 4}}},
+		},
+		{
+			name: "points v2",
+			data: concatBytes(selectionBytes(4, 1, 2), []byte{2}, selectionBytes(4, 1), selectionBytes(2, 2, 5, 0)),
+			want: &Selection{Type: SelectionPoints, Rank: 1, Points: [][]uint64{{5}, {0}}},
+		},
+		{
+			name: "hyperslab v1 blocks",
+			data: selectionBytes(4, 2, 1, 0, 24, 1, 2, 0, 3, 6, 9),
+			want: &Selection{Type: SelectionHyperslabs, Rank: 1, Blocks: []SelectionBlock{
+				{Start: []uint64{0}, End: []uint64{3}},
+				{Start: []uint64{6}, End: []uint64{9}},
+			}},
+		},
+		{
+			name: "hyperslab v2 regular",
+			data: concatBytes(selectionBytes(4, 2, 2), []byte{1}, selectionBytes(4, 68, 2), selectionBytes(8, 0, 4, u, 2, 1, 1, 3, 1)),
+			want: &Selection{Type: SelectionHyperslabs, Rank: 2, Regular: []HyperslabDim{
+				{Start: 0, Stride: 4, Count: u, Block: 2},
+				{Start: 1, Stride: 1, Count: 3, Block: 1},
+			}},
+		},
+		{
+			name: "hyperslab v3 regular",
+			data: concatBytes(selectionBytes(4, 2, 3), []byte{1, 2}, selectionBytes(4, 1), selectionBytes(2, 5, 1, 1, 10)),
+			want: &Selection{Type: SelectionHyperslabs, Rank: 1, Regular: []HyperslabDim{
+				{Start: 5, Stride: 1, Count: 1, Block: 10},
+			}},
+		},
+		{
+			name: "hyperslab v3 blocks",
+			data: concatBytes(selectionBytes(4, 2, 3), []byte{0, 8}, selectionBytes(4, 2), selectionBytes(8, 1, 0, 0, 1, 1)),
+			want: &Selection{Type: SelectionHyperslabs, Rank: 2, Blocks: []SelectionBlock{
+				{Start: []uint64{0, 0}, End: []uint64{1, 1}},
+			}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Trailing bytes belong to the next field and are not consumed.
+			got, n, err := ParseSelection(append(tt.data, 0xEE))
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+			require.Equal(t, len(tt.data), n)
+
+			for i := 0; i < len(tt.data); i++ {
+				_, _, err := ParseSelection(tt.data[:i])
+				require.Error(t, err, "length %d", i)
+			}
+		})
+	}
+}
+
+func TestParseSelection_Errors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"bad type", selectionBytes(4, 7, 1, 0, 0), "unsupported selection type"},
+		{"all version", selectionBytes(4, 3, 2, 0, 0), "unsupported selection version"},
+		{"points version", selectionBytes(4, 1, 3, 0, 0), "unsupported point selection version"},
+		{"hyperslab version", selectionBytes(4, 2, 4, 0, 0), "unsupported hyperslab selection version"},
+		{"zero rank", selectionBytes(4, 1, 1, 0, 0, 0, 0), "invalid selection rank"},
+		{"rank too large", selectionBytes(4, 1, 1, 0, 0, 33, 0), "invalid selection rank"},
+		{"encoding size", concatBytes(selectionBytes(4, 1, 2), []byte{3}), "invalid selection encoding size"},
+		{"count exceeds data", selectionBytes(4, 1, 1, 0, 0, 1, 1000), "exceeds data size"},
+		{"irregular v2", concatBytes(selectionBytes(4, 2, 2), []byte{0}, selectionBytes(4, 0, 1)), "must be regular"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := ParseSelection(tt.data)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
+
+func TestSelectionRuns(t *testing.T) {
+	u := SelectionUnlimited
+	run := func(length uint64, coords ...uint64) SelectionRun {
+		return SelectionRun{Coords: coords, Length: length}
+	}
+
+	tests := []struct {
+		name string
+		sel  *Selection
+		dims []uint64
+		want []SelectionRun
+	}{
+		{
+			name: "all",
+			sel:  &Selection{Type: SelectionAll},
+			dims: []uint64{2, 3},
+			want: []SelectionRun{run(3, 0, 0), run(3, 1, 0)},
+		},
+		{
+			name: "all scalar",
+			sel:  &Selection{Type: SelectionAll},
+			want: []SelectionRun{{Coords: []uint64{}, Length: 1}},
+		},
+		{
+			name: "none",
+			sel:  &Selection{Type: SelectionNone},
+			dims: []uint64{4},
+		},
+		{
+			name: "points keep order",
+			sel:  &Selection{Type: SelectionPoints, Rank: 2, Points: [][]uint64{{3, 1}, {0, 2}}},
+			dims: []uint64{4, 4},
+			want: []SelectionRun{run(1, 3, 1), run(1, 0, 2)},
+		},
+		{
+			name: "regular strided",
+			sel: &Selection{Type: SelectionHyperslabs, Rank: 2, Regular: []HyperslabDim{
+				{Start: 1, Stride: 2, Count: 2, Block: 1},
+				{Start: 0, Stride: 5, Count: 2, Block: 2},
+			}},
+			dims: []uint64{4, 8},
+			want: []SelectionRun{run(2, 1, 0), run(2, 1, 5), run(2, 3, 0), run(2, 3, 5)},
+		},
+		{
+			name: "overlapping blocks merge",
+			sel:  &Selection{Type: SelectionHyperslabs, Rank: 1, Regular: []HyperslabDim{{Start: 0, Stride: 1, Count: 3, Block: 2}}},
+			dims: []uint64{8},
+			want: []SelectionRun{run(4, 0)},
+		},
+		{
+			name: "unlimited count clipped",
+			sel:  &Selection{Type: SelectionHyperslabs, Rank: 1, Regular: []HyperslabDim{{Start: 1, Stride: 4, Count: u, Block: 2}}},
+			dims: []uint64{10},
+			want: []SelectionRun{run(2, 1), run(2, 5), run(1, 9)},
+		},
+		{
+			name: "unlimited block",
+			sel:  &Selection{Type: SelectionHyperslabs, Rank: 1, Regular: []HyperslabDim{{Start: 3, Stride: 1, Count: 1, Block: u}}},
+			dims: []uint64{7},
+			want: []SelectionRun{run(4, 3)},
+		},
+		{
+			name: "blocks sorted",
+			sel: &Selection{Type: SelectionHyperslabs, Rank: 2, Blocks: []SelectionBlock{
+				{Start: []uint64{2, 0}, End: []uint64{2, 1}},
+				{Start: []uint64{0, 3}, End: []uint64{1, 3}},
+			}},
+			dims: []uint64{3, 4},
+			want: []SelectionRun{run(1, 0, 3), run(1, 1, 3), run(2, 2, 0)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.sel.Runs(tt.dims)
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestSelectionRuns_Errors(t *testing.T) {
+	sel := &Selection{Type: SelectionPoints, Rank: 2, Points: [][]uint64{{0, 0}}}
+	_, err := sel.Runs([]uint64{4})
+	require.ErrorContains(t, err, "rank 2 does not match dataspace rank 1")
+
+	sel = &Selection{Type: SelectionHyperslabs, Rank: 1, Regular: []HyperslabDim{{Stride: 1, Count: SelectionUnlimited, Block: 1}}}
+	_, err = sel.Runs([]uint64{1 << 40})
+	require.ErrorContains(t, err, "too many blocks")
+
+	sel = &Selection{Type: SelectionHyperslabs, Rank: 2, Regular: []HyperslabDim{
+		{Stride: 1, Count: 1, Block: 1 << 20},
+		{Stride: 2, Count: 1 << 10, Block: 1},
+	}}
+	_, err = sel.Runs([]uint64{1 << 20, 1 << 11})
+	require.ErrorContains(t, err, "too many runs")
+}
+
+func TestSelectionBounds(t *testing.T) {
+	u := SelectionUnlimited
+
+	sel := &Selection{Type: SelectionHyperslabs, Rank: 2, Regular: []HyperslabDim{
+		{Start: 2, Stride: 3, Count: u, Block: 1},
+		{Start: 1, Stride: 4, Count: 3, Block: 2},
+	}}
+	require.Equal(t, []uint64{2, 11}, sel.Bounds())
+	require.Equal(t, 0, sel.UnlimitedDim())
+
+	sel = &Selection{Type: SelectionPoints, Rank: 2, Points: [][]uint64{{3, 1}, {0, 5}}}
+	require.Equal(t, []uint64{4, 6}, sel.Bounds())
+	require.Equal(t, -1, sel.UnlimitedDim())
+
+	n, err := sel.NumElements([]uint64{4, 6})
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), n)
+
+	require.Nil(t, (&Selection{Type: SelectionAll}).Bounds())
+}
+
+func TestEncodeSelection(t *testing.T) {
+	u := SelectionUnlimited
+	big := uint64(1) << 40
+
+	tests := []struct {
+		name    string
+		sel     *Selection
+		version uint32
+	}{
+		{"all", &Selection{Type: SelectionAll}, 1},
+		{"none", &Selection{Type: SelectionNone}, 1},
+		{"points", &Selection{Type: SelectionPoints, Rank: 2, Points: [][]uint64{{4, 1}, {0, 2}}}, 1},
+		{"large points", &Selection{Type: SelectionPoints, Rank: 1, Points: [][]uint64{{big}}}, 2},
+		{"regular", &Selection{Type: SelectionHyperslabs, Rank: 2, Regular: []HyperslabDim{
+			{Start: 0, Stride: 4, Count: u, Block: 2},
+			{Start: 1, Stride: 1, Count: 1, Block: 5},
+		}}, 2},
+		{"blocks", &Selection{Type: SelectionHyperslabs, Rank: 1, Blocks: []SelectionBlock{
+			{Start: []uint64{0}, End: []uint64{3}},
+		}}, 1},
+		{"large blocks", &Selection{Type: SelectionHyperslabs, Rank: 1, Blocks: []SelectionBlock{
+			{Start: []uint64{big}, End: []uint64{big + 1}},
+		}}, 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := EncodeSelection(tt.sel)
+			require.NoError(t, err)
+			require.Equal(t, tt.version, binary.LittleEndian.Uint32(data[4:8]))
+
+			got, n, err := ParseSelection(data)
+			require.NoError(t, err)
+			require.Equal(t, len(data), n)
+			require.Equal(t, tt.sel, got)
+		})
+	}
+
+	_, err := EncodeSelection(&Selection{Type: SelectionPoints, Rank: 2, Points: [][]uint64{{1}}})
+	require.ErrorContains(t, err, "do not match rank")
+	_, err = EncodeSelection(&Selection{Type: SelectionHyperslabs, Rank: 2, Regular: []HyperslabDim{{}}})
+	require.ErrorContains(t, err, "has 1 dimensions")
+	_, err = EncodeSelection(&Selection{Type: 9})
+	require.ErrorContains(t, err, "unsupported selection type")
+}
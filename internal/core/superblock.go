@@ -16,6 +16,7 @@ import (
 const (
 	Signature = "\x89HDF\r\n\x1a\n"
 	Version0  = 0
+	Version1  = 1
 	Version2  = 2
 	Version3  = 3
 )
@@ -29,16 +30,31 @@ type Superblock struct {
 	RootGroup      uint64
 	Endianness     binary.ByteOrder
 	SuperExtension uint64
-	DriverInfo     uint64
-
-	// V0-specific: Cached symbol table info for root group
-	// These are only used when Version == 0
-	RootBTreeAddr uint64 // B-tree address for root group (v0 only)
-	RootHeapAddr  uint64 // Local heap address for root group (v0 only)
+	DriverInfo     uint64 // Driver information block address (v0/v1 only, 0 or UNDEF for none)
+
+	// V0/V1-specific: Cached symbol table info for root group
+	// These are only used when Version is 0 or 1
+	RootBTreeAddr uint64 // B-tree address for root group (v0/v1 only)
+	RootHeapAddr  uint64 // Local heap address for root group (v0/v1 only)
+
+	// B-tree K values. Superblocks v0 and v1 store the group K values and v1
+	// also the indexed storage K; ReadSuperblock fills in the HDF5 defaults
+	// for values a superblock does not store.
+	SymbolLeafK     uint16 // Group leaf node K: symbol table nodes hold up to 2K entries
+	SymbolInternalK uint16 // Group internal node K: group B-tree nodes hold up to 2K children
+	IndexedStorageK uint16 // Chunk B-tree internal node K: nodes hold up to 2K children
 }
 
+// Default B-tree K values (H5F_CRT_SYM_LEAF_DEF, H5B_SNODE_IK_DEF and
+// H5D_BTREE_K in the HDF5 library).
+const (
+	DefaultSymbolLeafK     = 4
+	DefaultSymbolInternalK = 16
+	DefaultIndexedStorageK = 32
+)
+
 // ReadSuperblock reads and parses the HDF5 superblock from the file.
-// It supports versions 0, 2, and 3 of the superblock format.
+// It supports versions 0, 1, 2, and 3 of the superblock format.
 func ReadSuperblock(r io.ReaderAt) (*Superblock, error) {
 	buf := utils.GetBuffer(128)
 	defer utils.ReleaseBuffer(buf)
@@ -56,16 +72,16 @@ func ReadSuperblock(r io.ReaderAt) (*Superblock, error) {
 	}
 
 	version := buf[8]
-	if version != Version0 && version != Version2 && version != Version3 {
-		return nil, fmt.Errorf("unsupported superblock version: %d (only 0, 2, 3 supported)", version)
+	if version > Version3 {
+		return nil, fmt.Errorf("unsupported superblock version: %d (only 0, 1, 2, 3 supported)", version)
 	}
 
 	// Endianness and size handling depends on version
 	var endianness binary.ByteOrder
 	var offsetSize, lengthSize uint8
 
-	if version == Version0 {
-		// For v0: sizes in bytes 13-14, endianness presumably little-endian (check spec)
+	if version <= Version1 {
+		// For v0/v1: sizes in bytes 13-14, endianness presumably little-endian (check spec)
 		offsetSize = buf[13]
 		lengthSize = buf[14]
 		endianness = binary.LittleEndian // v0 files are typically little-endian
@@ -149,15 +165,21 @@ func ReadSuperblock(r io.ReaderAt) (*Superblock, error) {
 	}
 
 	sb := &Superblock{
-		Version:    version,
-		OffsetSize: offsetSize,
-		LengthSize: lengthSize,
-		Endianness: endianness,
+		Version:         version,
+		OffsetSize:      offsetSize,
+		LengthSize:      lengthSize,
+		Endianness:      endianness,
+		SymbolLeafK:     DefaultSymbolLeafK,
+		SymbolInternalK: DefaultSymbolInternalK,
+		IndexedStorageK: DefaultIndexedStorageK,
 	}
 
-	if version == Version0 {
+	if version <= Version1 {
 		sb.BaseAddress = 0
-		// Version 0 superblock structure:
+		// Version 0 superblock structure (offsets for 8-byte addresses):
+		// Offset 16-17: Group leaf node K
+		// Offset 18-19: Group internal node K
+		// Offset 20-23: File consistency flags
 		// Offset 24-31: Base address
 		// Offset 32-39: Free space index
 		// Offset 40-47: End-of-File address (NOT root group!)
@@ -169,22 +191,46 @@ func ReadSuperblock(r io.ReaderAt) (*Superblock, error) {
 		//   76-79: Reserved (4 bytes)
 		//   80-87: B-tree address (8 bytes) - for cached symbol table
 		//   88-95: Local heap address (8 bytes) - for cached symbol table
+		//
+		// Version 1 inserts the indexed storage internal node K (2 bytes) and
+		// 2 reserved bytes after the flags, moving everything after by 4.
+		// Zero K values (seen in test files) keep the defaults.
+		if k := binary.LittleEndian.Uint16(buf[16:18]); k != 0 {
+			sb.SymbolLeafK = k
+		}
+		if k := binary.LittleEndian.Uint16(buf[18:20]); k != 0 {
+			sb.SymbolInternalK = k
+		}
+		base := 24
+		if version == Version1 {
+			if k := binary.LittleEndian.Uint16(buf[24:26]); k != 0 {
+				sb.IndexedStorageK = k
+			}
+			base = 28
+		}
+		rootEntry := base + 4*int(offsetSize)
+
+		sb.DriverInfo, err = readValue(base+3*int(offsetSize), offsetSize)
+		if err != nil {
+			return nil, utils.WrapError("driver info address read failed", err)
+		}
 
-		// Read object header address at offset 64
-		sb.RootGroup, err = readValue(64, offsetSize)
+		// Read object header address of the root symbol table entry
+		sb.RootGroup, err = readValue(rootEntry+int(offsetSize), offsetSize)
 		if err != nil {
 			return nil, utils.WrapError("root group address read failed", err)
 		}
 
-		// ALWAYS read cached B-tree and Heap addresses for v0 files
+		// ALWAYS read cached B-tree and Heap addresses for v0/v1 files
 		// These are stored in the scratch-pad area when cache type = 1 (H5G_CACHED_STAB)
 		// Even if object header address is non-zero, the symbol table may use these
-		sb.RootBTreeAddr, err = readValue(80, offsetSize)
+		scratch := rootEntry + 2*int(offsetSize) + 8
+		sb.RootBTreeAddr, err = readValue(scratch, offsetSize)
 		if err != nil {
 			return nil, utils.WrapError("b-tree address read failed", err)
 		}
 
-		sb.RootHeapAddr, err = readValue(88, offsetSize)
+		sb.RootHeapAddr, err = readValue(scratch+int(offsetSize), offsetSize)
 		if err != nil {
 			return nil, utils.WrapError("heap address read failed", err)
 		}
@@ -240,15 +286,17 @@ func ReadSuperblock(r io.ReaderAt) (*Superblock, error) {
 //
 // Returns error if write fails or if superblock version is not supported.
 func (sb *Superblock) WriteTo(w io.WriterAt, eofAddress uint64) error {
-	// Support v0 (legacy), v2 (modern), and v3 (modern with file locking)
+	// Support v0 (legacy), v1 (legacy with indexed storage K), v2 (modern),
+	// and v3 (modern with file locking)
 	// Note: v2 and v3 use the same structure (v3 just has different flags in byte 11)
-	if sb.Version != Version0 && sb.Version != Version2 && sb.Version != Version3 {
-		return fmt.Errorf("only superblock version 0, 2, and 3 are supported for writing, got version %d", sb.Version)
+	if sb.Version > Version3 {
+		return fmt.Errorf("only superblock version 0, 1, 2, and 3 are supported for writing, got version %d", sb.Version)
 	}
 
 	// Dispatch to version-specific writer
 	switch sb.Version {
-	case Version0:
+	case Version0, Version1:
+		// v1 only adds the indexed storage K, writeV0 handles both
 		return sb.writeV0(w, eofAddress)
 	case Version2, Version3:
 		// v2 and v3 use the same structure, writeV2 handles both
@@ -318,7 +366,22 @@ func (sb *Superblock) writeV2(w io.WriterAt, eofAddress uint64) error {
 	return nil
 }
 
-// writeV0 writes superblock version 0 (legacy format for maximum compatibility).
+// SuperblockSize returns the size of a superblock of the given version with
+// 8-byte offsets and lengths and no driver information block, as written by
+// WriteTo.
+func SuperblockSize(version uint8) uint64 {
+	switch version {
+	case Version0:
+		return 96
+	case Version1:
+		return 100
+	default:
+		return 48
+	}
+}
+
+// writeV0 writes superblock version 0 (legacy format for maximum compatibility)
+// or version 1, which adds the indexed storage K.
 // This format is used by older HDF5 tools and is the most widely supported.
 //
 // Superblock v0 structure (96 bytes minimum):
@@ -338,8 +401,16 @@ func (sb *Superblock) writeV2(w io.WriterAt, eofAddress uint64) error {
 //	Bytes 24-31: Base Address (0)
 //	Bytes 32-39: Free Space Info Address (UNDEF)
 //	Bytes 40-47: End of File Address
-//	Bytes 48-55: Driver Info Block Address (UNDEF)
+//	Bytes 48-55: Driver Info Block Address (UNDEF if none)
 //	Bytes 56-95: Root Group Symbol Table Entry (40 bytes)
+//
+// Superblock v1 (100 bytes) inserts two fields after the flags and moves the
+// rest by 4 bytes:
+//
+//	Bytes 24-25: Indexed Storage Internal Node K (32)
+//	Bytes 26-27: Reserved (0)
+//
+// K values left zero are written as the HDF5 defaults.
 func (sb *Superblock) writeV0(w io.WriterAt, eofAddress uint64) error {
 	// Validate required fields
 	if sb.OffsetSize != 8 || sb.LengthSize != 8 {
@@ -347,14 +418,22 @@ func (sb *Superblock) writeV0(w io.WriterAt, eofAddress uint64) error {
 			sb.OffsetSize, sb.LengthSize)
 	}
 
-	// Allocate buffer for superblock v0 (96 bytes)
-	buf := make([]byte, 96)
+	orDefault := func(k, def uint16) uint16 {
+		if k == 0 {
+			return def
+		}
+		return k
+	}
+
+	// Allocate buffer for superblock v0 (96 bytes) or v1 (100 bytes)
+	size := SuperblockSize(sb.Version)
+	buf := make([]byte, size)
 
 	// Bytes 0-7: Signature
 	copy(buf[0:8], Signature)
 
-	// Byte 8: Version 0
-	buf[8] = 0
+	// Byte 8: Version (0 or 1)
+	buf[8] = sb.Version
 
 	// Byte 9: Free-space Storage Version (0)
 	buf[9] = 0
@@ -378,25 +457,36 @@ func (sb *Superblock) writeV0(w io.WriterAt, eofAddress uint64) error {
 	buf[15] = 0
 
 	// Bytes 16-17: Group Leaf Node K (default: 4)
-	binary.LittleEndian.PutUint16(buf[16:18], 4)
+	binary.LittleEndian.PutUint16(buf[16:18], orDefault(sb.SymbolLeafK, DefaultSymbolLeafK))
 
 	// Bytes 18-19: Group Internal Node K (default: 16)
-	binary.LittleEndian.PutUint16(buf[18:20], 16)
+	binary.LittleEndian.PutUint16(buf[18:20], orDefault(sb.SymbolInternalK, DefaultSymbolInternalK))
 
 	// Bytes 20-23: File Consistency Flags (0 = file is closed properly)
 	binary.LittleEndian.PutUint32(buf[20:24], 0)
 
+	// v1: Bytes 24-25 Indexed Storage Internal Node K (default: 32), 26-27 reserved
+	p := buf[24:]
+	if sb.Version == Version1 {
+		binary.LittleEndian.PutUint16(p[0:2], orDefault(sb.IndexedStorageK, DefaultIndexedStorageK))
+		p = p[4:]
+	}
+
 	// Bytes 24-31: Base address (typically 0)
-	binary.LittleEndian.PutUint64(buf[24:32], sb.BaseAddress)
+	binary.LittleEndian.PutUint64(p[0:8], sb.BaseAddress)
 
 	// Bytes 32-39: Free Space Info Address (UNDEF for now)
-	binary.LittleEndian.PutUint64(buf[32:40], 0xFFFFFFFFFFFFFFFF)
+	binary.LittleEndian.PutUint64(p[8:16], 0xFFFFFFFFFFFFFFFF)
 
 	// Bytes 40-47: End-of-file address
-	binary.LittleEndian.PutUint64(buf[40:48], eofAddress)
+	binary.LittleEndian.PutUint64(p[16:24], eofAddress)
 
-	// Bytes 48-55: Driver Info Block Address (UNDEF)
-	binary.LittleEndian.PutUint64(buf[48:56], 0xFFFFFFFFFFFFFFFF)
+	// Bytes 48-55: Driver Info Block Address (UNDEF if none)
+	driverInfo := sb.DriverInfo
+	if driverInfo == 0 {
+		driverInfo = 0xFFFFFFFFFFFFFFFF // UNDEF
+	}
+	binary.LittleEndian.PutUint64(p[24:32], driverInfo)
 
 	// Bytes 56-95: Root Group Symbol Table Entry (40 bytes)
 	// This is a Symbol Table Entry with cached B-tree/Heap addresses
@@ -409,32 +499,33 @@ func (sb *Superblock) writeV0(w io.WriterAt, eofAddress uint64) error {
 	//   Bytes 24-39: Scratch-pad space (16 bytes):
 	//     - Bytes 24-31: B-tree address (for H5G_CACHED_STAB)
 	//     - Bytes 32-39: Local heap address (for H5G_CACHED_STAB)
+	entry := p[32:72]
 
 	// Link Name Offset (0 for root group)
-	binary.LittleEndian.PutUint64(buf[56:64], 0)
+	binary.LittleEndian.PutUint64(entry[0:8], 0)
 
 	// Object Header Address (root group address)
-	binary.LittleEndian.PutUint64(buf[64:72], sb.RootGroup)
+	binary.LittleEndian.PutUint64(entry[8:16], sb.RootGroup)
 
 	// Cache Type (1 = H5G_CACHED_STAB, meaning symbol table with cached addresses)
-	binary.LittleEndian.PutUint32(buf[72:76], 1)
+	binary.LittleEndian.PutUint32(entry[16:20], 1)
 
 	// Reserved
-	binary.LittleEndian.PutUint32(buf[76:80], 0)
+	binary.LittleEndian.PutUint32(entry[20:24], 0)
 
 	// Scratch-pad space (16 bytes): Cached B-tree and Heap addresses
 	// This is CRITICAL for v0 - h5dump needs these to find the root group!
-	binary.LittleEndian.PutUint64(buf[80:88], sb.RootBTreeAddr) // B-tree address
-	binary.LittleEndian.PutUint64(buf[88:96], sb.RootHeapAddr)  // Heap address
+	binary.LittleEndian.PutUint64(entry[24:32], sb.RootBTreeAddr) // B-tree address
+	binary.LittleEndian.PutUint64(entry[32:40], sb.RootHeapAddr)  // Heap address
 
 	// Write superblock at offset 0
 	n, err := w.WriteAt(buf, 0)
 	if err != nil {
-		return fmt.Errorf("failed to write superblock v0: %w", err)
+		return fmt.Errorf("failed to write superblock v%d: %w", sb.Version, err)
 	}
 
-	if n != 96 {
-		return fmt.Errorf("incomplete superblock v0 write: wrote %d bytes, expected 96", n)
+	if uint64(n) != size {
+		return fmt.Errorf("incomplete superblock v%d write: wrote %d bytes, expected %d", sb.Version, n, size)
 	}
 
 	return nil
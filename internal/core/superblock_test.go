@@ -241,14 +241,14 @@ func TestSuperblockWrite(t *testing.T) {
 		defer tmpFile.Close()
 
 		sb := &Superblock{
-			Version:    1, // v1 not supported (only v0, v2, and v3 are supported).
+			Version:    4, // Only v0 to v3 are supported.
 			OffsetSize: 8,
 			LengthSize: 8,
 		}
 
 		err = sb.WriteTo(tmpFile, 1024)
 		assert.Error(t, err)
-		assert.Contains(t, err.Error(), "only superblock version 0, 2, and 3 are supported")
+		assert.Contains(t, err.Error(), "only superblock version 0, 1, 2, and 3 are supported")
 	})
 
 	t.Run("rejects invalid sizes", func(t *testing.T) {
@@ -151,3 +151,52 @@ func TestSuperblock_WriteV0_SymbolTableEntry(t *testing.T) {
 	cacheType := binary.LittleEndian.Uint32(symEntry[16:20])
 	require.Equal(t, uint32(1), cacheType, "cache type should be 1 for group")
 }
+
+// TestSuperblock_V1RoundTrip tests that v1 superblocks keep their K values
+// and the root symbol table entry moved by the indexed storage K.
+func TestSuperblock_V1RoundTrip(t *testing.T) {
+	sb := &Superblock{
+		Version:         Version1,
+		OffsetSize:      8,
+		LengthSize:      8,
+		RootGroup:       0x64,
+		RootBTreeAddr:   0x200,
+		RootHeapAddr:    0x300,
+		SymbolLeafK:     8,
+		SymbolInternalK: 20,
+		IndexedStorageK: 2,
+	}
+
+	buf := &memWriterAt{data: make([]byte, 0)}
+	require.NoError(t, sb.WriteTo(buf, 0x1000))
+	require.Len(t, buf.data, int(SuperblockSize(Version1)))
+	require.Equal(t, uint16(2), binary.LittleEndian.Uint16(buf.data[24:26]))
+	require.Equal(t, uint64(0x1000), binary.LittleEndian.Uint64(buf.data[44:52]))
+
+	got, err := ReadSuperblock(buf)
+	require.NoError(t, err)
+	require.Equal(t, uint8(Version1), got.Version)
+	require.Equal(t, sb.RootGroup, got.RootGroup)
+	require.Equal(t, sb.RootBTreeAddr, got.RootBTreeAddr)
+	require.Equal(t, sb.RootHeapAddr, got.RootHeapAddr)
+	require.Equal(t, uint16(8), got.SymbolLeafK)
+	require.Equal(t, uint16(20), got.SymbolInternalK)
+	require.Equal(t, uint16(2), got.IndexedStorageK)
+}
+
+// TestReadSuperblock_DefaultK tests the K values of superblocks that do not
+// store them.
+func TestReadSuperblock_DefaultK(t *testing.T) {
+	for _, version := range []uint8{Version0, Version2} {
+		sb := &Superblock{Version: version, OffsetSize: 8, LengthSize: 8, RootGroup: 0x60}
+
+		buf := &memWriterAt{data: make([]byte, 0)}
+		require.NoError(t, sb.WriteTo(buf, 0x1000))
+
+		got, err := ReadSuperblock(buf)
+		require.NoError(t, err)
+		require.Equal(t, uint16(DefaultSymbolLeafK), got.SymbolLeafK, "version %d", version)
+		require.Equal(t, uint16(DefaultSymbolInternalK), got.SymbolInternalK, "version %d", version)
+		require.Equal(t, uint16(DefaultIndexedStorageK), got.IndexedStorageK, "version %d", version)
+	}
+}
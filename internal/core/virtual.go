@@ -0,0 +1,584 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/utils"
+)
+
+// virtualMappingsVersion is the encoding version of the virtual dataset
+// mapping list (H5O_LAYOUT_VDS_GH_ENC_VERS_0).
+const virtualMappingsVersion = 0
+
+// VirtualSameFile is the source file name of mappings whose source dataset
+// is in the virtual dataset's own file.
+const VirtualSameFile = "."
+
+// maxVirtualSourceBlocks limits the number of source datasets a printf-style
+// mapping is expanded to.
+const maxVirtualSourceBlocks = 1 << 20
+
+// VirtualMapping is one entry of a virtual dataset's mapping list: the
+// elements SourceSelection selects in dataset SourceDataset of file
+// SourceFile appear at the elements VirtualSelection selects in the virtual
+// dataset.
+//
+// Source names are printf-style: "%%" stands for a literal "%", and in
+// mappings with an unlimited virtual selection and a bounded source
+// selection "%b" is replaced by the block number, mapping a series of
+// source datasets to consecutive blocks of the virtual selection.
+type VirtualMapping struct {
+	SourceFile       string
+	SourceDataset    string
+	SourceSelection  *Selection
+	VirtualSelection *Selection
+}
+
+// VirtualSource is an opened source dataset of a virtual dataset: its object
+// header and the reader and superblock of the file containing it.
+type VirtualSource struct {
+	Reader     io.ReaderAt
+	Header     *ObjectHeader
+	Superblock *Superblock
+}
+
+// VirtualSourceResolver opens the source datasets of virtual datasets.
+// Virtual datasets can be read through readers that implement it.
+type VirtualSourceResolver interface {
+	// ResolveVirtualSource opens dataset datasetName in file fileName, both
+	// as named in a mapping (after printf-style expansion). It returns nil
+	// and no error if the file or dataset does not exist; the mapped
+	// elements then read as the fill value.
+	ResolveVirtualSource(fileName, datasetName string) (*VirtualSource, error)
+}
+
+// ParseVirtualMappings decodes the mapping list of a virtual dataset, stored
+// as a global heap object.
+//
+// Format: version (1 byte, 0), number of entries (length-size bytes), then
+// per entry the null-terminated source file and dataset names followed by
+// the serialized source and virtual selections; a checksum closes the block.
+//
+// Reference: H5Olayout.c - H5O__layout_decode(), H5Dvirtual.c -
+// H5D__virtual_store_layout().
+func ParseVirtualMappings(data []byte, sb *Superblock) ([]VirtualMapping, error) {
+	headerSize := 1 + int(sb.LengthSize)
+	if len(data) < headerSize+4 {
+		return nil, errors.New("virtual dataset mapping list too short")
+	}
+	if data[0] != virtualMappingsVersion {
+		return nil, fmt.Errorf("unsupported virtual dataset mapping list version: %d", data[0])
+	}
+	if err := verifyChecksum(data, 0, "virtual dataset mapping list"); err != nil {
+		return nil, err
+	}
+
+	body := data[:len(data)-4]
+	numEntries := readUint64(body[1:], int(sb.LengthSize), sb.Endianness)
+	offset := headerSize
+
+	// Each entry needs at least two empty names and two 16-byte selections.
+	if numEntries > uint64(len(body)-offset)/34 {
+		return nil, fmt.Errorf("virtual dataset mapping count %d exceeds data size", numEntries)
+	}
+
+	mappings := make([]VirtualMapping, numEntries)
+	for i := range mappings {
+		m := &mappings[i]
+
+		var err error
+		if m.SourceFile, offset, err = readVirtualName(body, offset); err != nil {
+			return nil, fmt.Errorf("mapping %d source file: %w", i, err)
+		}
+		if m.SourceDataset, offset, err = readVirtualName(body, offset); err != nil {
+			return nil, fmt.Errorf("mapping %d source dataset: %w", i, err)
+		}
+
+		var n int
+		if m.SourceSelection, n, err = ParseSelection(body[offset:]); err != nil {
+			return nil, fmt.Errorf("mapping %d source selection: %w", i, err)
+		}
+		offset += n
+		if m.VirtualSelection, n, err = ParseSelection(body[offset:]); err != nil {
+			return nil, fmt.Errorf("mapping %d virtual selection: %w", i, err)
+		}
+		offset += n
+	}
+
+	return mappings, nil
+}
+
+// readVirtualName reads a null-terminated name at offset.
+func readVirtualName(data []byte, offset int) (string, int, error) {
+	end := bytes.IndexByte(data[offset:], 0)
+	if end < 0 {
+		return "", 0, errors.New("name not terminated")
+	}
+	return string(data[offset : offset+end]), offset + end + 1, nil
+}
+
+// ReadVirtualMappings reads the mapping list of a virtual layout from the
+// global heap. A virtual dataset without mappings has an undefined heap
+// address.
+func ReadVirtualMappings(r io.ReaderAt, layout *DataLayoutMessage, sb *Superblock) ([]VirtualMapping, error) {
+	if !layout.IsVirtual() {
+		return nil, errors.New("not a virtual layout")
+	}
+	if isUndefinedAddress(layout.DataAddress, sb.OffsetSize) {
+		return nil, nil
+	}
+
+	collection, err := ReadGlobalHeapCollection(r, layout.DataAddress, int(sb.OffsetSize))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read virtual dataset mapping list: %w", err)
+	}
+	obj, err := collection.GetObject(layout.HeapIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read virtual dataset mapping list: %w", err)
+	}
+
+	return ParseVirtualMappings(obj.Data, sb)
+}
+
+// EncodeVirtualMappings encodes the mapping list of a virtual dataset in the
+// format read by ParseVirtualMappings, to be stored as a global heap object.
+//
+// Reference: H5Dvirtual.c - H5D__virtual_store_layout().
+func EncodeVirtualMappings(mappings []VirtualMapping, sb *Superblock) ([]byte, error) {
+	buf := make([]byte, 1+int(sb.LengthSize))
+	buf[0] = virtualMappingsVersion
+	writeUint64(buf[1:], uint64(len(mappings)), int(sb.LengthSize), sb.Endianness)
+
+	for i := range mappings {
+		m := &mappings[i]
+		for _, name := range []string{m.SourceFile, m.SourceDataset} {
+			if name == "" || strings.IndexByte(name, 0) >= 0 {
+				return nil, fmt.Errorf("mapping %d: invalid source name %q", i, name)
+			}
+			if _, err := expandVirtualName(name, 0, true); err != nil {
+				return nil, fmt.Errorf("mapping %d: %w", i, err)
+			}
+			buf = append(buf, name...)
+			buf = append(buf, 0)
+		}
+
+		for _, sel := range []*Selection{m.SourceSelection, m.VirtualSelection} {
+			data, err := EncodeSelection(sel)
+			if err != nil {
+				return nil, fmt.Errorf("mapping %d: %w", i, err)
+			}
+			buf = append(buf, data...)
+		}
+	}
+
+	return binary.LittleEndian.AppendUint32(buf, utils.JenkinsChecksum(buf)), nil
+}
+
+// expandVirtualName expands a printf-style source name: "%b" becomes block
+// (if expand is set) and "%%" a literal "%".
+// Reference: H5Dvirtual.c - H5D__virtual_parse_source_name().
+func expandVirtualName(name string, block uint64, expand bool) (string, error) {
+	if !strings.Contains(name, "%") {
+		return name, nil
+	}
+
+	var sb strings.Builder
+	for i := 0; i < len(name); i++ {
+		if name[i] != '%' {
+			sb.WriteByte(name[i])
+			continue
+		}
+		i++
+		switch {
+		case i < len(name) && name[i] == '%':
+			sb.WriteByte('%')
+		case i < len(name) && name[i] == 'b' && expand:
+			sb.WriteString(strconv.FormatUint(block, 10))
+		default:
+			return "", fmt.Errorf("invalid format specifier in source name %q", name)
+		}
+	}
+	return sb.String(), nil
+}
+
+// VirtualNameHasBlock reports whether a printf-style source name contains
+// the block number specifier "%b".
+func VirtualNameHasBlock(name string) bool {
+	for i := 0; i+1 < len(name); i++ {
+		if name[i] == '%' {
+			if name[i+1] == 'b' {
+				return true
+			}
+			i++ // Skip the specifier, such as the second '%' of "%%".
+		}
+	}
+	return false
+}
+
+// virtualDataset is a virtual dataset whose mappings were resolved against
+// the source datasets.
+type virtualDataset struct {
+	dims   []uint64      // Current extent.
+	copies []virtualCopy // Source elements to copy, in mapping order.
+}
+
+// virtualCopy maps the elements of one source dataset into the virtual
+// dataset. Selections are expanded with the given extents, which clip
+// unlimited selections to the available data.
+type virtualCopy struct {
+	source      *virtualSource
+	sourceSel   *Selection
+	virtualSel  *Selection
+	virtualDims []uint64
+}
+
+// virtualSource is an opened source dataset with its parsed messages.
+type virtualSource struct {
+	*VirtualSource
+	msgs *datasetMessages
+	data []byte // Raw data, read on first use.
+}
+
+// virtualSourceSet opens each source dataset of a virtual dataset once.
+type virtualSourceSet struct {
+	resolver VirtualSourceResolver
+	sources  map[[2]string]*virtualSource
+}
+
+// open returns the source dataset, or nil if it does not exist.
+func (s *virtualSourceSet) open(fileName, datasetName string) (*virtualSource, error) {
+	key := [2]string{fileName, datasetName}
+	if src, ok := s.sources[key]; ok {
+		return src, nil
+	}
+
+	resolved, err := s.resolver.ResolveVirtualSource(fileName, datasetName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open source dataset %q in %q: %w", datasetName, fileName, err)
+	}
+
+	var src *virtualSource
+	if resolved != nil {
+		msgs, err := loadDatasetMessages(resolved.Reader, resolved.Header, resolved.Superblock)
+		if err != nil {
+			return nil, fmt.Errorf("source dataset %q in %q: %w", datasetName, fileName, err)
+		}
+		src = &virtualSource{VirtualSource: resolved, msgs: msgs}
+	}
+
+	s.sources[key] = src
+	return src, nil
+}
+
+// openVirtualDataset reads the mappings of a virtual dataset, opens its
+// source datasets and determines the current extent.
+//
+// Dimensions mapped by unlimited selections grow with the source data: each
+// such mapping extends the dimension to the end of the last source data it
+// maps (the H5D_VDS_LAST_AVAILABLE view), and printf-style mappings map
+// source datasets for consecutive blocks until the first missing one.
+//
+// Reference: H5Dvirtual.c - H5D__virtual_set_extent_unlim().
+func openVirtualDataset(r io.ReaderAt, msgs *datasetMessages, sb *Superblock) (*virtualDataset, error) {
+	resolver, ok := r.(VirtualSourceResolver)
+	if !ok {
+		return nil, errors.New("virtual dataset sources cannot be resolved")
+	}
+
+	mappings, err := ReadVirtualMappings(r, msgs.layout, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	vds := &virtualDataset{dims: append([]uint64(nil), msgs.dataspace.Dimensions...)}
+	sources := &virtualSourceSet{resolver: resolver, sources: make(map[[2]string]*virtualSource)}
+
+	rank := len(vds.dims)
+	extent := make([]uint64, rank)    // Extent of dimensions with unlimited mappings.
+	unlimited := make([]bool, rank)   // Dimensions with unlimited mappings.
+	minExtent := make([]uint64, rank) // Extent needed by the bounded dimensions of all mappings.
+
+	for i := range mappings {
+		m := &mappings[i]
+		vsel, ssel := m.VirtualSelection, m.SourceSelection
+		if vsel.Type != SelectionAll && vsel.Rank != rank {
+			return nil, fmt.Errorf("mapping %d: virtual selection rank %d does not match dataset rank %d", i, vsel.Rank, rank)
+		}
+		vdim, sdim := vsel.UnlimitedDim(), ssel.UnlimitedDim()
+		for d, b := range vsel.Bounds() {
+			if d != vdim {
+				minExtent[d] = max(minExtent[d], b)
+			}
+		}
+
+		switch {
+		case vdim < 0:
+			// Bounded mapping.
+			copies, err := vds.mapSource(sources, m, 0, false, vsel)
+			if err != nil {
+				return nil, fmt.Errorf("mapping %d: %w", i, err)
+			}
+			vds.copies = append(vds.copies, copies...)
+
+		case sdim >= 0:
+			// Unlimited source and virtual selections: the virtual
+			// selection extends as far as the source data reaches.
+			src, err := sources.openMapping(m, 0, false)
+			if err != nil {
+				return nil, fmt.Errorf("mapping %d: %w", i, err)
+			}
+			var sourceExtent uint64
+			if src != nil {
+				srcDims := src.msgs.dataspace.Dimensions
+				if sdim >= len(srcDims) {
+					return nil, fmt.Errorf("mapping %d: source selection rank does not match source dataset", i)
+				}
+				sourceExtent = srcDims[sdim]
+			}
+
+			clip := clipExtentMatch(vsel.Regular[vdim], ssel.Regular[sdim], sourceExtent)
+			extent[vdim] = max(extent[vdim], clip)
+			unlimited[vdim] = true
+
+			if src != nil {
+				virtualDims := append([]uint64(nil), vds.dims...)
+				virtualDims[vdim] = clip
+				vds.copies = append(vds.copies, virtualCopy{
+					source: src, sourceSel: ssel, virtualSel: vsel, virtualDims: virtualDims,
+				})
+			}
+
+		default:
+			// Printf-style mapping: source dataset n maps to block n of
+			// the unlimited virtual dimension.
+			unlim := vsel.Regular[vdim]
+			if unlim.Count != SelectionUnlimited {
+				return nil, fmt.Errorf("mapping %d: unlimited virtual block requires an unlimited source selection", i)
+			}
+			n, err := vds.mapSourceSeries(sources, m, vdim)
+			if err != nil {
+				return nil, fmt.Errorf("mapping %d: %w", i, err)
+			}
+			if n > 0 {
+				extent[vdim] = max(extent[vdim], unlim.Start+(n-1)*unlim.Stride+unlim.Block)
+			}
+			unlimited[vdim] = true
+		}
+	}
+
+	for d := range vds.dims {
+		if unlimited[d] {
+			vds.dims[d] = max(extent[d], minExtent[d])
+		}
+	}
+
+	// Unlimited mappings were expanded against the stored extent.
+	for i := range vds.copies {
+		c := &vds.copies[i]
+		for d := range vds.dims {
+			if unlimited[d] && c.virtualSel.UnlimitedDim() != d {
+				c.virtualDims[d] = vds.dims[d]
+			}
+		}
+	}
+
+	return vds, nil
+}
+
+// openMapping opens the source dataset of mapping m, expanding printf-style
+// names for block.
+func (s *virtualSourceSet) openMapping(m *VirtualMapping, block uint64, expand bool) (*virtualSource, error) {
+	fileName, err := expandVirtualName(m.SourceFile, block, expand)
+	if err != nil {
+		return nil, err
+	}
+	datasetName, err := expandVirtualName(m.SourceDataset, block, expand)
+	if err != nil {
+		return nil, err
+	}
+	return s.open(fileName, datasetName)
+}
+
+// mapSource maps the source dataset of m (for block of a printf-style
+// mapping) to the elements vsel selects.
+func (vds *virtualDataset) mapSource(sources *virtualSourceSet, m *VirtualMapping, block uint64, expand bool, vsel *Selection) ([]virtualCopy, error) {
+	src, err := sources.openMapping(m, block, expand)
+	if err != nil || src == nil {
+		return nil, err
+	}
+	return []virtualCopy{{
+		source: src, sourceSel: m.SourceSelection, virtualSel: vsel,
+		virtualDims: append([]uint64(nil), vds.dims...),
+	}}, nil
+}
+
+// mapSourceSeries maps the source datasets of a printf-style mapping to
+// consecutive blocks of unlimited dimension vdim, stopping at the first
+// missing source, and returns the number of sources found.
+func (vds *virtualDataset) mapSourceSeries(sources *virtualSourceSet, m *VirtualMapping, vdim int) (uint64, error) {
+	unlim := m.VirtualSelection.Regular[vdim]
+	series := VirtualNameHasBlock(m.SourceFile) || VirtualNameHasBlock(m.SourceDataset)
+
+	var n uint64
+	for ; n < maxVirtualSourceBlocks; n++ {
+		if n > 0 && !series {
+			break
+		}
+
+		// The selection of block n.
+		vsel := *m.VirtualSelection
+		vsel.Regular = append([]HyperslabDim(nil), vsel.Regular...)
+		vsel.Regular[vdim] = HyperslabDim{Start: unlim.Start + n*unlim.Stride, Stride: 1, Count: 1, Block: unlim.Block}
+
+		copies, err := vds.mapSource(sources, m, n, true, &vsel)
+		if err != nil {
+			return 0, err
+		}
+		if len(copies) == 0 {
+			break
+		}
+		vds.copies = append(vds.copies, copies...)
+	}
+	return n, nil
+}
+
+// clipExtentMatch returns the extent at which the unlimited dimension of the
+// virtual selection holds as many slices as the unlimited dimension of the
+// source selection holds within sourceExtent.
+// Reference: H5Shyper.c - H5S_hyper_get_clip_extent_match().
+func clipExtentMatch(virtual, source HyperslabDim, sourceExtent uint64) uint64 {
+	var slices uint64
+	switch {
+	case source.Start >= sourceExtent:
+		slices = 0
+	case source.Block == SelectionUnlimited || source.Block == source.Stride:
+		slices = sourceExtent - source.Start
+	default:
+		count := (sourceExtent - source.Start) / source.Stride
+		rem := sourceExtent - source.Start - count*source.Stride
+		slices = count*source.Block + min(rem, source.Block)
+	}
+
+	switch {
+	case slices == 0:
+		return 0
+	case virtual.Block == SelectionUnlimited || virtual.Block == virtual.Stride:
+		return virtual.Start + slices
+	}
+
+	count := slices / virtual.Block
+	if rem := slices - count*virtual.Block; rem > 0 {
+		// The extent ends within a partial block.
+		return virtual.Start + count*virtual.Stride + rem
+	}
+	return virtual.Start + (count-1)*virtual.Stride + virtual.Block
+}
+
+// read assembles the raw data of the virtual dataset. Elements not mapped to
+// existing source data are set to the fill value.
+func (vds *virtualDataset) read(datatype *DatatypeMessage, fillValue *FillValueMessage) ([]byte, error) {
+	elemSize := uint64(datatype.Size)
+	total := uint64(1)
+	for _, d := range vds.dims {
+		var err error
+		if total, err = utils.SafeMultiply(total, d); err != nil {
+			return nil, fmt.Errorf("dataset size overflow: %w", err)
+		}
+	}
+	dataSize, err := utils.SafeMultiply(total, elemSize)
+	if err != nil {
+		return nil, fmt.Errorf("dataset size overflow: %w", err)
+	}
+
+	rawData := make([]byte, dataSize)
+	FillBuffer(rawData, fillValue.Pattern(elemSize))
+
+	for _, c := range vds.copies {
+		if err := c.copyTo(rawData, vds.dims, datatype); err != nil {
+			return nil, err
+		}
+	}
+	return rawData, nil
+}
+
+// copyTo copies the selected source elements into the virtual dataset's raw
+// data. Elements outside the source or virtual extent are skipped.
+func (c *virtualCopy) copyTo(rawData []byte, dims []uint64, datatype *DatatypeMessage) error {
+	src := c.source
+	srcType, srcDims := src.msgs.datatype, src.msgs.dataspace.Dimensions
+	if srcType.Class != datatype.Class || srcType.Size != datatype.Size {
+		return fmt.Errorf("source dataset datatype %s does not match virtual dataset datatype %s", srcType, datatype)
+	}
+
+	vruns, err := c.virtualSel.Runs(c.virtualDims)
+	if err != nil {
+		return fmt.Errorf("virtual selection: %w", err)
+	}
+	sruns, err := c.sourceSel.Runs(srcDims)
+	if err != nil {
+		return fmt.Errorf("source selection: %w", err)
+	}
+	if len(vruns) == 0 || len(sruns) == 0 {
+		return nil
+	}
+
+	if src.data == nil && src.msgs.dataspace.TotalElements() > 0 {
+		if src.data, err = readDatasetRawData(src.Reader, src.msgs, src.Superblock); err != nil {
+			return fmt.Errorf("failed to read source dataset: %w", err)
+		}
+	}
+
+	elemSize := uint64(datatype.Size)
+	var vi, si int
+	var voff, soff uint64 // Elements consumed of the current runs.
+	for vi < len(vruns) && si < len(sruns) {
+		vrun, srun := &vruns[vi], &sruns[si]
+		n := min(vrun.Length-voff, srun.Length-soff)
+
+		vpos, vvalid := runOffset(vrun.Coords, voff, n, dims)
+		spos, svalid := runOffset(srun.Coords, soff, n, srcDims)
+		if count := min(vvalid, svalid); count > 0 {
+			copy(rawData[vpos*elemSize:(vpos+count)*elemSize], src.data[spos*elemSize:(spos+count)*elemSize])
+		}
+
+		voff += n
+		soff += n
+		if voff == vrun.Length {
+			vi++
+			voff = 0
+		}
+		if soff == srun.Length {
+			si++
+			soff = 0
+		}
+	}
+	return nil
+}
+
+// runOffset returns the linear index in a dataspace of extent dims of the
+// element skip elements into a run starting at coords, and how many of the
+// following n elements lie within the extent.
+func runOffset(coords []uint64, skip, n uint64, dims []uint64) (uint64, uint64) {
+	rank := len(dims)
+	if rank == 0 {
+		return 0, min(n, 1)
+	}
+
+	var pos uint64
+	for d, c := range coords {
+		if d == rank-1 {
+			c += skip
+		}
+		if c >= dims[d] {
+			return 0, 0
+		}
+		pos = pos*dims[d] + c
+	}
+	return pos, min(n, dims[rank-1]-coords[rank-1]-skip)
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"encoding/binary"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseVirtualMappings(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+
+	all := selectionBytes(4, 3, 1, 0, 0)
+	slab := concatBytes(selectionBytes(4, 2, 3), []byte{1, 8}, selectionBytes(4, 1), selectionBytes(8, 4, 1, 1, 2))
+
+	block := concatBytes(
+		[]byte{0}, selectionBytes(8, 2),
+		[]byte("src.h5\x00/data\x00"), all, slab,
+		[]byte(".\x00/x\x00"), slab, all,
+	)
+	block = binary.LittleEndian.AppendUint32(block, utils.JenkinsChecksum(block))
+
+	mappings, err := ParseVirtualMappings(block, sb)
+	require.NoError(t, err)
+	require.Len(t, mappings, 2)
+
+	require.Equal(t, "src.h5", mappings[0].SourceFile)
+	require.Equal(t, "/data", mappings[0].SourceDataset)
+	require.Equal(t, SelectionAll, mappings[0].SourceSelection.Type)
+	require.Equal(t, []HyperslabDim{{Start: 4, Stride: 1, Count: 1, Block: 2}}, mappings[0].VirtualSelection.Regular)
+
+	require.Equal(t, VirtualSameFile, mappings[1].SourceFile)
+	require.Equal(t, "/x", mappings[1].SourceDataset)
+	require.Equal(t, SelectionHyperslabs, mappings[1].SourceSelection.Type)
+	require.Equal(t, SelectionAll, mappings[1].VirtualSelection.Type)
+
+	t.Run("checksum mismatch", func(t *testing.T) {
+		bad := append([]byte(nil), block...)
+		bad[3] ^= 0xFF
+		_, err := ParseVirtualMappings(bad, sb)
+		require.ErrorContains(t, err, "checksum mismatch")
+	})
+
+	t.Run("bad version", func(t *testing.T) {
+		bad := append([]byte{1}, block[1:len(block)-4]...)
+		bad = binary.LittleEndian.AppendUint32(bad, utils.JenkinsChecksum(bad))
+		_, err := ParseVirtualMappings(bad, sb)
+		require.ErrorContains(t, err, "unsupported virtual dataset mapping list version")
+	})
+
+	t.Run("count exceeds data", func(t *testing.T) {
+		bad := concatBytes([]byte{0}, selectionBytes(8, 100), []byte("a\x00b\x00"), all, all)
+		bad = binary.LittleEndian.AppendUint32(bad, utils.JenkinsChecksum(bad))
+		_, err := ParseVirtualMappings(bad, sb)
+		require.ErrorContains(t, err, "exceeds data size")
+	})
+
+	t.Run("unterminated name", func(t *testing.T) {
+		bad := concatBytes([]byte{0}, selectionBytes(8, 1), []byte("abcdefghijklmnopqrstuvwxyz0123456789"))
+		bad = binary.LittleEndian.AppendUint32(bad, utils.JenkinsChecksum(bad))
+		_, err := ParseVirtualMappings(bad, sb)
+		require.ErrorContains(t, err, "name not terminated")
+	})
+}
+
+func TestExpandVirtualName(t *testing.T) {
+	tests := []struct {
+		name   string
+		block  uint64
+		expand bool
+		want   string
+	}{
+		{"plain.h5", 3, true, "plain.h5"},
+		{"f-%b.h5", 12, true, "f-12.h5"},
+		{"100%%-%b", 0, true, "100%-0"},
+		{"100%%.h5", 0, false, "100%.h5"},
+	}
+	for _, tt := range tests {
+		got, err := expandVirtualName(tt.name, tt.block, tt.expand)
+		require.NoError(t, err)
+		require.Equal(t, tt.want, got)
+	}
+
+	for _, name := range []string{"f-%d.h5", "trailing%", "f-%b.h5"} {
+		_, err := expandVirtualName(name, 0, false)
+		require.ErrorContains(t, err, "invalid format specifier", name)
+	}
+}
+
+func TestClipExtentMatch(t *testing.T) {
+	u := SelectionUnlimited
+	tests := []struct {
+		name    string
+		virtual HyperslabDim
+		source  HyperslabDim
+		extent  uint64
+		want    uint64
+	}{
+		{
+			name:    "contiguous",
+			virtual: HyperslabDim{Start: 2, Stride: 1, Count: 1, Block: u},
+			source:  HyperslabDim{Start: 0, Stride: 1, Count: 1, Block: u},
+			extent:  5,
+			want:    7,
+		},
+		{
+			name:    "interleaved",
+			virtual: HyperslabDim{Start: 1, Stride: 4, Count: u, Block: 1},
+			source:  HyperslabDim{Start: 0, Stride: 1, Count: 1, Block: u},
+			extent:  3,
+			want:    10,
+		},
+		{
+			name:    "partial virtual block",
+			virtual: HyperslabDim{Start: 0, Stride: 5, Count: u, Block: 2},
+			source:  HyperslabDim{Start: 0, Stride: 1, Count: 1, Block: u},
+			extent:  3,
+			want:    6,
+		},
+		{
+			name:    "strided source",
+			virtual: HyperslabDim{Start: 0, Stride: 1, Count: 1, Block: u},
+			source:  HyperslabDim{Start: 1, Stride: 3, Count: u, Block: 2},
+			extent:  8,
+			want:    5,
+		},
+		{
+			name:    "source empty",
+			virtual: HyperslabDim{Start: 4, Stride: 1, Count: 1, Block: u},
+			source:  HyperslabDim{Start: 6, Stride: 1, Count: 1, Block: u},
+			extent:  6,
+			want:    0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, clipExtentMatch(tt.virtual, tt.source, tt.extent))
+		})
+	}
+}
+
+func TestEncodeVirtualMappings(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+
+	mappings := []VirtualMapping{
+		{
+			SourceFile:       "run-%b.h5",
+			SourceDataset:    "/data",
+			SourceSelection:  &Selection{Type: SelectionAll},
+			VirtualSelection: &Selection{Type: SelectionHyperslabs, Rank: 1, Regular: []HyperslabDim{{Stride: 10, Count: SelectionUnlimited, Block: 10}}},
+		},
+		{
+			SourceFile:       VirtualSameFile,
+			SourceDataset:    "/100%%",
+			SourceSelection:  &Selection{Type: SelectionPoints, Rank: 1, Points: [][]uint64{{3}}},
+			VirtualSelection: &Selection{Type: SelectionPoints, Rank: 1, Points: [][]uint64{{5}}},
+		},
+	}
+
+	data, err := EncodeVirtualMappings(mappings, sb)
+	require.NoError(t, err)
+
+	got, err := ParseVirtualMappings(data, sb)
+	require.NoError(t, err)
+	require.Equal(t, mappings, got)
+
+	for _, name := range []string{"", "a\x00b", "f-%s.h5"} {
+		bad := []VirtualMapping{mappings[1]}
+		bad[0].SourceFile = name
+		_, err := EncodeVirtualMappings(bad, sb)
+		require.Error(t, err, "name %q", name)
+	}
+}
+
+func TestVirtualNameHasBlock(t *testing.T) {
+	require.True(t, VirtualNameHasBlock("f-%b.h5"))
+	require.True(t, VirtualNameHasBlock("%%%b"))
+	require.False(t, VirtualNameHasBlock("f.h5"))
+	require.False(t, VirtualNameHasBlock("100%%b"))
+	require.False(t, VirtualNameHasBlock("%"))
+}
+
+func TestEncodeVirtualLayoutMessage(t *testing.T) {
+	sb := &Superblock{OffsetSize: 8, LengthSize: 8, Endianness: binary.LittleEndian}
+
+	msg, err := ParseDataLayoutMessage(EncodeVirtualLayoutMessage(0x1234, 3, sb), sb)
+	require.NoError(t, err)
+	require.True(t, msg.IsVirtual())
+	require.Equal(t, uint64(0x1234), msg.DataAddress)
+	require.Equal(t, uint32(3), msg.HeapIndex)
+}
@@ -10,7 +10,8 @@ import (
 // Format matches HDF5 specification for raw data chunk B-tree.
 //
 // HDF5 uses B-tree v1 (type 1) to index chunked dataset chunks.
-// Each chunk is identified by its N-dimensional coordinate (scaled chunk index).
+// Each chunk is identified by the element offset of its first element,
+// with an extra trailing dimension for the datatype that is always 0.
 //
 // Format specification (HDF5 Format Spec III.A.2):
 // - Signature: "TREE" (4 bytes)
@@ -42,13 +43,13 @@ type ChunkBTreeNode struct {
 // Format (per HDF5 spec):
 // - Nbytes: uint32 (chunk size in bytes after filtering)
 // - Filter mask: uint32 (0 for no filters)
-// - Chunk scaled coordinates: uint64[dimensionality] (row-major, stored as byte offsets)
+// - Chunk offsets: uint64[dimensionality] (element offsets, trailing datatype offset 0)
 //
 // For MVP (Phase 1):
 // - No filters (FilterMask = 0)
 // - Nbytes is set to chunk data size.
 type ChunkKey struct {
-	Coords     []uint64 // [dim0, dim1, ..., dimN] (scaled chunk indices)
+	Coords     []uint64 // [dim0, dim1, ..., dimN, 0] (chunk offsets in elements)
 	FilterMask uint32   // Always 0 for Phase 1 (no compression)
 	Nbytes     uint32   // Chunk size in bytes (after filtering)
 }
@@ -61,10 +62,10 @@ type ChunkKey struct {
 //
 // Usage:
 //
-//	writer := NewChunkBTreeWriter(2) // 2D dataset
-//	writer.AddChunk([]uint64{0, 0}, chunkAddr1)
-//	writer.AddChunk([]uint64{0, 1}, chunkAddr2)
-//	writer.AddChunk([]uint64{1, 0}, chunkAddr3)
+//	writer := NewChunkBTreeWriter(3) // 2D dataset, chunks 10x20
+//	writer.AddChunk([]uint64{0, 0, 0}, chunkAddr1)
+//	writer.AddChunk([]uint64{0, 20, 0}, chunkAddr2)
+//	writer.AddChunk([]uint64{10, 0, 0}, chunkAddr3)
 //	btreeAddr, err := writer.WriteToFile(fileWriter, allocator)
 type ChunkBTreeWriter struct {
 	dimensionality int
@@ -73,7 +74,7 @@ type ChunkBTreeWriter struct {
 
 // ChunkBTreeEntry represents a single chunk in the index.
 type ChunkBTreeEntry struct {
-	Coordinate []uint64 // Scaled chunk coordinate
+	Coordinate []uint64 // Chunk offset in elements
 	Address    uint64   // File address of raw chunk data
 	Nbytes     uint32   // Chunk size in bytes (after filtering)
 }
@@ -81,7 +82,7 @@ type ChunkBTreeEntry struct {
 // NewChunkBTreeWriter creates new chunk B-tree writer.
 //
 // Parameters:
-//   - dimensionality: Number of dimensions in dataset (1, 2, 3, etc.)
+//   - dimensionality: Number of key dimensions (dataset rank + 1 for the datatype)
 //
 // Returns:
 //   - ChunkBTreeWriter ready to accept chunks
@@ -99,16 +100,16 @@ func NewChunkBTreeWriter(dimensionality int) *ChunkBTreeWriter {
 // in row-major order before writing.
 //
 // Parameters:
-//   - coord: Scaled chunk coordinate [dim0, dim1, ..., dimN]
+//   - coord: Chunk offset in elements [dim0, dim1, ..., dimN, 0]
 //   - address: File address where chunk data is written
 //
 // Example:
 //
 //	// For 2D dataset with chunk size [10, 20]
-//	// Dataset element [5, 15] is in chunk [0, 0]
-//	// Dataset element [15, 25] is in chunk [1, 1]
-//	writer.AddChunk([]uint64{0, 0}, 1000) // First chunk at address 1000
-//	writer.AddChunk([]uint64{1, 1}, 2000) // Second chunk at address 2000
+//	// Dataset element [5, 15] is in the chunk at offset [0, 0]
+//	// Dataset element [15, 25] is in the chunk at offset [10, 20]
+//	writer.AddChunk([]uint64{0, 0, 0}, 1000)   // First chunk at address 1000
+//	writer.AddChunk([]uint64{10, 20, 0}, 2000) // Second chunk at address 2000
 func (w *ChunkBTreeWriter) AddChunk(coord []uint64, address uint64) error {
 	return w.AddChunkWithSize(coord, address, 0)
 }
@@ -116,7 +117,7 @@ func (w *ChunkBTreeWriter) AddChunk(coord []uint64, address uint64) error {
 // AddChunkWithSize adds chunk to index with explicit size.
 //
 // Parameters:
-//   - coord: Scaled chunk coordinate [dim0, dim1, ..., dimN]
+//   - coord: Chunk offset in elements [dim0, dim1, ..., dimN, 0]
 //   - address: File address where chunk data is written
 //   - nbytes: Size of chunk data in bytes (after filtering)
 func (w *ChunkBTreeWriter) AddChunkWithSize(coord []uint64, address uint64, nbytes uint32) error {
@@ -141,11 +142,8 @@ func (w *ChunkBTreeWriter) AddChunkWithSize(coord []uint64, address uint64, nbyt
 // WriteToFile writes B-tree to file, returns root address.
 //
 // This method:
-// 1. Sorts entries by coordinate (row-major order)
-// 2. Builds single leaf node with all entries
-// 3. Adds sentinel max key (required by B-tree spec)
-// 4. Serializes node to bytes
-// 5. Allocates space and writes to file
+// 1. Encodes the node (see Encode)
+// 2. Allocates space and writes to file
 //
 // Parameters:
 //   - writer: FileWriter for write operations
@@ -158,8 +156,36 @@ func (w *ChunkBTreeWriter) AddChunkWithSize(coord []uint64, address uint64, nbyt
 // The returned address should be stored in the Data Layout Message
 // (chunked layout v3) as the B-tree address.
 func (w *ChunkBTreeWriter) WriteToFile(writer Writer, allocator Allocator) (uint64, error) {
+	buf, err := w.Encode()
+	if err != nil {
+		return 0, err
+	}
+
+	addr, err := allocator.Allocate(uint64(len(buf)))
+	if err != nil {
+		return 0, fmt.Errorf("failed to allocate space for B-tree: %w", err)
+	}
+
+	if err := writer.WriteAtAddress(buf, addr); err != nil {
+		return 0, fmt.Errorf("failed to write B-tree at address %d: %w", addr, err)
+	}
+
+	return addr, nil
+}
+
+// Encode serializes the B-tree leaf node without writing it.
+//
+// This method:
+// 1. Sorts entries by coordinate (row-major order)
+// 2. Builds single leaf node with all entries
+// 3. Adds sentinel max key (required by B-tree spec)
+// 4. Serializes node to bytes
+//
+// Callers that rewrite the index in place use the encoded size to decide
+// whether the node still fits its previous allocation.
+func (w *ChunkBTreeWriter) Encode() ([]byte, error) {
 	if len(w.entries) == 0 {
-		return 0, fmt.Errorf("no chunks to write (empty B-tree)")
+		return nil, fmt.Errorf("no chunks to write (empty B-tree)")
 	}
 
 	// 1. Sort entries by coordinate (row-major)
@@ -200,20 +226,7 @@ func (w *ChunkBTreeWriter) WriteToFile(writer Writer, allocator Allocator) (uint
 	})
 
 	// 5. Serialize
-	buf := serializeChunkBTreeNode(node, w.dimensionality)
-
-	// 6. Allocate space
-	addr, err := allocator.Allocate(uint64(len(buf)))
-	if err != nil {
-		return 0, fmt.Errorf("failed to allocate space for B-tree: %w", err)
-	}
-
-	// 7. Write to file
-	if err := writer.WriteAtAddress(buf, addr); err != nil {
-		return 0, fmt.Errorf("failed to write B-tree at address %d: %w", addr, err)
-	}
-
-	return addr, nil
+	return serializeChunkBTreeNode(node, w.dimensionality), nil
 }
 
 // serializeChunkBTreeNode serializes node to bytes.
@@ -319,3 +332,10 @@ type Writer interface {
 type Allocator interface {
 	Allocate(size uint64) (uint64, error)
 }
+
+// FreeingAllocator is an Allocator that can also release space.
+// Implemented by internal/writer.Allocator.
+type FreeingAllocator interface {
+	Allocator
+	Free(offset, size uint64) error
+}
@@ -0,0 +1,389 @@
+package structures
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// DefaultChunkBTreeK is HDF5's default K for raw data chunk B-trees
+// (H5D_BTREE_K): nodes hold up to 2K = 64 children. Superblocks before
+// version 1 cannot store a different value.
+const DefaultChunkBTreeK = 32
+
+// ChunkBTree is a raw data chunk B-tree v1 that is updated incrementally.
+//
+// Unlike ChunkBTreeWriter, which encodes a single leaf for a complete set of
+// chunks, ChunkBTree keeps its nodes in memory and inserts chunks one by one:
+//   - Nodes are allocated at their full size (2K children), as the HDF5
+//     library reads them, so they can grow in place. Only a root leaf is
+//     allocated for the next power of two of its entries, so small datasets
+//     stay small; it moves when it outgrows that space
+//   - A node that overflows is split and the new node's first key inserted
+//     into its parent; a root split allocates a new root
+//   - Insert only marks nodes dirty; Flush writes the dirty nodes
+//
+// Keys hold chunk offsets in elements plus the trailing datatype dimension
+// (always 0), like ChunkBTreeWriter. The last key of the rightmost nodes is
+// the same all-ones sentinel ChunkBTreeWriter writes.
+//
+// Usage:
+//
+//	tree := NewChunkBTree(3, DefaultChunkBTreeK) // 2D dataset
+//	tree.Insert([]uint64{0, 0, 0}, chunkAddr1, nbytes1)
+//	tree.Insert([]uint64{0, 20, 0}, chunkAddr2, nbytes2)
+//	err := tree.Flush(fileWriter, allocator)
+//	rootAddr := tree.RootAddress()
+type ChunkBTree struct {
+	dimensionality int
+	twoK           int
+	root           *chunkBTreeNode
+	dirty          []*chunkBTreeNode
+}
+
+// chunkBTreeNode is the in-memory copy of one node. keys has one entry more
+// than children: keys[i] is the lowest offset under children[i] and the last
+// key bounds the node on the right.
+type chunkBTreeNode struct {
+	address     uint64 // 0 until allocated by Flush
+	capacity    int    // Children that fit the allocated space
+	level       uint8
+	keys        []ChunkKey
+	children    []uint64          // Chunk addresses (leaf) or child node addresses
+	nodes       []*chunkBTreeNode // Child nodes (internal nodes only)
+	left, right *chunkBTreeNode
+	dirty       bool
+}
+
+// NewChunkBTree creates an empty chunk B-tree.
+//
+// Parameters:
+//   - dimensionality: Number of key dimensions (dataset rank + 1 for the datatype)
+//   - k: Node K; nodes hold up to 2K children (use DefaultChunkBTreeK)
+func NewChunkBTree(dimensionality, k int) *ChunkBTree {
+	return &ChunkBTree{
+		dimensionality: dimensionality,
+		twoK:           2 * k,
+	}
+}
+
+// RootAddress returns the file address of the root node, or 0 if the tree is
+// empty or has not been flushed yet.
+func (t *ChunkBTree) RootAddress() uint64 {
+	if t.root == nil {
+		return 0
+	}
+	return t.root.address
+}
+
+// Insert adds the chunk at coord to the tree, or updates its address and
+// size if the chunk is already indexed.
+//
+// Parameters:
+//   - coord: Chunk offset in elements [dim0, dim1, ..., dimN, 0]
+//   - address: File address where chunk data is written
+//   - nbytes: Size of chunk data in bytes (after filtering)
+func (t *ChunkBTree) Insert(coord []uint64, address uint64, nbytes uint32) error {
+	if len(coord) != t.dimensionality {
+		return fmt.Errorf("coordinate dimensionality mismatch: expected %d, got %d",
+			t.dimensionality, len(coord))
+	}
+
+	key := ChunkKey{Coords: append([]uint64{}, coord...), Nbytes: nbytes}
+
+	if t.root == nil {
+		maxKey := make([]uint64, t.dimensionality)
+		for i := range maxKey {
+			maxKey[i] = ^uint64(0)
+		}
+		t.root = &chunkBTreeNode{
+			keys:     []ChunkKey{key, {Coords: maxKey}},
+			children: []uint64{address},
+		}
+		t.markDirty(t.root)
+		return nil
+	}
+
+	split := t.insert(t.root, key, address)
+	if split == nil {
+		return nil
+	}
+
+	// The root split: grow the tree by one level.
+	old := t.root
+	t.root = &chunkBTreeNode{
+		level:    old.level + 1,
+		keys:     []ChunkKey{old.keys[0], split.keys[0], split.keys[len(split.keys)-1]},
+		children: []uint64{0, 0}, // Filled in by Flush
+		nodes:    []*chunkBTreeNode{old, split},
+	}
+	t.markDirty(t.root)
+	return nil
+}
+
+// insert adds key below n and returns the new right sibling if n split.
+func (t *ChunkBTree) insert(n *chunkBTreeNode, key ChunkKey, address uint64) *chunkBTreeNode {
+	used := len(n.children)
+
+	if n.level == 0 {
+		// Position of the first chunk not below key.
+		i := sort.Search(used, func(i int) bool {
+			return compareChunkCoords(n.keys[i].Coords, key.Coords) >= 0
+		})
+		if i < used && compareChunkCoords(n.keys[i].Coords, key.Coords) == 0 {
+			n.keys[i].Nbytes = key.Nbytes
+			n.children[i] = address
+			t.markDirty(n)
+			return nil
+		}
+		n.keys = append(n.keys[:i], append([]ChunkKey{key}, n.keys[i:]...)...)
+		n.children = append(n.children[:i], append([]uint64{address}, n.children[i:]...)...)
+		t.markDirty(n)
+		return t.splitIfFull(n)
+	}
+
+	// Child whose range holds key; keys below the first child extend it.
+	c := sort.Search(used, func(i int) bool {
+		return compareChunkCoords(n.keys[i].Coords, key.Coords) > 0
+	}) - 1
+	if c < 0 {
+		c = 0
+		n.keys[0] = ChunkKey{Coords: key.Coords, Nbytes: key.Nbytes}
+		t.markDirty(n)
+	}
+
+	split := t.insert(n.nodes[c], key, address)
+	if split == nil {
+		return nil
+	}
+
+	n.keys = append(n.keys[:c+1], append([]ChunkKey{split.keys[0]}, n.keys[c+1:]...)...)
+	n.children = append(n.children[:c+1], append([]uint64{0}, n.children[c+1:]...)...)
+	n.nodes = append(n.nodes[:c+1], append([]*chunkBTreeNode{split}, n.nodes[c+1:]...)...)
+	t.markDirty(n)
+	return t.splitIfFull(n)
+}
+
+// splitIfFull moves the upper half of an overflowing node into a new right
+// sibling and returns it.
+func (t *ChunkBTree) splitIfFull(n *chunkBTreeNode) *chunkBTreeNode {
+	used := len(n.children)
+	if used <= t.twoK {
+		return nil
+	}
+
+	mid := used / 2
+	right := &chunkBTreeNode{
+		level:    n.level,
+		keys:     append([]ChunkKey{}, n.keys[mid:]...),
+		children: append([]uint64{}, n.children[mid:]...),
+		left:     n,
+		right:    n.right,
+	}
+	if n.nodes != nil {
+		right.nodes = append([]*chunkBTreeNode{}, n.nodes[mid:]...)
+		n.nodes = n.nodes[:mid:mid]
+	}
+	n.keys = n.keys[: mid+1 : mid+1]
+	n.children = n.children[:mid:mid]
+
+	if n.right != nil {
+		n.right.left = right
+		t.markDirty(n.right)
+	}
+	n.right = right
+	t.markDirty(right)
+	return right
+}
+
+func (t *ChunkBTree) markDirty(n *chunkBTreeNode) {
+	if !n.dirty {
+		n.dirty = true
+		t.dirty = append(t.dirty, n)
+	}
+}
+
+// Flush allocates space for new nodes and writes every node changed since
+// the last Flush. The root address may change when the root was split.
+// Nodes that outgrow their space move, and their old space is freed.
+func (t *ChunkBTree) Flush(writer Writer, allocator FreeingAllocator) error {
+	if t.root == nil {
+		return fmt.Errorf("no chunks to write (empty B-tree)")
+	}
+
+	// Index loop: moving a node dirties its siblings, which point to it.
+	// Only a root leaf or the former root leaf moves, in the Flush after the
+	// split; its parent is then the new root, which is dirty as well.
+	for i := 0; i < len(t.dirty); i++ {
+		n := t.dirty[i]
+		capacity := t.twoK
+		if n == t.root && n.level == 0 {
+			capacity = min(nextPowerOfTwo(len(n.children)), t.twoK)
+		}
+		if n.address != 0 && capacity <= n.capacity {
+			continue
+		}
+		addr, err := allocator.Allocate(t.nodeSize(capacity))
+		if err != nil {
+			return fmt.Errorf("failed to allocate space for B-tree node: %w", err)
+		}
+		oldAddress, oldSize := n.address, t.nodeSize(n.capacity)
+		n.address = addr
+		n.capacity = capacity
+		if oldAddress != 0 {
+			if err := allocator.Free(oldAddress, oldSize); err != nil {
+				return fmt.Errorf("failed to free B-tree node at address %d: %w", oldAddress, err)
+			}
+			for _, s := range []*chunkBTreeNode{n.left, n.right} {
+				if s != nil {
+					t.markDirty(s)
+				}
+			}
+		}
+	}
+
+	for _, n := range t.dirty {
+		for i, child := range n.nodes {
+			n.children[i] = child.address
+		}
+		buf := make([]byte, t.nodeSize(n.capacity))
+		copy(buf, serializeChunkBTreeNode(n.encode(), t.dimensionality))
+		if err := writer.WriteAtAddress(buf, n.address); err != nil {
+			return fmt.Errorf("failed to write B-tree node at address %d: %w", n.address, err)
+		}
+		n.dirty = false
+	}
+	t.dirty = t.dirty[:0]
+	return nil
+}
+
+// nodeSize returns the size of a node with room for capacity children.
+func (t *ChunkBTree) nodeSize(capacity int) uint64 {
+	keySize := 4 + 4 + t.dimensionality*8                 // nbytes + filter_mask + coords
+	return uint64(24 + (capacity+1)*keySize + capacity*8) //nolint:gosec // G115: capacity is at most 2K
+}
+
+func nextPowerOfTwo(n int) int {
+	p := 1
+	for p < n {
+		p <<= 1
+	}
+	return p
+}
+
+// encode converts n to the ChunkBTreeNode layout serializeChunkBTreeNode writes.
+// Parents are written after their children are allocated, so in Flush the
+// sibling and child addresses are known.
+func (n *chunkBTreeNode) encode() *ChunkBTreeNode {
+	sibling := func(s *chunkBTreeNode) uint64 {
+		if s == nil {
+			return 0xFFFFFFFFFFFFFFFF
+		}
+		return s.address
+	}
+	return &ChunkBTreeNode{
+		Signature:    [4]byte{'T', 'R', 'E', 'E'},
+		NodeType:     1,
+		NodeLevel:    n.level,
+		EntriesUsed:  uint16(len(n.children)), //nolint:gosec // G115: at most 2K children
+		LeftSibling:  sibling(n.left),
+		RightSibling: sibling(n.right),
+		Keys:         n.keys,
+		ChildAddrs:   n.children,
+	}
+}
+
+// LoadChunkBTree reads the chunk B-tree rooted at address so that chunks can
+// be inserted into an existing dataset.
+//
+// Every node read is taken to have room for 2K children, as the HDF5 library
+// and ChunkBTree allocate them, except a root leaf: it only holds its entries
+// for sure and moves on the first insert. The last key of the rightmost nodes
+// is set to the all-ones sentinel, so chunks past the current extent are
+// found in them; those nodes are written by the next Flush.
+//
+// Parameters:
+//   - address: Address of the root node (from the layout message)
+//   - dimensionality: Number of key dimensions (dataset rank + 1 for the datatype)
+//   - k: Node K the file was written with (superblock indexed storage K)
+//   - offsetSize: Size of file addresses in bytes
+func LoadChunkBTree(r io.ReaderAt, address uint64, dimensionality, k int, offsetSize uint8) (*ChunkBTree, error) {
+	t := NewChunkBTree(dimensionality, k)
+
+	// Unit chunk dimensions keep the key offsets as stored.
+	unit := make([]uint64, dimensionality)
+	for i := range unit {
+		unit[i] = 1
+	}
+
+	var rightmost []*chunkBTreeNode // Last node read on each level
+	var load func(addr uint64, parent *chunkBTreeNode) (*chunkBTreeNode, error)
+	load = func(addr uint64, parent *chunkBTreeNode) (*chunkBTreeNode, error) {
+		raw, err := core.ParseBTreeV1Node(r, addr, offsetSize, dimensionality, unit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read chunk B-tree node at 0x%x: %w", addr, err)
+		}
+		switch {
+		case raw.NodeType != 1:
+			return nil, fmt.Errorf("B-tree node at 0x%x is not a chunk node (type %d)", addr, raw.NodeType)
+		case raw.EntriesUsed == 0 || int(raw.EntriesUsed) > t.twoK:
+			return nil, fmt.Errorf("chunk B-tree node at 0x%x has %d entries, expected 1 to %d",
+				addr, raw.EntriesUsed, t.twoK)
+		case parent != nil && raw.NodeLevel+1 != parent.level:
+			return nil, fmt.Errorf("chunk B-tree node at 0x%x has level %d below a level %d node",
+				addr, raw.NodeLevel, parent.level)
+		}
+
+		n := &chunkBTreeNode{
+			address:  addr,
+			capacity: t.twoK,
+			level:    raw.NodeLevel,
+			keys:     make([]ChunkKey, len(raw.Keys)),
+			children: raw.Children,
+		}
+		for i, key := range raw.Keys {
+			n.keys[i] = ChunkKey{Coords: key.Scaled, FilterMask: key.FilterMask, Nbytes: key.Nbytes}
+		}
+
+		if rightmost == nil {
+			rightmost = make([]*chunkBTreeNode, int(n.level)+1)
+		}
+		if left := rightmost[n.level]; left != nil {
+			left.right = n
+			n.left = left
+		}
+		rightmost[n.level] = n
+
+		if n.level > 0 {
+			n.nodes = make([]*chunkBTreeNode, len(n.children))
+			for i, child := range n.children {
+				if n.nodes[i], err = load(child, n); err != nil {
+					return nil, err
+				}
+			}
+		}
+		return n, nil
+	}
+
+	root, err := load(address, nil)
+	if err != nil {
+		return nil, err
+	}
+	if root.level == 0 {
+		root.capacity = len(root.children)
+	}
+	t.root = root
+
+	for _, n := range rightmost {
+		last := n.keys[len(n.keys)-1].Coords
+		for i := range last {
+			if last[i] != ^uint64(0) {
+				last[i] = ^uint64(0)
+				t.markDirty(n)
+			}
+		}
+	}
+	return t, nil
+}
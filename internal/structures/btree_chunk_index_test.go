@@ -0,0 +1,259 @@
+package structures
+
+import (
+	"encoding/binary"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// decodedChunkNode is a chunk B-tree node read back from a mock writer.
+type decodedChunkNode struct {
+	level       uint8
+	left, right uint64
+	keys        [][]uint64
+	children    []uint64
+}
+
+func decodeChunkNode(t *testing.T, w *mockChunkWriter, addr uint64, dimensionality int) decodedChunkNode {
+	t.Helper()
+	data := w.ReadAt(addr)
+	require.NotEmpty(t, data, "no node at %d", addr)
+	require.Equal(t, "TREE", string(data[0:4]))
+
+	n := decodedChunkNode{
+		level: data[5],
+		left:  binary.LittleEndian.Uint64(data[8:16]),
+		right: binary.LittleEndian.Uint64(data[16:24]),
+	}
+	entries := int(binary.LittleEndian.Uint16(data[6:8]))
+	off := 24
+	readKey := func() {
+		off += 8 // nbytes + filter mask
+		key := make([]uint64, dimensionality)
+		for i := range key {
+			key[i] = binary.LittleEndian.Uint64(data[off:])
+			off += 8
+		}
+		n.keys = append(n.keys, key)
+	}
+	for i := 0; i < entries; i++ {
+		readKey()
+		n.children = append(n.children, binary.LittleEndian.Uint64(data[off:]))
+		off += 8
+	}
+	readKey()
+	return n
+}
+
+// collectChunkLeaves walks the tree below addr and returns the leaves from
+// left to right.
+func collectChunkLeaves(t *testing.T, w *mockChunkWriter, addr uint64, dimensionality int) ([]uint64, []decodedChunkNode) {
+	t.Helper()
+	n := decodeChunkNode(t, w, addr, dimensionality)
+	if n.level == 0 {
+		return []uint64{addr}, []decodedChunkNode{n}
+	}
+	var addrs []uint64
+	var leaves []decodedChunkNode
+	for i, child := range n.children {
+		a, l := collectChunkLeaves(t, w, child, dimensionality)
+		require.Equal(t, n.keys[i], l[0].keys[0], "first key of child %d", i)
+		addrs = append(addrs, a...)
+		leaves = append(leaves, l...)
+	}
+	return addrs, leaves
+}
+
+func TestChunkBTree_SingleLeaf(t *testing.T) {
+	tree := NewChunkBTree(2, DefaultChunkBTreeK)
+	w := newMockChunkWriter()
+	alloc := newMockChunkAllocator(1000)
+
+	require.Equal(t, uint64(0), tree.RootAddress())
+	require.Error(t, tree.Flush(w, alloc))
+
+	// Out of order inserts.
+	for _, i := range []uint64{2, 0, 1} {
+		require.NoError(t, tree.Insert([]uint64{i * 10, 0}, 100+i, 40))
+	}
+	require.NoError(t, tree.Flush(w, alloc))
+	root := tree.RootAddress()
+	require.Equal(t, uint64(1000), root)
+
+	// A root leaf with 3 entries gets room for 4.
+	require.Len(t, w.ReadAt(root), int(tree.nodeSize(4)))
+
+	n := decodeChunkNode(t, w, root, 2)
+	require.Equal(t, []uint64{100, 101, 102}, n.children)
+	require.Equal(t, []uint64{0, 0}, n.keys[0])
+	require.Equal(t, []uint64{^uint64(0), ^uint64(0)}, n.keys[3])
+	require.Equal(t, uint64(0xFFFFFFFFFFFFFFFF), n.left)
+	require.Equal(t, uint64(0xFFFFFFFFFFFFFFFF), n.right)
+
+	// Updating a chunk and adding one that still fits keeps the address.
+	require.NoError(t, tree.Insert([]uint64{10, 0}, 201, 48))
+	require.NoError(t, tree.Insert([]uint64{30, 0}, 103, 40))
+	require.NoError(t, tree.Flush(w, alloc))
+	require.Equal(t, root, tree.RootAddress())
+	require.Equal(t, []uint64{100, 201, 102, 103}, decodeChunkNode(t, w, root, 2).children)
+	require.Empty(t, alloc.freed)
+
+	// The fifth chunk moves the root leaf and frees its old space.
+	require.NoError(t, tree.Insert([]uint64{40, 0}, 104, 40))
+	require.NoError(t, tree.Flush(w, alloc))
+	require.NotEqual(t, root, tree.RootAddress())
+	require.Len(t, decodeChunkNode(t, w, tree.RootAddress(), 2).children, 5)
+	require.Equal(t, map[uint64]uint64{root: tree.nodeSize(4)}, alloc.freed)
+
+	require.ErrorContains(t, tree.Insert([]uint64{0}, 1, 1), "dimensionality mismatch")
+}
+
+func TestChunkBTree_Split(t *testing.T) {
+	const k = 2 // Nodes of up to 4 children force several levels
+	const chunks = 50
+
+	tests := []struct {
+		name       string
+		flushEvery int
+		order      func(i uint64) uint64
+	}{
+		{"append", 1, func(i uint64) uint64 { return i }},
+		{"append batched", 7, func(i uint64) uint64 { return i }},
+		{"reverse", 1, func(i uint64) uint64 { return chunks - 1 - i }},
+		{"interleaved", 3, func(i uint64) uint64 { return (i * 17) % chunks }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tree := NewChunkBTree(2, k)
+			w := newMockChunkWriter()
+			alloc := newMockChunkAllocator(1000)
+
+			for i := uint64(0); i < chunks; i++ {
+				c := tt.order(i)
+				require.NoError(t, tree.Insert([]uint64{c * 8, 0}, 10000+c, 64))
+				if (i+1)%uint64(tt.flushEvery) == 0 {
+					require.NoError(t, tree.Flush(w, alloc))
+				}
+			}
+			require.NoError(t, tree.Flush(w, alloc))
+
+			root := decodeChunkNode(t, w, tree.RootAddress(), 2)
+			require.Positive(t, root.level)
+
+			addrs, leaves := collectChunkLeaves(t, w, tree.RootAddress(), 2)
+
+			// None of the nodes in use lie in space freed when a root
+			// leaf moved.
+			require.NotContains(t, alloc.freed, tree.RootAddress())
+			for _, a := range addrs {
+				require.NotContains(t, alloc.freed, a)
+			}
+
+			var got []uint64
+			for i, leaf := range leaves {
+				require.LessOrEqual(t, len(leaf.children), 2*k)
+
+				// Sibling links chain the leaves.
+				if i == 0 {
+					require.Equal(t, uint64(0xFFFFFFFFFFFFFFFF), leaf.left)
+				} else {
+					require.Equal(t, addrs[i-1], leaf.left)
+					require.Equal(t, leaf.keys[0], leaves[i-1].keys[len(leaves[i-1].keys)-1])
+				}
+				if i == len(leaves)-1 {
+					require.Equal(t, uint64(0xFFFFFFFFFFFFFFFF), leaf.right)
+				} else {
+					require.Equal(t, addrs[i+1], leaf.right)
+				}
+				got = append(got, leaf.children...)
+			}
+
+			want := make([]uint64, chunks)
+			for i := range want {
+				want[i] = 10000 + uint64(i)
+			}
+			require.Equal(t, want, got)
+		})
+	}
+}
+
+// chunkNodeReader reads the nodes a mockChunkWriter holds.
+type chunkNodeReader struct {
+	w *mockChunkWriter
+}
+
+func (r chunkNodeReader) ReadAt(p []byte, off int64) (int, error) {
+	for addr, data := range r.w.data {
+		//nolint:gosec // G115: test addresses are small
+		if start := uint64(off); start >= addr && start < addr+uint64(len(data)) {
+			n := copy(p, data[start-addr:])
+			if n < len(p) {
+				return n, io.EOF
+			}
+			return n, nil
+		}
+	}
+	return 0, io.EOF
+}
+
+func TestLoadChunkBTree(t *testing.T) {
+	const k = 2
+
+	w := newMockChunkWriter()
+	alloc := newMockChunkAllocator(1000)
+	tree := NewChunkBTree(2, k)
+	for c := uint64(0); c < 30; c++ {
+		require.NoError(t, tree.Insert([]uint64{c * 8, 0}, 10000+c, 64))
+	}
+	require.NoError(t, tree.Flush(w, alloc))
+	root := tree.RootAddress()
+
+	loaded, err := LoadChunkBTree(chunkNodeReader{w}, root, 2, k, 8)
+	require.NoError(t, err)
+	require.Equal(t, root, loaded.RootAddress())
+
+	// Nodes fuller than 2K do not match the file's K.
+	_, err = LoadChunkBTree(chunkNodeReader{w}, root, 2, 1, 8)
+	require.ErrorContains(t, err, "entries")
+
+	// Appending and updating chunks continues the tree.
+	for c := uint64(30); c < 60; c++ {
+		require.NoError(t, loaded.Insert([]uint64{c * 8, 0}, 10000+c, 64))
+	}
+	require.NoError(t, loaded.Insert([]uint64{5 * 8, 0}, 20005, 32))
+	require.NoError(t, loaded.Flush(w, alloc))
+
+	addrs, leaves := collectChunkLeaves(t, w, loaded.RootAddress(), 2)
+	var got []uint64
+	for i, leaf := range leaves {
+		require.LessOrEqual(t, len(leaf.children), 2*k)
+		if i > 0 {
+			require.Equal(t, addrs[i-1], leaf.left)
+			require.Equal(t, addrs[i], leaves[i-1].right)
+		}
+		got = append(got, leaf.children...)
+	}
+	require.Equal(t, uint64(0xFFFFFFFFFFFFFFFF), leaves[len(leaves)-1].right)
+	require.Equal(t, []uint64{^uint64(0), ^uint64(0)}, leaves[len(leaves)-1].keys[len(leaves[len(leaves)-1].keys)-1])
+
+	want := make([]uint64, 60)
+	for i := range want {
+		want[i] = 10000 + uint64(i)
+	}
+	want[5] = 20005
+	require.Equal(t, want, got)
+
+	// A root leaf moves on the first insert: its space may be exact.
+	small := NewChunkBTree(2, k)
+	require.NoError(t, small.Insert([]uint64{0, 0}, 100, 64))
+	require.NoError(t, small.Flush(w, alloc))
+	loaded, err = LoadChunkBTree(chunkNodeReader{w}, small.RootAddress(), 2, k, 8)
+	require.NoError(t, err)
+	require.NoError(t, loaded.Insert([]uint64{8, 0}, 101, 64))
+	require.NoError(t, loaded.Flush(w, alloc))
+	require.NotEqual(t, small.RootAddress(), loaded.RootAddress())
+	require.Equal(t, []uint64{100, 101}, decodeChunkNode(t, w, loaded.RootAddress(), 2).children)
+}
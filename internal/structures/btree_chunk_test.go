@@ -33,6 +33,7 @@ func (m *mockChunkWriter) ReadAt(address uint64) []byte {
 // Mock allocator for testing.
 type mockChunkAllocator struct {
 	nextAddr uint64
+	freed    map[uint64]uint64 // Freed address -> size.
 }
 
 func newMockChunkAllocator(startAddr uint64) *mockChunkAllocator {
@@ -47,6 +48,14 @@ func (m *mockChunkAllocator) Allocate(size uint64) (uint64, error) {
 	return addr, nil
 }
 
+func (m *mockChunkAllocator) Free(offset, size uint64) error {
+	if m.freed == nil {
+		m.freed = make(map[uint64]uint64)
+	}
+	m.freed[offset] = size
+	return nil
+}
+
 // TestChunkBTreeWriter_1D tests 1D chunked dataset.
 func TestChunkBTreeWriter_1D(t *testing.T) {
 	writer := NewChunkBTreeWriter(1)
@@ -2,7 +2,6 @@ package structures
 
 import (
 	"encoding/binary"
-	"errors"
 	"fmt"
 	"io"
 
@@ -17,8 +16,160 @@ import (
 // - Keys: heap offsets (for sorting/searching)
 // - Children: addresses of Symbol Table Nodes (SNODs)
 //
-// The function follows child pointers to SNODs and collects all entries from them.
+// Internal nodes (level > 0) point to further B-tree nodes and are followed
+// recursively. The function follows child pointers to SNODs and collects all
+// entries from them.
 func ReadGroupBTreeEntries(r io.ReaderAt, address uint64, sb *core.Superblock) ([]BTreeEntry, error) {
+	node, err := readGroupBTreeNode(r, address, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse each child to collect entries
+	var allEntries []BTreeEntry
+	for _, childAddr := range node.children {
+		if childAddr == 0 || childAddr == 0xFFFFFFFFFFFFFFFF {
+			continue
+		}
+
+		if node.level > 0 {
+			entries, err := ReadGroupBTreeEntries(r, childAddr, sb)
+			if err != nil {
+				return nil, err
+			}
+			allEntries = append(allEntries, entries...)
+			continue
+		}
+
+		snodNode, err := ParseSymbolTableNode(r, childAddr, sb)
+		if err != nil {
+			// Skip invalid SNODs
+			continue
+		}
+
+		// Convert SNOD entries to BTreeEntry format
+		for _, entry := range snodNode.Entries {
+			allEntries = append(allEntries, snodEntryToBTreeEntry(entry))
+		}
+	}
+
+	return allEntries, nil
+}
+
+// GroupSymbolTableNodes returns the addresses of the symbol table nodes
+// indexed by a group's v1 B-tree, in name order.
+func GroupSymbolTableNodes(r io.ReaderAt, address uint64, sb *core.Superblock) ([]uint64, error) {
+	node, err := readGroupBTreeNode(r, address, sb)
+	if err != nil {
+		return nil, err
+	}
+	if node.level == 0 {
+		return node.children, nil
+	}
+
+	var nodes []uint64
+	for _, childAddr := range node.children {
+		children, err := GroupSymbolTableNodes(r, childAddr, sb)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, children...)
+	}
+	return nodes, nil
+}
+
+// FindGroupBTreeEntry looks up a link name in a group's v1 B-tree.
+//
+// Keys are local heap offsets of link names, and child i holds the names that
+// sort after key i and up to key i+1. Only the nodes on the path to the name
+// are read, followed by a binary search of the symbol table node, so the cost
+// is logarithmic in the number of links.
+//
+// Returns nil (and no error) if the group has no link with that name.
+func FindGroupBTreeEntry(r io.ReaderAt, address uint64, heap *LocalHeap, name string, sb *core.Superblock) (*BTreeEntry, error) {
+	snodAddr, err := FindGroupSymbolTableNode(r, address, heap, name, sb)
+	if err != nil || snodAddr == 0 {
+		return nil, err
+	}
+
+	snod, err := ParseSymbolTableNode(r, snodAddr, sb)
+	if err != nil {
+		return nil, utils.WrapError("SNOD parse failed", err)
+	}
+
+	index, err := snod.FindEntry(heap, name)
+	if err != nil || index < 0 {
+		return nil, err
+	}
+	entry := snodEntryToBTreeEntry(snod.Entries[index])
+	return &entry, nil
+}
+
+// FindGroupSymbolTableNode returns the address of the symbol table node of a
+// group's v1 B-tree that holds name, if the group has a link with that name.
+// Returns 0 (and no error) if name sorts after every key of the B-tree.
+func FindGroupSymbolTableNode(r io.ReaderAt, address uint64, heap *LocalHeap, name string, sb *core.Superblock) (uint64, error) {
+	node, err := readGroupBTreeNode(r, address, sb)
+	if err != nil {
+		return 0, err
+	}
+
+	// Find the first child whose right key is not before the name.
+	child := -1
+	for i := range node.children {
+		right, err := heap.GetString(node.keys[i+1])
+		if err != nil {
+			return 0, utils.WrapError("B-tree key read failed", err)
+		}
+		if name <= right {
+			child = i
+			break
+		}
+	}
+	if child < 0 {
+		return 0, nil
+	}
+
+	if node.level > 0 {
+		return FindGroupSymbolTableNode(r, node.children[child], heap, name, sb)
+	}
+	return node.children[child], nil
+}
+
+// FindEntry returns the index of the entry named name, or -1 if the node has
+// no such entry. Entries of a symbol table node are sorted by name.
+func (stn *SymbolTableNode) FindEntry(heap *LocalHeap, name string) (int, error) {
+	lo, hi := 0, len(stn.Entries)
+	for lo < hi {
+		mid := (lo + hi) / 2
+		entryName, err := heap.GetString(stn.Entries[mid].LinkNameOffset)
+		if err != nil {
+			return -1, utils.WrapError("link name read failed", err)
+		}
+		switch {
+		case entryName == name:
+			return mid, nil
+		case entryName < name:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+
+	return -1, nil
+}
+
+// groupBTreeNode is a decoded group B-tree node: keys[i] and keys[i+1] bound
+// the names stored below children[i].
+type groupBTreeNode struct {
+	level       uint8
+	left, right uint64 // Sibling nodes of the same level.
+	keys        []uint64
+	children    []uint64
+}
+
+// readGroupBTreeNode reads and validates a group ("TREE", type 0) B-tree node.
+func readGroupBTreeNode(r io.ReaderAt, address uint64, sb *core.Superblock) (*groupBTreeNode, error) {
 	// Read B-tree node header.
 	// Format:
 	// - 4 bytes: Signature ("TREE").
@@ -49,80 +200,63 @@ func ReadGroupBTreeEntries(r io.ReaderAt, address uint64, sb *core.Superblock) (
 		return nil, fmt.Errorf("expected group B-tree (type 0), got type %d", nodeType)
 	}
 
-	// Check node level (we only support leaf nodes for now).
-	nodeLevel := header[5]
-	if nodeLevel != 0 {
-		return nil, errors.New("non-leaf B-tree nodes not supported yet")
+	node := &groupBTreeNode{
+		level: header[5],
+		left:  readAddress(header[8:], int(sb.OffsetSize), sb.Endianness),
+		right: readAddress(header[8+int(sb.OffsetSize):], int(sb.OffsetSize), sb.Endianness),
 	}
 
-	// Read number of entries (this is the number of keys used).
-	entriesUsed := sb.Endianness.Uint16(header[6:8])
+	// Read number of entries (this is the number of children used).
+	entriesUsed := int(sb.Endianness.Uint16(header[6:8]))
 	if entriesUsed == 0 {
-		return nil, nil
+		return node, nil
+	}
+	if maxEntries := 2 * int(sb.SymbolInternalK); maxEntries > 0 && entriesUsed > maxEntries {
+		return nil, fmt.Errorf("group B-tree node has %d entries, more than 2K = %d", entriesUsed, maxEntries)
 	}
 
 	// For group B-trees (type 0), the data after header is:
 	// - Keys and children interleaved: Key[0], Child[0], Key[1], Child[1], ..., Key[N]
 	// - Keys are heap offsets (offsetSize bytes each)
-	// - Children are SNOD addresses (offsetSize bytes each)
-	// - There are (entriesUsed) children and (entriesUsed+1) keys (but last key might be empty)
-	//
-	// For leaf nodes, children point to Symbol Table Nodes (SNODs).
-	// We need to parse each SNOD to get the actual entries.
-
-	// Calculate data size: interleaved keys and children
-	// Pattern: Key[0], Child[0], Key[1], Child[1], ..., Key[entriesUsed], (last child if internal node)
-	// For leaf nodes with N children: N+1 keys and N children
-	// But we read as pairs: (key, child) repeated
-	dataSize := int(entriesUsed) * 2 * int(sb.OffsetSize)  // entriesUsed children + keys interleaved
-	data := utils.GetBuffer(dataSize + int(sb.OffsetSize)) // +1 key at end
+	// - Children are SNOD addresses (leaf) or B-tree node addresses (internal)
+	// - There are (entriesUsed) children and (entriesUsed+1) keys
+	offsetSize := int(sb.OffsetSize)
+	dataSize := (2*entriesUsed + 1) * offsetSize
+	data := utils.GetBuffer(dataSize)
 	defer utils.ReleaseBuffer(data)
 
 	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
 	dataOffset := int64(address) + int64(headerSize)
-	if _, err := r.ReadAt(data[:dataSize+int(sb.OffsetSize)], dataOffset); err != nil {
+	if _, err := r.ReadAt(data[:dataSize], dataOffset); err != nil {
 		return nil, utils.WrapError("B-tree data read failed", err)
 	}
 
-	// Collect all SNOD addresses (children)
-	var snodAddresses []uint64
+	node.keys = make([]uint64, entriesUsed+1)
+	node.children = make([]uint64, entriesUsed)
 	pos := 0
-	for i := uint16(0); i < entriesUsed; i++ {
-		// Skip key (heap offset) - we don't need it for enumeration
-		pos += int(sb.OffsetSize)
-
-		// Read child address (SNOD) using file's endianness
-		childAddr := readAddress(data[pos:], int(sb.OffsetSize), sb.Endianness)
-		pos += int(sb.OffsetSize)
-
-		if childAddr != 0 && childAddr != 0xFFFFFFFFFFFFFFFF {
-			snodAddresses = append(snodAddresses, childAddr)
-		}
+	for i := 0; i < entriesUsed; i++ {
+		node.keys[i] = readAddress(data[pos:], offsetSize, sb.Endianness)
+		pos += offsetSize
+		node.children[i] = readAddress(data[pos:], offsetSize, sb.Endianness)
+		pos += offsetSize
 	}
+	node.keys[entriesUsed] = readAddress(data[pos:], offsetSize, sb.Endianness)
 
-	// Parse each SNOD to collect entries
-	var allEntries []BTreeEntry
-	for _, snodAddr := range snodAddresses {
-		snodNode, err := ParseSymbolTableNode(r, snodAddr, sb)
-		if err != nil {
-			// Skip invalid SNODs
-			continue
-		}
+	return node, nil
+}
 
-		// Convert SNOD entries to BTreeEntry format
-		for _, entry := range snodNode.Entries {
-			allEntries = append(allEntries, BTreeEntry{
-				LinkNameOffset:  entry.LinkNameOffset,
-				ObjectAddress:   entry.ObjectAddress,
-				CacheType:       entry.CacheType,
-				Reserved:        0,
-				CachedBTreeAddr: entry.CachedBTreeAddr,
-				CachedHeapAddr:  entry.CachedHeapAddr,
-			})
-		}
+// snodEntryToBTreeEntry converts a symbol table node entry to BTreeEntry format.
+func snodEntryToBTreeEntry(entry SymbolTableEntry) BTreeEntry {
+	return BTreeEntry{
+		LinkNameOffset:  entry.LinkNameOffset,
+		ObjectAddress:   entry.ObjectAddress,
+		CacheType:       entry.CacheType,
+		Reserved:        0,
+		CachedBTreeAddr: entry.CachedBTreeAddr,
+		CachedHeapAddr:  entry.CachedHeapAddr,
+
+		CachedSoftLinkOffset: entry.CachedSoftLinkOffset,
 	}
-
-	return allEntries, nil
 }
 
 // readAddress reads a variable-sized address from byte slice using the specified endianness.
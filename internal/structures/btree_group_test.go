@@ -293,20 +293,86 @@ func TestReadGroupBTreeEntries_InvalidNodeType(t *testing.T) {
 	}
 }
 
-func TestReadGroupBTreeEntries_NonLeafNode(t *testing.T) {
-	buf := make([]byte, 1024)
-	copy(buf[0:4], "TREE")
-	buf[4] = 0
-	buf[5] = 1 // Level 1 (non-leaf)
-	binary.LittleEndian.PutUint16(buf[6:8], 1)
+// writeTwoLevelGroupBTree builds a level-1 group B-tree over three symbol
+// table nodes holding the links "a" to "f" (object addresses 1000 to 1005).
+func writeTwoLevelGroupBTree(t *testing.T) (*mockReaderAt, *LocalHeap, uint64) {
+	t.Helper()
+
+	names := []string{"a", "b", "c", "d", "e", "f"}
+	heap := &LocalHeap{Data: []byte{0}} // Offset 0 holds the empty string.
+	offsets := make(map[string]uint64)
+	for _, name := range names {
+		offsets[name] = uint64(len(heap.Data))
+		heap.Data = append(heap.Data, name...)
+		heap.Data = append(heap.Data, 0)
+	}
 
-	reader := &mockReaderAt{data: buf}
+	w := &mockWriter{data: make([]byte, 0x2000)}
+	le := binary.LittleEndian
+
+	snodAddrs := []uint64{0x100, 0x400, 0x700}
+	for i, addr := range snodAddrs {
+		snod := NewSymbolTableNode(4)
+		for j := 0; j < 2; j++ {
+			name := names[i*2+j]
+			require.NoError(t, snod.AddEntry(SymbolTableEntry{
+				LinkNameOffset: offsets[name],
+				ObjectAddress:  1000 + uint64(i*2+j),
+			}))
+		}
+		require.NoError(t, snod.WriteAt(w, addr, 8, 4, le))
+	}
+
+	writeNode := func(addr uint64, level uint8, keys []uint64, children []uint64, left, right uint64) {
+		node := NewBTreeNodeV1(0, 2)
+		node.NodeLevel = level
+		node.LeftSibling = left
+		node.RightSibling = right
+		node.Keys = keys
+		node.ChildPointers = children
+		node.EntriesUsed = uint16(len(children))
+		require.NoError(t, node.WriteAt(w, addr, 8, 2, le))
+	}
+	undef := ^uint64(0)
+	writeNode(0x1000, 0, []uint64{0, offsets["b"], offsets["d"]}, snodAddrs[:2], undef, 0x1400)
+	writeNode(0x1400, 0, []uint64{offsets["d"], offsets["f"]}, snodAddrs[2:], 0x1000, undef)
+	writeNode(0x1800, 1, []uint64{0, offsets["d"], offsets["f"]}, []uint64{0x1000, 0x1400}, undef, undef)
+
+	return &mockReaderAt{data: w.data}, heap, 0x1800
+}
+
+func TestReadGroupBTreeEntries_MultiLevel(t *testing.T) {
+	reader, heap, root := writeTwoLevelGroupBTree(t)
 	sb := createMockSuperblock()
 
-	entries, err := ReadGroupBTreeEntries(reader, 0, sb)
-	require.Error(t, err)
-	require.Nil(t, entries)
-	require.Contains(t, err.Error(), "non-leaf B-tree nodes not supported")
+	entries, err := ReadGroupBTreeEntries(reader, root, sb)
+	require.NoError(t, err)
+	require.Len(t, entries, 6)
+
+	for i, entry := range entries {
+		name, err := heap.GetString(entry.LinkNameOffset)
+		require.NoError(t, err)
+		require.Equal(t, string(rune('a'+i)), name)
+		require.Equal(t, uint64(1000+i), entry.ObjectAddress)
+	}
+}
+
+func TestFindGroupBTreeEntry(t *testing.T) {
+	reader, heap, root := writeTwoLevelGroupBTree(t)
+	sb := createMockSuperblock()
+
+	for i, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		entry, err := FindGroupBTreeEntry(reader, root, heap, name, sb)
+		require.NoError(t, err)
+		require.NotNil(t, entry, name)
+		require.Equal(t, uint64(1000+i), entry.ObjectAddress, name)
+	}
+
+	for _, name := range []string{"", "0", "bb", "g"} {
+		entry, err := FindGroupBTreeEntry(reader, root, heap, name, sb)
+		require.NoError(t, err)
+		require.Nil(t, entry, name)
+	}
 }
 
 func TestReadGroupBTreeEntries_ReadErrors(t *testing.T) {
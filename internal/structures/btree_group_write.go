@@ -0,0 +1,264 @@
+package structures
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// groupSplit describes a node split in two while inserting into a group
+// B-tree: the new node at addr holds the names after key.
+type groupSplit struct {
+	key  uint64
+	addr uint64
+}
+
+// groupInsert holds what inserting a link into a group B-tree needs.
+type groupInsert struct {
+	r     io.ReaderAt
+	w     io.WriterAt
+	alloc Allocator
+	heap  *LocalHeap
+	sb    *core.Superblock
+	name  string
+	entry SymbolTableEntry
+}
+
+// InsertGroupSymbolTableEntry inserts entry into the v1 B-tree at address of
+// a group whose link names are stored in heap. The name of the entry, at
+// entry.LinkNameOffset, is name; it may not be in heap yet.
+//
+// Entries are kept sorted by name. Full symbol table nodes and B-tree nodes
+// are split in two, with the new nodes allocated from alloc; the root node
+// stays at address, so the group's Symbol Table message is unchanged.
+//
+// Reference: H5B.c - H5B_insert(), H5Gnode.c - H5G__node_insert().
+func InsertGroupSymbolTableEntry(r io.ReaderAt, w io.WriterAt, alloc Allocator, address uint64, heap *LocalHeap,
+	name string, entry SymbolTableEntry, sb *core.Superblock,
+) error {
+	ins := &groupInsert{r: r, w: w, alloc: alloc, heap: heap, sb: sb, name: name, entry: entry}
+	split, err := ins.insert(address)
+	if err != nil || split == nil {
+		return err
+	}
+
+	// The root was split: move its left half to a new node and make the
+	// root the parent of both halves.
+	left, err := readGroupBTreeNode(r, address, sb)
+	if err != nil {
+		return err
+	}
+	right, err := readGroupBTreeNode(r, split.addr, sb)
+	if err != nil {
+		return err
+	}
+	leftAddr, err := alloc.Allocate(ins.btreeNodeSize())
+	if err != nil {
+		return fmt.Errorf("allocate B-tree node: %w", err)
+	}
+	if err := ins.writeNode(leftAddr, left); err != nil {
+		return err
+	}
+	right.left = leftAddr
+	if err := ins.writeNode(split.addr, right); err != nil {
+		return err
+	}
+
+	root := &groupBTreeNode{
+		level:    left.level + 1,
+		left:     undefinedAddress,
+		right:    undefinedAddress,
+		keys:     []uint64{left.keys[0], split.key, right.keys[len(right.keys)-1]},
+		children: []uint64{leftAddr, split.addr},
+	}
+	return ins.writeNode(address, root)
+}
+
+// undefinedAddress marks a missing sibling node.
+const undefinedAddress = ^uint64(0)
+
+// insert inserts the entry below the B-tree node at addr. If the node had
+// to be split, the returned split gives its new right half.
+func (ins *groupInsert) insert(addr uint64) (*groupSplit, error) {
+	node, err := readGroupBTreeNode(ins.r, addr, ins.sb)
+	if err != nil {
+		return nil, err
+	}
+	if len(node.children) == 0 {
+		return nil, fmt.Errorf("group B-tree node at 0x%x has no children", addr)
+	}
+
+	// Find the first child whose right key is not before the name. Names
+	// after every key go to the last child, whose right key is raised.
+	child := len(node.children) - 1
+	raise := true
+	for i := range node.children {
+		right, err := ins.heap.GetString(node.keys[i+1])
+		if err != nil {
+			return nil, fmt.Errorf("B-tree key read failed: %w", err)
+		}
+		if ins.name <= right {
+			child, raise = i, false
+			break
+		}
+	}
+
+	var split *groupSplit
+	if node.level == 0 {
+		split, err = ins.insertIntoNode(node.children[child])
+	} else {
+		split, err = ins.insert(node.children[child])
+	}
+	if err != nil {
+		return nil, err
+	}
+	if raise {
+		node.keys[len(node.keys)-1] = ins.entry.LinkNameOffset
+	}
+	if split == nil {
+		if !raise {
+			return nil, nil
+		}
+		return nil, ins.writeNode(addr, node)
+	}
+
+	// The new node follows the one that was split.
+	node.keys = insertUint64(node.keys, child+1, split.key)
+	node.children = insertUint64(node.children, child+1, split.addr)
+	if len(node.children) <= 2*int(ins.sb.SymbolInternalK) {
+		return nil, ins.writeNode(addr, node)
+	}
+
+	half := len(node.children) / 2
+	newAddr, err := ins.alloc.Allocate(ins.btreeNodeSize())
+	if err != nil {
+		return nil, fmt.Errorf("allocate B-tree node: %w", err)
+	}
+	right := &groupBTreeNode{
+		level:    node.level,
+		left:     addr,
+		right:    node.right,
+		keys:     append([]uint64(nil), node.keys[half:]...),
+		children: append([]uint64(nil), node.children[half:]...),
+	}
+	if ins.defined(node.right) {
+		if err := ins.writeLeftSibling(node.right, newAddr); err != nil {
+			return nil, err
+		}
+	}
+	node.right = newAddr
+	node.keys = node.keys[:half+1]
+	node.children = node.children[:half]
+	if err := ins.writeNode(newAddr, right); err != nil {
+		return nil, err
+	}
+	if err := ins.writeNode(addr, node); err != nil {
+		return nil, err
+	}
+	return &groupSplit{key: node.keys[half], addr: newAddr}, nil
+}
+
+// insertIntoNode inserts the entry into the symbol table node at addr,
+// splitting it if it is full.
+func (ins *groupInsert) insertIntoNode(addr uint64) (*groupSplit, error) {
+	snod, err := ParseSymbolTableNode(ins.r, addr, ins.sb)
+	if err != nil {
+		return nil, fmt.Errorf("read symbol table node: %w", err)
+	}
+
+	index := len(snod.Entries)
+	for i := range snod.Entries {
+		name, err := ins.heap.GetString(snod.Entries[i].LinkNameOffset)
+		if err != nil {
+			return nil, fmt.Errorf("read link name: %w", err)
+		}
+		if name == ins.name {
+			return nil, fmt.Errorf("link %q already exists", ins.name)
+		}
+		if ins.name < name {
+			index = i
+			break
+		}
+	}
+
+	capacity := 2 * ins.sb.SymbolLeafK
+	entries := append(append(append([]SymbolTableEntry(nil), snod.Entries[:index]...), ins.entry), snod.Entries[index:]...)
+	if len(entries) <= int(capacity) {
+		return nil, ins.writeSymbolTableNode(addr, entries)
+	}
+
+	half := len(entries) / 2
+	newAddr, err := ins.alloc.Allocate(8 + uint64(capacity)*uint64(2*int(ins.sb.OffsetSize)+24))
+	if err != nil {
+		return nil, fmt.Errorf("allocate symbol table node: %w", err)
+	}
+	if err := ins.writeSymbolTableNode(newAddr, entries[half:]); err != nil {
+		return nil, err
+	}
+	if err := ins.writeSymbolTableNode(addr, entries[:half]); err != nil {
+		return nil, err
+	}
+	return &groupSplit{key: entries[half-1].LinkNameOffset, addr: newAddr}, nil
+}
+
+// writeSymbolTableNode writes a symbol table node holding entries.
+func (ins *groupInsert) writeSymbolTableNode(addr uint64, entries []SymbolTableEntry) error {
+	capacity := 2 * ins.sb.SymbolLeafK
+	snod := NewSymbolTableNode(capacity)
+	for _, entry := range entries {
+		if err := snod.AddEntry(entry); err != nil {
+			return err
+		}
+	}
+	if err := snod.WriteAt(ins.w, addr, ins.sb.OffsetSize, capacity, ins.sb.Endianness); err != nil {
+		return fmt.Errorf("write symbol table node: %w", err)
+	}
+	return nil
+}
+
+// btreeNodeSize returns the size of a group B-tree node, which always has
+// room for 2K children.
+func (ins *groupInsert) btreeNodeSize() uint64 {
+	k := uint64(ins.sb.SymbolInternalK)
+	return 8 + 2*uint64(ins.sb.OffsetSize) + (4*k+1)*uint64(ins.sb.OffsetSize)
+}
+
+// defined reports whether the sibling address addr points to a node.
+func (ins *groupInsert) defined(addr uint64) bool {
+	return addr != 0 && addr != undefinedAddress>>(64-8*uint(ins.sb.OffsetSize))
+}
+
+// writeNode writes a group B-tree node.
+func (ins *groupInsert) writeNode(addr uint64, node *groupBTreeNode) error {
+	btn := NewBTreeNodeV1(0, ins.sb.SymbolInternalK)
+	btn.NodeLevel = node.level
+	btn.LeftSibling = node.left
+	btn.RightSibling = node.right
+	btn.Keys = node.keys
+	btn.ChildPointers = node.children
+	btn.EntriesUsed = uint16(len(node.children)) //nolint:gosec // G115: at most 2K children
+	if err := btn.WriteAt(ins.w, addr, ins.sb.OffsetSize, ins.sb.SymbolInternalK, ins.sb.Endianness); err != nil {
+		return fmt.Errorf("write B-tree node: %w", err)
+	}
+	return nil
+}
+
+// writeLeftSibling sets the left sibling of the B-tree node at addr.
+func (ins *groupInsert) writeLeftSibling(addr, sibling uint64) error {
+	buf := make([]byte, ins.sb.OffsetSize)
+	writeAddr(buf, sibling, len(buf), ins.sb.Endianness)
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.WriterAt interface
+	if _, err := ins.w.WriteAt(buf, int64(addr)+8); err != nil {
+		return fmt.Errorf("write B-tree sibling: %w", err)
+	}
+	return nil
+}
+
+// insertUint64 inserts v into s at index i.
+func insertUint64(s []uint64, i int, v uint64) []uint64 {
+	s = append(s, 0)
+	copy(s[i+1:], s[i:])
+	s[i] = v
+	return s
+}
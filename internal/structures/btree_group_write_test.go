@@ -0,0 +1,93 @@
+package structures
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestInsertGroupSymbolTableEntry(t *testing.T) {
+	reader, heap, root := writeTwoLevelGroupBTree(t)
+	reader.data = append(reader.data, make([]byte, 0x20000)...)
+	w := &mockWriter{data: reader.data}
+	alloc := &mockAllocator{nextAddr: 0x2000}
+	sb := createMockSuperblock()
+	sb.SymbolLeafK = 2
+	sb.SymbolInternalK = 2
+
+	// Names before, between and after the existing ones, enough to split
+	// the root twice.
+	want := map[string]uint64{"a": 1000, "b": 1001, "c": 1002, "d": 1003, "e": 1004, "f": 1005}
+	var names []string
+	for _, prefix := range []string{"0", "c", "g", "z", "b"} {
+		for i := 0; i < 8; i++ {
+			names = append(names, prefix+string(rune('a'+i)))
+		}
+	}
+	for i, name := range names {
+		offset := uint64(len(heap.Data))
+		heap.Data = append(append(heap.Data, name...), 0)
+		entry := SymbolTableEntry{LinkNameOffset: offset, ObjectAddress: uint64(2000 + i)}
+		require.NoError(t, InsertGroupSymbolTableEntry(reader, w, alloc, root, heap, name, entry, sb), name)
+		want[name] = entry.ObjectAddress
+	}
+
+	sorted := make([]string, 0, len(want))
+	for name := range want {
+		sorted = append(sorted, name)
+	}
+	sort.Strings(sorted)
+
+	entries, err := ReadGroupBTreeEntries(reader, root, sb)
+	require.NoError(t, err)
+	got := make([]string, len(entries))
+	for i, entry := range entries {
+		got[i], err = heap.GetString(entry.LinkNameOffset)
+		require.NoError(t, err)
+		require.Equal(t, want[got[i]], entry.ObjectAddress, got[i])
+	}
+	require.Equal(t, sorted, got)
+
+	for name, addr := range want {
+		entry, err := FindGroupBTreeEntry(reader, root, heap, name, sb)
+		require.NoError(t, err)
+		require.NotNil(t, entry, name)
+		require.Equal(t, addr, entry.ObjectAddress, name)
+	}
+
+	// The leaves are linked in name order.
+	node, err := readGroupBTreeNode(reader, root, sb)
+	require.NoError(t, err)
+	require.Greater(t, node.level, uint8(1))
+	addr := root
+	for node.level > 0 {
+		addr = node.children[0]
+		node, err = readGroupBTreeNode(reader, addr, sb)
+		require.NoError(t, err)
+	}
+	var linked []string
+	for {
+		for _, child := range node.children {
+			snod, err := ParseSymbolTableNode(reader, child, sb)
+			require.NoError(t, err)
+			for _, entry := range snod.Entries {
+				name, err := heap.GetString(entry.LinkNameOffset)
+				require.NoError(t, err)
+				linked = append(linked, name)
+			}
+		}
+		if node.right == undefinedAddress || node.right == 0 {
+			break
+		}
+		next, err := readGroupBTreeNode(reader, node.right, sb)
+		require.NoError(t, err)
+		require.Equal(t, addr, next.left)
+		addr, node = node.right, next
+	}
+	require.Equal(t, sorted, linked)
+
+	// Names are unique.
+	entry := SymbolTableEntry{LinkNameOffset: 1, ObjectAddress: 3000}
+	require.ErrorContains(t, InsertGroupSymbolTableEntry(reader, w, alloc, root, heap, "a", entry, sb), "already exists")
+}
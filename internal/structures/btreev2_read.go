@@ -0,0 +1,123 @@
+package structures
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/utils"
+)
+
+// openLinkNameIndex opens a B-tree v2 and checks that it is a link name
+// index (type 5).
+func openLinkNameIndex(r io.ReaderAt, headerAddr uint64, sb *core.Superblock) (*core.BTreeV2, error) {
+	bt, err := core.OpenBTreeV2(r, headerAddr, sb)
+	if err != nil {
+		return nil, err
+	}
+	if bt.Type != BTreeV2TypeLinkNameIndex {
+		return nil, fmt.Errorf("%w: expected type %d, got %d", ErrInvalidBTreeType, BTreeV2TypeLinkNameIndex, bt.Type)
+	}
+	return bt, nil
+}
+
+// ReadLinkNameRecords returns all records of a link name index B-tree v2
+// (type 5) in hash order. Trees of any depth are supported.
+func ReadLinkNameRecords(r io.ReaderAt, headerAddr uint64, sb *core.Superblock) ([]LinkNameRecord, error) {
+	bt, err := openLinkNameIndex(r, headerAddr, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LinkNameRecord, 0, bt.TotalRecords)
+	err = bt.ForEach(func(rec []byte) error {
+		records = append(records, decodeLinkNameRecord(rec))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// FindLinkNameRecords searches a link name index B-tree v2 (type 5) for the
+// records of the given link name. Only the nodes on the path to the name's
+// hash are read, so the cost is logarithmic in the number of links.
+//
+// Several names can share a hash, so callers must compare the name stored in
+// the link message that each returned heap ID points to.
+func FindLinkNameRecords(r io.ReaderAt, headerAddr uint64, name string, sb *core.Superblock) ([]LinkNameRecord, error) {
+	bt, err := openLinkNameIndex(r, headerAddr, sb)
+	if err != nil {
+		return nil, err
+	}
+
+	hash := utils.JenkinsChecksum([]byte(name))
+	raw, err := bt.Find(func(rec []byte) int {
+		h := binary.LittleEndian.Uint32(rec[0:4])
+		switch {
+		case h < hash:
+			return -1
+		case h > hash:
+			return 1
+		default:
+			return 0
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	records := make([]LinkNameRecord, len(raw))
+	for i, rec := range raw {
+		records[i] = decodeLinkNameRecord(rec)
+	}
+	return records, nil
+}
+
+// LinkCreationOrderRecord is a record of a link creation order index B-tree
+// v2 (type 6).
+//
+// Reference: H5Gbtree2.c - H5G_dense_btree2_corder_rec_t.
+type LinkCreationOrderRecord struct {
+	CreationOrder int64   // Creation order of the link
+	HeapID        [7]byte // Fractal heap ID of the link message
+}
+
+// ReadLinkCreationOrderRecords returns all records of a link creation order
+// index B-tree v2 (type 6), in creation order.
+func ReadLinkCreationOrderRecords(r io.ReaderAt, headerAddr uint64, sb *core.Superblock) ([]LinkCreationOrderRecord, error) {
+	bt, err := core.OpenBTreeV2(r, headerAddr, sb)
+	if err != nil {
+		return nil, err
+	}
+	if bt.Type != BTreeV2TypeLinkOrderIndex {
+		return nil, fmt.Errorf("%w: expected type %d, got %d", ErrInvalidBTreeType, BTreeV2TypeLinkOrderIndex, bt.Type)
+	}
+
+	records := make([]LinkCreationOrderRecord, 0, bt.TotalRecords)
+	err = bt.ForEach(func(rec []byte) error {
+		if len(rec) < 15 {
+			return fmt.Errorf("creation order record too short: %d bytes", len(rec))
+		}
+		var r LinkCreationOrderRecord
+		//nolint:gosec // G115: HDF5 binary format requires uint64 to int64 conversion
+		r.CreationOrder = int64(binary.LittleEndian.Uint64(rec[0:8]))
+		copy(r.HeapID[:], rec[8:15])
+		records = append(records, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// decodeLinkNameRecord decodes a type 5 record: hash (4 bytes) + heap ID (7 bytes).
+func decodeLinkNameRecord(rec []byte) LinkNameRecord {
+	var r LinkNameRecord
+	r.NameHash = binary.LittleEndian.Uint32(rec[0:4])
+	copy(r.HeapID[:], rec[4:])
+	return r
+}
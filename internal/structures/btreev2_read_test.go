@@ -0,0 +1,161 @@
+package structures
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+// writeDepth1LinkNameBTree builds a link name index B-tree v2 of depth 1 with
+// a 64-byte node size (4 records per leaf, 2 per internal node):
+//
+//	root: r3 r6 -> leaves [r0 r1 r2] [r4 r5] [r7]
+//
+// Each record's heap ID holds the index of its name in names.
+func writeDepth1LinkNameBTree(t *testing.T, names []string) (*mockReaderAt, uint64) {
+	t.Helper()
+	require.Len(t, names, 8)
+
+	type rec struct {
+		hash  uint32
+		index byte
+	}
+	recs := make([]rec, len(names))
+	for i, name := range names {
+		recs[i] = rec{utils.JenkinsChecksum([]byte(name)), byte(i)}
+	}
+	sort.Slice(recs, func(i, j int) bool { return recs[i].hash < recs[j].hash })
+
+	encodeRecords := func(rs ...rec) []byte {
+		var buf []byte
+		for _, r := range rs {
+			buf = binary.LittleEndian.AppendUint32(buf, r.hash)
+			buf = append(buf, r.index, 0, 0, 0, 0, 0, 0)
+		}
+		return buf
+	}
+	withChecksum := func(buf []byte) []byte {
+		return binary.LittleEndian.AppendUint32(buf, utils.JenkinsChecksum(buf))
+	}
+
+	data := make([]byte, 0x400)
+	put := func(addr uint64, buf []byte) { copy(data[addr:], buf) }
+
+	leaves := []struct {
+		addr uint64
+		recs []rec
+	}{
+		{0x100, recs[0:3]},
+		{0x180, recs[4:6]},
+		{0x200, recs[7:8]},
+	}
+	for _, leaf := range leaves {
+		buf := append([]byte("BTLF"), 0, BTreeV2TypeLinkNameIndex)
+		buf = append(buf, encodeRecords(leaf.recs...)...)
+		put(leaf.addr, withChecksum(buf))
+	}
+
+	// Internal node: records, then (address, record count) per child.
+	internal := append([]byte("BTIN"), 0, BTreeV2TypeLinkNameIndex)
+	internal = append(internal, encodeRecords(recs[3], recs[6])...)
+	for _, leaf := range leaves {
+		internal = binary.LittleEndian.AppendUint64(internal, leaf.addr)
+		internal = append(internal, byte(len(leaf.recs)))
+	}
+	put(0x280, withChecksum(internal))
+
+	header := append([]byte("BTHD"), 0, BTreeV2TypeLinkNameIndex)
+	header = binary.LittleEndian.AppendUint32(header, 64) // Node size.
+	header = binary.LittleEndian.AppendUint16(header, 11) // Record size.
+	header = binary.LittleEndian.AppendUint16(header, 1)  // Depth.
+	header = append(header, 100, 40)                      // Split/merge percent.
+	header = binary.LittleEndian.AppendUint64(header, 0x280)
+	header = binary.LittleEndian.AppendUint16(header, 2) // Records in root.
+	header = binary.LittleEndian.AppendUint64(header, uint64(len(recs)))
+	put(0x20, withChecksum(header))
+
+	return &mockReaderAt{data: data}, 0x20
+}
+
+func TestBTreeV2Read_Depth1(t *testing.T) {
+	names := make([]string, 8)
+	for i := range names {
+		names[i] = fmt.Sprintf("link_%d", i)
+	}
+	reader, addr := writeDepth1LinkNameBTree(t, names)
+	sb := createMockSuperblock()
+
+	t.Run("read all records in hash order", func(t *testing.T) {
+		records, err := ReadLinkNameRecords(reader, addr, sb)
+		require.NoError(t, err)
+		require.Len(t, records, 8)
+		for i := 1; i < len(records); i++ {
+			require.Less(t, records[i-1].NameHash, records[i].NameHash)
+		}
+	})
+
+	t.Run("find each name", func(t *testing.T) {
+		for i, name := range names {
+			records, err := FindLinkNameRecords(reader, addr, name, sb)
+			require.NoError(t, err)
+			require.Len(t, records, 1, name)
+			require.Equal(t, byte(i), records[0].HeapID[0], name)
+		}
+	})
+
+	t.Run("missing name", func(t *testing.T) {
+		records, err := FindLinkNameRecords(reader, addr, "no_such_link", sb)
+		require.NoError(t, err)
+		require.Empty(t, records)
+	})
+
+	t.Run("corrupted node", func(t *testing.T) {
+		corrupt := &mockReaderAt{data: append([]byte(nil), reader.data...)}
+		corrupt.data[0x180+6] ^= 0xFF
+		_, err := ReadLinkNameRecords(corrupt, addr, sb)
+		require.ErrorContains(t, err, "checksum mismatch")
+	})
+}
+
+func TestReadLinkCreationOrderRecords(t *testing.T) {
+	withChecksum := func(buf []byte) []byte {
+		return binary.LittleEndian.AppendUint32(buf, utils.JenkinsChecksum(buf))
+	}
+	data := make([]byte, 0x200)
+
+	// Single leaf holding creation orders 0-2, heap IDs tagged 10-12.
+	leaf := append([]byte("BTLF"), 0, BTreeV2TypeLinkOrderIndex)
+	for i := 0; i < 3; i++ {
+		leaf = binary.LittleEndian.AppendUint64(leaf, uint64(i))
+		leaf = append(leaf, byte(10+i), 0, 0, 0, 0, 0, 0)
+	}
+	copy(data[0x100:], withChecksum(leaf))
+
+	header := append([]byte("BTHD"), 0, BTreeV2TypeLinkOrderIndex)
+	header = binary.LittleEndian.AppendUint32(header, 512) // Node size.
+	header = binary.LittleEndian.AppendUint16(header, 15)  // Record size.
+	header = binary.LittleEndian.AppendUint16(header, 0)   // Depth.
+	header = append(header, 100, 40)                       // Split/merge percent.
+	header = binary.LittleEndian.AppendUint64(header, 0x100)
+	header = binary.LittleEndian.AppendUint16(header, 3) // Records in root.
+	header = binary.LittleEndian.AppendUint64(header, 3)
+	copy(data[0x20:], withChecksum(header))
+
+	sb := createMockSuperblock()
+	records, err := ReadLinkCreationOrderRecords(&mockReaderAt{data: data}, 0x20, sb)
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	for i, rec := range records {
+		require.Equal(t, int64(i), rec.CreationOrder)
+		require.Equal(t, byte(10+i), rec.HeapID[0])
+	}
+
+	names := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	reader, addr := writeDepth1LinkNameBTree(t, names)
+	_, err = ReadLinkCreationOrderRecords(reader, addr, sb)
+	require.ErrorIs(t, err, ErrInvalidBTreeType)
+}
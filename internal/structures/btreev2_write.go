@@ -33,8 +33,9 @@ const (
 	BTreeV2HeaderSignature = "BTHD" // B-tree v2 header signature
 	BTreeV2LeafSignature   = "BTLF" // B-tree v2 leaf node signature
 
-	BTreeV2TypeLinkNameIndex = uint8(5) // Type 5 = Link Name Index for dense groups
-	BTreeV2TypeAttrNameIndex = uint8(8) // Type 8 = Attribute Name Index for dense attributes
+	BTreeV2TypeLinkNameIndex  = uint8(5) // Type 5 = Link Name Index for dense groups
+	BTreeV2TypeLinkOrderIndex = uint8(6) // Type 6 = Link Creation Order Index for dense groups
+	BTreeV2TypeAttrNameIndex  = uint8(8) // Type 8 = Attribute Name Index for dense attributes
 
 	DefaultBTreeV2NodeSize     = uint32(4096) // 4KB default node size
 	DefaultBTreeV2SplitPercent = uint8(100)   // Split at 100% full
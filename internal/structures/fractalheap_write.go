@@ -1152,6 +1152,46 @@ func (fh *WritableFractalHeap) DeleteObject(heapID []byte) error {
 	return nil
 }
 
+// DeleteObjectSpecCompliant deletes an object addressed by a heap ID that
+// counts its offset from the start of the direct block, as official HDF5
+// files do. The offset is adjusted the same way FractalHeap's
+// ReadObjectSpecCompliant adjusts it, so the deleted object is the one
+// readers resolve the ID to.
+func (fh *WritableFractalHeap) DeleteObjectSpecCompliant(heapID []byte, sizeofAddr uint8) error {
+	if len(heapID) < 1+int(fh.Header.HeapOffsetSize) {
+		return ErrInvalidObjectID
+	}
+
+	headerSize := 5 + uint64(sizeofAddr) + uint64(fh.Header.HeapOffsetSize)
+	offset := readUint(heapID[1:1+int(fh.Header.HeapOffsetSize)], int(fh.Header.HeapOffsetSize), binary.LittleEndian)
+	if offset < headerSize {
+		return fh.DeleteObject(heapID)
+	}
+
+	adjusted := make([]byte, len(heapID))
+	copy(adjusted, heapID)
+	writeUintVar(adjusted[1:], offset-headerSize, int(fh.Header.HeapOffsetSize), binary.LittleEndian)
+	return fh.DeleteObject(adjusted)
+}
+
+// SpecCompliantHeapID converts a heap ID returned by InsertObject, whose
+// offset counts from the start of the block data, to one counting from the
+// start of the direct block, as official HDF5 files and FractalHeap's
+// ReadObjectSpecCompliant expect.
+func (fh *WritableFractalHeap) SpecCompliantHeapID(heapID []byte, sizeofAddr uint8) ([]byte, error) {
+	if len(heapID) < 1+int(fh.Header.HeapOffsetSize) {
+		return nil, ErrInvalidObjectID
+	}
+
+	headerSize := 5 + uint64(sizeofAddr) + uint64(fh.Header.HeapOffsetSize)
+	offset := readUint(heapID[1:1+int(fh.Header.HeapOffsetSize)], int(fh.Header.HeapOffsetSize), binary.LittleEndian)
+
+	adjusted := make([]byte, len(heapID))
+	copy(adjusted, heapID)
+	writeUintVar(adjusted[1:], offset+headerSize, int(fh.Header.HeapOffsetSize), binary.LittleEndian)
+	return adjusted, nil
+}
+
 // writeUintVar writes a variable-length unsigned integer.
 func writeUintVar(buf []byte, value uint64, size int, endianness binary.ByteOrder) {
 	switch size {
@@ -0,0 +1,305 @@
+package structures
+
+import (
+	"cmp"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"slices"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/utils"
+)
+
+// Free-space manager clients (H5FS_client_t in the HDF5 library).
+const (
+	FreeSpaceClientFractalHeap uint8 = 0
+	FreeSpaceClientFile        uint8 = 1
+)
+
+// Free-space manager parameters the HDF5 library uses for file space
+// (H5MF_FSPACE_SHRINK and H5MF_FSPACE_EXPAND, and the simple, small and large
+// section classes).
+const (
+	fileSpaceShrinkPercent = 80
+	fileSpaceExpandPercent = 120
+	fileSpaceClasses       = 3
+)
+
+// FreeSpaceSection is a free range of file space.
+type FreeSpaceSection struct {
+	Offset uint64
+	Size   uint64
+}
+
+// FreeSpaceManager is a free-space manager stored in the file. It consists of
+// a header ("FSHD") and a section info block ("FSSE") listing the sections.
+//
+// Header format:
+//   - Signature "FSHD" (4 bytes), version 0 (1 byte), client ID (1 byte)
+//   - Total space, total section count, serialized and ghost section counts (lengthSize bytes each)
+//   - Number of section classes, shrink percent, expand percent, address space bits (2 bytes each)
+//   - Maximum section size (lengthSize bytes)
+//   - Section info address (offsetSize bytes), used and allocated size (lengthSize bytes each)
+//   - Checksum (4 bytes)
+//
+// Section info format:
+//   - Signature "FSSE" (4 bytes), version 0 (1 byte), header address (offsetSize bytes)
+//   - For each section size, in increasing order: number of sections and size,
+//     then for each section its offset and class (1 byte)
+//   - Checksum (4 bytes)
+//
+// The number of sections, section sizes and offsets use the smallest widths
+// that fit the header's serialized section count, maximum section size and
+// address space bits.
+//
+// Reference: HDF5 Format Spec Sections III.G and III.H.
+// C Reference: H5FScache.c - H5FS__cache_hdr_serialize() and H5FS__cache_sinfo_serialize().
+type FreeSpaceManager struct {
+	HeaderAddress   uint64
+	SectionsAddress uint64 // UNDEF when the manager has no sections
+	SectionsSize    uint64 // Allocated size of the section info block
+	Sections        []FreeSpaceSection
+}
+
+// ReadFreeSpaceManager reads a file free-space manager and its sections.
+func ReadFreeSpaceManager(r io.ReaderAt, address uint64, sb *core.Superblock) (*FreeSpaceManager, error) {
+	offsetSize, lengthSize := int(sb.OffsetSize), int(sb.LengthSize)
+
+	size := FreeSpaceHeaderSize(sb)
+	buf := make([]byte, size)
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
+	if _, err := r.ReadAt(buf, int64(address)); err != nil {
+		return nil, utils.WrapError("free-space header read failed", err)
+	}
+
+	if string(buf[0:4]) != "FSHD" {
+		return nil, fmt.Errorf("invalid free-space header signature: %q", buf[0:4])
+	}
+	if buf[4] != 0 {
+		return nil, fmt.Errorf("unsupported free-space header version: %d", buf[4])
+	}
+	if buf[5] != FreeSpaceClientFile {
+		return nil, fmt.Errorf("unsupported free-space manager client: %d", buf[5])
+	}
+	if err := verifyFreeSpaceChecksum(buf, "free-space header"); err != nil {
+		return nil, err
+	}
+
+	pos := 6 + lengthSize // Skip total space
+	pos += lengthSize     // Skip total section count
+	serialCount := readUint(buf[pos:], lengthSize, sb.Endianness)
+	pos += 2 * lengthSize // Skip ghost section count
+	pos += 3 * 2          // Skip classes, shrink and expand percent
+	addrBits := sb.Endianness.Uint16(buf[pos : pos+2])
+	pos += 2
+	maxSectSize := readUint(buf[pos:], lengthSize, sb.Endianness)
+	pos += lengthSize
+
+	fsm := &FreeSpaceManager{HeaderAddress: address}
+	fsm.SectionsAddress = readUint(buf[pos:], offsetSize, sb.Endianness)
+	pos += offsetSize
+	sectSize := readUint(buf[pos:], lengthSize, sb.Endianness)
+	pos += lengthSize
+	fsm.SectionsSize = readUint(buf[pos:], lengthSize, sb.Endianness)
+
+	if serialCount == 0 || sectSize == 0 {
+		return fsm, nil
+	}
+
+	cntSize, lenSize, offSize := sectionWidths(serialCount, maxSectSize, addrBits)
+	sections, err := readFreeSpaceSections(r, fsm.SectionsAddress, sectSize, serialCount,
+		cntSize, lenSize, offSize, sb)
+	if err != nil {
+		return nil, err
+	}
+	fsm.Sections = sections
+
+	return fsm, nil
+}
+
+// readFreeSpaceSections reads the section info block of a free-space manager.
+func readFreeSpaceSections(r io.ReaderAt, address, size, count uint64, cntSize, lenSize, offSize int,
+	sb *core.Superblock) ([]FreeSpaceSection, error) {
+	if size < uint64(9+int(sb.OffsetSize)) || size > 1<<30 {
+		return nil, fmt.Errorf("invalid free-space section info size: %d", size)
+	}
+	buf := make([]byte, size)
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.ReaderAt interface
+	if _, err := r.ReadAt(buf, int64(address)); err != nil {
+		return nil, utils.WrapError("free-space section info read failed", err)
+	}
+
+	if string(buf[0:4]) != "FSSE" {
+		return nil, fmt.Errorf("invalid free-space section info signature: %q", buf[0:4])
+	}
+	if buf[4] != 0 {
+		return nil, fmt.Errorf("unsupported free-space section info version: %d", buf[4])
+	}
+	if err := verifyFreeSpaceChecksum(buf, "free-space section info"); err != nil {
+		return nil, err
+	}
+
+	end := len(buf) - 4
+	pos := 5 + int(sb.OffsetSize)
+	sections := make([]FreeSpaceSection, 0, count)
+	for uint64(len(sections)) < count {
+		if pos+cntSize+lenSize > end {
+			return nil, errors.New("free-space section info truncated")
+		}
+		n := readUint(buf[pos:], cntSize, binary.LittleEndian)
+		pos += cntSize
+		sectSize := readUint(buf[pos:], lenSize, binary.LittleEndian)
+		pos += lenSize
+
+		if n == 0 || n > count-uint64(len(sections)) {
+			return nil, fmt.Errorf("invalid free-space section count: %d", n)
+		}
+		for ; n > 0; n-- {
+			if pos+offSize+1 > end {
+				return nil, errors.New("free-space section info truncated")
+			}
+			offset := readUint(buf[pos:], offSize, binary.LittleEndian)
+			pos += offSize + 1 // File space section classes store no data
+			sections = append(sections, FreeSpaceSection{Offset: offset, Size: sectSize})
+		}
+	}
+
+	return sections, nil
+}
+
+// WriteFreeSpaceManager writes a file free-space manager holding the given
+// sections: its header at headerAddr and the section info block at
+// sectionsAddr. The caller allocates FreeSpaceHeaderSize and
+// FreeSpaceSectionsSize bytes for them.
+func WriteFreeSpaceManager(w io.WriterAt, headerAddr, sectionsAddr uint64, sections []FreeSpaceSection,
+	sb *core.Superblock) error {
+	offsetSize, lengthSize := int(sb.OffsetSize), int(sb.LengthSize)
+
+	sorted := slices.Clone(sections)
+	slices.SortFunc(sorted, func(a, b FreeSpaceSection) int {
+		if a.Size != b.Size {
+			return cmp.Compare(a.Size, b.Size)
+		}
+		return cmp.Compare(a.Offset, b.Offset)
+	})
+
+	count := uint64(len(sorted))
+	addrBits, maxSectSize := fileSpaceLimits(sb)
+	cntSize, lenSize, offSize := sectionWidths(count, maxSectSize, addrBits)
+	sectSize := FreeSpaceSectionsSize(sorted, sb)
+
+	// Section info block
+	sect := make([]byte, sectSize)
+	copy(sect[0:4], "FSSE")
+	writeUintVar(sect[5:], headerAddr, offsetSize, sb.Endianness)
+	pos := 5 + offsetSize
+	var total uint64
+	for i := 0; i < len(sorted); {
+		j := i
+		for j < len(sorted) && sorted[j].Size == sorted[i].Size {
+			j++
+		}
+		writeUintVar(sect[pos:], uint64(j-i), cntSize, binary.LittleEndian)
+		pos += cntSize
+		writeUintVar(sect[pos:], sorted[i].Size, lenSize, binary.LittleEndian)
+		pos += lenSize
+		for _, s := range sorted[i:j] {
+			writeUintVar(sect[pos:], s.Offset, offSize, binary.LittleEndian)
+			pos += offSize + 1 // Simple section class (0)
+			total += s.Size
+		}
+		i = j
+	}
+	binary.LittleEndian.PutUint32(sect[pos:], utils.JenkinsChecksum(sect[:pos]))
+
+	// Header
+	hdr := make([]byte, FreeSpaceHeaderSize(sb))
+	copy(hdr[0:4], "FSHD")
+	hdr[5] = FreeSpaceClientFile
+	pos = 6
+	for _, v := range []uint64{total, count, count, 0} {
+		writeUintVar(hdr[pos:], v, lengthSize, sb.Endianness)
+		pos += lengthSize
+	}
+	for _, v := range []uint16{fileSpaceClasses, fileSpaceShrinkPercent, fileSpaceExpandPercent, addrBits} {
+		sb.Endianness.PutUint16(hdr[pos:pos+2], v)
+		pos += 2
+	}
+	writeUintVar(hdr[pos:], maxSectSize, lengthSize, sb.Endianness)
+	pos += lengthSize
+	writeUintVar(hdr[pos:], sectionsAddr, offsetSize, sb.Endianness)
+	pos += offsetSize
+	writeUintVar(hdr[pos:], sectSize, lengthSize, sb.Endianness)
+	pos += lengthSize
+	writeUintVar(hdr[pos:], sectSize, lengthSize, sb.Endianness)
+	pos += lengthSize
+	binary.LittleEndian.PutUint32(hdr[pos:], utils.JenkinsChecksum(hdr[:pos]))
+
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.WriterAt interface
+	if _, err := w.WriteAt(sect, int64(sectionsAddr)); err != nil {
+		return fmt.Errorf("failed to write free-space section info: %w", err)
+	}
+	//nolint:gosec // G115: HDF5 addresses fit in int64 for io.WriterAt interface
+	if _, err := w.WriteAt(hdr, int64(headerAddr)); err != nil {
+		return fmt.Errorf("failed to write free-space header: %w", err)
+	}
+
+	return nil
+}
+
+// FreeSpaceHeaderSize returns the size of a free-space manager header.
+func FreeSpaceHeaderSize(sb *core.Superblock) uint64 {
+	return uint64(6 + 4*int(sb.LengthSize) + 4*2 + 3*int(sb.LengthSize) + int(sb.OffsetSize) + 4)
+}
+
+// FreeSpaceSectionsSize returns the size of the section info block that
+// WriteFreeSpaceManager writes for the given sections.
+func FreeSpaceSectionsSize(sections []FreeSpaceSection, sb *core.Superblock) uint64 {
+	addrBits, maxSectSize := fileSpaceLimits(sb)
+	cntSize, lenSize, offSize := sectionWidths(uint64(len(sections)), maxSectSize, addrBits)
+
+	sizes := make(map[uint64]struct{}, len(sections))
+	for _, s := range sections {
+		sizes[s.Size] = struct{}{}
+	}
+
+	size := 5 + int(sb.OffsetSize) + 4
+	size += len(sizes) * (cntSize + lenSize)
+	size += len(sections) * (offSize + 1)
+	return uint64(size)
+}
+
+// fileSpaceLimits returns the address space bits and maximum section size the
+// HDF5 library records for file space managers.
+func fileSpaceLimits(sb *core.Superblock) (addrBits uint16, maxSectSize uint64) {
+	addrBits = uint16(8*sb.OffsetSize - 1)
+	return addrBits, uint64(1)<<addrBits - 1
+}
+
+// sectionWidths returns the encoded widths of section counts, sizes and
+// offsets in a section info block (H5FS_SINFO_PREFIX_SIZE and friends).
+func sectionWidths(serialCount, maxSectSize uint64, addrBits uint16) (cntSize, lenSize, offSize int) {
+	return limitEncSize(serialCount), limitEncSize(maxSectSize), int(addrBits+7) / 8
+}
+
+// limitEncSize returns the number of bytes needed to encode values up to
+// limit (H5VM_limit_enc_size).
+func limitEncSize(limit uint64) int {
+	if limit == 0 {
+		return 1
+	}
+	return (bits.Len64(limit)-1)/8 + 1
+}
+
+// verifyFreeSpaceChecksum checks the Jenkins checksum in the last 4 bytes.
+func verifyFreeSpaceChecksum(buf []byte, what string) error {
+	end := len(buf) - 4
+	stored := binary.LittleEndian.Uint32(buf[end:])
+	if computed := utils.JenkinsChecksum(buf[:end]); stored != computed {
+		return fmt.Errorf("%s checksum mismatch: stored 0x%08x, computed 0x%08x", what, stored, computed)
+	}
+	return nil
+}
@@ -1,6 +1,7 @@
 package structures
 
 import (
+	"bytes"
 	"encoding/binary"
 	"fmt"
 
@@ -30,8 +31,14 @@ type LinkMessage struct {
 	// For hard links.
 	ObjectAddress uint64
 
-	// For soft links.
+	// For soft links, and the object path of external links.
 	TargetPath string
+
+	// For external links: the name of the file holding TargetPath.
+	ExternalFile string
+
+	// For user-defined links: the link value, interpreted by the link class.
+	UserData []byte
 }
 
 // Link message flag bits.
@@ -193,22 +200,66 @@ func ParseLinkMessage(data []byte, sb *core.Superblock) (*LinkMessage, error) {
 		msg.TargetPath = string(data[current : current+int(targetLen)])
 		// current is not used after this point.
 
+	case LinkTypeExternal:
+		if current+2 > len(data) {
+			return nil, fmt.Errorf("unexpected end of data reading external link length")
+		}
+		valueLen := int(binary.LittleEndian.Uint16(data[current : current+2]))
+		current += 2
+		if current+valueLen > len(data) {
+			return nil, fmt.Errorf("unexpected end of data reading external link value")
+		}
+		file, target, err := parseExternalLinkValue(data[current:current+valueLen], data[current:])
+		if err != nil {
+			return nil, err
+		}
+		msg.ExternalFile = file
+		msg.TargetPath = target
+
 	default:
-		// External or user-defined links (type >= 64).
-		// For now, just skip the data.
-		// User-defined link data: 2 bytes length + data.
+		// User-defined links (type >= 65): 2 bytes length + data, which only
+		// the registered link class can interpret.
 		if current+2 > len(data) {
 			return nil, fmt.Errorf("unexpected end of data reading user-defined link length")
 		}
-		udLen := binary.LittleEndian.Uint16(data[current : current+2])
-		// Skip user-defined link data: current += 2 + int(udLen).
-		// Not implemented yet, so we don't need to track current.
-		_ = udLen // Acknowledge we read it but don't use it.
+		udLen := int(binary.LittleEndian.Uint16(data[current : current+2]))
+		current += 2
+		if current+udLen > len(data) {
+			return nil, fmt.Errorf("unexpected end of data reading user-defined link value")
+		}
+		msg.UserData = append([]byte(nil), data[current:current+udLen]...)
 	}
 
 	return msg, nil
 }
 
+// parseExternalLinkValue returns the file name and object path of an external
+// link value. The HDF5 library stores a version/flags byte (0) followed by
+// the two strings, each null-terminated. Files written by FileWriter store
+// the file name length in place of the value length, then the file name and
+// a length-prefixed object path; rest holds the data from the file name on.
+//
+// Reference: H5Lexternal.c - H5L__extern_query().
+func parseExternalLinkValue(value, rest []byte) (file, target string, err error) {
+	if len(value) > 0 && value[0] == 0 {
+		parts := bytes.SplitN(value[1:], []byte{0}, 3)
+		if len(parts) < 3 {
+			return "", "", fmt.Errorf("external link value is not two null-terminated strings")
+		}
+		return string(parts[0]), string(parts[1]), nil
+	}
+
+	fileLen := len(value)
+	if fileLen+2 > len(rest) {
+		return "", "", fmt.Errorf("unexpected end of data reading external link path length")
+	}
+	pathLen := int(binary.LittleEndian.Uint16(rest[fileLen : fileLen+2]))
+	if fileLen+2+pathLen > len(rest) {
+		return "", "", fmt.Errorf("unexpected end of data reading external link path")
+	}
+	return string(value), string(rest[fileLen+2 : fileLen+2+pathLen]), nil
+}
+
 // IsHardLink returns true if this is a hard link.
 func (lm *LinkMessage) IsHardLink() bool {
 	return lm.Type == LinkTypeHard
@@ -219,6 +270,11 @@ func (lm *LinkMessage) IsSoftLink() bool {
 	return lm.Type == LinkTypeSoft
 }
 
+// IsExternalLink returns true if this is an external link.
+func (lm *LinkMessage) IsExternalLink() bool {
+	return lm.Type == LinkTypeExternal
+}
+
 // String returns a string representation of the link.
 func (lm *LinkMessage) String() string {
 	switch lm.Type {
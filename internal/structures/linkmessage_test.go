@@ -200,17 +200,17 @@ func TestParseLinkMessage_SoftLink(t *testing.T) {
 }
 
 func TestParseLinkMessage_ExternalLink(t *testing.T) {
-	// Test user-defined/external link (type >= 64)
+	// External link value: version/flags byte, then file and object path,
+	// both null-terminated.
+	value := "\x00other.h5\x00/group/dset\x00"
 	buf := make([]byte, 256)
 	buf[0] = 1 // Version
 	buf[1] = flagNameSize0 | flagStoreLinkType
 	buf[2] = byte(LinkTypeExternal) // Type 64
 	buf[3] = 8                      // Name length
 	copy(buf[4:12], "external")
-	// User-defined data length (2 bytes)
-	binary.LittleEndian.PutUint16(buf[12:14], 20)
-	// User-defined data (we skip parsing it)
-	copy(buf[14:34], "external_link_data..")
+	binary.LittleEndian.PutUint16(buf[12:14], uint16(len(value)))
+	copy(buf[14:], value)
 
 	sb := createMockSuperblock()
 	msg, err := ParseLinkMessage(buf, sb)
@@ -220,6 +220,46 @@ func TestParseLinkMessage_ExternalLink(t *testing.T) {
 	require.Equal(t, "external", msg.Name)
 	require.False(t, msg.IsHardLink())
 	require.False(t, msg.IsSoftLink())
+	require.True(t, msg.IsExternalLink())
+	require.Equal(t, "other.h5", msg.ExternalFile)
+	require.Equal(t, "/group/dset", msg.TargetPath)
+}
+
+func TestParseLinkMessage_ExternalLinkLengthPrefixed(t *testing.T) {
+	// Value as written by FileWriter.CreateExternalLink: length-prefixed
+	// file name and object path.
+	buf := make([]byte, 64)
+	buf[0] = 1 // Version
+	buf[1] = flagNameSize0 | flagStoreLinkType
+	buf[2] = byte(LinkTypeExternal)
+	buf[3] = 3
+	copy(buf[4:7], "ext")
+	binary.LittleEndian.PutUint16(buf[7:9], 8)
+	copy(buf[9:17], "other.h5")
+	binary.LittleEndian.PutUint16(buf[17:19], 5)
+	copy(buf[19:24], "/dset")
+
+	msg, err := ParseLinkMessage(buf[:24], createMockSuperblock())
+	require.NoError(t, err)
+	require.Equal(t, "other.h5", msg.ExternalFile)
+	require.Equal(t, "/dset", msg.TargetPath)
+
+	_, err = ParseLinkMessage(buf[:20], createMockSuperblock())
+	require.Error(t, err)
+}
+
+func TestParseLinkMessage_UserDefinedLink(t *testing.T) {
+	buf := []byte{1, flagNameSize0 | flagStoreLinkType | flagStoreCharset, 187, 1, 2, 'u', 'd', 3, 0, 'a', 'b', 'c'}
+
+	msg, err := ParseLinkMessage(buf, createMockSuperblock())
+	require.NoError(t, err)
+	require.Equal(t, LinkType(187), msg.Type)
+	require.Equal(t, uint8(1), msg.CharacterSet)
+	require.Equal(t, "ud", msg.Name)
+	require.Equal(t, []byte("abc"), msg.UserData)
+
+	_, err = ParseLinkMessage(buf[:len(buf)-1], createMockSuperblock())
+	require.Error(t, err)
 }
 
 func TestParseLinkMessage_NameSizeVariants(t *testing.T) {
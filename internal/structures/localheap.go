@@ -71,7 +71,16 @@ func LoadLocalHeap(r io.ReaderAt, address uint64, sb *core.Superblock) (*LocalHe
 	}
 	pos += int(sb.LengthSize)
 
-	// Free list offset (lengthSize bytes) - skip for now
+	// Free list offset (lengthSize bytes)
+	var freeList uint64
+	switch sb.LengthSize {
+	case 2:
+		freeList = uint64(sb.Endianness.Uint16(headerBuf[pos : pos+2]))
+	case 4:
+		freeList = uint64(sb.Endianness.Uint32(headerBuf[pos : pos+4]))
+	case 8:
+		freeList = sb.Endianness.Uint64(headerBuf[pos : pos+8])
+	}
 	pos += int(sb.LengthSize)
 
 	// Data segment address (offsetSize bytes)
@@ -86,8 +95,10 @@ func LoadLocalHeap(r io.ReaderAt, address uint64, sb *core.Superblock) (*LocalHe
 	}
 
 	heap := &LocalHeap{
+		FreeList: freeList,
 		//nolint:gosec // G115: headerSize is calculated from small values (LengthSize, OffsetSize <= 8)
-		HeaderSize: uint64(headerSize),
+		HeaderSize:         uint64(headerSize),
+		DataSegmentAddress: dataSegmentAddr,
 	}
 
 	// Allocate and read data segment from the ACTUAL address in the header
@@ -133,7 +144,7 @@ func (h *LocalHeap) GetString(offset uint64) (string, error) {
 // For MVP:
 //   - No free list management (append-only)
 //   - Strings are stored sequentially with null terminators
-//   - Size is fixed at creation (no dynamic growth)
+//   - Size is fixed at creation; Resize grows the data segment
 func NewLocalHeap(initialSize uint64) *LocalHeap {
 	// Ensure minimum size (at least 16 bytes for alignment)
 	if initialSize < 16 {
@@ -191,6 +202,23 @@ func (h *LocalHeap) AddString(s string) (offset uint64, err error) {
 	return offset, nil
 }
 
+// Available returns the bytes left for strings at the end of the data segment.
+func (h *LocalHeap) Available() uint64 {
+	return h.DataSegmentSize - uint64(len(h.strings))
+}
+
+// Resize gives the heap a data segment of size bytes at address, keeping the
+// strings stored so far at their offsets. The next WriteTo writes the data
+// segment there; address may be the current one when it grew in place.
+func (h *LocalHeap) Resize(address, size uint64) error {
+	if size < uint64(len(h.strings)) {
+		return errors.New("local heap data segment smaller than its strings")
+	}
+	h.DataSegmentAddress = address
+	h.DataSegmentSize = size
+	return nil
+}
+
 // WriteTo writes the local heap to the file at the specified address.
 // This includes the header and the data segment.
 //
@@ -207,10 +235,13 @@ func (h *LocalHeap) AddString(s string) (offset uint64, err error) {
 //
 // The data segment address in the header is set to address + 32.
 func (h *LocalHeap) WriteTo(w io.WriterAt, address uint64) error {
-	// Set data segment address (immediately after header)
+	// Set data segment address (immediately after header), unless the heap
+	// was loaded from a file: its data segment stays where it is.
 	// Header size is 32 bytes for 8-byte addressing (4 + 1 + 3 + 8 + 8 + 8)
 	headerSize := uint64(32)
-	h.DataSegmentAddress = address + headerSize
+	if h.DataSegmentAddress == 0 {
+		h.DataSegmentAddress = address + headerSize
+	}
 
 	// Pad strings buffer to full data segment size
 	if uint64(len(h.strings)) < h.DataSegmentSize {
@@ -309,6 +340,13 @@ func (h *LocalHeap) PrepareForModification() error {
 		}
 	}
 
+	// A free block at the end of the data segment (as the C library leaves
+	// it) starts with its own offset and size, which are not strings.
+	if h.FreeList != 1 && h.FreeList < uint64(usedSize) && h.FreeList+16 <= uint64(len(h.Data)) &&
+		h.FreeList+binary.LittleEndian.Uint64(h.Data[h.FreeList+8:]) == uint64(len(h.Data)) {
+		usedSize = int(h.FreeList) //nolint:gosec // G115: bounded by len(h.Data)
+	}
+
 	// Copy existing data to strings buffer (preserving all content and offsets)
 	// This MUST include all null terminators to maintain correct offsets
 	h.strings = make([]byte, usedSize)
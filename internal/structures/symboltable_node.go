@@ -47,10 +47,12 @@ func ParseSymbolTableNode(r io.ReaderAt, address uint64, sb *core.Superblock) (*
 
 	numSymbols := sb.Endianness.Uint16(header[6:8])
 
-	// Note: Symbol table nodes have a fixed capacity (typically 32 entries for K=16).
-	// When parsing, we don't know the original capacity if numSymbols=0.
-	// Use standard capacity (32) to allow modifications.
-	capacity := uint16(32) // Standard capacity (2*K where K=16)
+	// Note: Symbol table nodes have a fixed capacity of 2K entries, K being
+	// the file's group leaf node K. Keep that capacity to allow modifications.
+	capacity := 2 * sb.SymbolLeafK
+	if capacity == 0 {
+		capacity = 2 * core.DefaultSymbolLeafK
+	}
 	if numSymbols > capacity {
 		capacity = numSymbols // Increase if needed
 	}
@@ -108,11 +110,16 @@ func ParseSymbolTableNode(r io.ReaderAt, address uint64, sb *core.Superblock) (*
 		offset += 4
 
 		// Read scratch-pad (16 bytes).
-		// For CacheType == 1 (H5G_CACHED_STAB), this contains cached B-tree and heap addresses.
+		// For CacheType == 1 (H5G_CACHED_STAB), this contains cached B-tree and heap addresses;
+		// for CacheType == 2 (H5G_CACHED_SLINK), the local heap offset of the soft link value.
 		var cachedBTree, cachedHeap uint64
-		if cacheType == 1 {
+		var softLinkOffset uint32
+		switch cacheType {
+		case CacheTypeSymbolTable:
 			cachedBTree = readAddressFromBytes(data[offset:], int(sb.OffsetSize), sb.Endianness)
 			cachedHeap = readAddressFromBytes(data[offset+int(sb.OffsetSize):], int(sb.OffsetSize), sb.Endianness)
+		case CacheTypeSoftLink:
+			softLinkOffset = sb.Endianness.Uint32(data[offset : offset+4])
 		}
 		offset += 16
 
@@ -123,6 +130,8 @@ func ParseSymbolTableNode(r io.ReaderAt, address uint64, sb *core.Superblock) (*
 			Reserved:        reserved,
 			CachedBTreeAddr: cachedBTree,
 			CachedHeapAddr:  cachedHeap,
+
+			CachedSoftLinkOffset: softLinkOffset,
 		})
 	}
 
@@ -175,6 +184,36 @@ func (stn *SymbolTableNode) AddEntry(entry SymbolTableEntry) error {
 	return nil
 }
 
+// InsertEntry inserts a symbol table entry at index i, shifting later entries.
+// Used to keep entries sorted by link name, as required for lookups.
+// Returns an error if the node would exceed capacity.
+func (stn *SymbolTableNode) InsertEntry(i int, entry SymbolTableEntry) error {
+	capacity := cap(stn.Entries)
+	if int(stn.NumSymbols) >= capacity {
+		return fmt.Errorf("symbol table node is full (%d/%d)", stn.NumSymbols, capacity)
+	}
+	if i < 0 || i > len(stn.Entries) {
+		return fmt.Errorf("entry index %d out of range [0, %d]", i, len(stn.Entries))
+	}
+
+	stn.Entries = append(stn.Entries, SymbolTableEntry{})
+	copy(stn.Entries[i+1:], stn.Entries[i:])
+	stn.Entries[i] = entry
+	stn.NumSymbols++
+	return nil
+}
+
+// RemoveEntry removes the symbol table entry at index i, shifting later entries.
+func (stn *SymbolTableNode) RemoveEntry(i int) error {
+	if i < 0 || i >= len(stn.Entries) {
+		return fmt.Errorf("entry index %d out of range [0, %d)", i, len(stn.Entries))
+	}
+
+	stn.Entries = append(stn.Entries[:i], stn.Entries[i+1:]...)
+	stn.NumSymbols--
+	return nil
+}
+
 // WriteAt writes the symbol table node to w at the specified address.
 // offsetSize determines the size of addresses in the file (typically 8 bytes).
 // maxEntries is the fixed size of the node (for padding with zeros).
@@ -214,7 +253,14 @@ func (stn *SymbolTableNode) WriteAt(w io.WriterAt, address uint64, offsetSize ui
 			endianness.PutUint32(buf[pos:pos+4], entry.Reserved)
 			pos += 4
 
-			// Skip scratch-pad (16 bytes, already zero)
+			// Write scratch-pad (16 bytes), keeping cached addresses
+			switch entry.CacheType {
+			case CacheTypeSymbolTable:
+				writeAddressToBytes(buf[pos:], entry.CachedBTreeAddr, int(offsetSize), endianness)
+				writeAddressToBytes(buf[pos+int(offsetSize):], entry.CachedHeapAddr, int(offsetSize), endianness)
+			case CacheTypeSoftLink:
+				endianness.PutUint32(buf[pos:pos+4], entry.CachedSoftLinkOffset)
+			}
 			pos += 16
 		} else {
 			// Write empty entry (all zeros)
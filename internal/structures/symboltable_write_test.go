@@ -85,6 +85,27 @@ func TestSymbolTableNode_AddEntry(t *testing.T) {
 	})
 }
 
+func TestSymbolTableNode_InsertEntry(t *testing.T) {
+	node := NewSymbolTableNode(4)
+
+	require.NoError(t, node.InsertEntry(0, SymbolTableEntry{LinkNameOffset: 20}))
+	require.NoError(t, node.InsertEntry(0, SymbolTableEntry{LinkNameOffset: 0}))
+	require.NoError(t, node.InsertEntry(2, SymbolTableEntry{LinkNameOffset: 30}))
+	require.NoError(t, node.InsertEntry(1, SymbolTableEntry{LinkNameOffset: 10}))
+
+	assert.Equal(t, uint16(4), node.NumSymbols)
+	for i, entry := range node.Entries {
+		assert.Equal(t, uint64(i*10), entry.LinkNameOffset)
+	}
+
+	err := node.InsertEntry(0, SymbolTableEntry{LinkNameOffset: 40})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "full")
+
+	node = NewSymbolTableNode(4)
+	require.Error(t, node.InsertEntry(1, SymbolTableEntry{}))
+}
+
 func TestSymbolTableNode_WriteAt(t *testing.T) {
 	t.Run("empty node", func(t *testing.T) {
 		node := NewSymbolTableNode(32)
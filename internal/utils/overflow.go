@@ -109,6 +109,12 @@ const (
 	// MaxChunkSize limits chunk size to 1GB (reasonable for in-memory processing).
 	MaxChunkSize = 1024 * 1024 * 1024 // 1GB
 
+	// MaxDatasetSize limits a dataset read into memory as a whole to 16GB.
+	// The size comes from the dataspace, which for unallocated or sparse
+	// chunked storage is not bounded by the file; larger datasets are read
+	// in parts (hyperslabs or chunk by chunk).
+	MaxDatasetSize = 16 * 1024 * 1024 * 1024 // 16GB
+
 	// MaxAttributeSize limits attribute size to 64MB.
 	MaxAttributeSize = 64 * 1024 * 1024 // 64MB
 
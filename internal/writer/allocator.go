@@ -1,8 +1,8 @@
 // Package writer provides HDF5 file writing infrastructure.
 //
 // The Allocator manages free space allocation in HDF5 files.
-// For v0.11.0-beta MVP, it uses a simple end-of-file allocation strategy
-// with no freed space reuse.
+// It allocates at the end of the file, reusing space released with Free
+// when a freed block is large enough (best fit).
 //
 // See ALLOCATOR_DESIGN.md for comprehensive design documentation.
 package writer
@@ -26,10 +26,12 @@ type AllocatedBlock struct {
 
 // Allocator manages space allocation in HDF5 files.
 //
-// Strategy (MVP v0.11.0-beta):
-//   - End-of-file allocation: All allocations occur at end of file
-//   - No freed space reuse: Once allocated, space is never reclaimed
-//   - No fragmentation: Perfect sequential layout
+// Strategy:
+//   - Free-space reuse: Allocations are served from the smallest freed block
+//     that fits (best fit), splitting off the unused rest
+//   - End-of-file allocation: Allocations no freed block can hold occur at
+//     end of file
+//   - Coalescing: Freed blocks merge with adjacent freed blocks
 //   - Overlap prevention: All allocations tracked
 //
 // Thread Safety:
@@ -37,27 +39,28 @@ type AllocatedBlock struct {
 //   - Designed for single-threaded FileWriter
 //
 // Performance:
-//   - Allocate: O(1) - constant time
+//   - Allocate: O(f) - linear scan over freed blocks
+//   - Free: O(n + f) - trims allocated blocks, merges freed blocks
 //   - IsAllocated: O(n) - linear scan over blocks
 //   - Blocks: O(n log n) - copy and sort
 //   - ValidateNoOverlaps: O(n log n) - sort and scan
 //
 // Advanced features (deferred to v0.11.0-RC):
-//   - Free space reuse (best-fit, first-fit strategies)
-//   - Fragmentation management
 //   - Thread safety (optional mutex)
 //   - Alignment enforcement (8-byte)
 //
 // See ALLOCATOR_DESIGN.md for detailed design documentation.
 type Allocator struct {
-	blocks     []AllocatedBlock // All allocated blocks (append-only in MVP)
+	blocks     []AllocatedBlock // All allocated blocks (trimmed by Free)
+	free       []AllocatedBlock // Freed blocks, sorted by offset and coalesced
 	nextOffset uint64           // Next available address (end-of-file)
 }
 
 // NewAllocator creates a space allocator.
 //
 // The allocator tracks all allocations and manages free space in the HDF5 file.
-// It uses end-of-file allocation strategy (no freed space reuse in MVP).
+// It starts without freed blocks, so allocations occur at end of file until
+// space is released with Free.
 //
 // Parameters:
 //   - initialOffset: Starting address for allocations (typically after superblock)
@@ -81,15 +84,18 @@ func NewAllocator(initialOffset uint64) *Allocator {
 	}
 }
 
-// Allocate reserves a block of space at the end of the file.
+// Allocate reserves a block of space in the file.
 //
-// The block is allocated at the current end-of-file address and tracked
-// to prevent overlapping allocations. This is the primary method for
-// obtaining space for HDF5 objects (datasets, groups, attributes, metadata).
+// The block is taken from freed space when possible, otherwise it is
+// allocated at the current end-of-file address. It is tracked to prevent
+// overlapping allocations. This is the primary method for obtaining space
+// for HDF5 objects (datasets, groups, attributes, metadata).
 //
 // Strategy:
-//   - Allocates at current end-of-file (sequential allocation)
-//   - Updates end-of-file pointer to addr + size
+//   - Best fit: Uses the smallest freed block of at least size bytes (the
+//     lowest one among equal sizes) and keeps the rest of it free
+//   - Otherwise allocates at current end-of-file and updates the end-of-file
+//     pointer to addr + size
 //   - Tracks allocation in internal block list
 //   - No alignment enforcement (deferred to RC)
 //   - No size limit validation (OS will reject impossible sizes)
@@ -120,6 +126,39 @@ func (a *Allocator) Allocate(size uint64) (uint64, error) {
 		return 0, fmt.Errorf("cannot allocate zero bytes")
 	}
 
+	best := -1
+	for i, block := range a.free {
+		if block.Size >= size && (best < 0 || block.Size < a.free[best].Size) {
+			best = i
+		}
+	}
+	if best < 0 {
+		return a.AllocateAtEnd(size)
+	}
+
+	// Take the start of the freed block, the rest stays free
+	addr := a.free[best].Offset
+	if a.free[best].Size == size {
+		a.free = append(a.free[:best], a.free[best+1:]...)
+	} else {
+		a.free[best].Offset += size
+		a.free[best].Size -= size
+	}
+
+	a.blocks = append(a.blocks, AllocatedBlock{Offset: addr, Size: size})
+	return addr, nil
+}
+
+// AllocateAtEnd reserves a block of space at the end of the file, ignoring
+// freed space.
+//
+// Use it for blocks that must follow everything allocated so far, such as
+// the free-space manager that records the freed blocks themselves.
+func (a *Allocator) AllocateAtEnd(size uint64) (uint64, error) {
+	if size == 0 {
+		return 0, fmt.Errorf("cannot allocate zero bytes")
+	}
+
 	// Allocate at current end of file
 	addr := a.nextOffset
 
@@ -136,6 +175,143 @@ func (a *Allocator) Allocate(size uint64) (uint64, error) {
 	return addr, nil
 }
 
+// Extend reserves the range [offset, offset+size) so the block ending at
+// offset can grow in place, as object headers do when messages are added.
+//
+// The part of the range below the end of file must be freed space, which
+// is taken out of the freed blocks; the rest is allocated at the end of
+// file. The range cannot start past the end of file.
+//
+// Returns:
+//   - error: Non-nil if the range is empty, starts past the end of file or
+//     overlaps space that is still allocated
+func (a *Allocator) Extend(offset, size uint64) error {
+	if size == 0 {
+		return fmt.Errorf("cannot extend by zero bytes")
+	}
+	if offset > a.nextOffset {
+		return fmt.Errorf("cannot extend at %d: beyond end of file %d", offset, a.nextOffset)
+	}
+
+	end := offset + size
+	inFile := min(end, a.nextOffset)
+	if inFile > offset {
+		// The freed blocks are coalesced, so the range lies in one of them.
+		i := sort.Search(len(a.free), func(i int) bool {
+			return a.free[i].Offset+a.free[i].Size > offset
+		})
+		if i == len(a.free) || a.free[i].Offset > offset || a.free[i].Offset+a.free[i].Size < inFile {
+			return fmt.Errorf("cannot extend [%d, %d): space is allocated", offset, inFile)
+		}
+
+		// Split the freed block around the range
+		block := a.free[i]
+		var rest []AllocatedBlock
+		if block.Offset < offset {
+			rest = append(rest, AllocatedBlock{Offset: block.Offset, Size: offset - block.Offset})
+		}
+		if blockEnd := block.Offset + block.Size; blockEnd > inFile {
+			rest = append(rest, AllocatedBlock{Offset: inFile, Size: blockEnd - inFile})
+		}
+		a.free = append(a.free[:i], append(rest, a.free[i+1:]...)...)
+		a.blocks = append(a.blocks, AllocatedBlock{Offset: offset, Size: inFile - offset})
+	}
+
+	if end > inFile {
+		if _, err := a.AllocateAtEnd(end - inFile); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Free releases the range [offset, offset+size) of the file.
+//
+// Tracked blocks overlapping the range are trimmed, or dropped when the range
+// covers them entirely, so the range no longer counts as allocated. The range
+// may also cover space the allocator never handed out, such as objects already
+// present in a file opened for modification.
+//
+// The range is added to the freed blocks, merged with freed blocks it
+// overlaps or touches, and handed out again by Allocate.
+//
+// Parameters:
+//   - offset: Starting address of the range
+//   - size: Size of the range (must be > 0)
+//
+// Returns:
+//   - error: Non-nil if the range is empty or extends past the end of file
+//
+// Example:
+//
+//	addr, _ := alloc.Allocate(100)
+//	_ = alloc.Free(addr, 100)
+//	alloc.IsAllocated(addr, 100) // false
+func (a *Allocator) Free(offset, size uint64) error {
+	if size == 0 {
+		return fmt.Errorf("cannot free zero bytes")
+	}
+
+	end := offset + size
+	if end < offset || end > a.nextOffset {
+		return fmt.Errorf("cannot free [%d, %d): beyond end of file %d", offset, end, a.nextOffset)
+	}
+
+	blocks := make([]AllocatedBlock, 0, len(a.blocks)+1)
+	for _, block := range a.blocks {
+		blockEnd := block.Offset + block.Size
+		if blockEnd <= offset || block.Offset >= end {
+			blocks = append(blocks, block)
+			continue
+		}
+
+		// Keep the parts of the block outside the freed range.
+		if block.Offset < offset {
+			blocks = append(blocks, AllocatedBlock{Offset: block.Offset, Size: offset - block.Offset})
+		}
+		if blockEnd > end {
+			blocks = append(blocks, AllocatedBlock{Offset: end, Size: blockEnd - end})
+		}
+	}
+	a.blocks = blocks
+
+	// Insert the range into the freed blocks, merging its neighbours
+	merged := AllocatedBlock{Offset: offset, Size: size}
+	free := make([]AllocatedBlock, 0, len(a.free)+1)
+	inserted := false
+	for _, block := range a.free {
+		blockEnd := block.Offset + block.Size
+		switch {
+		case blockEnd < merged.Offset:
+			free = append(free, block)
+		case block.Offset > merged.Offset+merged.Size:
+			if !inserted {
+				free = append(free, merged)
+				inserted = true
+			}
+			free = append(free, block)
+		default:
+			mergedEnd := max(blockEnd, merged.Offset+merged.Size)
+			merged.Offset = min(block.Offset, merged.Offset)
+			merged.Size = mergedEnd - merged.Offset
+		}
+	}
+	if !inserted {
+		free = append(free, merged)
+	}
+	a.free = free
+
+	return nil
+}
+
+// FreeBlocks returns a copy of the freed blocks not handed out again yet,
+// sorted by offset. Adjacent freed blocks are merged into one.
+func (a *Allocator) FreeBlocks() []AllocatedBlock {
+	blocks := make([]AllocatedBlock, len(a.free))
+	copy(blocks, a.free)
+	return blocks
+}
+
 // IsAllocated checks if an address range overlaps with any allocated blocks.
 //
 // This method is useful for validation and debugging to ensure no
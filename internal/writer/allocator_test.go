@@ -72,6 +72,164 @@ func TestAllocate(t *testing.T) {
 	})
 }
 
+func TestFree(t *testing.T) {
+	t.Run("whole block", func(t *testing.T) {
+		alloc := NewAllocator(48)
+		addr1, _ := alloc.Allocate(100)
+		addr2, _ := alloc.Allocate(50)
+
+		require.NoError(t, alloc.Free(addr1, 100))
+		assert.False(t, alloc.IsAllocated(addr1, 100))
+		assert.True(t, alloc.IsAllocated(addr2, 50))
+		assert.Equal(t, []AllocatedBlock{{Offset: 148, Size: 50}}, alloc.Blocks())
+
+		assert.Equal(t, []AllocatedBlock{{Offset: 48, Size: 100}}, alloc.FreeBlocks())
+	})
+
+	t.Run("part of a block", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		_, _ = alloc.Allocate(100)
+
+		require.NoError(t, alloc.Free(40, 20))
+		assert.Equal(t, []AllocatedBlock{{Offset: 0, Size: 40}, {Offset: 60, Size: 40}}, alloc.Blocks())
+		require.NoError(t, alloc.ValidateNoOverlaps())
+	})
+
+	t.Run("untracked space", func(t *testing.T) {
+		// Files opened for modification start with existing data.
+		alloc := NewAllocator(1000)
+		require.NoError(t, alloc.Free(100, 50))
+		assert.Empty(t, alloc.Blocks())
+		assert.Equal(t, []AllocatedBlock{{Offset: 100, Size: 50}}, alloc.FreeBlocks())
+	})
+
+	t.Run("coalescing", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		for i := 0; i < 5; i++ {
+			_, _ = alloc.Allocate(10)
+		}
+
+		require.NoError(t, alloc.Free(30, 10))
+		require.NoError(t, alloc.Free(10, 10))
+		assert.Equal(t, []AllocatedBlock{{Offset: 10, Size: 10}, {Offset: 30, Size: 10}}, alloc.FreeBlocks())
+
+		// Freeing the gap merges both neighbours.
+		require.NoError(t, alloc.Free(20, 10))
+		assert.Equal(t, []AllocatedBlock{{Offset: 10, Size: 30}}, alloc.FreeBlocks())
+
+		// Overlapping ranges merge as well.
+		require.NoError(t, alloc.Free(35, 10))
+		assert.Equal(t, []AllocatedBlock{{Offset: 10, Size: 35}}, alloc.FreeBlocks())
+	})
+
+	t.Run("invalid ranges", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		_, _ = alloc.Allocate(100)
+
+		err := alloc.Free(10, 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot free zero bytes")
+
+		err = alloc.Free(90, 20)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "beyond end of file")
+	})
+}
+
+func TestAllocate_ReusesFreedSpace(t *testing.T) {
+	t.Run("best fit", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		for _, size := range []uint64{100, 10, 40, 10, 60, 10} {
+			_, _ = alloc.Allocate(size)
+		}
+		require.NoError(t, alloc.Free(0, 100))
+		require.NoError(t, alloc.Free(110, 40))
+		require.NoError(t, alloc.Free(160, 60))
+
+		// The smallest block that fits is used, the rest stays free.
+		addr, err := alloc.Allocate(30)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(110), addr)
+		assert.Equal(t, []AllocatedBlock{{Offset: 0, Size: 100}, {Offset: 140, Size: 10}, {Offset: 160, Size: 60}},
+			alloc.FreeBlocks())
+
+		// Exact fits use up the block.
+		addr, err = alloc.Allocate(60)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(160), addr)
+		assert.Equal(t, []AllocatedBlock{{Offset: 0, Size: 100}, {Offset: 140, Size: 10}}, alloc.FreeBlocks())
+
+		require.NoError(t, alloc.ValidateNoOverlaps())
+		assert.Equal(t, uint64(230), alloc.EndOfFile())
+	})
+
+	t.Run("lowest block among equal sizes", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		_, _ = alloc.Allocate(100)
+		require.NoError(t, alloc.Free(60, 20))
+		require.NoError(t, alloc.Free(20, 20))
+
+		addr, err := alloc.Allocate(20)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(20), addr)
+	})
+
+	t.Run("end of file when nothing fits", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		_, _ = alloc.Allocate(100)
+		require.NoError(t, alloc.Free(0, 50))
+
+		addr, err := alloc.Allocate(60)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(100), addr)
+		assert.Equal(t, uint64(160), alloc.EndOfFile())
+		assert.Equal(t, []AllocatedBlock{{Offset: 0, Size: 50}}, alloc.FreeBlocks())
+	})
+
+	t.Run("allocate at end", func(t *testing.T) {
+		alloc := NewAllocator(0)
+		_, _ = alloc.Allocate(100)
+		require.NoError(t, alloc.Free(0, 100))
+
+		addr, err := alloc.AllocateAtEnd(10)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(100), addr)
+		assert.Len(t, alloc.FreeBlocks(), 1)
+
+		_, err = alloc.AllocateAtEnd(0)
+		require.Error(t, err)
+	})
+}
+
+func TestExtend(t *testing.T) {
+	alloc := NewAllocator(0)
+	for _, size := range []uint64{40, 30, 30} {
+		_, _ = alloc.Allocate(size)
+	}
+	require.NoError(t, alloc.Free(40, 30))
+
+	// Freed space after a block is taken, the rest stays free.
+	require.NoError(t, alloc.Extend(40, 10))
+	assert.Equal(t, []AllocatedBlock{{Offset: 50, Size: 20}}, alloc.FreeBlocks())
+	assert.True(t, alloc.IsAllocated(40, 10))
+
+	// Allocated space cannot be taken.
+	require.Error(t, alloc.Extend(60, 20))
+	require.Error(t, alloc.Extend(100, 0))
+	require.Error(t, alloc.Extend(110, 10))
+
+	// Past the end of file, the end moves.
+	require.NoError(t, alloc.Extend(100, 16))
+	assert.Equal(t, uint64(116), alloc.EndOfFile())
+
+	// A range that starts in freed space at the end of file continues past it.
+	require.NoError(t, alloc.Free(100, 16))
+	require.NoError(t, alloc.Extend(108, 16))
+	assert.Equal(t, []AllocatedBlock{{Offset: 50, Size: 20}, {Offset: 100, Size: 8}}, alloc.FreeBlocks())
+	assert.Equal(t, uint64(124), alloc.EndOfFile())
+	require.NoError(t, alloc.ValidateNoOverlaps())
+}
+
 func TestIsAllocated(t *testing.T) {
 	alloc := NewAllocator(0)
 
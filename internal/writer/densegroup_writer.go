@@ -47,6 +47,13 @@ type denseLink struct {
 	// Future: soft link path, external link file+path
 }
 
+// Fractal heap direct block sizes for dense groups. The block is sized to
+// the links it holds, as heap offsets are 2 bytes wide.
+const (
+	denseGroupMinBlockSize = 512
+	denseGroupMaxBlockSize = 64 * 1024
+)
+
 // NewDenseGroupWriter creates new dense group writer.
 //
 // Parameters:
@@ -59,8 +66,8 @@ type denseLink struct {
 func NewDenseGroupWriter(name string) *DenseGroupWriter {
 	return &DenseGroupWriter{
 		name:        name,
-		fractalHeap: structures.NewWritableFractalHeap(512 * 1024), // 512KB default
-		btree:       structures.NewWritableBTreeV2(4096),           // 4KB node
+		fractalHeap: structures.NewWritableFractalHeap(denseGroupMaxBlockSize), // Resized in WriteToFile
+		btree:       structures.NewWritableBTreeV2(4096),                       // 4KB node
 		linkInfo: &core.LinkInfoMessage{
 			Version: 0,
 			Flags:   0, // No creation order tracking for MVP
@@ -131,9 +138,23 @@ func (dgw *DenseGroupWriter) WriteToFile(fw *FileWriter, allocator *Allocator, s
 	}
 
 	// Step 1: Process all links
-	for _, link := range dgw.links {
+	linkMsgs := make([][]byte, len(dgw.links))
+	var totalSize uint64
+	for i, link := range dgw.links {
 		// 1a. Create link message (hard link format)
-		linkMsg := dgw.createLinkMessage(link, sb)
+		linkMsgs[i] = dgw.createLinkMessage(link, sb)
+		totalSize += uint64(len(linkMsgs[i]))
+	}
+
+	// Size the heap block to the links, so small groups stay small
+	blockSize := uint64(denseGroupMinBlockSize)
+	for blockSize < totalSize && blockSize < denseGroupMaxBlockSize {
+		blockSize *= 2
+	}
+	dgw.fractalHeap = structures.NewWritableFractalHeap(blockSize)
+
+	for i, link := range dgw.links {
+		linkMsg := linkMsgs[i]
 
 		// 1b. Insert into fractal heap
 		heapID, err := dgw.fractalHeap.InsertObject(linkMsg)
@@ -141,6 +162,12 @@ func (dgw *DenseGroupWriter) WriteToFile(fw *FileWriter, allocator *Allocator, s
 			return 0, fmt.Errorf("failed to insert link %s into heap: %w", link.name, err)
 		}
 
+		// Dense link heap IDs count from the start of the direct block
+		heapID, err = dgw.fractalHeap.SpecCompliantHeapID(heapID, sb.OffsetSize)
+		if err != nil {
+			return 0, fmt.Errorf("invalid heap ID for link %s: %w", link.name, err)
+		}
+
 		// 1c. Convert heap ID to uint64 for B-tree
 		// Heap ID is 8 bytes, read as little-endian uint64
 		var heapIDUint64 uint64
@@ -185,26 +212,32 @@ func (dgw *DenseGroupWriter) WriteToFile(fw *FileWriter, allocator *Allocator, s
 
 // createLinkMessage creates link message for fractal heap storage.
 //
-// Format (from H5Olinfo.c - link message):
+// Format (from H5Olink.c - link message):
 //   - Version: 1 (1 byte)
-//   - Type: 0 = Hard Link (1 byte)
-//   - Creation Order Present: 0 (1 byte, bit flags) - MVP: no creation order
-//   - Link Name Encoding: 0 = ASCII/UTF-8 (1 byte)
-//   - Link Name Length: variable (compact uint64 encoding)
+//   - Flags: bits 0-1 = size of the name length field, bit 4 = character set present (1 byte)
+//   - Link Name Character Set: 0 = ASCII (1 byte)
+//   - Link Name Length: 1, 2, 4 or 8 bytes (little-endian)
 //   - Link Name: UTF-8 bytes
 //   - Link Info:
 //   - For hard link: target object header address (offsetSize bytes)
 //
-// Reference: H5Ollink.c - H5O__link_encode().
+// No link type field is written, which means a hard link.
+//
+// Reference: H5Olink.c - H5O__link_encode().
 func (dgw *DenseGroupWriter) createLinkMessage(link denseLink, sb *core.Superblock) []byte {
 	nameBytes := []byte(link.name)
 	nameLen := uint64(len(nameBytes))
 
-	// Calculate message size
-	// Version (1) + Type (1) + Flags (1) + Encoding (1) + Name Length (variable) + Name + Address
-	// For MVP: name length encoded as compact uint64 (1-8 bytes based on value)
-	nameLenSize := compactUint64Size(nameLen)
-	messageSize := 4 + nameLenSize + len(nameBytes) + int(sb.OffsetSize)
+	// The name length field is 1, 2, 4 or 8 bytes wide
+	var sizeCode uint8
+	nameLenSize := 1
+	for nameLenSize < compactUint64Size(nameLen) {
+		sizeCode++
+		nameLenSize *= 2
+	}
+
+	// Version (1) + Flags (1) + Character Set (1) + Name Length + Name + Address
+	messageSize := 3 + nameLenSize + len(nameBytes) + int(sb.OffsetSize)
 
 	buf := make([]byte, messageSize)
 	offset := 0
@@ -213,24 +246,15 @@ func (dgw *DenseGroupWriter) createLinkMessage(link denseLink, sb *core.Superblo
 	buf[offset] = 1 // Link message version 1
 	offset++
 
-	// Type (1 byte): 0 = Hard Link
-	buf[offset] = 0
-	offset++
-
 	// Flags (1 byte)
-	// Bit 0: creation order present (0 = no)
-	// Bit 1: link type field present (0 = no, type is in separate field)
-	// Bit 2: link name character set field present (1 = yes)
-	// Bit 3: link name is stored as a creation order (0 = no)
-	// For MVP: only bit 2 set (character set field present)
-	buf[offset] = 0x04 // Character set field present
+	buf[offset] = core.LinkFlagCharSetBit | sizeCode
 	offset++
 
-	// Link Name Character Set Encoding (1 byte)
-	buf[offset] = 0 // ASCII/UTF-8
+	// Link Name Character Set (1 byte)
+	buf[offset] = 0 // ASCII
 	offset++
 
-	// Link Name Length (compact uint64)
+	// Link Name Length (zero-padded to the field size)
 	encodeCompactUint64(buf[offset:], nameLen)
 	offset += nameLenSize
 
@@ -284,7 +308,7 @@ func (dgw *DenseGroupWriter) createObjectHeader(fw *FileWriter, allocator *Alloc
 	headerSize := 7 + messageSize // 7-byte header + messages
 
 	// Allocate space for object header
-	headerAddr, err := allocator.Allocate(headerSize)
+	headerAddr, err := allocator.AllocateAtEnd(headerSize)
 	if err != nil {
 		return 0, fmt.Errorf("failed to allocate object header: %w", err)
 	}
@@ -384,19 +384,19 @@ func TestDenseGroupWriter_LinkMessage(t *testing.T) {
 		t.Errorf("Link message version mismatch: got %d, want 1", msg[0])
 	}
 
-	// Verify type (second byte should be 0 for hard link)
-	if msg[1] != 0 {
-		t.Errorf("Link type mismatch: got %d, want 0 (hard link)", msg[1])
+	// Verify flags (second byte: character set present, 1-byte name length)
+	if msg[1] != core.LinkFlagCharSetBit {
+		t.Errorf("Link flags mismatch: got 0x%02x, want 0x%02x", msg[1], core.LinkFlagCharSetBit)
 	}
 
-	// Verify flags (third byte should have character set bit set)
-	if msg[2]&0x04 == 0 {
-		t.Error("Character set flag not set")
+	// Verify character set (third byte should be 0 for ASCII)
+	if msg[2] != 0 {
+		t.Errorf("Character set mismatch: got %d, want 0 (ASCII)", msg[2])
 	}
 
-	// Verify encoding (fourth byte should be 0 for UTF-8)
-	if msg[3] != 0 {
-		t.Errorf("Character encoding mismatch: got %d, want 0 (UTF-8)", msg[3])
+	// Verify name length (fourth byte)
+	if msg[3] != byte(len("testlink")) {
+		t.Errorf("Link name length mismatch: got %d, want %d", msg[3], len("testlink"))
 	}
 
 	// Verify message contains link name
@@ -3,6 +3,7 @@ package writer
 import (
 	"bytes"
 	"compress/gzip"
+	"compress/zlib"
 	"fmt"
 	"io"
 )
@@ -50,12 +51,13 @@ func (f *GZIPFilter) Name() string {
 // Apply compresses data using GZIP/DEFLATE algorithm.
 // Returns compressed data suitable for storage.
 //
-// The compressed data includes GZIP headers and CRC32 checksum.
+// HDF5 stores deflate data in the zlib format (RFC 1950): a 2-byte header,
+// the DEFLATE stream and an Adler-32 checksum.
 func (f *GZIPFilter) Apply(data []byte) ([]byte, error) {
 	var buf bytes.Buffer
 
-	// Create gzip writer with specified compression level
-	w, err := gzip.NewWriterLevel(&buf, f.level)
+	// Create zlib writer with specified compression level
+	w, err := zlib.NewWriterLevel(&buf, f.level)
 	if err != nil {
 		return nil, fmt.Errorf("gzip writer creation failed: %w", err)
 	}
@@ -78,11 +80,19 @@ func (f *GZIPFilter) Apply(data []byte) ([]byte, error) {
 // Returns the original uncompressed data.
 //
 // This method reverses the Apply operation, restoring the original data.
+// Data in the gzip format, as written by earlier versions of this package,
+// is decompressed as well.
 func (f *GZIPFilter) Remove(data []byte) ([]byte, error) {
 	buf := bytes.NewReader(data)
 
-	// Create gzip reader
-	r, err := gzip.NewReader(buf)
+	// Create zlib reader (gzip streams start with 0x1f 0x8b, never a zlib header)
+	var r io.ReadCloser
+	var err error
+	if len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b {
+		r, err = gzip.NewReader(buf)
+	} else {
+		r, err = zlib.NewReader(buf)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("gzip reader creation failed: %w", err)
 	}
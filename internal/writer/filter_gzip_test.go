@@ -2,6 +2,9 @@ package writer
 
 import (
 	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -130,6 +133,31 @@ func TestGZIPFilter_DecompressData(t *testing.T) {
 	require.Equal(t, original, decompressed)
 }
 
+func TestGZIPFilter_Format(t *testing.T) {
+	filter := NewGZIPFilter(6)
+	original := []byte("HDF5 deflate data is a zlib stream")
+
+	compressed, err := filter.Apply(original)
+	require.NoError(t, err)
+
+	// The HDF5 library decompresses with inflate(), which expects zlib.
+	r, err := zlib.NewReader(bytes.NewReader(compressed))
+	require.NoError(t, err)
+	decompressed, err := io.ReadAll(r)
+	require.NoError(t, err)
+	require.Equal(t, original, decompressed)
+
+	// Chunks written by earlier versions hold gzip streams.
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	_, err = w.Write(original)
+	require.NoError(t, err)
+	require.NoError(t, w.Close())
+	decompressed, err = filter.Remove(buf.Bytes())
+	require.NoError(t, err)
+	require.Equal(t, original, decompressed)
+}
+
 func TestGZIPFilter_RoundTrip_SmallData(t *testing.T) {
 	filter := NewGZIPFilter(6)
 	original := []byte("Hello, HDF5 GZIP filter!")
@@ -134,22 +134,22 @@ func (fp *FilterPipeline) EncodePipelineMessage() ([]byte, error) {
 		return nil, errors.New("empty filter pipeline")
 	}
 
-	// Pipeline message format (version 2):
-	// Bytes 0:    Version (1 byte) = 2
+	// Pipeline message format (version 1):
+	// Bytes 0:    Version (1 byte) = 1
 	// Bytes 1:    Number of filters (1 byte)
 	// Bytes 2-7:  Reserved (6 bytes, must be 0)
 	//
 	// For each filter:
 	//   Filter ID (2 bytes)
-	//   Name length (2 bytes) - may be 0
+	//   Name length (2 bytes) - may be 0, else includes the null terminator
 	//   Flags (2 bytes)
 	//   Number of CD values (2 bytes)
 	//   Name (variable, padded to 8-byte boundary) - only if name length > 0
-	//   CD values (4 bytes each)
+	//   CD values (4 bytes each, padded to 8-byte boundary)
 
 	buf := make([]byte, 0, 8+len(fp.filters)*32) // Pre-allocate for header + filters
 	header := make([]byte, 8)
-	header[0] = 2 // Version 2
+	header[0] = 1 // Version 1
 	header[1] = byte(len(fp.filters))
 	// Reserved bytes 2-7 are already zero
 	buf = append(buf, header...)
@@ -166,7 +166,10 @@ func (fp *FilterPipeline) EncodePipelineMessage() ([]byte, error) {
 func encodeFilter(f Filter) []byte {
 	flags, cdValues := f.Encode()
 	name := f.Name()
-	nameLen := uint16(len(name)) //nolint:gosec // G115: Filter names are short (<256), always fit in uint16
+	var nameLen uint16
+	if name != "" {
+		nameLen = uint16(len(name) + 1) //nolint:gosec // G115: Filter names are short (<256), always fit in uint16
+	}
 
 	// Calculate padded name length (align to 8-byte boundary)
 	var paddedNameLen uint16
@@ -174,8 +177,8 @@ func encodeFilter(f Filter) []byte {
 		paddedNameLen = ((nameLen + 7) / 8) * 8
 	}
 
-	// Calculate buffer size
-	bufSize := 8 + int(paddedNameLen) + len(cdValues)*4
+	// Calculate buffer size (an odd number of CD values is followed by 4 bytes of padding)
+	bufSize := 8 + int(paddedNameLen) + (len(cdValues)+len(cdValues)%2)*4
 	buf := make([]byte, bufSize)
 
 	// Filter header (8 bytes)
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/meko-christian/go-hdf5/internal/core"
 	"github.com/stretchr/testify/require"
 )
 
@@ -242,7 +243,7 @@ func TestFilterPipeline_EncodePipelineMessage_SingleFilter(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check header
-	require.Equal(t, byte(2), msg[0])           // Version 2
+	require.Equal(t, byte(1), msg[0])           // Version 1
 	require.Equal(t, byte(1), msg[1])           // 1 filter
 	require.Equal(t, make([]byte, 6), msg[2:8]) // Reserved
 
@@ -252,7 +253,7 @@ func TestFilterPipeline_EncodePipelineMessage_SingleFilter(t *testing.T) {
 	require.Equal(t, uint16(FilterGZIP), filterID)
 
 	nameLen := binary.LittleEndian.Uint16(msg[offset+2:])
-	require.Equal(t, uint16(7), nameLen) // "deflate"
+	require.Equal(t, uint16(8), nameLen) // "deflate" + null terminator
 
 	flags := binary.LittleEndian.Uint16(msg[offset+4:])
 	require.Equal(t, uint16(0), flags)
@@ -290,12 +291,12 @@ func TestFilterPipeline_EncodePipelineMessage_MultipleFilters(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check header
-	require.Equal(t, byte(2), msg[0]) // Version 2
+	require.Equal(t, byte(1), msg[0]) // Version 1
 	require.Equal(t, byte(2), msg[1]) // 2 filters
 
 	// Verify message is valid length
-	// Header (8) + Filter1 (8 + 8 (padded name) + 4 (1 CD)) + Filter2 (8 + 8 (padded name) + 4 (1 CD)) = 48
-	require.Equal(t, 48, len(msg))
+	// Header (8) + Filter1 (8 + 8 (padded name) + 8 (1 CD, padded)) + Filter2 (8 + 8 (padded name) + 8 (1 CD, padded)) = 56
+	require.Equal(t, 56, len(msg))
 
 	// Verify both filters are present in message
 	offset := 8
@@ -304,14 +305,14 @@ func TestFilterPipeline_EncodePipelineMessage_MultipleFilters(t *testing.T) {
 	filterID1 := binary.LittleEndian.Uint16(msg[offset:])
 	require.Equal(t, uint16(FilterShuffle), filterID1)
 	nameLen1 := binary.LittleEndian.Uint16(msg[offset+2:])
-	require.Equal(t, uint16(7), nameLen1) // "shuffle"
+	require.Equal(t, uint16(8), nameLen1) // "shuffle" + null terminator
 
-	// Second filter (offset = 8 + 8 + 8 + 4 = 28)
-	offset2 := 28
+	// Second filter (offset = 8 + 8 + 8 + 8 = 32)
+	offset2 := 32
 	filterID2 := binary.LittleEndian.Uint16(msg[offset2:])
 	require.Equal(t, uint16(FilterGZIP), filterID2)
 	nameLen2 := binary.LittleEndian.Uint16(msg[offset2+2:])
-	require.Equal(t, uint16(7), nameLen2) // "deflate"
+	require.Equal(t, uint16(8), nameLen2) // "deflate" + null terminator
 }
 
 func TestFilterPipeline_EncodePipelineMessage_NoName(t *testing.T) {
@@ -328,7 +329,7 @@ func TestFilterPipeline_EncodePipelineMessage_NoName(t *testing.T) {
 	require.NoError(t, err)
 
 	// Check header
-	require.Equal(t, byte(2), msg[0]) // Version 2
+	require.Equal(t, byte(1), msg[0]) // Version 1
 	require.Equal(t, byte(1), msg[1]) // 1 filter
 
 	// Check filter encoding
@@ -350,7 +351,7 @@ func TestFilterPipeline_EncodePipelineMessage_LongName(t *testing.T) {
 	pipeline := NewFilterPipeline()
 	filter := &mockFilter{
 		id:       FilterGZIP,
-		name:     "very-long-filter-name", // 21 bytes -> padded to 24
+		name:     "very-long-filter-name", // 21 bytes + null -> padded to 24
 		flags:    42,
 		cdValues: []uint32{1, 2, 3},
 	}
@@ -361,7 +362,7 @@ func TestFilterPipeline_EncodePipelineMessage_LongName(t *testing.T) {
 
 	offset := 8
 	nameLen := binary.LittleEndian.Uint16(msg[offset+2:])
-	require.Equal(t, uint16(21), nameLen)
+	require.Equal(t, uint16(22), nameLen)
 
 	// Name should be padded to 24 bytes (next multiple of 8)
 	name := string(msg[offset+8 : offset+8+21])
@@ -376,3 +377,35 @@ func TestFilterPipeline_EncodePipelineMessage_LongName(t *testing.T) {
 	require.Equal(t, uint32(2), cd2)
 	require.Equal(t, uint32(3), cd3)
 }
+
+func TestFilterPipeline_EncodePipelineMessage_ParsedByReader(t *testing.T) {
+	pipeline := NewFilterPipeline()
+	pipeline.AddFilter(NewShuffleFilter(4))
+	pipeline.AddFilter(NewGZIPFilter(6))
+	pipeline.AddFilter(NewFletcher32Filter())
+
+	msg, err := pipeline.EncodePipelineMessage()
+	require.NoError(t, err)
+
+	parsed, err := core.ParseFilterPipelineMessage(msg)
+	require.NoError(t, err)
+	require.Len(t, parsed.Filters, 3)
+	require.Equal(t, core.FilterShuffle, parsed.Filters[0].ID)
+	require.Equal(t, "shuffle", parsed.Filters[0].Name)
+	require.Equal(t, []uint32{4}, parsed.Filters[0].ClientData)
+	require.Equal(t, core.FilterDeflate, parsed.Filters[1].ID)
+	require.Equal(t, "deflate", parsed.Filters[1].Name)
+	require.Equal(t, []uint32{6}, parsed.Filters[1].ClientData)
+	require.Equal(t, core.FilterFletcher, parsed.Filters[2].ID)
+
+	// Data written through the pipeline is decoded by the reader's filters.
+	original := make([]byte, 400)
+	for i := range original {
+		original[i] = byte(i % 7)
+	}
+	encoded, err := pipeline.Apply(original)
+	require.NoError(t, err)
+	decoded, err := parsed.ApplyFilters(encoded)
+	require.NoError(t, err)
+	require.Equal(t, original, decoded)
+}
@@ -0,0 +1,49 @@
+package writer
+
+import (
+	"fmt"
+	"io"
+	"os"
+)
+
+// Storage holds the bytes of a file written by a FileWriter.
+//
+// Addresses in the file map directly to offsets in the storage. Writes past
+// the end of the storage extend it; the gap, if any, reads as zeros.
+//
+// Implementations:
+//   - Files on disk (NewFileWriter, OpenFileWriter)
+//   - Any other random-access storage via NewStorageWriter
+type Storage interface {
+	io.ReaderAt
+	io.WriterAt
+
+	// Size returns the current size of the storage in bytes.
+	Size() (int64, error)
+
+	// Truncate changes the size of the storage, zero-filling when it grows.
+	Truncate(size int64) error
+
+	// Sync commits written data to durable storage, if there is any.
+	Sync() error
+
+	// Close releases the storage. The writer does not use it afterwards.
+	Close() error
+}
+
+// fileStorage is the Storage of a file on disk.
+type fileStorage struct {
+	*os.File
+}
+
+// Size implements Storage.
+func (s fileStorage) Size() (int64, error) {
+	stat, err := s.Stat()
+	if err != nil {
+		return 0, fmt.Errorf("failed to stat file: %w", err)
+	}
+	return stat.Size(), nil
+}
+
+// Ensure fileStorage implements Storage.
+var _ Storage = fileStorage{}
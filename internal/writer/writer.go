@@ -6,7 +6,7 @@ import (
 	"os"
 )
 
-// FileWriter wraps an os.File for writing HDF5 files.
+// FileWriter wraps a Storage, usually a file on disk, for writing HDF5 files.
 // It provides:
 // - Space allocation tracking (via Allocator)
 // - Write-at-address operations
@@ -15,8 +15,13 @@ import (
 //
 // Thread-safety: Not thread-safe. Caller must synchronize access.
 type FileWriter struct {
-	file      *os.File   // Underlying OS file
+	file      Storage    // Underlying storage
 	allocator *Allocator // Space allocation tracker
+
+	// Raw data space (see SeparateRawData); nil when raw data shares the
+	// address space of metadata
+	raw     *Allocator
+	rawBase uint64
 }
 
 // CreateMode specifies the file creation/opening behavior.
@@ -77,7 +82,7 @@ func NewFileWriter(filename string, mode CreateMode, initialOffset uint64) (*Fil
 	}
 
 	return &FileWriter{
-		file:      osFile,
+		file:      fileStorage{osFile},
 		allocator: NewAllocator(initialOffset),
 	}, nil
 }
@@ -132,24 +137,40 @@ func OpenFileWriter(filename string, mode CreateMode, initialOffset uint64) (*Fi
 		return nil, fmt.Errorf("failed to open file: %w", err)
 	}
 
-	// Get actual file size to validate initialOffset
-	stat, err := osFile.Stat()
+	fw, err := NewStorageWriter(fileStorage{osFile}, initialOffset)
 	if err != nil {
 		_ = osFile.Close()
-		return nil, fmt.Errorf("failed to stat file: %w", err)
+		return nil, err
 	}
+	return fw, nil
+}
 
-	fileSize := uint64(stat.Size()) //nolint:gosec // Safe: file size conversion
+// NewStorageWriter creates a writer for the file held by storage, which
+// the writer takes over: closing the writer closes storage.
+//
+// New allocations occur after the existing contents of storage, or after
+// initialOffset if that is larger (so empty storage starts at initialOffset,
+// like a file created with NewFileWriter).
+//
+// Example:
+//
+//	// Write a file to custom storage, for example a buffer in memory
+//	fw, err := NewStorageWriter(storage, 48)
+func NewStorageWriter(storage Storage, initialOffset uint64) (*FileWriter, error) {
+	size, err := storage.Size()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get storage size: %w", err)
+	}
 
 	// Initialize allocator at file size (new allocations happen after existing data)
-	allocatorOffset := fileSize
-	if initialOffset > fileSize {
+	allocatorOffset := uint64(size) //nolint:gosec // Safe: storage size conversion
+	if initialOffset > allocatorOffset {
 		// Caller provided larger offset than file size - trust it (for sparse files)
 		allocatorOffset = initialOffset
 	}
 
 	return &FileWriter{
-		file:      osFile,
+		file:      storage,
 		allocator: NewAllocator(allocatorOffset),
 	}, nil
 }
@@ -158,8 +179,9 @@ func OpenFileWriter(filename string, mode CreateMode, initialOffset uint64) (*Fi
 // Returns the address where the block was allocated.
 // The space is not zeroed - caller must write data to the allocated block.
 //
-// For MVP:
-// - Allocation always occurs at end of file
+// Allocation:
+// - Reuses space released with Free when a freed block fits (see Allocator.Allocate)
+// - Otherwise occurs at end of file
 // - No alignment requirements
 //
 // Example:
@@ -178,6 +200,63 @@ func (w *FileWriter) Allocate(size uint64) (uint64, error) {
 	return w.allocator.Allocate(size)
 }
 
+// AllocateObjectHeader reserves a block of space for an object header at
+// the end of the file, never in freed space. Object headers are rewritten
+// in place when messages are added to them and grow into the space that
+// follows, which is only possible while nothing has been allocated there.
+func (w *FileWriter) AllocateObjectHeader(size uint64) (uint64, error) {
+	if w.file == nil {
+		return 0, fmt.Errorf("writer is closed")
+	}
+
+	return w.allocator.AllocateAtEnd(size)
+}
+
+// AllocateRaw reserves a block of space for raw data (dataset elements).
+// It allocates from the raw data space if the file has one (see
+// SeparateRawData), and like Allocate otherwise.
+func (w *FileWriter) AllocateRaw(size uint64) (uint64, error) {
+	if w.raw == nil {
+		return w.Allocate(size)
+	}
+	if w.file == nil {
+		return 0, fmt.Errorf("writer is closed")
+	}
+
+	return w.raw.Allocate(size)
+}
+
+// Free releases a region of the file that is no longer used, so later
+// allocations can reuse it. See Allocator.Free.
+func (w *FileWriter) Free(addr, size uint64) error {
+	if w.file == nil {
+		return fmt.Errorf("writer is closed")
+	}
+
+	if w.raw != nil && addr >= w.rawBase {
+		return w.raw.Free(addr, size)
+	}
+	return w.allocator.Free(addr, size)
+}
+
+// SeparateRawData places raw data allocated with AllocateRaw in its own
+// address space starting at base, like the split file driver of the HDF5
+// library does. Allocations start at end, the end of the raw data already
+// in the file (base when there is none).
+func (w *FileWriter) SeparateRawData(base, end uint64) {
+	w.rawBase = base
+	w.raw = NewAllocator(max(base, end))
+}
+
+// RawEndOfFile returns the end of the raw data space (see SeparateRawData),
+// or EndOfFile if the file has none.
+func (w *FileWriter) RawEndOfFile() uint64 {
+	if w.raw == nil {
+		return w.EndOfFile()
+	}
+	return w.raw.EndOfFile()
+}
+
 // WriteAt writes data at a specific address in the file.
 // Implements io.WriterAt interface.
 //
@@ -199,7 +278,6 @@ func (w *FileWriter) WriteAt(data []byte, offset int64) (int, error) {
 		return 0, nil // Nothing to write
 	}
 
-	// Use os.File.WriteAt which handles seeking internally
 	n, err := w.file.WriteAt(data, offset)
 	if err != nil {
 		return n, fmt.Errorf("write at address %d failed: %w", offset, err)
@@ -235,7 +313,7 @@ func (w *FileWriter) EndOfFile() uint64 {
 	return w.allocator.EndOfFile()
 }
 
-// Flush ensures all writes are committed to disk.
+// Flush ensures all writes are committed to disk (see Storage.Sync).
 // This should be called before closing or when data durability is required.
 func (w *FileWriter) Flush() error {
 	if w.file == nil {
@@ -245,7 +323,7 @@ func (w *FileWriter) Flush() error {
 	return w.file.Sync()
 }
 
-// Close closes the underlying file.
+// Close closes the underlying storage.
 // This does NOT automatically flush - call Flush() first if needed.
 // After Close(), the writer cannot be used.
 func (w *FileWriter) Close() error {
@@ -258,10 +336,10 @@ func (w *FileWriter) Close() error {
 	return err
 }
 
-// File returns the underlying *os.File.
-// Use with caution - direct file operations may break allocation tracking.
+// Storage returns the underlying storage.
+// Use with caution - direct writes may break allocation tracking.
 // Primarily for reading operations or advanced use cases.
-func (w *FileWriter) File() *os.File {
+func (w *FileWriter) Storage() Storage {
 	return w.file
 }
 
@@ -314,16 +392,6 @@ func (w *FileWriter) WriteAtWithAllocation(data []byte) (uint64, error) {
 	return addr, nil
 }
 
-// Seek implements io.Seeker interface for compatibility.
-// Note: HDF5 uses absolute addressing, so seeking is rarely needed.
-func (w *FileWriter) Seek(offset int64, whence int) (int64, error) {
-	if w.file == nil {
-		return 0, fmt.Errorf("writer is closed")
-	}
-
-	return w.file.Seek(offset, whence)
-}
-
 // Ensure FileWriter implements io.ReaderAt and io.WriterAt.
 var (
 	_ io.ReaderAt = (*FileWriter)(nil)
@@ -78,7 +78,7 @@ func TestNewFileWriter(t *testing.T) {
 			defer func() { _ = writer.Close() }()
 
 			// Verify initial state
-			assert.NotNil(t, writer.File())
+			assert.NotNil(t, writer.Storage())
 			assert.Equal(t, tt.initialOffset, writer.EndOfFile())
 
 			// Verify file exists
@@ -47,7 +47,7 @@ import (
 //   - Target must exist before creating link
 //   - Parent group must exist before creating link
 //   - Reference count stored in object header (v1) or RefCount message (v2)
-//   - No link deletion support yet (DeleteLink not implemented)
+//   - Links are removed with Unlink, which decrements the count again
 //   - No circular link detection
 //
 // Reference: H5L.c - H5Lcreate_hard().
@@ -161,14 +161,18 @@ func writeV1RefCount(fw *FileWriter, addr uint64, oh *core.ObjectHeader) error {
 // writeV2RefCount writes reference count for v2 object header.
 func writeV2RefCount(fw *FileWriter, addr uint64, oh *core.ObjectHeader) error {
 	// V2: Reference count stored in RefCount message (type 0x0016)
-	// If refcount > 1, we need to add/update RefCount message
-	if oh.ReferenceCount > 1 {
+	// If refcount > 1, we need to add/update RefCount message. Once added,
+	// the message is kept up to date as links are removed.
+	if oh.ReferenceCount > 1 || hasRefCountMessage(oh) {
 		if err := ensureRefCountMessage(fw, oh); err != nil {
 			return err
 		}
 	}
 
 	// Write entire object header back to disk
+	if err := reserveObjectHeader(fw, addr, oh); err != nil {
+		return err
+	}
 	err := core.WriteObjectHeader(fw.writer, addr, oh, fw.file.sb)
 	if err != nil {
 		return fmt.Errorf("failed to write v2 object header: %w", err)
@@ -176,6 +180,16 @@ func writeV2RefCount(fw *FileWriter, addr uint64, oh *core.ObjectHeader) error {
 	return nil
 }
 
+// hasRefCountMessage reports whether the object header has a RefCount message.
+func hasRefCountMessage(oh *core.ObjectHeader) bool {
+	for _, msg := range oh.Messages {
+		if msg.Type == core.MsgRefCount && len(msg.Data) >= 4 {
+			return true
+		}
+	}
+	return false
+}
+
 // ensureRefCountMessage ensures RefCount message exists and is updated.
 func ensureRefCountMessage(fw *FileWriter, oh *core.ObjectHeader) error {
 	// Check if RefCount message already exists
@@ -240,20 +254,25 @@ func ensureRefCountMessage(fw *FileWriter, oh *core.ObjectHeader) error {
 //
 // Limitations:
 //   - Symbol table format only (dense groups not yet supported)
-//   - No soft link resolution yet (reading soft links not implemented)
+//   - Readers follow the link only when opened WithFollowLinks
 //   - No circular link detection
 //
 // HDF5 Spec: Section IV.A.2.f "Link Message" - Type 1 (Soft Link)
 // Reference: H5L.c - H5Lcreate_soft().
 func (fw *FileWriter) CreateSoftLink(linkPath, targetPath string) error {
-	// Validate paths
-	if err := validateLinkPath(linkPath); err != nil {
-		return fmt.Errorf("invalid link path: %w", err)
-	}
-
 	if err := validateSoftLinkTargetPath(targetPath); err != nil {
 		return fmt.Errorf("invalid target path: %w", err)
 	}
+	return fw.createSoftLink(linkPath, targetPath)
+}
+
+// createSoftLink creates a soft link storing targetPath as is. Unlike
+// CreateSoftLink it accepts relative targets, resolved from the link's
+// group, as found in existing files.
+func (fw *FileWriter) createSoftLink(linkPath, targetPath string) error {
+	if err := validateLinkPath(linkPath); err != nil {
+		return fmt.Errorf("invalid link path: %w", err)
+	}
 
 	// Parse link path to find parent and link name
 	parent, linkName := parsePath(linkPath)
@@ -311,7 +330,7 @@ func (fw *FileWriter) CreateSoftLink(linkPath, targetPath string) error {
 	}
 
 	// Allocate space for object header
-	linkAddr, err := fw.writer.Allocate(headerSize)
+	linkAddr, err := fw.writer.AllocateObjectHeader(headerSize)
 	if err != nil {
 		return fmt.Errorf("failed to allocate space for soft link object header: %w", err)
 	}
@@ -345,31 +364,6 @@ func validateSoftLinkTargetPath(path string) error {
 	return nil
 }
 
-// resolveSoftLink follows a soft link and returns the target object address.
-//
-// **STATUS: NOT YET IMPLEMENTED (MVP v0.11.5-beta)** - Planned for v0.12.0
-//
-// Planned functionality:
-//   - Chain resolution (A→B→C): Follows multiple soft links
-//   - Cycle detection: Detects circular references (A→B→A)
-//   - Depth limiting: Maximum 32 resolution levels
-//   - Dangling links: Returns error if target not found
-//
-// Parameters:
-//   - linkAddr: Address of the soft link message
-//   - visitedPaths: Set of visited paths for cycle detection (pass nil to start)
-//
-// Returns:
-//   - uint64: Address of target object
-//   - error: if target not found, cycle detected, or max depth exceeded
-//
-// Internal use only - will be called when accessing soft link in v0.12.0.
-func (fw *FileWriter) resolveSoftLink(linkAddr uint64, visitedPaths map[string]bool) (uint64, error) { //nolint:unused // Future use in v0.12.0
-	_ = linkAddr
-	_ = visitedPaths
-	return 0, fmt.Errorf("soft link resolution not yet implemented (planned for v0.12.0)")
-}
-
 // CreateExternalLink creates a link to an object in another HDF5 file.
 // The link stores the external file path and object path within that file.
 // Both files must exist when the external link is accessed (lazy resolution).
@@ -400,17 +394,11 @@ func (fw *FileWriter) resolveSoftLink(linkAddr uint64, visitedPaths map[string]b
 //
 // Limitations:
 //   - Symbol table format only (dense groups not yet supported)
-//   - No external link resolution yet (reading external links not implemented)
-//   - No file caching or performance optimization
+//   - Readers follow the link only when opened WithFollowLinks
 //
 // HDF5 Spec: Section IV.A.2.f "Link Message" - Type 64 (External Link)
 // Reference: H5Lcreate_external() in H5L.c.
 func (fw *FileWriter) CreateExternalLink(linkPath, fileName, objectPath string) error {
-	// Validate link path
-	if err := validateLinkPath(linkPath); err != nil {
-		return fmt.Errorf("invalid link path: %w", err)
-	}
-
 	// Validate file name
 	if err := validateExternalFileName(fileName); err != nil {
 		return fmt.Errorf("invalid file name: %w", err)
@@ -420,6 +408,15 @@ func (fw *FileWriter) CreateExternalLink(linkPath, fileName, objectPath string)
 	if err := validateSoftLinkTargetPath(objectPath); err != nil {
 		return fmt.Errorf("invalid object path: %w", err)
 	}
+	return fw.createExternalLink(linkPath, fileName, objectPath)
+}
+
+// createExternalLink creates an external link storing fileName and
+// objectPath as is, like createSoftLink for soft links.
+func (fw *FileWriter) createExternalLink(linkPath, fileName, objectPath string) error {
+	if err := validateLinkPath(linkPath); err != nil {
+		return fmt.Errorf("invalid link path: %w", err)
+	}
 
 	// Parse link path to find parent and link name
 	parent, linkName := parsePath(linkPath)
@@ -499,7 +496,7 @@ func (fw *FileWriter) CreateExternalLink(linkPath, fileName, objectPath string)
 	}
 
 	// Allocate space for object header
-	linkAddr, err := fw.writer.Allocate(headerSize)
+	linkAddr, err := fw.writer.AllocateObjectHeader(headerSize)
 	if err != nil {
 		return fmt.Errorf("failed to allocate space for external link object header: %w", err)
 	}
@@ -537,33 +534,6 @@ func validateExternalFileName(fileName string) error {
 	return nil
 }
 
-// resolveExternalLink resolves an external link and returns the target object.
-//
-// **STATUS: NOT YET IMPLEMENTED (MVP v0.11.5-beta)** - Planned for v0.12.0
-//
-// Planned functionality:
-//   - Open external HDF5 file (cache for performance)
-//   - Resolve objectPath within external file
-//   - Handle file not found, object not found errors
-//   - Support absolute and relative file paths
-//   - Prevent circular external references
-//
-// Parameters:
-//   - linkAddr: Address of the external link message
-//   - visitedFiles: Set of visited files for cycle detection (pass nil to start)
-//
-// Returns:
-//   - *File: Opened external file (caller must cache/close)
-//   - uint64: Address of target object in external file
-//   - error: if file/object not found or cycle detected
-//
-// Internal use only - will be called when accessing external link in v0.12.0.
-func (fw *FileWriter) resolveExternalLink(linkAddr uint64, visitedFiles map[string]bool) (*File, uint64, error) { //nolint:unused // Future use in v0.12.0
-	_ = linkAddr
-	_ = visitedFiles
-	return nil, 0, fmt.Errorf("external link resolution not yet implemented in MVP v0.11.5-beta (planned for v0.12.0)")
-}
-
 // Note: The following methods are already implemented in group_write.go and are reused here:
 // - parsePath(path string) (parent, name string)
 // - linkToParent(parentPath, childName string, childAddr uint64) error
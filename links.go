@@ -0,0 +1,486 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/structures"
+)
+
+// maxLinkHops limits the number of soft and external links followed while
+// resolving one path, like H5L_NUM_LINKS in the HDF5 library. It also ends
+// resolution of links that point back to themselves.
+const maxLinkHops = 16
+
+// LinkType identifies how a link locates its target. The values are those
+// of the HDF5 format: types from 65 on are user-defined link classes.
+type LinkType int
+
+// Link types.
+const (
+	LinkHard     LinkType = 0  // Points to an object header in the same file.
+	LinkSoft     LinkType = 1  // Names a path in the same file.
+	LinkExternal LinkType = 64 // Names a path in another file.
+)
+
+// String returns the link type name.
+func (t LinkType) String() string {
+	switch t {
+	case LinkHard:
+		return "hard"
+	case LinkSoft:
+		return "soft"
+	case LinkExternal:
+		return "external"
+	default:
+		return fmt.Sprintf("user-defined(%d)", int(t))
+	}
+}
+
+// IsUserDefined reports whether t is a user-defined link class.
+func (t LinkType) IsUserDefined() bool {
+	return t > LinkExternal
+}
+
+// CharSet is the character set of a link name.
+type CharSet uint8
+
+// Character sets.
+const (
+	CharSetASCII CharSet = 0
+	CharSetUTF8  CharSet = 1
+)
+
+// LinkInfo describes one link of a group, like H5L_info2_t in the HDF5
+// library.
+type LinkInfo struct {
+	Name string
+	Type LinkType
+
+	// Address is the object header address of a hard link's target.
+	Address uint64
+
+	// Target is the path a soft link names, or the object path of an
+	// external link within File. Relative soft link targets are resolved
+	// from the group holding the link, external ones from the file's root.
+	Target string
+
+	// File is the name of the file an external link points into.
+	File string
+
+	// CreationOrder is the link's position in the order links were added to
+	// the group. It is only valid if HasCreationOrder is set, which requires
+	// the group to track creation order.
+	CreationOrder    int64
+	HasCreationOrder bool
+
+	// CharSet is the character set of Name.
+	CharSet CharSet
+
+	// Data is the raw link value of user-defined links.
+	Data []byte
+}
+
+// WithFollowLinks makes path lookups (Get, OpenDataset, OpenGroup and
+// Exists) and Walk follow soft and external links. Without it, looking up a
+// path through such a link fails and Walk skips them; Group.Links reports
+// them either way.
+//
+// Dangling links resolve to errors wrapping ErrNotFound. At most 16 links
+// are followed per lookup, so links forming a cycle fail instead of looping;
+// Walk does not descend into a group already on the path being walked.
+//
+// Example:
+//
+//	f, err := hdf5.Open("data.h5", hdf5.WithFollowLinks(true))
+//	ds, err := f.OpenDataset("/latest/temperature") // "/latest" is a soft link
+func WithFollowLinks(follow bool) OpenOption {
+	return func(cfg *openConfig) {
+		cfg.followLinks = follow
+	}
+}
+
+// WithExternalLinkPrefix sets a directory to search for the files external
+// links point into, like H5Pset_elink_prefix in the HDF5 library. A leading
+// "${ORIGIN}" stands for the directory of the file holding the link.
+//
+// Files named by relative paths are searched in the directories of the
+// HDF5_EXT_PREFIX environment variable (a list separated like PATH), then in
+// the prefix, then next to the file holding the link and finally relative to
+// the working directory. Absolute paths are tried as given first; if that
+// fails, their base name is searched the same way. The files are opened with
+// the options of the file holding the link and closed with it.
+func WithExternalLinkPrefix(prefix string) OpenOption {
+	return func(cfg *openConfig) {
+		cfg.externalPrefix = prefix
+	}
+}
+
+// Links returns the links of the group in storage order: hard links to its
+// children, soft and external links with their targets, and user-defined
+// links with their raw values. Hard links are listed even when their target
+// could not be loaded as a child.
+//
+// Example:
+//
+//	links, err := group.Links()
+//	for _, l := range links {
+//	    if l.Type == hdf5.LinkSoft {
+//	        fmt.Printf("%s -> %s\n", l.Name, l.Target)
+//	    }
+//	}
+func (g *Group) Links() ([]LinkInfo, error) {
+	return append([]LinkInfo(nil), g.links...), nil
+}
+
+// ChildrenByCreationOrder returns the children of the group in the order
+// their links were created, like iterating with H5_INDEX_CRT_ORDER in the
+// HDF5 library. Dense groups are listed using their creation order index;
+// compact groups by the creation order stored in each link.
+//
+// Only groups created with link creation order tracking record the order;
+// for other groups an error is returned.
+func (g *Group) ChildrenByCreationOrder() ([]Object, error) {
+	if g.linkInfo == nil || !g.linkInfo.HasCreationOrderTracking() {
+		return nil, fmt.Errorf("group %q does not track link creation order", g.name)
+	}
+
+	names, err := g.namesByCreationOrder()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]Object, len(g.children))
+	for _, child := range g.children {
+		byName[child.Name()] = child
+	}
+	children := make([]Object, 0, len(g.children))
+	for _, name := range names {
+		if child, ok := byName[name]; ok {
+			children = append(children, child)
+		}
+	}
+	return children, nil
+}
+
+// namesByCreationOrder returns the link names of a group that tracks
+// creation order, in creation order.
+func (g *Group) namesByCreationOrder() ([]string, error) {
+	li := g.linkInfo
+	if !li.HasCreationOrderIndex() || !li.HasCreationOrderBTree() || !li.HasFractalHeap() {
+		links := make([]LinkInfo, len(g.links))
+		copy(links, g.links)
+		sort.SliceStable(links, func(i, j int) bool {
+			return links[i].CreationOrder < links[j].CreationOrder
+		})
+		names := make([]string, len(links))
+		for i, l := range links {
+			names[i] = l.Name
+		}
+		return names, nil
+	}
+
+	f := g.file
+	records, err := structures.ReadLinkCreationOrderRecords(f.r, li.CreationOrderBTreeAddress, f.sb)
+	if err != nil {
+		return nil, fmt.Errorf("read creation order index: %w", err)
+	}
+	fh, err := structures.OpenFractalHeap(f.r, li.FractalHeapAddress, f.sb.LengthSize, f.sb.OffsetSize, f.sb.Endianness)
+	if err != nil {
+		return nil, fmt.Errorf("open fractal heap: %w", err)
+	}
+
+	names := make([]string, 0, len(records))
+	for _, rec := range records {
+		linkData, err := fh.ReadObjectSpecCompliant(rec.HeapID[:])
+		if err != nil {
+			return nil, fmt.Errorf("read link from fractal heap: %w", err)
+		}
+		linkMsg, err := structures.ParseLinkMessage(linkData, f.sb)
+		if err != nil {
+			return nil, fmt.Errorf("parse link message: %w", err)
+		}
+		names = append(names, linkMsg.Name)
+	}
+	return names, nil
+}
+
+// addChild records child, loaded from the hard link link. Link objects
+// written by FileWriter are recorded as the links they hold.
+func (g *Group) addChild(link LinkInfo, child Object) {
+	if stub, ok := child.(*linkStub); ok {
+		g.links = append(g.links, stub.link)
+		return
+	}
+	g.children = append(g.children, child)
+	g.links = append(g.links, link)
+}
+
+// hardLink returns the hard link of a symbol table entry.
+func hardLink(name string, address uint64) LinkInfo {
+	return LinkInfo{Name: name, Type: LinkHard, Address: address}
+}
+
+// addLink records a link that is not loaded as a child.
+func (g *Group) addLink(linkMsg *structures.LinkMessage) {
+	g.links = append(g.links, linkFromMessage(linkMsg))
+}
+
+// addSoftLink records a soft link of a symbol table, whose target is stored
+// in the group's local heap at offset. Unreadable targets are skipped.
+func (g *Group) addSoftLink(heap *structures.LocalHeap, name string, offset uint32) {
+	target, err := heap.GetString(uint64(offset))
+	if err != nil {
+		return
+	}
+	g.links = append(g.links, LinkInfo{Name: name, Type: LinkSoft, Target: target})
+}
+
+// ref returns the lookup reference of a loaded group.
+func (g *Group) ref() objectRef {
+	if g.address == 0 {
+		return objectRef{obj: g}
+	}
+	return objectRef{address: g.address, stab: g.symbolTable}
+}
+
+// followLink returns the object a soft or external link of g points to,
+// named like the link.
+func (g *Group) followLink(l LinkInfo) (Object, error) {
+	file, ref, err := g.file.followLink(g.ref(), l, new(int))
+	if err != nil {
+		return nil, err
+	}
+	return file.loadRef(ref, l.Name)
+}
+
+// linkFromMessage converts a parsed Link message.
+func linkFromMessage(linkMsg *structures.LinkMessage) LinkInfo {
+	link := LinkInfo{
+		Name:             linkMsg.Name,
+		Type:             LinkType(linkMsg.Type),
+		CreationOrder:    linkMsg.CreationOrder,
+		HasCreationOrder: linkMsg.CreationOrderValid,
+		CharSet:          CharSet(linkMsg.CharacterSet),
+	}
+	switch {
+	case linkMsg.IsHardLink():
+		link.Address = linkMsg.ObjectAddress
+	case linkMsg.IsSoftLink():
+		link.Target = linkMsg.TargetPath
+	case linkMsg.IsExternalLink():
+		link.Target = linkMsg.TargetPath
+		link.File = linkMsg.ExternalFile
+	default:
+		link.Data = linkMsg.UserData
+	}
+	return link
+}
+
+// linkStub stands in for a link object while its group is loaded.
+type linkStub struct {
+	link LinkInfo
+}
+
+// Name returns the link name.
+func (s *linkStub) Name() string {
+	return s.link.Name
+}
+
+// linkObject returns the link held by header if it is a link object, or nil.
+// FileWriter stores soft and external links as object headers holding a
+// single Link message named like the hard link to the header.
+func linkObject(header *core.ObjectHeader, name string, sb *core.Superblock) *LinkInfo {
+	var link *LinkInfo
+	for _, msg := range header.Messages {
+		switch msg.Type {
+		case core.MsgNil:
+		case core.MsgLinkMessage:
+			if link != nil {
+				return nil
+			}
+			linkMsg, err := structures.ParseLinkMessage(msg.Data, sb)
+			if err != nil || linkMsg.IsHardLink() || linkMsg.Name != name {
+				return nil
+			}
+			l := linkFromMessage(linkMsg)
+			link = &l
+		default:
+			return nil
+		}
+	}
+	return link
+}
+
+// readLinkObject returns the link held by the object at address if it is a
+// link object, or nil.
+func (f *File) readLinkObject(address uint64, name string) *LinkInfo {
+	if readSignature(f.r, address) == SignatureSNOD {
+		return nil
+	}
+	header, err := core.ReadObjectHeader(f.r, address, f.sb)
+	if err != nil {
+		return nil
+	}
+	return linkObject(header, name, f.sb)
+}
+
+// resolvePath resolves names from the group ref of f, following soft and
+// external links if enabled. It returns the file and object the path leads
+// to; hops counts the links followed so far.
+func (f *File) resolvePath(ref objectRef, names []string, hops *int) (*File, objectRef, error) {
+	for i, name := range names {
+		next, err := f.findLink(ref, name)
+		if err != nil {
+			if errors.Is(err, errNotGroup) {
+				return nil, objectRef{}, fmt.Errorf("%w: %q is not a group", ErrNotFound, strings.Join(names[:i], "/"))
+			}
+			return nil, objectRef{}, err
+		}
+		if next == nil {
+			return nil, objectRef{}, fmt.Errorf("%w: %q", ErrNotFound, strings.Join(names[:i+1], "/"))
+		}
+		if next.link != nil {
+			file, target, err := f.followLink(ref, *next.link, hops)
+			if err != nil {
+				return nil, objectRef{}, err
+			}
+			f, next = file, &target
+		}
+		ref = *next
+	}
+	return f, ref, nil
+}
+
+// followLink resolves the soft or external link l of the group parent.
+func (f *File) followLink(parent objectRef, l LinkInfo, hops *int) (*File, objectRef, error) {
+	if l.Type.IsUserDefined() {
+		return nil, objectRef{}, fmt.Errorf("link %q of type %s cannot be followed", l.Name, l.Type)
+	}
+	if !f.config.followLinks {
+		return nil, objectRef{}, fmt.Errorf("%q is a %s link (open the file with WithFollowLinks to follow it)", l.Name, l.Type)
+	}
+	*hops++
+	if *hops > maxLinkHops {
+		return nil, objectRef{}, fmt.Errorf("link %q: more than %d links followed (link cycle?)", l.Name, maxLinkHops)
+	}
+
+	switch l.Type {
+	case LinkSoft:
+		start := parent
+		if strings.HasPrefix(l.Target, "/") {
+			start = f.root.ref()
+		}
+		file, ref, err := f.resolvePath(start, splitPath(l.Target), hops)
+		if err != nil {
+			return nil, objectRef{}, fmt.Errorf("soft link %q -> %q: %w", l.Name, l.Target, err)
+		}
+		return file, ref, nil
+	case LinkExternal:
+		ext, err := f.linkFile(l.File)
+		if err != nil {
+			return nil, objectRef{}, fmt.Errorf("external link %q: %w", l.Name, err)
+		}
+		file, ref, err := ext.resolvePath(ext.root.ref(), splitPath(l.Target), hops)
+		if err != nil {
+			return nil, objectRef{}, fmt.Errorf("external link %q -> %s:%s: %w", l.Name, l.File, l.Target, err)
+		}
+		return file, ref, nil
+	default:
+		return nil, objectRef{}, fmt.Errorf("link %q of type %s cannot be followed", l.Name, l.Type)
+	}
+}
+
+// linkFile returns the file name of an external link, opening it on first
+// use. See WithExternalLinkPrefix.
+func (f *File) linkFile(name string) (*File, error) {
+	f.filesMu.Lock()
+	defer f.filesMu.Unlock()
+
+	if file, ok := f.linkFiles[name]; ok {
+		return file, nil
+	}
+
+	var lastErr error
+	for _, path := range f.searchPaths(name, "HDF5_EXT_PREFIX", f.config.externalPrefix) {
+		file, err := open(path, f.config.linked())
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if f.linkFiles == nil {
+			f.linkFiles = make(map[string]*File)
+		}
+		f.linkFiles[name] = file
+		return file, nil
+	}
+	return nil, fmt.Errorf("failed to open external file %q: %w", name, lastErr)
+}
+
+// walker visits the objects of a file for Walk.
+type walker struct {
+	fn     func(string, Object)
+	follow bool
+
+	// ancestors holds the groups on the path being walked, by file and
+	// address, when links are followed.
+	ancestors map[walkKey]bool
+}
+
+type walkKey struct {
+	file    string
+	image   *File // File opened from a reader, which has no name.
+	address uint64
+}
+
+func (w *walker) walkGroup(g *Group, currentPath string) {
+	w.fn(currentPath, g)
+
+	if w.follow {
+		key := walkKey{image: g.file, address: g.address}
+		if g.file.filename != "" {
+			name, err := filepath.Abs(g.file.filename)
+			if err != nil {
+				name = g.file.filename
+			}
+			key = walkKey{file: name, address: g.address}
+		}
+		if w.ancestors[key] {
+			return
+		}
+		w.ancestors[key] = true
+		defer delete(w.ancestors, key)
+	}
+
+	for _, child := range g.Children() {
+		w.visit(child, currentPath+child.Name())
+	}
+
+	if !w.follow {
+		return
+	}
+	for _, l := range g.links {
+		if l.Type == LinkHard {
+			continue
+		}
+		// Dangling links and unreadable targets are skipped, as Walk
+		// reports no errors.
+		obj, err := g.followLink(l)
+		if err != nil {
+			continue
+		}
+		w.visit(obj, currentPath+l.Name)
+	}
+}
+
+func (w *walker) visit(obj Object, path string) {
+	if group, ok := obj.(*Group); ok {
+		w.walkGroup(group, path+"/")
+	} else {
+		w.fn(path, obj)
+	}
+}
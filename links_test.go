@@ -0,0 +1,347 @@
+package hdf5
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroupLinks_SoftLinks(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "tsoftlinks.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	group, err := f.OpenGroup("/group1")
+	require.NoError(t, err)
+	links, err := group.Links()
+	require.NoError(t, err)
+
+	targets := make(map[string]string)
+	for _, l := range links {
+		require.Equal(t, LinkSoft, l.Type, l.Name)
+		targets[l.Name] = l.Target
+	}
+	require.Equal(t, map[string]string{
+		"soft_dangle":    "not_yet",
+		"soft_dset1":     "/dset1",
+		"soft_dset2":     "/dset2",
+		"soft_dtype":     "/dtype",
+		"soft_empty_grp": "/group_empty",
+	}, targets)
+	require.Empty(t, group.Children())
+
+	links, err = f.Root().Links()
+	require.NoError(t, err)
+	var hard []string
+	for _, l := range links {
+		if l.Type == LinkHard {
+			require.NotZero(t, l.Address)
+			hard = append(hard, l.Name)
+		}
+	}
+	require.ElementsMatch(t, []string{"dset1", "dset2", "dtype", "group1", "group_empty"}, hard)
+}
+
+func TestGroupLinks_SymbolTable(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "tslink.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	links, err := f.Root().Links()
+	require.NoError(t, err)
+	require.Equal(t, []LinkInfo{
+		{Name: "slink1", Type: LinkSoft, Target: "somevalue"},
+		{Name: "slink2", Type: LinkSoft, Target: "linkvalue"},
+	}, links)
+}
+
+func TestGet_FollowSoftLinks(t *testing.T) {
+	name := filepath.Join("testdata", "hdf5_official", "tsoftlinks.h5")
+
+	f, err := Open(name)
+	require.NoError(t, err)
+	_, err = f.Get("/group1/soft_dset1")
+	require.ErrorContains(t, err, "WithFollowLinks")
+	require.False(t, errors.Is(err, ErrNotFound))
+	require.NoError(t, f.Close())
+
+	f, err = Open(name, WithFollowLinks(true))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/group1/soft_dset1")
+	require.NoError(t, err)
+	require.Equal(t, "soft_dset1", ds.Name())
+	data, err := ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{0, 0, 1, 1, 2, 2, 3, 3}, data)
+
+	// Soft link to a group, then a path below it.
+	group, err := f.OpenGroup("/soft_group1")
+	require.NoError(t, err)
+	require.Equal(t, "soft_group1", group.Name())
+	ds, err = group.OpenDataset("soft_dset2")
+	require.NoError(t, err)
+	_, err = ds.Read()
+	require.NoError(t, err)
+
+	obj, err := f.Get("soft_group1/soft_dtype")
+	require.NoError(t, err)
+	require.IsType(t, &NamedDatatype{}, obj)
+
+	_, err = f.Get("/soft_dangle")
+	require.ErrorIs(t, err, ErrNotFound)
+	ok, err := f.Exists("/group1/soft_dangle")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestGet_FollowExternalLinks(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "textlinksrc.h5"), WithFollowLinks(true))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	links, err := f.Root().Links()
+	require.NoError(t, err)
+	external := make(map[string]LinkInfo)
+	for _, l := range links {
+		external[l.Name] = l
+	}
+	require.Equal(t, LinkInfo{Name: "ext_link2", Type: LinkExternal, File: "textlinktar.h5", Target: "dset"}, external["ext_link2"])
+
+	ds, err := f.OpenDataset("/ext_link2")
+	require.NoError(t, err)
+	data, err := ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6}, data)
+
+	// Relative object path, then a hard link in the other file.
+	ds, err = f.OpenDataset("/ext_link1/dset")
+	require.NoError(t, err)
+	data, err = ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6}, data)
+
+	// External link to a soft link in a third file.
+	ds, err = f.OpenDataset("/ext2soft_link1")
+	require.NoError(t, err)
+	data, err = ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{0, 0, 1, 1, 2, 2, 3, 3}, data)
+
+	_, err = f.Get("/ext2softdangle_link1")
+	require.ErrorIs(t, err, ErrNotFound)
+
+	// ext_link4 -> textlinktar.h5:/group/elink_t2 -> textlinksrc.h5:/ext_link4.
+	_, err = f.Get("/ext_link4")
+	require.ErrorContains(t, err, "links followed")
+
+	// An external link back to the root of this file.
+	group, err := f.OpenGroup("/ext_link1/elink_t1")
+	require.NoError(t, err)
+	ok, err := group.Exists("ext_link2")
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestWalk_FollowLinks(t *testing.T) {
+	name := filepath.Join("testdata", "hdf5_official", "textlinksrc.h5")
+
+	f, err := Open(name)
+	require.NoError(t, err)
+	var paths []string
+	f.Walk(func(path string, _ Object) { paths = append(paths, path) })
+	require.NoError(t, f.Close())
+	require.Equal(t, []string{"/"}, paths)
+
+	f, err = Open(name, WithFollowLinks(true))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	seen := make(map[string]bool)
+	f.Walk(func(path string, _ Object) {
+		require.False(t, seen[path], "visited %s twice", path)
+		seen[path] = true
+	})
+	require.True(t, seen["/ext_link2"])
+	require.True(t, seen["/ext_link1/"])
+	require.True(t, seen["/ext_link1/dset"])
+	require.True(t, seen["/ext2soft_link1"])
+	require.False(t, seen["/ext2softdangle_link1"])
+
+	// elink_t1 leads back to this file's root, which is not walked again.
+	require.True(t, seen["/ext_link1/elink_t1/"])
+	for path := range seen {
+		require.False(t, strings.HasPrefix(path, "/ext_link1/elink_t1/ext_link"), path)
+	}
+}
+
+func TestWithExternalLinkPrefix(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, "links.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/links")
+	require.NoError(t, err)
+	require.NoError(t, fw.CreateExternalLink("/links/tar", "textlinktar.h5", "/dset"))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename, WithFollowLinks(true))
+	require.NoError(t, err)
+	_, err = f.Get("/links/tar")
+	require.ErrorContains(t, err, "failed to open external file")
+	require.NoError(t, f.Close())
+
+	prefix, err := filepath.Abs(filepath.Join("testdata", "hdf5_official"))
+	require.NoError(t, err)
+	f, err = Open(filename, WithFollowLinks(true), WithExternalLinkPrefix(prefix))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ds, err := f.OpenDataset("/links/tar")
+	require.NoError(t, err)
+	data, err := ds.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6}, data)
+}
+
+func TestWrittenLinks_ReadBack(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(dir, "target.h5")
+	filename := filepath.Join(dir, "links.h5")
+
+	fw, err := CreateForWrite(target, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/values", Int32, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{7, 8, 9}))
+	require.NoError(t, fw.Close())
+
+	fw, err = CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/data")
+	require.NoError(t, err)
+	ds, err = fw.CreateDataset("/data/temperature", Float64, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{1.5, 2.5}))
+	_, err = fw.CreateGroup("/links")
+	require.NoError(t, err)
+	require.NoError(t, fw.CreateSoftLink("/links/temp", "/data/temperature"))
+	require.NoError(t, fw.CreateSoftLink("/links/data", "/data"))
+	require.NoError(t, fw.CreateSoftLink("/links/loop", "/links/loop"))
+	require.NoError(t, fw.CreateExternalLink("/links/ext", "target.h5", "/values"))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename, WithFollowLinks(true))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	group, err := f.OpenGroup("/links")
+	require.NoError(t, err)
+	require.Empty(t, group.Children())
+	links, err := group.Links()
+	require.NoError(t, err)
+	require.ElementsMatch(t, []LinkInfo{
+		{Name: "temp", Type: LinkSoft, Target: "/data/temperature"},
+		{Name: "data", Type: LinkSoft, Target: "/data"},
+		{Name: "loop", Type: LinkSoft, Target: "/links/loop"},
+		{Name: "ext", Type: LinkExternal, File: "target.h5", Target: "/values"},
+	}, links)
+
+	ds2, err := f.OpenDataset("/links/temp")
+	require.NoError(t, err)
+	data, err := ds2.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1.5, 2.5}, data)
+
+	ds2, err = f.OpenDataset("/links/data/temperature")
+	require.NoError(t, err)
+	require.Equal(t, "temperature", ds2.Name())
+
+	ds2, err = f.OpenDataset("/links/ext")
+	require.NoError(t, err)
+	data, err = ds2.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{7, 8, 9}, data)
+
+	_, err = f.Get("/links/loop")
+	require.ErrorContains(t, err, "links followed")
+
+	var paths []string
+	f.Walk(func(path string, _ Object) { paths = append(paths, path) })
+	require.Contains(t, paths, "/links/temp")
+	require.Contains(t, paths, "/links/data/temperature")
+	require.Contains(t, paths, "/links/ext")
+	require.NotContains(t, paths, "/links/loop")
+}
+
+func TestChildrenByCreationOrder(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "tordergr.h5"))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	names := func(objs []Object) []string {
+		var result []string
+		for _, obj := range objs {
+			result = append(result, obj.Name())
+		}
+		return result
+	}
+
+	children, err := f.Root().ChildrenByCreationOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"2", "1"}, names(children))
+
+	group, err := f.OpenGroup("/1")
+	require.NoError(t, err)
+	children, err = group.ChildrenByCreationOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"c", "b", "a"}, names(children))
+
+	links, err := group.Links()
+	require.NoError(t, err)
+	for i, l := range links {
+		require.True(t, l.HasCreationOrder, l.Name)
+		require.Equal(t, CharSetASCII, l.CharSet, l.Name)
+		require.Equal(t, int64(i), l.CreationOrder, l.Name)
+	}
+
+	group, err = f.OpenGroup("/1/a/a2")
+	require.NoError(t, err)
+	children, err = group.ChildrenByCreationOrder()
+	require.NoError(t, err)
+	require.Equal(t, []string{"a22", "a21"}, names(children))
+
+	// Group /2 was created without creation order tracking.
+	group, err = f.OpenGroup("/2")
+	require.NoError(t, err)
+	_, err = group.ChildrenByCreationOrder()
+	require.ErrorContains(t, err, "does not track link creation order")
+}
+
+func TestGroupLinks_UserDefined(t *testing.T) {
+	f, err := Open(filepath.Join("testdata", "hdf5_official", "tudlink.h5"), WithFollowLinks(true))
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	links, err := f.Root().Links()
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+	for _, l := range links {
+		require.Equal(t, LinkType(187), l.Type, l.Name)
+		require.True(t, l.Type.IsUserDefined())
+		require.Equal(t, "user-defined(187)", l.Type.String())
+	}
+	require.Empty(t, f.Root().Children())
+
+	_, err = f.Get("/udlink1")
+	require.ErrorContains(t, err, "cannot be followed")
+
+	var paths []string
+	f.Walk(func(path string, _ Object) { paths = append(paths, path) })
+	require.Equal(t, []string{"/"}, paths)
+}
@@ -0,0 +1,395 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/structures"
+	"github.com/meko-christian/go-hdf5/internal/utils"
+)
+
+// ErrNotFound is returned (wrapped) by path lookups when no object exists at
+// the requested path.
+var ErrNotFound = errors.New("object not found")
+
+// objectRef identifies an object reached during path resolution without
+// loading it (and, for groups, their children).
+type objectRef struct {
+	address uint64
+
+	// stab holds the symbol table addresses cached in the parent's symbol
+	// table entry (H5G_CACHED_STAB), used by v0 groups without a Symbol
+	// Table message in their own object header.
+	stab *structures.SymbolTable
+
+	// obj is set when the object is already loaded. Used for groups without
+	// an object header (traditional SNOD format), which are searched in memory.
+	obj Object
+
+	// link is set instead of the fields above for soft and external links,
+	// which are followed by resolvePath.
+	link *LinkInfo
+}
+
+// Get returns the object at path. Absolute paths ("/a/b") and paths relative
+// to the root group ("a/b") are both accepted; "/" returns the root group.
+//
+// Path components are resolved link by link on disk, using the name index of
+// dense groups and the symbol table B-tree of old-style groups, so only the
+// objects along the path are read. If nothing exists at path, the returned
+// error wraps ErrNotFound.
+//
+// Example:
+//
+//	obj, err := f.Get("/measurements/run1/temperature")
+//	if errors.Is(err, hdf5.ErrNotFound) {
+//	    // no such object
+//	}
+func (f *File) Get(path string) (Object, error) {
+	if f.root == nil {
+		return nil, errors.New("file has no root group loaded")
+	}
+	return f.root.Get(path)
+}
+
+// Exists reports whether an object exists at path.
+// Lookup failures other than a missing object are returned as errors.
+func (f *File) Exists(path string) (bool, error) {
+	return exists(f.Get(path))
+}
+
+// OpenDataset returns the dataset at path.
+func (f *File) OpenDataset(path string) (*Dataset, error) {
+	obj, err := f.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	return asDataset(obj, path)
+}
+
+// OpenGroup returns the group at path.
+func (f *File) OpenGroup(path string) (*Group, error) {
+	obj, err := f.Get(path)
+	if err != nil {
+		return nil, err
+	}
+	return asGroup(obj, path)
+}
+
+// Get returns the object at relPath, resolved relative to this group.
+// Paths starting with "/" are resolved from the file's root group, and an
+// empty path (or ".") returns the group itself. See File.Get.
+func (g *Group) Get(relPath string) (Object, error) {
+	start := g
+	if strings.HasPrefix(relPath, "/") {
+		start = g.file.root
+	}
+
+	names := splitPath(relPath)
+	if len(names) == 0 {
+		return start, nil
+	}
+
+	file, ref, err := g.file.resolvePath(start.ref(), names, new(int))
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("lookup %q: %w", relPath, err)
+	}
+
+	obj, err := file.loadRef(ref, names[len(names)-1])
+	if err != nil {
+		return nil, fmt.Errorf("load %q: %w", relPath, err)
+	}
+	return obj, nil
+}
+
+// Exists reports whether an object exists at relPath. See Group.Get.
+func (g *Group) Exists(relPath string) (bool, error) {
+	return exists(g.Get(relPath))
+}
+
+// OpenDataset returns the dataset at relPath. See Group.Get.
+func (g *Group) OpenDataset(relPath string) (*Dataset, error) {
+	obj, err := g.Get(relPath)
+	if err != nil {
+		return nil, err
+	}
+	return asDataset(obj, relPath)
+}
+
+// OpenGroup returns the group at relPath. See Group.Get.
+func (g *Group) OpenGroup(relPath string) (*Group, error) {
+	obj, err := g.Get(relPath)
+	if err != nil {
+		return nil, err
+	}
+	return asGroup(obj, relPath)
+}
+
+// splitPath returns the link names of an HDF5 path, skipping empty and "."
+// components.
+func splitPath(path string) []string {
+	var names []string
+	for _, name := range strings.Split(path, "/") {
+		if name != "" && name != "." {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func exists(_ Object, err error) (bool, error) {
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func asDataset(obj Object, path string) (*Dataset, error) {
+	ds, ok := obj.(*Dataset)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a dataset", path)
+	}
+	return ds, nil
+}
+
+func asGroup(obj Object, path string) (*Group, error) {
+	group, ok := obj.(*Group)
+	if !ok {
+		return nil, fmt.Errorf("%q is not a group", path)
+	}
+	return group, nil
+}
+
+// errNotGroup is returned by findLink when a path component names an object
+// that cannot hold links.
+var errNotGroup = errors.New("not a group")
+
+// findLink resolves the link called name in the group identified by ref.
+// Returns nil (and no error) if the group has no such link.
+//
+//nolint:gocognit,gocyclo,cyclop // Mirrors the link storage formats handled by loadModernGroup
+func (f *File) findLink(ref objectRef, name string) (*objectRef, error) {
+	if ref.obj != nil {
+		return findLoadedLink(ref.obj, name)
+	}
+
+	// Traditional groups have no object header; search the loaded group.
+	if readSignature(f.r, ref.address) == SignatureSNOD {
+		obj, err := f.loadRef(ref, "")
+		if err != nil {
+			return nil, err
+		}
+		return findLoadedLink(obj, name)
+	}
+
+	header, err := core.ReadObjectHeader(f.r, ref.address, f.sb)
+	if err != nil {
+		return nil, utils.WrapError("object header read failed", err)
+	}
+	if header.Type == core.ObjectTypeDataset || header.Type == core.ObjectTypeDatatype {
+		return nil, errNotGroup
+	}
+
+	// Compact storage: links are Link messages in the object header.
+	hasLinkMessages := false
+	var linkInfo *core.LinkInfoMessage
+	stab := ref.stab
+	for _, msg := range header.Messages {
+		switch msg.Type {
+		case core.MsgLinkMessage:
+			hasLinkMessages = true
+			linkMsg, err := structures.ParseLinkMessage(msg.Data, f.sb)
+			if err != nil {
+				return nil, utils.WrapError("link message parse failed", err)
+			}
+			if linkMsg.Name == name {
+				return linkTarget(linkMsg), nil
+			}
+		case core.MsgLinkInfo:
+			linkInfo, err = core.ParseLinkInfoMessage(msg.Data, f.sb)
+			if err != nil {
+				return nil, utils.WrapError("link info parse failed", err)
+			}
+		case core.MsgSymbolTable:
+			if len(msg.Data) >= 16 {
+				stab = &structures.SymbolTable{
+					Version:      1,
+					BTreeAddress: f.sb.Endianness.Uint64(msg.Data[0:8]),
+					HeapAddress:  f.sb.Endianness.Uint64(msg.Data[8:16]),
+				}
+			}
+		}
+	}
+	if hasLinkMessages {
+		return nil, nil
+	}
+
+	// Dense storage: links in a fractal heap, indexed by name hash.
+	if linkInfo != nil && linkInfo.HasFractalHeap() && linkInfo.HasNameBTree() {
+		return f.findDenseLink(linkInfo, name)
+	}
+
+	// Old-style groups: for v0/v1 files, the root group's symbol table
+	// addresses may only be cached in the superblock.
+	if stab == nil && f.sb.Version <= core.Version1 && ref.address == f.sb.RootGroup &&
+		f.sb.RootBTreeAddr != 0 && f.sb.RootHeapAddr != 0 {
+		stab = &structures.SymbolTable{
+			Version:      1,
+			BTreeAddress: f.sb.RootBTreeAddr,
+			HeapAddress:  f.sb.RootHeapAddr,
+		}
+	}
+	if stab != nil {
+		return f.findSymbolTableLink(stab, name)
+	}
+
+	return nil, nil
+}
+
+// findDenseLink looks up name in the link name index B-tree v2 and reads the
+// matching link messages from the fractal heap.
+func (f *File) findDenseLink(linkInfo *core.LinkInfoMessage, name string) (*objectRef, error) {
+	records, err := structures.FindLinkNameRecords(f.r, linkInfo.NameBTreeAddress, name, f.sb)
+	if err != nil {
+		return nil, fmt.Errorf("search link name index: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	fh, err := structures.OpenFractalHeap(f.r, linkInfo.FractalHeapAddress,
+		f.sb.LengthSize, f.sb.OffsetSize, f.sb.Endianness)
+	if err != nil {
+		return nil, fmt.Errorf("open fractal heap: %w", err)
+	}
+
+	// Records are matched by name hash; compare the stored names to rule out
+	// collisions.
+	for _, rec := range records {
+		linkData, err := fh.ReadObjectSpecCompliant(rec.HeapID[:])
+		if err != nil {
+			return nil, fmt.Errorf("read link from fractal heap: %w", err)
+		}
+		linkMsg, err := structures.ParseLinkMessage(linkData, f.sb)
+		if err != nil {
+			return nil, utils.WrapError("link message parse failed", err)
+		}
+		if linkMsg.Name == name {
+			return linkTarget(linkMsg), nil
+		}
+	}
+
+	return nil, nil
+}
+
+// findSymbolTableLink looks up name in an old-style group's symbol table.
+func (f *File) findSymbolTableLink(stab *structures.SymbolTable, name string) (*objectRef, error) {
+	heap, err := structures.LoadLocalHeap(f.r, stab.HeapAddress, f.sb)
+	if err != nil {
+		return nil, utils.WrapError("local heap load failed", err)
+	}
+
+	var entry *structures.BTreeEntry
+	switch sig := readSignature(f.r, stab.BTreeAddress); sig {
+	case "TREE":
+		entry, err = structures.FindGroupBTreeEntry(f.r, stab.BTreeAddress, heap, name, f.sb)
+		if err != nil {
+			return nil, utils.WrapError("B-tree search failed", err)
+		}
+	case "BTRE":
+		// Modern B-tree format has no name ordering to search by.
+		entries, err := structures.ReadBTreeEntries(f.r, stab.BTreeAddress, f.sb)
+		if err != nil {
+			return nil, utils.WrapError("B-tree read failed", err)
+		}
+		for i := range entries {
+			linkName, err := heap.GetString(entries[i].LinkNameOffset)
+			if err == nil && linkName == name {
+				entry = &entries[i]
+				break
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unknown B-tree signature: %q at address 0x%X", sig, stab.BTreeAddress)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	if entry.IsSoftLink() {
+		target, err := heap.GetString(uint64(entry.CachedSoftLinkOffset))
+		if err != nil {
+			return nil, utils.WrapError("soft link value read failed", err)
+		}
+		return &objectRef{link: &LinkInfo{Name: name, Type: LinkSoft, Target: target}}, nil
+	}
+	if link := f.readLinkObject(entry.ObjectAddress, name); link != nil {
+		return &objectRef{link: link}, nil
+	}
+
+	next := &objectRef{address: entry.ObjectAddress}
+	if entry.CacheType == structures.CacheTypeSymbolTable && entry.CachedBTreeAddr != 0 {
+		next.stab = &structures.SymbolTable{
+			Version:      1,
+			BTreeAddress: entry.CachedBTreeAddr,
+			HeapAddress:  entry.CachedHeapAddr,
+		}
+	}
+	return next, nil
+}
+
+// linkTarget returns the object a link message points to.
+func linkTarget(linkMsg *structures.LinkMessage) *objectRef {
+	if linkMsg.IsHardLink() {
+		return &objectRef{address: linkMsg.ObjectAddress}
+	}
+	link := linkFromMessage(linkMsg)
+	return &objectRef{link: &link}
+}
+
+// findLoadedLink searches the children and links of an already loaded group.
+func findLoadedLink(obj Object, name string) (*objectRef, error) {
+	group, ok := obj.(*Group)
+	if !ok {
+		return nil, errNotGroup
+	}
+	for _, child := range group.children {
+		if child.Name() == name {
+			return &objectRef{obj: child}, nil
+		}
+	}
+	for i := range group.links {
+		if link := group.links[i]; link.Name == name && link.Type != LinkHard {
+			return &objectRef{link: &link}, nil
+		}
+	}
+	return nil, nil
+}
+
+// loadRef loads the object identified by ref, naming it name.
+func (f *File) loadRef(ref objectRef, name string) (Object, error) {
+	if ref.obj != nil {
+		return ref.obj, nil
+	}
+
+	// The B-trees of groups loaded by Open are already marked as visited,
+	// which would leave them without children; track cycles afresh.
+	f.loadMu.Lock()
+	defer f.loadMu.Unlock()
+	visited := f.visitedBTrees
+	f.visitedBTrees = make(map[uint64]bool)
+	defer func() { f.visitedBTrees = visited }()
+
+	if ref.stab != nil {
+		return loadGroupWithCachedSymbolTable(f, ref.address, name, ref.stab.BTreeAddress, ref.stab.HeapAddress)
+	}
+	return loadObject(f, ref.address, name)
+}
@@ -0,0 +1,152 @@
+package hdf5
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLookup_NestedPaths(t *testing.T) {
+	f, err := Open("testdata/with_groups.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	t.Run("absolute and relative paths", func(t *testing.T) {
+		for _, path := range []string{"/subgroup/nested_group/nested_data", "subgroup/nested_group/nested_data", "//subgroup/./nested_group/nested_data"} {
+			ds, err := f.OpenDataset(path)
+			require.NoError(t, err, path)
+			require.Equal(t, "nested_data", ds.Name())
+		}
+	})
+
+	t.Run("root", func(t *testing.T) {
+		obj, err := f.Get("/")
+		require.NoError(t, err)
+		require.Same(t, f.Root(), obj)
+	})
+
+	t.Run("group relative", func(t *testing.T) {
+		sub, err := f.OpenGroup("/subgroup")
+		require.NoError(t, err)
+		require.Equal(t, "subgroup", sub.Name())
+		require.Len(t, sub.Children(), 2)
+
+		ds, err := sub.OpenDataset("nested_group/nested_data")
+		require.NoError(t, err)
+		require.Equal(t, "nested_data", ds.Name())
+
+		// Absolute paths are resolved from the root group.
+		ds, err = sub.OpenDataset("/dataset1")
+		require.NoError(t, err)
+		require.Equal(t, "dataset1", ds.Name())
+
+		self, err := sub.Get("")
+		require.NoError(t, err)
+		require.Same(t, sub, self)
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		for _, path := range []string{"/missing", "/subgroup/missing", "/missing/nested_data", "/dataset1/child"} {
+			_, err := f.Get(path)
+			require.ErrorIs(t, err, ErrNotFound, path)
+
+			ok, err := f.Exists(path)
+			require.NoError(t, err, path)
+			require.False(t, ok, path)
+		}
+
+		ok, err := f.Exists("/subgroup/dataset2")
+		require.NoError(t, err)
+		require.True(t, ok)
+	})
+
+	t.Run("wrong type", func(t *testing.T) {
+		_, err := f.OpenGroup("/dataset1")
+		require.ErrorContains(t, err, "is not a group")
+		require.NotErrorIs(t, err, ErrNotFound)
+
+		_, err = f.OpenDataset("/subgroup")
+		require.ErrorContains(t, err, "is not a dataset")
+	})
+}
+
+func TestLookup_SymbolTableGroup(t *testing.T) {
+	// Old-style root group with 100 committed datatypes "0".."99".
+	f, err := Open("testdata/hdf5_official/h5repack_early.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for _, name := range []string{"0", "1", "42", "99"} {
+		obj, err := f.Get("/" + name)
+		require.NoError(t, err, name)
+		require.IsType(t, &NamedDatatype{}, obj)
+		require.Equal(t, name, obj.Name())
+	}
+
+	ok, err := f.Exists("/100")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestLookup_DenseGroup(t *testing.T) {
+	// Root group with 38 links in dense storage (fractal heap + name index).
+	f, err := Open("testdata/hdf5_official/h5repack_objs.h5")
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for _, name := range []string{"integer", "float3D", "dset_referenced", "bitfield3D"} {
+		ds, err := f.OpenDataset("/" + name)
+		require.NoError(t, err, name)
+		require.Equal(t, name, ds.Name())
+	}
+
+	obj, err := f.Get("/type")
+	require.NoError(t, err)
+	require.IsType(t, &NamedDatatype{}, obj)
+
+	g1, err := f.OpenGroup("g1")
+	require.NoError(t, err)
+	require.Equal(t, "g1", g1.Name())
+
+	ok, err := f.Exists("/integer4D")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestFileWriter_OpenDatasetByPath(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "reopen.h5")
+
+	// Names are created out of order: lookups rely on the writer keeping
+	// symbol table entries sorted.
+	names := []string{"/zeta", "/alpha", "/mid", "/a/values", "/a/beta"}
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/a")
+	require.NoError(t, err)
+	for _, name := range names {
+		_, err = fw.CreateDataset(name, Float64, []uint64{4})
+		require.NoError(t, err)
+	}
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	for _, name := range names {
+		ok, err := f.Exists(name)
+		require.NoError(t, err)
+		require.True(t, ok, name)
+	}
+	require.NoError(t, f.Close())
+
+	fw, err = OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	defer func() { _ = fw.Close() }()
+
+	_, err = fw.OpenDataset("/a/values")
+	require.NoError(t, err)
+
+	_, err = fw.OpenDataset("/a/missing")
+	require.ErrorContains(t, err, `dataset "/a/missing" not found`)
+}
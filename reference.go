@@ -0,0 +1,422 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// Reference is a reference value read with Dataset.ReadReferences: an
+// ObjectRef, a RegionRef or a Ref. File.Dereference loads the object it
+// points to.
+type Reference = core.Reference
+
+// ObjectRef is an object reference: the address of the referenced group,
+// dataset or named datatype. Reference-typed attributes read with ReadValue
+// return ObjectRef or []ObjectRef values.
+type ObjectRef = core.ObjectRef
+
+// RegionRef is a dataset region reference: the global heap object holding the
+// referenced dataset's address and selection. Reference-typed attributes read
+// with ReadValue return RegionRef or []RegionRef values.
+type RegionRef = core.RegionRef
+
+// Ref is a revised reference (HDF5 1.12+) to an object, a dataset region or
+// an attribute. A non-empty File names the file holding the referenced
+// object, which Dereference opens with the file opener (see WithFileOpener).
+// Reference-typed attributes read with ReadValue return Ref or []Ref values.
+type Ref = core.Ref
+
+// Revised reference types, stored in Ref.Type.
+const (
+	RefTypeObject    = core.ReferenceTypeObject2   // An object.
+	RefTypeRegion    = core.ReferenceTypeRegion2   // A dataset region.
+	RefTypeAttribute = core.ReferenceTypeAttribute // An attribute.
+)
+
+// FileOpener opens the file named by a revised reference into another file.
+// name is the file name stored in the reference.
+type FileOpener func(name string) (*File, error)
+
+// WithFileOpener sets how files named by revised references into other
+// files are opened, like the file access property list of H5Ropen_object.
+//
+// By default, a relative name is opened next to the referencing file, then
+// relative to the working directory; an absolute name is opened as given,
+// then by its base name next to the referencing file. Files opened by
+// default are closed with the referencing file; files returned by opener
+// are left to the caller.
+//
+// Example:
+//
+//	f, err := hdf5.Open("index.h5", hdf5.WithFileOpener(func(name string) (*hdf5.File, error) {
+//	    return hdf5.Open(filepath.Join("/data/archive", name))
+//	}))
+func WithFileOpener(opener FileOpener) OpenOption {
+	return func(cfg *openConfig) {
+		cfg.fileOpener = opener
+	}
+}
+
+// ReadReferences reads all elements of a reference dataset. Each element is
+// an ObjectRef, a RegionRef or a Ref, depending on the dataset's reference
+// type; unset elements report IsNull.
+//
+// Example:
+//
+//	refs, err := ds.ReadReferences()
+//	for _, ref := range refs {
+//	    obj, err := f.Dereference(ref)
+//	    ...
+//	}
+func (d *Dataset) ReadReferences() ([]Reference, error) {
+	rawData, info, err := d.readRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	if info.Datatype.Class != core.DatatypeReference {
+		return nil, fmt.Errorf("dataset %q is not a reference dataset: %s", d.name, info.Datatype)
+	}
+
+	return core.DecodeReferences(d.file.r, rawData, info.Datatype, info.Dataspace.TotalElements(), int(d.file.sb.OffsetSize))
+}
+
+// Dereference returns the object a reference points to. For a region
+// reference it returns the referenced dataset; see Region and ReadRegion for
+// the selected elements. For an attribute reference it returns the object
+// holding the attribute; see DereferenceAttribute.
+//
+// Objects linked into the file's group hierarchy are returned as loaded by
+// Open, named by their link name; objects that are only reachable through
+// the reference are loaded without a name.
+func (f *File) Dereference(ref Reference) (Object, error) {
+	if ref == nil || ref.IsNull() {
+		return nil, errors.New("cannot dereference a null reference")
+	}
+
+	switch r := ref.(type) {
+	case ObjectRef:
+		return f.objectAt(uint64(r))
+	case RegionRef:
+		ds, _, err := f.region(r)
+		if err != nil {
+			return nil, err
+		}
+		return ds, nil
+	case Ref:
+		file, err := f.refFile(r)
+		if err != nil {
+			return nil, err
+		}
+		obj, err := file.objectAt(r.Address)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := obj.(*Dataset); r.Type == RefTypeRegion && !ok {
+			return nil, fmt.Errorf("region reference points to %T, not a dataset", obj)
+		}
+		return obj, nil
+	default:
+		return nil, fmt.Errorf("unsupported reference type %T", ref)
+	}
+}
+
+// DereferenceAttribute returns the attribute an attribute reference points
+// to.
+//
+// Example:
+//
+//	attr, err := f.DereferenceAttribute(ref)
+//	value, err := attr.ReadValue()
+func (f *File) DereferenceAttribute(ref Reference) (*core.Attribute, error) {
+	if r, ok := ref.(Ref); !ok || (!r.IsNull() && r.Type != RefTypeAttribute) {
+		return nil, errors.New("not an attribute reference")
+	}
+	obj, err := f.Dereference(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	var attrs []*core.Attribute
+	switch o := obj.(type) {
+	case *Group:
+		attrs, err = o.Attributes()
+	case *Dataset:
+		attrs, err = o.Attributes()
+	case *NamedDatatype:
+		attrs, err = o.Attributes()
+	default:
+		return nil, fmt.Errorf("attribute reference points to %T", obj)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read attributes: %w", err)
+	}
+
+	name := ref.(Ref).Attribute
+	for _, attr := range attrs {
+		if attr.Name == name {
+			return attr, nil
+		}
+	}
+	return nil, fmt.Errorf("attribute %q not found", name)
+}
+
+// RegionSelection is the part of a dataset a region reference selects.
+// One field is set, or none for an empty selection.
+type RegionSelection struct {
+	All       bool                // The whole dataset.
+	Hyperslab *HyperslabSelection // A regular hyperslab.
+	Blocks    []RegionBlock       // An irregular hyperslab, as a list of blocks.
+	Points    [][]uint64          // Individual elements, in selection order.
+}
+
+// RegionBlock is one block of an irregular hyperslab selection, given by its
+// first and last (inclusive) coordinates.
+type RegionBlock struct {
+	Start []uint64
+	End   []uint64
+}
+
+// Region returns the dataset a region reference (a RegionRef or a Ref of
+// type RefTypeRegion) points to and the selection within it.
+//
+// Example:
+//
+//	ds, sel, err := f.Region(ref)
+//	if sel.Hyperslab != nil {
+//	    data, err := ds.ReadHyperslab(sel.Hyperslab)
+//	    ...
+//	}
+func (f *File) Region(ref Reference) (*Dataset, *RegionSelection, error) {
+	ds, sel, err := f.region(ref)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := &RegionSelection{}
+	switch sel.Type {
+	case core.SelectionAll:
+		result.All = true
+	case core.SelectionPoints:
+		result.Points = sel.Points
+	case core.SelectionHyperslabs:
+		if sel.Regular == nil {
+			result.Blocks = make([]RegionBlock, len(sel.Blocks))
+			for i, b := range sel.Blocks {
+				result.Blocks[i] = RegionBlock{Start: b.Start, End: b.End}
+			}
+			break
+		}
+		hs := &HyperslabSelection{
+			Start:  make([]uint64, sel.Rank),
+			Count:  make([]uint64, sel.Rank),
+			Stride: make([]uint64, sel.Rank),
+			Block:  make([]uint64, sel.Rank),
+		}
+		for i, dim := range sel.Regular {
+			hs.Start[i], hs.Count[i], hs.Stride[i], hs.Block[i] = dim.Start, dim.Count, dim.Stride, dim.Block
+		}
+		result.Hyperslab = hs
+	}
+	return ds, result, nil
+}
+
+// ReadRegion reads the elements a region reference selects, converted to
+// float64 like Dataset.Read. Elements are returned in selection order:
+// row-major for hyperslabs, the stored order for points.
+func (f *File) ReadRegion(ref Reference) ([]float64, error) {
+	ds, sel, err := f.region(ref)
+	if err != nil {
+		return nil, err
+	}
+
+	rawData, info, err := ds.readRaw()
+	if err != nil {
+		return nil, err
+	}
+	if !info.Datatype.IsNumeric() {
+		return nil, fmt.Errorf("dataset %q is not numeric: %s", ds.name, info.Datatype)
+	}
+
+	dims := info.Dataspace.Dimensions
+	runs, err := sel.Runs(dims)
+	if err != nil {
+		return nil, fmt.Errorf("invalid region selection: %w", err)
+	}
+
+	elemSize := uint64(info.Datatype.Size)
+	var selected []byte
+	for _, run := range runs {
+		off, err := regionRunOffset(run, dims)
+		if err != nil {
+			return nil, err
+		}
+		selected = append(selected, rawData[off*elemSize:(off+run.Length)*elemSize]...)
+	}
+	return convertToFloat64(selected, info.Datatype, uint64(len(selected))/elemSize)
+}
+
+// region resolves a region reference to its dataset and selection.
+func (f *File) region(ref Reference) (*Dataset, *core.Selection, error) {
+	var obj Object
+	var sel *core.Selection
+	switch r := ref.(type) {
+	case RegionRef:
+		address, s, err := core.ReadRegionReference(f.r, r, int(f.sb.OffsetSize))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to resolve region reference: %w", err)
+		}
+		if obj, err = f.objectAt(address); err != nil {
+			return nil, nil, err
+		}
+		sel = s
+	case Ref:
+		if r.IsNull() {
+			return nil, nil, errors.New("null region reference")
+		}
+		if r.Type != RefTypeRegion || r.Selection == nil {
+			return nil, nil, errors.New("not a region reference")
+		}
+		file, err := f.refFile(r)
+		if err != nil {
+			return nil, nil, err
+		}
+		if obj, err = file.objectAt(r.Address); err != nil {
+			return nil, nil, err
+		}
+		sel = r.Selection
+	default:
+		return nil, nil, fmt.Errorf("not a region reference: %T", ref)
+	}
+	ds, ok := obj.(*Dataset)
+	if !ok {
+		return nil, nil, fmt.Errorf("region reference points to %T, not a dataset", obj)
+	}
+	return ds, sel, nil
+}
+
+// refFile returns the file a revised reference points into: f itself, or the
+// file named by ref.File opened with the file opener. Referenced files are
+// opened once.
+func (f *File) refFile(ref Ref) (*File, error) {
+	if ref.File == "" {
+		return f, nil
+	}
+
+	f.filesMu.Lock()
+	defer f.filesMu.Unlock()
+
+	if file, ok := f.refFiles[ref.File]; ok {
+		return file, nil
+	}
+
+	var file *File
+	var err error
+	if f.config.fileOpener != nil {
+		file, err = f.config.fileOpener(ref.File)
+	} else {
+		for _, path := range f.refFilePaths(ref.File) {
+			if file, err = open(path, f.config.linked()); err == nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open referenced file %q: %w", ref.File, err)
+	}
+	if file == nil {
+		return nil, fmt.Errorf("failed to open referenced file %q", ref.File)
+	}
+
+	if f.refFiles == nil {
+		f.refFiles = make(map[string]*File)
+	}
+	f.refFiles[ref.File] = file
+	return file, nil
+}
+
+// refFilePaths returns the paths tried, in order, by the default file opener.
+// See WithFileOpener.
+func (f *File) refFilePaths(name string) []string {
+	origin := filepath.Dir(f.filename)
+	if filepath.IsAbs(name) {
+		return []string{name, filepath.Join(origin, filepath.Base(name))}
+	}
+	return []string{filepath.Join(origin, name), name}
+}
+
+// regionRunOffset returns the element offset of a run in a dataset of dims,
+// checking that the run lies within the dataset.
+func regionRunOffset(run core.SelectionRun, dims []uint64) (uint64, error) {
+	if len(run.Coords) != len(dims) {
+		return 0, fmt.Errorf("region selection rank %d does not match dataset rank %d", len(run.Coords), len(dims))
+	}
+	if len(dims) == 0 && run.Length > 1 {
+		return 0, errors.New("region selection out of bounds of scalar dataset")
+	}
+	var off uint64
+	for i, c := range run.Coords {
+		end := c + 1
+		if i == len(dims)-1 {
+			end = c + run.Length
+		}
+		if end > dims[i] || end < c {
+			return 0, fmt.Errorf("region selection out of bounds in dimension %d", i)
+		}
+		off = off*dims[i] + c
+	}
+	return off, nil
+}
+
+// objectAt returns the object whose header is at address.
+//
+// Objects linked into the file's group hierarchy are returned as loaded by
+// Open, named by their link name; objects that are only reachable through
+// a reference are loaded without a name.
+func (f *File) objectAt(address uint64) (Object, error) {
+	if address == f.sb.RootGroup {
+		return f.root, nil
+	}
+	if obj := findObject(f.root, address, make(map[*Group]bool)); obj != nil {
+		return obj, nil
+	}
+
+	obj, err := f.loadRef(objectRef{address: address}, "")
+	if err != nil {
+		return nil, fmt.Errorf("failed to load referenced object at address %d: %w", address, err)
+	}
+	return obj, nil
+}
+
+// findObject searches the loaded hierarchy below g for the object whose
+// header is at address.
+func findObject(g *Group, address uint64, visited map[*Group]bool) Object {
+	if g == nil || visited[g] {
+		return nil
+	}
+	visited[g] = true
+
+	if g.address == address {
+		return g
+	}
+	for _, child := range g.Children() {
+		switch c := child.(type) {
+		case *Group:
+			if obj := findObject(c, address, visited); obj != nil {
+				return obj
+			}
+		case *Dataset:
+			if c.address == address {
+				return c
+			}
+		case *NamedDatatype:
+			if c.address == address {
+				return c
+			}
+		}
+	}
+	return nil
+}
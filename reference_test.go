@@ -13,11 +13,11 @@ import (
 
 // fileClassification holds the classification of a test file.
 type fileClassification struct {
-	isCorruptFile            bool   // Files intentionally corrupted - expect error handling
-	requiresSpecialDriver    bool   // Files needing special file drivers
-	requiresOldLayoutVersion bool   // Files with layout version 1-2 (HDF5 1.6 era)
-	expectError              bool   // We expect this file to fail (either open or operations)
-	expectErrorReason        string // Why we expect error
+	isCorruptFile         bool   // Files intentionally corrupted - expect error handling
+	requiresSpecialDriver bool   // Files needing special file drivers
+	oldLayoutVersion      bool   // Files with layout version 1-2 (HDF5 1.6 era)
+	expectError           bool   // We expect this file to fail (either open or operations)
+	expectErrorReason     string // Why we expect error
 }
 
 // classifyFile determines the classification of a reference test file.
@@ -35,12 +35,12 @@ func classifyFile(name string) fileClassification {
 			name == "tsizeslheap.h5",
 
 		// Files with older data layout versions (v1-v2, HDF5 1.6 era).
-		requiresOldLayoutVersion: name == "btree_idx_1_6.h5" ||
+		// The family and multi files are left out: their raw data is in
+		// the other member files.
+		oldLayoutVersion: name == "btree_idx_1_6.h5" ||
 			name == "deflate.h5" ||
-			name == "family_v16-000000.h5" ||
 			name == "filespace_1_6.h5" ||
 			name == "fill_old.h5" ||
-			name == "multi_file_v16-s.h5" ||
 			name == "tarrold.h5" ||
 			name == "test_filters_be.h5" ||
 			name == "test_filters_le.h5" ||
@@ -62,15 +62,12 @@ func classifyFile(name string) fileClassification {
 
 // shouldSkip returns true if the file should be skipped during testing.
 func (c fileClassification) shouldSkip() bool {
-	return c.requiresSpecialDriver || c.requiresOldLayoutVersion
+	return c.requiresSpecialDriver
 }
 
 // skipReason returns the reason for skipping the file.
 func (c fileClassification) skipReason() string {
-	if c.requiresSpecialDriver {
-		return "requires special file driver"
-	}
-	return "requires old layout version (v1-v2)"
+	return "requires special file driver"
 }
 
 // TestReference_AllFiles tests all 57 reference files from HDF5 C library.
@@ -100,7 +97,9 @@ func TestReference_AllFiles(t *testing.T) {
 		}
 
 		t.Run(name, func(t *testing.T) {
-			result := testReferenceFile(t, file, name, class.isCorruptFile, class.requiresSpecialDriver)
+			// Open errors of files expected to fail must count as failures.
+			corrupt := class.isCorruptFile && !class.expectError
+			result := testReferenceFile(t, file, name, corrupt, class.requiresSpecialDriver)
 
 			// For files expected to fail, invert the result.
 			if class.expectError {
@@ -163,6 +162,55 @@ func TestReference_AllFiles(t *testing.T) {
 	require.Equal(t, 0, failed, "All reference files must pass")
 }
 
+// TestReference_OldLayoutVersion reads every dataset of the reference files
+// that use data layout message versions 1 and 2.
+func TestReference_OldLayoutVersion(t *testing.T) {
+	files, err := filepath.Glob("testdata/reference/*.h5")
+	require.NoError(t, err)
+
+	tested := 0
+	for _, file := range files {
+		name := filepath.Base(file)
+		if !classifyFile(name).oldLayoutVersion {
+			continue
+		}
+		tested++
+
+		t.Run(name, func(t *testing.T) {
+			f, err := hdf5.Open(file)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			datasets := 0
+			f.Walk(func(path string, obj hdf5.Object) {
+				ds, ok := obj.(*hdf5.Dataset)
+				if !ok {
+					return
+				}
+				datasets++
+
+				meta, err := ds.Meta()
+				require.NoError(t, err, path)
+
+				if meta.Dtype.Class == hdf5.ClassCompound {
+					// The compound members of tarrold.h5 are old-style
+					// arrays, which ReadCompound does not decode.
+					return
+				}
+				_, err = ds.Read()
+				if name == "tlayouto.h5" {
+					// 2^33 x (2^28+1) elements without storage.
+					require.ErrorContains(t, err, "too large", path)
+					return
+				}
+				require.NoError(t, err, path)
+			})
+			require.Positive(t, datasets)
+		})
+	}
+	require.Equal(t, 11, tested)
+}
+
 // testResult holds the result of testing a single file.
 type testResult struct {
 	passed   bool
@@ -0,0 +1,251 @@
+package hdf5
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+)
+
+// NewObjectRef returns an object reference to the group or dataset at path,
+// for writing to an ObjectReference dataset.
+//
+// Example:
+//
+//	ref, err := fw.NewObjectRef("/measurements/run1")
+//	ds, err := fw.CreateDataset("/index", hdf5.ObjectReference, []uint64{1})
+//	err = ds.Write([]hdf5.ObjectRef{ref})
+func (fw *FileWriter) NewObjectRef(path string) (ObjectRef, error) {
+	addr, err := fw.resolveObjectAddress(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	return ObjectRef(addr), nil
+}
+
+// NewRegionRef returns a region reference to the elements sel selects in
+// the dataset at path, for writing to a RegionReference dataset. Nil Stride
+// and Block default to 1, as for Dataset.ReadHyperslab.
+//
+// The selection is stored in the file's global heap, together with the
+// dataset address, when the reference is created.
+//
+// Example:
+//
+//	ref, err := fw.NewRegionRef("/image", &hdf5.HyperslabSelection{
+//	    Start: []uint64{10, 10},
+//	    Count: []uint64{32, 32},
+//	})
+//	ds, err := fw.CreateDataset("/regions", hdf5.RegionReference, []uint64{1})
+//	err = ds.Write([]hdf5.RegionRef{ref})
+//
+// Reference: H5Rdeprec.c - H5Rcreate() with H5R_DATASET_REGION.
+func (fw *FileWriter) NewRegionRef(path string, sel *HyperslabSelection) (RegionRef, error) {
+	addr, region, err := fw.hyperslabRegion(path, sel)
+	if err != nil {
+		return RegionRef{}, err
+	}
+	return fw.writeRegionRef(addr, region)
+}
+
+// NewPointRegionRef returns a region reference to individual elements of the
+// dataset at path, for writing to a RegionReference dataset. Each point holds
+// one coordinate per dimension; ReadRegion returns the elements in the order
+// given.
+func (fw *FileWriter) NewPointRegionRef(path string, points [][]uint64) (RegionRef, error) {
+	addr, region, err := fw.pointRegion(path, points)
+	if err != nil {
+		return RegionRef{}, err
+	}
+	return fw.writeRegionRef(addr, region)
+}
+
+// CreateObjectRef returns a revised reference to the group, dataset or named
+// datatype at path, for writing to a RevisedReference dataset.
+//
+// Revised references can point into other files: set File of a reference
+// created with the other file's writer to the name its readers should open.
+//
+// Example:
+//
+//	ref, err := fw.CreateObjectRef("/measurements/run1")
+//	ds, err := fw.CreateDataset("/index", hdf5.RevisedReference, []uint64{1})
+//	err = ds.Write([]hdf5.Ref{ref})
+//
+// Reference: H5R.c - H5Rcreate_object().
+func (fw *FileWriter) CreateObjectRef(path string) (Ref, error) {
+	addr, err := fw.resolveObjectAddress(path)
+	if err != nil {
+		return Ref{}, fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	return Ref{Type: RefTypeObject, Address: addr}, nil
+}
+
+// CreateRegionRef returns a revised reference to the elements sel selects in
+// the dataset at path. Nil Stride and Block default to 1, as for
+// Dataset.ReadHyperslab.
+//
+// Reference: H5R.c - H5Rcreate_region().
+func (fw *FileWriter) CreateRegionRef(path string, sel *HyperslabSelection) (Ref, error) {
+	addr, region, err := fw.hyperslabRegion(path, sel)
+	if err != nil {
+		return Ref{}, err
+	}
+	return Ref{Type: RefTypeRegion, Address: addr, Selection: region}, nil
+}
+
+// CreatePointRegionRef returns a revised reference to individual elements of
+// the dataset at path, like NewPointRegionRef.
+func (fw *FileWriter) CreatePointRegionRef(path string, points [][]uint64) (Ref, error) {
+	addr, region, err := fw.pointRegion(path, points)
+	if err != nil {
+		return Ref{}, err
+	}
+	return Ref{Type: RefTypeRegion, Address: addr, Selection: region}, nil
+}
+
+// CreateAttributeRef returns a revised reference to the attribute name of the
+// object at path. The attribute is looked up when the reference is
+// dereferenced, so it may be written after the reference is created.
+//
+// Reference: H5R.c - H5Rcreate_attr().
+func (fw *FileWriter) CreateAttributeRef(path, name string) (Ref, error) {
+	if name == "" {
+		return Ref{}, errors.New("attribute name cannot be empty")
+	}
+	addr, err := fw.resolveObjectAddress(path)
+	if err != nil {
+		return Ref{}, fmt.Errorf("failed to resolve %q: %w", path, err)
+	}
+	return Ref{Type: RefTypeAttribute, Address: addr, Attribute: name}, nil
+}
+
+// hyperslabRegion validates a hyperslab selection of the dataset at path and
+// returns the dataset address and the selection.
+func (fw *FileWriter) hyperslabRegion(path string, sel *HyperslabSelection) (uint64, *core.Selection, error) {
+	if sel == nil {
+		return 0, nil, errors.New("selection cannot be nil")
+	}
+	addr, dims, err := fw.regionDataset(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	// Validate a copy: validation fills in the default stride and block.
+	hs := &HyperslabSelection{Start: sel.Start, Count: sel.Count, Stride: sel.Stride, Block: sel.Block}
+	if err := validateHyperslabSelection(hs, dims); err != nil {
+		return 0, nil, fmt.Errorf("invalid selection: %w", err)
+	}
+
+	regular := make([]core.HyperslabDim, len(dims))
+	for i := range regular {
+		if hs.Count[i] > 1 && hs.Stride[i] < hs.Block[i] {
+			return 0, nil, fmt.Errorf("invalid selection: blocks overlap in dimension %d (stride %d < block %d)",
+				i, hs.Stride[i], hs.Block[i])
+		}
+		regular[i] = core.HyperslabDim{Start: hs.Start[i], Stride: hs.Stride[i], Count: hs.Count[i], Block: hs.Block[i]}
+	}
+
+	return addr, &core.Selection{
+		Type:    core.SelectionHyperslabs,
+		Rank:    len(dims),
+		Regular: regular,
+	}, nil
+}
+
+// pointRegion validates a point selection of the dataset at path and
+// returns the dataset address and the selection.
+func (fw *FileWriter) pointRegion(path string, points [][]uint64) (uint64, *core.Selection, error) {
+	if len(points) == 0 {
+		return 0, nil, errors.New("point list cannot be empty")
+	}
+	addr, dims, err := fw.regionDataset(path)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	for i, p := range points {
+		if len(p) != len(dims) {
+			return 0, nil, fmt.Errorf("point %d has %d coordinates, dataset has %d dimensions", i, len(p), len(dims))
+		}
+		for d, c := range p {
+			if c >= dims[d] {
+				return 0, nil, fmt.Errorf("point %d out of bounds in dimension %d: %d >= %d", i, d, c, dims[d])
+			}
+		}
+	}
+
+	return addr, &core.Selection{
+		Type:   core.SelectionPoints,
+		Rank:   len(dims),
+		Points: points,
+	}, nil
+}
+
+// regionDataset returns the object header address and dimensions of the
+// dataset at path.
+func (fw *FileWriter) regionDataset(path string) (uint64, []uint64, error) {
+	addr, err := fw.resolveObjectAddress(path)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to resolve dataset %q: %w", path, err)
+	}
+
+	header, err := core.ReadObjectHeader(fw.writer, addr, fw.file.sb)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read object header of %q: %w", path, err)
+	}
+	if header.Type != core.ObjectTypeDataset {
+		return 0, nil, fmt.Errorf("%q is not a dataset", path)
+	}
+	info, err := core.ReadDatasetInfo(header, fw.file.sb)
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to read dataset %q: %w", path, err)
+	}
+	if len(info.Dataspace.Dimensions) == 0 {
+		return 0, nil, fmt.Errorf("cannot select a region of scalar dataset %q", path)
+	}
+	return addr, info.Dataspace.Dimensions, nil
+}
+
+// writeRegionRef stores the dataset address and selection of a region
+// reference in the global heap.
+func (fw *FileWriter) writeRegionRef(addr uint64, sel *core.Selection) (RegionRef, error) {
+	data, err := core.EncodeRegionReference(addr, sel, int(fw.file.sb.OffsetSize))
+	if err != nil {
+		return RegionRef{}, err
+	}
+	heapID, err := fw.globalHeapWriter.WriteToGlobalHeap(data)
+	if err != nil {
+		return RegionRef{}, fmt.Errorf("failed to write region reference: %w", err)
+	}
+	return RegionRef{HeapAddress: heapID.CollectionAddress, ObjectIndex: uint32(heapID.ObjectIndex)}, nil
+}
+
+// encodeRefs encodes revised references for writing expectedSize bytes of a
+// RevisedReference dataset. Region, attribute and external references are
+// stored in the global heap; the elements hold their heap IDs.
+func (fw *FileWriter) encodeRefs(refs []Ref, expectedSize uint64) ([]byte, error) {
+	offsetSize := int(fw.file.sb.OffsetSize)
+	elemSize := uint64(core.RefSize(offsetSize))
+	if actualSize := uint64(len(refs)) * elemSize; actualSize != expectedSize {
+		return nil, fmt.Errorf("data size mismatch: expected %d bytes, got %d bytes", expectedSize, actualSize)
+	}
+
+	putBlob := func(blob []byte) (uint64, uint32, error) {
+		heapID, err := fw.globalHeapWriter.WriteToGlobalHeap(blob)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to write reference to global heap: %w", err)
+		}
+		return heapID.CollectionAddress, uint32(heapID.ObjectIndex), nil
+	}
+
+	buf := make([]byte, 0, expectedSize)
+	for i, ref := range refs {
+		elem, err := core.EncodeRef(ref, offsetSize, putBlob)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode reference %d: %w", i, err)
+		}
+		buf = append(buf, elem...)
+	}
+	return buf, nil
+}
@@ -0,0 +1,863 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/meko-christian/go-hdf5/internal/writer"
+)
+
+// RepackOption configures Repack.
+type RepackOption func(*repackConfig)
+
+// repackConfig holds the settings of a Repack call.
+type repackConfig struct {
+	superblockVersion    uint8
+	superblockVersionSet bool
+
+	chunkDims  []uint64     // Chunk dimensions for datasets of matching rank
+	contiguous bool         // Write all datasets contiguously
+	filters    []FilterInfo // Filters replacing those of the source
+	filtersSet bool         // filters was set by a filter option
+
+	maxCompactLinks int // Links per group before dense storage
+	maxCompactAttrs int // Attributes per object before dense storage (-1 = writer default)
+}
+
+// RepackSuperblockVersion sets the superblock version of the repacked file.
+// By default the source version is kept if the writer supports it
+// (versions 0 to 2); otherwise version 2 is used.
+func RepackSuperblockVersion(version uint8) RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.superblockVersion = version
+		cfg.superblockVersionSet = true
+	}
+}
+
+// RepackChunkDims rechunks every dataset whose rank is len(dims) with the
+// given chunk dimensions. Other datasets keep their layout.
+func RepackChunkDims(dims []uint64) RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.chunkDims = dims
+		cfg.contiguous = false
+	}
+}
+
+// RepackContiguous writes every dataset with contiguous layout. Contiguous
+// datasets cannot be filtered or resized, so filters are dropped and
+// resizable datasets fail to repack.
+func RepackContiguous() RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.contiguous = true
+		cfg.chunkDims = nil
+	}
+}
+
+// RepackGZIP compresses every dataset with GZIP at the given level (1-9).
+// Like the other filter options, it replaces the filters of the source
+// datasets; filter options can be combined.
+func RepackGZIP(level int) RepackOption {
+	return repackFilter(FilterInfo{ID: FilterGZIP, ClientData: []uint32{uint32(level)}}) //nolint:gosec // Level is 1-9
+}
+
+// RepackShuffle applies the byte shuffle filter to every dataset. Shuffle
+// always runs before the other filters.
+func RepackShuffle() RepackOption {
+	return repackFilter(FilterInfo{ID: FilterShuffle})
+}
+
+// RepackFletcher32 adds a Fletcher32 checksum to every chunk.
+func RepackFletcher32() RepackOption {
+	return repackFilter(FilterInfo{ID: FilterFletcher32})
+}
+
+// RepackLZF compresses every dataset with LZF.
+func RepackLZF() RepackOption {
+	return repackFilter(FilterInfo{ID: FilterLZF})
+}
+
+// RepackNoFilters removes all filters, keeping each dataset's layout.
+func RepackNoFilters() RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.filters = nil
+		cfg.filtersSet = true
+	}
+}
+
+// repackFilter returns an option adding f to the filters of every dataset.
+func repackFilter(f FilterInfo) RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.filters = append(cfg.filters, f)
+		cfg.filtersSet = true
+	}
+}
+
+// RepackMaxCompactLinks sets how many links a group holds in a symbol table
+// before it is written as a dense group (default 8). The root group always
+// uses a symbol table.
+func RepackMaxCompactLinks(n int) RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.maxCompactLinks = max(n, 0)
+	}
+}
+
+// RepackMaxCompactAttributes sets how many attributes an object stores in
+// its header before they move to dense storage (see WithMaxCompactAttributes).
+func RepackMaxCompactAttributes(n int) RepackOption {
+	return func(cfg *repackConfig) {
+		cfg.maxCompactAttrs = max(n, 0)
+	}
+}
+
+// Repack copies every object of the HDF5 file src into a new file dst, like
+// the h5repack tool. The copy contains no free space left behind by deleted
+// objects or rewritten headers, and options can change the layout, filters,
+// superblock version and storage thresholds on the way.
+//
+// Groups, datasets, attributes, hard links (objects reachable by several
+// paths are copied once), soft links and external links are copied. Object
+// and region references are rewritten to point at the copied objects;
+// references to objects outside the copy become null references.
+//
+// Datasets keep their datatype, fill value, chunking and filters unless an
+// option replaces them. Contiguous datasets that get filters are stored as a
+// single chunk. Compact and virtual datasets are written contiguously, and
+// scalar datasets as one-element datasets.
+//
+// Limitations:
+//   - Named datatypes and shared datatype messages are not supported
+//   - User-defined links are not supported
+//   - Hard links back into a group stored densely (more links than
+//     RepackMaxCompactLinks) are not supported
+//   - Datasets with a zero-sized dimension are not supported
+//   - Variable-length and reference data nested in compound, array or
+//     variable-length types is not supported
+//   - Filters other than GZIP, shuffle, Fletcher32, LZF and BZIP2 are not supported
+//
+// Example:
+//
+//	// Compact a file and recompress its datasets
+//	err := hdf5.Repack("data.h5", "data_packed.h5",
+//	    hdf5.RepackShuffle(), hdf5.RepackGZIP(6))
+//
+// Reference: tools/src/h5repack/h5repack_copy.c - copy_objects().
+func Repack(src, dst string, opts ...RepackOption) error {
+	cfg := &repackConfig{
+		maxCompactLinks: denseGroupThreshold,
+		maxCompactAttrs: -1,
+	}
+	for _, opt := range opts {
+		opt(cfg)
+	}
+
+	f, err := Open(src)
+	if err != nil {
+		return fmt.Errorf("failed to open source file: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	version := cfg.superblockVersion
+	if !cfg.superblockVersionSet {
+		version = f.SuperblockVersion()
+		if version > SuperblockV2 {
+			version = SuperblockV2
+		}
+	}
+	writeOpts := []interface{}{WithSuperblockVersion(version)}
+	if cfg.maxCompactAttrs >= 0 {
+		writeOpts = append(writeOpts, WithMaxCompactAttributes(cfg.maxCompactAttrs))
+	}
+
+	// Version 1 object headers cannot be modified, so the root group of
+	// older formats gets its attributes on creation.
+	legacyRoot := version < SuperblockV2
+	if legacyRoot {
+		attrs, err := f.Root().Attributes()
+		if err != nil {
+			return fmt.Errorf("/: failed to read attributes: %w", err)
+		}
+		for _, attr := range attrs {
+			if attr.Datatype != nil && (attr.Datatype.Class == core.DatatypeVarLen || attr.Datatype.Class == core.DatatypeReference) {
+				return fmt.Errorf("/: attribute %q: variable-length and reference root attributes require superblock version 2", attr.Name)
+			}
+			writeOpts = append(writeOpts, WithRootAttribute(attr.Name, attr))
+		}
+	}
+
+	fw, err := CreateForWrite(dst, CreateTruncate, writeOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create destination file: %w", err)
+	}
+
+	r := &repacker{
+		src:     f,
+		fw:      fw,
+		cfg:     cfg,
+		written: map[uint64]uint64{f.sb.RootGroup: fw.rootGroupAddr},
+
+		rootAttrsWritten: legacyRoot,
+	}
+	if err := r.run(); err != nil {
+		_ = fw.Close()
+		return err
+	}
+	return fw.Close()
+}
+
+// repacker copies the objects of one file into a FileWriter.
+//
+// Object headers are rewritten in place, so each object's attributes and
+// reference count are written right after its header, while nothing follows
+// it in the file. Data needing global heap space is prepared before the
+// header is allocated. References are written in a second pass, once the
+// new address of every object is known, into storage of the same size.
+type repacker struct {
+	src *File
+	fw  *FileWriter
+	cfg *repackConfig
+
+	// written maps source object header addresses to copied ones. Dense
+	// groups are mapped to 0 until their children are copied.
+	written map[uint64]uint64
+
+	// linkCounts holds the number of hard links to each source object.
+	linkCounts map[uint64]uint32
+
+	references []repackReferences
+
+	// rootAttrsWritten is set when the root attributes were written on
+	// file creation.
+	rootAttrsWritten bool
+}
+
+// repackReferences holds reference data of a copied dataset or attribute,
+// written once all objects are copied.
+type repackReferences struct {
+	path string
+	dw   *DatasetWriter  // Dataset holding the references, or nil
+	addr uint64          // Object holding the attribute, if dw is nil
+	attr *core.Attribute // Source attribute, or the source dataset's data and type
+}
+
+// run copies the whole file.
+func (r *repacker) run() error {
+	root := r.src.Root()
+	r.linkCounts = make(map[uint64]uint32)
+	if err := r.countLinks(root); err != nil {
+		return err
+	}
+
+	if !r.rootAttrsWritten {
+		attrs, err := r.prepareAttributes("/", root.Attributes)
+		if err != nil {
+			return err
+		}
+		if err := r.writeAttributes("/", r.fw.rootGroupAddr, attrs); err != nil {
+			return err
+		}
+	}
+	if err := r.copyLinks(root, "/"); err != nil {
+		return err
+	}
+
+	for _, ref := range r.references {
+		data, err := r.remapReferences(ref.attr.Datatype, ref.attr.Data, ref.attr.Dataspace.TotalElements())
+		if err != nil {
+			return fmt.Errorf("%s: %w", ref.path, err)
+		}
+		if ref.dw != nil {
+			err = ref.dw.WriteRaw(data)
+		} else {
+			err = r.writeAttribute(ref.addr, ref.attr, data)
+		}
+		if err != nil {
+			return fmt.Errorf("%s: %w", ref.path, err)
+		}
+	}
+	return nil
+}
+
+// countLinks counts the hard links to each object below the group g.
+func (r *repacker) countLinks(g *Group) error {
+	links, err := g.Links()
+	if err != nil {
+		return err
+	}
+	for _, l := range links {
+		if l.Type != LinkHard {
+			continue
+		}
+		r.linkCounts[l.Address]++
+		if r.linkCounts[l.Address] > 1 {
+			continue
+		}
+		for _, child := range g.Children() {
+			if sub, ok := child.(*Group); ok && child.Name() == l.Name {
+				if err := r.countLinks(sub); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// copyLinks copies the links of the source group g into the group at path.
+func (r *repacker) copyLinks(g *Group, path string) error {
+	links, err := g.Links()
+	if err != nil {
+		return err
+	}
+	children := make(map[string]Object, len(g.children))
+	for _, child := range g.Children() {
+		children[child.Name()] = child
+	}
+
+	for _, l := range links {
+		childPath := strings.TrimSuffix(path, "/") + "/" + l.Name
+		switch l.Type {
+		case LinkHard:
+			child, ok := children[l.Name]
+			if !ok {
+				return fmt.Errorf("%s: object at 0x%x could not be read", childPath, l.Address)
+			}
+			err = r.copyObject(child, childPath, l.Address)
+		case LinkSoft:
+			// Link values are copied as is; they may be relative.
+			err = r.fw.createSoftLink(childPath, l.Target)
+		case LinkExternal:
+			err = r.fw.createExternalLink(childPath, l.File, l.Target)
+		default:
+			err = fmt.Errorf("%s: user-defined links (type %d) are not supported", childPath, l.Type)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// copyObject copies the object at srcAddr, linked from the source as path.
+// Objects copied before are linked again; their reference count already
+// includes all links.
+func (r *repacker) copyObject(obj Object, path string, srcAddr uint64) error {
+	if addr, seen := r.written[srcAddr]; seen {
+		if addr == 0 {
+			return fmt.Errorf("%s: hard link into the dense group being copied is not supported", path)
+		}
+		parent, name := parsePath(path)
+		return r.fw.linkToParent(parent, name, addr)
+	}
+
+	switch o := obj.(type) {
+	case *Group:
+		return r.copyGroup(o, path, srcAddr)
+	case *Dataset:
+		return r.copyDataset(o, path, srcAddr)
+	case *NamedDatatype:
+		return fmt.Errorf("%s: named datatypes are not supported", path)
+	default:
+		return fmt.Errorf("%s: unsupported object type %T", path, obj)
+	}
+}
+
+// copyGroup copies the group g and everything below it to path.
+func (r *repacker) copyGroup(g *Group, path string, srcAddr uint64) error {
+	links, err := g.Links()
+	if err != nil {
+		return err
+	}
+
+	if len(links) <= r.cfg.maxCompactLinks {
+		attrs, err := r.prepareAttributes(path, g.Attributes)
+		if err != nil {
+			return err
+		}
+		gw, err := r.fw.CreateGroup(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		if err := r.finishObject(path, srcAddr, gw.headerAddr, attrs); err != nil {
+			return err
+		}
+		return r.copyLinks(g, path)
+	}
+
+	// Dense groups are written once all their links are known.
+	if err := r.fw.beginDenseGroup(path); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	r.written[srcAddr] = 0
+	if err := r.copyLinks(g, path); err != nil {
+		return err
+	}
+	attrs, err := r.prepareAttributes(path, g.Attributes)
+	if err != nil {
+		return err
+	}
+	addr, err := r.fw.finishDenseGroup(path)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return r.finishObject(path, srcAddr, addr, attrs)
+}
+
+// copyDataset copies the dataset d to path.
+func (r *repacker) copyDataset(d *Dataset, path string, srcAddr uint64) error {
+	header, err := core.ReadObjectHeader(r.src.r, srcAddr, r.src.sb)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	for _, msg := range header.Messages {
+		if msg.Type == core.MsgDatatype && msg.Flags&core.MsgFlagShared != 0 {
+			return fmt.Errorf("%s: shared datatypes are not supported", path)
+		}
+	}
+
+	data, info, err := d.readRaw()
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := checkRepackDatatype(info.Datatype); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	meta := newDatasetMeta(info)
+	dims := meta.Shape
+	if len(dims) == 0 {
+		dims = []uint64{1}
+	}
+	if err := validateDimensions(dims); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	count := calculateTotalElements(dims)
+
+	config, err := r.datasetConfig(meta, info)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if info.Datatype.Class == core.DatatypeVarLen {
+		if data, err = r.copyHeapObjects(data, count); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+	attrs, err := r.prepareAttributes(path, d.Attributes)
+	if err != nil {
+		return err
+	}
+
+	dw, err := r.fw.createDataset(path, &parsedTypeHandler{msg: info.Datatype}, dims, config)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	if err := r.finishObject(path, srcAddr, dw.address, attrs); err != nil {
+		return err
+	}
+
+	if info.Datatype.Class == core.DatatypeReference {
+		r.references = append(r.references, repackReferences{
+			path: path,
+			dw:   dw,
+			attr: &core.Attribute{
+				Datatype:  info.Datatype,
+				Dataspace: info.Dataspace,
+				Data:      data,
+			},
+		})
+		return nil
+	}
+	if err := dw.WriteRaw(data); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	return nil
+}
+
+// finishObject records the copy of the object at srcAddr, written to addr,
+// and writes its reference count and attributes.
+func (r *repacker) finishObject(path string, srcAddr, addr uint64, attrs []*core.Attribute) error {
+	r.written[srcAddr] = addr
+
+	if n := r.linkCounts[srcAddr]; n > 1 {
+		oh, err := core.ReadObjectHeader(r.fw.writer, addr, r.fw.file.sb)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		oh.ReferenceCount = n
+		if err := writeObjectHeaderWithRefCount(r.fw, addr, oh); err != nil {
+			return fmt.Errorf("%s: failed to set reference count: %w", path, err)
+		}
+	}
+
+	return r.writeAttributes(path, addr, attrs)
+}
+
+// datasetConfig returns the creation settings of the copy of a dataset.
+func (r *repacker) datasetConfig(meta *DatasetMeta, info *core.DatasetInfo) (*datasetConfig, error) {
+	config := &datasetConfig{}
+
+	filters := meta.Filters
+	if r.cfg.filtersSet {
+		filters = r.cfg.filters
+	}
+
+	switch {
+	case r.cfg.contiguous:
+		filters = nil
+	case len(r.cfg.chunkDims) > 0 && len(r.cfg.chunkDims) == len(meta.Shape):
+		config.chunkDims = append([]uint64(nil), r.cfg.chunkDims...)
+	case meta.Layout == LayoutChunked:
+		config.chunkDims = meta.ChunkShape
+	case len(filters) > 0:
+		// Filters need chunks; store the dataset as a single chunk.
+		config.chunkDims = append([]uint64(nil), meta.Shape...)
+	}
+	if len(config.chunkDims) > 0 && len(meta.Shape) == 0 {
+		config.chunkDims = []uint64{1}
+	}
+
+	for i, dim := range meta.MaxShape {
+		if dim != meta.Shape[i] {
+			if len(config.chunkDims) == 0 {
+				return nil, fmt.Errorf("resizable datasets cannot be written contiguously")
+			}
+			config.maxDims = meta.MaxShape
+			break
+		}
+	}
+
+	if len(filters) > 0 {
+		if err := applyRepackFilters(config, filters); err != nil {
+			return nil, err
+		}
+	}
+
+	if fv := info.FillValue; fv != nil && info.Datatype.Class != core.DatatypeVarLen {
+		if fv.Defined && uint64(len(fv.Value)) == uint64(info.Datatype.Size) {
+			config.fillValue = fv.Value
+		}
+		config.fillTime = meta.FillTime
+	}
+
+	return config, nil
+}
+
+// applyRepackFilters sets up the writer filter pipeline for filters.
+func applyRepackFilters(config *datasetConfig, filters []FilterInfo) error {
+	config.pipeline = writer.NewFilterPipeline()
+	for _, f := range filters {
+		switch f.ID {
+		case FilterShuffle:
+			config.enableShuffle = true
+		case FilterGZIP:
+			level := 6
+			if len(f.ClientData) > 0 {
+				level = int(f.ClientData[0])
+			}
+			config.pipeline.AddFilter(writer.NewGZIPFilter(level))
+		case FilterFletcher32:
+			config.pipeline.AddFilter(writer.NewFletcher32Filter())
+		case FilterLZF:
+			config.pipeline.AddFilter(writer.NewLZFFilter())
+		case FilterBZIP2:
+			blockSize := 9
+			if len(f.ClientData) > 0 {
+				blockSize = int(f.ClientData[0])
+			}
+			config.pipeline.AddFilter(writer.NewBZIP2Filter(blockSize))
+		default:
+			return fmt.Errorf("filter %s is not supported for writing", f.ID)
+		}
+	}
+	return nil
+}
+
+// prepareAttributes reads the attributes of a source object and copies
+// their variable-length data to the new file.
+func (r *repacker) prepareAttributes(path string, read func() ([]*core.Attribute, error)) ([]*core.Attribute, error) {
+	attrs, err := read()
+	if err != nil {
+		return nil, fmt.Errorf("%s: failed to read attributes: %w", path, err)
+	}
+
+	prepared := make([]*core.Attribute, len(attrs))
+	for i, attr := range attrs {
+		if attr.Datatype == nil || attr.Dataspace == nil {
+			return nil, fmt.Errorf("%s: attribute %q has no datatype or dataspace", path, attr.Name)
+		}
+		if err := checkRepackDatatype(attr.Datatype); err != nil {
+			return nil, fmt.Errorf("%s: attribute %q: %w", path, attr.Name, err)
+		}
+
+		prepared[i] = attr
+		if attr.Datatype.Class == core.DatatypeVarLen {
+			data, err := r.copyHeapObjects(attr.Data, attr.Dataspace.TotalElements())
+			if err != nil {
+				return nil, fmt.Errorf("%s: attribute %q: %w", path, attr.Name, err)
+			}
+			prepared[i] = &core.Attribute{
+				Name:      attr.Name,
+				Datatype:  attr.Datatype,
+				Dataspace: attr.Dataspace,
+				Data:      data,
+			}
+		}
+	}
+	return prepared, nil
+}
+
+// writeAttributes writes the prepared attributes of the object copied to
+// addr. Attributes holding references are written as null references and
+// queued for the second pass.
+func (r *repacker) writeAttributes(path string, addr uint64, attrs []*core.Attribute) error {
+	for _, attr := range attrs {
+		data := attr.Data
+		if attr.Datatype.Class == core.DatatypeReference {
+			r.references = append(r.references, repackReferences{
+				path: fmt.Sprintf("%s: attribute %q", path, attr.Name),
+				addr: addr,
+				attr: attr,
+			})
+			data = make([]byte, len(attr.Data))
+		}
+		if err := r.writeAttribute(addr, attr, data); err != nil {
+			return fmt.Errorf("%s: attribute %q: %w", path, attr.Name, err)
+		}
+	}
+	return nil
+}
+
+// writeAttribute writes attr with the value data to the object at addr.
+func (r *repacker) writeAttribute(addr uint64, attr *core.Attribute, data []byte) error {
+	return writeAttribute(r.fw, addr, attr.Name, &core.Attribute{
+		Name:      attr.Name,
+		Datatype:  attr.Datatype,
+		Dataspace: attr.Dataspace,
+		Data:      data,
+	})
+}
+
+// copyHeapObjects copies the global heap objects of count variable-length
+// elements to the new file and returns the elements with their new heap IDs.
+//
+// Each element holds the sequence length (4 bytes) and the global heap ID:
+// collection address (offset size) and object index (4 bytes).
+func (r *repacker) copyHeapObjects(data []byte, count uint64) ([]byte, error) {
+	offsetSize := int(r.src.sb.OffsetSize)
+	if offsetSize != int(r.fw.file.sb.OffsetSize) {
+		return nil, fmt.Errorf("variable-length data with %d-byte offsets is not supported", offsetSize)
+	}
+	elemSize := 4 + offsetSize + 4
+	if uint64(len(data)) != count*uint64(elemSize) {
+		return nil, fmt.Errorf("variable-length data size mismatch: expected %d bytes, got %d bytes",
+			count*uint64(elemSize), len(data))
+	}
+
+	out := append([]byte(nil), data...)
+	collections := make(map[uint64]*core.GlobalHeapCollection)
+	for i := 0; i < len(out); i += elemSize {
+		elem := out[i : i+elemSize]
+		heapAddr := readOffset(elem[4:], offsetSize)
+		if heapAddr == 0 {
+			continue // Empty sequence
+		}
+		index := binary.LittleEndian.Uint32(elem[4+offsetSize:])
+
+		collection, ok := collections[heapAddr]
+		if !ok {
+			var err error
+			collection, err = core.ReadGlobalHeapCollection(r.src.r, heapAddr, offsetSize)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read global heap collection at 0x%x: %w", heapAddr, err)
+			}
+			collections[heapAddr] = collection
+		}
+		obj, err := collection.GetObject(index)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read global heap object: %w", err)
+		}
+
+		heapID, err := r.fw.globalHeapWriter.WriteToGlobalHeap(obj.Data)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write global heap object: %w", err)
+		}
+		writeOffset(elem[4:], heapID.CollectionAddress, offsetSize)
+		binary.LittleEndian.PutUint32(elem[4+offsetSize:], uint32(heapID.ObjectIndex))
+	}
+	return out, nil
+}
+
+// remapReferences rewrites count references of datatype dtype to point at
+// the copied objects. References to objects that were not copied become
+// null references.
+func (r *repacker) remapReferences(dtype *core.DatatypeMessage, data []byte, count uint64) ([]byte, error) {
+	offsetSize := int(r.src.sb.OffsetSize)
+	if offsetSize != int(r.fw.file.sb.OffsetSize) {
+		return nil, fmt.Errorf("references with %d-byte offsets are not supported", offsetSize)
+	}
+
+	if dtype.IsRevisedReference() {
+		refs, err := core.DecodeRefs(r.src.r, data, dtype, count, offsetSize)
+		if err != nil {
+			return nil, err
+		}
+		for i, ref := range refs {
+			if ref.IsNull() || ref.File != "" {
+				continue
+			}
+			addr, ok := r.written[ref.Address]
+			if !ok || addr == 0 {
+				refs[i] = Ref{}
+				continue
+			}
+			refs[i].Address = addr
+		}
+		return r.fw.encodeRefs(refs, uint64(len(data)))
+	}
+
+	switch dtype.ReferenceType() {
+	case core.ReferenceTypeObject:
+		refs, err := core.DecodeObjectReferences(data, dtype, count)
+		if err != nil {
+			return nil, err
+		}
+		for i, ref := range refs {
+			refs[i] = ObjectRef(r.written[uint64(ref)])
+		}
+		return encodeReferenceData(refs, dtype, uint64(len(data)))
+
+	case core.ReferenceTypeRegion:
+		refs, err := core.DecodeRegionReferences(data, dtype, count)
+		if err != nil {
+			return nil, err
+		}
+		for i, ref := range refs {
+			if ref.IsNull() {
+				continue
+			}
+			srcAddr, sel, err := core.ReadRegionReference(r.src.r, ref, offsetSize)
+			if err != nil {
+				return nil, err
+			}
+			addr, ok := r.written[srcAddr]
+			if !ok || addr == 0 {
+				refs[i] = RegionRef{}
+				continue
+			}
+			if refs[i], err = r.fw.writeRegionRef(addr, sel); err != nil {
+				return nil, err
+			}
+		}
+		return encodeReferenceData(refs, dtype, uint64(len(data)))
+
+	default:
+		return nil, fmt.Errorf("unsupported reference type: %d", dtype.ReferenceType())
+	}
+}
+
+// checkRepackDatatype reports an error for datatypes whose data Repack
+// cannot copy: variable-length and reference data is only rewritten at the
+// top level of a datatype.
+func checkRepackDatatype(dt *core.DatatypeMessage) error {
+	var nested []*core.DatatypeMessage
+	switch dt.Class {
+	case core.DatatypeVarLen:
+		base, err := core.ParseDatatypeMessage(dt.Properties)
+		if err != nil {
+			return fmt.Errorf("invalid variable-length base type: %w", err)
+		}
+		nested = append(nested, base)
+	case core.DatatypeCompound, core.DatatypeArray:
+		nested = append(nested, dt)
+	default:
+		return nil
+	}
+
+	for _, t := range nested {
+		found, err := hasHeapData(t)
+		if err != nil {
+			return err
+		}
+		if found {
+			return errors.New("nested variable-length or reference data is not supported")
+		}
+	}
+	return nil
+}
+
+// hasHeapData reports whether the members or base types of dt include
+// variable-length or reference types.
+func hasHeapData(dt *core.DatatypeMessage) (bool, error) {
+	var members []*core.DatatypeMessage
+	switch dt.Class {
+	case core.DatatypeVarLen, core.DatatypeReference:
+		return true, nil
+	case core.DatatypeCompound:
+		compound, err := core.ParseCompoundType(dt)
+		if err != nil {
+			return false, fmt.Errorf("unsupported compound datatype: %w", err)
+		}
+		for _, m := range compound.Members {
+			members = append(members, m.Type)
+		}
+	case core.DatatypeArray:
+		_, base, err := arrayType(dt)
+		if err != nil {
+			return false, err
+		}
+		members = append(members, base)
+	}
+
+	for _, m := range members {
+		if found, err := hasHeapData(m); found || err != nil {
+			return found, err
+		}
+	}
+	return false, nil
+}
+
+// arrayType parses the dimensions and base type of an array datatype.
+// Version 2 stores a permutation index after the dimensions, and pads the
+// rank to 4 bytes.
+func arrayType(dt *core.DatatypeMessage) ([]uint64, *core.DatatypeMessage, error) {
+	props := dt.Properties
+	if len(props) < 1 {
+		return nil, nil, errors.New("array datatype too short")
+	}
+	rank := int(props[0])
+	dimsOffset, offset := 1, 1+4*rank
+	if dt.Version < 3 {
+		dimsOffset, offset = 4, 4+8*rank
+	}
+	if len(props) < offset {
+		return nil, nil, errors.New("array datatype too short")
+	}
+	dims := make([]uint64, rank)
+	for i := range dims {
+		dims[i] = uint64(binary.LittleEndian.Uint32(props[dimsOffset+4*i:]))
+	}
+	base, err := core.ParseDatatypeMessage(props[offset:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return dims, base, nil
+}
+
+// readOffset reads a little-endian file offset of size bytes.
+func readOffset(b []byte, size int) uint64 {
+	if size == 4 {
+		return uint64(binary.LittleEndian.Uint32(b))
+	}
+	return binary.LittleEndian.Uint64(b)
+}
+
+// writeOffset writes a little-endian file offset of size bytes.
+func writeOffset(b []byte, v uint64, size int) {
+	if size == 4 {
+		binary.LittleEndian.PutUint32(b, uint32(v)) //nolint:gosec // G115: 4-byte offsets hold 32-bit addresses
+		return
+	}
+	binary.LittleEndian.PutUint64(b, v)
+}
@@ -0,0 +1,419 @@
+package hdf5
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+// writeRepackSource writes a file with groups, datasets, attributes and
+// links, and leaves a hole behind by deleting a dataset.
+func writeRepackSource(t *testing.T, filename string) {
+	t.Helper()
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+
+	scratch, err := fw.CreateDataset("/scratch", Int32, []uint64{256})
+	require.NoError(t, err)
+	require.NoError(t, scratch.Write(make([]int32, 256)))
+
+	grp, err := fw.CreateGroup("/grp")
+	require.NoError(t, err)
+	require.NoError(t, grp.WriteAttribute("count", int32(7)))
+	chunked, err := fw.CreateDataset("/grp/chunked", Int32, []uint64{8}, WithChunkDims([]uint64{4}), WithGZIPCompression(6))
+	require.NoError(t, err)
+	require.NoError(t, chunked.Write([]int32{1, 2, 3, 4, 5, 6, 7, 8}))
+
+	values, err := fw.CreateDataset("/values", Float64, []uint64{2, 3})
+	require.NoError(t, err)
+	require.NoError(t, fw.CreateHardLink("/grp/alias", "/values"))
+	require.NoError(t, values.WriteAttribute("units", "m"))
+	require.NoError(t, values.WriteAttribute("scale", 0.5))
+	require.NoError(t, values.Write([]float64{1, 2, 3, 4, 5, 6}))
+
+	require.NoError(t, fw.CreateSoftLink("/soft", "/grp/chunked"))
+	require.NoError(t, fw.CreateExternalLink("/ext", "other.h5", "/data"))
+
+	require.NoError(t, fw.Unlink("/scratch"))
+	require.NoError(t, fw.Close())
+}
+
+func TestRepack_CopiesObjects(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.h5")
+	dst := filepath.Join(dir, "dst.h5")
+	writeRepackSource(t, src)
+
+	require.NoError(t, Repack(src, dst))
+
+	f, err := Open(dst)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	values, err := f.OpenDataset("/values")
+	require.NoError(t, err)
+	data, err := values.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6}, data)
+	attrs, err := values.Attributes()
+	require.NoError(t, err)
+	require.Len(t, attrs, 2)
+	units, err := attrs[0].ReadValue()
+	require.NoError(t, err)
+	require.Equal(t, "m", units)
+
+	// Hard links share one copy of the object.
+	alias, err := f.OpenDataset("/grp/alias")
+	require.NoError(t, err)
+	require.Equal(t, values.Address(), alias.Address())
+	header, err := core.ReadObjectHeader(f.r, values.Address(), f.sb)
+	require.NoError(t, err)
+	require.Equal(t, uint32(2), header.GetReferenceCount())
+
+	grp, err := f.OpenGroup("/grp")
+	require.NoError(t, err)
+	grpAttrs, err := grp.Attributes()
+	require.NoError(t, err)
+	require.Len(t, grpAttrs, 1)
+	require.Equal(t, "count", grpAttrs[0].Name)
+
+	chunked, err := f.OpenDataset("/grp/chunked")
+	require.NoError(t, err)
+	data, err = chunked.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6, 7, 8}, data)
+	meta, err := chunked.Meta()
+	require.NoError(t, err)
+	require.Equal(t, []uint64{4}, meta.ChunkShape)
+	require.Len(t, meta.Filters, 1)
+	require.Equal(t, FilterGZIP, meta.Filters[0].ID)
+
+	links, err := f.Root().Links()
+	require.NoError(t, err)
+	byName := make(map[string]LinkInfo)
+	for _, l := range links {
+		byName[l.Name] = l
+	}
+	require.Equal(t, LinkSoft, byName["soft"].Type)
+	require.Equal(t, "/grp/chunked", byName["soft"].Target)
+	require.Equal(t, LinkExternal, byName["ext"].Type)
+	require.Equal(t, "other.h5", byName["ext"].File)
+	require.Equal(t, "/data", byName["ext"].Target)
+}
+
+func TestRepack_Filters(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.h5")
+	writeRepackSource(t, src)
+
+	tests := []struct {
+		name       string
+		opts       []RepackOption
+		dataset    string
+		wantChunks []uint64
+		wantIDs    []FilterID
+	}{
+		{
+			name:       "compress contiguous",
+			opts:       []RepackOption{RepackShuffle(), RepackGZIP(9), RepackFletcher32()},
+			dataset:    "/values",
+			wantChunks: []uint64{2, 3},
+			wantIDs:    []FilterID{FilterShuffle, FilterGZIP, FilterFletcher32},
+		},
+		{
+			name:       "rechunk with LZF",
+			opts:       []RepackOption{RepackChunkDims([]uint64{2}), RepackLZF()},
+			dataset:    "/grp/chunked",
+			wantChunks: []uint64{2},
+			wantIDs:    []FilterID{FilterLZF},
+		},
+		{
+			name:       "remove filters",
+			opts:       []RepackOption{RepackNoFilters()},
+			dataset:    "/grp/chunked",
+			wantChunks: []uint64{4},
+		},
+		{
+			name:    "contiguous",
+			opts:    []RepackOption{RepackContiguous()},
+			dataset: "/grp/chunked",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dst := filepath.Join(t.TempDir(), "dst.h5")
+			require.NoError(t, Repack(src, dst, tt.opts...))
+
+			f, err := Open(dst)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ds, err := f.OpenDataset(tt.dataset)
+			require.NoError(t, err)
+			meta, err := ds.Meta()
+			require.NoError(t, err)
+			require.Equal(t, tt.wantChunks, meta.ChunkShape)
+			ids := make([]FilterID, len(meta.Filters))
+			for i, filter := range meta.Filters {
+				ids[i] = filter.ID
+			}
+			require.Equal(t, len(tt.wantIDs), len(ids))
+			if len(tt.wantIDs) > 0 {
+				require.Equal(t, tt.wantIDs, ids)
+			}
+
+			for path, want := range map[string][]float64{
+				"/values":      {1, 2, 3, 4, 5, 6},
+				"/grp/chunked": {1, 2, 3, 4, 5, 6, 7, 8},
+			} {
+				d, err := f.OpenDataset(path)
+				require.NoError(t, err)
+				data, err := d.Read()
+				require.NoError(t, err)
+				require.Equal(t, want, data, path)
+			}
+		})
+	}
+}
+
+func TestRepack_References(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.h5")
+	dst := filepath.Join(dir, "dst.h5")
+
+	fw, err := CreateForWrite(src, CreateTruncate)
+	require.NoError(t, err)
+	// A dataset deleted before the copy shifts every later address.
+	filler, err := fw.CreateDataset("/filler", Int32, []uint64{64})
+	require.NoError(t, err)
+	require.NoError(t, filler.Write(make([]int32, 64)))
+	values, err := fw.CreateDataset("/values", Int32, []uint64{2, 3})
+	require.NoError(t, err)
+	require.NoError(t, values.Write([]int32{0, 1, 2, 3, 4, 5}))
+
+	objRef, err := fw.NewObjectRef("/values")
+	require.NoError(t, err)
+	regionRef, err := fw.NewPointRegionRef("/values", [][]uint64{{1, 2}, {0, 1}})
+	require.NoError(t, err)
+	objects, err := fw.CreateDataset("/objects", ObjectReference, []uint64{2})
+	require.NoError(t, err)
+	refData := make([]byte, 8)
+	fw.file.sb.Endianness.PutUint64(refData, uint64(objRef))
+	require.NoError(t, objects.WriteAttribute("target", &core.Attribute{
+		Datatype:  &core.DatatypeMessage{Class: core.DatatypeReference, Version: 1, Size: 8},
+		Dataspace: &core.DataspaceMessage{Type: core.DataspaceSimple, Dimensions: []uint64{1}},
+		Data:      refData,
+	}))
+	require.NoError(t, objects.Write([]ObjectRef{objRef, 0}))
+	regions, err := fw.CreateDataset("/regions", RegionReference, []uint64{1})
+	require.NoError(t, err)
+	require.NoError(t, regions.Write([]RegionRef{regionRef}))
+	require.NoError(t, fw.Unlink("/filler"))
+	require.NoError(t, fw.Close())
+
+	require.NoError(t, Repack(src, dst))
+
+	f, err := Open(dst)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ods, err := f.OpenDataset("/objects")
+	require.NoError(t, err)
+	refs, err := ods.ReadReferences()
+	require.NoError(t, err)
+	require.Len(t, refs, 2)
+	require.True(t, refs[1].IsNull())
+	obj, err := f.Dereference(refs[0])
+	require.NoError(t, err)
+	require.Equal(t, "values", obj.Name())
+
+	attrs, err := ods.Attributes()
+	require.NoError(t, err)
+	require.Len(t, attrs, 1)
+	attrRefs, err := core.DecodeReferences(f.r, attrs[0].Data, attrs[0].Datatype, 1, int(f.sb.OffsetSize))
+	require.NoError(t, err)
+	require.Equal(t, refs[0], attrRefs[0])
+
+	rds, err := f.OpenDataset("/regions")
+	require.NoError(t, err)
+	refs, err = rds.ReadReferences()
+	require.NoError(t, err)
+	data, err := f.ReadRegion(refs[0])
+	require.NoError(t, err)
+	require.Equal(t, []float64{5, 1}, data)
+}
+
+func TestRepack_Thresholds(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.h5")
+	dst := filepath.Join(dir, "dst.h5")
+	writeRepackSource(t, src)
+
+	require.NoError(t, Repack(src, dst,
+		RepackSuperblockVersion(SuperblockV2),
+		RepackMaxCompactLinks(1),
+		RepackMaxCompactAttributes(1)))
+
+	f, err := Open(dst)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.EqualValues(t, SuperblockV2, f.SuperblockVersion())
+
+	// /grp has two links, so its links are stored densely.
+	grp, err := f.OpenGroup("/grp")
+	require.NoError(t, err)
+	require.NotNil(t, grp.linkInfo)
+	require.True(t, grp.linkInfo.HasFractalHeap())
+	links, err := grp.Links()
+	require.NoError(t, err)
+	require.Len(t, links, 2)
+
+	// /values has two attributes, so they are stored densely.
+	values, err := f.OpenDataset("/grp/alias")
+	require.NoError(t, err)
+	header, err := core.ReadObjectHeader(f.r, values.Address(), f.sb)
+	require.NoError(t, err)
+	var hasAttrInfo bool
+	for _, msg := range header.Messages {
+		require.NotEqual(t, core.MsgAttribute, msg.Type)
+		hasAttrInfo = hasAttrInfo || msg.Type == core.MsgAttributeInfo
+	}
+	require.True(t, hasAttrInfo)
+	attrs, err := values.Attributes()
+	require.NoError(t, err)
+	require.Len(t, attrs, 2)
+
+	data, err := values.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6}, data)
+}
+
+func TestRepack_Testdata(t *testing.T) {
+	files := []string{
+		"testdata/with_groups.h5",
+		"testdata/various_types.h5",
+		"testdata/test_attributes.h5",
+		"testdata/compound_test.h5",
+		"testdata/gzip_test.h5",
+		"testdata/test_3d_chunked.h5",
+		"testdata/reference_traverse.h5",
+		"testdata/v0.h5",
+		"testdata/v3.h5",
+	}
+
+	for _, name := range files {
+		t.Run(filepath.Base(name), func(t *testing.T) {
+			if _, err := os.Stat(name); err != nil {
+				t.Skipf("%s not available", name)
+			}
+			dst := filepath.Join(t.TempDir(), "dst.h5")
+			require.NoError(t, Repack(name, dst))
+			require.Equal(t, repackContents(t, name), repackContents(t, dst))
+		})
+	}
+}
+
+func TestRepack_LinkTestdata(t *testing.T) {
+	// Soft and external links with relative and dangling targets.
+	files := []string{
+		"h5diff_links.h5",
+		"h5diff_danglelinks1.h5",
+		"h5diff_danglelinks2.h5",
+		"h5diff_linked_softlink.h5",
+		"h5diff_grp_recurse_ext1.h5",
+		"h5diff_ext2softlink_src.h5",
+		"h5copy_extlinks_src.h5",
+	}
+
+	for _, name := range files {
+		t.Run(name, func(t *testing.T) {
+			src := filepath.Join("testdata/hdf5_official", name)
+			if _, err := os.Stat(src); err != nil {
+				t.Skipf("%s not available", src)
+			}
+			dst := filepath.Join(t.TempDir(), name)
+			require.NoError(t, Repack(src, dst))
+			require.Equal(t, repackContents(t, src), repackContents(t, dst))
+			require.Equal(t, repackLinks(t, src), repackLinks(t, dst))
+		})
+	}
+}
+
+func TestRepack_Errors(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src.h5")
+	writeRepackSource(t, src)
+
+	err := Repack(filepath.Join(dir, "missing.h5"), filepath.Join(dir, "dst.h5"))
+	require.ErrorContains(t, err, "failed to open source file")
+
+	// Variable-length root attributes need a version 2 superblock.
+	if _, statErr := os.Stat("testdata/vlen_strings.h5"); statErr == nil {
+		err = Repack("testdata/vlen_strings.h5", filepath.Join(dir, "vlen.h5"))
+		require.ErrorContains(t, err, "require superblock version 2")
+	}
+}
+
+// repackContents returns the raw data of every dataset and the raw value of
+// every attribute in a file, by path.
+func repackContents(t *testing.T, filename string) map[string][]byte {
+	t.Helper()
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	contents := make(map[string][]byte)
+	addAttributes := func(path string, attrs []*core.Attribute, err error) {
+		require.NoError(t, err, path)
+		for _, attr := range attrs {
+			contents[path+"@"+attr.Name] = attr.Data
+		}
+	}
+	f.Walk(func(path string, obj Object) {
+		switch o := obj.(type) {
+		case *Group:
+			attrs, err := o.Attributes()
+			addAttributes(path, attrs, err)
+			contents[path] = nil
+		case *Dataset:
+			data, _, err := o.readRaw()
+			require.NoError(t, err, path)
+			contents[path] = data
+			attrs, err := o.Attributes()
+			addAttributes(path, attrs, err)
+		}
+	})
+	return contents
+}
+
+// repackLinks returns the soft and external links of a file by path.
+func repackLinks(t *testing.T, filename string) map[string]LinkInfo {
+	t.Helper()
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	links := make(map[string]LinkInfo)
+	f.Walk(func(path string, obj Object) {
+		g, ok := obj.(*Group)
+		if !ok {
+			return
+		}
+		groupLinks, err := g.Links()
+		require.NoError(t, err, path)
+		for _, l := range groupLinks {
+			if l.Type != LinkHard {
+				links[strings.TrimSuffix(path, "/")+"/"+l.Name] = l
+			}
+		}
+	})
+	return links
+}
@@ -0,0 +1,113 @@
+package hdf5
+
+import (
+	"errors"
+	"io"
+
+	"github.com/meko-christian/go-hdf5/internal/writer"
+)
+
+// Storage holds the bytes of a file written by a FileWriter created with
+// CreateWithStorage or OpenWithStorage. Implement it to write HDF5 files to
+// memory, object stores or other random-access storage.
+//
+// Addresses in the file map directly to offsets in the storage. Writes past
+// the end must extend the storage; Truncate zero-fills when it grows.
+type Storage = writer.Storage
+
+// MemoryStorage is a Storage that holds the file in memory.
+//
+// Thread-safety: Not thread-safe. Caller must synchronize access.
+type MemoryStorage struct {
+	buf []byte
+}
+
+// NewMemoryStorage returns a MemoryStorage holding data, which it takes
+// over and may modify. Pass nil to start empty.
+//
+// Example:
+//
+//	storage := hdf5.NewMemoryStorage(nil)
+//	fw, err := hdf5.CreateWithStorage(storage)
+//	// ... write datasets, then fw.Close() ...
+//	f, err := hdf5.OpenBytes(storage.Bytes())
+func NewMemoryStorage(data []byte) *MemoryStorage {
+	return &MemoryStorage{buf: data}
+}
+
+// Bytes returns the contents of the storage. The slice is valid until the
+// next write.
+func (s *MemoryStorage) Bytes() []byte {
+	return s.buf
+}
+
+// ReadAt implements io.ReaderAt.
+func (s *MemoryStorage) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if off >= int64(len(s.buf)) {
+		return 0, io.EOF
+	}
+	n := copy(p, s.buf[off:])
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+// WriteAt implements io.WriterAt, extending the storage as needed.
+func (s *MemoryStorage) WriteAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, errors.New("negative offset")
+	}
+	if end := off + int64(len(p)); end > int64(len(s.buf)) {
+		s.resize(end)
+	}
+	return copy(s.buf[off:], p), nil
+}
+
+// Size implements Storage.
+func (s *MemoryStorage) Size() (int64, error) {
+	return int64(len(s.buf)), nil
+}
+
+// Truncate implements Storage.
+func (s *MemoryStorage) Truncate(size int64) error {
+	if size < 0 {
+		return errors.New("negative size")
+	}
+	s.resize(size)
+	return nil
+}
+
+// Sync implements Storage. It does nothing.
+func (s *MemoryStorage) Sync() error {
+	return nil
+}
+
+// Close implements Storage. The contents stay available through Bytes.
+func (s *MemoryStorage) Close() error {
+	return nil
+}
+
+// resize changes the size of the storage to size, zero-filling when it
+// grows.
+func (s *MemoryStorage) resize(size int64) {
+	if size <= int64(len(s.buf)) {
+		s.buf = s.buf[:size]
+		return
+	}
+	if size > int64(cap(s.buf)) {
+		buf := make([]byte, size, max(size, 2*int64(cap(s.buf))))
+		copy(buf, s.buf)
+		s.buf = buf
+		return
+	}
+	old := len(s.buf)
+	s.buf = s.buf[:size]
+	clear(s.buf[old:])
+}
+
+// Ensure MemoryStorage implements Storage.
+var _ Storage = (*MemoryStorage)(nil)
@@ -0,0 +1,178 @@
+package hdf5
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenBytes(t *testing.T) {
+	data, err := os.ReadFile("testdata/compound_test.h5")
+	require.NoError(t, err)
+
+	f, err := OpenBytes(data)
+	require.NoError(t, err)
+	ds, err := f.OpenDataset("/measurements")
+	require.NoError(t, err)
+	values, err := ds.ReadCompound()
+	require.NoError(t, err)
+	require.Len(t, values, 5)
+	require.Equal(t, "Sample A", values[0]["name"])
+	require.NoError(t, f.Close())
+	require.NoError(t, f.Close())
+
+	_, err = OpenBytes([]byte("not an HDF5 file at all"))
+	require.ErrorContains(t, err, "not an HDF5 file")
+
+	// A truncated image fails to open.
+	_, err = OpenBytes(data[:len(data)/4])
+	require.Error(t, err)
+}
+
+func TestOpenReader_Embedded(t *testing.T) {
+	data, err := os.ReadFile("testdata/compound_test.h5")
+	require.NoError(t, err)
+
+	// The file stored at an offset inside a larger archive.
+	archive := append(append(bytes.Repeat([]byte{0xAA}, 1000), data...), bytes.Repeat([]byte{0xBB}, 100)...)
+	r := io.NewSectionReader(bytes.NewReader(archive), 1000, int64(len(data)))
+
+	f, err := OpenReader(r, r.Size())
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var paths []string
+	f.Walk(func(path string, _ Object) {
+		paths = append(paths, path)
+	})
+	require.Contains(t, paths, "/measurements")
+}
+
+func TestCreateWithStorage(t *testing.T) {
+	storage := NewMemoryStorage(nil)
+	fw, err := CreateWithStorage(storage)
+	require.NoError(t, err)
+
+	_, err = fw.CreateGroup("/grp")
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/grp/values", Int32, []uint64{8}, WithChunkDims([]uint64{4}), WithGZIPCompression(6))
+	require.NoError(t, err)
+	require.NoError(t, ds.WriteAttribute("units", "m"))
+	require.NoError(t, ds.Write([]int32{1, 2, 3, 4, 5, 6, 7, 8}))
+	names, err := fw.CreateDataset("/names", VLenString, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, names.Write([]string{"a", "bc"}))
+	require.NoError(t, fw.CreateSoftLink("/soft", "/grp"))
+	extra, err := fw.CreateDataset("/extra", Int32, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, extra.Write([]int32{1, 2}))
+	require.NoError(t, fw.Close())
+
+	image := storage.Bytes()
+	f, err := OpenBytes(image, WithFollowLinks(true))
+	require.NoError(t, err)
+	values, err := f.OpenDataset("/grp/values")
+	require.NoError(t, err)
+	got, err := values.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3, 4, 5, 6, 7, 8}, got)
+	attr, err := values.ReadAttribute("units")
+	require.NoError(t, err)
+	require.Equal(t, "m", attr)
+
+	// Followed links are visited once although the file has no name.
+	var paths []string
+	f.Walk(func(path string, _ Object) {
+		paths = append(paths, path)
+	})
+	require.Contains(t, paths, "/soft/values")
+	require.NoError(t, f.Close())
+
+	// The image matches a file written to disk.
+	filename := filepath.Join(t.TempDir(), "image.h5")
+	require.NoError(t, os.WriteFile(filename, image, 0o600))
+	f, err = Open(filename)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	// Modify the image in place.
+	fw, err = OpenWithStorage(NewMemoryStorage(image), OpenReadWrite)
+	require.NoError(t, err)
+	extra, err = fw.OpenDataset("/extra")
+	require.NoError(t, err)
+	require.NoError(t, extra.WriteAttribute("count", int32(2)))
+	require.NoError(t, fw.Unlink("/soft"))
+
+	// The header of /names is followed by its strings, so it cannot grow.
+	names, err = fw.OpenDataset("/names")
+	require.NoError(t, err)
+	require.ErrorContains(t, names.WriteAttribute("count", int32(2)), "cannot grow")
+	storage = fw.writer.Storage().(*MemoryStorage)
+	require.NoError(t, fw.Close())
+
+	f, err = OpenBytes(storage.Bytes())
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	rextra, err := f.OpenDataset("/extra")
+	require.NoError(t, err)
+	count, err := rextra.ReadAttribute("count")
+	require.NoError(t, err)
+	require.Equal(t, int32(2), count)
+	_, err = f.OpenDataset("/grp/values")
+	require.NoError(t, err)
+	ok, err := f.Exists("/soft")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestCreateWithStorage_Truncates(t *testing.T) {
+	storage := NewMemoryStorage(bytes.Repeat([]byte{0xFF}, 4096))
+	fw, err := CreateWithStorage(storage, WithSuperblockVersion(SuperblockV0))
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/data", Uint8, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]uint8{1, 2, 3}))
+	require.NoError(t, fw.Close())
+	require.Less(t, len(storage.Bytes()), 4096)
+
+	f, err := OpenBytes(storage.Bytes())
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	require.Equal(t, uint8(0), f.SuperblockVersion())
+
+	_, err = OpenWithStorage(NewMemoryStorage([]byte("garbage")), OpenReadWrite)
+	require.Error(t, err)
+}
+
+func TestMemoryStorage(t *testing.T) {
+	s := NewMemoryStorage(nil)
+
+	n, err := s.WriteAt([]byte("abc"), 4)
+	require.NoError(t, err)
+	require.Equal(t, 3, n)
+	require.Equal(t, []byte{0, 0, 0, 0, 'a', 'b', 'c'}, s.Bytes())
+
+	buf := make([]byte, 4)
+	n, err = s.ReadAt(buf, 5)
+	require.ErrorIs(t, err, io.EOF)
+	require.Equal(t, 2, n)
+	require.Equal(t, "bc", string(buf[:n]))
+	_, err = s.ReadAt(buf, 7)
+	require.ErrorIs(t, err, io.EOF)
+
+	// Shrinking and growing again zero-fills.
+	require.NoError(t, s.Truncate(5))
+	require.NoError(t, s.Truncate(7))
+	require.Equal(t, []byte{0, 0, 0, 0, 'a', 0, 0}, s.Bytes())
+	size, err := s.Size()
+	require.NoError(t, err)
+	require.Equal(t, int64(7), size)
+
+	require.Error(t, s.Truncate(-1))
+	_, err = s.WriteAt(buf, -1)
+	require.Error(t, err)
+}
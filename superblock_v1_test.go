@@ -0,0 +1,124 @@
+package hdf5
+
+import (
+	"encoding/binary"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSuperblockV1_CustomK(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "v1.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate,
+		WithSuperblockVersion(SuperblockV1),
+		WithSymbolTableK(8, 4),
+		WithIndexedStorageK(2))
+	require.NoError(t, err)
+
+	// Nodes of 4 chunks: 40 chunks need a three-level chunk B-tree.
+	ds, err := fw.CreateDataset("/data", Int32, []uint64{40}, WithChunkDims([]uint64{1}))
+	require.NoError(t, err)
+	values := make([]int32, 40)
+	for i := range values {
+		values[i] = int32(i * 3)
+	}
+	require.NoError(t, ds.Write(values))
+
+	// Symbol table nodes of 2*4 links: the ninth link splits the node.
+	for _, name := range []string{"/a", "/b", "/c", "/d", "/e", "/f", "/g", "/h"} {
+		_, err := fw.CreateGroup(name)
+		require.NoError(t, err)
+	}
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	sb := f.Superblock()
+	require.Equal(t, uint8(core.Version1), sb.Version)
+	require.Equal(t, uint16(4), sb.SymbolLeafK)
+	require.Equal(t, uint16(8), sb.SymbolInternalK)
+	require.Equal(t, uint16(2), sb.IndexedStorageK)
+
+	// The root group's B-tree node points to two symbol table nodes.
+	raw, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	node := raw[sb.RootBTreeAddr:]
+	require.Equal(t, "TREE", string(node[:4]))
+	require.Equal(t, uint16(2), binary.LittleEndian.Uint16(node[6:8]))
+
+	want := make([]float64, len(values))
+	for i, v := range values {
+		want[i] = float64(v)
+	}
+	require.Equal(t, want, readBack(t, filename, "/data"))
+
+	var names []string
+	f.Walk(func(path string, _ Object) {
+		names = append(names, path)
+	})
+	require.Contains(t, names, "/g/")
+	require.Contains(t, names, "/h/")
+}
+
+func TestSuperblockV0_SymbolTableK(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "v0.h5")
+	fw, err := CreateForWrite(filename, CreateTruncate, WithSuperblockVersion(SuperblockV0))
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/g")
+	require.NoError(t, err)
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	// The writer's symbol table nodes hold 32 links, recorded as leaf K 16.
+	require.Equal(t, uint16(16), f.Superblock().SymbolLeafK)
+	require.Equal(t, uint16(core.DefaultSymbolInternalK), f.Superblock().SymbolInternalK)
+}
+
+func TestSuperblockK_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []interface{}
+		want string
+	}{
+		{
+			name: "istore K with v0",
+			opts: []interface{}{WithSuperblockVersion(SuperblockV0), WithIndexedStorageK(16)},
+			want: "indexed storage K requires superblock version 1",
+		},
+		{
+			name: "istore K with v2",
+			opts: []interface{}{WithIndexedStorageK(16)},
+			want: "indexed storage K requires superblock version 1",
+		},
+		{
+			name: "symbol K with v2",
+			opts: []interface{}{WithSymbolTableK(16, 8)},
+			want: "symbol table K values require superblock version 0 or 1",
+		},
+		{
+			name: "zero leaf K",
+			opts: []interface{}{WithSuperblockVersion(SuperblockV1), WithSymbolTableK(16, 0)},
+			want: "symbol table leaf K must be between 1 and 32767",
+		},
+		{
+			name: "unknown version",
+			opts: []interface{}{WithSuperblockVersion(4)},
+			want: "unsupported superblock version: 4",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := CreateForWrite(filepath.Join(t.TempDir(), "bad.h5"), CreateTruncate, tt.opts...)
+			require.ErrorContains(t, err, tt.want)
+		})
+	}
+}
@@ -0,0 +1,70 @@
+/*
+ * Writes chunk_index_v4.h5: layout v4 datasets using the single chunk and
+ * version 2 B-tree chunk indexes, plain and deflated. Element i holds i.
+ *
+ *   h5cc -o create_chunk_index create_chunk_index.c && ./create_chunk_index
+ */
+#include "hdf5.h"
+#include <stdio.h>
+
+#define ROWS 40
+#define COLS 40
+
+static int write_dataset(hid_t file, const char *name, int rank, const hsize_t *dims,
+                         const hsize_t *maxdims, const hsize_t *chunk, int deflate,
+                         const int *data)
+{
+    hid_t space, dcpl, dset;
+    herr_t status;
+
+    space = H5Screate_simple(rank, dims, maxdims);
+    dcpl = H5Pcreate(H5P_DATASET_CREATE);
+    H5Pset_chunk(dcpl, rank, chunk);
+    if (deflate)
+        H5Pset_deflate(dcpl, 6);
+
+    dset = H5Dcreate2(file, name, H5T_STD_I32LE, space, H5P_DEFAULT, dcpl, H5P_DEFAULT);
+    status = H5Dwrite(dset, H5T_NATIVE_INT, H5S_ALL, H5S_ALL, H5P_DEFAULT, data);
+
+    H5Dclose(dset);
+    H5Pclose(dcpl);
+    H5Sclose(space);
+    return status < 0 ? -1 : 0;
+}
+
+int main(void)
+{
+    static int data[ROWS * COLS];
+    hid_t fapl, file;
+    int i;
+
+    /* Single chunk index: fixed dimensions equal to the chunk dimensions. */
+    hsize_t single_dims[2] = {6, 8};
+
+    /* Version 2 B-tree index: two unlimited dimensions; 400 chunks need
+     * internal nodes with the default 512-byte B-tree nodes. */
+    hsize_t bt2_dims[2] = {ROWS, COLS};
+    hsize_t bt2_max[2] = {H5S_UNLIMITED, H5S_UNLIMITED};
+    hsize_t bt2_chunk[2] = {2, 2};
+
+    for (i = 0; i < ROWS * COLS; i++)
+        data[i] = i;
+
+    fapl = H5Pcreate(H5P_FILE_ACCESS);
+    H5Pset_libver_bounds(fapl, H5F_LIBVER_LATEST, H5F_LIBVER_LATEST);
+    file = H5Fcreate("chunk_index_v4.h5", H5F_ACC_TRUNC, H5P_DEFAULT, fapl);
+
+    if (write_dataset(file, "single", 2, single_dims, NULL, single_dims, 0, data) < 0 ||
+        write_dataset(file, "single_filter", 2, single_dims, NULL, single_dims, 1, data) < 0 ||
+        write_dataset(file, "btree2", 2, bt2_dims, bt2_max, bt2_chunk, 0, data) < 0 ||
+        write_dataset(file, "btree2_filter", 2, bt2_dims, bt2_max, bt2_chunk, 1, data) < 0) {
+        fprintf(stderr, "failed to write chunk_index_v4.h5\n");
+        return 1;
+    }
+
+    H5Fclose(file);
+    H5Pclose(fapl);
+
+    printf("Created chunk_index_v4.h5\n");
+    return 0;
+}
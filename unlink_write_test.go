@@ -0,0 +1,506 @@
+package hdf5
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/meko-christian/go-hdf5/internal/core"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUnlink_Dataset(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "unlink.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/data")
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/data/a", Float64, []uint64{4})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{1, 2, 3, 4}))
+	ds, err = fw.CreateDataset("/data/b", Int32, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{5, 6}))
+
+	addr, err := fw.resolveObjectAddress("/data/a")
+	require.NoError(t, err)
+	require.True(t, fw.writer.Allocator().IsAllocated(addr, 1))
+
+	require.NoError(t, fw.Unlink("/data/a"))
+
+	// The object header and data of the dataset are released.
+	require.False(t, fw.writer.Allocator().IsAllocated(addr, 1))
+	require.ErrorIs(t, fw.Unlink("/data/a"), ErrNotFound)
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ok, err := f.Exists("/data/a")
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	remaining, err := f.OpenDataset("/data/b")
+	require.NoError(t, err)
+	data, err := remaining.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{5, 6}, data)
+}
+
+func TestUnlink_HardLinks(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "hardlinks.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/original", Float64, []uint64{3})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{1, 2, 3}))
+	require.NoError(t, fw.CreateHardLink("/alias", "/original"))
+
+	addr, err := fw.resolveObjectAddress("/original")
+	require.NoError(t, err)
+
+	// The object survives while a link is left.
+	require.NoError(t, fw.Unlink("/original"))
+	header, err := core.ReadObjectHeader(fw.writer, addr, fw.file.sb)
+	require.NoError(t, err)
+	require.Equal(t, uint32(1), header.GetReferenceCount())
+	require.True(t, fw.writer.Allocator().IsAllocated(addr, 1))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	alias, err := f.OpenDataset("/alias")
+	require.NoError(t, err)
+	data, err := alias.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2, 3}, data)
+	ok, err := f.Exists("/original")
+	require.NoError(t, err)
+	require.False(t, ok)
+	require.NoError(t, f.Close())
+
+	fw, err = OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	require.NoError(t, fw.Unlink("/alias"))
+	require.False(t, fw.writer.Allocator().IsAllocated(addr, 1))
+	require.NoError(t, fw.Close())
+}
+
+func TestDeleteGroup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "delete_group.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	defer func() { _ = fw.Close() }()
+
+	_, err = fw.CreateGroup("/g")
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/g/sub")
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/g/other", Int32, []uint64{1})
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/g/sub/kept", Int32, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{1, 2}))
+	require.NoError(t, fw.CreateHardLink("/kept", "/g/sub/kept"))
+
+	otherAddr, err := fw.resolveObjectAddress("/g/other")
+	require.NoError(t, err)
+
+	require.ErrorContains(t, fw.DeleteDataset("/g"), `"/g" is not a dataset`)
+	require.NoError(t, fw.DeleteGroup("/g"))
+
+	// Objects below the group are deleted unless linked elsewhere.
+	require.False(t, fw.writer.Allocator().IsAllocated(otherAddr, 1))
+	require.NotContains(t, fw.groups, "/g")
+	require.NotContains(t, fw.groups, "/g/sub")
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	ok, err := f.Exists("/g")
+	require.NoError(t, err)
+	require.False(t, ok)
+	kept, err := f.OpenDataset("/kept")
+	require.NoError(t, err)
+	data, err := kept.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2}, data)
+}
+
+func TestDeleteGroup_Recreate(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "recreate.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/g")
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/g/old", Float64, []uint64{1})
+	require.NoError(t, err)
+	require.NoError(t, fw.DeleteGroup("/g"))
+
+	// The name can be used again.
+	_, err = fw.CreateGroup("/g")
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/g/new", Float64, []uint64{1})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{7}))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	group, err := f.OpenGroup("/g")
+	require.NoError(t, err)
+	require.Len(t, group.Children(), 1)
+	ok, err := f.Exists("/g/old")
+	require.NoError(t, err)
+	require.False(t, ok)
+}
+
+func TestDeleteDataset_WrongType(t *testing.T) {
+	fw, err := CreateForWrite(filepath.Join(t.TempDir(), "types.h5"), CreateTruncate)
+	require.NoError(t, err)
+	defer func() { _ = fw.Close() }()
+
+	_, err = fw.CreateGroup("/group")
+	require.NoError(t, err)
+	_, err = fw.CreateDataset("/dataset", Float64, []uint64{1})
+	require.NoError(t, err)
+	require.NoError(t, fw.CreateSoftLink("/soft", "/dataset"))
+
+	require.ErrorContains(t, fw.DeleteGroup("/dataset"), `"/dataset" is not a group`)
+	require.ErrorContains(t, fw.DeleteDataset("/group"), `"/group" is not a dataset`)
+	require.ErrorContains(t, fw.DeleteDataset("/soft"), `"/soft" is a link, not a dataset`)
+	require.ErrorIs(t, fw.DeleteDataset("/missing"), ErrNotFound)
+	require.ErrorIs(t, fw.DeleteDataset("/missing/dataset"), ErrNotFound)
+	require.ErrorContains(t, fw.DeleteGroup("/"), "cannot remove the root group")
+}
+
+func TestUnlink_SoftLink(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "soft.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/target", Float64, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{3, 4}))
+	require.NoError(t, fw.CreateSoftLink("/soft", "/target"))
+	require.NoError(t, fw.CreateExternalLink("/ext", "other.h5", "/values"))
+
+	require.NoError(t, fw.Unlink("/soft"))
+	require.NoError(t, fw.Unlink("/ext"))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	links, err := f.Root().Links()
+	require.NoError(t, err)
+	require.Len(t, links, 1)
+	require.Equal(t, "target", links[0].Name)
+
+	target, err := f.OpenDataset("/target")
+	require.NoError(t, err)
+	data, err := target.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{3, 4}, data)
+}
+
+func TestMove(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "move.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/raw")
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/raw/run1")
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/raw/run1/temperature", Float64, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{20.5, 21}))
+	_, err = fw.CreateGroup("/archive")
+	require.NoError(t, err)
+
+	require.ErrorContains(t, fw.Move("/raw", "/raw/inner"), "into itself")
+	require.ErrorContains(t, fw.Move("/raw", "/archive"), `"/archive" already exists`)
+	require.ErrorIs(t, fw.Move("/missing", "/archive/missing"), ErrNotFound)
+
+	// Move a group, then rename a dataset within its new location.
+	require.NoError(t, fw.Move("/raw/run1", "/archive/run1"))
+	require.NoError(t, fw.Move("/archive/run1/temperature", "/archive/run1/t"))
+	require.NoError(t, fw.Move("/raw", "/old"))
+
+	// Groups created in this session are known under their new paths.
+	ds, err = fw.CreateDataset("/archive/run1/pressure", Int32, []uint64{1})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]int32{1013}))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	temperature, err := f.OpenDataset("/archive/run1/t")
+	require.NoError(t, err)
+	data, err := temperature.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{20.5, 21}, data)
+
+	_, err = f.OpenDataset("/archive/run1/pressure")
+	require.NoError(t, err)
+	_, err = f.OpenGroup("/old")
+	require.NoError(t, err)
+	for _, path := range []string{"/raw", "/old/run1", "/archive/run1/temperature"} {
+		ok, err := f.Exists(path)
+		require.NoError(t, err)
+		require.False(t, ok, path)
+	}
+}
+
+func TestUnlink_LibraryFiles(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		path    string
+		remains string
+	}{
+		{"symbol table", "tall.h5", "/g1/g1.1/dset1.1.1", "/g1/g1.1/dset1.1.2"},
+		{"dense group", "h5diff_grp_recurse1.h5", "/grp1", "/grp10"},
+		{"compact group", "tall.h5", "/g2/dset2.1", "/g2/dset2.2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			copyTestdata(t, tt.file, dir)
+			filename := filepath.Join(dir, tt.file)
+
+			fw, err := OpenForWrite(filename, OpenReadWrite)
+			require.NoError(t, err)
+			require.NoError(t, fw.Unlink(tt.path))
+			require.NoError(t, fw.Close())
+
+			f, err := Open(filename)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ok, err := f.Exists(tt.path)
+			require.NoError(t, err)
+			require.False(t, ok)
+			ok, err = f.Exists(tt.remains)
+			require.NoError(t, err)
+			require.True(t, ok)
+		})
+	}
+}
+
+func TestMove_LibraryFiles(t *testing.T) {
+	tests := []struct {
+		name string
+		file string
+		src  string
+		dst  string
+	}{
+		{"multi-node symbol table", "h5diff_basic1.h5", "/g1/d1", "/g1/zz"},
+		{"multi-node symbol table inner node", "h5diff_basic1.h5", "/g1/dset10", "/g1/fp0"},
+		{"into multi-node symbol table", "h5diff_basic1.h5", "/g1/d1", "/g1/dset0"},
+		{"into symbol table", "tall.h5", "/g2/dset2.1", "/g1/g1.1/dset1.1.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			copyTestdata(t, tt.file, dir)
+			filename := filepath.Join(dir, tt.file)
+
+			f, err := Open(filename)
+			require.NoError(t, err)
+			ds, err := f.OpenDataset(tt.src)
+			require.NoError(t, err)
+			want, err := ds.Read()
+			require.NoError(t, err)
+			require.NoError(t, f.Close())
+
+			fw, err := OpenForWrite(filename, OpenReadWrite)
+			require.NoError(t, err)
+			require.NoError(t, fw.Move(tt.src, tt.dst))
+			require.NoError(t, fw.Close())
+
+			f, err = Open(filename)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+
+			ok, err := f.Exists(tt.src)
+			require.NoError(t, err)
+			require.False(t, ok)
+			ds, err = f.OpenDataset(tt.dst)
+			require.NoError(t, err)
+			got, err := ds.Read()
+			require.NoError(t, err)
+			require.Equal(t, want, got)
+
+			// Every other link is still found by name.
+			f.Walk(func(path string, _ Object) {
+				ok, err := f.Exists(path)
+				require.NoError(t, err)
+				require.True(t, ok, path)
+			})
+		})
+	}
+}
+
+func TestMove_DenseGroup(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "dense.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	for i, name := range []string{"/a", "/b", "/c"} {
+		ds, err := fw.CreateDataset(name, Int32, []uint64{1})
+		require.NoError(t, err)
+		require.NoError(t, ds.Write([]int32{int32(i)}))
+	}
+	require.NoError(t, fw.CreateDenseGroup("/dense", map[string]string{"first": "/a"}))
+	require.NoError(t, fw.Close())
+
+	fw, err = OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	require.NoError(t, fw.Move("/b", "/dense/second"))
+	require.NoError(t, fw.Move("/dense/first", "/dense/renamed"))
+	require.NoError(t, fw.Move("/dense/renamed", "/first"))
+	require.NoError(t, fw.Move("/c", "/dense/third"))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	for path, want := range map[string]float64{"/dense/second": 1, "/first": 0, "/dense/third": 2} {
+		ds, err := f.OpenDataset(path)
+		require.NoError(t, err, path)
+		data, err := ds.Read()
+		require.NoError(t, err)
+		require.Equal(t, []float64{want}, data, path)
+	}
+	for _, path := range []string{"/b", "/c", "/dense/first", "/dense/renamed"} {
+		ok, err := f.Exists(path)
+		require.NoError(t, err)
+		require.False(t, ok, path)
+	}
+}
+
+func TestMove_UnsupportedDestinations(t *testing.T) {
+	tests := []struct {
+		name    string
+		file    string
+		src     string
+		dst     string
+		wantErr string
+	}{
+		{"dense group with free-space manager", "h5diff_grp_recurse1.h5", "/grp1/grp2/dset2", "/dset9", "free-space manager"},
+		{"compact group", "tall.h5", "/g1/g1.1/dset1.1.1", "/g2/dset2.3", "object header"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			copyTestdata(t, tt.file, dir)
+			filename := filepath.Join(dir, tt.file)
+
+			fw, err := OpenForWrite(filename, OpenReadWrite)
+			require.NoError(t, err)
+			require.ErrorContains(t, fw.Move(tt.src, tt.dst), tt.wantErr)
+			require.NoError(t, fw.Close())
+
+			// The file is unchanged.
+			f, err := Open(filename)
+			require.NoError(t, err)
+			defer func() { _ = f.Close() }()
+			ok, err := f.Exists(tt.src)
+			require.NoError(t, err)
+			require.True(t, ok)
+			ok, err = f.Exists(tt.dst)
+			require.NoError(t, err)
+			require.False(t, ok)
+		})
+	}
+}
+
+func TestUnlink_ReadErrorKeepsLink(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "corrupt.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	_, err = fw.CreateGroup("/g")
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/g/chunked", Float64, []uint64{4}, WithChunkDims([]uint64{2}))
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{1, 2, 3, 4}))
+	keep, err := fw.CreateDataset("/keep", Float64, []uint64{2})
+	require.NoError(t, err)
+	require.NoError(t, keep.Write([]float64{1, 2}))
+	require.NoError(t, fw.Close())
+
+	// Damage the chunk B-tree of the dataset below /g, which is only read
+	// when the dataset is released.
+	raw, err := os.ReadFile(filename)
+	require.NoError(t, err)
+	index := bytes.Index(raw, []byte("TREE\x01"))
+	require.Positive(t, index)
+	raw[index] = 'X'
+	require.NoError(t, os.WriteFile(filename, raw, 0o600))
+
+	fw, err = OpenForWrite(filename, OpenReadWrite)
+	require.NoError(t, err)
+	require.ErrorContains(t, fw.Unlink("/g"), "failed to release object")
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	ok, err := f.Exists("/g/chunked")
+	require.NoError(t, err)
+	require.True(t, ok)
+	ds2, err := f.OpenDataset("/keep")
+	require.NoError(t, err)
+	data, err := ds2.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{1, 2}, data)
+}
+
+func TestUnlink_AfterWriteAttribute(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "attr.h5")
+
+	fw, err := CreateForWrite(filename, CreateTruncate)
+	require.NoError(t, err)
+	ds, err := fw.CreateDataset("/a", Float64, []uint64{4})
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{1, 2, 3, 4}))
+	require.NoError(t, ds.WriteAttribute("units", "m"))
+	ds, err = fw.CreateDataset("/b", Float64, []uint64{4}, WithChunkDims([]uint64{2}))
+	require.NoError(t, err)
+	require.NoError(t, ds.Write([]float64{5, 6, 7, 8}))
+
+	require.NoError(t, fw.Unlink("/a"))
+	require.NoError(t, fw.Close())
+
+	f, err := Open(filename)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+	ok, err := f.Exists("/a")
+	require.NoError(t, err)
+	require.False(t, ok)
+	b, err := f.OpenDataset("/b")
+	require.NoError(t, err)
+	data, err := b.Read()
+	require.NoError(t, err)
+	require.Equal(t, []float64{5, 6, 7, 8}, data)
+}